@@ -24,6 +24,11 @@
 -- were last-turn snapshots from an assigning RecordTokenUsage and
 -- undercounted real spend on multi-turn traces by ~28x.
 --
+-- Batched calls (turns[].batch_id set, e.g. the claudeexecutor Message
+-- Batches mode) are billed at 50% of the synchronous rate on every token
+-- class, so each turn's cost is halved when it carries a batch_id. The
+-- discount composes with the tier and cache multipliers per call.
+--
 -- Tier matching is per-call (each turn classified independently against 200K)
 -- which matches Anthropic and Vertex billing semantics. All four cost
 -- columns (input / output / cache_read / cache_creation) honor the Large
@@ -153,53 +158,54 @@ priced AS (
 SELECT
   m.* EXCEPT (p_std, p_large),
   -- Per-call sums over turns[]. Each turn picks its own tier based on
-  -- that call's input_tokens against the 200K threshold.
+  -- that call's input_tokens against the 200K threshold, and batched turns
+  -- take the 50% batch discount.
   (
-    SELECT SUM(
+    SELECT SUM((
       COALESCE(turn.input_tokens, 0) *
         IF(turn.input_tokens > 200000 AND m.pricing_model IN (
              'claude-sonnet-4-5','gemini-2.5-pro','gemini-3-pro-preview','gemini-3.1-pro-preview'
            ),
            p_large.input_price,
            p_std.input_price)
-    )
+    ) * IF(IFNULL(turn.batch_id, '') != '', 0.5, 1.0))
     FROM UNNEST(m.turns) turn
   ) AS input_cost_usd,
   (
-    SELECT SUM(
+    SELECT SUM((
       COALESCE(turn.output_tokens, 0) *
         IF(turn.input_tokens > 200000 AND m.pricing_model IN (
              'claude-sonnet-4-5','gemini-2.5-pro','gemini-3-pro-preview','gemini-3.1-pro-preview'
            ),
            p_large.output_price,
            p_std.output_price)
-    )
+    ) * IF(IFNULL(turn.batch_id, '') != '', 0.5, 1.0))
     FROM UNNEST(m.turns) turn
   ) AS output_cost_usd,
   (
-    SELECT SUM(
+    SELECT SUM((
       COALESCE(turn.cache_read_tokens, 0) *
         IF(turn.input_tokens > 200000 AND m.pricing_model IN (
              'claude-sonnet-4-5','gemini-2.5-pro','gemini-3-pro-preview','gemini-3.1-pro-preview'
            ),
            p_large.cache_read_price,
            p_std.cache_read_price)
-    )
+    ) * IF(IFNULL(turn.batch_id, '') != '', 0.5, 1.0))
     FROM UNNEST(m.turns) turn
   ) AS cache_read_cost_usd,
   (
-    SELECT SUM(
+    SELECT SUM((
       COALESCE(turn.cache_creation_tokens, 0) *
         IF(turn.input_tokens > 200000 AND m.pricing_model IN (
              'claude-sonnet-4-5','gemini-2.5-pro','gemini-3-pro-preview','gemini-3.1-pro-preview'
            ),
            p_large.cache_creation_price,
            p_std.cache_creation_price)
-    )
+    ) * IF(IFNULL(turn.batch_id, '') != '', 0.5, 1.0))
     FROM UNNEST(m.turns) turn
   ) AS cache_creation_cost_usd,
   (
-    SELECT SUM(
+    SELECT SUM((
       COALESCE(turn.input_tokens, 0) *
         IF(turn.input_tokens > 200000 AND m.pricing_model IN (
              'claude-sonnet-4-5','gemini-2.5-pro','gemini-3-pro-preview','gemini-3.1-pro-preview'
@@ -224,7 +230,7 @@ SELECT
            ),
            p_large.cache_creation_price,
            p_std.cache_creation_price)
-    )
+    ) * IF(IFNULL(turn.batch_id, '') != '', 0.5, 1.0))
     FROM UNNEST(m.turns) turn
   ) AS total_cost_usd
 FROM priced m
@@ -103,8 +103,8 @@ func moreReasoningBlocksNote(n int) string {
 // result-submission tools are deliberately excluded: their rationales are
 // procedural noise in a change summary.
 var DefaultRationaleToolNames = []string{
-	"edit_file", "write_file", "delete_file", "move_file", "copy_file",
-	"chmod", "symlink",
+	"edit_file", "apply_patch", "write_file", "delete_file", "move_file", "copy_file",
+	"chmod", "symlink", "restore_checkpoint",
 }
 
 // SummarizeTraceReasoning renders a concise, human-readable rationale for a
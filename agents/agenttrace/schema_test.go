@@ -105,6 +105,10 @@ func TestAgentTraceSchemaMatchesStruct(t *testing.T) {
 		name: "reasoning",
 		tags: jsonTags(reflect.TypeFor[ReasoningContent]()),
 		cols: fieldsOf("reasoning"),
+	}, {
+		name: "compactions",
+		tags: jsonTags(reflect.TypeFor[Compaction]()),
+		cols: fieldsOf("compactions"),
 	}, {
 		name:       "exec_context",
 		tags:       jsonTags(reflect.TypeFor[ExecutionContext]()),
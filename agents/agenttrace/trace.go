@@ -76,6 +76,19 @@ type ReasoningContent struct {
 	Thinking string `json:"thinking"`
 }
 
+// Compaction records one context-compaction step: before the request for
+// Turn, the executor elided older tool results to keep the conversation
+// within its configured budget. Token counts are the executor's estimates,
+// not provider-reported usage.
+type Compaction struct {
+	Turn                  int       `json:"turn"`
+	Time                  time.Time `json:"time"`
+	EstimatedTokensBefore int64     `json:"estimated_tokens_before"`
+	EstimatedTokensAfter  int64     `json:"estimated_tokens_after"`
+	ElidedToolResults     int       `json:"elided_tool_results"`
+	ElidedBytes           int64     `json:"elided_bytes"`
+}
+
 // ToolCall represents a single tool invocation within a trace
 type ToolCall[T any] struct {
 	ID     string         `json:"id"`
@@ -162,6 +175,14 @@ type RecordedTurn struct {
 	CacheCreationTokens int64     `json:"cache_creation_tokens,omitempty"`
 	StartTime           time.Time `json:"start_time"`
 	EndTime             time.Time `json:"end_time"`
+	// BatchID is the provider batch the turn's request was submitted through
+	// (e.g. an Anthropic Message Batch), empty for a synchronous call. Batched
+	// calls are billed at the provider's batch discount, so cost analysis
+	// prices a turn by whether it is set. Populated via LLMTurn.RecordBatch.
+	BatchID string `json:"batch_id,omitempty"`
+	// BatchWaitMS is how long the batched request waited between submission
+	// and the poll that collected its result, in milliseconds.
+	BatchWaitMS int64 `json:"batch_wait_ms,omitempty"`
 	// Errors is the chronological list of errors the turn encountered, including
 	// transients that the turn recovered from. A non-empty list does NOT mean
 	// the turn failed — see Failed for the terminal outcome. Populated via
@@ -191,6 +212,7 @@ type Trace[T any] struct {
 	ToolCalls   []*ToolCall[T]     `json:"tool_calls"`
 	Turns       []RecordedTurn     `json:"turns,omitempty"`
 	Reasoning   []ReasoningContent `json:"reasoning,omitempty"`
+	Compactions []Compaction       `json:"compactions,omitempty"`
 	Result      T                  `json:"result"`
 	Error       error              `json:"-"` // handled by MarshalJSON
 	StartTime   time.Time          `json:"start_time"`
@@ -483,6 +505,24 @@ func (lt *LLMTurn[T]) RecordCacheTokens(cacheReadTokens, cacheCreationTokens int
 	lt.record.CacheCreationTokens = cacheCreationTokens
 }
 
+// RecordBatch marks the turn as served through the provider batch batchID
+// rather than a synchronous call, with wait the time the request spent
+// between submission and result collection. It sets the driftlessaf.batch.*
+// span attributes and the per-turn record, so downstream cost analysis can
+// apply the batch discount per call and latency dashboards can separate
+// queueing from generation. A turn that only submitted the batch records a
+// zero wait.
+func (lt *LLMTurn[T]) RecordBatch(batchID string, wait time.Duration) {
+	if lt.span != nil {
+		lt.span.SetAttributes(
+			attribute.String(AttrBatchID, batchID),
+			attribute.Int64(AttrBatchWaitMS, wait.Milliseconds()),
+		)
+	}
+	lt.record.BatchID = batchID
+	lt.record.BatchWaitMS = wait.Milliseconds()
+}
+
 // RecordError appends err to the turn's chronological error list and emits
 // an exception event on the OTEL span. It does NOT mark the turn as failed —
 // use Fail for that. RecordError is the right call for transient errors the
@@ -812,6 +852,27 @@ func (t *Trace[T]) AppendReasoning(content ReasoningContent) {
 	t.Reasoning = append(t.Reasoning, content)
 }
 
+// RecordCompaction appends a context-compaction step to the trace and adds a
+// matching event to the root span. Time defaults to now.
+func (t *Trace[T]) RecordCompaction(c Compaction) {
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	t.mu.Lock()
+	t.Compactions = append(t.Compactions, c)
+	span := t.span
+	t.mu.Unlock()
+
+	if span != nil {
+		span.AddEvent(EventCompaction, oteltrace.WithTimestamp(c.Time), oteltrace.WithAttributes(
+			attribute.Int(AttrCompactionTurn, c.Turn),
+			attribute.Int64(AttrCompactionTokensBefore, c.EstimatedTokensBefore),
+			attribute.Int64(AttrCompactionTokensAfter, c.EstimatedTokensAfter),
+			attribute.Int(AttrCompactionElided, c.ElidedToolResults),
+		))
+	}
+}
+
 // Duration returns the duration of the tool call
 func (tc *ToolCall[T]) Duration() time.Duration {
 	tc.mu.Lock()
@@ -884,6 +945,33 @@ const (
 	AttrResumeLinkedTraceID = "driftlessaf.resume.linked_trace_id"
 )
 
+// Batch span attributes on a per-call chat span whose request was served
+// through a provider batch API rather than a synchronous call.
+const (
+	// AttrBatchID carries the provider batch identifier.
+	AttrBatchID = "driftlessaf.batch.id"
+	// AttrBatchWaitMS carries the submission-to-collection wait in
+	// milliseconds.
+	AttrBatchWaitMS = "driftlessaf.batch.wait_ms"
+)
+
+// Compaction span event and attributes, added to the root span by
+// RecordCompaction.
+const (
+	// EventCompaction names the span event for one compaction step.
+	EventCompaction = "driftlessaf.compaction"
+	// AttrCompactionTurn carries the turn the compaction preceded.
+	AttrCompactionTurn = "driftlessaf.compaction.turn"
+	// AttrCompactionTokensBefore carries the estimated conversation size
+	// before elision.
+	AttrCompactionTokensBefore = "driftlessaf.compaction.estimated_tokens_before"
+	// AttrCompactionTokensAfter carries the estimated conversation size
+	// after elision.
+	AttrCompactionTokensAfter = "driftlessaf.compaction.estimated_tokens_after"
+	// AttrCompactionElided carries the number of tool results elided.
+	AttrCompactionElided = "driftlessaf.compaction.elided_tool_results"
+)
+
 // Suspend finalizes the trace as suspended: it halted mid-run to await an
 // out-of-band signal (a human answer, a checkpoint wake) rather than
 // completing or failing. Unlike complete's error path, the root span is closed
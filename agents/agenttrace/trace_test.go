@@ -426,6 +426,45 @@ func TestLLMTurnEndWithoutRecordTokens(t *testing.T) {
 	}
 }
 
+// RecordBatch lands the batch ID and wait on the turn's row so cost analysis
+// can apply the batch discount per call.
+func TestLLMTurnRecordBatch(t *testing.T) {
+	tracer := &mockTracer[string]{traces: &[]*Trace[string]{}}
+	trace := tracer.NewTrace(t.Context(), randomString())
+
+	turn := trace.BeginTurn(0, "anthropic", "model")
+	turn.RecordTokens(10, 5)
+	turn.RecordBatch("msgbatch_123", 90*time.Second)
+	turn.End()
+
+	want := []RecordedTurn{
+		{Index: 0, Model: "model", System: "anthropic", InputTokens: 10, OutputTokens: 5, BatchID: "msgbatch_123", BatchWaitMS: 90000},
+	}
+	if diff := cmp.Diff(want, trace.Turns, ignoreTurnTimes); diff != "" {
+		t.Errorf("trace.Turns (-want +got):\n%s", diff)
+	}
+}
+
+func TestTraceRecordCompaction(t *testing.T) {
+	tracer := &mockTracer[string]{traces: &[]*Trace[string]{}}
+	trace := tracer.NewTrace(t.Context(), randomString())
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	trace.RecordCompaction(Compaction{Turn: 7, Time: at, EstimatedTokensBefore: 160000, EstimatedTokensAfter: 110000, ElidedToolResults: 3, ElidedBytes: 200000})
+	trace.RecordCompaction(Compaction{Turn: 12})
+
+	if got := len(trace.Compactions); got != 2 {
+		t.Fatalf("len(Compactions): got = %d, want = 2", got)
+	}
+	want := Compaction{Turn: 7, Time: at, EstimatedTokensBefore: 160000, EstimatedTokensAfter: 110000, ElidedToolResults: 3, ElidedBytes: 200000}
+	if diff := cmp.Diff(want, trace.Compactions[0]); diff != "" {
+		t.Errorf("Compactions[0] (-want +got):\n%s", diff)
+	}
+	if trace.Compactions[1].Time.IsZero() {
+		t.Error("Compactions[1].Time: got zero, want defaulted to now")
+	}
+}
+
 // RecordError appends to the chronological Errors list without marking the
 // turn failed. This is the recovery shape: a transient error happened, the
 // turn went on to succeed, and BQ should see both facts (Errors non-empty,
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package continuation
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// anthropicState is the subset of an anthropic.MessageNewParams JSON payload
+// the renderer reads.
+type anthropicState struct {
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+}
+
+// anthropicBlock is one content block of an Anthropic message. Thinking and
+// redacted_thinking blocks decode too and are skipped by type.
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// renderAnthropic renders a claudeexecutor ProviderState: the Messages of the
+// request as it stood at the pause point.
+func renderAnthropic(raw json.RawMessage) ([]Entry, error) {
+	var state anthropicState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	toolNames := make(map[string]string)
+	for i, m := range state.Messages {
+		role := RoleUser
+		if m.Role == "assistant" {
+			role = RoleAssistant
+		}
+		// Content is either a bare string or an array of blocks.
+		if len(m.Content) > 0 && m.Content[0] == '"' {
+			text, err := contentText(m.Content)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+			entries = append(entries, Entry{Role: role, Kind: KindText, Text: text})
+			continue
+		}
+		var blocks []anthropicBlock
+		if len(m.Content) > 0 {
+			if err := json.Unmarshal(m.Content, &blocks); err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+		}
+		for _, b := range blocks {
+			switch b.Type {
+			case "text":
+				if b.Text != "" {
+					entries = append(entries, Entry{Role: role, Kind: KindText, Text: b.Text})
+				}
+			case "tool_use":
+				toolNames[b.ID] = b.Name
+				entries = append(entries, Entry{Role: RoleAssistant, Kind: KindToolCall, Tool: b.Name, ID: b.ID, Text: compactJSON(b.Input)})
+			case "tool_result":
+				text, err := contentText(b.Content)
+				if err != nil {
+					return nil, fmt.Errorf("message %d: tool_result %q: %w", i, b.ToolUseID, err)
+				}
+				if b.IsError {
+					text = "error: " + text
+				}
+				entries = append(entries, Entry{Role: RoleTool, Kind: KindToolResult, Tool: toolNames[b.ToolUseID], ID: b.ToolUseID, Text: text})
+			}
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package continuation
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+)
+
+const (
+	// DefaultEntryMaxBytes caps the text of a single transcript entry. A tool
+	// result (a file read, a command's output) can be arbitrarily large; the
+	// continuation needs its gist, not its bytes.
+	DefaultEntryMaxBytes = 4096
+
+	// DefaultTranscriptMaxBytes bounds the transcript as a whole. When the
+	// capped entries still exceed it the oldest are dropped — the turns
+	// leading up to the question matter most — and Continuation.Omitted
+	// records how many.
+	DefaultTranscriptMaxBytes = 64 * 1024
+)
+
+// Roles of a transcript Entry, normalized across providers.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Kinds of a transcript Entry.
+const (
+	// KindText is prose: a user prompt or the assistant's visible reply.
+	KindText = "text"
+	// KindToolCall is a tool invocation issued by the assistant; Text holds
+	// its JSON arguments.
+	KindToolCall = "tool_call"
+	// KindToolResult is the output a tool returned; Text holds it verbatim.
+	KindToolResult = "tool_result"
+)
+
+// Entry is one provider-neutral step of a parked conversation.
+type Entry struct {
+	// Role is RoleUser, RoleAssistant, or RoleTool.
+	Role string `xml:"role,attr"`
+	// Kind is KindText, KindToolCall, or KindToolResult.
+	Kind string `xml:"kind,attr"`
+	// Tool names the tool for a call or result.
+	Tool string `xml:"tool,attr,omitempty"`
+	// ID is the provider's call identifier for a call or result, so a result
+	// can be matched to its call. Empty when the provider issued none.
+	ID string `xml:"id,attr,omitempty"`
+	// Text is the entry's content.
+	Text string `xml:",chardata"`
+}
+
+// Pending is a pending ask-a-friend question paired with the friend's
+// framed answer.
+type Pending struct {
+	// Tool is the suspend tool the model called.
+	Tool string `xml:"tool,attr"`
+	// ID is the pending call's identifier, matching the transcript's call.
+	ID string `xml:"id,attr"`
+	// Question is the friend-facing question, when the call carried one.
+	Question string `xml:"question,omitempty"`
+	// Answer is the friend's answer, framed by checkpoint.FramedAnswers.
+	Answer string `xml:"answer"`
+}
+
+// Continuation is the seed for a fresh run continuing a parked conversation
+// on a different model. It marshals as a <parked_conversation> XML element.
+type Continuation struct {
+	XMLName xml.Name `xml:"parked_conversation"`
+	// Provider and Model identify where the conversation was parked.
+	Provider string `xml:"provider,attr"`
+	Model    string `xml:"model,attr"`
+	// Omitted counts the oldest entries dropped to fit the transcript budget.
+	Omitted int `xml:"omitted_entries,attr,omitempty"`
+	// Entries is the parked transcript, oldest first.
+	Entries []Entry `xml:"entry"`
+	// Pending pairs each pending question with its answer, in envelope order.
+	Pending []Pending `xml:"pending_question"`
+}
+
+// renderers decode each provider's ProviderState into transcript entries.
+var renderers = map[string]func(json.RawMessage) ([]Entry, error){
+	checkpoint.ProviderAnthropic: renderAnthropic,
+	checkpoint.ProviderGoogle:    renderGoogle,
+	checkpoint.ProviderOpenAI:    renderOpenAI,
+}
+
+// Transcript renders env's ProviderState as provider-neutral entries, oldest
+// first, using the renderer for env.Provider. System prompts and thinking
+// content are dropped: the continuing run has its own system prompt, and
+// thinking is provider-private. The answer frame's delimiters are stripped
+// from every entry, since all of it is agent- or tool-authored.
+func Transcript(env checkpoint.Envelope) ([]Entry, error) {
+	render, ok := renderers[env.Provider]
+	if !ok {
+		return nil, fmt.Errorf("continuation: no transcript renderer for provider %q", env.Provider)
+	}
+	if len(env.ProviderState) == 0 {
+		return nil, errors.New("continuation: envelope has no provider state")
+	}
+	entries, err := render(env.ProviderState)
+	if err != nil {
+		return nil, fmt.Errorf("continuation: render %s transcript: %w", env.Provider, err)
+	}
+	for i := range entries {
+		entries[i].Text = checkpoint.StripAnswerDelimiters(entries[i].Text)
+	}
+	return entries, nil
+}
+
+// New builds the Continuation for env. answers maps each pending tool call
+// ID to the friend's raw answer, as for an executor's Resume; a missing
+// answer is framed as the explicit empty-answer placeholder. Entries are
+// capped at DefaultEntryMaxBytes and the transcript at
+// DefaultTranscriptMaxBytes.
+func New(env checkpoint.Envelope, answers map[string]string) (*Continuation, error) {
+	framed, err := checkpoint.FramedAnswers(env.PendingToolCalls, answers, 0)
+	if err != nil {
+		return nil, fmt.Errorf("continuation: %w", err)
+	}
+	entries, err := Transcript(env)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Continuation{
+		Provider: env.Provider,
+		Model:    env.Model,
+		Pending:  make([]Pending, 0, len(framed)),
+	}
+	for i, f := range framed {
+		c.Pending = append(c.Pending, Pending{
+			Tool:     f.Name,
+			ID:       f.ID,
+			Question: checkpoint.CapText(checkpoint.StripAnswerDelimiters(checkpoint.QuestionFromPending(env.PendingToolCalls[i:i+1])), DefaultEntryMaxBytes),
+			Answer:   f.Text,
+		})
+	}
+
+	// Cap each entry, then keep the newest entries that fit the budget.
+	size := 0
+	start := len(entries)
+	for start > 0 {
+		e := entries[start-1]
+		e.Text = checkpoint.CapText(e.Text, DefaultEntryMaxBytes)
+		if size+len(e.Text) > DefaultTranscriptMaxBytes {
+			break
+		}
+		size += len(e.Text)
+		entries[start-1] = e
+		start--
+	}
+	c.Omitted = start
+	c.Entries = entries[start:]
+	return c, nil
+}
+
+// contentText flattens a message content value that is either a plain JSON
+// string or an array of typed blocks, keeping the text of every block that
+// has any. null and absent content yield "".
+func contentText(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	var blocks []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return "", err
+	}
+	texts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Text != "" {
+			texts = append(texts, b.Text)
+		}
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// compactJSON renders tool arguments or a structured result on one line.
+// Absent values render as "{}".
+func compactJSON(raw json.RawMessage) string {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return "{}"
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
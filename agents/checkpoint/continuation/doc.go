@@ -0,0 +1,39 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package continuation carries a parked ask-a-friend conversation across a
+// model change.
+//
+// A checkpoint.Envelope only resumes on the exact provider, model, and
+// configuration that parked it: checkpoint.ValidateForResume fails closed on
+// any drift, because one model's transcript (signed thinking blocks, provider
+// call IDs, cache markers) cannot be replayed to another. When a model is
+// deprecated or upgraded between park and wake, continuation is the opt-in
+// alternative to discarding the run: a brand-new run on the target model is
+// seeded with a plain-text rendering of the parked transcript, the pending
+// question, and the friend's answer.
+//
+// Transcript decodes an envelope's ProviderState with a per-provider renderer
+// (Anthropic MessageNewParams, Gemini chat history, OpenAI chat-completions
+// params) into provider-neutral Entry values; thinking content is dropped.
+// New assembles the Continuation — the bounded transcript plus each pending
+// question paired with its framed answer — which marshals as XML for binding
+// into a prompt with promptbuilder's BindXML:
+//
+//	c, err := continuation.New(*wake.Envelope, map[string]string{
+//		wake.Envelope.PendingToolCalls[0].ID: wake.Answer.Text,
+//	})
+//	suffix, err := prompt.BindXML("parked_conversation", c)
+//
+// metaagent.Continue wires this end to end; suspend.Coordinator's
+// ContinueOnDrift lets a drifted envelope through Wake so it can be continued
+// rather than swept.
+//
+// Every agent-authored string is stripped of the answer frame's delimiters
+// (checkpoint.StripAnswerDelimiters) and capped, so the transcript can neither
+// forge a friend answer nor crowd out the rest of the prompt; the answers
+// themselves are framed with checkpoint.FramedAnswers, exactly as a Resume
+// would frame them.
+package continuation
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package continuation_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/checkpoint/continuation"
+)
+
+func ExampleNew() {
+	// An envelope parked by the OpenAI-compatible executor.
+	env := checkpoint.Envelope{
+		Provider: checkpoint.ProviderOpenAI,
+		Model:    "old-model",
+		ProviderState: json.RawMessage(`{"messages":[
+			{"role":"user","content":"Fix the build"},
+			{"role":"assistant","tool_calls":[{"id":"call_ask","type":"function","function":{"name":"ask_a_friend","arguments":"{\"question\":\"Bump Go?\"}"}}]}]}`),
+		PendingToolCalls: []checkpoint.PendingToolCall{{
+			ID:        "call_ask",
+			Name:      "ask_a_friend",
+			InputJSON: json.RawMessage(`{"question":"Bump Go?"}`),
+		}},
+	}
+
+	c, err := continuation.New(env, map[string]string{"call_ask": "Yes."})
+	if err != nil {
+		panic(err)
+	}
+	out, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+	// Output:
+	// <parked_conversation provider="openai" model="old-model">
+	//   <entry role="user" kind="text">Fix the build</entry>
+	//   <entry role="assistant" kind="tool_call" tool="ask_a_friend" id="call_ask">{&#34;question&#34;:&#34;Bump Go?&#34;}</entry>
+	//   <pending_question tool="ask_a_friend" id="call_ask">
+	//     <question>Bump Go?</question>
+	//     <answer>&lt;&lt;&lt;BEGIN HUMAN ANSWER&gt;&gt;&gt;&#xA;Yes.&#xA;&lt;&lt;&lt;END HUMAN ANSWER&gt;&gt;&gt;</answer>
+	//   </pending_question>
+	// </parked_conversation>
+}
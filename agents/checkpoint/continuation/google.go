@@ -0,0 +1,58 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package continuation
+
+import (
+	"encoding/json"
+)
+
+// googleState is the subset of a genai.Content history JSON payload the
+// renderer reads.
+type googleState []struct {
+	Role  string `json:"role"`
+	Parts []struct {
+		Text         string `json:"text"`
+		Thought      bool   `json:"thought"`
+		FunctionCall *struct {
+			ID   string          `json:"id"`
+			Name string          `json:"name"`
+			Args json.RawMessage `json:"args"`
+		} `json:"functionCall"`
+		FunctionResponse *struct {
+			ID       string          `json:"id"`
+			Name     string          `json:"name"`
+			Response json.RawMessage `json:"response"`
+		} `json:"functionResponse"`
+	} `json:"parts"`
+}
+
+// renderGoogle renders a googleexecutor ProviderState: the chat history as it
+// stood at the pause point. Thought parts are dropped.
+func renderGoogle(raw json.RawMessage) ([]Entry, error) {
+	var state googleState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, c := range state {
+		role := RoleUser
+		if c.Role == "model" {
+			role = RoleAssistant
+		}
+		for _, p := range c.Parts {
+			switch {
+			case p.FunctionCall != nil:
+				entries = append(entries, Entry{Role: RoleAssistant, Kind: KindToolCall, Tool: p.FunctionCall.Name, ID: p.FunctionCall.ID, Text: compactJSON(p.FunctionCall.Args)})
+			case p.FunctionResponse != nil:
+				entries = append(entries, Entry{Role: RoleTool, Kind: KindToolResult, Tool: p.FunctionResponse.Name, ID: p.FunctionResponse.ID, Text: compactJSON(p.FunctionResponse.Response)})
+			case p.Text != "" && !p.Thought:
+				entries = append(entries, Entry{Role: role, Kind: KindText, Text: p.Text})
+			}
+		}
+	}
+	return entries, nil
+}
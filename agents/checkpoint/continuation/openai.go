@@ -0,0 +1,62 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package continuation
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// openAIState is the subset of an openai.ChatCompletionNewParams JSON payload
+// the renderer reads.
+type openAIState struct {
+	Messages []struct {
+		Role       string          `json:"role"`
+		Content    json.RawMessage `json:"content"`
+		ToolCallID string          `json:"tool_call_id"`
+		ToolCalls  []struct {
+			ID       string `json:"id"`
+			Function struct {
+				Name      string `json:"name"`
+				Arguments string `json:"arguments"`
+			} `json:"function"`
+		} `json:"tool_calls"`
+	} `json:"messages"`
+}
+
+// renderOpenAI renders an openaiexecutor ProviderState: the Messages of the
+// chat-completions request as it stood at the pause point. System and
+// developer messages are dropped.
+func renderOpenAI(raw json.RawMessage) ([]Entry, error) {
+	var state openAIState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	toolNames := make(map[string]string)
+	for i, m := range state.Messages {
+		text, err := contentText(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		switch m.Role {
+		case "user":
+			entries = append(entries, Entry{Role: RoleUser, Kind: KindText, Text: text})
+		case "assistant":
+			if text != "" {
+				entries = append(entries, Entry{Role: RoleAssistant, Kind: KindText, Text: text})
+			}
+			for _, tc := range m.ToolCalls {
+				toolNames[tc.ID] = tc.Function.Name
+				entries = append(entries, Entry{Role: RoleAssistant, Kind: KindToolCall, Tool: tc.Function.Name, ID: tc.ID, Text: compactJSON(json.RawMessage(tc.Function.Arguments))})
+			}
+		case "tool":
+			entries = append(entries, Entry{Role: RoleTool, Kind: KindToolResult, Tool: toolNames[m.ToolCallID], ID: m.ToolCallID, Text: text})
+		}
+	}
+	return entries, nil
+}
@@ -50,7 +50,10 @@ all match the live executor — the digest is required, so an empty digest on
 either side fails closed rather than vacuously matching — turn budget must
 remain, and any park-time Deadline must not have passed; drift surfaces as
 ErrConfigDrift so callers rebuild from scratch instead of resuming against
-stale state.
+stale state. A caller that would rather not lose the friend's answer to a
+model change can continue the parked conversation on the new model as a
+fresh run seeded with its plain-text transcript; see the continuation
+subpackage.
 
 # Usage
 
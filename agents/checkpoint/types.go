@@ -103,13 +103,12 @@ type Envelope struct {
 	// call identifier schemes differ per provider. A cross-model "resume" that
 	// silently transplanted a transcript would replay unverifiable state.
 	//
-	// TODO(DEV-2247): support continuing on a different model. One model's
-	// conversation can never be handed to another model directly — it will not
-	// verify or replay. The workable approach: start a brand-new run on the
-	// target model and feed it the parked conversation as plain text, together
-	// with the pending question and the friend's answer. Everything needed for
-	// that already lives in ProviderState, so no envelope schema change is
-	// required.
+	// Continuing on a different model is therefore a fresh run, not a resume:
+	// the checkpoint/continuation package renders ProviderState as a
+	// provider-neutral plain-text transcript, paired with the pending
+	// questions and the friend's framed answers, to seed a brand-new run on
+	// the target model (metaagent.Continue; suspend.Coordinator's
+	// ContinueOnDrift keeps a drifted envelope claimable for it).
 	ProviderState json.RawMessage `json:"provider_state,omitempty"`
 	// LoopState is executor-loop bookkeeping (turn index, cache-tail state, ...)
 	// carried opaquely so each executor owns its own shape.
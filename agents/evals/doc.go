@@ -62,5 +62,10 @@ namespacing each entry under the observer.
 
 The report subpackage turns a NamespacedObserver[*ResultCollector] tree into
 markdown evaluation reports.
+
+# Offline runs
+
+The replay subpackage records provider HTTP traffic to fixtures and replays
+it, so eval suites run in CI without network access or credentials.
 */
 package evals
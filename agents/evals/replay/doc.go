@@ -0,0 +1,74 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package replay records and replays model provider HTTP traffic so agent
+// tests and eval suites run deterministically without network access or
+// Vertex credentials.
+//
+// # Overview
+//
+// A Transport is an http.RoundTripper placed beneath a provider SDK client.
+// In ModeRecord it forwards each request to a wrapped transport and writes
+// the request/response pair to a fixture directory; in ModeReplay it serves
+// those responses back and fails any request it has no recording for.
+// Because it works at the HTTP layer, one Transport serves the claudeexecutor,
+// googleexecutor and openaiexecutor alike: hand Transport.Client to the SDK
+// client the executor is built on.
+//
+// Fixtures are keyed by RequestKey, a hash of the method, URL path, query and
+// canonicalised JSON body. Host and headers are excluded, so fixtures survive
+// a region change and never capture credentials. A request sent more than once
+// replays its recorded responses in order. Each key is one JSON file in the
+// directory, readable enough to review in a diff.
+//
+// # Usage
+//
+// ForTest picks the mode from the DRIFTLESSAF_RECORD environment variable
+// (RecordEnv) and fails the test on unmatched requests:
+//
+//	func TestTriage(t *testing.T) {
+//	    tr := replay.ForTest(t, "testdata/triage")
+//
+//	    // Claude: pass the client before the Vertex option, which layers OAuth
+//	    // on top of it.
+//	    client := anthropic.NewClient(
+//	        option.WithHTTPClient(tr.Client()),
+//	        vertex.WithGoogleAuth(ctx, region, projectID),
+//	    )
+//
+//	    // Gemini: a custom HTTPClient bypasses credential discovery, so
+//	    // recording needs an authorised transport (see WithTransport).
+//	    client, err := genai.NewClient(ctx, &genai.ClientConfig{
+//	        Backend:    genai.BackendVertexAI,
+//	        Project:    projectID,
+//	        Location:   region,
+//	        HTTPClient: tr.Client(),
+//	    })
+//
+//	    // OpenAI-compatible: likewise.
+//	    client := openai.NewClient(option.WithHTTPClient(tr.Client()), ...)
+//	}
+//
+// Record the fixtures once against the live provider and commit them:
+//
+//	DRIFTLESSAF_RECORD=1 go test ./...
+//
+// # Evals
+//
+// Eval suites combine ForTest with testevals: the agent runs against the
+// recorded responses and the evals package callbacks grade the resulting
+// trace, so a suite runs in CI without network access:
+//
+//	tr := replay.ForTest(t, "testdata/evals")
+//	obs := evals.NewNamespacedObserver(func(name string) evals.Observer {
+//	    return testevals.NewPrefix(t, name)
+//	})
+//	ctx = agenttrace.WithTracer[*Result](ctx, evals.BuildTracer[*Result](obs, suite))
+//	// build the executor over tr.Client() and run it
+//
+// Replay is only as deterministic as the requests: if a prompt, tool
+// definition or model changes, its key changes and the suite reports the
+// request as unrecorded until the fixtures are re-recorded.
+package replay
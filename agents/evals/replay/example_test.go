@@ -0,0 +1,57 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replay_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"chainguard.dev/driftlessaf/agents/evals/replay"
+)
+
+// ExampleNew records one exchange against a live endpoint, then replays it
+// after the endpoint has gone away.
+func ExampleNew() {
+	dir, err := os.MkdirTemp("", "replay")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"answer":"42"}`)
+	}))
+
+	send := func(tr *replay.Transport) string {
+		resp, err := tr.Client().Post(srv.URL+"/v1/messages", "application/json", strings.NewReader(`{"q":"life"}`))
+		if err != nil {
+			return err.Error()
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	rec, err := replay.New(dir, replay.ModeRecord)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("recorded:", send(rec))
+
+	srv.Close()
+	rep, err := replay.New(dir, replay.ModeReplay)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("replayed:", send(rep))
+	// Output:
+	// recorded: {"answer":"42"}
+	// replayed: {"answer":"42"}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode selects whether a Transport talks to the provider or to its fixtures.
+type Mode int
+
+const (
+	// ModeReplay serves recorded responses from the fixture directory and
+	// fails any request without a recording. It never touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards every request to the wrapped transport and persists
+	// the request/response pair to the fixture directory.
+	ModeRecord
+)
+
+// String implements fmt.Stringer.
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// ErrUnmatched is returned (wrapped) by a replaying Transport for a request
+// that has no remaining recorded response.
+var ErrUnmatched = errors.New("replay: no recorded response for request")
+
+// droppedResponseHeaders are not persisted: the stored body is already
+// decoded and its length is implied, and cookies have no business in a
+// fixture checked into a repository.
+var droppedResponseHeaders = []string{"Content-Length", "Content-Encoding", "Set-Cookie"}
+
+// fixture is the on-disk form of every exchange sharing one request key.
+// Responses are kept in the order they were recorded so a request the run
+// sends more than once (a retry, a repeated tool loop) replays in sequence.
+type fixture struct {
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Query       string          `json:"query,omitempty"`
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	Responses   []response      `json:"responses"`
+}
+
+type response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	// Body holds a UTF-8 body (JSON, SSE) verbatim; anything else is kept
+	// base64-encoded in BodyBytes.
+	Body      string `json:"body,omitempty"`
+	BodyBytes []byte `json:"body_bytes,omitempty"`
+}
+
+// Transport is an http.RoundTripper that records or replays provider
+// traffic. It sits beneath the provider SDK client, so it works unchanged for
+// the Anthropic, Gemini and OpenAI clients the executors are built on.
+type Transport struct {
+	dir   string
+	mode  Mode
+	inner http.RoundTripper
+
+	mu        sync.Mutex
+	fixtures  map[string]*fixture // replay: loaded; record: written so far
+	served    map[string]int      // replay: responses served per key
+	unmatched []string
+}
+
+// Option configures a Transport.
+type Option func(*Transport) error
+
+// WithTransport sets the transport a recording Transport forwards requests
+// to, typically one that carries the provider's credentials. The default is
+// http.DefaultTransport. Replay never uses it.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(t *Transport) error {
+		if rt == nil {
+			return errors.New("transport cannot be nil")
+		}
+		t.inner = rt
+		return nil
+	}
+}
+
+// New creates a Transport over the fixture directory dir. In ModeReplay the
+// directory's fixtures are loaded up front; in ModeRecord the directory is
+// created if needed and each exchange is written as soon as it completes, so a
+// run that fails part way still leaves its recordings behind.
+func New(dir string, mode Mode, opts ...Option) (*Transport, error) {
+	t := &Transport{
+		dir:      dir,
+		mode:     mode,
+		inner:    http.DefaultTransport,
+		fixtures: make(map[string]*fixture),
+		served:   make(map[string]int),
+	}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, fmt.Errorf("applying option: %w", err)
+		}
+	}
+
+	switch mode {
+	case ModeRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating fixture directory: %w", err)
+		}
+	case ModeReplay:
+		if err := t.load(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown mode %v", mode)
+	}
+	return t, nil
+}
+
+// Client returns an *http.Client that sends through t, for handing to a
+// provider SDK.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Mode reports whether t records or replays.
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// Unmatched returns a description of every request a replaying Transport
+// could not serve, in the order they arrived.
+func (t *Transport) Unmatched() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.unmatched...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	key, canonical := RequestKey(req, body)
+
+	if t.mode == ModeReplay {
+		return t.replay(req, key)
+	}
+	return t.record(req, key, body, canonical)
+}
+
+func (t *Transport) replay(req *http.Request, key string) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.fixtures[key]
+	n := t.served[key]
+	if !ok || n >= len(f.Responses) {
+		desc := fmt.Sprintf("%s %s (key %s, served %d)", req.Method, req.URL.Path, key, n)
+		t.unmatched = append(t.unmatched, desc)
+		return nil, fmt.Errorf("%w: %s", ErrUnmatched, desc)
+	}
+	t.served[key] = n + 1
+
+	r := f.Responses[n]
+	payload := r.BodyBytes
+	if payload == nil {
+		payload = []byte(r.Body)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(payload)),
+		ContentLength: int64(len(payload)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) record(req *http.Request, key string, body []byte, canonical json.RawMessage) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	// Let the underlying transport negotiate (and transparently undo)
+	// compression so the recorded body is the decoded payload.
+	out.Header.Del("Accept-Encoding")
+
+	resp, err := t.inner.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(payload))
+
+	r := response{StatusCode: resp.StatusCode, Header: resp.Header.Clone()}
+	for _, h := range droppedResponseHeaders {
+		r.Header.Del(h)
+	}
+	if utf8.Valid(payload) {
+		r.Body = string(payload)
+	} else {
+		r.BodyBytes = payload
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, ok := t.fixtures[key]
+	if !ok {
+		f = &fixture{
+			Method:      req.Method,
+			Path:        req.URL.Path,
+			Query:       canonicalQuery(req),
+			RequestBody: canonical,
+		}
+		t.fixtures[key] = f
+	}
+	f.Responses = append(f.Responses, r)
+	if err := t.write(key, f); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *Transport) load() error {
+	paths, err := filepath.Glob(filepath.Join(t.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("listing fixtures: %w", err)
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading fixture: %w", err)
+		}
+		var f fixture
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return fmt.Errorf("parsing fixture %s: %w", filepath.Base(path), err)
+		}
+		t.fixtures[strings.TrimSuffix(filepath.Base(path), ".json")] = &f
+	}
+	return nil
+}
+
+func (t *Transport) write(key string, f *fixture) error {
+	raw, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fixture: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, key+".json"), append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing fixture: %w", err)
+	}
+	return nil
+}
+
+// RequestKey returns the fixture key for a request with the given body, and
+// the canonical form of the body that went into it.
+//
+// The key is a hash of the method, URL path, sorted query and canonical body.
+// The scheme and host are left out so a fixture recorded against one regional
+// endpoint replays against another, and headers are left out so credentials
+// and SDK version stamps never affect matching. A JSON body is canonicalised
+// by re-encoding it, which sorts object keys and drops insignificant
+// whitespace; any other body is hashed as is.
+func RequestKey(req *http.Request, body []byte) (string, json.RawMessage) {
+	canonical := canonicalBody(body)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", req.Method, req.URL.Path, canonicalQuery(req))
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil))[:32], canonical
+}
+
+func canonicalBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	// UseNumber keeps large integers exact through the re-encode.
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		if out, err := json.Marshal(v); err == nil {
+			return out
+		}
+	}
+	// Not JSON: store it as a JSON string so the fixture stays readable.
+	out, _ := json.Marshal(string(body))
+	return out
+}
+
+func canonicalQuery(req *http.Request) string {
+	q := req.URL.Query()
+	for _, vs := range q {
+		sort.Strings(vs)
+	}
+	// Encode sorts by key.
+	return q.Encode()
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	// RoundTrip owns closing the request body even on error.
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingServer answers every request with "reply N" (N counting from 1)
+// and reports how many requests it saw.
+func countingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "reply %d", n.Add(1))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func post(t *testing.T, c *http.Client, url, body string) (string, error) {
+	t.Helper()
+	resp, err := c.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(out), nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	srv, hits := countingServer(t)
+
+	rec, err := New(dir, ModeRecord)
+	if err != nil {
+		t.Fatalf("New(record) error = %v", err)
+	}
+	for _, body := range []string{`{"model":"m","n":1}`, `{"model":"m","n":2}`, `{"model":"m","n":1}`} {
+		if _, err := post(t, rec.Client(), srv.URL+"/v1/messages?beta=true", body); err != nil {
+			t.Fatalf("record post error = %v", err)
+		}
+	}
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("server hits while recording: got = %d, want = 3", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("fixture files: got = %d, want = 2", len(entries))
+	}
+
+	srv.Close()
+	rep, err := New(dir, ModeReplay)
+	if err != nil {
+		t.Fatalf("New(replay) error = %v", err)
+	}
+	// Key order, whitespace and host are not part of the match, and a
+	// repeated request replays its recordings in order.
+	for _, tc := range []struct{ body, want string }{
+		{`{"n":1, "model":"m"}`, "reply 1"},
+		{`{"model":"m","n":2}`, "reply 2"},
+		{`{"model":"m","n":1}`, "reply 3"},
+	} {
+		got, err := post(t, rep.Client(), "http://elsewhere.invalid/v1/messages?beta=true", tc.body)
+		if err != nil {
+			t.Fatalf("replay post(%s) error = %v", tc.body, err)
+		}
+		if got != tc.want {
+			t.Errorf("replay post(%s): got = %q, want = %q", tc.body, got, tc.want)
+		}
+	}
+
+	// The recordings for n=1 are used up.
+	if _, err := post(t, rep.Client(), "http://elsewhere.invalid/v1/messages?beta=true", `{"model":"m","n":1}`); !errors.Is(err, ErrUnmatched) {
+		t.Errorf("exhausted replay error = %v, want ErrUnmatched", err)
+	}
+	if got := len(rep.Unmatched()); got != 1 {
+		t.Errorf("Unmatched(): got = %d entries, want = 1", got)
+	}
+}
+
+func TestReplayUnmatched(t *testing.T) {
+	rep, err := New(t.TempDir(), ModeReplay)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := post(t, rep.Client(), "http://example.invalid/v1/messages", `{}`); !errors.Is(err, ErrUnmatched) {
+		t.Errorf("post() error = %v, want ErrUnmatched", err)
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	key := func(method, url, body string) string {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		k, _ := RequestKey(req, []byte(body))
+		return k
+	}
+
+	base := key("POST", "https://a.example/v1/x?b=2&a=1", `{"a":1,"b":[1,2]}`)
+	for name, tc := range map[string]struct {
+		method, url, body string
+		same              bool
+	}{
+		"host and query order ignored": {"POST", "https://b.example/v1/x?a=1&b=2", `{"b":[1,2],"a":1}`, true},
+		"whitespace ignored":           {"POST", "https://a.example/v1/x?a=1&b=2", "{ \"a\": 1,\n \"b\": [1, 2] }", true},
+		"method matters":               {"PUT", "https://a.example/v1/x?a=1&b=2", `{"a":1,"b":[1,2]}`, false},
+		"path matters":                 {"POST", "https://a.example/v1/y?a=1&b=2", `{"a":1,"b":[1,2]}`, false},
+		"query matters":                {"POST", "https://a.example/v1/x?a=1", `{"a":1,"b":[1,2]}`, false},
+		"array order matters":          {"POST", "https://a.example/v1/x?a=1&b=2", `{"a":1,"b":[2,1]}`, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := key(tc.method, tc.url, tc.body) == base; got != tc.same {
+				t.Errorf("same key: got = %v, want = %v", got, tc.same)
+			}
+		})
+	}
+}
+
+func TestForTestMode(t *testing.T) {
+	t.Setenv(RecordEnv, "")
+	if got := ForTest(t, t.TempDir()).Mode(); got != ModeReplay {
+		t.Errorf("Mode() unset: got = %v, want = %v", got, ModeReplay)
+	}
+	t.Setenv(RecordEnv, "1")
+	if got := ForTest(t, t.TempDir()).Mode(); got != ModeRecord {
+		t.Errorf("Mode() recording: got = %v, want = %v", got, ModeRecord)
+	}
+}
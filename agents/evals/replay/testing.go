@@ -0,0 +1,45 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package replay
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+// RecordEnv is the environment variable that switches ForTest into
+// ModeRecord. Any value strconv.ParseBool accepts as true enables recording;
+// unset, eval suites replay.
+const RecordEnv = "DRIFTLESSAF_RECORD"
+
+// ForTest returns a Transport over dir for use in a test or eval suite. It
+// replays by default and records when RecordEnv is set, so the same suite runs
+// offline in CI and re-records against the live provider on demand:
+//
+//	DRIFTLESSAF_RECORD=1 go test ./...
+//
+// When replaying, the test fails at cleanup if any request went unmatched;
+// the transport's error alone may be swallowed or retried by an SDK. A setup
+// error fails the test immediately.
+func ForTest(t testing.TB, dir string, opts ...Option) *Transport {
+	t.Helper()
+
+	mode := ModeReplay
+	if record, _ := strconv.ParseBool(os.Getenv(RecordEnv)); record {
+		mode = ModeRecord
+	}
+	tr, err := New(dir, mode, opts...)
+	if err != nil {
+		t.Fatalf("replay.New(%s, %v) error = %v", dir, mode, err)
+	}
+	t.Cleanup(func() {
+		for _, miss := range tr.Unmatched() {
+			t.Errorf("unrecorded request: %s (re-record with %s=1)", miss, RecordEnv)
+		}
+	})
+	return tr
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"chainguard.dev/driftlessaf/agents/model"
+)
+
+// DefaultNudgeAt is the fraction of a limit at which the model is nudged to
+// submit when Config.NudgeAt is zero.
+const DefaultNudgeAt = 0.8
+
+// Config configures a per-run spend budget for an executor's conversation
+// loop. At least one of MaxTokens and MaxCostUSD must be set; when both are,
+// whichever is reached first applies.
+type Config struct {
+	// MaxTokens caps the run's total token usage: input, output, cache reads
+	// and cache writes combined. Zero means no token cap.
+	MaxTokens int64
+
+	// MaxCostUSD caps the run's estimated cost in US dollars, computed from
+	// its token usage with Prices. Zero means no cost cap.
+	MaxCostUSD float64
+
+	// Prices maps the executor's model to its token prices. Required when
+	// MaxCostUSD is set; a run whose model has no price fails before its
+	// first request rather than running uncapped.
+	Prices PriceTable
+
+	// NudgeAt is the fraction of a limit, in (0, 1], at which the model is
+	// told once to wrap up and submit its result. Zero means
+	// DefaultNudgeAt.
+	NudgeAt float64
+}
+
+// Validate reports whether the config is usable.
+func (c Config) Validate() error {
+	switch {
+	case c.MaxTokens < 0:
+		return errors.New("budget max tokens cannot be negative")
+	case c.MaxCostUSD < 0:
+		return errors.New("budget max cost cannot be negative")
+	case c.MaxTokens == 0 && c.MaxCostUSD == 0:
+		return errors.New("budget must set max tokens, max cost, or both")
+	case c.MaxCostUSD > 0 && c.Prices == nil:
+		return errors.New("budget max cost requires a price table")
+	case c.NudgeAt < 0 || c.NudgeAt > 1:
+		return fmt.Errorf("budget nudge fraction must be in (0, 1], got %v", c.NudgeAt)
+	}
+	return nil
+}
+
+// Usage is a run's accumulated token usage. InputTokens excludes the tokens
+// counted in CacheReadTokens and CacheWriteTokens, so the four fields never
+// overlap.
+type Usage struct {
+	InputTokens      int64 `json:"input_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+}
+
+// Total returns the sum of every token category.
+func (u Usage) Total() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// Price is a model's token pricing in US dollars per million tokens.
+type Price struct {
+	Input      float64
+	Output     float64
+	CacheRead  float64
+	CacheWrite float64
+}
+
+// Cost returns the cost of u at these prices, in US dollars.
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheReadTokens)*p.CacheRead +
+		float64(u.CacheWriteTokens)*p.CacheWrite) / 1e6
+}
+
+// PriceTable looks up a model's token prices. Implementations must be safe
+// for concurrent use.
+type PriceTable interface {
+	// Price returns the prices for model, and false when it is unknown.
+	Price(model string) (Price, bool)
+}
+
+// Prices is a static PriceTable keyed by model name. A model matches its
+// exact key or, failing that, the longest key it starts with, so one entry
+// covers a model's dated and regional variants (for example
+// "claude-sonnet-4-6" covers "claude-sonnet-4-6@20260101"). An empty key
+// matches every model and so acts as the fallback price. Lookups are
+// case-insensitive.
+type Prices map[string]Price
+
+var _ PriceTable = Prices(nil)
+
+// Price implements PriceTable.
+func (p Prices) Price(model string) (Price, bool) {
+	model = strings.ToLower(model)
+	var (
+		best    Price
+		bestLen = -1
+	)
+	for key, price := range p {
+		key = strings.ToLower(key)
+		if key == model {
+			return price, true
+		}
+		if strings.HasPrefix(model, key) && len(key) > bestLen {
+			best, bestLen = price, len(key)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// Registry is a PriceTable backed by the model registry: it returns the
+// Price that Register, Load or LoadEnv declared for a model (see
+// model.Info.Price), and false for models declared without one.
+type Registry struct{}
+
+var _ PriceTable = Registry{}
+
+// Price implements PriceTable.
+func (Registry) Price(id string) (Price, bool) {
+	p := model.Resolve(id).Price
+	if p == nil {
+		return Price{}, false
+	}
+	return Price(*p), true
+}
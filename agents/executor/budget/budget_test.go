@@ -0,0 +1,193 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/model"
+)
+
+func TestConfigValidate(t *testing.T) {
+	prices := Prices{"m": {Input: 1}}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "tokens", cfg: Config{MaxTokens: 1000}},
+		{name: "cost", cfg: Config{MaxCostUSD: 1, Prices: prices}},
+		{name: "both", cfg: Config{MaxTokens: 1000, MaxCostUSD: 1, Prices: prices, NudgeAt: 0.5}},
+		{name: "empty", cfg: Config{}, wantErr: true},
+		{name: "negative tokens", cfg: Config{MaxTokens: -1}, wantErr: true},
+		{name: "negative cost", cfg: Config{MaxCostUSD: -1, Prices: prices}, wantErr: true},
+		{name: "cost without prices", cfg: Config{MaxCostUSD: 1}, wantErr: true},
+		{name: "nudge over one", cfg: Config{MaxTokens: 1000, NudgeAt: 1.5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate: got = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPricesLookup(t *testing.T) {
+	prices := Prices{
+		"":                  {Input: 1},
+		"claude-sonnet":     {Input: 2},
+		"claude-sonnet-4-6": {Input: 3},
+		"gemini-2.5-pro":    {Input: 4},
+	}
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{model: "claude-sonnet-4-6", want: 3},
+		{model: "Claude-Sonnet-4-6@20260101", want: 3},
+		{model: "claude-sonnet-4-5", want: 2},
+		{model: "gemini-2.5-pro", want: 4},
+		{model: "unknown", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := prices.Price(tt.model)
+			if !ok || got.Input != tt.want {
+				t.Errorf("Price(%q): got = %v, %v, want = %v", tt.model, got.Input, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := (Prices{"claude": {}}).Price("gemini"); ok {
+		t.Error("Price: matched a model with no entry")
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	model.Register("test-budget-priced", model.Info{
+		Backend: model.BackendOpenAICompat,
+		Price:   &model.Price{Input: 1, Output: 2, CacheRead: 0.1, CacheWrite: 1.25},
+	})
+
+	got, ok := Registry{}.Price("test-budget-priced-v2")
+	if want := (Price{Input: 1, Output: 2, CacheRead: 0.1, CacheWrite: 1.25}); !ok || got != want {
+		t.Errorf("Price: got = %+v, %v, want = %+v", got, ok, want)
+	}
+	if _, ok := (Registry{}).Price("claude-sonnet-4-6"); ok {
+		t.Error("Price: a built-in model without a declared price matched")
+	}
+}
+
+func TestTrackerTokens(t *testing.T) {
+	tracker, err := Config{MaxTokens: 1000}.NewTracker("m")
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+
+	tracker.Add(Usage{InputTokens: 500, OutputTokens: 100, CacheReadTokens: 100})
+	if tracker.Nudge() {
+		t.Error("Nudge: fired at 70% of the limit")
+	}
+	if err := tracker.Err(); err != nil {
+		t.Errorf("Err: got = %v under the limit", err)
+	}
+
+	tracker.Add(Usage{CacheWriteTokens: 100})
+	if !tracker.Nudge() {
+		t.Error("Nudge: did not fire at 80% of the limit")
+	}
+	if tracker.Nudge() {
+		t.Error("Nudge: fired twice")
+	}
+
+	// Reaching the limit exactly is allowed; going over is not.
+	tracker.Add(Usage{OutputTokens: 200})
+	if err := tracker.Err(); err != nil {
+		t.Errorf("Err: got = %v at the limit", err)
+	}
+	tracker.Add(Usage{OutputTokens: 1})
+	exceeded, ok := AsExceeded(fmt.Errorf("wrapped: %w", tracker.Err()))
+	if !ok {
+		t.Fatalf("AsExceeded: not an *ExceededError: %v", tracker.Err())
+	}
+	if exceeded.Limit != LimitTokens || exceeded.Usage.Total() != 1001 || exceeded.MaxTokens != 1000 {
+		t.Errorf("ExceededError: got = %+v", exceeded)
+	}
+}
+
+func TestTrackerCost(t *testing.T) {
+	cfg := Config{
+		MaxTokens:  1_000_000,
+		MaxCostUSD: 1,
+		Prices:     Prices{"m": {Input: 1, Output: 10, CacheRead: 0.1, CacheWrite: 2}},
+	}
+	if _, err := cfg.NewTracker("other"); err == nil {
+		t.Error("NewTracker: accepted a model with no price")
+	}
+
+	tracker, err := cfg.NewTracker("m")
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	// $0.10 + $0.50 + $0.01 + $0.40 = $1.01, well under the token cap.
+	tracker.Add(Usage{InputTokens: 100_000, OutputTokens: 50_000, CacheReadTokens: 100_000, CacheWriteTokens: 200_000})
+	exceeded, ok := AsExceeded(tracker.Err())
+	if !ok {
+		t.Fatalf("Err: got = %v, want an *ExceededError", tracker.Err())
+	}
+	if exceeded.Limit != LimitCost {
+		t.Errorf("Limit: got = %q, want = %q", exceeded.Limit, LimitCost)
+	}
+	if got, want := fmt.Sprintf("%.2f", exceeded.CostUSD), "1.01"; got != want {
+		t.Errorf("CostUSD: got = %s, want = %s", got, want)
+	}
+}
+
+func TestTrackerRestore(t *testing.T) {
+	cfg := Config{MaxTokens: 100}
+	first, err := cfg.NewTracker("m")
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	first.Add(Usage{InputTokens: 70, OutputTokens: 10})
+	if !first.Nudge() {
+		t.Fatal("Nudge: did not fire at 80% of the limit")
+	}
+
+	// The state survives a JSON round trip, as it does in a checkpoint.
+	raw, err := json.Marshal(first.State())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	second, err := cfg.NewTracker("m")
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	second.Restore(state)
+	if second.Nudge() {
+		t.Error("Nudge: fired again after Restore")
+	}
+	second.Add(Usage{OutputTokens: 21})
+	if _, ok := AsExceeded(second.Err()); !ok {
+		t.Errorf("Err: got = %v, want the restored usage to count against the limit", second.Err())
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	tracker.Add(Usage{InputTokens: 1 << 40})
+	if tracker.Err() != nil || tracker.Nudge() || tracker.CostUSD() != 0 || tracker.Usage().Total() != 0 || tracker.State() != (State{}) {
+		t.Error("nil Tracker: want an unlimited no-op")
+	}
+}
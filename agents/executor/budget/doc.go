@@ -0,0 +1,53 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package budget caps what a single agent run may spend.
+
+# Overview
+
+WithMaxTurns and WithMaxToolCallsBeforeFinalize bound how many times an agent
+iterates, not what those iterations cost: a run that re-reads a large
+context every turn can cost far more than its turn count suggests. A Config
+caps a run's total tokens (MaxTokens), its estimated dollar cost
+(MaxCostUSD), or both.
+
+Each executor creates a Tracker per run and feeds it the usage of every
+model response — the same input, output and cache counts it records on the
+trace via agenttrace.LLMTurn. Cost is derived from those counts with a
+pluggable PriceTable; Prices is a static table keyed by model name prefix,
+and Registry reads the prices declared in the model registry, for example
+from a file loaded with model.LoadEnv.
+
+# Enforcement
+
+Once usage reaches Config.NudgeAt of a limit (80% by default), the executor
+sends the model a single NudgeMessage asking it to submit its result now.
+Before every later model request it checks the limits, and a run that has
+gone over is aborted with an *ExceededError, which AsExceeded extracts. A
+result submitted on the turn that crossed the limit is still returned: the
+tokens are already spent, and discarding the result would waste them.
+
+The budget applies to the whole run, across pauses: when a run parks on a
+checkpoint (an ask-a-friend suspension or a pending Message Batch), the
+executor saves the Tracker's State in the envelope's LoopState, and the
+resume or batch poll that continues it restores that State before its first
+request. A resumed run therefore cannot spend the budget afresh, and a run
+already nudged is not nudged again.
+
+# Usage
+
+	exec, err := claudeexecutor.New[*Request, *Result](client, prompt,
+		claudeexecutor.WithBudget[*Request, *Result](budget.Config{
+			MaxCostUSD: 2,
+			Prices: budget.Prices{
+				"claude-sonnet-4-6": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+			},
+		}),
+	)
+
+metaagent.Config.Budget applies the same config on every backend.
+*/
+package budget
@@ -0,0 +1,39 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package budget_test
+
+import (
+	"errors"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+)
+
+// ExampleConfig_NewTracker shows a run nearing, then exceeding, its cost cap.
+func ExampleConfig_NewTracker() {
+	cfg := budget.Config{
+		MaxCostUSD: 1,
+		Prices:     budget.Prices{"example-model": {Input: 2, Output: 10}},
+	}
+	tracker, err := cfg.NewTracker("example-model@2026")
+	if err != nil {
+		panic(err)
+	}
+
+	// $0.80: the nudge fires once.
+	tracker.Add(budget.Usage{InputTokens: 300_000, OutputTokens: 20_000})
+	fmt.Println("nudge:", tracker.Nudge(), tracker.Nudge())
+
+	// $1.10: over the cap.
+	tracker.Add(budget.Usage{InputTokens: 150_000})
+	var exceeded *budget.ExceededError
+	if err := tracker.Err(); errors.As(err, &exceeded) {
+		fmt.Printf("exceeded %s: $%.2f\n", exceeded.Limit, exceeded.CostUSD)
+	}
+	// Output:
+	// nudge: true false
+	// exceeded cost: $1.10
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package budget
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+)
+
+// Limit names the budget limit a run exceeded.
+type Limit string
+
+const (
+	// LimitTokens is Config.MaxTokens.
+	LimitTokens Limit = "tokens"
+	// LimitCost is Config.MaxCostUSD.
+	LimitCost Limit = "cost"
+)
+
+// ExceededError is returned by an executor whose run went over its budget.
+// The run is aborted before its next model request; a result the model
+// submits on the turn that crosses the limit is still returned. Extract it
+// with AsExceeded.
+type ExceededError struct {
+	// Limit is the limit that was exceeded.
+	Limit Limit
+	// Model is the model the run was priced as.
+	Model string
+	// Usage is the run's token usage when it was aborted.
+	Usage Usage
+	// CostUSD is the run's estimated cost when it was aborted, or zero when
+	// the budget has no cost cap.
+	CostUSD float64
+	// MaxTokens and MaxCostUSD are the configured limits.
+	MaxTokens  int64
+	MaxCostUSD float64
+}
+
+// Error implements the error interface. Return it as a pointer so AsExceeded
+// can extract it through wrapped error chains.
+func (e *ExceededError) Error() string {
+	if e.Limit == LimitCost {
+		return fmt.Sprintf("agent exceeded its cost budget: $%.4f of $%.4f (model %q, %d tokens)",
+			e.CostUSD, e.MaxCostUSD, e.Model, e.Usage.Total())
+	}
+	return fmt.Sprintf("agent exceeded its token budget: %d of %d tokens (model %q)",
+		e.Usage.Total(), e.MaxTokens, e.Model)
+}
+
+// AsExceeded reports whether err is (or wraps) an *ExceededError and, if so,
+// returns it.
+func AsExceeded(err error) (*ExceededError, bool) {
+	var e *ExceededError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// Tracker accumulates one run's spend against a Config. A nil *Tracker is a
+// valid, unlimited tracker, so executors call it unconditionally. A Tracker
+// is not safe for concurrent use; a run's conversation loop is sequential.
+type Tracker struct {
+	cfg    Config
+	model  string
+	price  Price
+	usage  Usage
+	nudged bool
+}
+
+// State is what a Tracker has recorded: the usage so far and whether the
+// nudge has fired. Executors persist it in a checkpoint envelope so a run that
+// is parked and resumed continues against the same budget.
+type State struct {
+	Usage  Usage `json:"usage"`
+	Nudged bool  `json:"nudged,omitempty"`
+}
+
+// NewTracker returns a Tracker for one run against model. It fails when the
+// config caps cost and the price table has no entry for model, so a run is
+// never left uncapped by a missing price.
+func (c Config) NewTracker(model string) (*Tracker, error) {
+	t := &Tracker{cfg: c, model: model}
+	if c.MaxCostUSD > 0 {
+		price, ok := c.Prices.Price(model)
+		if !ok {
+			return nil, fmt.Errorf("budget: no price for model %q", model)
+		}
+		t.price = price
+	}
+	return t, nil
+}
+
+// Add records the usage of one model response.
+func (t *Tracker) Add(u Usage) {
+	if t == nil {
+		return
+	}
+	t.usage.InputTokens += u.InputTokens
+	t.usage.OutputTokens += u.OutputTokens
+	t.usage.CacheReadTokens += u.CacheReadTokens
+	t.usage.CacheWriteTokens += u.CacheWriteTokens
+}
+
+// State returns what t has recorded so far, for persisting across a
+// checkpoint.
+func (t *Tracker) State() State {
+	if t == nil {
+		return State{}
+	}
+	return State{Usage: t.usage, Nudged: t.nudged}
+}
+
+// Restore seeds t with a State saved by an earlier leg of the same run, so
+// its limits and nudge apply to the run's total spend.
+func (t *Tracker) Restore(s State) {
+	if t == nil {
+		return
+	}
+	t.usage = s.Usage
+	t.nudged = s.Nudged
+}
+
+// Usage returns the usage recorded so far.
+func (t *Tracker) Usage() Usage {
+	if t == nil {
+		return Usage{}
+	}
+	return t.usage
+}
+
+// CostUSD returns the estimated cost of the usage recorded so far, or zero
+// when the budget has no cost cap.
+func (t *Tracker) CostUSD() float64 {
+	if t == nil {
+		return 0
+	}
+	return t.price.Cost(t.usage)
+}
+
+// Err returns an *ExceededError once the recorded usage is over a limit, and
+// nil otherwise. Executors call it before each model request.
+func (t *Tracker) Err() error {
+	if t == nil {
+		return nil
+	}
+	var limit Limit
+	switch {
+	case t.cfg.MaxTokens > 0 && t.usage.Total() > t.cfg.MaxTokens:
+		limit = LimitTokens
+	case t.cfg.MaxCostUSD > 0 && t.CostUSD() > t.cfg.MaxCostUSD:
+		limit = LimitCost
+	default:
+		return nil
+	}
+	return &ExceededError{
+		Limit:      limit,
+		Model:      t.model,
+		Usage:      t.usage,
+		CostUSD:    t.CostUSD(),
+		MaxTokens:  t.cfg.MaxTokens,
+		MaxCostUSD: t.cfg.MaxCostUSD,
+	}
+}
+
+// Nudge reports whether the model should now be told to wrap up: it returns
+// true exactly once, the first time the recorded usage reaches the nudge
+// fraction of a limit.
+func (t *Tracker) Nudge() bool {
+	if t == nil || t.nudged {
+		return false
+	}
+	at := cmp.Or(t.cfg.NudgeAt, DefaultNudgeAt)
+	near := (t.cfg.MaxTokens > 0 && float64(t.usage.Total()) >= at*float64(t.cfg.MaxTokens)) ||
+		(t.cfg.MaxCostUSD > 0 && t.CostUSD() >= at*t.cfg.MaxCostUSD)
+	t.nudged = near
+	return near
+}
+
+// NudgeMessage is the instruction executors send when Nudge fires. It names
+// submitTool when the run has one.
+func NudgeMessage(submitTool string) string {
+	if submitTool == "" {
+		return "You are nearly out of budget for this task. Stop investigating and return your final response now, based on what you have found so far."
+	}
+	return fmt.Sprintf("You are nearly out of budget for this task. Stop investigating and call the %s tool now to return your result based on what you have found so far.", submitTool)
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/executor/retry"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/chainguard-dev/clog"
+)
+
+// DefaultBatchPollInterval is how long a workqueue key waits between polls of
+// a pending Message Batch when WithBatchMode is given a zero interval. Batches
+// typically complete within minutes and always within 24 hours; polling more
+// often than this only burns reconciler cycles.
+const DefaultBatchPollInterval = time.Minute
+
+// batchReason is the Envelope.Reason (and trace suspension reason) stamped on
+// a run parked awaiting a Message Batch result.
+const batchReason = "anthropic_batch"
+
+// batchState is the Envelope.LoopState of a run parked on a Message Batch: the
+// batch to poll, the custom ID of this run's request within it, when it was
+// submitted (for the wait recorded on the collecting turn), and the run's
+// budget spend so far. Budget shares its key with execshared.LoopState, so
+// runConversation restores it the same way as on Resume.
+type batchState struct {
+	BatchID     string        `json:"batch_id"`
+	CustomID    string        `json:"custom_id"`
+	SubmittedAt time.Time     `json:"submitted_at"`
+	Budget      *budget.State `json:"budget,omitempty"`
+}
+
+// batchResult is a Message Batch result handed to runConversation by
+// PollBatch, consumed by the first turn in place of a streaming call.
+type batchResult struct {
+	message anthropic.Message
+	batchID string
+	wait    time.Duration
+}
+
+// WithBatchMode submits every turn through the Anthropic Message Batches API
+// instead of the streaming Messages API, trading latency for the batch
+// discount. It suits latency-insensitive workloads (evals, nightly sweeps) run
+// from a workqueue reconciler.
+//
+// In batch mode Execute runs no turns itself: it submits the first request
+// and returns a *BatchPending error carrying a checkpoint.Envelope and the
+// interval to poll after. The caller persists the envelope (see ParkBatch)
+// and, when the key is redelivered, hands it to BatchPoller.PollBatch, which
+// either returns the same *BatchPending (the batch is still processing) or
+// runs the collected turn's tool calls and submits the next turn — returning
+// a new *BatchPending — until the run produces its Response. Tool handlers
+// therefore run in the poll that collects their turn.
+//
+// pollInterval is the RequeueAfter delay between polls; zero selects
+// DefaultBatchPollInterval. Batch mode requires WithProvider(ProviderAnthropic):
+// Vertex AI does not serve the Message Batches API (validated in New).
+func WithBatchMode[Request promptbuilder.Bindable, Response any](pollInterval time.Duration) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if pollInterval < 0 {
+			return fmt.Errorf("batch poll interval must be non-negative, got %v", pollInterval)
+		}
+		if pollInterval == 0 {
+			pollInterval = DefaultBatchPollInterval
+		}
+		e.batchPollInterval = pollInterval
+		return nil
+	}
+}
+
+// BatchPending is the error Execute and PollBatch return while a turn's
+// request is waiting in a Message Batch. Like checkpoint.Suspension it is an
+// intentional halt, not a failure: the trace is finalized as suspended and
+// the embedded Envelope carries everything PollBatch needs to collect the
+// result and continue the conversation.
+//
+// Unlike a Suspension the envelope has no pending tool calls — nothing awaits
+// a human — so it must not be parked through suspend.Coordinator, whose
+// Validate requires one. Save it directly (ParkBatch) instead.
+type BatchPending struct {
+	// Envelope is the serializable capture of the waiting conversation; its
+	// LoopState names the batch to poll.
+	checkpoint.Envelope
+
+	// BatchID is the Message Batch the turn's request was submitted to.
+	BatchID string
+
+	// PollAfter is how long the caller should wait before polling again.
+	PollAfter time.Duration
+}
+
+// Error implements error.
+func (bp *BatchPending) Error() string {
+	return fmt.Sprintf("agent turn %d awaiting message batch %s", bp.Turn, bp.BatchID)
+}
+
+// AsBatchPending reports whether err is (or wraps) a *BatchPending and, if
+// so, returns it. It mirrors checkpoint.AsSuspension.
+func AsBatchPending(err error) (*BatchPending, bool) {
+	var bp *BatchPending
+	if errors.As(err, &bp) {
+		return bp, true
+	}
+	return nil, false
+}
+
+// ParkBatch saves bp's envelope under key and returns the workqueue error
+// that redelivers key after bp.PollAfter, so a reconciler can end with:
+//
+//	if bp, ok := claudeexecutor.AsBatchPending(err); ok {
+//		return claudeexecutor.ParkBatch(ctx, store, key, bp)
+//	}
+//
+// On redelivery the reconciler Loads the envelope and calls PollBatch.
+func ParkBatch(ctx context.Context, store checkpoint.Store, key string, bp *BatchPending) error {
+	env := bp.Envelope
+	env.ReconcilerKey = key
+	if err := store.Save(ctx, key, &env); err != nil {
+		return fmt.Errorf("park batch %s: %w", bp.BatchID, err)
+	}
+	return workqueue.RequeueAfter(bp.PollAfter)
+}
+
+// BatchPoller is the capability interface for continuing a run submitted in
+// batch mode (see WithBatchMode). Like Resumer it is kept off Interface so
+// existing consumers compile unmodified; a batch-mode caller type-asserts the
+// Interface returned by New.
+type BatchPoller[Request promptbuilder.Bindable, Response any] interface {
+	// PollBatch checks the Message Batch named by env. While it is still
+	// processing PollBatch returns a *BatchPending for the same batch. Once it
+	// has ended PollBatch continues the conversation on the collected result,
+	// returning either the run's Response, a *BatchPending for the next turn,
+	// or a *checkpoint.Suspension. It returns checkpoint.ErrConfigDrift
+	// (wrapped) when the live executor config no longer matches env.
+	PollBatch(ctx context.Context, env checkpoint.Envelope, tools map[string]claudetool.Metadata[Response]) (Response, error)
+}
+
+var _ BatchPoller[promptbuilder.Bindable, any] = (*executor[promptbuilder.Bindable, any])(nil)
+
+// PollBatch implements BatchPoller.
+func (e *executor[Request, Response]) PollBatch(
+	ctx context.Context,
+	env checkpoint.Envelope,
+	tools map[string]claudetool.Metadata[Response],
+) (response Response, err error) {
+	// The same fail-closed gate as Resume: the collected turn continues the
+	// conversation only against the configuration that submitted it.
+	staticParams, _, _, serr := e.buildStaticParams(tools)
+	if serr != nil {
+		return response, fmt.Errorf("build static params for digest: %w", serr)
+	}
+	turnBudget, verr := execshared.ValidateResume(env, suspendProviderName, e.modelName, staticParams, e.maxTurns)
+	if verr != nil {
+		return response, verr
+	}
+	var state batchState
+	if uerr := json.Unmarshal(env.LoopState, &state); uerr != nil || state.BatchID == "" {
+		return response, fmt.Errorf("poll batch: envelope carries no batch state (reason=%q)", env.Reason)
+	}
+
+	batch, gerr := retry.RetryWithBackoff(ctx, e.retryConfig, "get_message_batch", isRetryableClaudeError, func() (*anthropic.MessageBatch, error) {
+		return e.client.Messages.Batches.Get(ctx, state.BatchID)
+	})
+	if gerr != nil {
+		if requeueErr := retry.RequeueIfRetryable(ctx, gerr, isRetryableClaudeError, "Claude API"); requeueErr != nil {
+			return response, requeueErr
+		}
+		return response, fmt.Errorf("poll message batch %s: %w", state.BatchID, gerr)
+	}
+	if batch.ProcessingStatus != anthropic.MessageBatchProcessingStatusEnded {
+		clog.InfoContext(ctx, "Message batch still processing",
+			"batch_id", state.BatchID, "status", batch.ProcessingStatus, "waited", time.Since(state.SubmittedAt))
+		return response, &BatchPending{Envelope: env, BatchID: state.BatchID, PollAfter: e.batchPollInterval}
+	}
+
+	message, rerr := e.batchMessage(ctx, state)
+	if rerr != nil {
+		return response, rerr
+	}
+
+	// Restore the request exactly as submitted. Its tail cache markers are
+	// stripped and reseeded by a fresh tracker, as on Resume.
+	var params anthropic.MessageNewParams
+	if uerr := json.Unmarshal(env.ProviderState, &params); uerr != nil {
+		return response, fmt.Errorf("poll batch: unmarshal provider state: %w", uerr)
+	}
+	stripMessageCacheControl(params.Messages)
+
+	trace, done := execshared.StartResumeTrace[Response](ctx, env)
+	defer func() {
+		done(response, err)
+	}()
+	tail := newTailBreakpoints(params)
+
+	// The collected turn is the envelope's own turn (it was submitted, not
+	// run), so the loop re-enters at env.Turn with the result prefetched.
+	response, err = e.runConversation(ctx, trace, params, tools, tail, env.Turn, turnBudget, nil, env.LoopState, &batchResult{
+		message: message,
+		batchID: state.BatchID,
+		wait:    time.Since(state.SubmittedAt),
+	})
+	return response, err
+}
+
+// batchMessage streams the ended batch's results and returns the message for
+// state's request. A request that did not succeed (errored, canceled, or
+// expired) is an error: it is not retried within the batch.
+func (e *executor[Request, Response]) batchMessage(ctx context.Context, state batchState) (anthropic.Message, error) {
+	stream := e.client.Messages.Batches.ResultsStreaming(ctx, state.BatchID)
+	defer stream.Close()
+	for stream.Next() {
+		r := stream.Current()
+		if r.CustomID != state.CustomID {
+			continue
+		}
+		if r.Result.Type != "succeeded" {
+			return anthropic.Message{}, fmt.Errorf("message batch %s request %s %s: %s",
+				state.BatchID, state.CustomID, r.Result.Type, r.Result.Error.Error.Message)
+		}
+		return r.Result.Message, nil
+	}
+	if err := stream.Err(); err != nil {
+		return anthropic.Message{}, fmt.Errorf("read message batch %s results: %w", state.BatchID, err)
+	}
+	return anthropic.Message{}, fmt.Errorf("message batch %s has no result for request %s", state.BatchID, state.CustomID)
+}
+
+// submitBatch submits params as a one-request Message Batch and returns the
+// *BatchPending that parks the run until PollBatch collects the result.
+// remainingTurns is the run's turn budget including this turn, and spend its
+// budget tracker, saved so the poll continues against the same budget.
+func (e *executor[Request, Response]) submitBatch(
+	ctx context.Context,
+	llmTurn *agenttrace.LLMTurn[Response],
+	cfg retry.RetryConfig,
+	params anthropic.MessageNewParams,
+	tools map[string]claudetool.Metadata[Response],
+	turn, remainingTurns int,
+	spend *budget.Tracker,
+	traceID string,
+) (*BatchPending, error) {
+	// MessageBatchNewParamsRequestParams carries the same fields as
+	// MessageNewParams, so a JSON round-trip converts one to the other without
+	// enumerating them.
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal batch request: %w", err)
+	}
+	var reqParams anthropic.MessageBatchNewParamsRequestParams
+	if err := json.Unmarshal(raw, &reqParams); err != nil {
+		return nil, fmt.Errorf("convert batch request: %w", err)
+	}
+	customID := fmt.Sprintf("turn-%d", turn)
+
+	batch, err := retry.RetryWithBackoff(ctx, cfg, "create_message_batch", isRetryableClaudeError, func() (*anthropic.MessageBatch, error) {
+		return e.client.Messages.Batches.New(ctx, anthropic.MessageBatchNewParams{
+			Requests: []anthropic.MessageBatchNewParamsRequest{{
+				CustomID: customID,
+				Params:   reqParams,
+			}},
+		})
+	})
+	e.telemetry.RecordAPIRequest(ctx, err)
+	if err != nil {
+		if requeueErr := retry.RequeueIfRetryable(ctx, err, isRetryableClaudeError, "Claude API"); requeueErr != nil {
+			return nil, requeueErr
+		}
+		return nil, fmt.Errorf("failed to create Claude message batch: %w", err)
+	}
+	llmTurn.RecordBatch(batch.ID, 0)
+
+	staticParams, _, _, err := e.buildStaticParams(tools)
+	if err != nil {
+		return nil, fmt.Errorf("build static params for digest: %w", err)
+	}
+	digest, err := checkpoint.DigestJSON(staticParams)
+	if err != nil {
+		return nil, err
+	}
+	loopState, err := json.Marshal(batchState{
+		BatchID:     batch.ID,
+		CustomID:    customID,
+		SubmittedAt: time.Now(),
+		Budget:      execshared.BudgetState(spend),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal batch state: %w", err)
+	}
+	clog.InfoContext(ctx, "Submitted turn to message batch", "batch_id", batch.ID, "turn", turn)
+	return &BatchPending{
+		Envelope: checkpoint.Envelope{
+			Version:        checkpoint.EnvelopeVersion,
+			Provider:       suspendProviderName,
+			Model:          e.modelName,
+			ConfigDigest:   digest,
+			Turn:           turn,
+			RemainingTurns: remainingTurns,
+			Reason:         batchReason,
+			ProviderState:  raw,
+			LoopState:      loopState,
+			TraceID:        traceID,
+		},
+		BatchID:   batch.ID,
+		PollAfter: e.batchPollInterval,
+	}, nil
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/checkpoint/memstore"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/claudeexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// fakeBatchServer is a minimal Message Batches API. Each created batch is
+// answered, once ended, with the next entry of results (a result object as
+// the results JSONL carries it).
+type fakeBatchServer struct {
+	t       *testing.T
+	results []string
+
+	mu       sync.Mutex
+	created  []json.RawMessage // request params of each created batch
+	ended    map[string]bool
+	resultOf map[string]string
+}
+
+func newFakeBatchServer(t *testing.T, results ...string) (*fakeBatchServer, *httptest.Server) {
+	f := &fakeBatchServer{t: t, results: results, ended: map[string]bool{}, resultOf: map[string]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+// end marks every created batch as ended.
+func (f *fakeBatchServer) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id := range f.resultOf {
+		f.ended[id] = true
+	}
+}
+
+func (f *fakeBatchServer) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/messages/batches")
+	switch {
+	case r.Method == http.MethodPost && path == "":
+		var body struct {
+			Requests []struct {
+				CustomID string          `json:"custom_id"`
+				Params   json.RawMessage `json:"params"`
+			} `json:"requests"`
+		}
+		raw, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(raw, &body); err != nil || len(body.Requests) != 1 {
+			f.t.Errorf("create batch: want one request, got %s", raw)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := len(f.created)
+		if n >= len(f.results) {
+			f.t.Errorf("create batch: unexpected batch %d", n+1)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.created = append(f.created, body.Requests[0].Params)
+		id := fmt.Sprintf("msgbatch_%d", n+1)
+		f.resultOf[id] = fmt.Sprintf(`{"custom_id":%q,"result":%s}`, body.Requests[0].CustomID, f.results[n])
+		_, _ = io.WriteString(w, batchJSON(id, false))
+	case r.Method == http.MethodGet && strings.HasSuffix(path, "/results"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/results")
+		w.Header().Set("Content-Type", "application/x-jsonl")
+		_, _ = io.WriteString(w, f.resultOf[id]+"\n")
+	case r.Method == http.MethodGet:
+		id := strings.TrimPrefix(path, "/")
+		_, _ = io.WriteString(w, batchJSON(id, f.ended[id]))
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func batchJSON(id string, ended bool) string {
+	status := "in_progress"
+	if ended {
+		status = "ended"
+	}
+	return fmt.Sprintf(`{"id":%q,"type":"message_batch","processing_status":%q,"created_at":"2026-01-01T00:00:00Z","expires_at":"2026-01-02T00:00:00Z","request_counts":{"processing":1,"succeeded":0,"errored":0,"canceled":0,"expired":0}}`, id, status)
+}
+
+// succeeded renders a succeeded batch result carrying one assistant message
+// with the given content blocks.
+func succeeded(stopReason string, content ...string) string {
+	return fmt.Sprintf(`{"type":"succeeded","message":{"id":"msg","type":"message","role":"assistant","model":"claude-sonnet-4-6","content":[%s],"stop_reason":%q,"usage":{"input_tokens":10,"output_tokens":5}}}`,
+		strings.Join(content, ","), stopReason)
+}
+
+func newBatchExecutor(t *testing.T, srv *httptest.Server, opts ...claudeexecutor.Option[errCapRequest, errCapResponse]) claudeexecutor.Interface[errCapRequest, errCapResponse] {
+	t.Helper()
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	opts = append([]claudeexecutor.Option[errCapRequest, errCapResponse]{
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithProvider[errCapRequest, errCapResponse](claudeexecutor.ProviderAnthropic),
+		claudeexecutor.WithBatchMode[errCapRequest, errCapResponse](time.Minute),
+	}, opts...)
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](client, prompt, opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return exec
+}
+
+// TestBatchModeToolLoop drives a two-turn run through batches: the first
+// collected turn calls a tool, which runs in the poll that collected it and
+// submits the second turn; the second collected turn answers.
+func TestBatchModeToolLoop(t *testing.T) {
+	f, srv := newFakeBatchServer(t,
+		succeeded("tool_use", `{"type":"tool_use","id":"toolu_1","name":"lookup","input":{"q":"answer"}}`),
+		succeeded("end_turn", `{"type":"text","text":"{\"answer\":\"42\"}"}`),
+	)
+	const maxTurns = 5
+	exec := newBatchExecutor(t, srv, claudeexecutor.WithMaxTurns[errCapRequest, errCapResponse](maxTurns))
+	poller, ok := exec.(claudeexecutor.BatchPoller[errCapRequest, errCapResponse])
+	if !ok {
+		t.Fatal("executor does not implement BatchPoller")
+	}
+
+	lookups := 0
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"lookup": {
+			Definition: anthropic.ToolParam{Name: "lookup", InputSchema: anthropic.ToolInputSchemaParam{Type: "object"}},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				lookups++
+				return map[string]any{"value": "42"}
+			},
+		},
+	}
+	tracer := &recordingTracer{}
+	ctx := agenttrace.WithTracer[errCapResponse](t.Context(), tracer)
+
+	// Execute submits the first turn and parks.
+	_, err := exec.Execute(ctx, errCapRequest{}, tools)
+	bp, ok := claudeexecutor.AsBatchPending(err)
+	if !ok {
+		t.Fatalf("Execute: got %v, want a *BatchPending", err)
+	}
+	if bp.BatchID != "msgbatch_1" || bp.Turn != 0 || bp.RemainingTurns != maxTurns || bp.PollAfter != time.Minute {
+		t.Errorf("BatchPending: got batch=%q turn=%d remaining=%d poll=%v, want msgbatch_1, 0, %d, 1m",
+			bp.BatchID, bp.Turn, bp.RemainingTurns, bp.PollAfter, maxTurns)
+	}
+
+	// Polling before the batch ends keeps the run parked on the same batch.
+	_, err = poller.PollBatch(ctx, bp.Envelope, tools)
+	if again, ok := claudeexecutor.AsBatchPending(err); !ok || again.BatchID != bp.BatchID {
+		t.Fatalf("PollBatch (in progress): got %v, want a *BatchPending for %s", err, bp.BatchID)
+	}
+
+	// The first turn's result calls the tool and submits the second turn.
+	f.end()
+	_, err = poller.PollBatch(ctx, bp.Envelope, tools)
+	bp, ok = claudeexecutor.AsBatchPending(err)
+	if !ok {
+		t.Fatalf("PollBatch (turn 0): got %v, want a *BatchPending", err)
+	}
+	if bp.BatchID != "msgbatch_2" || bp.Turn != 1 || bp.RemainingTurns != maxTurns-1 {
+		t.Errorf("BatchPending: got batch=%q turn=%d remaining=%d, want msgbatch_2, 1, %d",
+			bp.BatchID, bp.Turn, bp.RemainingTurns, maxTurns-1)
+	}
+	if lookups != 1 {
+		t.Errorf("lookup calls: got = %d, want = 1", lookups)
+	}
+	if got := string(f.created[1]); !strings.Contains(got, `"tool_use_id":"toolu_1"`) {
+		t.Errorf("second batch request does not carry the tool result: %s", got)
+	}
+
+	f.end()
+	got, err := poller.PollBatch(ctx, bp.Envelope, tools)
+	if err != nil {
+		t.Fatalf("PollBatch (turn 1): %v", err)
+	}
+	if got.Answer != "42" {
+		t.Errorf("Answer: got = %q, want = %q", got.Answer, "42")
+	}
+
+	// Each submitting and collecting turn is attributed to its batch.
+	var batches []string
+	for _, tr := range tracer.traces {
+		for _, turn := range tr.Turns {
+			batches = append(batches, fmt.Sprintf("%d:%s", turn.Index, turn.BatchID))
+		}
+	}
+	if got, want := strings.Join(batches, " "), "0:msgbatch_1 0:msgbatch_1 1:msgbatch_2 1:msgbatch_2"; got != want {
+		t.Errorf("recorded turns: got = %q, want = %q", got, want)
+	}
+}
+
+// TestBatchModeBudgetSpansPolls checks each poll restores the spend saved with
+// the batch it collects: at 15 tokens per turn against a 25-token cap, the
+// second poll is over budget and aborts instead of submitting a third batch.
+func TestBatchModeBudgetSpansPolls(t *testing.T) {
+	lookup := `{"type":"tool_use","id":"toolu_%d","name":"lookup","input":{}}`
+	f, srv := newFakeBatchServer(t,
+		succeeded("tool_use", fmt.Sprintf(lookup, 1)),
+		succeeded("tool_use", fmt.Sprintf(lookup, 2)),
+	)
+	exec := newBatchExecutor(t, srv, claudeexecutor.WithBudget[errCapRequest, errCapResponse](budget.Config{MaxTokens: 25}))
+	poller := exec.(claudeexecutor.BatchPoller[errCapRequest, errCapResponse])
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"lookup": {
+			Definition: anthropic.ToolParam{Name: "lookup", InputSchema: anthropic.ToolInputSchemaParam{Type: "object"}},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"value": "42"}
+			},
+		},
+	}
+
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	bp, ok := claudeexecutor.AsBatchPending(err)
+	if !ok {
+		t.Fatalf("Execute: got %v, want a *BatchPending", err)
+	}
+	f.end()
+	_, err = poller.PollBatch(t.Context(), bp.Envelope, tools)
+	if bp, ok = claudeexecutor.AsBatchPending(err); !ok {
+		t.Fatalf("PollBatch (turn 0): got %v, want a *BatchPending", err)
+	}
+	f.end()
+	_, err = poller.PollBatch(t.Context(), bp.Envelope, tools)
+	exceeded, ok := budget.AsExceeded(err)
+	if !ok {
+		t.Fatalf("PollBatch (turn 1): got %v, want a *budget.ExceededError", err)
+	}
+	if got, want := exceeded.Usage.Total(), int64(30); got != want {
+		t.Errorf("ExceededError.Usage.Total: got = %d, want = %d", got, want)
+	}
+	if got, want := len(f.created), 2; got != want {
+		t.Errorf("batches created: got = %d, want = %d", got, want)
+	}
+}
+
+func TestBatchModeErroredResult(t *testing.T) {
+	f, srv := newFakeBatchServer(t,
+		`{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}}`)
+	exec := newBatchExecutor(t, srv)
+
+	_, err := exec.Execute(t.Context(), errCapRequest{}, nil)
+	bp, ok := claudeexecutor.AsBatchPending(err)
+	if !ok {
+		t.Fatalf("Execute: got %v, want a *BatchPending", err)
+	}
+	f.end()
+	_, err = exec.(claudeexecutor.BatchPoller[errCapRequest, errCapResponse]).PollBatch(t.Context(), bp.Envelope, nil)
+	if err == nil || !strings.Contains(err.Error(), "prompt is too long") {
+		t.Errorf("PollBatch: got %v, want the errored result's message", err)
+	}
+}
+
+func TestBatchModeConfigDrift(t *testing.T) {
+	_, srv := newFakeBatchServer(t, succeeded("end_turn", `{"type":"text","text":"{}"}`))
+	exec := newBatchExecutor(t, srv)
+
+	_, err := exec.Execute(t.Context(), errCapRequest{}, nil)
+	bp, ok := claudeexecutor.AsBatchPending(err)
+	if !ok {
+		t.Fatalf("Execute: got %v, want a *BatchPending", err)
+	}
+	drifted := newBatchExecutor(t, srv, claudeexecutor.WithModel[errCapRequest, errCapResponse]("claude-opus-4-8"))
+	_, err = drifted.(claudeexecutor.BatchPoller[errCapRequest, errCapResponse]).PollBatch(t.Context(), bp.Envelope, nil)
+	if !errors.Is(err, checkpoint.ErrConfigDrift) {
+		t.Errorf("PollBatch: got %v, want checkpoint.ErrConfigDrift", err)
+	}
+}
+
+func TestParkBatch(t *testing.T) {
+	store := memstore.New()
+	bp := &claudeexecutor.BatchPending{
+		Envelope:  checkpoint.Envelope{Provider: checkpoint.ProviderAnthropic, Turn: 2},
+		BatchID:   "msgbatch_1",
+		PollAfter: 5 * time.Minute,
+	}
+
+	err := claudeexecutor.ParkBatch(t.Context(), store, "org/repo#1", bp)
+	if delay, ok := workqueue.GetRequeueDelay(err); !ok || delay != 5*time.Minute {
+		t.Errorf("ParkBatch: got %v, want RequeueAfter(5m)", err)
+	}
+	env, _, ok, err := store.Load(t.Context(), "org/repo#1")
+	if err != nil || !ok {
+		t.Fatalf("Load: ok=%v err=%v", ok, err)
+	}
+	if env.ReconcilerKey != "org/repo#1" || env.Turn != 2 {
+		t.Errorf("saved envelope: got key=%q turn=%d, want org/repo#1, 2", env.ReconcilerKey, env.Turn)
+	}
+}
+
+func TestWithBatchModeValidation(t *testing.T) {
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	for name, opts := range map[string][]claudeexecutor.Option[errCapRequest, errCapResponse]{
+		"vertex provider": {
+			claudeexecutor.WithBatchMode[errCapRequest, errCapResponse](0),
+		},
+		"negative interval": {
+			claudeexecutor.WithProvider[errCapRequest, errCapResponse](claudeexecutor.ProviderAnthropic),
+			claudeexecutor.WithBatchMode[errCapRequest, errCapResponse](-time.Second),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := claudeexecutor.New[errCapRequest, errCapResponse](anthropic.NewClient(), prompt, opts...); err == nil {
+				t.Error("New: got nil error, want a rejection")
+			}
+		})
+	}
+}
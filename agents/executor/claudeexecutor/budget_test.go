@@ -0,0 +1,201 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/claudeexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// TestBudgetNudgesThenAborts drives a model that never finishes against a
+// $1.10 cost cap at $0.48 per turn (input, cache reads and output priced
+// separately): the third request carries the nudge, and the run is aborted
+// with a *budget.ExceededError before a fourth request is sent.
+func TestBudgetNudgesThenAborts(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingAnthropicServer(t, func(reqNum int, body []byte) []string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		return []string{
+			fmt.Sprintf(`{"type":"message_start","message":{"id":"msg_%d","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":100000,"cache_read_input_tokens":100000,"output_tokens":1}}}`, reqNum),
+			fmt.Sprintf(`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_%d","name":"look","input":{}}}`, reqNum),
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"reasoning\":\"look\"}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":10000}}`,
+			`{"type":"message_stop"}`,
+		}
+	})
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		claudeexecutor.WithModel[errCapRequest, errCapResponse]("claude-sonnet-4-6"),
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithBudget[errCapRequest, errCapResponse](budget.Config{
+			MaxCostUSD: 1.10,
+			Prices: budget.Prices{
+				"claude-sonnet-4-6": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"look": {
+			Definition: anthropic.ToolParam{
+				Name:        "look",
+				Description: anthropic.String("Look around."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": true}
+			},
+		},
+	}
+
+	_, err = exec.Execute(t.Context(), errCapRequest{}, tools)
+	exceeded, ok := budget.AsExceeded(err)
+	if !ok {
+		t.Fatalf("Execute: got = %v, want a *budget.ExceededError", err)
+	}
+	if exceeded.Limit != budget.LimitCost || exceeded.Model != "claude-sonnet-4-6" {
+		t.Errorf("ExceededError: got = %+v, want the cost limit for claude-sonnet-4-6", exceeded)
+	}
+	if got, want := fmt.Sprintf("%.2f", exceeded.CostUSD), "1.44"; got != want {
+		t.Errorf("CostUSD: got = %s, want = %s", got, want)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got = %d, want = 3", len(bodies))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if got := strings.Count(bodies[i], "nearly out of budget for this task"); got != want {
+			t.Errorf("request %d nudges: got = %d, want = %d", i+1, got, want)
+		}
+	}
+}
+
+// TestBudgetSpansResumes parks a run on ask_a_friend twice against a
+// 250-token cap at 101 tokens per turn. Each resume restores the spend saved
+// in the envelope, so the second resume sends the nudge on its first request
+// and is aborted before a second: the budget covers the run, not each leg.
+func TestBudgetSpansResumes(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingAnthropicServer(t, func(reqNum int, body []byte) []string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		name, input := askAFriendToolName, map[string]any{"question": "ship it?"}
+		if reqNum > 2 {
+			name, input = "look", map[string]any{"reasoning": "look"}
+		}
+		events := []string{
+			fmt.Sprintf(`{"type":"message_start","message":{"id":"msg_%d","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":100,"output_tokens":1}}}`, reqNum),
+		}
+		events = append(events, toolUseBlockSSE(t, 0, fmt.Sprintf("toolu_%d", reqNum), name, input)...)
+		return append(events,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":1}}`,
+			`{"type":"message_stop"}`,
+		)
+	})
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithSuspendTool[errCapRequest, errCapResponse](askAFriendProvider()),
+		claudeexecutor.WithBudget[errCapRequest, errCapResponse](budget.Config{MaxTokens: 250}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resumer := exec.(claudeexecutor.Resumer[errCapRequest, errCapResponse])
+
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"look": {
+			Definition: anthropic.ToolParam{
+				Name:        "look",
+				Description: anthropic.String("Look around."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": true}
+			},
+		},
+	}
+
+	_, err = exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got = %v, want a *checkpoint.Suspension", err)
+	}
+	_, err = resumer.Resume(t.Context(), susp.Envelope, map[string]string{"toolu_1": "yes"}, tools)
+	if susp, ok = checkpoint.AsSuspension(err); !ok {
+		t.Fatalf("first Resume: got = %v, want a *checkpoint.Suspension", err)
+	}
+	_, err = resumer.Resume(t.Context(), susp.Envelope, map[string]string{"toolu_2": "yes"}, tools)
+	exceeded, ok := budget.AsExceeded(err)
+	if !ok {
+		t.Fatalf("second Resume: got = %v, want a *budget.ExceededError", err)
+	}
+	if got, want := exceeded.Usage.Total(), int64(303); got != want {
+		t.Errorf("ExceededError.Usage.Total: got = %d, want = %d", got, want)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got = %d, want = 3", len(bodies))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if got := strings.Count(bodies[i], "nearly out of budget for this task"); got != want {
+			t.Errorf("request %d nudges: got = %d, want = %d", i+1, got, want)
+		}
+	}
+}
@@ -24,7 +24,9 @@ const tailBreakpointLimit = 2
 // tailPosition identifies one content block within params.Messages by index.
 // Indices remain valid for the lifetime of an execution because the
 // conversation loop only ever appends messages and never mutates the content
-// slices of earlier messages.
+// slices of earlier messages. Compaction (WithCompaction) rewrites only the
+// inner content of earlier tool_result blocks, leaving the blocks themselves,
+// and any marker on them, in place.
 type tailPosition struct {
 	message int
 	block   int
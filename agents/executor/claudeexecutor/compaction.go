@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor
+
+import (
+	"context"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// compact runs a compaction step over params.Messages ahead of the request
+// for turn. Candidates are the text-only tool_result blocks before the last
+// assistant message — the ones after it answer that message's tool calls and
+// have not been seen by the model yet. An elided block keeps its tool_use_id
+// and cache_control marker; only its content is swapped for the placeholder.
+func (e *executor[Request, Response]) compact(ctx context.Context, trace *agenttrace.Trace[Response], turn int, params *anthropic.MessageNewParams) error {
+	if e.compaction == nil {
+		return nil
+	}
+	var (
+		positions []tailPosition
+		sizes     []int
+	)
+	lastAssistant := -1
+	for m, msg := range params.Messages {
+		if msg.Role == anthropic.MessageParamRoleAssistant {
+			lastAssistant = m
+		}
+	}
+	for m := 0; m < lastAssistant; m++ {
+		for b, block := range params.Messages[m].Content {
+			if size, ok := toolResultTextSize(block.OfToolResult); ok {
+				positions = append(positions, tailPosition{message: m, block: b})
+				sizes = append(sizes, size)
+			}
+		}
+	}
+	return execshared.Compact(ctx, trace, e.compaction, turn, params.Messages, sizes, func(i int, placeholder string) {
+		pos := positions[i]
+		params.Messages[pos.message].Content[pos.block].OfToolResult.Content = []anthropic.ToolResultBlockParamContentUnion{{
+			OfText: &anthropic.TextBlockParam{Text: placeholder},
+		}}
+	})
+}
+
+// toolResultTextSize returns the total text size of a tool_result block made
+// only of text, and false for anything else: a non-tool_result block, one
+// carrying images or documents, or one already elided.
+func toolResultTextSize(tr *anthropic.ToolResultBlockParam) (int, bool) {
+	if tr == nil || len(tr.Content) == 0 {
+		return 0, false
+	}
+	size := 0
+	for _, c := range tr.Content {
+		if c.OfText == nil {
+			return 0, false
+		}
+		if compaction.IsPlaceholder(c.OfText.Text) {
+			return 0, false
+		}
+		size += len(c.OfText.Text)
+	}
+	return size, true
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/claudeexecutor"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/submitresult"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// TestCompactionElidesOldToolResults drives three large tool results through
+// a tight compaction budget. The validating server checks every request keeps
+// tool_use/tool_result pairing and the cache-breakpoint budget; the test then
+// checks the final request carried the oldest result as a placeholder and the
+// step landed on the trace.
+func TestCompactionElidesOldToolResults(t *testing.T) {
+	toolCallTurn := func(n int) []string {
+		return []string{
+			fmt.Sprintf(`{"type":"message_start","message":{"id":"msg_%d","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`, n),
+			fmt.Sprintf(`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_%d","name":"read","input":{}}}`, n),
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"reasoning\":\"read\"}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":1}}`,
+			`{"type":"message_stop"}`,
+		}
+	}
+	finalTurn := []string{
+		`{"type":"message_start","message":{"id":"msg_final","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":20,"output_tokens":5}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"{\"answer\":\"done\"}"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+		`{"type":"message_stop"}`,
+	}
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingAnthropicServer(t, func(reqNum int, body []byte) []string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if reqNum <= 3 {
+			return toolCallTurn(reqNum)
+		}
+		return finalTurn
+	})
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithCompaction[errCapRequest, errCapResponse](compaction.Config{Threshold: 6000, KeepRecent: 1}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	output := strings.Repeat("x", 20_000)
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"read": {
+			Definition: anthropic.ToolParam{
+				Name:        "read",
+				Description: anthropic.String("Read a large file."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"content": output}
+			},
+		},
+	}
+
+	tracer := &recordingTracer{}
+	ctx := agenttrace.WithTracer[errCapResponse](t.Context(), tracer)
+	if _, err := exec.Execute(ctx, errCapRequest{}, tools); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if len(bodies) != 4 {
+		t.Fatalf("requests: got = %d, want = 4", len(bodies))
+	}
+	// Requests 2 and 3 stay under budget (the newest results are never
+	// candidates); request 4 elides the oldest result only.
+	for i, want := range []int{0, 0, 0, 1} {
+		if got := strings.Count(bodies[i], "[elided tool result:"); got != want {
+			t.Errorf("request %d placeholders: got = %d, want = %d", i+1, got, want)
+		}
+	}
+	if got, want := strings.Count(bodies[3], output), 2; got != want {
+		t.Errorf("request 4 verbatim results: got = %d, want = %d", got, want)
+	}
+
+	if len(tracer.traces) != 1 {
+		t.Fatalf("traces: got = %d, want = 1", len(tracer.traces))
+	}
+	compactions := tracer.traces[0].Compactions
+	if len(compactions) != 1 {
+		t.Fatalf("Compactions: got = %d, want = 1", len(compactions))
+	}
+	if c := compactions[0]; c.Turn != 3 || c.ElidedToolResults != 1 || c.EstimatedTokensAfter >= c.EstimatedTokensBefore {
+		t.Errorf("Compactions[0]: got = %+v, want turn 3 eliding one result", c)
+	}
+}
+
+// TestCompactionSparesResultsBeforeNudge checks that a user message appended
+// after a turn's tool results, here the early-finalize nudge, does not make
+// those unseen results compaction candidates: with KeepRecent 1 the newest
+// result the model has seen is kept, and the unseen one is not counted
+// against it, so nothing is elided.
+func TestCompactionSparesResultsBeforeNudge(t *testing.T) {
+	toolCallTurn := func(n int) []string {
+		return []string{
+			fmt.Sprintf(`{"type":"message_start","message":{"id":"msg_%d","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`, n),
+			fmt.Sprintf(`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_%d","name":"read","input":{}}}`, n),
+			`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"reasoning\":\"read\"}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":1}}`,
+			`{"type":"message_stop"}`,
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingAnthropicServer(t, func(reqNum int, body []byte) []string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if reqNum <= 2 {
+			return toolCallTurn(reqNum)
+		}
+		return submitCallTurn(t, "msg_submit", "toolu_submit", submitInput("done"))
+	})
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](submitresult.ClaudeToolForResponse[errCapResponse]),
+		claudeexecutor.WithMaxToolCallsBeforeFinalize[errCapRequest, errCapResponse](2),
+		claudeexecutor.WithCompaction[errCapRequest, errCapResponse](compaction.Config{Threshold: 6000, KeepRecent: 1}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	output := strings.Repeat("x", 20_000)
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"read": {
+			Definition: anthropic.ToolParam{
+				Name:        "read",
+				Description: anthropic.String("Read a large file."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"content": output}
+			},
+		},
+	}
+
+	resp, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got, want := resp.Answer, "done"; got != want {
+		t.Errorf("resp.Answer: got = %q, want = %q", got, want)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got = %d, want = 3", len(bodies))
+	}
+	last := bodies[2]
+	if !strings.Contains(last, "You have gathered enough evidence") {
+		t.Fatal("request 3: early-finalize nudge missing")
+	}
+	if got := strings.Count(last, "[elided tool result:"); got != 0 {
+		t.Errorf("request 3 placeholders: got = %d, want = 0", got)
+	}
+	if got, want := strings.Count(last, output), 2; got != want {
+		t.Errorf("request 3 verbatim results: got = %d, want = %d", got, want)
+	}
+}
@@ -72,6 +72,10 @@ SPDX-License-Identifier: Apache-2.0
 //     initial user message, outside the shared cacheable prefix (off by default)
 //   - WithMaxToolCallsBeforeFinalize: Soft-cap the agentic loop (off by default)
 //   - WithForceSubmitToolChoice: Force the terminal submit tool via tool_choice (off by default)
+//   - WithBatchMode: Submit turns through the Message Batches API (off by default)
+//   - WithCompaction: Elide older tool results past a context budget (off by default)
+//   - WithBudget: Cap each run's tokens and estimated cost (off by default)
+//   - WithObserver: Stream progress events as the run proceeds (off by default)
 //
 // # Prompt Caching
 //
@@ -97,6 +101,36 @@ SPDX-License-Identifier: Apache-2.0
 // tools + system + payload prefix from a single cache entry instead of each
 // re-paying the payload at full input price.
 //
+// # Batch Mode
+//
+// WithBatchMode trades latency for the Message Batches discount on workloads
+// nobody waits on (evals, nightly sweeps). Each turn is submitted as a
+// one-request batch and the run parks with a *BatchPending error instead of
+// blocking; a workqueue reconciler saves its envelope and requeues:
+//
+//	resp, err := exec.Execute(ctx, req, tools)
+//	if bp, ok := claudeexecutor.AsBatchPending(err); ok {
+//	    return claudeexecutor.ParkBatch(ctx, store, key, bp)
+//	}
+//
+// When the key is redelivered, the reconciler loads the envelope and calls
+// PollBatch (via the BatchPoller capability interface), which returns the
+// same *BatchPending while the batch is processing and otherwise runs the
+// collected turn's tool calls and submits the next turn — or returns the
+// Response. Each collected turn is recorded on the trace with its batch ID
+// and wait (agenttrace LLMTurn.RecordBatch), which the cost view prices at
+// the batch rate. Batch mode requires WithProvider(ProviderAnthropic).
+//
+// # Context Compaction
+//
+// WithCompaction keeps long tool loops within the context window. Before each
+// turn whose estimated conversation size exceeds the configured threshold, the
+// oldest tool_result blocks have their content replaced by a short
+// placeholder. The blocks themselves stay, so tool_use pairing holds and the
+// tail cache breakpoints keep their positions, although the cached prefix is
+// re-written from the first elided block. Each step is recorded as an
+// agenttrace.Compaction on the trace.
+//
 // # Extended Thinking
 //
 // Extended thinking allows Claude to show its internal reasoning process before
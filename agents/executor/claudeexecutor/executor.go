@@ -13,12 +13,16 @@ import (
 	"fmt"
 	"reflect"
 	"slices"
+	"time"
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
 	"chainguard.dev/driftlessaf/agents/checkpoint"
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
 	"chainguard.dev/driftlessaf/agents/executor/internal/telemetry"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/metrics"
 	"chainguard.dev/driftlessaf/agents/model"
@@ -159,6 +163,26 @@ type executor[Request promptbuilder.Bindable, Response any] struct {
 	// response to the constraints its jsonschema struct tags declare; callers
 	// append semantic validators via WithResultValidator (repeatable).
 	resultValidators []callbacks.ResultValidator[Response]
+
+	// batchPollInterval, when greater than zero, enables batch mode: every
+	// turn is submitted through the Message Batches API and the run parks with
+	// a *BatchPending that polls again after this interval, instead of
+	// streaming. Zero (the default) streams every turn. Set via WithBatchMode.
+	batchPollInterval time.Duration
+
+	// compaction, when non-nil, elides older tool results from the
+	// conversation once its estimated size crosses the configured threshold.
+	// Nil (the default) keeps every tool result verbatim. Set via
+	// WithCompaction.
+	compaction *compaction.Config
+
+	// budget, when non-nil, caps each run's token usage and estimated cost.
+	// Nil (the default) leaves spend uncapped. Set via WithBudget.
+	budget *budget.Config
+
+	// observer receives the run's progress events as they happen. Nil (the
+	// default) discards them. Set via WithObserver.
+	observer progress.Observer
 }
 
 // maxCacheBreakpoints is the Anthropic API's hard limit on the number of
@@ -215,6 +239,12 @@ func New[Request promptbuilder.Bindable, Response any](
 		}
 	}
 
+	// The output-token limit and effort are checked against the model
+	// registry once the model is final, whatever order the options came in.
+	if err := execshared.ValidateModelLimits(e.modelName, e.maxTokens, effort.Level(e.effort)); err != nil {
+		return nil, err
+	}
+
 	// Forcing a submit tool_choice is incompatible with extended thinking: the
 	// API rejects a forced tool_choice while thinking is active. Checked after
 	// all options are applied so the conflict is caught regardless of the order
@@ -236,6 +266,13 @@ func New[Request promptbuilder.Bindable, Response any](
 		}
 	}
 
+	// The Message Batches API is served only by the Anthropic first-party API.
+	// Checked after all options are applied so the order WithBatchMode and
+	// WithProvider were supplied in is irrelevant.
+	if e.batchPollInterval > 0 && e.provider != ProviderAnthropic {
+		return nil, fmt.Errorf("WithBatchMode requires WithProvider(%q): provider %q does not serve the Message Batches API", ProviderAnthropic, e.provider)
+	}
+
 	// The recorder is built after options so it captures the final model,
 	// provider, and resource labels.
 	e.telemetry = telemetry.NewRecorder(genaiMetrics, e.modelName, e.provider.metricName(), e.resourceLabels, responseCodeFromError)
@@ -356,7 +393,7 @@ func (e *executor[Request, Response]) Execute(
 	// A fresh Execute runs the full turn budget starting at turn 0. Resume
 	// (resume.go) shares the same loop with a restored params, a startTurn past
 	// the suspension point, and the envelope's remaining budget.
-	return e.runConversation(ctx, trace, params, tools, tail, 0, e.maxTurns, seedToolCalls)
+	return e.runConversation(ctx, trace, params, tools, tail, 0, e.maxTurns, seedToolCalls, nil, nil)
 }
 
 // runConversation drives the bounded turn loop shared by Execute (a fresh run,
@@ -369,6 +406,10 @@ func (e *executor[Request, Response]) Execute(
 // run before aborting — for a resume it is the envelope's RemainingTurns, so
 // the overall turn budget is enforced across pauses. trace is already started
 // by the caller (its done callback reads the caller's named err on return).
+// loopState is the envelope's LoopState for a resumed run (nil for a fresh
+// one); the budget spend it carries seeds the run's tracker. prefetched, when
+// non-nil, is a Message Batch result collected by PollBatch:
+// the first turn consumes it in place of a Claude API call.
 func (e *executor[Request, Response]) runConversation(
 	ctx context.Context,
 	trace *agenttrace.Trace[Response],
@@ -378,7 +419,13 @@ func (e *executor[Request, Response]) runConversation(
 	startTurn int,
 	turnBudget int,
 	seedToolCalls []anthropic.ToolUseBlock,
+	loopState json.RawMessage,
+	prefetched *batchResult,
 ) (response Response, err error) {
+	// Observers learn the call is over however it returns, so one that
+	// publishes asynchronously can flush before the caller moves on.
+	defer e.observer.Emit(ctx, progress.Event{Kind: progress.RunCompleted})
+
 	// finalResult stores the result if a tool sets it
 	var finalResult Response
 	finalResultPtr := &finalResult
@@ -436,6 +483,13 @@ func (e *executor[Request, Response]) runConversation(
 	}
 	availableTools := availableToolNames(tools, heldOutNames...)
 
+	// spend tracks the run's usage against the optional budget; nil when
+	// WithBudget was not applied, which makes every call on it a no-op.
+	spend, err := execshared.NewTracker(e.budget, e.modelName, loopState)
+	if err != nil {
+		return response, err
+	}
+
 	// executeToolCall handles executing a single tool call and returning the
 	// result. The handler writes any terminal result into resultPtr; the
 	// sequential path passes the shared finalResultPtr, while the concurrent
@@ -443,7 +497,7 @@ func (e *executor[Request, Response]) runConversation(
 	// The committed return reports that the terminal submit tool accepted the
 	// call and the registered result validators passed, so resultPtr holds the
 	// run's final result — even when that result is the zero value.
-	executeToolCall := func(toolUse anthropic.ToolUseBlock, resultPtr *Response) (anthropic.ContentBlockParamUnion, bool, error) {
+	executeToolCall := func(turn int, toolUse anthropic.ToolUseBlock, resultPtr *Response) (anthropic.ContentBlockParamUnion, bool, error) {
 		kvs := []any{"tool", toolUse.Name, "id", toolUse.ID}
 		var args map[string]any
 		if err := json.Unmarshal(normalizeToolUseInput(toolUse.Input), &args); err == nil {
@@ -452,6 +506,7 @@ func (e *executor[Request, Response]) runConversation(
 			}
 		}
 		clog.InfoContext(ctx, "Executing tool call", kvs...)
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallStarted, Turn: turn, ToolCallID: toolUse.ID, ToolName: toolUse.Name})
 
 		var result map[string]any
 		committed := false
@@ -475,7 +530,7 @@ func (e *executor[Request, Response]) runConversation(
 			// validators' findings as the tool result so the model can address
 			// them and submit again — the loop continues.
 			var err error
-			result, committed, err = e.evaluateSubmission(ctx, toolUse, args, trace, resultPtr)
+			result, committed, err = e.evaluateSubmission(ctx, turn, toolUse, args, trace, resultPtr)
 			if err != nil {
 				return anthropic.ContentBlockParamUnion{}, false, err
 			}
@@ -501,6 +556,8 @@ func (e *executor[Request, Response]) runConversation(
 			}
 		}
 
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallCompleted, Turn: turn, ToolCallID: toolUse.ID, ToolName: toolUse.Name, Error: progress.ToolError(result)})
+
 		// Marshal result
 		resultBytes, err := json.Marshal(result)
 		if err != nil {
@@ -534,7 +591,7 @@ func (e *executor[Request, Response]) runConversation(
 		})
 
 		// Execute the tool call.
-		result, committed, err := executeToolCall(toolCall, finalResultPtr)
+		result, committed, err := executeToolCall(startTurn, toolCall, finalResultPtr)
 		if err != nil {
 			return response, err
 		}
@@ -565,11 +622,41 @@ func (e *executor[Request, Response]) runConversation(
 		defer func() {
 			// A suspension is an intentional halt, not a failure: the shared
 			// carve-out keeps the turn from being marked Failed (the trace-level
-			// disposition is set by trace.Suspend on the suspend path). Every
-			// other error still fails the turn.
-			execshared.FailTurnUnlessSuspended(llmTurn, err)
+			// disposition is set by trace.Suspend on the suspend path). A turn
+			// parked on a Message Batch is the same kind of halt. Every other
+			// error still fails the turn.
+			if _, pending := AsBatchPending(err); !pending {
+				execshared.FailTurnUnlessSuspended(llmTurn, err)
+			}
 			llmTurn.End()
+			e.observer.Emit(ctx, progress.Event{Kind: progress.TurnCompleted, Turn: turn})
 		}()
+		e.observer.Emit(ctx, progress.Event{Kind: progress.TurnStarted, Turn: turn})
+
+		// Enforce the spend budget before paying for another request, and
+		// ask the model once to wrap up as the budget nears. The nudge is its
+		// own user message, as with the early-finalize nudge; no tool_choice
+		// is forced, so it is safe alongside extended thinking.
+		if err := spend.Err(); err != nil {
+			clog.ErrorContext(ctx, "Agent exceeded its budget", "error", err)
+			return response, true, err
+		}
+		if spend.Nudge() {
+			e.telemetry.RecordToolCall(ctx, "budget_nudge")
+			clog.InfoContext(ctx, "Budget nearly spent, nudging model to emit result",
+				"tokens", spend.Usage().Total(), "cost_usd", spend.CostUSD())
+			params.Messages = append(params.Messages, anthropic.MessageParam{
+				Role:    anthropic.MessageParamRoleUser,
+				Content: []anthropic.ContentBlockParamUnion{anthropic.NewTextBlock(budget.NudgeMessage(submitToolName))},
+			})
+		}
+
+		// Elide older tool results first when the conversation has outgrown
+		// its compaction budget. Elision rewrites block content in place, so
+		// the tail positions tracked below stay valid.
+		if err := e.compact(ctx, trace, turn, &params); err != nil {
+			return response, true, err
+		}
 
 		// Move the tail cache breakpoint onto the newest message so everything
 		// appended since the last turn (tool results, redirects) is written to
@@ -597,41 +684,73 @@ func (e *executor[Request, Response]) runConversation(
 			clog.WarnContext(ctx, "failed to record llm prompt payload", "error", err)
 		}
 
-		// Stream response with retry for transient errors
-		message, err := retry.RetryWithBackoff(ctx, turnCfg, "stream_message", isRetryableClaudeError, func() (anthropic.Message, error) {
-			stream := e.client.Messages.NewStreaming(ctx, params)
-			var msg anthropic.Message
-			for stream.Next() {
-				event := stream.Current()
-				if err := msg.Accumulate(event); err != nil {
-					// The SDK re-marshals accumulated content on content_block_stop
-					// and message_stop to refresh its JSON cache. A tool_use block
-					// whose Input is a non-nil zero-length json.RawMessage (model
-					// emitted a tool call with no input_json_delta events) causes
-					// json.Marshal to fail with "unexpected end of JSON input".
-					// The structural accumulation is intact -- only the JSON cache
-					// refresh failed -- so normalize the empty tool inputs to "{}"
-					// and continue.
-					if isEmptyRawMessageMarshalErr(err) && normalizeEmptyToolInputs(&msg) {
-						continue
+		// Obtain the turn's response: a Message Batch result collected by
+		// PollBatch, a new batch submission that parks the run (batch mode), or
+		// a streaming call with retry for transient errors. Only the streaming
+		// call reports text and thinking to the observer as it arrives; batch
+		// results are reported whole once the message is in hand.
+		var (
+			message  anthropic.Message
+			streamed bool
+		)
+		switch {
+		case prefetched != nil && turn == startTurn:
+			message = prefetched.message
+			llmTurn.RecordBatch(prefetched.batchID, prefetched.wait)
+		case e.batchPollInterval > 0:
+			bp, err := e.submitBatch(ctx, llmTurn, turnCfg, params, tools, turn, turnBudget-(turn-startTurn), spend, trace.ID)
+			if err != nil {
+				return response, true, err
+			}
+			// Finalize the trace as suspended, as on the ask-a-friend path: the
+			// run is waiting, not failed, and the deferred done(...) no-ops.
+			trace.Suspend(bp.Reason)
+			return response, true, bp
+		default:
+			streamed = true
+			message, err = retry.RetryWithBackoff(ctx, turnCfg, "stream_message", isRetryableClaudeError, func() (anthropic.Message, error) {
+				stream := e.client.Messages.NewStreaming(ctx, params)
+				var msg anthropic.Message
+				for stream.Next() {
+					event := stream.Current()
+					if event.Type == "content_block_delta" {
+						switch event.Delta.Type {
+						case "text_delta":
+							e.observer.Emit(ctx, progress.Event{Kind: progress.TextDelta, Turn: turn, Text: event.Delta.Text})
+						case "thinking_delta":
+							e.observer.Emit(ctx, progress.Event{Kind: progress.ThinkingDelta, Turn: turn, Text: event.Delta.Thinking})
+						}
+					}
+					if err := msg.Accumulate(event); err != nil {
+						// The SDK re-marshals accumulated content on content_block_stop
+						// and message_stop to refresh its JSON cache. A tool_use block
+						// whose Input is a non-nil zero-length json.RawMessage (model
+						// emitted a tool call with no input_json_delta events) causes
+						// json.Marshal to fail with "unexpected end of JSON input".
+						// The structural accumulation is intact -- only the JSON cache
+						// refresh failed -- so normalize the empty tool inputs to "{}"
+						// and continue.
+						if isEmptyRawMessageMarshalErr(err) && normalizeEmptyToolInputs(&msg) {
+							continue
+						}
+						return msg, fmt.Errorf("failed to accumulate event: %w", err)
 					}
-					return msg, fmt.Errorf("failed to accumulate event: %w", err)
 				}
+				if err := stream.Err(); err != nil {
+					return msg, err
+				}
+				return msg, nil
+			})
+			e.telemetry.RecordAPIRequest(ctx, err)
+			if err != nil {
+				// If the error is a retryable Claude API error (429, 503, 504, 529) that
+				// exhausted inner retries, signal the workqueue to back off instead of
+				// immediately retrying — avoids contributing to API overload.
+				if requeueErr := retry.RequeueIfRetryable(ctx, err, isRetryableClaudeError, "Claude API"); requeueErr != nil {
+					return response, true, requeueErr
+				}
+				return response, true, fmt.Errorf("failed to stream Claude response: %w", err)
 			}
-			if err := stream.Err(); err != nil {
-				return msg, err
-			}
-			return msg, nil
-		})
-		e.telemetry.RecordAPIRequest(ctx, err)
-		if err != nil {
-			// If the error is a retryable Claude API error (429, 503, 504, 529) that
-			// exhausted inner retries, signal the workqueue to back off instead of
-			// immediately retrying — avoids contributing to API overload.
-			if requeueErr := retry.RequeueIfRetryable(ctx, err, isRetryableClaudeError, "Claude API"); requeueErr != nil {
-				return response, true, requeueErr
-			}
-			return response, true, fmt.Errorf("failed to stream Claude response: %w", err)
 		}
 
 		// Record token usage in metrics and on the per-turn span. Trace-level
@@ -641,6 +760,12 @@ func (e *executor[Request, Response]) runConversation(
 			e.telemetry.RecordTokens(ctx, message.Usage.InputTokens, message.Usage.OutputTokens)
 			llmTurn.RecordTokens(message.Usage.InputTokens, message.Usage.OutputTokens)
 		}
+		spend.Add(budget.Usage{
+			InputTokens:      message.Usage.InputTokens,
+			OutputTokens:     message.Usage.OutputTokens,
+			CacheReadTokens:  message.Usage.CacheReadInputTokens,
+			CacheWriteTokens: message.Usage.CacheCreationInputTokens,
+		})
 
 		// Capture the assistant content blocks (text + tool_use + thinking)
 		// as the completion for this turn. Pairs with RecordRequest above to
@@ -689,6 +814,9 @@ func (e *executor[Request, Response]) runConversation(
 			switch content.Type {
 			case "text":
 				textContent = content.Text
+				if !streamed {
+					e.observer.Emit(ctx, progress.Event{Kind: progress.TextDelta, Turn: turn, Text: content.Text})
+				}
 			case "tool_use":
 				toolUseBlocks = append(toolUseBlocks, anthropic.ToolUseBlock{
 					ID:    content.ID,
@@ -702,6 +830,9 @@ func (e *executor[Request, Response]) runConversation(
 				trace.AppendReasoning(agenttrace.ReasoningContent{
 					Thinking: content.Thinking,
 				})
+				if !streamed && content.Thinking != "" {
+					e.observer.Emit(ctx, progress.Event{Kind: progress.ThinkingDelta, Turn: turn, Text: content.Thinking})
+				}
 			}
 		}
 
@@ -751,7 +882,7 @@ func (e *executor[Request, Response]) runConversation(
 			execshared.DispatchToolCalls(toolUseBlocks, e.toolCallConcurrency,
 				func(toolUse anthropic.ToolUseBlock) bool { return heldOut(toolUse.Name) },
 				func(i int, toolUse anthropic.ToolUseBlock) {
-					res, committed, cerr := executeToolCall(toolUse, &perCallResults[i])
+					res, committed, cerr := executeToolCall(turn, toolUse, &perCallResults[i])
 					outcomes[i] = toolOutcome{result: res, committed: committed, err: cerr}
 				})
 
@@ -800,17 +931,14 @@ func (e *executor[Request, Response]) runConversation(
 			// real tool_result is in the transcript above, so the envelope's
 			// PendingToolCalls shrinks to just the suspend call.
 			if suspendIdx >= 0 {
-				susp, berr := e.buildSuspension(params, tools, turn, toolUseBlocks[suspendIdx], trace.ID)
+				susp, berr := e.buildSuspension(params, tools, turn, toolUseBlocks[suspendIdx], spend, trace.ID)
 				if berr != nil {
 					return response, true, berr
 				}
-				clog.InfoContext(ctx, "Suspending Claude agent execution to await human answer",
-					"turn", turn, "tool", toolUseBlocks[suspendIdx].Name, "tool_use_id", toolUseBlocks[suspendIdx].ID)
 				// Finalize the trace as suspended (status OK, not Error) before
 				// returning the Suspension error; the deferred done(...) then
 				// no-ops via the finalized guard.
-				trace.Suspend(susp.Reason)
-				e.telemetry.RecordTurns(ctx, turn+1, false)
+				execshared.ParkSuspension(ctx, trace, e.telemetry, susp)
 				return response, true, susp
 			}
 
@@ -1137,9 +1265,11 @@ func (e *executor[Request, Response]) submitToolName() string {
 // and gates its accepted response on the registered result validators via
 // execshared.GateSubmission (see there for the gate semantics). It returns
 // the tool result to send back to the model and whether the response
-// committed as the run's final result (written through resultPtr).
+// committed as the run's final result (written through resultPtr). A
+// validator rejection is reported to the observer against turn.
 func (e *executor[Request, Response]) evaluateSubmission(
 	ctx context.Context,
+	turn int,
 	toolUse anthropic.ToolUseBlock,
 	args map[string]any,
 	trace *agenttrace.Trace[Response],
@@ -1147,7 +1277,10 @@ func (e *executor[Request, Response]) evaluateSubmission(
 ) (map[string]any, bool, error) {
 	return execshared.GateSubmission(ctx, e.submitTool.Handler(ctx, toolUse, trace),
 		trace, toolUse.ID, toolUse.Name, args,
-		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr)
+		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr,
+		func(findings []callbacks.Finding) {
+			e.observer.Emit(ctx, progress.Event{Kind: progress.SubmitRejected, Turn: turn, ToolCallID: toolUse.ID, ToolName: toolUse.Name, Findings: findings})
+		})
 }
 
 // shouldForceSubmitOnFirstTurn reports whether the forced submit tool_choice
@@ -0,0 +1,133 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/claudeexecutor"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// TestObserverStreamsDeltas checks that the observer receives each streamed
+// thinking and text delta as its own event, in stream order, around the
+// turn's tool call.
+func TestObserverStreamsDeltas(t *testing.T) {
+	srv := newValidatingAnthropicServer(t, func(reqNum int, _ []byte) []string {
+		if reqNum == 1 {
+			return []string{
+				`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":"","signature":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"The answer "}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"needs a look."}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+				`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Let me "}}`,
+				`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"look."}}`,
+				`{"type":"content_block_stop","index":1}`,
+				`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_1","name":"look","input":{}}}`,
+				`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"reasoning\":\"look\"}"}}`,
+				`{"type":"content_block_stop","index":2}`,
+				`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":10}}`,
+				`{"type":"message_stop"}`,
+			}
+		}
+		return []string{
+			`{"type":"message_start","message":{"id":"msg_2","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"{\"answer\":\"done\"}"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+			`{"type":"message_stop"}`,
+		}
+	})
+
+	client := anthropic.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+
+	var (
+		mu     sync.Mutex
+		events []progress.Event
+	)
+	exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		claudeexecutor.WithObserver[errCapRequest, errCapResponse](func(_ context.Context, ev progress.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, ev)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"look": {
+			Definition: anthropic.ToolParam{
+				Name:        "look",
+				Description: anthropic.String("Look around."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": true}
+			},
+		},
+	}
+	got, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got.Answer != "done" {
+		t.Errorf("Answer: got = %q, want = done", got.Answer)
+	}
+
+	var steps []string
+	for _, ev := range events {
+		steps = append(steps, strings.TrimSpace(string(ev.Kind)+" "+ev.Text))
+	}
+	want := []string{
+		"turn_started",
+		"thinking_delta The answer",
+		"thinking_delta needs a look.",
+		"text_delta Let me",
+		"text_delta look.",
+		"tool_call_started",
+		"tool_call_completed",
+		"turn_completed",
+		"turn_started",
+		`text_delta {"answer":"done"}`,
+		"turn_completed",
+		"run_completed",
+	}
+	if !slices.Equal(steps, want) {
+		t.Errorf("events:\ngot  = %q\nwant = %q", steps, want)
+	}
+	if ev := events[6]; ev.Turn != 0 || ev.ToolName != "look" || ev.ToolCallID != "toolu_1" || ev.Error != "" {
+		t.Errorf("tool completion: got = %+v, want look/toolu_1 on turn 0 without error", ev)
+	}
+}
@@ -12,7 +12,10 @@ import (
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
@@ -23,19 +26,16 @@ import (
 // Option is a functional option for configuring the executor
 type Option[Request promptbuilder.Bindable, Response any] func(*executor[Request, Response]) error
 
-// WithMaxTokens sets the maximum tokens for responses
+// WithMaxTokens sets the maximum tokens for responses. New rejects a value
+// above the model's output-token ceiling in the model registry (128000 for
+// current Claude models). The executor streams every response
+// (Messages.NewStreaming), so values well above the old 32000 cap do not risk
+// the SDK's non-streaming HTTP timeout.
 func WithMaxTokens[Request promptbuilder.Bindable, Response any](tokens int64) Option[Request, Response] {
 	return func(e *executor[Request, Response]) error {
 		if tokens <= 0 {
 			return fmt.Errorf("max tokens must be positive, got %d", tokens)
 		}
-		// 128000 is the max output-token ceiling for current Claude models (Sonnet 5,
-		// Opus 4.6/4.7/4.8, Fable 5) on both the Vertex and Anthropic-direct backends. The
-		// executor streams every response (Messages.NewStreaming), so values well above the
-		// old 32000 cap do not risk the SDK's non-streaming HTTP timeout.
-		if tokens > 128000 {
-			return fmt.Errorf("max tokens %d exceeds maximum of 128000", tokens)
-		}
 		e.maxTokens = tokens
 		return nil
 	}
@@ -93,6 +93,53 @@ func WithMaxTurns[Request promptbuilder.Bindable, Response any](turns int) Optio
 	}
 }
 
+// WithCompaction enables context compaction: before each request whose
+// estimated conversation size exceeds cfg.Threshold tokens, the oldest tool
+// results are replaced with a short placeholder. The tool_result blocks stay
+// in place, so tool_use pairing and the prompt-cache breakpoints are
+// preserved; each compaction step is recorded on the trace. See the
+// compaction package for the policy. Off by default.
+func WithCompaction[Request promptbuilder.Bindable, Response any](cfg compaction.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.compaction = &cfg
+		return nil
+	}
+}
+
+// WithBudget caps each run's spend: its total tokens, its estimated cost, or
+// both (see budget.Config). Cache reads and writes count toward the token cap
+// and are priced at the table's cache rates. As the budget nears, the model
+// is told once to submit its result; once it is exceeded, the run is aborted
+// before its next request with an error that budget.AsExceeded extracts. The
+// spend is saved in the checkpoint envelope, so a run resumed after a
+// suspension, or continued by PollBatch in batch mode, counts against the same
+// budget. Batch turns are priced at the table's full rates, not the batch
+// discount. Off by default.
+func WithBudget[Request promptbuilder.Bindable, Response any](cfg budget.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.budget = &cfg
+		return nil
+	}
+}
+
+// WithObserver registers an observer for the run's progress events: turn
+// starts, streamed thinking and text deltas, tool calls starting and
+// completing, and submissions the result validators reject. In batch mode
+// each turn's text arrives whole once its batch completes. See the progress
+// package.
+func WithObserver[Request promptbuilder.Bindable, Response any](obs progress.Observer) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		e.observer = obs
+		return nil
+	}
+}
+
 // WithToolCallConcurrency bounds how many of a single turn's tool calls run
 // concurrently when the model emits more than one in a turn (parallel tool
 // use). Defaults to DefaultToolCallConcurrency.
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package claudeexecutor_test
+
+import (
+	"context"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/evals/replay"
+	"chainguard.dev/driftlessaf/agents/executor/claudeexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// TestReplayToolLoop records a two-turn tool loop against the validating
+// server, then reruns it from the fixtures with no server at all: the
+// executor's requests must be deterministic enough to match their recordings.
+func TestReplayToolLoop(t *testing.T) {
+	toolCallTurn := []string{
+		`{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_lookup","name":"lookup","input":{}}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"reasoning\":\"check\"}"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":1}}`,
+		`{"type":"message_stop"}`,
+	}
+	finalTurn := []string{
+		`{"type":"message_start","message":{"id":"msg_02","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-6","stop_reason":null,"usage":{"input_tokens":20,"output_tokens":5}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"{\"answer\":\"42\"}"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`,
+		`{"type":"message_stop"}`,
+	}
+	srv := newValidatingAnthropicServer(t, func(reqNum int, _ []byte) []string {
+		if reqNum == 1 {
+			return toolCallTurn
+		}
+		return finalTurn
+	})
+
+	tools := map[string]claudetool.Metadata[errCapResponse]{
+		"lookup": {
+			Definition: anthropic.ToolParam{
+				Name:        "lookup",
+				Description: anthropic.String("Look something up."),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Type:       "object",
+					Properties: map[string]any{"reasoning": map[string]any{"type": "string"}},
+				},
+			},
+			Handler: func(context.Context, anthropic.ToolUseBlock, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"result": "ok"}
+			},
+		},
+	}
+	run := func(tr *replay.Transport, baseURL string) {
+		t.Helper()
+		client := anthropic.NewClient(
+			option.WithBaseURL(baseURL),
+			option.WithAPIKey("test"),
+			option.WithMaxRetries(0),
+			option.WithHTTPClient(tr.Client()),
+		)
+		prompt, err := promptbuilder.NewPrompt("hello")
+		if err != nil {
+			t.Fatalf("NewPrompt: %v", err)
+		}
+		exec, err := claudeexecutor.New[errCapRequest, errCapResponse](
+			client,
+			prompt,
+			claudeexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+			claudeexecutor.WithMaxTurns[errCapRequest, errCapResponse](3),
+		)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		resp, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+		if err != nil {
+			t.Fatalf("Execute (%v): %v", tr.Mode(), err)
+		}
+		if got, want := resp.Answer, "42"; got != want {
+			t.Errorf("resp.Answer (%v): got = %q, want = %q", tr.Mode(), got, want)
+		}
+	}
+
+	dir := t.TempDir()
+	rec, err := replay.New(dir, replay.ModeRecord)
+	if err != nil {
+		t.Fatalf("replay.New(record): %v", err)
+	}
+	run(rec, srv.URL)
+
+	srv.Close()
+	rep, err := replay.New(dir, replay.ModeReplay)
+	if err != nil {
+		t.Fatalf("replay.New(replay): %v", err)
+	}
+	run(rep, "http://replay.invalid")
+	if got := rep.Unmatched(); len(got) != 0 {
+		t.Errorf("Unmatched: %v", got)
+	}
+}
@@ -10,16 +10,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
 
-	"chainguard.dev/driftlessaf/agents/agenttrace"
 	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
 	"github.com/anthropics/anthropic-sdk-go"
-	"github.com/chainguard-dev/clog"
-	"go.opentelemetry.io/otel/attribute"
-	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 // Resumer is the capability interface for resuming a conversation that
@@ -69,21 +65,16 @@ func (e *executor[Request, Response]) Resume(
 	// Fail-closed configuration checks. A resume must reconstruct the request
 	// against the same backend, model, and turn-invariant prefix that produced
 	// the envelope; any drift means the parked state is stale and the run must be
-	// rebuilt from scratch rather than replayed. ValidateForResume surfaces the
+	// rebuilt from scratch rather than replayed. ValidateResume surfaces the
 	// mismatches as checkpoint.ErrConfigDrift so a waker can branch on errors.Is,
-	// and also enforces the remaining-turn budget and any envelope deadline before
-	// any state is touched. ValidateForResume takes now as a parameter (rather
-	// than reading the clock itself) so the checkpoint package's own deadline
-	// tests can inject one; this call site supplies wall-clock time.
+	// enforces the remaining-turn budget and any envelope deadline before any
+	// state is touched, and caps the budget at the live maxTurns.
 	staticParams, _, _, serr := e.buildStaticParams(tools)
 	if serr != nil {
 		return response, fmt.Errorf("build static params for digest: %w", serr)
 	}
-	digest, derr := checkpoint.DigestJSON(staticParams)
-	if derr != nil {
-		return response, derr
-	}
-	if verr := checkpoint.ValidateForResume(env, suspendProviderName, e.modelName, digest, time.Now()); verr != nil {
+	turnBudget, verr := execshared.ValidateResume(env, suspendProviderName, e.modelName, staticParams, e.maxTurns)
+	if verr != nil {
 		return response, verr
 	}
 
@@ -111,32 +102,19 @@ func (e *executor[Request, Response]) Resume(
 
 	// Start a NEW trace for the resumed run, linked back to the originating trace
 	// so the two halves of a paused conversation join downstream.
-	trace, done := agenttrace.StartTrace[Response](ctx, resumeTracePrompt(env))
+	trace, done := execshared.StartResumeTrace[Response](ctx, env)
 	defer func() {
 		done(response, err)
 	}()
-	if env.TraceID != "" {
-		if span := oteltrace.SpanFromContext(trace.Context()); span.SpanContext().IsValid() {
-			span.SetAttributes(attribute.String(agenttrace.AttrResumeLinkedTraceID, env.TraceID))
-		}
-	}
-
-	clog.InfoContext(ctx, "Resuming suspended Claude agent execution",
-		"key", env.ReconcilerKey, "run", env.RunID, "turn", env.Turn,
-		"remaining_turns", env.RemainingTurns, "linked_trace", env.TraceID)
 
 	// A fresh tail tracker: its budget is derived from the (surviving) static
 	// prefix markers, and it reseeds markers on the growing tail as the resumed
 	// conversation continues.
 	tail := newTailBreakpoints(params)
 
-	// Cap the envelope's remaining budget at the live executor's configured
-	// maxTurns. The turn budget is loop config, not part of the request digest,
-	// so ValidateForResume cannot catch a mismatch: without the cap, an envelope
-	// parked under a larger budget (an operator lowering maxTurns between park
-	// and wake, or a tampered checkpoint) would resume with more turns than the
-	// live configuration allows.
-	response, err = e.runConversation(ctx, trace, params, tools, tail, env.Turn+1, min(env.RemainingTurns, e.maxTurns), nil)
+	// turnBudget is the envelope's remaining budget capped at the live
+	// executor's maxTurns (see execshared.ValidateResume).
+	response, err = e.runConversation(ctx, trace, params, tools, tail, env.Turn+1, turnBudget, nil, env.LoopState, nil)
 	return response, err
 }
 
@@ -188,10 +166,3 @@ func appendPendingAnswers(params *anthropic.MessageNewParams, pending []checkpoi
 	}
 	return nil
 }
-
-// resumeTracePrompt is the prompt string recorded on a resumed run's trace. The
-// restored transcript is the real context; this string only labels the resume
-// so the trace is self-describing.
-func resumeTracePrompt(env checkpoint.Envelope) string {
-	return fmt.Sprintf("resume suspended agent (key=%q, run=%q, reason=%q)", env.ReconcilerKey, env.RunID, env.Reason)
-}
@@ -6,11 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 package claudeexecutor
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/toolcall/claudetool"
 	"github.com/anthropics/anthropic-sdk-go"
@@ -74,19 +75,16 @@ func (e *executor[Request, Response]) suspendToolName() string {
 // resume can rebuild the exact Anthropic request. suspendCall is the tool_use
 // block that triggered the pause; its provider-assigned ID is persisted so
 // resume pairs the human answer back to this exact call rather than re-deriving
-// it from the tool name.
+// it from the tool name. spend is the run's budget tracker, saved so the
+// resumed run continues against the same budget.
 func (e *executor[Request, Response]) buildSuspension(
 	params anthropic.MessageNewParams,
 	tools map[string]claudetool.Metadata[Response],
 	turn int,
 	suspendCall anthropic.ToolUseBlock,
+	spend *budget.Tracker,
 	traceID string,
 ) (*checkpoint.Suspension, error) {
-	providerState, err := json.Marshal(params)
-	if err != nil {
-		return nil, fmt.Errorf("marshal provider state: %w", err)
-	}
-
 	// ConfigDigest is taken over the turn-invariant request prefix (tools,
 	// system, sampling) — exactly the configuration a resume must match to
 	// replay safely. buildStaticParams leaves Messages unset, so the digest is
@@ -95,19 +93,10 @@ func (e *executor[Request, Response]) buildSuspension(
 	if err != nil {
 		return nil, fmt.Errorf("build static params for digest: %w", err)
 	}
-	digest, err := checkpoint.DigestJSON(staticParams)
-	if err != nil {
-		return nil, err
-	}
-
-	// NewAskAFriendSuspension stamps the schema version, the shared reason, and
-	// the clamped remaining-turn budget; this executor supplies only the
-	// provider-typed pieces. LoopState is nil: the parked turn index lives in
-	// Envelope.Turn and Resume re-enters the loop at Turn+1.
-	return checkpoint.NewAskAFriendSuspension(suspendProviderName, e.modelName, digest, turn, e.maxTurns,
+	return execshared.BuildSuspension(suspendProviderName, e.modelName, staticParams, params, turn, e.maxTurns,
 		checkpoint.PendingToolCall{
 			ID:        suspendCall.ID,
 			Name:      suspendCall.Name,
 			InputJSON: normalizeToolUseInput(suspendCall.Input),
-		}, providerState, nil, traceID), nil
+		}, spend, traceID)
 }
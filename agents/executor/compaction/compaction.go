@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compaction
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultKeepRecent is the number of most recent tool results kept verbatim
+// when Config.KeepRecent is zero.
+const DefaultKeepRecent = 4
+
+// bytesPerToken is the rough serialized-bytes-to-token ratio used by
+// EstimateTokens. Tool results are mostly JSON, source and logs, for which
+// four bytes per token is a conservative average across the providers.
+const bytesPerToken = 4
+
+// placeholderPrefix opens every placeholder so an already-elided result is
+// recognised (IsPlaceholder) and never elided twice.
+const placeholderPrefix = "[elided tool result:"
+
+// Config configures opt-in context compaction for an executor's conversation
+// loop.
+type Config struct {
+	// Threshold is the estimated conversation size, in tokens, above which
+	// older tool results are elided before the next request. Compaction then
+	// elides down to three quarters of Threshold, so it fires in occasional
+	// steps rather than on every turn — each step invalidates the prompt
+	// cache from the first elided result onward. Required.
+	Threshold int
+
+	// KeepRecent is the number of most recent tool results the model has
+	// already seen that are always kept verbatim, on top of the results it
+	// has not seen yet (which are never elided). Zero means
+	// DefaultKeepRecent.
+	KeepRecent int
+}
+
+// Validate reports whether the config is usable.
+func (c Config) Validate() error {
+	if c.Threshold <= 0 {
+		return errors.New("compaction threshold must be positive")
+	}
+	if c.KeepRecent < 0 {
+		return errors.New("compaction keep-recent count cannot be negative")
+	}
+	return nil
+}
+
+// Plan is the outcome of Config.Plan.
+type Plan struct {
+	// Elide holds the indexes, into the sizes passed to Config.Plan, of the
+	// tool results to replace with a Placeholder, oldest first. Empty when
+	// no compaction is needed or nothing is eligible.
+	Elide []int
+	// EstimatedTokensBefore and EstimatedTokensAfter are the conversation
+	// size estimates on either side of the elision.
+	EstimatedTokensBefore int
+	EstimatedTokensAfter  int
+	// ElidedBytes is the total size of the elided tool results.
+	ElidedBytes int
+}
+
+// Plan decides which tool results to elide from a conversation estimated at
+// tokens. sizes holds the byte size of each tool result the model has
+// already seen, oldest first; callers leave out results already replaced by a
+// placeholder and the results the next request delivers for the first time.
+// Nothing is elided while tokens is at or under the threshold; above it,
+// results are elided oldest first, sparing the KeepRecent newest, until the
+// estimate drops to three quarters of the threshold or no candidate is left.
+func (c Config) Plan(tokens int, sizes []int) Plan {
+	p := Plan{EstimatedTokensBefore: tokens, EstimatedTokensAfter: tokens}
+	if tokens <= c.Threshold {
+		return p
+	}
+	keep := c.KeepRecent
+	if keep == 0 {
+		keep = DefaultKeepRecent
+	}
+	target := c.Threshold * 3 / 4
+	for i := 0; i < len(sizes)-keep && p.EstimatedTokensAfter > target; i++ {
+		saved := EstimateTokens(sizes[i]) - EstimateTokens(len(Placeholder(sizes[i])))
+		if saved <= 0 {
+			continue
+		}
+		p.Elide = append(p.Elide, i)
+		p.ElidedBytes += sizes[i]
+		p.EstimatedTokensAfter -= saved
+	}
+	return p
+}
+
+// EstimateTokens returns a rough token count for size bytes of serialized
+// conversation. It is deliberately provider-agnostic: compaction needs a
+// stable trigger, not an exact count.
+func EstimateTokens(size int) int {
+	return (size + bytesPerToken - 1) / bytesPerToken
+}
+
+// Placeholder returns the text that replaces an elided tool result of size
+// bytes. It tells the model the output existed and how to get it back, so a
+// model that still needs it re-runs the tool rather than guessing.
+func Placeholder(size int) string {
+	return fmt.Sprintf("%s %d bytes of output removed to keep the conversation within its context budget; call the tool again if you still need it]", placeholderPrefix, size)
+}
+
+// IsPlaceholder reports whether s is a Placeholder.
+func IsPlaceholder(s string) bool {
+	return strings.HasPrefix(s, placeholderPrefix)
+}
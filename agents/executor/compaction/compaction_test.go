@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compaction
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlan(t *testing.T) {
+	big := 40_000 // 10k tokens
+	tests := []struct {
+		name       string
+		config     Config
+		tokens     int
+		sizes      []int
+		wantElide  []int
+		wantTokens int
+	}{{
+		name:       "under threshold",
+		config:     Config{Threshold: 100_000},
+		tokens:     90_000,
+		sizes:      []int{big, big, big, big, big, big},
+		wantTokens: 90_000,
+	}, {
+		name:   "elides oldest down to three quarters",
+		config: Config{Threshold: 100_000},
+		tokens: 110_000,
+		sizes:  []int{big, big, big, big, big, big, big, big},
+		// 110k → 75k needs four results; only the first four are eligible
+		// beyond the default KeepRecent of four.
+		wantElide:  []int{0, 1, 2, 3},
+		wantTokens: 110_000 - 4*(10_000-EstimateTokens(len(Placeholder(big)))),
+	}, {
+		name:       "keep recent spares the newest",
+		config:     Config{Threshold: 100_000, KeepRecent: 5},
+		tokens:     110_000,
+		sizes:      []int{big, big, big, big, big, big},
+		wantElide:  []int{0},
+		wantTokens: 110_000 - (10_000 - EstimateTokens(len(Placeholder(big)))),
+	}, {
+		name:       "results smaller than a placeholder are skipped",
+		config:     Config{Threshold: 100, KeepRecent: 1},
+		tokens:     200,
+		sizes:      []int{10, big, 10},
+		wantElide:  []int{1},
+		wantTokens: 200 - (10_000 - EstimateTokens(len(Placeholder(big)))),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.config.Plan(tt.tokens, tt.sizes)
+			if diff := cmp.Diff(tt.wantElide, p.Elide); diff != "" {
+				t.Errorf("Elide (-want +got):\n%s", diff)
+			}
+			if p.EstimatedTokensBefore != tt.tokens {
+				t.Errorf("EstimatedTokensBefore: got = %d, want = %d", p.EstimatedTokensBefore, tt.tokens)
+			}
+			if p.EstimatedTokensAfter != tt.wantTokens {
+				t.Errorf("EstimatedTokensAfter: got = %d, want = %d", p.EstimatedTokensAfter, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestPlaceholder(t *testing.T) {
+	if p := Placeholder(1234); !IsPlaceholder(p) {
+		t.Errorf("IsPlaceholder(%q) = false, want true", p)
+	}
+	if IsPlaceholder(`{"result":"ok"}`) {
+		t.Error(`IsPlaceholder("{...}") = true, want false`)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config  Config
+		wantErr bool
+	}{
+		"valid":              {config: Config{Threshold: 1000}},
+		"zero threshold":     {config: Config{}, wantErr: true},
+		"negative keep":      {config: Config{Threshold: 1000, KeepRecent: -1}, wantErr: true},
+		"explicit keep zero": {config: Config{Threshold: 1000, KeepRecent: 0}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.config.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
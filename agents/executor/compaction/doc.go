@@ -0,0 +1,47 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package compaction provides the provider-agnostic policy behind the
+executors' opt-in context-window compaction.
+
+# Overview
+
+Tool results accumulate verbatim in an agent's conversation, so an agent that
+reads many large files can exhaust the model's context window long before it
+reaches its turn limit. With compaction enabled, each executor estimates the
+conversation size before every request and, once it crosses
+Config.Threshold, replaces the oldest tool results with a short Placeholder.
+
+Elision rewrites only the content of a tool result. The tool call, its ID and
+the result block itself stay in place, so every tool_use/tool_result pair
+stays valid and the executors' cache breakpoints keep their positions. The
+newest KeepRecent results, and the ones the model has not seen yet, are never
+elided.
+
+# Usage
+
+Enable it with the executor's WithCompaction option (or
+metaagent.Config.Compaction):
+
+	exec, err := claudeexecutor.New[*Request, *Result](client, prompt,
+		claudeexecutor.WithCompaction[*Request, *Result](compaction.Config{
+			Threshold: 150_000,
+		}),
+	)
+
+Each compaction step is recorded on the agenttrace.Trace as an
+agenttrace.Compaction event.
+
+# Trade-offs
+
+Eliding a result invalidates the prompt cache from that point onward, so
+compaction works in steps: it elides down to three quarters of the
+threshold, then leaves the conversation alone until it grows past the
+threshold again. Size is estimated from the serialized conversation
+(EstimateTokens) rather than counted by the provider, so leave headroom
+below the model's real context limit.
+*/
+package compaction
@@ -0,0 +1,28 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compaction_test
+
+import (
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+)
+
+// ExampleConfig_Plan shows the elision decision for a conversation that has
+// grown past its threshold.
+func ExampleConfig_Plan() {
+	cfg := compaction.Config{Threshold: 20_000, KeepRecent: 2}
+
+	// Byte sizes of the tool results the model has seen, oldest first.
+	sizes := []int{40_000, 400, 24_000, 16_000, 8_000}
+	p := cfg.Plan(24_000, sizes)
+
+	fmt.Println("elide:", p.Elide)
+	fmt.Println("under threshold:", p.EstimatedTokensAfter <= cfg.Threshold)
+	// Output:
+	// elide: [0]
+	// under threshold: true
+}
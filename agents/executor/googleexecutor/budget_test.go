@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// TestBudgetNudgesThenAborts drives a model that never submits against a
+// 1200-token budget at 500 tokens per response: the third request carries the
+// nudge next to its function response, and the run is aborted with a
+// *budget.ExceededError before a fourth request is sent.
+func TestBudgetNudgesThenAborts(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingGenerateContentServer(t, nil, func(reqNum int, body []byte) string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		return fmt.Sprintf(`{
+			"candidates":[{"content":{"parts":[{"functionCall":{"id":"call_%d","name":"look","args":{}}}]}}],
+			"usageMetadata":{"promptTokenCount":400,"candidatesTokenCount":100,"totalTokenCount":500}
+		}`, reqNum)
+	})
+
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := googleexecutor.New[errCapRequest, errCapResponse](
+		newTestClient(t, srv.URL),
+		prompt,
+		googleexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		googleexecutor.WithBudget[errCapRequest, errCapResponse](budget.Config{MaxTokens: 1200}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]googletool.Metadata[errCapResponse]{
+		"look": {
+			Definition: &genai.FunctionDeclaration{Name: "look"},
+			Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse], _ *errCapResponse) *genai.FunctionResponse {
+				return &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: map[string]any{"ok": true}}
+			},
+		},
+	}
+
+	_, err = exec.Execute(t.Context(), errCapRequest{}, tools)
+	exceeded, ok := budget.AsExceeded(err)
+	if !ok {
+		t.Fatalf("Execute: got = %v, want a *budget.ExceededError", err)
+	}
+	// The test server's context cache write (5 tokens) counts too.
+	want := budget.Usage{InputTokens: 1200, OutputTokens: 300, CacheWriteTokens: 5}
+	if exceeded.Limit != budget.LimitTokens || exceeded.Usage != want {
+		t.Errorf("ExceededError: got = %+v, want tokens exceeded with usage %+v", exceeded, want)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got = %d, want = 3", len(bodies))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if got := strings.Count(bodies[i], "nearly out of budget for this task"); got != want {
+			t.Errorf("request %d nudges: got = %d, want = %d", i+1, got, want)
+		}
+	}
+}
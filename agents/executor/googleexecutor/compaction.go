@@ -0,0 +1,98 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"google.golang.org/genai"
+)
+
+// compactedOutputKey is the response field an elided function response
+// carries its placeholder under.
+const compactedOutputKey = "output"
+
+// compact runs a compaction step ahead of sending pending, the function
+// responses the model has not seen yet. The genai chat owns the conversation,
+// so candidates are the function responses in its curated history and, when
+// any is elided, the chat is rebuilt over a compacted copy of that history —
+// the history the next Send would have sent, with each elided response keeping
+// its ID and name so it still pairs with its call. The returned chat is the
+// one to send on; it is chat itself when nothing was elided.
+func (e *executor[Request, Response]) compact(ctx context.Context, trace *agenttrace.Trace[Response], turn int, chat *genai.Chat, config *genai.GenerateContentConfig, pending []*genai.Part) (*genai.Chat, error) {
+	if e.compaction == nil {
+		return chat, nil
+	}
+	type position struct{ content, part int }
+	history := chat.History(true)
+	var (
+		positions []position
+		sizes     []int
+	)
+	for c, content := range history {
+		for p, part := range content.Parts {
+			if size, ok := functionResponseSize(part); ok {
+				positions = append(positions, position{content: c, part: p})
+				sizes = append(sizes, size)
+			}
+		}
+	}
+
+	compacted := slices.Clone(history)
+	elided := false
+	conversation := slices.Concat(history, []*genai.Content{{Role: genai.RoleUser, Parts: pending}})
+	err := execshared.Compact(ctx, trace, e.compaction, turn, conversation, sizes, func(i int, placeholder string) {
+		pos := positions[i]
+		// Copy-on-write: the curated history's contents are shared with the
+		// live chat, which must not change under it.
+		content := *compacted[pos.content]
+		content.Parts = slices.Clone(content.Parts)
+		fr := content.Parts[pos.part].FunctionResponse
+		content.Parts[pos.part] = &genai.Part{FunctionResponse: &genai.FunctionResponse{
+			ID:       fr.ID,
+			Name:     fr.Name,
+			Response: map[string]any{compactedOutputKey: placeholder},
+		}}
+		compacted[pos.content] = &content
+		elided = true
+	})
+	if err != nil || !elided {
+		return chat, err
+	}
+	// Chats.Create rejects contents without a role, but the chat records a
+	// model reply verbatim and the API may leave its role unset. Every
+	// content the chat recorded without one is a model reply.
+	for i, content := range compacted {
+		if content.Role == "" {
+			c := *content
+			c.Role = genai.RoleModel
+			compacted[i] = &c
+		}
+	}
+	return e.client.Chats.Create(ctx, e.model, config, compacted)
+}
+
+// functionResponseSize returns the serialized size of a part's function
+// response, and false for any other part or a response already elided.
+func functionResponseSize(part *genai.Part) (int, bool) {
+	if part == nil || part.FunctionResponse == nil {
+		return 0, false
+	}
+	resp := part.FunctionResponse.Response
+	if s, ok := resp[compactedOutputKey].(string); ok && len(resp) == 1 && compaction.IsPlaceholder(s) {
+		return 0, false
+	}
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return 0, false
+	}
+	return len(raw), true
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// TestCompactionElidesOldFunctionResponses drives three large function
+// responses through a tight compaction budget. The validating server checks
+// functionCall/functionResponse pairing on every request, including the one
+// sent on the chat rebuilt over the compacted history.
+func TestCompactionElidesOldFunctionResponses(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingGenerateContentServer(t, nil, func(reqNum int, body []byte) string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if reqNum <= 3 {
+			return fmt.Sprintf(`{
+				"candidates":[{"content":{"parts":[{"functionCall":{"id":"call_%d","name":"read","args":{}}}]}}],
+				"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1,"totalTokenCount":2}
+			}`, reqNum)
+		}
+		return submitTurnJSON
+	})
+
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := googleexecutor.New[errCapRequest, errCapResponse](
+		newTestClient(t, srv.URL),
+		prompt,
+		googleexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		googleexecutor.WithCompaction[errCapRequest, errCapResponse](compaction.Config{Threshold: 6000, KeepRecent: 1}),
+		googleexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](func() (googletool.SubmitMetadata[errCapResponse], error) {
+			return googletool.SubmitMetadata[errCapResponse]{
+				Definition: &genai.FunctionDeclaration{Name: "submit_result"},
+				Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+					answer, _ := call.Args["answer"].(string)
+					return toolcall.SubmitOutcome[errCapResponse]{
+						Accepted:   true,
+						Response:   errCapResponse{Answer: answer},
+						ToolResult: map[string]any{"success": true},
+					}
+				},
+			}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	output := strings.Repeat("x", 20_000)
+	tools := map[string]googletool.Metadata[errCapResponse]{
+		"read": {
+			Definition: &genai.FunctionDeclaration{Name: "read"},
+			Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse], _ *errCapResponse) *genai.FunctionResponse {
+				return &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: map[string]any{"content": output}}
+			},
+		},
+	}
+
+	tracer := &recordingTracer{}
+	ctx := agenttrace.WithTracer[errCapResponse](t.Context(), tracer)
+	resp, err := exec.Execute(ctx, errCapRequest{}, tools)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if resp.Answer != "42" {
+		t.Errorf("Answer: got = %q, want = %q", resp.Answer, "42")
+	}
+
+	if len(bodies) != 4 {
+		t.Fatalf("requests: got = %d, want = 4", len(bodies))
+	}
+	for i, want := range []int{0, 0, 0, 1} {
+		if got := strings.Count(bodies[i], "[elided tool result:"); got != want {
+			t.Errorf("request %d placeholders: got = %d, want = %d", i+1, got, want)
+		}
+	}
+	if got, want := strings.Count(bodies[3], output), 2; got != want {
+		t.Errorf("request 4 verbatim responses: got = %d, want = %d", got, want)
+	}
+
+	compactions := tracer.traces[0].Compactions
+	if len(compactions) != 1 || compactions[0].ElidedToolResults != 1 {
+		t.Errorf("Compactions: got = %+v, want one step eliding one response", compactions)
+	}
+}
@@ -64,6 +64,10 @@ The executor supports various configuration options:
   - WithResponseSchema: Define structured output schema
   - WithThinking: Enable thinking mode with a token budget
   - WithEffort: Enable thinking via the provider-neutral effort scale
+  - WithSuspendTool: Register an ask-a-friend tool that parks the run for Resumer
+  - WithCompaction: Elide older function responses past a context budget
+  - WithBudget: Cap each run's tokens and estimated cost
+  - WithObserver: Receive progress events as the run proceeds
 
 # Thinking Mode
 
@@ -138,6 +142,9 @@ The executor provides comprehensive error handling:
   - Chat sessions are created per execution (not reused)
   - Tool responses are sent synchronously
   - Large response schemas may impact latency
+  - Function responses accumulate in the chat history; WithCompaction elides
+    the oldest once the conversation outgrows a token budget, rebuilding the
+    chat session over the compacted history
 
 # Thread Safety
 
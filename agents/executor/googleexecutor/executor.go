@@ -8,6 +8,7 @@ package googleexecutor
 import (
 	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -16,9 +17,13 @@ import (
 	"time"
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
 	"chainguard.dev/driftlessaf/agents/executor/internal/telemetry"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/metrics"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
@@ -79,6 +84,7 @@ type executor[Request promptbuilder.Bindable, Response any] struct {
 	thinkingBudget   *int32                              // nil = disabled, non-nil = enabled with budget
 	effortLevel      effort.Level                        // "" = unset; resolved per model via thinkingConfigForEffort
 	submitTool       googletool.SubmitMetadata[Response] // opt-in: set via WithSubmitResultProvider
+	suspendTool      *genai.FunctionDeclaration          // opt-in: set via WithSuspendTool; nil disables suspension entirely
 	telemetry        *telemetry.Recorder                 // shared GenAI metrics recorder, built after options in New
 	retryConfig      retry.RetryConfig                   // retry configuration for transient Vertex AI errors
 	resourceLabels   map[string]string                   // resource labels for GCP billing attribution
@@ -120,6 +126,19 @@ type executor[Request promptbuilder.Bindable, Response any] struct {
 	// safe for concurrent use (they share the trace, which is safe). Set via
 	// WithToolCallConcurrency.
 	toolCallConcurrency int
+
+	// compaction, when non-nil, elides older function responses once the
+	// conversation's estimated size crosses the configured threshold. Nil
+	// (the default) keeps the history verbatim. Set via WithCompaction.
+	compaction *compaction.Config
+
+	// budget, when non-nil, caps each run's token usage and estimated cost.
+	// Nil (the default) leaves spend uncapped. Set via WithBudget.
+	budget *budget.Config
+
+	// observer receives the run's progress events as they happen. Nil (the
+	// default) discards them. Set via WithObserver.
+	observer progress.Observer
 }
 
 // New creates a new Google AI executor with the given configuration
@@ -177,6 +196,20 @@ func New[Request promptbuilder.Bindable, Response any](
 		}
 	}
 
+	if err := execshared.ValidateModelLimits(exec.model, int64(exec.maxOutputTokens), exec.effortLevel); err != nil {
+		return nil, err
+	}
+
+	// A suspend tool must not share the terminal submit tool's name. Checked
+	// after all options are applied so the order they were supplied in is
+	// irrelevant; the complementary caller-tool collision is checked in
+	// Execute, where the run's tool map is known. See suspend.go.
+	if exec.suspendTool != nil {
+		if err := execshared.ValidateSuspendToolName(exec.suspendToolName(), exec.submitToolName()); err != nil {
+			return nil, err
+		}
+	}
+
 	// The recorder is built after options so it captures the final model and
 	// resource labels.
 	exec.telemetry = telemetry.NewRecorder(genaiMetrics, exec.model, "gcp.vertex_ai", exec.resourceLabels, responseCodeFromError)
@@ -228,10 +261,25 @@ func (e *executor[Request, Response]) Execute(
 		done(resp, err)
 	}()
 
-	// submitToolName is the configured terminal tool the model calls to
-	// return its result. Empty when no submit tool is registered.
-	submitToolName := e.submitToolName()
+	// The conversation starts from the user prompt; seed tool calls, when
+	// given, are executed and appended to it in runConversation.
+	history := []*genai.Content{{
+		Role:  "user",
+		Parts: []*genai.Part{{Text: prompt}},
+	}}
+
+	// A fresh Execute runs the full turn budget starting at turn 0. Resume
+	// (resume.go) shares the same loop with the restored history, a startTurn
+	// past the suspension point, and the envelope's remaining budget.
+	return e.runConversation(ctx, trace, history, tools, 0, e.maxTurns, seedToolCalls, nil)
+}
 
+// buildStaticConfig assembles the turn-invariant generation config: the tool
+// declarations, system instruction, sampling, response format, and thinking
+// configuration, inline (context caching and resource labels are applied by
+// runConversation, so the config digest taken over this value is independent
+// of both). It also returns the sorted tool declarations.
+func (e *executor[Request, Response]) buildStaticConfig(tools map[string]googletool.Metadata[Response]) (*genai.GenerateContentConfig, []*genai.FunctionDeclaration, error) {
 	// Build tool definitions, sorted by name for deterministic ordering.
 	//
 	// Why sort? Vertex AI context caching hashes the cached content (system
@@ -239,7 +287,7 @@ func (e *executor[Request, Response]) Execute(
 	// without sorting, the tool definitions could serialize differently on each
 	// call — producing different cache content even though the tools haven't
 	// changed. Sorting by name ensures stable content across calls.
-	toolDeclarations := make([]*genai.FunctionDeclaration, 0, len(tools)+1)
+	toolDeclarations := make([]*genai.FunctionDeclaration, 0, len(tools)+2)
 	for _, meta := range tools {
 		toolDeclarations = append(toolDeclarations, meta.Definition)
 	}
@@ -247,11 +295,19 @@ func (e *executor[Request, Response]) Execute(
 	// outside the tools map — dispatch routes it through evaluateSubmission —
 	// but the model discovers it the same way. A caller-registered tool with
 	// the same name takes precedence, matching dispatch.
-	if submitToolName != "" {
-		if _, exists := tools[submitToolName]; !exists {
+	if name := e.submitToolName(); name != "" {
+		if _, exists := tools[name]; !exists {
 			toolDeclarations = append(toolDeclarations, e.submitTool.Definition)
 		}
 	}
+	// Advertise the held-out ask-a-friend suspend tool the same way. A
+	// caller-registered tool of the same name is rejected in runConversation,
+	// so the no-collision case is all that reaches the model.
+	if name := e.suspendToolName(); name != "" {
+		if _, exists := tools[name]; !exists {
+			toolDeclarations = append(toolDeclarations, e.suspendTool)
+		}
+	}
 	slices.SortFunc(toolDeclarations, func(a, b *genai.FunctionDeclaration) int {
 		return cmp.Compare(a.Name, b.Name)
 	})
@@ -260,51 +316,22 @@ func (e *executor[Request, Response]) Execute(
 	config := &genai.GenerateContentConfig{
 		Temperature:     ptr(e.temperature),
 		MaxOutputTokens: e.maxOutputTokens,
-		Labels:          e.resourceLabels,
 	}
 
 	// Build system instruction content
-	var systemInstruction *genai.Content
 	if e.systemInstructions != nil {
 		systemPrompt, err := e.systemInstructions.Build()
 		if err != nil {
-			return resp, fmt.Errorf("building system prompt: %w", err)
+			return nil, nil, fmt.Errorf("building system prompt: %w", err)
 		}
-		systemInstruction = &genai.Content{
+		config.SystemInstruction = &genai.Content{
 			Parts: []*genai.Part{{Text: systemPrompt}},
 		}
 	}
 
 	// Build tools
-	var genaiTools []*genai.Tool
 	if len(toolDeclarations) > 0 {
-		genaiTools = []*genai.Tool{{FunctionDeclarations: toolDeclarations}}
-	}
-
-	// Attempt to use cached content for system instructions + tools.
-	// When using CachedContent, SystemInstruction and Tools MUST NOT be set
-	// on GenerateContentConfig — they are already in the cache.
-	usedCache := false
-	var cacheCreationTokens int64
-	if e.cacheControl && (systemInstruction != nil || len(genaiTools) > 0) {
-		cacheName, createdTokens, err := e.getOrCreateCache(ctx, systemInstruction, genaiTools)
-		if err != nil {
-			clog.WarnContext(ctx, "Failed to create cached content, falling back to non-cached mode", "error", err)
-		} else {
-			config.CachedContent = cacheName
-			cacheCreationTokens = createdTokens
-			usedCache = true
-		}
-	}
-
-	// Fall back to inline system instruction and tools if not using cache.
-	if !usedCache {
-		if systemInstruction != nil {
-			config.SystemInstruction = systemInstruction
-		}
-		if len(genaiTools) > 0 {
-			config.Tools = genaiTools
-		}
+		config.Tools = []*genai.Tool{{FunctionDeclarations: toolDeclarations}}
 	}
 
 	// Add response MIME type if provided
@@ -329,53 +356,95 @@ func (e *executor[Request, Response]) Execute(
 		config.ThinkingConfig = thinkingConfigForEffort(e.model, e.effortLevel)
 	}
 
+	return config, toolDeclarations, nil
+}
+
+// runConversation drives the bounded turn loop shared by Execute (a fresh run,
+// startTurn 0) and Resume (a restored run, startTurn env.Turn+1). history is
+// the conversation so far, ending with the user content to send next: Execute
+// passes the user prompt, Resume the parked transcript whose final content
+// carries the suspended turn's function responses and the framed answers.
+// turnBudget bounds how many turns may run before aborting — for a resume it
+// is the envelope's remaining budget, so the overall turn budget is enforced
+// across pauses. loopState is the envelope's LoopState for a resumed run (nil
+// for a fresh one); the budget spend it carries seeds the run's tracker. trace
+// is already started by the caller.
+func (e *executor[Request, Response]) runConversation(
+	ctx context.Context,
+	trace *agenttrace.Trace[Response],
+	history []*genai.Content,
+	tools map[string]googletool.Metadata[Response],
+	startTurn int,
+	turnBudget int,
+	seedToolCalls []*genai.FunctionCall,
+	loopState json.RawMessage,
+) (resp Response, err error) {
+	// Observers learn the call is over however it returns, so one that
+	// publishes asynchronously can flush before the caller moves on.
+	defer e.observer.Emit(ctx, progress.Event{Kind: progress.RunCompleted})
+
+	// submitToolName is the configured terminal tool the model calls to
+	// return its result. Empty when no submit tool is registered.
+	submitToolName := e.submitToolName()
+
+	// suspendToolName is the configured ask-a-friend suspend tool. Empty when
+	// WithSuspendTool was not applied, in which case isSuspend is always false
+	// and every suspend-specific branch below is dead.
+	suspendToolName := e.suspendToolName()
+
+	// Partition the tool set into the routing predicates the dispatch loop
+	// consults: isSubmit routes the terminal submit tool (executeToolCall's
+	// dispatch switch), isSuspend the ask-a-friend suspend tool, and heldOut
+	// is their union — dispatched only after the concurrent pool drains so a
+	// held-out call observes every sibling's real function response (see
+	// execshared.HeldOutPartition). It also rejects a suspend tool shadowed by
+	// a caller-registered tool of the same name.
+	isSubmit, isSuspend, heldOut, err := execshared.HeldOutPartition(tools, submitToolName, e.submitTool.Handler != nil, suspendToolName, e.suspendTool != nil)
+	if err != nil {
+		return resp, err
+	}
+
+	config, toolDeclarations, err := e.buildStaticConfig(tools)
+	if err != nil {
+		return resp, err
+	}
+	config.Labels = e.resourceLabels
+
+	// Attempt to use cached content for system instructions + tools.
+	// When using CachedContent, SystemInstruction and Tools MUST NOT be set
+	// on GenerateContentConfig — they are already in the cache.
+	var cacheCreationTokens int64
+	if e.cacheControl && (config.SystemInstruction != nil || len(config.Tools) > 0) {
+		cacheName, createdTokens, err := e.getOrCreateCache(ctx, config.SystemInstruction, config.Tools)
+		if err != nil {
+			clog.WarnContext(ctx, "Failed to create cached content, falling back to non-cached mode", "error", err)
+		} else {
+			config.CachedContent = cacheName
+			config.SystemInstruction = nil
+			config.Tools = nil
+			cacheCreationTokens = createdTokens
+		}
+	}
+
+	// spend tracks the run's usage against the optional budget; nil when
+	// WithBudget was not applied, which makes every call on it a no-op.
+	// sendWithRetry enforces it before each request and accounts each
+	// response.
+	spend, err := execshared.NewTracker(e.budget, e.model, loopState)
+	if err != nil {
+		return resp, err
+	}
+	spend.Add(budget.Usage{CacheWriteTokens: cacheCreationTokens})
+
 	// Create a new chat session with optional seed messages
 	clog.InfoContext(ctx, "Creating Google AI chat session",
 		"model", e.model,
 	)
 
-	// Pre-execute seed tool calls and prepare history
-	// Build complete history, then split: use first n-1 for chat creation, send last via SendMessage
-	history := make([]*genai.Content, 0, 1+len(seedToolCalls)*2)
-
-	// Add initial user prompt to history
-	history = append(history, &genai.Content{
-		Role: "user",
-		Parts: []*genai.Part{{
-			Text: prompt,
-		}},
-	})
-
 	// finalResult stores the result if a tool sets it
 	var finalResult Response
 	finalResultPtr := &finalResult
 
-	// isSubmit reports whether a call routes to the terminal submit tool. It
-	// is the routing predicate consulted by executeToolCall's dispatch switch.
-	isSubmit := execshared.SubmitPredicate(tools, submitToolName, e.submitTool.Handler != nil)
-
-	// heldOutTools is the set of tool names dispatched sequentially after the
-	// concurrent tool pool drains, rather than within it (see
-	// execshared.DispatchToolCalls). A held-out call's work runs only once
-	// every sibling handler has finished and its real tool_result is in the
-	// transcript. Today the only member is the terminal submit tool — its
-	// result validators may read state the sibling handlers produce
-	// (worktrees, files), so they must observe the finished state rather than
-	// race the handlers still producing it — and only when the call actually
-	// routes to submit (a caller tool of the same name shadows it and is
-	// dispatched in the pool, so it is not held out). Membership is derived
-	// from isSubmit so the two predicates cannot drift. Building the partition
-	// as a set is the seam DEV-2247 widens: the suspend tool joins this set so
-	// it too quiesces behind its siblings' real tool_results.
-	heldOutTools := make(map[string]struct{}, 1)
-	if isSubmit(submitToolName) {
-		heldOutTools[submitToolName] = struct{}{}
-	}
-	heldOut := func(name string) bool {
-		_, ok := heldOutTools[name]
-		return ok
-	}
-
 	// executeToolCall runs a single function call and returns its response
 	// part. The handler writes any terminal result into resultPtr; each call
 	// gets its own slot on the concurrent path so handlers never race on the
@@ -383,13 +452,14 @@ func (e *executor[Request, Response]) Execute(
 	// accepted the call and the registered result validators passed, so
 	// resultPtr holds the run's final result — even when that result is the
 	// zero value.
-	executeToolCall := func(call *genai.FunctionCall, resultPtr *Response) (*genai.Part, bool, error) {
+	executeToolCall := func(turn int, call *genai.FunctionCall, resultPtr *Response) (*genai.Part, bool, error) {
 		kvs := make([]any, 0, 4+2*len(call.Args))
 		kvs = append(kvs, "tool", call.Name, "id", call.ID)
 		for k, v := range call.Args {
 			kvs = append(kvs, "args."+k, v)
 		}
 		clog.InfoContext(ctx, "Executing tool call", kvs...)
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallStarted, Turn: turn, ToolCallID: call.ID, ToolName: call.Name})
 
 		// Record tool call metric
 		e.telemetry.RecordToolCall(ctx, call.Name)
@@ -413,12 +483,18 @@ func (e *executor[Request, Response]) Execute(
 			// the run's final result. A rejected submission returns the
 			// validators' findings as the tool result so the model can address
 			// them and submit again — the loop continues.
-			result, com, err := e.evaluateSubmission(ctx, call, trace, resultPtr)
+			result, com, err := e.evaluateSubmission(ctx, turn, call, trace, resultPtr)
 			if err != nil {
 				return nil, false, err
 			}
 			committed = com
 			toolResponse = &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: result}
+		case isSuspend(call.Name):
+			// Held-out ask-a-friend tool. Its real result is the human's
+			// answer, supplied on resume — not here. The placeholder keeps the
+			// transcript paired in the submit-wins case; on the suspension path
+			// it is dropped so the answer can take its slot.
+			toolResponse = &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: map[string]any{"status": checkpoint.StatusAwaitingAnswer}}
 		default:
 			clog.ErrorContext(ctx, "Unknown function call requested by model", "function", call.Name)
 			toolResponse = googletool.Error(call, "Unknown function: %s", call.Name)
@@ -430,6 +506,8 @@ func (e *executor[Request, Response]) Execute(
 			trace.BadToolCall(call.ID, call.Name, call.Args, fmt.Errorf("unknown function %q: %w", call.Name, agenttrace.ErrUnknownTool))
 		}
 
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallCompleted, Turn: turn, ToolCallID: call.ID, ToolName: call.Name, Error: progress.ToolError(toolResponse.Response)})
+
 		return &genai.Part{FunctionResponse: toolResponse}, committed, nil
 	}
 
@@ -437,7 +515,7 @@ func (e *executor[Request, Response]) Execute(
 	for _, call := range seedToolCalls {
 		clog.InfoContext(ctx, "Pre-executing seed tool call", "tool", call.Name, "id", call.ID)
 
-		part, committed, err := executeToolCall(call, finalResultPtr)
+		part, committed, err := executeToolCall(startTurn, call, finalResultPtr)
 		if err != nil {
 			return resp, err
 		}
@@ -471,7 +549,7 @@ func (e *executor[Request, Response]) Execute(
 
 	// Send final message to get response with retry for transient errors
 	clog.InfoContext(ctx, "Sending final message")
-	response, err := e.sendWithRetry(ctx, e.telemetry.WithAPIRequestCounter(ctx, e.retryConfig), "send_initial_message", "failed to send final message", func() (*genai.GenerateContentResponse, error) {
+	response, err := e.sendWithRetry(ctx, spend, e.telemetry.WithAPIRequestCounter(ctx, e.retryConfig), "send_initial_message", "failed to send final message", func() (*genai.GenerateContentResponse, error) {
 		return chat.Send(ctx, history[len(history)-1].Parts...)
 	})
 	if err != nil {
@@ -493,11 +571,12 @@ func (e *executor[Request, Response]) Execute(
 		var zero Response
 		llmTurn := trace.BeginTurn(turn, agenttrace.SystemGoogleVertex, e.model)
 		defer func() {
-			if err != nil {
-				llmTurn.Fail(err)
-			}
+			// A suspension is an intentional halt, not a failure.
+			execshared.FailTurnUnlessSuspended(llmTurn, err)
 			llmTurn.End()
+			e.observer.Emit(ctx, progress.Event{Kind: progress.TurnCompleted, Turn: turn})
 		}()
+		e.observer.Emit(ctx, progress.Event{Kind: progress.TurnStarted, Turn: turn})
 
 		// Per-turn retry config wires transient API errors that the retry
 		// recovers from into the turn's Errors list. Without this, retries
@@ -527,7 +606,7 @@ func (e *executor[Request, Response]) Execute(
 		// Gemini cache creation at zero while retaining the count for usage
 		// accounting.
 		createdTokens := int64(0)
-		if turn == 0 {
+		if turn == startTurn {
 			createdTokens = cacheCreationTokens
 		}
 		if e.cacheControl && (cacheReadTokens > 0 || createdTokens > 0) {
@@ -583,7 +662,7 @@ func (e *executor[Request, Response]) Execute(
 
 			// Ask the model to provide a more concise version
 			retryMsg := genai.Part{Text: "Your response exceeded the maximum output token limit. Please provide a more concise response that focuses on the most critical information while still completing the task."}
-			retryResp, err := e.sendWithRetry(ctx, turnCfg, "send_max_tokens_retry", "failed to send retry message after hitting max tokens", func() (*genai.GenerateContentResponse, error) {
+			retryResp, err := e.sendWithRetry(ctx, spend, turnCfg, "send_max_tokens_retry", "failed to send retry message after hitting max tokens", func() (*genai.GenerateContentResponse, error) {
 				return chat.SendMessage(ctx, retryMsg)
 			})
 			if err != nil {
@@ -607,7 +686,7 @@ func (e *executor[Request, Response]) Execute(
 
 			// Send a message asking the model to try again with retry for transient errors
 			retryMsg := genai.Part{Text: fmt.Sprintf("The function call was malformed. Please try again using the available functions: %v", funcNames)}
-			retryResp, err := e.sendWithRetry(ctx, turnCfg, "send_malformed_retry", "failed to send retry message after malformed function call", func() (*genai.GenerateContentResponse, error) {
+			retryResp, err := e.sendWithRetry(ctx, spend, turnCfg, "send_malformed_retry", "failed to send retry message after malformed function call", func() (*genai.GenerateContentResponse, error) {
 				return chat.SendMessage(ctx, retryMsg)
 			})
 			if err != nil {
@@ -646,7 +725,7 @@ func (e *executor[Request, Response]) Execute(
 			clog.WarnContext(ctx, "Model returned an unusable response, asking it to continue",
 				"reason", what, "attempt", emptyTurns, "turn", turn)
 			retryMsg := genai.Part{Text: "Your last response was empty. Please continue: either call one of the available functions or provide your answer as text."}
-			resp, err := e.sendWithRetry(ctx, turnCfg, "send_empty_response_retry", "failed to send retry message after an empty response", func() (*genai.GenerateContentResponse, error) {
+			resp, err := e.sendWithRetry(ctx, spend, turnCfg, "send_empty_response_retry", "failed to send retry message after an empty response", func() (*genai.GenerateContentResponse, error) {
 				return chat.SendMessage(ctx, retryMsg)
 			})
 			if err != nil {
@@ -696,12 +775,14 @@ func (e *executor[Request, Response]) Execute(
 				trace.AppendReasoning(agenttrace.ReasoningContent{
 					Thinking: part.Text,
 				})
+				e.observer.Emit(ctx, progress.Event{Kind: progress.ThinkingDelta, Turn: turn, Text: part.Text})
 				clog.InfoContext(ctx, "Found thought part",
 					"part_index", i,
 					"thinking_length", len(part.Text))
 			case part.Text != "":
 				responseText = part.Text
 				hasText = true
+				e.observer.Emit(ctx, progress.Event{Kind: progress.TextDelta, Turn: turn, Text: part.Text})
 				clog.InfoContext(ctx, "Found text part",
 					"part_index", i,
 					"text_length", len(part.Text))
@@ -737,16 +818,26 @@ func (e *executor[Request, Response]) Execute(
 			execshared.DispatchToolCalls(toolCalls, e.toolCallConcurrency,
 				func(call *genai.FunctionCall) bool { return heldOut(call.Name) },
 				func(i int, call *genai.FunctionCall) {
-					part, committed, cerr := executeToolCall(call, &perCallResults[i])
+					part, committed, cerr := executeToolCall(turn, call, &perCallResults[i])
 					parts[i] = part
 					outcomes[i] = toolOutcome{committed: committed, err: cerr}
 				})
 
+			// suspendIdx records the slot of the held-out suspend call, if the
+			// model issued one this turn. Its response part is left out so the
+			// human answer takes the pending slot on resume; the suspension
+			// itself is deferred until the whole turn is consumed, because a
+			// sibling terminal result makes the pending question moot.
+			suspendIdx := -1
 			var toolResponseParts []*genai.Part
 			for i := range toolCalls {
 				if outcomes[i].err != nil {
 					return zero, nil, true, outcomes[i].err
 				}
+				if isSuspend(toolCalls[i].Name) {
+					suspendIdx = i
+					continue
+				}
 				// The committed flag is the submit tool's explicit terminal
 				// signal — it fires even for a zero-value result, so the model
 				// is never told "submitted successfully" on a run that keeps
@@ -761,8 +852,42 @@ func (e *executor[Request, Response]) Execute(
 				toolResponseParts = append(toolResponseParts, parts[i])
 			}
 
+			// No terminal result won the turn: if the model asked to pause,
+			// park the conversation now, before the siblings' responses are
+			// sent. The parked transcript is the chat's curated history (the
+			// model turn carrying the suspend call is its last entry) plus a
+			// user content holding the siblings' real responses, into which
+			// the answer is paired on resume.
+			if suspendIdx >= 0 {
+				transcript := slices.Concat(chat.History(true), []*genai.Content{{Role: "user", Parts: toolResponseParts}})
+				susp, berr := e.buildSuspension(transcript, tools, turn, suspendIdx, toolCalls[suspendIdx], spend, trace.ID)
+				if berr != nil {
+					return zero, nil, true, berr
+				}
+				execshared.ParkSuspension(ctx, trace, e.telemetry, susp)
+				return zero, nil, true, susp
+			}
+
+			// Ask the model once to wrap up as the budget nears. The
+			// instruction rides alongside the function responses; Gemini
+			// accepts text and functionResponse parts in one user content.
+			if spend.Nudge() {
+				e.telemetry.RecordToolCall(ctx, "budget_nudge")
+				clog.InfoContext(ctx, "Budget nearly spent, nudging model to emit result",
+					"tokens", spend.Usage().Total(), "cost_usd", spend.CostUSD())
+				toolResponseParts = append(toolResponseParts, &genai.Part{Text: budget.NudgeMessage(submitToolName)})
+			}
+
+			// Elide older function responses first if the conversation has
+			// outgrown its compaction budget; this may swap in a rebuilt chat.
+			compacted, cerr := e.compact(ctx, trace, turn, chat, config, toolResponseParts)
+			if cerr != nil {
+				return zero, nil, true, cerr
+			}
+			chat = compacted
+
 			// Send tool responses back to the chat with retry for transient errors
-			nextResponse, err := e.sendWithRetry(ctx, turnCfg, "send_tool_responses", "failed to send tool responses", func() (*genai.GenerateContentResponse, error) {
+			nextResponse, err := e.sendWithRetry(ctx, spend, turnCfg, "send_tool_responses", "failed to send tool responses", func() (*genai.GenerateContentResponse, error) {
 				return chat.Send(ctx, toolResponseParts...)
 			})
 			if err != nil {
@@ -778,7 +903,7 @@ func (e *executor[Request, Response]) Execute(
 			clog.WarnContext(ctx, "Model responded with text instead of calling submit_result, redirecting")
 			e.telemetry.RecordToolCall(ctx, "submit_result_redirect")
 
-			redirectResp, err := e.sendWithRetry(ctx, turnCfg, "send_submit_redirect", "failed to send submit_result redirect", func() (*genai.GenerateContentResponse, error) {
+			redirectResp, err := e.sendWithRetry(ctx, spend, turnCfg, "send_submit_redirect", "failed to send submit_result redirect", func() (*genai.GenerateContentResponse, error) {
 				return chat.SendMessage(ctx, genai.Part{
 					Text: "You must call the submit_result tool to return your response. Do not respond with plain text. If you encountered an error or cannot complete the task, call submit_result with an appropriate error or summary.",
 				})
@@ -815,8 +940,11 @@ func (e *executor[Request, Response]) Execute(
 		return zero, nil, true, errors.New("unexpected response format from model")
 	}
 
-	for turn := range e.maxTurns {
-		result, nextResp, done, err := executeTurn(turn, response)
+	// turn is offset by startTurn so a resumed run's trace turns continue past
+	// the suspension point; the loop runs at most turnBudget iterations so the
+	// overall turn budget is honored across pauses.
+	for i := range turnBudget {
+		result, nextResp, done, err := executeTurn(startTurn+i, response)
 		// done=true on all terminal paths (including errors); || err != nil is a
 		// safety net in case a future path sets err without setting done.
 		if done || err != nil {
@@ -828,15 +956,16 @@ func (e *executor[Request, Response]) Execute(
 		response = nextResp
 	}
 
-	clog.ErrorContext(ctx, "Agent exceeded maximum conversation turns", "max_turns", e.maxTurns)
-	e.telemetry.RecordTurns(ctx, e.maxTurns, true)
+	lastTurn := startTurn + turnBudget
+	clog.ErrorContext(ctx, "Agent exceeded maximum conversation turns", "max_turns", turnBudget)
+	e.telemetry.RecordTurns(ctx, lastTurn, true)
 	// The final unprocessed response carries real billable tokens (the
 	// model already generated them). Without this synthetic turn the
 	// loop would exit and only OTel metrics would see them — turns[]
 	// is the source of truth for cost analysis (DEV-1140), so leaving
 	// them off would silently undercount maxTurns-exhausted runs.
 	if response != nil && response.UsageMetadata != nil {
-		llmTurn := trace.BeginTurn(e.maxTurns, agenttrace.SystemGoogleVertex, e.model)
+		llmTurn := trace.BeginTurn(lastTurn, agenttrace.SystemGoogleVertex, e.model)
 		inputTokens, outputTokens := inputOutputTokenCounts(response.UsageMetadata)
 		llmTurn.RecordTokens(inputTokens, outputTokens)
 		if e.cacheControl && response.UsageMetadata.CachedContentTokenCount > 0 {
@@ -844,7 +973,7 @@ func (e *executor[Request, Response]) Execute(
 		}
 		llmTurn.End()
 	}
-	return resp, fmt.Errorf("agent exceeded maximum conversation turns (%d)", e.maxTurns)
+	return resp, fmt.Errorf("agent exceeded maximum conversation turns (%d)", turnBudget)
 }
 
 // submitToolName returns the configured terminal submit tool's name, or ""
@@ -864,16 +993,21 @@ func (e *executor[Request, Response]) submitToolName() string {
 // and gates its accepted response on the registered result validators via
 // execshared.GateSubmission (see there for the gate semantics). It returns
 // the tool result to send back to the model and whether the response
-// committed as the run's final result (written through resultPtr).
+// committed as the run's final result (written through resultPtr). A
+// validator rejection is reported to the observer against turn.
 func (e *executor[Request, Response]) evaluateSubmission(
 	ctx context.Context,
+	turn int,
 	call *genai.FunctionCall,
 	trace *agenttrace.Trace[Response],
 	resultPtr *Response,
 ) (map[string]any, bool, error) {
 	return execshared.GateSubmission(ctx, e.submitTool.Handler(ctx, call, trace),
 		trace, call.ID, call.Name, call.Args,
-		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr)
+		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr,
+		func(findings []callbacks.Finding) {
+			e.observer.Emit(ctx, progress.Event{Kind: progress.SubmitRejected, Turn: turn, ToolCallID: call.ID, ToolName: call.Name, Findings: findings})
+		})
 }
 
 // sendWithRetry runs a single chat send under RetryWithBackoff, counts the
@@ -882,13 +1016,21 @@ func (e *executor[Request, Response]) evaluateSubmission(
 // requeue path depends on the error type), any other failure is wrapped with
 // errContext. On success it records the response's usage metadata as OTel
 // token metrics; per-turn token attribution onto the trace happens separately,
-// at the top of the next executeTurn iteration.
+// at the top of the next executeTurn iteration. It is also where the run's
+// spend budget is enforced: a run already over budget fails with the
+// budget's error instead of sending, and each response's usage is added to
+// spend.
 func (e *executor[Request, Response]) sendWithRetry(
 	ctx context.Context,
+	spend *budget.Tracker,
 	cfg retry.RetryConfig,
 	operation, errContext string,
 	send func() (*genai.GenerateContentResponse, error),
 ) (*genai.GenerateContentResponse, error) {
+	if err := spend.Err(); err != nil {
+		clog.ErrorContext(ctx, "Agent exceeded its budget", "error", err)
+		return nil, err
+	}
 	response, err := retry.RetryWithBackoff(ctx, cfg, operation, isRetryableVertexError, send)
 	e.telemetry.RecordAPIRequest(ctx, err)
 	if err != nil {
@@ -900,6 +1042,11 @@ func (e *executor[Request, Response]) sendWithRetry(
 
 	if response != nil && response.UsageMetadata != nil {
 		e.recordTokenMetrics(ctx, response.UsageMetadata)
+		// Prompt tokens include the cached prefix; the budget prices it
+		// separately.
+		inputTokens, outputTokens := inputOutputTokenCounts(response.UsageMetadata)
+		cached := int64(response.UsageMetadata.CachedContentTokenCount)
+		spend.Add(budget.Usage{InputTokens: inputTokens - cached, OutputTokens: outputTokens, CacheReadTokens: cached})
 	}
 	return response, nil
 }
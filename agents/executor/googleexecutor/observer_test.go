@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// TestObserverReceivesParts checks that each thought and text part of a
+// response reaches the observer as one delta, alongside the turn and tool
+// call events.
+func TestObserverReceivesParts(t *testing.T) {
+	srv := newValidatingGenerateContentServer(t, nil, func(reqNum int, _ []byte) string {
+		if reqNum == 1 {
+			return `{
+				"candidates":[{"content":{"parts":[
+					{"text":"Checking the tree.","thought":true},
+					{"functionCall":{"id":"call_1","name":"look","args":{}}}
+				]}}],
+				"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}
+			}`
+		}
+		return `{
+			"candidates":[{"content":{"parts":[{"text":"{\"answer\":\"done\"}"}]},"finishReason":"STOP"}],
+			"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"totalTokenCount":15}
+		}`
+	})
+
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	var (
+		mu     sync.Mutex
+		events []progress.Event
+	)
+	exec, err := googleexecutor.New[errCapRequest, errCapResponse](
+		newTestClient(t, srv.URL),
+		prompt,
+		googleexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		googleexecutor.WithObserver[errCapRequest, errCapResponse](func(_ context.Context, ev progress.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, ev)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]googletool.Metadata[errCapResponse]{
+		"look": {
+			Definition: &genai.FunctionDeclaration{Name: "look"},
+			Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse], _ *errCapResponse) *genai.FunctionResponse {
+				return googletool.Error(call, "nothing at %s", "/")
+			},
+		},
+	}
+	got, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got.Answer != "done" {
+		t.Errorf("Answer: got = %q, want = done", got.Answer)
+	}
+
+	var kinds []progress.Kind
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []progress.Kind{
+		progress.TurnStarted,
+		progress.ThinkingDelta,
+		progress.ToolCallStarted,
+		progress.ToolCallCompleted,
+		progress.TurnCompleted,
+		progress.TurnStarted,
+		progress.TextDelta,
+		progress.TurnCompleted,
+		progress.RunCompleted,
+	}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("events: got = %v, want = %v", kinds, want)
+	}
+	if ev := events[1]; ev.Text != "Checking the tree." {
+		t.Errorf("thinking delta: got = %q, want = %q", ev.Text, "Checking the tree.")
+	}
+	if ev := events[3]; ev.ToolName != "look" || ev.Error != "nothing at /" {
+		t.Errorf("tool completion: got = %+v, want look failing with its error", ev)
+	}
+	if ev := events[5]; ev.Turn != 1 {
+		t.Errorf("final text turn: got = %d, want = 1", ev.Turn)
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/model"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
@@ -50,17 +53,14 @@ func WithTemperature[Request promptbuilder.Bindable, Response any](temperature f
 	}
 }
 
-// WithMaxOutputTokens sets the maximum output tokens for generation
+// WithMaxOutputTokens sets the maximum output tokens for generation. New
+// rejects a value above the model's output-token ceiling in the model
+// registry (65536 for current Gemini models).
 func WithMaxOutputTokens[Request promptbuilder.Bindable, Response any](tokens int32) Option[Request, Response] {
 	return func(e *executor[Request, Response]) error {
 		if tokens <= 0 {
 			return fmt.Errorf("max output tokens must be positive, got %d", tokens)
 		}
-		// Gemini models support up to 8192 tokens by default, some support more
-		// Gemini 3.1 Pro supports up to 65536 output tokens
-		if tokens > 65536 {
-			return fmt.Errorf("max output tokens %d exceeds maximum of 65536", tokens)
-		}
 		e.maxOutputTokens = tokens
 		return nil
 	}
@@ -80,6 +80,51 @@ func WithMaxTurns[Request promptbuilder.Bindable, Response any](turns int) Optio
 	}
 }
 
+// WithBudget caps each run's spend: its total tokens, its estimated cost, or
+// both (see budget.Config). Context-cache reads are priced at the table's
+// cache-read rate and tokens written to a newly created context cache at its
+// cache-write rate. As the budget nears, the model is told once to submit its
+// result; once it is exceeded, the run is aborted before its next request
+// with an error that budget.AsExceeded extracts. The spend is saved in the
+// checkpoint envelope, so a resumed run counts against the same budget. Off
+// by default.
+func WithBudget[Request promptbuilder.Bindable, Response any](cfg budget.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.budget = &cfg
+		return nil
+	}
+}
+
+// WithObserver registers an observer for the run's progress events: turn
+// starts, thought and text parts, tool calls starting and completing, and
+// submissions the result validators reject. Responses are not streamed, so
+// each part arrives as a single delta. See the progress package.
+func WithObserver[Request promptbuilder.Bindable, Response any](obs progress.Observer) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		e.observer = obs
+		return nil
+	}
+}
+
+// WithCompaction enables context compaction. Before function responses are
+// sent, if the conversation's estimated size exceeds cfg.Threshold tokens,
+// the oldest responses already in the chat history are replaced with a short
+// placeholder; each keeps its ID and name, so it still pairs with its
+// function call. Each compaction step is recorded on the trace. See the
+// compaction package for the policy. Off by default.
+func WithCompaction[Request promptbuilder.Bindable, Response any](cfg compaction.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.compaction = &cfg
+		return nil
+	}
+}
+
 // WithToolCallConcurrency bounds how many of a single turn's function calls run
 // concurrently when the model emits more than one in a turn (parallel function
 // calling). Defaults to DefaultToolCallConcurrency.
@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor_test
+
+import (
+	"context"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/evals/replay"
+	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// TestReplayToolLoop records a lookup-then-submit loop against the validating
+// server, then reruns it from the fixtures with no server at all: the
+// executor's genai requests must be deterministic enough to match their
+// recordings. It then replays the fixtures committed under testdata/replay,
+// which catches request drift across SDK upgrades; re-record them with
+// DRIFTLESSAF_RECORD=1.
+func TestReplayToolLoop(t *testing.T) {
+	script := func(reqNum int, _ []byte) string {
+		if reqNum == 1 {
+			return lookupTurnJSON
+		}
+		return submitTurnJSON
+	}
+
+	tools := map[string]googletool.Metadata[errCapResponse]{
+		"lookup": {
+			Definition: &genai.FunctionDeclaration{Name: "lookup"},
+			Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse], _ *errCapResponse) *genai.FunctionResponse {
+				return &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: map[string]any{"ok": true}}
+			},
+		},
+	}
+	run := func(tr *replay.Transport, baseURL string) {
+		t.Helper()
+		client, err := genai.NewClient(t.Context(), &genai.ClientConfig{
+			Backend:    genai.BackendGeminiAPI,
+			APIKey:     "test",
+			HTTPClient: tr.Client(),
+			HTTPOptions: genai.HTTPOptions{
+				BaseURL: baseURL,
+			},
+		})
+		if err != nil {
+			t.Fatalf("genai.NewClient: %v", err)
+		}
+		prompt, err := promptbuilder.NewPrompt("hello")
+		if err != nil {
+			t.Fatalf("NewPrompt: %v", err)
+		}
+		exec, err := googleexecutor.New[errCapRequest, errCapResponse](
+			client,
+			prompt,
+			googleexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+			googleexecutor.WithMaxTurns[errCapRequest, errCapResponse](3),
+			googleexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](func() (googletool.SubmitMetadata[errCapResponse], error) {
+				return googletool.SubmitMetadata[errCapResponse]{
+					Definition: &genai.FunctionDeclaration{Name: "submit_result"},
+					Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+						answer, _ := call.Args["answer"].(string)
+						return toolcall.SubmitOutcome[errCapResponse]{
+							Accepted:   true,
+							Response:   errCapResponse{Answer: answer},
+							ToolResult: map[string]any{"success": true},
+						}
+					},
+				}, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		resp, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+		if err != nil {
+			t.Fatalf("Execute (%v): %v", tr.Mode(), err)
+		}
+		if got, want := resp.Answer, "42"; got != want {
+			t.Errorf("resp.Answer (%v): got = %q, want = %q", tr.Mode(), got, want)
+		}
+	}
+
+	srv := newValidatingGenerateContentServer(t, nil, script)
+	dir := t.TempDir()
+	rec, err := replay.New(dir, replay.ModeRecord)
+	if err != nil {
+		t.Fatalf("replay.New(record): %v", err)
+	}
+	run(rec, srv.URL)
+
+	srv.Close()
+	rep, err := replay.New(dir, replay.ModeReplay)
+	if err != nil {
+		t.Fatalf("replay.New(replay): %v", err)
+	}
+	run(rep, "http://replay.invalid")
+	if got := rep.Unmatched(); len(got) != 0 {
+		t.Errorf("Unmatched: %v", got)
+	}
+
+	fixtures := replay.ForTest(t, "testdata/replay")
+	baseURL := "http://replay.invalid"
+	if fixtures.Mode() == replay.ModeRecord {
+		baseURL = newValidatingGenerateContentServer(t, nil, script).URL
+	}
+	run(fixtures, baseURL)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// Resumer is the capability interface for resuming a conversation that
+// previously suspended (see WithSuspendTool). Like claudeexecutor.Resumer it is
+// deliberately NOT part of the exported Interface: a caller that parks and
+// wakes runs type-asserts the Interface returned by New to Resumer, while
+// every other consumer compiles unmodified.
+type Resumer[Request promptbuilder.Bindable, Response any] interface {
+	// Resume rebuilds the paused conversation from env and continues it.
+	// answers maps each pending tool call ID (env.PendingToolCalls[i].ID) to
+	// the human's answer; a missing entry is framed as an explicit
+	// empty-answer placeholder so no function call is left unanswered. It
+	// returns checkpoint.ErrConfigDrift (wrapped) when the live executor
+	// config no longer matches the envelope.
+	Resume(ctx context.Context, env checkpoint.Envelope, answers map[string]string, tools map[string]googletool.Metadata[Response]) (Response, error)
+}
+
+var (
+	_ Interface[promptbuilder.Bindable, any] = (*executor[promptbuilder.Bindable, any])(nil)
+	_ Resumer[promptbuilder.Bindable, any]   = (*executor[promptbuilder.Bindable, any])(nil)
+)
+
+// Resume implements Resumer. It restores the chat history captured at the
+// suspension point, adds one framed function response per pending call to its
+// trailing user content, and drives the shared turn loop for the envelope's
+// remaining turn budget under a new trace linked back to the originating one.
+func (e *executor[Request, Response]) Resume(
+	ctx context.Context,
+	env checkpoint.Envelope,
+	answers map[string]string,
+	tools map[string]googletool.Metadata[Response],
+) (resp Response, err error) {
+	// Fail closed on any drift in provider, model, or the turn-invariant
+	// generation config before the parked state is touched.
+	static, _, serr := e.buildStaticConfig(tools)
+	if serr != nil {
+		return resp, serr
+	}
+	turnBudget, verr := execshared.ValidateResume(env, suspendProviderName, e.model, static, e.maxTurns)
+	if verr != nil {
+		return resp, verr
+	}
+
+	var history []*genai.Content
+	if uerr := json.Unmarshal(env.ProviderState, &history); uerr != nil {
+		return resp, fmt.Errorf("resume: unmarshal provider state: %w", uerr)
+	}
+	if aerr := appendPendingAnswers(history, env.PendingToolCalls, answers); aerr != nil {
+		return resp, aerr
+	}
+
+	trace, done := execshared.StartResumeTrace[Response](ctx, env)
+	defer func() {
+		done(resp, err)
+	}()
+
+	return e.runConversation(ctx, trace, history, tools, env.Turn+1, turnBudget, nil, env.LoopState)
+}
+
+// appendPendingAnswers adds one framed function response per pending call to
+// the trailing user content of history, which already holds the suspended
+// turn's sibling responses and directly follows the model turn that issued the
+// calls. Framing is checkpoint.FramedAnswers' job; this only maps the framed
+// answers into the genai function response shape.
+func appendPendingAnswers(history []*genai.Content, pending []checkpoint.PendingToolCall, answers map[string]string) error {
+	n := len(history)
+	if n < 2 || history[n-1] == nil || history[n-1].Role != "user" || history[n-2] == nil || history[n-2].Role != "model" {
+		return errors.New("resume: provider state does not end with the suspended model turn")
+	}
+	framed, err := checkpoint.FramedAnswers(pending, answers, 0)
+	if err != nil {
+		return err
+	}
+	for _, f := range framed {
+		history[n-1].Parts = append(history[n-1].Parts, &genai.Part{
+			FunctionResponse: &genai.FunctionResponse{
+				ID:       responseCallID(f.ID),
+				Name:     f.Name,
+				Response: map[string]any{"answer": f.Text},
+			},
+		})
+	}
+	return nil
+}
@@ -68,7 +68,7 @@ func TestEvaluateSubmissionCommitsWhenValidatorsPass(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[testResponse](ctx, "prompt")
 
 	var result testResponse
-	toolResult, committed, err := exec.evaluateSubmission(ctx, &genai.FunctionCall{
+	toolResult, committed, err := exec.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{"answer": "done"},
 	}, trace, &result)
 	if err != nil {
@@ -94,7 +94,7 @@ func TestEvaluateSubmissionZeroValueResponseCommits(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[testResponse](ctx, "prompt")
 
 	var result testResponse
-	_, committed, err := exec.evaluateSubmission(ctx, &genai.FunctionCall{
+	_, committed, err := exec.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{"answer": ""},
 	}, trace, &result)
 	if err != nil {
@@ -120,7 +120,7 @@ func TestEvaluateSubmissionRejectionIsNotCommitted(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[testResponse](ctx, "prompt")
 
 	var result testResponse
-	toolResult, committed, err := exec.evaluateSubmission(ctx, &genai.FunctionCall{
+	toolResult, committed, err := exec.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{"answer": "done"},
 	}, trace, &result)
 	if err != nil {
@@ -150,7 +150,7 @@ func TestEvaluateSubmissionValidatorErrorFailsLoud(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[testResponse](ctx, "prompt")
 
 	var result testResponse
-	_, committed, err := exec.evaluateSubmission(ctx, &genai.FunctionCall{
+	_, committed, err := exec.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{"answer": "done"},
 	}, trace, &result)
 	if !errors.Is(err, wantErr) {
@@ -193,7 +193,7 @@ func TestEvaluateSubmissionUnacceptedOutcomePassesThrough(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[testResponse](ctx, "prompt")
 
 	var result testResponse
-	toolResult, committed, err := e.evaluateSubmission(ctx, &genai.FunctionCall{
+	toolResult, committed, err := e.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{},
 	}, trace, &result)
 	if err != nil {
@@ -285,7 +285,7 @@ func TestBaseSchemaValidatorRejectsNonconformingSubmission(t *testing.T) {
 	trace, _ := agenttrace.StartTrace[gradedResponse](ctx, "prompt")
 
 	var result gradedResponse
-	toolResult, committed, err := e.evaluateSubmission(ctx, &genai.FunctionCall{
+	toolResult, committed, err := e.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c1", Name: "submit_result", Args: map[string]any{"answer": "maybe"},
 	}, trace, &result)
 	if err != nil {
@@ -308,7 +308,7 @@ func TestBaseSchemaValidatorRejectsNonconformingSubmission(t *testing.T) {
 		t.Errorf("finding details: got = %q, want mention of allowed values", findings[0].Details)
 	}
 
-	toolResult, committed, err = e.evaluateSubmission(ctx, &genai.FunctionCall{
+	toolResult, committed, err = e.evaluateSubmission(ctx, 0, &genai.FunctionCall{
 		ID: "c2", Name: "submit_result", Args: map[string]any{"answer": "yes"},
 	}, trace, &result)
 	if err != nil {
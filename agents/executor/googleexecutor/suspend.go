@@ -0,0 +1,127 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+// suspendProviderName is the checkpoint.Envelope.Provider value stamped by this
+// executor. It names the executor backend that produced ProviderState (a JSON
+// array of genai.Content), NOT the serving endpoint.
+const suspendProviderName = checkpoint.ProviderGoogle
+
+// syntheticCallIDPrefix marks a pending tool call ID minted by this executor.
+// Vertex frequently omits FunctionCall.ID, but a checkpoint envelope requires
+// every pending call to be identifiable, so an empty ID is replaced with a
+// positional one. Resume maps it back to the empty ID the model issued, so the
+// function response pairs with its call by name exactly as a live turn would.
+const syntheticCallIDPrefix = "gemini-call:"
+
+// SuspendProvider constructs the function declaration for the held-out
+// ask-a-friend suspend tool. It mirrors SubmitResultProvider's shape (a
+// constructor that can fail) so callers wire suspension the same way they wire
+// the terminal submit tool.
+type SuspendProvider func() (*genai.FunctionDeclaration, error)
+
+// WithSuspendTool registers the ask-a-friend suspend tool advertised to the
+// model. When the model calls it, Execute halts after the turn quiesces (every
+// sibling function call has produced its real response) and returns a
+// *checkpoint.Suspension — an error value carrying the full envelope needed to
+// resume later through Resumer — instead of a Response.
+//
+// This is fully opt-in: without WithSuspendTool the suspend tool is never
+// advertised and Execute's behavior is unchanged. The suspend tool name must
+// differ from the terminal submit tool's name (validated at construction) and
+// from every caller-registered tool (validated in Execute, where the run's tool
+// map is known).
+func WithSuspendTool[Request promptbuilder.Bindable, Response any](provider SuspendProvider) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if provider == nil {
+			return errors.New("suspend tool provider cannot be nil")
+		}
+		def, err := provider()
+		if err != nil {
+			return err
+		}
+		if def == nil || def.Name == "" {
+			return errors.New("suspend tool definition must have a name")
+		}
+		e.suspendTool = def
+		return nil
+	}
+}
+
+// suspendToolName returns the configured ask-a-friend suspend tool's name, or ""
+// when WithSuspendTool was not applied. Symmetric with submitToolName.
+func (e *executor[Request, Response]) suspendToolName() string {
+	if e.suspendTool == nil {
+		return ""
+	}
+	return e.suspendTool.Name
+}
+
+// pendingCallID returns the ID persisted for a suspended function call: the
+// model's own ID when it issued one, otherwise a synthetic ID derived from the
+// turn and the call's position within it.
+func pendingCallID(call *genai.FunctionCall, turn, index int) string {
+	if call.ID != "" {
+		return call.ID
+	}
+	return fmt.Sprintf("%s%d:%d", syntheticCallIDPrefix, turn, index)
+}
+
+// responseCallID is the inverse of pendingCallID: the ID a function response
+// to the pending call must carry.
+func responseCallID(id string) string {
+	if strings.HasPrefix(id, syntheticCallIDPrefix) {
+		return ""
+	}
+	return id
+}
+
+// buildSuspension assembles the checkpoint.Suspension returned when the model
+// calls the suspend tool. history is the transcript at the pause point — the
+// model turn carrying the suspend call, followed by a user content holding the
+// siblings' real function responses — captured verbatim as ProviderState. The
+// config digest is taken over buildStaticConfig, which holds everything but
+// the conversation (and neither the cache name nor resource labels), so it is
+// stable across turns and cache rotations. spend is the run's budget tracker,
+// saved so the resumed run continues against the same budget.
+func (e *executor[Request, Response]) buildSuspension(
+	history []*genai.Content,
+	tools map[string]googletool.Metadata[Response],
+	turn int,
+	index int,
+	suspendCall *genai.FunctionCall,
+	spend *budget.Tracker,
+	traceID string,
+) (*checkpoint.Suspension, error) {
+	static, _, err := e.buildStaticConfig(tools)
+	if err != nil {
+		return nil, err
+	}
+	input, err := json.Marshal(suspendCall.Args)
+	if err != nil {
+		return nil, fmt.Errorf("marshal suspend call arguments: %w", err)
+	}
+	return execshared.BuildSuspension(suspendProviderName, e.model, static, history, turn, e.maxTurns,
+		checkpoint.PendingToolCall{
+			ID:        pendingCallID(suspendCall, turn, index),
+			Name:      suspendCall.Name,
+			InputJSON: input,
+		}, spend, traceID)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package googleexecutor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/googletool"
+	"google.golang.org/genai"
+)
+
+const askAFriendToolName = "ask_a_friend"
+
+// askTurnJSON is a generateContent response calling the lookup tool and the
+// suspend tool in one turn. The suspend call carries no id, as Vertex often
+// omits them, so the executor must mint one for the envelope.
+const askTurnJSON = `{
+	"candidates":[{"content":{"role":"model","parts":[
+		{"functionCall":{"id":"call_lookup","name":"lookup","args":{}}},
+		{"functionCall":{"name":"ask_a_friend","args":{"question":"ship it?"}}}
+	]}}],
+	"usageMetadata":{"promptTokenCount":1,"candidatesTokenCount":1,"totalTokenCount":2}
+}`
+
+// newSuspendingExecutor builds an executor with the submit and suspend tools
+// registered against srvURL, and the lookup tool map the scripted turns call.
+func newSuspendingExecutor(t *testing.T, srvURL, model string, maxTurns int) (googleexecutor.Interface[errCapRequest, errCapResponse], map[string]googletool.Metadata[errCapResponse]) {
+	t.Helper()
+	prompt, err := promptbuilder.NewPrompt("hello")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := googleexecutor.New[errCapRequest, errCapResponse](
+		newTestClient(t, srvURL),
+		prompt,
+		googleexecutor.WithModel[errCapRequest, errCapResponse](model),
+		googleexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		googleexecutor.WithMaxTurns[errCapRequest, errCapResponse](maxTurns),
+		googleexecutor.WithoutCacheControl[errCapRequest, errCapResponse](),
+		googleexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](func() (googletool.SubmitMetadata[errCapResponse], error) {
+			return googletool.SubmitMetadata[errCapResponse]{
+				Definition: &genai.FunctionDeclaration{Name: "submit_result"},
+				Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+					answer, _ := call.Args["answer"].(string)
+					return toolcall.SubmitOutcome[errCapResponse]{
+						Accepted:   true,
+						Response:   errCapResponse{Answer: answer},
+						ToolResult: map[string]any{"success": true},
+					}
+				},
+			}, nil
+		}),
+		googleexecutor.WithSuspendTool[errCapRequest, errCapResponse](func() (*genai.FunctionDeclaration, error) {
+			return &genai.FunctionDeclaration{
+				Name:        askAFriendToolName,
+				Description: "Pause and ask a human operator a question.",
+				Parameters: &genai.Schema{
+					Type:       genai.TypeObject,
+					Properties: map[string]*genai.Schema{"question": {Type: genai.TypeString}},
+				},
+			}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tools := map[string]googletool.Metadata[errCapResponse]{
+		"lookup": {
+			Definition: &genai.FunctionDeclaration{Name: "lookup"},
+			Handler: func(_ context.Context, call *genai.FunctionCall, _ *agenttrace.Trace[errCapResponse], _ *errCapResponse) *genai.FunctionResponse {
+				return &genai.FunctionResponse{ID: call.ID, Name: call.Name, Response: map[string]any{"ok": true}}
+			},
+		},
+	}
+	return exec, tools
+}
+
+// TestSuspendReturnsSuspension pins that a turn calling the suspend tool halts
+// the run with a *checkpoint.Suspension whose single pending call carries a
+// synthesized id (the model issued none), while the sibling lookup's real
+// response is captured in the provider state.
+func TestSuspendReturnsSuspension(t *testing.T) {
+	const maxTurns = 7
+	srv := newValidatingGenerateContentServer(t, nil, func(int, []byte) string { return askTurnJSON })
+	exec, tools := newSuspendingExecutor(t, srv.URL, "gemini-2.5-flash", maxTurns)
+
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+	if got, want := len(susp.PendingToolCalls), 1; got != want {
+		t.Fatalf("PendingToolCalls: got %d, want %d", got, want)
+	}
+	if pc := susp.PendingToolCalls[0]; pc.ID == "" || pc.Name != askAFriendToolName {
+		t.Errorf("PendingToolCalls[0]: got (%q, %q), want a non-empty id and name %q", pc.ID, pc.Name, askAFriendToolName)
+	}
+	if got, want := susp.Provider, checkpoint.ProviderGoogle; got != want {
+		t.Errorf("Provider: got %q, want %q", got, want)
+	}
+	if got, want := susp.RemainingTurns, maxTurns-1; got != want {
+		t.Errorf("RemainingTurns: got %d, want %d", got, want)
+	}
+	if !strings.Contains(string(susp.ProviderState), `"functionResponse"`) {
+		t.Errorf("ProviderState does not carry the sibling function response: %s", susp.ProviderState)
+	}
+	if strings.Contains(string(susp.ProviderState), checkpoint.StatusAwaitingAnswer) {
+		t.Errorf("ProviderState answers the pending call with a placeholder: %s", susp.ProviderState)
+	}
+}
+
+// TestResumeRoundTrip parks a run and resumes it on a fresh executor with the
+// same configuration: the resumed request answers the pending call with the
+// framed human answer (the validating server rejects any unpaired call), and
+// the run then completes through the submit tool.
+func TestResumeRoundTrip(t *testing.T) {
+	var resumedBody string
+	srv := newValidatingGenerateContentServer(t, nil, func(reqNum int, body []byte) string {
+		if reqNum == 1 {
+			return askTurnJSON
+		}
+		resumedBody = string(body)
+		return submitTurnJSON
+	})
+
+	exec, tools := newSuspendingExecutor(t, srv.URL, "gemini-2.5-flash", 5)
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+
+	fresh, tools := newSuspendingExecutor(t, srv.URL, "gemini-2.5-flash", 5)
+	resumer, ok := fresh.(googleexecutor.Resumer[errCapRequest, errCapResponse])
+	if !ok {
+		t.Fatal("executor does not implement Resumer")
+	}
+	answers := map[string]string{susp.PendingToolCalls[0].ID: "yes, ship it"}
+	resp, err := resumer.Resume(t.Context(), susp.Envelope, answers, tools)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if got, want := resp.Answer, "42"; got != want {
+		t.Errorf("resp.Answer: got %q, want %q", got, want)
+	}
+	if !strings.Contains(resumedBody, "BEGIN HUMAN ANSWER") || !strings.Contains(resumedBody, "yes, ship it") {
+		t.Errorf("resumed request does not carry the framed answer: %s", resumedBody)
+	}
+}
+
+// TestResumeFailsClosedOnModelDrift pins that an envelope parked under one
+// model is rejected with checkpoint.ErrConfigDrift by an executor configured
+// for another.
+func TestResumeFailsClosedOnModelDrift(t *testing.T) {
+	srv := newValidatingGenerateContentServer(t, nil, func(int, []byte) string { return askTurnJSON })
+
+	exec, tools := newSuspendingExecutor(t, srv.URL, "gemini-2.5-flash", 5)
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+
+	drifted, tools := newSuspendingExecutor(t, srv.URL, "gemini-2.5-pro", 5)
+	_, err = drifted.(googleexecutor.Resumer[errCapRequest, errCapResponse]).Resume(t.Context(), susp.Envelope, nil, tools)
+	if !errors.Is(err, checkpoint.ErrConfigDrift) {
+		t.Errorf("Resume: got %v, want checkpoint.ErrConfigDrift", err)
+	}
+}
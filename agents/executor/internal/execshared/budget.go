@@ -0,0 +1,69 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package execshared
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+)
+
+// LoopState is the Envelope.LoopState the executors write when a run parks:
+// the loop bookkeeping a resumed run continues from. Claude's Message Batch
+// state carries the same budget field, so either shape restores through
+// NewTracker.
+type LoopState struct {
+	// Budget is the run's spend so far; nil when the run has no budget.
+	Budget *budget.State `json:"budget,omitempty"`
+}
+
+// MarshalLoopState captures spend as an Envelope.LoopState. It returns nil
+// when spend is nil, so an envelope from a run without a budget is unchanged.
+func MarshalLoopState(spend *budget.Tracker) (json.RawMessage, error) {
+	if spend == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(LoopState{Budget: BudgetState(spend)})
+	if err != nil {
+		return nil, fmt.Errorf("marshal loop state: %w", err)
+	}
+	return raw, nil
+}
+
+// BudgetState returns spend's state for persisting, or nil when spend is nil.
+func BudgetState(spend *budget.Tracker) *budget.State {
+	if spend == nil {
+		return nil
+	}
+	s := spend.State()
+	return &s
+}
+
+// NewTracker returns the budget tracker for a run of model under cfg, or nil
+// when cfg is nil. loopState is the envelope's LoopState when the run is
+// resumed from a checkpoint (nil for a fresh run); the spend it recorded
+// seeds the tracker so the budget covers the run as a whole.
+func NewTracker(cfg *budget.Config, model string, loopState json.RawMessage) (*budget.Tracker, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	spend, err := cfg.NewTracker(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(loopState) == 0 {
+		return spend, nil
+	}
+	var state LoopState
+	if err := json.Unmarshal(loopState, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal loop state: %w", err)
+	}
+	if state.Budget != nil {
+		spend.Restore(*state.Budget)
+	}
+	return spend, nil
+}
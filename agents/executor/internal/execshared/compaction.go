@@ -0,0 +1,54 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package execshared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"github.com/chainguard-dev/clog"
+)
+
+// Compact runs one context-compaction step ahead of the request for turn.
+// conversation is the provider transcript as it will be sent, marshaled only
+// to estimate its size. sizes holds the byte size of each tool result eligible
+// for elision, oldest first, and elide(i, placeholder) replaces the content of
+// the i-th of them in place — keeping the result block, and so its tool-call
+// pairing and any cache marker, where it is. A step that elides anything is
+// logged and recorded on the trace. It is a no-op when cfg is nil.
+func Compact[T any](ctx context.Context, trace *agenttrace.Trace[T], cfg *compaction.Config, turn int, conversation any, sizes []int, elide func(i int, placeholder string)) error {
+	if cfg == nil {
+		return nil
+	}
+	raw, err := json.Marshal(conversation)
+	if err != nil {
+		return fmt.Errorf("estimate conversation size: %w", err)
+	}
+	plan := cfg.Plan(compaction.EstimateTokens(len(raw)), sizes)
+	if len(plan.Elide) == 0 {
+		return nil
+	}
+	for _, i := range plan.Elide {
+		elide(i, compaction.Placeholder(sizes[i]))
+	}
+
+	clog.InfoContext(ctx, "Compacted conversation",
+		"turn", turn,
+		"elided_tool_results", len(plan.Elide),
+		"estimated_tokens_before", plan.EstimatedTokensBefore,
+		"estimated_tokens_after", plan.EstimatedTokensAfter)
+	trace.RecordCompaction(agenttrace.Compaction{
+		Turn:                  turn,
+		EstimatedTokensBefore: int64(plan.EstimatedTokensBefore),
+		EstimatedTokensAfter:  int64(plan.EstimatedTokensAfter),
+		ElidedToolResults:     len(plan.Elide),
+		ElidedBytes:           int64(plan.ElidedBytes),
+	})
+	return nil
+}
@@ -6,6 +6,9 @@ SPDX-License-Identifier: Apache-2.0
 // Package execshared holds behavior shared by the executor implementations
 // (claudeexecutor, googleexecutor, openaiexecutor) so each backend applies
 // identical semantics: prompt-suffix handling, resource-label defaults, the
-// submit routing predicate, the bounded tool-call dispatch pool, and the
-// submit-gate validation tail.
+// submit routing predicate, the bounded tool-call dispatch pool, the
+// submit-gate validation tail, and the ask-a-friend suspend/resume lifecycle
+// (building, parking, and validating a checkpoint envelope), the
+// context-compaction step, and the model-registry checks on the configured
+// output-token limit and effort.
 package execshared
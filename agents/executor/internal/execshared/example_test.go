@@ -85,7 +85,7 @@ func ExampleGateSubmission() {
 			ToolResult: map[string]any{"success": true},
 		},
 		trace, "call-1", "submit_result", map[string]any{"verdict": "pass"},
-		nil, rec, "submit_result", &result)
+		nil, rec, "submit_result", &result, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -128,3 +128,21 @@ func ExampleFailTurnUnlessSuspended() {
 	// Output:
 	// turn not failed
 }
+
+func ExampleValidateResume() {
+	// static is the executor's turn-invariant request configuration; the same
+	// value is digested at park time and checked again on resume.
+	static := map[string]any{"model": "model", "temperature": 0.1}
+	call := checkpoint.PendingToolCall{ID: "call_1", Name: "ask_a_friend", InputJSON: []byte(`{"question":"ship it?"}`)}
+	susp, err := execshared.BuildSuspension(checkpoint.ProviderOpenAI, "model", static, []string{"transcript"}, 2, 10, call, nil, "")
+	if err != nil {
+		panic(err)
+	}
+
+	// The live configuration lowered maxTurns since the park: the resumed
+	// run's budget is capped at it.
+	budget, err := execshared.ValidateResume(susp.Envelope, checkpoint.ProviderOpenAI, "model", static, 5)
+	fmt.Println(budget, err)
+	// Output:
+	// 5 <nil>
+}
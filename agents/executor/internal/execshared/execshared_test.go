@@ -13,8 +13,11 @@ import (
 	"testing"
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/effort"
 	"chainguard.dev/driftlessaf/agents/executor/internal/telemetry"
 	"chainguard.dev/driftlessaf/agents/metrics"
+	"chainguard.dev/driftlessaf/agents/model"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/toolcall"
 	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
@@ -241,10 +244,14 @@ func TestGateSubmissionRejectionKinds(t *testing.T) {
 			return []callbacks.Finding{{Details: "verdict lacks evidence"}}, nil
 		}
 
-		var result reviewResult
+		var (
+			result   reviewResult
+			rejected []callbacks.Finding
+		)
 		toolResult, committed, err := GateSubmission(ctx, outcome, trace, "call-1", "submit_result",
 			map[string]any{"verdict": "pass"},
-			[]callbacks.ResultValidator[reviewResult]{findingsValidator}, rec, "submit_result", &result)
+			[]callbacks.ResultValidator[reviewResult]{findingsValidator}, rec, "submit_result", &result,
+			func(findings []callbacks.Finding) { rejected = findings })
 		if err != nil {
 			t.Fatalf("GateSubmission() error = %v, want = nil (findings reject, they do not abort)", err)
 		}
@@ -254,6 +261,9 @@ func TestGateSubmissionRejectionKinds(t *testing.T) {
 		if toolResult == nil {
 			t.Errorf("tool result: got = nil, want = rejection payload for the model")
 		}
+		if len(rejected) != 1 {
+			t.Errorf("onRejected findings: got = %d, want = 1", len(rejected))
+		}
 		if len(trace.ToolCalls) != 1 {
 			t.Fatalf("tool calls length: got = %d, want = 1", len(trace.ToolCalls))
 		}
@@ -268,7 +278,7 @@ func TestGateSubmissionRejectionKinds(t *testing.T) {
 
 		var result reviewResult
 		_, committed, err := GateSubmission(ctx, outcome, trace, "call-1", "submit_result",
-			map[string]any{"verdict": "pass"}, nil, rec, "submit_result", &result)
+			map[string]any{"verdict": "pass"}, nil, rec, "submit_result", &result, nil)
 		if err != nil {
 			t.Fatalf("GateSubmission() error = %v, want = nil", err)
 		}
@@ -295,7 +305,7 @@ func TestGateSubmissionRejectionKinds(t *testing.T) {
 		var result reviewResult
 		_, committed, err := GateSubmission(ctx, outcome, trace, "call-1", "submit_result",
 			map[string]any{"verdict": "pass"},
-			[]callbacks.ResultValidator[reviewResult]{errValidator}, rec, "submit_result", &result)
+			[]callbacks.ResultValidator[reviewResult]{errValidator}, rec, "submit_result", &result, nil)
 		if err == nil {
 			t.Fatalf("GateSubmission() error = nil, want = validator error (aborts the run)")
 		}
@@ -310,3 +320,85 @@ func TestGateSubmissionRejectionKinds(t *testing.T) {
 		}
 	})
 }
+
+// TestBuildSuspensionValidateResume pins the park/wake contract the executors
+// share: an envelope built by BuildSuspension validates against the same live
+// configuration, and any drift in provider, model, or the digested static
+// configuration fails closed with checkpoint.ErrConfigDrift.
+// TestValidateModelLimits asserts the output cap applies to every model
+// with a known limit, while effort is only enforced for declared models.
+func TestValidateModelLimits(t *testing.T) {
+	model.Register("test-limits-declared", model.Info{
+		Backend:         model.BackendOpenAICompat,
+		Efforts:         []effort.Level{effort.Low},
+		MaxOutputTokens: 4096,
+	})
+
+	tests := []struct {
+		name      string
+		model     string
+		maxTokens int64
+		level     effort.Level
+		wantErr   bool
+	}{
+		{name: "claude at ceiling", model: "claude-sonnet-4-6", maxTokens: 128000},
+		{name: "claude above ceiling", model: "claude-sonnet-4-6", maxTokens: 128001, wantErr: true},
+		{name: "gemini above ceiling", model: "gemini-2.5-pro", maxTokens: 65537, wantErr: true},
+		{name: "unknown cap", model: "meta/llama-3.3-70b-instruct-maas", maxTokens: 1 << 20},
+		{name: "built-in effort is lenient", model: "claude-sonnet-4-5", maxTokens: 1, level: effort.High},
+		{name: "declared effort", model: "test-limits-declared-v1", maxTokens: 4096, level: effort.Low},
+		{name: "undeclared effort", model: "test-limits-declared-v1", maxTokens: 1, level: effort.High, wantErr: true},
+		{name: "declared cap", model: "test-limits-declared-v1", maxTokens: 4097, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateModelLimits(tt.model, tt.maxTokens, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateModelLimits: got = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildSuspensionValidateResume(t *testing.T) {
+	static := map[string]any{"tools": []string{"read_file"}, "temperature": 0.1}
+	call := checkpoint.PendingToolCall{ID: "call_1", Name: "ask_a_friend", InputJSON: []byte(`{"question":"ship it?"}`)}
+	susp, err := BuildSuspension(checkpoint.ProviderGoogle, "model", static, []string{"transcript"}, 3, 10, call, nil, "trace-1")
+	if err != nil {
+		t.Fatalf("BuildSuspension() = %v", err)
+	}
+	if err := susp.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if got, want := susp.RemainingTurns, 6; got != want {
+		t.Errorf("RemainingTurns: got = %d, want = %d", got, want)
+	}
+	if got, want := string(susp.ProviderState), `["transcript"]`; got != want {
+		t.Errorf("ProviderState: got = %s, want = %s", got, want)
+	}
+
+	budget, err := ValidateResume(susp.Envelope, checkpoint.ProviderGoogle, "model", static, 10)
+	if err != nil {
+		t.Fatalf("ValidateResume() = %v", err)
+	}
+	if budget != susp.RemainingTurns {
+		t.Errorf("ValidateResume() budget: got = %d, want = %d", budget, susp.RemainingTurns)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		provider string
+		model    string
+		static   any
+	}{
+		{name: "provider", provider: checkpoint.ProviderOpenAI, model: "model", static: static},
+		{name: "model", provider: checkpoint.ProviderGoogle, model: "other", static: static},
+		{name: "static config", provider: checkpoint.ProviderGoogle, model: "model", static: map[string]any{"temperature": 0.9}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ValidateResume(susp.Envelope, tc.provider, tc.model, tc.static, 10); !errors.Is(err, checkpoint.ErrConfigDrift) {
+				t.Errorf("ValidateResume() = %v, want checkpoint.ErrConfigDrift", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package execshared
+
+import (
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/model"
+)
+
+// ValidateModelLimits checks an executor's configured output-token limit and
+// effort level against the model registry, once every option is applied and
+// the model is final. maxTokens must not exceed the model's MaxOutputTokens
+// when that is known. An effort level is rejected only for models declared
+// with model.Register or model.Load: a declaration lists what the model
+// accepts, while the built-in rules are a best guess the executors clamp or
+// drop against at request time. An empty level is never checked.
+func ValidateModelLimits(modelName string, maxTokens int64, level effort.Level) error {
+	info := model.Resolve(modelName)
+	if info.MaxOutputTokens > 0 && maxTokens > info.MaxOutputTokens {
+		return fmt.Errorf("max tokens %d exceeds the maximum of %d for model %q", maxTokens, info.MaxOutputTokens, modelName)
+	}
+	if level == "" {
+		return nil
+	}
+	if declared, ok := model.Registered(modelName); ok && !declared.SupportsEffort(level) {
+		return fmt.Errorf("effort %q is not supported by model %q (supported: %v)", level, modelName, declared.Efforts)
+	}
+	return nil
+}
@@ -24,7 +24,8 @@ import (
 // the tool result to send back to the model and whether the response
 // committed as the run's final result (written through resultPtr). A rejected
 // submission returns the validators' findings so the model can address them
-// and submit again; a validator error aborts the run.
+// and submit again, and hands them to onRejected when it is non-nil; a
+// validator error aborts the run.
 func GateSubmission[Response any](
 	ctx context.Context,
 	outcome toolcall.SubmitOutcome[Response],
@@ -35,6 +36,7 @@ func GateSubmission[Response any](
 	rec *telemetry.Recorder,
 	submitToolName string,
 	resultPtr *Response,
+	onRejected func(findings []callbacks.Finding),
 ) (map[string]any, bool, error) {
 	if !outcome.Accepted {
 		// The handler recorded the failed call on the trace; its ToolResult
@@ -56,6 +58,9 @@ func GateSubmission[Response any](
 		clog.InfoContext(ctx, "Submission rejected by result validators", "findings", len(findings))
 		rec.RecordToolCall(ctx, "submit_result_rejected")
 		tc.CompleteRejected(fmt.Errorf("result rejected: validation raised %d finding(s)", len(findings)))
+		if onRejected != nil {
+			onRejected(findings)
+		}
 		return callbacks.RejectionResult(submitToolName, findings), false, nil
 	}
 
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package execshared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/internal/telemetry"
+	"github.com/chainguard-dev/clog"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// BuildSuspension assembles the *checkpoint.Suspension an executor returns
+// when the model calls its held-out ask-a-friend tool. state is the provider
+// transcript as it stands at the pause point — the model turn carrying the
+// suspend call plus the siblings' real results, with the suspend call left
+// unanswered — and is marshaled verbatim as ProviderState. static is the
+// executor's turn-invariant request configuration (tools, system prompt,
+// sampling; never the conversation), digested into ConfigDigest so Resume
+// can detect drift with ValidateResume. call is the suspend call itself, its
+// provider-assigned ID persisted so resume pairs the answer back to it.
+//
+// spend is the run's budget tracker, saved as LoopState (see
+// MarshalLoopState) so Resume continues against the same budget. The parked
+// turn index lives in Envelope.Turn and every executor's Resume re-enters its
+// loop at Turn+1.
+func BuildSuspension(provider, model string, static, state any, turn, maxTurns int, call checkpoint.PendingToolCall, spend *budget.Tracker, traceID string) (*checkpoint.Suspension, error) {
+	providerState, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal provider state: %w", err)
+	}
+	loopState, err := MarshalLoopState(spend)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := checkpoint.DigestJSON(static)
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint.NewAskAFriendSuspension(provider, model, digest, turn, maxTurns, call, providerState, loopState, traceID), nil
+}
+
+// ParkSuspension finalizes a run that is returning susp: the trace is marked
+// suspended (status OK, not Error) so the deferred done callback no-ops via
+// its finalized guard, and the turns consumed so far are recorded as a run
+// that did not exceed its limit.
+func ParkSuspension[T any](ctx context.Context, trace *agenttrace.Trace[T], rec *telemetry.Recorder, susp *checkpoint.Suspension) {
+	kvs := []any{"provider", susp.Provider, "turn", susp.Turn}
+	if len(susp.PendingToolCalls) > 0 {
+		kvs = append(kvs, "tool", susp.PendingToolCalls[0].Name, "tool_call_id", susp.PendingToolCalls[0].ID)
+	}
+	clog.InfoContext(ctx, "Suspending agent execution to await human answer", kvs...)
+	trace.Suspend(susp.Reason)
+	rec.RecordTurns(ctx, susp.Turn+1, false)
+}
+
+// ValidateResume is the fail-closed gate every executor's Resume runs before
+// touching the parked state: it digests the live turn-invariant configuration
+// (the same static value BuildSuspension digested at park time) and checks it,
+// the provider and the model against env with checkpoint.ValidateForResume,
+// surfacing drift as checkpoint.ErrConfigDrift.
+//
+// It returns the turn budget the resumed run may use: the envelope's
+// RemainingTurns capped at the live maxTurns. The turn budget is loop config,
+// not part of the digest, so without the cap an envelope parked under a
+// larger budget (an operator lowering maxTurns between park and wake, or a
+// tampered checkpoint) would resume with more turns than the live
+// configuration allows.
+func ValidateResume(env checkpoint.Envelope, provider, model string, static any, maxTurns int) (int, error) {
+	digest, err := checkpoint.DigestJSON(static)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkpoint.ValidateForResume(env, provider, model, digest, time.Now()); err != nil {
+		return 0, err
+	}
+	return min(env.RemainingTurns, maxTurns), nil
+}
+
+// StartResumeTrace starts the trace for a resumed run. A resume mints a new
+// trace rather than continuing the suspended one; it is linked back to the
+// originating trace (env.TraceID) so the two halves of a paused conversation
+// join downstream. The restored transcript is the real context, so the trace
+// prompt only labels the resume.
+func StartResumeTrace[T any](ctx context.Context, env checkpoint.Envelope) (*agenttrace.Trace[T], func(T, error)) {
+	trace, done := agenttrace.StartTrace[T](ctx, fmt.Sprintf("resume suspended agent (key=%q, run=%q, reason=%q)", env.ReconcilerKey, env.RunID, env.Reason))
+	if env.TraceID != "" {
+		if span := oteltrace.SpanFromContext(trace.Context()); span.SpanContext().IsValid() {
+			span.SetAttributes(attribute.String(agenttrace.AttrResumeLinkedTraceID, env.TraceID))
+		}
+	}
+	clog.InfoContext(ctx, "Resuming suspended agent execution",
+		"provider", env.Provider, "key", env.ReconcilerKey, "run", env.RunID, "turn", env.Turn,
+		"remaining_turns", env.RemainingTurns, "linked_trace", env.TraceID)
+	return trace, done
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// TestBudgetNudgesThenAborts drives a model that never submits against a
+// 1200-token budget at 500 tokens per turn: the third request carries the
+// nudge (1000 tokens spent, past 80%), and the run is aborted with an
+// *budget.ExceededError before a fourth request is sent.
+func TestBudgetNudgesThenAborts(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingOpenAIServer(t, func(reqNum int, body []byte) string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		return fmt.Sprintf(`{
+  "id": "chatcmpl-%d",
+  "object": "chat.completion",
+  "created": %d,
+  "model": "test-model",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "",
+      "tool_calls": [
+        {"id": "call_%d", "type": "function", "function": {"name": "look", "arguments": "{\"reasoning\":\"r\"}"}}
+      ]
+    }
+  }],
+  "usage": {"prompt_tokens": 400, "completion_tokens": 100, "total_tokens": 500}
+}`, reqNum, reqNum, reqNum)
+	})
+
+	client := openai.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	exec, err := openaiexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		openaiexecutor.WithBudget[errCapRequest, errCapResponse](budget.Config{MaxTokens: 1200}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]openaistool.Metadata[errCapResponse]{
+		"look": openaistool.FromTool(toolcall.Tool[errCapResponse]{
+			Def: toolcall.Definition{Name: "look", Description: "Look around."},
+			Handler: func(context.Context, toolcall.ToolCall, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": true}
+			},
+		}),
+	}
+
+	_, err = exec.Execute(t.Context(), errCapRequest{}, tools)
+	exceeded, ok := budget.AsExceeded(err)
+	if !ok {
+		t.Fatalf("Execute: got = %v, want a *budget.ExceededError", err)
+	}
+	if exceeded.Limit != budget.LimitTokens || exceeded.Usage.Total() != 1500 {
+		t.Errorf("ExceededError: got = %+v, want tokens exceeded at 1500", exceeded)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf("requests: got = %d, want = 3", len(bodies))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if got := strings.Count(bodies[i], "nearly out of budget for this task"); got != want {
+			t.Errorf("request %d nudges: got = %d, want = %d", i+1, got, want)
+		}
+	}
+}
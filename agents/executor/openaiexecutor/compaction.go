@@ -0,0 +1,50 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor
+
+import (
+	"context"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"github.com/openai/openai-go"
+)
+
+// compact runs a compaction step over params.Messages ahead of the request
+// for turn. Candidates are the string-content tool messages that precede the
+// last assistant message; the tool messages after it answer that message's
+// calls and have not been seen by the model yet. An elided message is
+// replaced by a tool message for the same tool_call_id.
+func (e *executor[Request, Response]) compact(ctx context.Context, trace *agenttrace.Trace[Response], turn int, params *openai.ChatCompletionNewParams) error {
+	if e.compaction == nil {
+		return nil
+	}
+	lastAssistant := -1
+	for i, msg := range params.Messages {
+		if msg.OfAssistant != nil {
+			lastAssistant = i
+		}
+	}
+	var (
+		indexes []int
+		sizes   []int
+	)
+	for i := 0; i < lastAssistant; i++ {
+		tool := params.Messages[i].OfTool
+		if tool == nil || !tool.Content.OfString.Valid() {
+			continue
+		}
+		if content := tool.Content.OfString.Value; !compaction.IsPlaceholder(content) {
+			indexes = append(indexes, i)
+			sizes = append(sizes, len(content))
+		}
+	}
+	return execshared.Compact(ctx, trace, e.compaction, turn, params.Messages, sizes, func(i int, placeholder string) {
+		idx := indexes[i]
+		params.Messages[idx] = openai.ToolMessage(placeholder, params.Messages[idx].OfTool.ToolCallID)
+	})
+}
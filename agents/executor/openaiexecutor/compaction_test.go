@@ -0,0 +1,138 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+)
+
+// TestCompactionElidesOldToolMessages drives three large tool messages through
+// a tight compaction budget. The validating server checks every request still
+// answers each assistant tool call; the test then checks the final request
+// carried the oldest result as a placeholder and the step landed on the trace.
+func TestCompactionElidesOldToolMessages(t *testing.T) {
+	toolCallTurn := func(n int) string {
+		return fmt.Sprintf(`{
+  "id": "chatcmpl-%d",
+  "object": "chat.completion",
+  "created": %d,
+  "model": "test-model",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "",
+      "tool_calls": [
+        {"id": "call_%d", "type": "function", "function": {"name": "read", "arguments": "{\"reasoning\":\"r\"}"}}
+      ]
+    }
+  }],
+  "usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}
+}`, n, n, n)
+	}
+
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	srv := newValidatingOpenAIServer(t, func(reqNum int, body []byte) string {
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if reqNum <= 3 {
+			return toolCallTurn(reqNum)
+		}
+		return turn2SubmitJSON
+	})
+
+	client := openai.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+
+	submit := func() (openaistool.SubmitMetadata[errCapResponse], error) {
+		return openaistool.SubmitMetadata[errCapResponse]{
+			Definition: openai.ChatCompletionToolParam{
+				Function: shared.FunctionDefinitionParam{Name: "submit_result"},
+			},
+			Handler: func(context.Context, openai.ChatCompletionMessageToolCall, *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+				return toolcall.SubmitOutcome[errCapResponse]{
+					Accepted:   true,
+					Response:   errCapResponse{Answer: "done"},
+					ToolResult: map[string]any{"success": true},
+				}
+			},
+		}, nil
+	}
+	exec, err := openaiexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		openaiexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](submit),
+		openaiexecutor.WithCompaction[errCapRequest, errCapResponse](compaction.Config{Threshold: 6000, KeepRecent: 1}),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	output := strings.Repeat("x", 20_000)
+	tools := map[string]openaistool.Metadata[errCapResponse]{
+		"read": openaistool.FromTool(toolcall.Tool[errCapResponse]{
+			Def: toolcall.Definition{Name: "read", Description: "Read a large file."},
+			Handler: func(context.Context, toolcall.ToolCall, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"content": output}
+			},
+		}),
+	}
+
+	tracer := &recordingTracer{}
+	ctx := agenttrace.WithTracer[errCapResponse](t.Context(), tracer)
+	if _, err := exec.Execute(ctx, errCapRequest{}, tools); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if len(bodies) != 4 {
+		t.Fatalf("requests: got = %d, want = 4", len(bodies))
+	}
+	for i, want := range []int{0, 0, 0, 1} {
+		if got := strings.Count(bodies[i], "[elided tool result:"); got != want {
+			t.Errorf("request %d placeholders: got = %d, want = %d", i+1, got, want)
+		}
+	}
+	if got, want := strings.Count(bodies[3], output), 2; got != want {
+		t.Errorf("request 4 verbatim results: got = %d, want = %d", got, want)
+	}
+
+	if len(tracer.traces) != 1 {
+		t.Fatalf("traces: got = %d, want = 1", len(tracer.traces))
+	}
+	compactions := tracer.traces[0].Compactions
+	if len(compactions) != 1 {
+		t.Fatalf("Compactions: got = %d, want = 1", len(compactions))
+	}
+	if c := compactions[0]; c.Turn != 3 || c.ElidedToolResults != 1 || c.EstimatedTokensAfter >= c.EstimatedTokensBefore {
+		t.Errorf("Compactions[0]: got = %+v, want turn 3 eliding one result", c)
+	}
+}
@@ -4,8 +4,9 @@ SPDX-License-Identifier: Apache-2.0
 */
 
 // Package openaiexecutor provides a multi-turn conversation executor for
-// OpenAI-compatible chat completion APIs, including Vertex AI's partner model
-// endpoint.
+// OpenAI-compatible chat completion APIs: Vertex AI's partner model endpoint,
+// the first-party OpenAI API, and self-hosted servers such as vLLM or
+// llama.cpp.
 //
 // The executor manages the full conversation lifecycle: sending prompts,
 // processing tool calls, recording metrics, and extracting structured results.
@@ -30,7 +31,7 @@ SPDX-License-Identifier: Apache-2.0
 //
 // # Options
 //
-//   - [WithModel]: set the model name (required for Vertex AI partner models)
+//   - [WithModel]: set the model name (required for any model but the default)
 //   - [WithMaxTokens]: set the maximum completion tokens
 //   - [WithTemperature]: set the sampling temperature (0.0–2.0)
 //   - [WithEffort]: set the reasoning effort for reasoning models (xhigh/max clamp to high)
@@ -38,9 +39,37 @@ SPDX-License-Identifier: Apache-2.0
 //   - [WithSystemInstructions]: set the system prompt
 //   - [WithUserPromptSuffix]: append a static prompt to the built user prompt
 //   - [WithSubmitResultProvider]: register the submit_result tool for structured output
+//   - [WithSuspendTool]: register an ask-a-friend tool that parks the run for [Resumer]
 //   - [WithRetryConfig]: configure retry behavior for transient API errors
+//   - [WithCompaction]: elide older tool messages once the conversation outgrows a token budget
+//   - [WithBudget]: cap each run's token usage and estimated cost
+//   - [WithObserver]: receive progress events (turns, text, tool calls) as they happen
 //   - [WithResourceLabels]: set labels for observability attribution
 //
+// # Endpoints
+//
+// The executor talks to whatever server the client points at; only the model
+// name changes per server. For a local vLLM or llama.cpp server:
+//
+//	client := openai.NewClient(
+//		option.WithBaseURL("http://localhost:8000/v1"),
+//		option.WithAPIKey("unused"),
+//	)
+//	exec, err := openaiexecutor.New[MyRequest, MyResponse](client, prompt,
+//		openaiexecutor.WithModel[MyRequest, MyResponse]("qwen2.5-coder-32b"),
+//	)
+//
+// Request parameters follow the model's capabilities in the model package.
+// Temperature is omitted for an OpenAI-compatible model registered without
+// SamplingParams, and reasoning_effort for one registered without Efforts, so
+// a reasoning model is declared once with model.Register rather than
+// configured around at every call site:
+//
+//	model.Register("o4-mini", model.Info{
+//		Backend: model.BackendOpenAICompat,
+//		Efforts: []effort.Level{effort.Low, effort.Medium, effort.High},
+//	})
+//
 // # Thinking Models
 //
 // Models that return reasoning_content in their responses (e.g. kimi-k2-thinking-maas)
@@ -11,6 +11,8 @@ import (
 	"testing"
 
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/model"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/packages/param"
 	"github.com/openai/openai-go/shared"
@@ -82,3 +84,96 @@ func TestReasoningEffortRequestAssembly(t *testing.T) {
 		t.Errorf("request with effort set does not carry reasoning_effort=high: %s", set)
 	}
 }
+
+// TestCapabilityGatedParams checks the static request follows the model's
+// declared capabilities: temperature is sent to a default "publisher/model"
+// id and to unregistered ids but dropped for OpenAI-compatible models
+// registered without it, and reasoning_effort is sent when configured. A
+// model registered without efforts is configured without one, since New
+// rejects an effort it does not declare (see TestDeclaredEffortRejected).
+func TestCapabilityGatedParams(t *testing.T) {
+	model.Register("test-gated-reasoner", model.Info{
+		Backend: model.BackendOpenAICompat,
+		Efforts: []effort.Level{effort.Low, effort.Medium, effort.High},
+	})
+	model.Register("test-gated-chat", model.Info{
+		Backend:        model.BackendOpenAICompat,
+		SamplingParams: true,
+	})
+
+	tests := []struct {
+		model           string
+		wantTemperature bool
+		wantEffort      bool
+	}{
+		{model: "google/gemini-2.5-flash", wantTemperature: true, wantEffort: true},
+		{model: "test-unregistered-model", wantTemperature: true, wantEffort: true},
+		{model: "test-gated-reasoner-v1", wantEffort: true},
+		{model: "test-gated-chat-7b", wantTemperature: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			prompt, err := promptbuilder.NewPrompt("p")
+			if err != nil {
+				t.Fatalf("NewPrompt: %v", err)
+			}
+			opts := []Option[*testRequest, testResponse]{WithModel[*testRequest, testResponse](tt.model)}
+			if tt.wantEffort {
+				opts = append(opts, WithEffort[*testRequest, testResponse](effort.Medium))
+			}
+			iface, err := New[*testRequest, testResponse](openai.NewClient(), prompt, opts...)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			params, err := iface.(*executor[*testRequest, testResponse]).buildStaticParams(nil)
+			if err != nil {
+				t.Fatalf("buildStaticParams: %v", err)
+			}
+			raw, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if got := strings.Contains(string(raw), `"temperature"`); got != tt.wantTemperature {
+				t.Errorf("temperature sent: got = %v, want = %v (%s)", got, tt.wantTemperature, raw)
+			}
+			if got := strings.Contains(string(raw), `"reasoning_effort":"medium"`); got != tt.wantEffort {
+				t.Errorf("reasoning_effort sent: got = %v, want = %v (%s)", got, tt.wantEffort, raw)
+			}
+		})
+	}
+}
+
+// TestDeclaredEffortRejected checks New rejects an effort level or output
+// limit a registered model does not declare, rather than sending a request
+// the server would refuse.
+func TestDeclaredEffortRejected(t *testing.T) {
+	model.Register("test-declared-low", model.Info{
+		Backend:         model.BackendOpenAICompat,
+		Efforts:         []effort.Level{effort.Low},
+		MaxOutputTokens: 16384,
+	})
+	prompt, err := promptbuilder.NewPrompt("p")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option[*testRequest, testResponse]
+		wantErr bool
+	}{
+		{name: "declared effort", opts: []Option[*testRequest, testResponse]{WithEffort[*testRequest, testResponse](effort.Low)}},
+		{name: "undeclared effort", opts: []Option[*testRequest, testResponse]{WithEffort[*testRequest, testResponse](effort.High)}, wantErr: true},
+		{name: "within output limit", opts: []Option[*testRequest, testResponse]{WithMaxTokens[*testRequest, testResponse](16384)}},
+		{name: "above output limit", opts: []Option[*testRequest, testResponse]{WithMaxTokens[*testRequest, testResponse](16385)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option[*testRequest, testResponse]{WithModel[*testRequest, testResponse]("test-declared-low-8b")}, tt.opts...)
+			_, err := New[*testRequest, testResponse](openai.NewClient(), prompt, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New: got = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
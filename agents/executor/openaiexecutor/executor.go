@@ -6,6 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 package openaiexecutor
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -14,10 +15,16 @@ import (
 	"slices"
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
 	"chainguard.dev/driftlessaf/agents/executor/internal/telemetry"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/metrics"
+	"chainguard.dev/driftlessaf/agents/model"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/result"
 	"chainguard.dev/driftlessaf/agents/schema"
@@ -62,6 +69,7 @@ type executor[Request promptbuilder.Bindable, Response any] struct {
 	temperature     float64
 	reasoningEffort shared.ReasoningEffort // "" = unset; omitted from requests
 	submitTool      openaistool.SubmitMetadata[Response]
+	suspendTool     *openai.ChatCompletionToolParam // opt-in: set via WithSuspendTool; nil disables suspension entirely
 	telemetry       *telemetry.Recorder
 	retryConfig     retry.RetryConfig
 	resourceLabels  map[string]string
@@ -84,6 +92,19 @@ type executor[Request promptbuilder.Bindable, Response any] struct {
 	// tool handlers are themselves safe for concurrent use (they share the
 	// trace, which is safe). Set via WithToolCallConcurrency.
 	toolCallConcurrency int
+
+	// compaction, when non-nil, elides older tool messages once the
+	// conversation's estimated size crosses the configured threshold. Nil
+	// (the default) sends every tool message verbatim. Set via WithCompaction.
+	compaction *compaction.Config
+
+	// budget, when non-nil, caps each run's token usage and estimated cost.
+	// Nil (the default) leaves spend uncapped. Set via WithBudget.
+	budget *budget.Config
+
+	// observer receives the run's progress events as they happen. Nil (the
+	// default) discards them. Set via WithObserver.
+	observer progress.Observer
 }
 
 // New creates a new OpenAI-compatible executor.
@@ -118,6 +139,22 @@ func New[Request promptbuilder.Bindable, Response any](
 		}
 	}
 
+	// reasoningEffort is already clamped onto low/medium/high, so that is the
+	// level a declared model must list.
+	if err := execshared.ValidateModelLimits(e.modelName, e.maxTokens, effort.Level(e.reasoningEffort)); err != nil {
+		return nil, err
+	}
+
+	// A suspend tool must not share the terminal submit tool's name. Checked
+	// after all options are applied so the order they were supplied in is
+	// irrelevant; the complementary caller-tool collision is checked in
+	// Execute, where the run's tool map is known. See suspend.go.
+	if e.suspendTool != nil {
+		if err := execshared.ValidateSuspendToolName(e.suspendToolName(), e.submitToolName()); err != nil {
+			return nil, err
+		}
+	}
+
 	// The recorder is built after options so it captures the final model and
 	// resource labels. codeFromError is nil because this executor does not
 	// record genai.api.requests: it has no error→code mapping yet, and wiring
@@ -160,13 +197,30 @@ func (e *executor[Request, Response]) Execute(
 		"model", e.modelName,
 		"prompt_length", len(prompt),
 	)
+	if e.reasoningEffort != "" && effortForModel(e.modelName, e.reasoningEffort) == "" {
+		clog.WarnContext(ctx, "Model does not take reasoning_effort, dropping the configured effort",
+			"model", e.modelName, "effort", e.reasoningEffort)
+	}
 
-	// submitToolName is the configured terminal tool the model calls to
-	// return its result. Empty when no submit tool is registered.
-	submitToolName := e.submitToolName()
+	reqParams, err := e.buildStaticParams(tools)
+	if err != nil {
+		return response, err
+	}
+	reqParams.Messages = append(reqParams.Messages, openai.UserMessage(prompt))
 
-	// Build tool definitions.
-	toolDefs := make([]openai.ChatCompletionToolParam, 0, len(tools)+1)
+	// A fresh Execute runs the full turn budget starting at turn 0. Resume
+	// (resume.go) shares the same loop with restored params, a startTurn past
+	// the suspension point, and the envelope's remaining budget.
+	return e.runConversation(ctx, trace, reqParams, tools, 0, e.maxTurns, nil)
+}
+
+// buildStaticParams assembles the turn-invariant part of the request: the
+// tool definitions (sorted by name so the request, and the config digest
+// taken over it, are stable across calls), the system prompt, and the
+// sampling parameters. The conversation is appended by the caller: Execute
+// adds the user prompt, Resume restores the parked transcript.
+func (e *executor[Request, Response]) buildStaticParams(tools map[string]openaistool.Metadata[Response]) (openai.ChatCompletionNewParams, error) {
+	toolDefs := make([]openai.ChatCompletionToolParam, 0, len(tools)+2)
 	for _, meta := range tools {
 		toolDefs = append(toolDefs, meta.Definition)
 	}
@@ -174,75 +228,131 @@ func (e *executor[Request, Response]) Execute(
 	// outside the tools map — dispatch routes it through evaluateSubmission —
 	// but the model discovers it the same way. A caller-registered tool with
 	// the same name takes precedence, matching dispatch.
-	if submitToolName != "" {
-		if _, exists := tools[submitToolName]; !exists {
+	if name := e.submitToolName(); name != "" {
+		if _, exists := tools[name]; !exists {
 			toolDefs = append(toolDefs, e.submitTool.Definition)
 		}
 	}
-
-	// Build initial messages.
-	messages := []openai.ChatCompletionMessageParamUnion{
-		openai.UserMessage(prompt),
+	// Advertise the held-out ask-a-friend suspend tool the same way. A
+	// caller-registered tool of the same name is rejected in runConversation,
+	// so the no-collision case is all that reaches the model.
+	if name := e.suspendToolName(); name != "" {
+		if _, exists := tools[name]; !exists {
+			toolDefs = append(toolDefs, *e.suspendTool)
+		}
 	}
+	slices.SortFunc(toolDefs, func(a, b openai.ChatCompletionToolParam) int {
+		return cmp.Compare(a.Function.Name, b.Function.Name)
+	})
 
+	var messages []openai.ChatCompletionMessageParamUnion
 	if e.systemInstructions != nil {
 		systemPrompt, err := e.systemInstructions.Build()
 		if err != nil {
-			return response, fmt.Errorf("building system prompt: %w", err)
+			return openai.ChatCompletionNewParams{}, fmt.Errorf("building system prompt: %w", err)
 		}
-		messages = append([]openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(systemPrompt),
-		}, messages...)
+		messages = append(messages, openai.SystemMessage(systemPrompt))
 	}
 
-	reqParams := openai.ChatCompletionNewParams{
+	params := openai.ChatCompletionNewParams{
 		Model:               e.modelName,
 		Messages:            messages,
 		Tools:               toolDefs,
 		MaxCompletionTokens: param.NewOpt(e.maxTokens),
-		Temperature:         param.NewOpt(e.temperature),
 		// The zero value is omitted from the request (omitzero), so this only
-		// takes effect when WithEffort configured it.
-		ReasoningEffort: e.reasoningEffort,
+		// takes effect when WithEffort configured it and the model takes it.
+		ReasoningEffort: effortForModel(e.modelName, e.reasoningEffort),
 	}
+	// Reasoning models (first-party o-series and GPT-5, many local reasoning
+	// models) reject a non-default temperature; model.Resolve says which.
+	if supportsSamplingParams(e.modelName) {
+		params.Temperature = param.NewOpt(e.temperature)
+	}
+	return params, nil
+}
 
-	// isSubmit reports whether a call routes to the terminal submit tool. It
-	// is the routing predicate consulted by executeToolCall's dispatch switch.
-	isSubmit := execshared.SubmitPredicate(tools, submitToolName, e.submitTool.Handler != nil)
-
-	// heldOutTools is the set of tool names dispatched sequentially after the
-	// concurrent tool pool drains, rather than within it (see
-	// execshared.DispatchToolCalls). A held-out call's work runs only once
-	// every sibling handler has finished and its real tool_result is in the
-	// transcript. Today the only member is the terminal submit tool — its
-	// result validators may read state the sibling handlers produce
-	// (worktrees, files), so they must observe the finished state rather than
-	// race the handlers still producing it — and only when the call actually
-	// routes to submit (a caller tool of the same name shadows it and is
-	// dispatched in the pool, so it is not held out). Membership is derived
-	// from isSubmit so the two predicates cannot drift. Building the partition
-	// as a set is the seam DEV-2247 widens: the suspend tool joins this set so
-	// it too quiesces behind its siblings' real tool_results.
-	heldOutTools := make(map[string]struct{}, 1)
-	if isSubmit(submitToolName) {
-		heldOutTools[submitToolName] = struct{}{}
+// supportsSamplingParams reports whether the model accepts the temperature
+// parameter. Only ids resolving to the OpenAI-compatible backend are gated —
+// by default every "publisher/model" id accepts it, and model.Register
+// declares the ones that do not. Ids of any other shape keep the parameter,
+// as they always have.
+func supportsSamplingParams(modelName string) bool {
+	info := model.Resolve(modelName)
+	return info.Backend != model.BackendOpenAICompat || info.SamplingParams
+}
+
+// effortForModel drops a configured reasoning_effort for a model that
+// model.Resolve reports as taking no effort parameter (an OpenAI-compatible
+// id declared with an empty Efforts set) and passes it through otherwise.
+// The shared scale was already clamped onto low/medium/high by WithEffort.
+func effortForModel(modelName string, level shared.ReasoningEffort) shared.ReasoningEffort {
+	info := model.Resolve(modelName)
+	if info.Backend == model.BackendOpenAICompat && len(info.Efforts) == 0 {
+		return ""
 	}
-	heldOut := func(name string) bool {
-		_, ok := heldOutTools[name]
-		return ok
+	return level
+}
+
+// runConversation drives the bounded turn loop shared by Execute (a fresh run,
+// startTurn 0) and Resume (a restored run, startTurn env.Turn+1). reqParams is
+// the fully assembled request; turnBudget bounds how many turns may run before
+// aborting — for a resume it is the envelope's remaining budget, so the
+// overall turn budget is enforced across pauses. loopState is the envelope's
+// LoopState for a resumed run (nil for a fresh one); the budget spend it
+// carries seeds the run's tracker. trace is already started by the caller.
+func (e *executor[Request, Response]) runConversation(
+	ctx context.Context,
+	trace *agenttrace.Trace[Response],
+	reqParams openai.ChatCompletionNewParams,
+	tools map[string]openaistool.Metadata[Response],
+	startTurn int,
+	turnBudget int,
+	loopState json.RawMessage,
+) (response Response, err error) {
+	// Observers learn the call is over however it returns, so one that
+	// publishes asynchronously can flush before the caller moves on.
+	defer e.observer.Emit(ctx, progress.Event{Kind: progress.RunCompleted})
+
+	// submitToolName is the configured terminal tool the model calls to
+	// return its result. Empty when no submit tool is registered.
+	submitToolName := e.submitToolName()
+
+	// suspendToolName is the configured ask-a-friend suspend tool. Empty when
+	// WithSuspendTool was not applied, in which case isSuspend is always false
+	// and every suspend-specific branch below is dead.
+	suspendToolName := e.suspendToolName()
+
+	// Partition the tool set into the routing predicates the dispatch loop
+	// consults: isSubmit routes the terminal submit tool, isSuspend the
+	// ask-a-friend suspend tool, and heldOut is their union — dispatched only
+	// after the concurrent pool drains so a held-out call observes every
+	// sibling's real result (see execshared.HeldOutPartition).
+	isSubmit, isSuspend, heldOut, err := execshared.HeldOutPartition(tools, submitToolName, e.submitTool.Handler != nil, suspendToolName, e.suspendTool != nil)
+	if err != nil {
+		return response, err
 	}
 
 	// availableTools names every tool the model can call, for the unknown-tool
 	// result. The tool set is fixed for the run, so it is built once here
-	// rather than per bad call. isSubmit gates the held-out submit name, so a
-	// name that no option configured, or that a caller-registered tool
-	// shadows, is not listed as its own entry.
-	heldOutNames := make([]string, 0, 1)
+	// rather than per bad call. isSubmit and isSuspend gate the two held-out
+	// names, so a name that no option configured, or that a caller-registered
+	// tool shadows, is not listed as its own entry.
+	heldOutNames := make([]string, 0, 2)
 	if isSubmit(submitToolName) {
 		heldOutNames = append(heldOutNames, submitToolName)
 	}
+	if isSuspend(suspendToolName) {
+		heldOutNames = append(heldOutNames, suspendToolName)
+	}
 	availableTools := availableToolNames(tools, heldOutNames...)
 
+	// spend tracks the run's usage against the optional budget; nil when
+	// WithBudget was not applied, which makes every call on it a no-op.
+	spend, err := execshared.NewTracker(e.budget, e.modelName, loopState)
+	if err != nil {
+		return response, err
+	}
+
 	// executeToolCall runs a single tool call and returns its serialized result.
 	// The handler writes any terminal result into resultPtr; each tool call in a
 	// turn gets its own slot so concurrent handlers never race on the same
@@ -250,7 +360,7 @@ func (e *executor[Request, Response]) Execute(
 	// accepted the call and the registered result validators passed, so
 	// resultPtr holds the run's final result — even when that result is the
 	// zero value.
-	executeToolCall := func(tc openai.ChatCompletionMessageToolCall, resultPtr *Response) (string, bool, error) {
+	executeToolCall := func(turn int, tc openai.ChatCompletionMessageToolCall, resultPtr *Response) (string, bool, error) {
 		kvs := []any{"tool", tc.Function.Name, "id", tc.ID}
 		var args map[string]any
 		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err == nil {
@@ -259,6 +369,7 @@ func (e *executor[Request, Response]) Execute(
 			}
 		}
 		clog.InfoContext(ctx, "Executing tool call", kvs...)
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallStarted, Turn: turn, ToolCallID: tc.ID, ToolName: tc.Function.Name})
 
 		var res map[string]any
 		committed := false
@@ -279,10 +390,17 @@ func (e *executor[Request, Response]) Execute(
 			// them and submit again — the loop continues.
 			e.telemetry.RecordToolCall(ctx, tc.Function.Name)
 			var err error
-			res, committed, err = e.evaluateSubmission(ctx, tc, args, trace, resultPtr)
+			res, committed, err = e.evaluateSubmission(ctx, turn, tc, args, trace, resultPtr)
 			if err != nil {
 				return "", false, err
 			}
+		case isSuspend(tc.Function.Name):
+			// Held-out ask-a-friend tool. Its real result is the human's
+			// answer, supplied on resume — not here. The placeholder keeps the
+			// transcript paired in the submit-wins case; on the suspension path
+			// it is dropped so the answer can take its slot.
+			e.telemetry.RecordToolCall(ctx, tc.Function.Name)
+			res = map[string]any{"status": checkpoint.StatusAwaitingAnswer}
 		default:
 			clog.ErrorContext(ctx, "Unknown tool requested", "tool", tc.Function.Name)
 			trace.BadToolCall(tc.ID, tc.Function.Name,
@@ -294,6 +412,8 @@ func (e *executor[Request, Response]) Execute(
 			}
 		}
 
+		e.observer.Emit(ctx, progress.Event{Kind: progress.ToolCallCompleted, Turn: turn, ToolCallID: tc.ID, ToolName: tc.Function.Name, Error: progress.ToolError(res)})
+
 		resBytes, err := json.Marshal(res)
 		if err != nil {
 			return "", false, fmt.Errorf("failed to marshal tool result: %w", err)
@@ -308,11 +428,12 @@ func (e *executor[Request, Response]) Execute(
 	executeTurn := func(turn int) (_ Response, _ bool, err error) {
 		llmTurn := trace.BeginTurn(turn, agenttrace.SystemOpenAI, e.modelName)
 		defer func() {
-			if err != nil {
-				llmTurn.Fail(err)
-			}
+			// A suspension is an intentional halt, not a failure.
+			execshared.FailTurnUnlessSuspended(llmTurn, err)
 			llmTurn.End()
+			e.observer.Emit(ctx, progress.Event{Kind: progress.TurnCompleted, Turn: turn})
 		}()
+		e.observer.Emit(ctx, progress.Event{Kind: progress.TurnStarted, Turn: turn})
 
 		// Per-turn retry config wires transient API errors that the retry
 		// recovers from into the turn's Errors list. Without this, retries
@@ -320,6 +441,25 @@ func (e *executor[Request, Response]) Execute(
 		turnCfg := e.retryConfig
 		turnCfg.OnAttemptError = llmTurn.RecordError
 
+		// Enforce the spend budget before paying for another request, and
+		// ask the model once to wrap up as the budget nears.
+		if err := spend.Err(); err != nil {
+			clog.ErrorContext(ctx, "Agent exceeded its budget", "error", err)
+			return response, true, err
+		}
+		if spend.Nudge() {
+			e.telemetry.RecordToolCall(ctx, "budget_nudge")
+			clog.InfoContext(ctx, "Budget nearly spent, nudging model to emit result",
+				"tokens", spend.Usage().Total(), "cost_usd", spend.CostUSD())
+			reqParams.Messages = append(reqParams.Messages, openai.UserMessage(budget.NudgeMessage(submitToolName)))
+		}
+
+		// Elide older tool messages if the conversation has outgrown its
+		// compaction budget, before the request is captured and sent.
+		if err := e.compact(ctx, trace, turn, &reqParams); err != nil {
+			return response, true, err
+		}
+
 		// Capture the cumulative prompt as sent. reqParams.Messages grows
 		// across turns (assistant + tool result messages are appended in
 		// place), so each row carries the full context the model saw.
@@ -342,6 +482,14 @@ func (e *executor[Request, Response]) Execute(
 			e.telemetry.RecordTokens(ctx, completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
 			llmTurn.RecordTokens(completion.Usage.PromptTokens, completion.Usage.CompletionTokens)
 		}
+		// prompt_tokens includes the cached prefix; the budget prices it
+		// separately.
+		cached := completion.Usage.PromptTokensDetails.CachedTokens
+		spend.Add(budget.Usage{
+			InputTokens:     completion.Usage.PromptTokens - cached,
+			OutputTokens:    completion.Usage.CompletionTokens,
+			CacheReadTokens: cached,
+		})
 
 		if len(completion.Choices) == 0 {
 			return response, true, errors.New("no choices in completion response")
@@ -367,8 +515,13 @@ func (e *executor[Request, Response]) Execute(
 				trace.AppendReasoning(agenttrace.ReasoningContent{
 					Thinking: thinking,
 				})
+				e.observer.Emit(ctx, progress.Event{Kind: progress.ThinkingDelta, Turn: turn, Text: thinking})
 			}
 		}
+		// Completions are not streamed, so the turn's text is one delta.
+		if choice.Message.Content != "" {
+			e.observer.Emit(ctx, progress.Event{Kind: progress.TextDelta, Turn: turn, Text: choice.Message.Content})
+		}
 
 		// Handle tool calls.
 		if len(choice.Message.ToolCalls) > 0 {
@@ -378,7 +531,7 @@ func (e *executor[Request, Response]) Execute(
 			// Dispatch the turn's tool calls under a bounded pool, collecting all
 			// results before checking for a final result so the conversation
 			// history stays consistent (every tool result message is appended).
-			// Submit calls are held out until the pool drains — see
+			// Submit and suspend calls are held out until the pool drains — see
 			// execshared.DispatchToolCalls for the concurrency and
 			// submit-quiesce semantics. Each handler writes into its own result
 			// slot so the shared finalResultPtr is never raced, and the tool
@@ -396,7 +549,7 @@ func (e *executor[Request, Response]) Execute(
 			execshared.DispatchToolCalls(toolCalls, e.toolCallConcurrency,
 				func(tc openai.ChatCompletionMessageToolCall) bool { return heldOut(tc.Function.Name) },
 				func(i int, tc openai.ChatCompletionMessageToolCall) {
-					resJSON, committed, cerr := executeToolCall(tc, &perCallResults[i])
+					resJSON, committed, cerr := executeToolCall(turn, tc, &perCallResults[i])
 					if cerr != nil {
 						outcomes[i] = toolOutcome{err: cerr}
 						return
@@ -404,10 +557,21 @@ func (e *executor[Request, Response]) Execute(
 					outcomes[i] = toolOutcome{msg: openai.ToolMessage(resJSON, tc.ID), committed: committed}
 				})
 
+			// suspendIdx records the slot of the held-out suspend call, if the
+			// model issued one this turn. Its tool message is left out of the
+			// transcript so the human answer takes the pending slot on resume;
+			// the suspension itself is deferred until the whole turn is
+			// consumed, because a sibling terminal result makes the pending
+			// question moot.
+			suspendIdx := -1
 			for i := range toolCalls {
 				if outcomes[i].err != nil {
 					return response, true, outcomes[i].err
 				}
+				if isSuspend(toolCalls[i].Function.Name) {
+					suspendIdx = i
+					continue
+				}
 				reqParams.Messages = append(reqParams.Messages, outcomes[i].msg)
 			}
 			for i := range toolCalls {
@@ -423,6 +587,19 @@ func (e *executor[Request, Response]) Execute(
 					return perCallResults[i], true, nil
 				}
 			}
+
+			// No terminal result won the turn: if the model asked to pause,
+			// park the conversation now. Every sibling handler has finished
+			// and its real tool message is in the transcript, so the
+			// envelope's only pending call is the suspend call.
+			if suspendIdx >= 0 {
+				susp, berr := e.buildSuspension(reqParams, tools, turn, toolCalls[suspendIdx], spend, trace.ID)
+				if berr != nil {
+					return response, true, berr
+				}
+				execshared.ParkSuspension(ctx, trace, e.telemetry, susp)
+				return response, true, susp
+			}
 			return response, false, nil
 		}
 
@@ -460,8 +637,11 @@ func (e *executor[Request, Response]) Execute(
 		return response, true, errors.New("no content in completion response")
 	}
 
-	for turn := range e.maxTurns {
-		resp, done, err := executeTurn(turn)
+	// turn is offset by startTurn so a resumed run's trace turns continue past
+	// the suspension point; the loop runs at most turnBudget iterations so the
+	// overall turn budget is honored across pauses.
+	for i := range turnBudget {
+		resp, done, err := executeTurn(startTurn + i)
 		// done=true on all terminal paths (including errors); || err != nil is a
 		// safety net in case a future path sets err without setting done.
 		if done || err != nil {
@@ -469,9 +649,9 @@ func (e *executor[Request, Response]) Execute(
 		}
 	}
 
-	clog.ErrorContext(ctx, "Agent exceeded maximum conversation turns", "max_turns", e.maxTurns)
-	e.telemetry.RecordTurns(ctx, e.maxTurns, true)
-	return response, fmt.Errorf("agent exceeded maximum conversation turns (%d)", e.maxTurns)
+	clog.ErrorContext(ctx, "Agent exceeded maximum conversation turns", "max_turns", turnBudget)
+	e.telemetry.RecordTurns(ctx, startTurn+turnBudget, true)
+	return response, fmt.Errorf("agent exceeded maximum conversation turns (%d)", turnBudget)
 }
 
 // submitToolName returns the configured terminal submit tool's name, or ""
@@ -491,9 +671,11 @@ func (e *executor[Request, Response]) submitToolName() string {
 // and gates its accepted response on the registered result validators via
 // execshared.GateSubmission (see there for the gate semantics). It returns
 // the tool result to send back to the model and whether the response
-// committed as the run's final result (written through resultPtr).
+// committed as the run's final result (written through resultPtr). A
+// validator rejection is reported to the observer against turn.
 func (e *executor[Request, Response]) evaluateSubmission(
 	ctx context.Context,
+	turn int,
 	tc openai.ChatCompletionMessageToolCall,
 	args map[string]any,
 	trace *agenttrace.Trace[Response],
@@ -501,12 +683,15 @@ func (e *executor[Request, Response]) evaluateSubmission(
 ) (map[string]any, bool, error) {
 	return execshared.GateSubmission(ctx, e.submitTool.Handler(ctx, tc, trace),
 		trace, tc.ID, tc.Function.Name, args,
-		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr)
+		e.resultValidators, e.telemetry, e.submitToolName(), resultPtr,
+		func(findings []callbacks.Finding) {
+			e.observer.Emit(ctx, progress.Event{Kind: progress.SubmitRejected, Turn: turn, ToolCallID: tc.ID, ToolName: tc.Function.Name, Findings: findings})
+		})
 }
 
 // availableToolNames returns the sorted names of every tool the model can
 // call: the registered tools, plus the heldOut names that dispatch outside
-// the tool map (the terminal submit tool). Callers pass a held-out name only
+// the tool map (the terminal submit tool, the suspend tool). Callers pass a held-out name only
 // while it routes, so a shadowed or unconfigured name never reaches the list.
 // The unknown-tool result carries the list, so a model that misspells or
 // invents a tool name can correct the call.
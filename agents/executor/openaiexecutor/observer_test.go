@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+)
+
+// TestObserverReceivesRunEvents drives a tool call that fails, a submission
+// the validator rejects and one it accepts, and checks the observer saw each
+// step in order with the turn it happened on.
+func TestObserverReceivesRunEvents(t *testing.T) {
+	srv := newValidatingOpenAIServer(t, func(reqNum int, _ []byte) string {
+		if reqNum == 1 {
+			return `{
+  "id": "chatcmpl-1",
+  "object": "chat.completion",
+  "created": 1,
+  "model": "test-model",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "Looking first.",
+      "tool_calls": [
+        {"id": "call_look", "type": "function", "function": {"name": "look", "arguments": "{\"reasoning\":\"r\"}"}}
+      ]
+    }
+  }],
+  "usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}
+}`
+		}
+		return turn2SubmitJSON
+	})
+
+	client := openai.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+
+	submit := func() (openaistool.SubmitMetadata[errCapResponse], error) {
+		return openaistool.SubmitMetadata[errCapResponse]{
+			Definition: openai.ChatCompletionToolParam{
+				Function: shared.FunctionDefinitionParam{Name: "submit_result"},
+			},
+			Handler: func(context.Context, openai.ChatCompletionMessageToolCall, *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+				return toolcall.SubmitOutcome[errCapResponse]{
+					Accepted:   true,
+					Response:   errCapResponse{Answer: "done"},
+					ToolResult: map[string]any{"success": true},
+				}
+			},
+		}, nil
+	}
+	var validations int
+	validator := func(context.Context, errCapResponse, string) ([]callbacks.Finding, error) {
+		validations++
+		if validations == 1 {
+			return []callbacks.Finding{{Details: "missing evidence"}}, nil
+		}
+		return nil, nil
+	}
+
+	var (
+		mu     sync.Mutex
+		events []progress.Event
+	)
+	observer := func(_ context.Context, ev progress.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	}
+
+	exec, err := openaiexecutor.New[errCapRequest, errCapResponse](
+		client,
+		prompt,
+		openaiexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](submit),
+		openaiexecutor.WithResultValidator[errCapRequest, errCapResponse](validator),
+		openaiexecutor.WithObserver[errCapRequest, errCapResponse](observer),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tools := map[string]openaistool.Metadata[errCapResponse]{
+		"look": openaistool.FromTool(toolcall.Tool[errCapResponse]{
+			Def: toolcall.Definition{Name: "look", Description: "Look around."},
+			Handler: func(context.Context, toolcall.ToolCall, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"error": "nothing to see"}
+			},
+		}),
+	}
+	if _, err := exec.Execute(t.Context(), errCapRequest{}, tools); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	type step struct {
+		kind progress.Kind
+		turn int
+	}
+	var got []step
+	for _, ev := range events {
+		got = append(got, step{ev.Kind, ev.Turn})
+	}
+	want := []step{
+		{progress.TurnStarted, 0},
+		{progress.TextDelta, 0},
+		{progress.ToolCallStarted, 0},
+		{progress.ToolCallCompleted, 0},
+		{progress.TurnCompleted, 0},
+		{progress.TurnStarted, 1},
+		{progress.ToolCallStarted, 1},
+		{progress.SubmitRejected, 1},
+		{progress.ToolCallCompleted, 1},
+		{progress.TurnCompleted, 1},
+		{progress.TurnStarted, 2},
+		{progress.ToolCallStarted, 2},
+		{progress.ToolCallCompleted, 2},
+		{progress.TurnCompleted, 2},
+		{progress.RunCompleted, 0},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events: got = %v, want = %v", got, want)
+	}
+
+	if ev := events[1]; ev.Text != "Looking first." {
+		t.Errorf("text delta: got = %q, want = %q", ev.Text, "Looking first.")
+	}
+	if ev := events[3]; ev.ToolName != "look" || ev.ToolCallID != "call_look" || ev.Error != "nothing to see" {
+		t.Errorf("tool completion: got = %+v, want look/call_look failing with its error", ev)
+	}
+	if ev := events[7]; ev.ToolName != "submit_result" || len(ev.Findings) != 1 {
+		t.Errorf("submit rejection: got = %+v, want submit_result with one finding", ev)
+	}
+	if ev := events[8]; ev.Error == "" {
+		t.Error("rejected submission completion: got no error, want the rejection message")
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"fmt"
 
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
 	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/executor/retry"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
@@ -93,6 +96,50 @@ func WithMaxTurns[Request promptbuilder.Bindable, Response any](turns int) Optio
 	}
 }
 
+// WithCompaction enables context compaction: before a request whose
+// estimated conversation size exceeds cfg.Threshold tokens, the oldest tool
+// messages the model has already seen have their content replaced with a
+// short placeholder, keeping their tool_call_id so every assistant tool call
+// stays answered. Each compaction step is recorded on the trace. See the
+// compaction package for the policy. Off by default.
+func WithCompaction[Request promptbuilder.Bindable, Response any](cfg compaction.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.compaction = &cfg
+		return nil
+	}
+}
+
+// WithBudget caps each run's spend: its total tokens, its estimated cost, or
+// both (see budget.Config). As the budget nears, the model is told once to
+// submit its result; once it is exceeded, the run is aborted before its next
+// request with an error that budget.AsExceeded extracts. Cached prompt tokens
+// are priced as cache reads. The spend is saved in the checkpoint envelope, so
+// a resumed run counts against the same budget. Off by default.
+func WithBudget[Request promptbuilder.Bindable, Response any](cfg budget.Config) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		e.budget = &cfg
+		return nil
+	}
+}
+
+// WithObserver registers an observer for the run's progress events: turn
+// starts, reasoning and response text, tool calls starting and completing,
+// and submissions the result validators reject. Completions are not
+// streamed, so each turn's text arrives as a single delta. See the progress
+// package.
+func WithObserver[Request promptbuilder.Bindable, Response any](obs progress.Observer) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		e.observer = obs
+		return nil
+	}
+}
+
 // WithToolCallConcurrency bounds how many of a single turn's tool calls run
 // concurrently when the model emits more than one in a turn (parallel tool
 // calls). Defaults to DefaultToolCallConcurrency.
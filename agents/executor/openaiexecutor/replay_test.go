@@ -0,0 +1,118 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor_test
+
+import (
+	"context"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/evals/replay"
+	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+)
+
+// TestReplayToolLoop records a parallel-tool-call turn followed by a submit
+// turn against the validating server, then reruns it from the fixtures with no
+// server at all: the executor's chat completion requests must be deterministic
+// enough to match their recordings. It then replays the fixtures committed
+// under testdata/replay, which catches request drift across SDK upgrades;
+// re-record them with DRIFTLESSAF_RECORD=1.
+func TestReplayToolLoop(t *testing.T) {
+	script := func(reqNum int, _ []byte) string {
+		if reqNum == 1 {
+			return turn1ToolCallsJSON
+		}
+		return turn2SubmitJSON
+	}
+
+	mkTool := func(name string) openaistool.Metadata[errCapResponse] {
+		return openaistool.FromTool(toolcall.Tool[errCapResponse]{
+			Def: toolcall.Definition{Name: name, Description: "test tool " + name},
+			Handler: func(context.Context, toolcall.ToolCall, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": name}
+			},
+		})
+	}
+	tools := map[string]openaistool.Metadata[errCapResponse]{
+		"tool_a": mkTool("tool_a"),
+		"tool_b": mkTool("tool_b"),
+	}
+	submit := func() (openaistool.SubmitMetadata[errCapResponse], error) {
+		return openaistool.SubmitMetadata[errCapResponse]{
+			Definition: openai.ChatCompletionToolParam{
+				Function: shared.FunctionDefinitionParam{Name: "submit_result"},
+			},
+			Handler: func(context.Context, openai.ChatCompletionMessageToolCall, *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+				return toolcall.SubmitOutcome[errCapResponse]{
+					Accepted:   true,
+					Response:   errCapResponse{Answer: "done"},
+					ToolResult: map[string]any{"success": true},
+				}
+			},
+		}, nil
+	}
+	run := func(tr *replay.Transport, baseURL string) {
+		t.Helper()
+		client := openai.NewClient(
+			option.WithBaseURL(baseURL),
+			option.WithAPIKey("test"),
+			option.WithMaxRetries(0),
+			option.WithHTTPClient(tr.Client()),
+		)
+		prompt, err := promptbuilder.NewPrompt("go")
+		if err != nil {
+			t.Fatalf("NewPrompt: %v", err)
+		}
+		exec, err := openaiexecutor.New[errCapRequest, errCapResponse](
+			client,
+			prompt,
+			openaiexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+			openaiexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](submit),
+			openaiexecutor.WithMaxTurns[errCapRequest, errCapResponse](3),
+		)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		resp, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+		if err != nil {
+			t.Fatalf("Execute (%v): %v", tr.Mode(), err)
+		}
+		if got, want := resp.Answer, "done"; got != want {
+			t.Errorf("resp.Answer (%v): got = %q, want = %q", tr.Mode(), got, want)
+		}
+	}
+
+	srv := newValidatingOpenAIServer(t, script)
+	dir := t.TempDir()
+	rec, err := replay.New(dir, replay.ModeRecord)
+	if err != nil {
+		t.Fatalf("replay.New(record): %v", err)
+	}
+	run(rec, srv.URL)
+
+	srv.Close()
+	rep, err := replay.New(dir, replay.ModeReplay)
+	if err != nil {
+		t.Fatalf("replay.New(replay): %v", err)
+	}
+	run(rep, "http://replay.invalid")
+	if got := rep.Unmatched(); len(got) != 0 {
+		t.Errorf("Unmatched: %v", got)
+	}
+
+	fixtures := replay.ForTest(t, "testdata/replay")
+	baseURL := "http://replay.invalid"
+	if fixtures.Mode() == replay.ModeRecord {
+		baseURL = newValidatingOpenAIServer(t, script).URL
+	}
+	run(fixtures, baseURL)
+}
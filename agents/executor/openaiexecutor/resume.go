@@ -0,0 +1,101 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+)
+
+// Resumer is the capability interface for resuming a conversation that
+// previously suspended (see WithSuspendTool). Like claudeexecutor.Resumer it is
+// deliberately NOT part of the exported Interface: a caller that parks and
+// wakes runs type-asserts the Interface returned by New to Resumer, while
+// every other consumer compiles unmodified.
+type Resumer[Request promptbuilder.Bindable, Response any] interface {
+	// Resume rebuilds the paused conversation from env and continues it.
+	// answers maps each pending tool call ID (env.PendingToolCalls[i].ID) to
+	// the human's answer; a missing entry is framed as an explicit
+	// empty-answer placeholder so no tool call is left unanswered. It returns
+	// checkpoint.ErrConfigDrift (wrapped) when the live executor config no
+	// longer matches the envelope.
+	Resume(ctx context.Context, env checkpoint.Envelope, answers map[string]string, tools map[string]openaistool.Metadata[Response]) (Response, error)
+}
+
+var (
+	_ Interface[promptbuilder.Bindable, any] = (*executor[promptbuilder.Bindable, any])(nil)
+	_ Resumer[promptbuilder.Bindable, any]   = (*executor[promptbuilder.Bindable, any])(nil)
+)
+
+// Resume implements Resumer. It restores the chat-completions request captured
+// at the suspension point, appends one framed tool message per pending call,
+// and drives the shared turn loop for the envelope's remaining turn budget
+// under a new trace linked back to the originating one.
+func (e *executor[Request, Response]) Resume(
+	ctx context.Context,
+	env checkpoint.Envelope,
+	answers map[string]string,
+	tools map[string]openaistool.Metadata[Response],
+) (response Response, err error) {
+	// Fail closed on any drift in provider, model, or the turn-invariant
+	// request configuration before the parked state is touched.
+	static, serr := e.buildStaticParams(tools)
+	if serr != nil {
+		return response, serr
+	}
+	turnBudget, verr := execshared.ValidateResume(env, suspendProviderName, e.modelName, static, e.maxTurns)
+	if verr != nil {
+		return response, verr
+	}
+
+	var reqParams openai.ChatCompletionNewParams
+	if uerr := json.Unmarshal(env.ProviderState, &reqParams); uerr != nil {
+		return response, fmt.Errorf("resume: unmarshal provider state: %w", uerr)
+	}
+	if aerr := appendPendingAnswers(&reqParams, env.PendingToolCalls, answers); aerr != nil {
+		return response, aerr
+	}
+
+	trace, done := execshared.StartResumeTrace[Response](ctx, env)
+	defer func() {
+		done(response, err)
+	}()
+
+	response, err = e.runConversation(ctx, trace, reqParams, tools, env.Turn+1, turnBudget, env.LoopState)
+	return response, err
+}
+
+// appendPendingAnswers appends one framed tool message per pending tool call,
+// pairing each to its persisted tool_call_id. The captured transcript ends
+// with the suspended assistant turn followed by its siblings' tool messages,
+// so the answers complete that run of tool messages. Framing is
+// checkpoint.FramedAnswers' job; this only maps the framed answers into the
+// OpenAI tool message shape.
+func appendPendingAnswers(reqParams *openai.ChatCompletionNewParams, pending []checkpoint.PendingToolCall, answers map[string]string) error {
+	i := len(reqParams.Messages) - 1
+	for i >= 0 && reqParams.Messages[i].OfTool != nil {
+		i--
+	}
+	if i < 0 || reqParams.Messages[i].OfAssistant == nil {
+		return errors.New("resume: provider state does not end with the suspended assistant turn")
+	}
+	framed, err := checkpoint.FramedAnswers(pending, answers, 0)
+	if err != nil {
+		return err
+	}
+	for _, f := range framed {
+		reqParams.Messages = append(reqParams.Messages, openai.ToolMessage(f.Text, f.ID))
+	}
+	return nil
+}
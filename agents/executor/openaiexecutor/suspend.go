@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor
+
+import (
+	"errors"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/internal/execshared"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+)
+
+// suspendProviderName is the checkpoint.Envelope.Provider value stamped by this
+// executor. It names the executor backend that produced ProviderState (an
+// openai.ChatCompletionNewParams JSON blob), NOT the serving endpoint (Vertex
+// partner models vs any other OpenAI-compatible API).
+const suspendProviderName = checkpoint.ProviderOpenAI
+
+// SuspendProvider constructs the tool definition for the held-out ask-a-friend
+// suspend tool. It mirrors SubmitResultProvider's shape (a constructor that can
+// fail) so callers wire suspension the same way they wire the terminal submit
+// tool.
+type SuspendProvider func() (openai.ChatCompletionToolParam, error)
+
+// WithSuspendTool registers the ask-a-friend suspend tool advertised to the
+// model. When the model calls it, Execute halts after the turn quiesces (every
+// sibling tool call has produced its real tool message) and returns a
+// *checkpoint.Suspension — an error value carrying the full envelope needed to
+// resume later through Resumer — instead of a Response.
+//
+// This is fully opt-in: without WithSuspendTool the suspend tool is never
+// advertised and Execute's behavior is unchanged. The suspend tool name must
+// differ from the terminal submit tool's name (validated at construction) and
+// from every caller-registered tool (validated in Execute, where the run's tool
+// map is known).
+func WithSuspendTool[Request promptbuilder.Bindable, Response any](provider SuspendProvider) Option[Request, Response] {
+	return func(e *executor[Request, Response]) error {
+		if provider == nil {
+			return errors.New("suspend tool provider cannot be nil")
+		}
+		def, err := provider()
+		if err != nil {
+			return err
+		}
+		if def.Function.Name == "" {
+			return errors.New("suspend tool definition must have a name")
+		}
+		e.suspendTool = &def
+		return nil
+	}
+}
+
+// suspendToolName returns the configured ask-a-friend suspend tool's name, or ""
+// when WithSuspendTool was not applied. Symmetric with submitToolName.
+func (e *executor[Request, Response]) suspendToolName() string {
+	if e.suspendTool == nil {
+		return ""
+	}
+	return e.suspendTool.Function.Name
+}
+
+// buildSuspension assembles the checkpoint.Suspension returned when the model
+// calls the suspend tool. reqParams is the request as it stands at the pause
+// point — the assistant tool_calls message plus the siblings' real tool
+// messages, with the suspend call left unanswered — captured verbatim as
+// ProviderState. The config digest is taken over buildStaticParams, which holds
+// everything but the conversation, so it is stable across turns. spend is the
+// run's budget tracker, saved so the resumed run continues against the same
+// budget.
+func (e *executor[Request, Response]) buildSuspension(
+	reqParams openai.ChatCompletionNewParams,
+	tools map[string]openaistool.Metadata[Response],
+	turn int,
+	suspendCall openai.ChatCompletionMessageToolCall,
+	spend *budget.Tracker,
+	traceID string,
+) (*checkpoint.Suspension, error) {
+	static, err := e.buildStaticParams(tools)
+	if err != nil {
+		return nil, err
+	}
+	return execshared.BuildSuspension(suspendProviderName, e.modelName, static, reqParams, turn, e.maxTurns,
+		checkpoint.PendingToolCall{
+			ID:        suspendCall.ID,
+			Name:      suspendCall.Function.Name,
+			InputJSON: []byte(suspendCall.Function.Arguments),
+		}, spend, traceID)
+}
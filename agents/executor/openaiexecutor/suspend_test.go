@@ -0,0 +1,205 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package openaiexecutor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+)
+
+const askAFriendToolName = "ask_a_friend"
+
+// askTurnJSON is a completion that calls a regular tool and the suspend tool
+// in the same turn, so the parked transcript carries a real sibling result.
+const askTurnJSON = `{
+  "id": "chatcmpl-ask",
+  "object": "chat.completion",
+  "created": 1,
+  "model": "test-model",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "",
+      "tool_calls": [
+        {"id": "call_a", "type": "function", "function": {"name": "tool_a", "arguments": "{\"reasoning\":\"r\"}"}},
+        {"id": "call_ask", "type": "function", "function": {"name": "ask_a_friend", "arguments": "{\"question\":\"ship it?\"}"}}
+      ]
+    }
+  }],
+  "usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}
+}`
+
+// askAFriendProvider is a SuspendProvider registering an ask_a_friend tool the
+// model can call to pause the conversation for a human answer.
+func askAFriendProvider() openaiexecutor.SuspendProvider {
+	return func() (openai.ChatCompletionToolParam, error) {
+		return openai.ChatCompletionToolParam{
+			Function: shared.FunctionDefinitionParam{
+				Name:        askAFriendToolName,
+				Description: openai.String("Pause and ask a human operator a question."),
+				Parameters: shared.FunctionParameters{
+					"type":       "object",
+					"properties": map[string]any{"question": map[string]any{"type": "string"}},
+				},
+			},
+		}, nil
+	}
+}
+
+// newSuspendingExecutor builds an executor with the submit and suspend tools
+// registered against srv, and the regular tool_a map the scripted turns call.
+func newSuspendingExecutor(t *testing.T, srvURL, model string, maxTurns int) (openaiexecutor.Interface[errCapRequest, errCapResponse], map[string]openaistool.Metadata[errCapResponse]) {
+	t.Helper()
+	client := openai.NewClient(
+		option.WithBaseURL(srvURL),
+		option.WithAPIKey("test"),
+		option.WithMaxRetries(0),
+	)
+	prompt, err := promptbuilder.NewPrompt("go")
+	if err != nil {
+		t.Fatalf("NewPrompt: %v", err)
+	}
+	submit := func() (openaistool.SubmitMetadata[errCapResponse], error) {
+		return openaistool.SubmitMetadata[errCapResponse]{
+			Definition: openai.ChatCompletionToolParam{
+				Function: shared.FunctionDefinitionParam{Name: "submit_result"},
+			},
+			Handler: func(context.Context, openai.ChatCompletionMessageToolCall, *agenttrace.Trace[errCapResponse]) toolcall.SubmitOutcome[errCapResponse] {
+				return toolcall.SubmitOutcome[errCapResponse]{
+					Accepted:   true,
+					Response:   errCapResponse{Answer: "done"},
+					ToolResult: map[string]any{"success": true},
+				}
+			},
+		}, nil
+	}
+	exec, err := openaiexecutor.New[errCapRequest, errCapResponse](
+		client, prompt,
+		openaiexecutor.WithModel[errCapRequest, errCapResponse](model),
+		openaiexecutor.WithRetryConfig[errCapRequest, errCapResponse](fastRetry(0)),
+		openaiexecutor.WithMaxTurns[errCapRequest, errCapResponse](maxTurns),
+		openaiexecutor.WithSubmitResultProvider[errCapRequest, errCapResponse](submit),
+		openaiexecutor.WithSuspendTool[errCapRequest, errCapResponse](askAFriendProvider()),
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tools := map[string]openaistool.Metadata[errCapResponse]{
+		"tool_a": openaistool.FromTool(toolcall.Tool[errCapResponse]{
+			Def: toolcall.Definition{Name: "tool_a", Description: "test tool"},
+			Handler: func(context.Context, toolcall.ToolCall, *agenttrace.Trace[errCapResponse], *errCapResponse) map[string]any {
+				return map[string]any{"ok": "tool_a"}
+			},
+		}),
+	}
+	return exec, tools
+}
+
+// TestSuspendReturnsSuspension pins that a turn calling the suspend tool halts
+// the run with a *checkpoint.Suspension whose single pending call is keyed by
+// the provider-assigned tool_call_id, while the sibling tool's real result is
+// captured in the provider state.
+func TestSuspendReturnsSuspension(t *testing.T) {
+	const maxTurns = 7
+	srv := newValidatingOpenAIServer(t, func(int, []byte) string { return askTurnJSON })
+	exec, tools := newSuspendingExecutor(t, srv.URL, "test-model", maxTurns)
+
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+	if got, want := len(susp.PendingToolCalls), 1; got != want {
+		t.Fatalf("PendingToolCalls: got %d, want %d", got, want)
+	}
+	if pc := susp.PendingToolCalls[0]; pc.ID != "call_ask" || pc.Name != askAFriendToolName {
+		t.Errorf("PendingToolCalls[0]: got (%q, %q), want (%q, %q)", pc.ID, pc.Name, "call_ask", askAFriendToolName)
+	}
+	if got, want := susp.Provider, checkpoint.ProviderOpenAI; got != want {
+		t.Errorf("Provider: got %q, want %q", got, want)
+	}
+	if got, want := susp.RemainingTurns, maxTurns-1; got != want {
+		t.Errorf("RemainingTurns: got %d, want %d", got, want)
+	}
+	if !strings.Contains(string(susp.ProviderState), `"tool_call_id":"call_a"`) {
+		t.Errorf("ProviderState does not carry the sibling tool result: %s", susp.ProviderState)
+	}
+	if strings.Contains(string(susp.ProviderState), checkpoint.StatusAwaitingAnswer) {
+		t.Errorf("ProviderState answers the pending call with a placeholder: %s", susp.ProviderState)
+	}
+}
+
+// TestResumeRoundTrip parks a run and resumes it on a fresh executor with the
+// same configuration: the resumed request pairs the framed human answer with
+// the pending tool_call_id (the validating server rejects any unpaired call),
+// and the run then completes through the submit tool.
+func TestResumeRoundTrip(t *testing.T) {
+	var resumedBody string
+	srv := newValidatingOpenAIServer(t, func(reqNum int, body []byte) string {
+		if reqNum == 1 {
+			return askTurnJSON
+		}
+		resumedBody = string(body)
+		return turn2SubmitJSON
+	})
+
+	exec, tools := newSuspendingExecutor(t, srv.URL, "test-model", 5)
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+
+	fresh, tools := newSuspendingExecutor(t, srv.URL, "test-model", 5)
+	resumer, ok := fresh.(openaiexecutor.Resumer[errCapRequest, errCapResponse])
+	if !ok {
+		t.Fatal("executor does not implement Resumer")
+	}
+	resp, err := resumer.Resume(t.Context(), susp.Envelope, map[string]string{"call_ask": "yes, ship it"}, tools)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if got, want := resp.Answer, "done"; got != want {
+		t.Errorf("resp.Answer: got %q, want %q", got, want)
+	}
+	if !strings.Contains(resumedBody, "BEGIN HUMAN ANSWER") || !strings.Contains(resumedBody, "yes, ship it") {
+		t.Errorf("resumed request does not carry the framed answer: %s", resumedBody)
+	}
+}
+
+// TestResumeFailsClosedOnModelDrift pins that an envelope parked under one
+// model is rejected with checkpoint.ErrConfigDrift by an executor configured
+// for another.
+func TestResumeFailsClosedOnModelDrift(t *testing.T) {
+	srv := newValidatingOpenAIServer(t, func(int, []byte) string { return askTurnJSON })
+
+	exec, tools := newSuspendingExecutor(t, srv.URL, "test-model", 5)
+	_, err := exec.Execute(t.Context(), errCapRequest{}, tools)
+	susp, ok := checkpoint.AsSuspension(err)
+	if !ok {
+		t.Fatalf("Execute: got err %v, want a *checkpoint.Suspension", err)
+	}
+
+	drifted, tools := newSuspendingExecutor(t, srv.URL, "other-model", 5)
+	_, err = drifted.(openaiexecutor.Resumer[errCapRequest, errCapResponse]).Resume(t.Context(), susp.Envelope, nil, tools)
+	if !errors.Is(err, checkpoint.ErrConfigDrift) {
+		t.Errorf("Resume: got %v, want checkpoint.ErrConfigDrift", err)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package progress reports what an agent run is doing while it runs.
+
+# Overview
+
+An executor's trace is complete only once Execute returns, and a long agent
+run can take many minutes. An Observer receives typed events as they happen
+instead: a turn starting and ending, fragments of the model's reasoning and
+response text, each tool call starting and completing, a submission rejected
+by the result validators, and the executor call returning.
+
+The Claude executor streams its responses, so ThinkingDelta and TextDelta
+events arrive token by token. The Google and OpenAI-compatible executors
+receive each response whole and report it as a single delta per part. When
+a streamed request is retried, the retried attempt's deltas are reported
+again from the start.
+
+# Usage
+
+Register an observer with the executor's WithObserver option (or
+metaagent.Config.Observer):
+
+	exec, err := claudeexecutor.New[*Request, *Result](client, prompt,
+		claudeexecutor.WithObserver[*Request, *Result](func(ctx context.Context, ev progress.Event) {
+			if ev.Kind == progress.ToolCallStarted {
+				clog.InfoContext(ctx, "agent is calling a tool", "tool", ev.ToolName)
+			}
+		}),
+	)
+
+Observers run inline with the conversation loop and with concurrent tool
+handlers, so they must be safe for concurrent use and must not block. Join
+combines several observers; statusmanager.NewProgressObserver coalesces
+events into check run updates published off the run's goroutines.
+*/
+package progress
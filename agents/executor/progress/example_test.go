@@ -0,0 +1,36 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package progress_test
+
+import (
+	"context"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+)
+
+// ExampleJoin fans one run's events out to two observers.
+func ExampleJoin() {
+	var tools []string
+	record := func(_ context.Context, ev progress.Event) {
+		if ev.Kind == progress.ToolCallStarted {
+			tools = append(tools, ev.ToolName)
+		}
+	}
+	log := func(_ context.Context, ev progress.Event) {
+		fmt.Printf("turn %d: %s\n", ev.Turn, ev.Kind)
+	}
+
+	obs := progress.Join(record, nil, log)
+	ctx := context.Background()
+	obs.Emit(ctx, progress.Event{Kind: progress.TurnStarted, Turn: 0})
+	obs.Emit(ctx, progress.Event{Kind: progress.ToolCallStarted, Turn: 0, ToolName: "read_file"})
+	fmt.Println(tools)
+	// Output:
+	// turn 0: turn_started
+	// turn 0: tool_call_started
+	// [read_file]
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package progress
+
+import (
+	"context"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+// Kind identifies what happened in an Event.
+type Kind string
+
+const (
+	// TurnStarted fires as the executor begins a model turn.
+	TurnStarted Kind = "turn_started"
+	// TurnCompleted fires as a model turn ends, once its tool calls have
+	// completed.
+	TurnCompleted Kind = "turn_completed"
+	// RunCompleted fires as the executor call returns, however it ends: with
+	// a result, an error, or parked on a checkpoint. Turn is not set.
+	RunCompleted Kind = "run_completed"
+	// ThinkingDelta carries a fragment of the model's reasoning in Text.
+	ThinkingDelta Kind = "thinking_delta"
+	// TextDelta carries a fragment of the model's visible response in Text.
+	TextDelta Kind = "text_delta"
+	// ToolCallStarted fires before a tool call's handler runs.
+	ToolCallStarted Kind = "tool_call_started"
+	// ToolCallCompleted fires once a tool call's result is ready. Error is
+	// set when the result reports one.
+	ToolCallCompleted Kind = "tool_call_completed"
+	// SubmitRejected fires when the result validators reject a submission;
+	// Findings holds what they raised. The run continues so the model can
+	// address them.
+	SubmitRejected Kind = "submit_rejected"
+)
+
+// Event is one step of an agent run, delivered to an Observer as it happens.
+// Only the fields relevant to Kind are set.
+type Event struct {
+	// Kind is what happened.
+	Kind Kind
+
+	// Turn is the conversation turn the event belongs to.
+	Turn int
+
+	// Text is the fragment carried by ThinkingDelta and TextDelta.
+	Text string
+
+	// ToolCallID and ToolName identify the call for ToolCallStarted,
+	// ToolCallCompleted and SubmitRejected.
+	ToolCallID string
+	ToolName   string
+
+	// Error is the error message a completed tool call returned to the
+	// model, if any.
+	Error string
+
+	// Findings are the validator findings behind a SubmitRejected event.
+	Findings []callbacks.Finding
+}
+
+// Observer receives an agent run's events. Executors call it synchronously
+// from the conversation loop and, for tool call events, from concurrent tool
+// handlers, so it must be safe for concurrent use and should return quickly:
+// a slow observer stalls the run. A nil Observer discards every event.
+type Observer func(ctx context.Context, ev Event)
+
+// Emit delivers ev to o, doing nothing when o is nil.
+func (o Observer) Emit(ctx context.Context, ev Event) {
+	if o != nil {
+		o(ctx, ev)
+	}
+}
+
+// Join returns an Observer that delivers each event to every non-nil
+// observer in order, or nil when there are none.
+func Join(observers ...Observer) Observer {
+	var live []Observer
+	for _, o := range observers {
+		if o != nil {
+			live = append(live, o)
+		}
+	}
+	switch len(live) {
+	case 0:
+		return nil
+	case 1:
+		return live[0]
+	}
+	return func(ctx context.Context, ev Event) {
+		for _, o := range live {
+			o(ctx, ev)
+		}
+	}
+}
+
+// ToolError returns the error message a tool result reports under its
+// "error" key, the convention every tool in this module follows, or "" when
+// it reports none.
+func ToolError(result map[string]any) string {
+	msg, _ := result["error"].(string)
+	return msg
+}
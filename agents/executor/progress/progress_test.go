@@ -0,0 +1,51 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package progress
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNilObserverDiscards(t *testing.T) {
+	var o Observer
+	o.Emit(t.Context(), Event{Kind: TurnStarted})
+}
+
+func TestJoin(t *testing.T) {
+	if Join() != nil || Join(nil, nil) != nil {
+		t.Error("Join of no observers: got non-nil, want nil")
+	}
+
+	var got []string
+	named := func(name string) Observer {
+		return func(context.Context, Event) { got = append(got, name) }
+	}
+	Join(named("a"), nil, named("b")).Emit(t.Context(), Event{Kind: TextDelta})
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("delivery order: got = %v, want = [a b]", got)
+	}
+}
+
+func TestToolError(t *testing.T) {
+	tests := []struct {
+		name   string
+		result map[string]any
+		want   string
+	}{
+		{name: "nil", result: nil, want: ""},
+		{name: "success", result: map[string]any{"content": "x"}, want: ""},
+		{name: "error", result: map[string]any{"error": "no such file"}, want: "no such file"},
+		{name: "non-string error", result: map[string]any{"error": 42}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToolError(tt.result); got != tt.want {
+				t.Errorf("ToolError: got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package metaagent
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"chainguard.dev/driftlessaf/agents/model"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
@@ -26,21 +27,49 @@ type Agent[Req promptbuilder.Bindable, Resp, CB any] interface {
 // The modelName parameter determines which provider implementation is used:
 //   - Models starting with "gemini-" use Google's Generative AI SDK (native)
 //   - Models starting with "claude-" use Anthropic's SDK via Vertex AI (native)
-//   - Models in "publisher/model" format use Vertex AI's OpenAI-compatible endpoint
+//   - Models in "publisher/model" format use Vertex AI's OpenAI-compatible
+//     endpoint, or config.OpenAIEndpoint when set
+//   - Any other model uses config.OpenAIEndpoint when set
+//
+// model.Register can re-route an id by declaring its backend. The first call
+// to New also loads the model declarations named by the environment (see
+// model.LoadEnv), so a deployment can declare models without code changes.
 func New[Req promptbuilder.Bindable, Resp, CB any](
 	ctx context.Context,
 	projectID, region, modelName string,
 	config Config[Resp, CB],
 ) (Agent[Req, Resp, CB], error) {
-	switch model.Resolve(modelName).Backend {
+	if err := loadModelEnv(); err != nil {
+		return nil, fmt.Errorf("loading model declarations: %w", err)
+	}
+	backend := model.Resolve(modelName).Backend
+	if backend == model.BackendUnknown && config.OpenAIEndpoint != nil {
+		// The configured server serves its own model ids.
+		backend = model.BackendOpenAICompat
+	}
+	switch backend {
 	case model.BackendGemini:
 		return newGoogleAgent[Req, Resp, CB](ctx, projectID, region, modelName, config)
 	case model.BackendClaude:
 		return newClaudeAgent[Req, Resp, CB](ctx, projectID, region, modelName, config)
 	case model.BackendOpenAICompat:
-		// publisher/model format routes to the Vertex AI OpenAI-compatible endpoint
+		// publisher/model format routes to the Vertex AI OpenAI-compatible
+		// endpoint unless the config names another server.
 		return newOpenAICompatAgent[Req, Resp, CB](ctx, projectID, region, modelName, config)
 	default:
 		return nil, fmt.Errorf("unsupported model: %s (expected gemini-*, claude-*, or publisher/model format)", modelName)
 	}
 }
+
+// loadModelEnv applies the environment's model declarations once per
+// process; a failure is returned from every New call.
+var loadModelEnv = sync.OnceValue(model.LoadEnv)
+
+// outputTokens returns a backend's default per-turn output-token cap,
+// lowered to the model's ceiling when the registry knows a smaller one.
+func outputTokens(modelName string, fallback int64) int64 {
+	if limit := model.Resolve(modelName).MaxOutputTokens; limit > 0 && limit < fallback {
+		return limit
+	}
+	return fallback
+}
@@ -23,6 +23,7 @@ import (
 // defaultMaxTokens is the per-turn output-token cap applied when Config.MaxTokens
 // is unset. It matches the historical meta-agent default; agents that need room
 // for extended thinking plus a tool call on the same turn raise it via Config.
+// A model declared with a smaller MaxOutputTokens gets that instead.
 const defaultMaxTokens int64 = 32000
 
 // claudeAgent implements Agent using Claude via Vertex AI (default) or the
@@ -73,7 +74,7 @@ func newClaudeAgent[Req promptbuilder.Bindable, Resp, CB any](
 		claudeexecutor.WithModel[Req, Resp](model),
 		claudeexecutor.WithProvider[Req, Resp](provider),
 		claudeexecutor.WithTemperature[Req, Resp](0.2),
-		claudeexecutor.WithMaxTokens[Req, Resp](cmp.Or(config.MaxTokens, defaultMaxTokens)),
+		claudeexecutor.WithMaxTokens[Req, Resp](cmp.Or(config.MaxTokens, outputTokens(model, defaultMaxTokens))),
 		claudeexecutor.WithSubmitResultProvider[Req, Resp](func() (claudetool.SubmitMetadata[Resp], error) { return submitTool, nil }),
 		claudeexecutor.WithResourceLabels[Req, Resp](map[string]string{"projectID": projectID, "region": region, "model_name": strings.ToLower(model)}),
 	}
@@ -89,6 +90,18 @@ func newClaudeAgent[Req promptbuilder.Bindable, Resp, CB any](
 		executorOpts = append(executorOpts, claudeexecutor.WithToolCallConcurrency[Req, Resp](config.ToolCallConcurrency))
 	}
 
+	if config.Compaction != nil {
+		executorOpts = append(executorOpts, claudeexecutor.WithCompaction[Req, Resp](*config.Compaction))
+	}
+
+	if config.Budget != nil {
+		executorOpts = append(executorOpts, claudeexecutor.WithBudget[Req, Resp](*config.Budget))
+	}
+
+	if config.Observer != nil {
+		executorOpts = append(executorOpts, claudeexecutor.WithObserver[Req, Resp](config.Observer))
+	}
+
 	if config.SystemInstructions != nil {
 		executorOpts = append(executorOpts, claudeexecutor.WithSystemInstructions[Req, Resp](config.SystemInstructions))
 	}
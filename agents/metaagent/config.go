@@ -7,6 +7,9 @@ package metaagent
 
 import (
 	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/executor/budget"
+	"chainguard.dev/driftlessaf/agents/executor/compaction"
+	"chainguard.dev/driftlessaf/agents/executor/progress"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/submitresult"
 	"chainguard.dev/driftlessaf/agents/toolcall"
@@ -52,11 +55,48 @@ type Config[Resp, CB any] struct {
 	// whose tool handlers mutate shared state without their own synchronization.
 	ToolCallConcurrency int
 
+	// Compaction, when non-nil, enables context compaction on every backend:
+	// once a request's estimated size crosses Compaction.Threshold tokens, the
+	// oldest tool results the model has already seen are replaced with short
+	// placeholders, and each step is recorded on the run's trace. Nil (the
+	// default) sends every tool result verbatim for the whole run. Useful for
+	// long tool-heavy runs that would otherwise outgrow the context window.
+	Compaction *compaction.Config
+
+	// Budget, when non-nil, caps each run's spend on every backend: its total
+	// tokens, its estimated dollar cost (priced with Budget.Prices against
+	// the resolved model), or both. The model is nudged once to submit as the
+	// budget nears, and a run that goes over fails with an error that
+	// budget.AsExceeded extracts. Nil (the default) leaves spend uncapped;
+	// MaxTurns still bounds the run.
+	Budget *budget.Config
+
+	// Observer, when non-nil, receives each run's progress events as they
+	// happen on every backend: turn starts and ends, thinking and text
+	// deltas, tool calls starting and completing, submissions rejected by
+	// ResultValidators, and the run's completion. Claude streams its deltas; Gemini and
+	// OpenAI-compatible models report each response part whole. It is
+	// called inline with the run, so it must be safe for concurrent use and
+	// must not block; statusmanager.NewProgressObserver is a check-run
+	// adapter that publishes from its own goroutine.
+	Observer progress.Observer
+
+	// OpenAIEndpoint, when non-nil, sends OpenAI-compatible models to this
+	// server instead of Vertex AI's OpenAI-compatible endpoint: the
+	// first-party OpenAI API, or a local vLLM or llama.cpp server. Model ids
+	// New cannot otherwise route (no "gemini-", "claude-" or
+	// "publisher/model" shape) are then sent here too, so a server's own
+	// model names work as-is. Declare such models' capabilities with
+	// model.Register so temperature and reasoning_effort are only sent where
+	// accepted. projectID and region are still recorded as resource labels.
+	OpenAIEndpoint *OpenAIEndpoint
+
 	// MaxTokens caps the model's output tokens per turn (the Anthropic
 	// max_tokens parameter). Zero (the default) uses the meta-agent default of
-	// 32000; the executor rejects values above 128000, the ceiling for current
-	// Claude models. Because the executor streams every response, large values
-	// do not risk the SDK's non-streaming HTTP timeout. Raise it for stages
+	// 32000; the executor rejects values above the model's output-token
+	// ceiling in the model registry (128000 for current Claude models).
+	// Because the executor streams every response, large values do not risk
+	// the SDK's non-streaming HTTP timeout. Raise it for stages
 	// whose turns need room for BOTH extended thinking and a tool call: at high
 	// effort on a large context, adaptive thinking can otherwise consume the
 	// whole budget and stop at max_tokens before the model emits its tool call,
@@ -112,14 +152,10 @@ type Config[Resp, CB any] struct {
 	// obtains the resume path by type-asserting the constructed agent with
 	// AsResumer.
 	//
-	// Claude backend only for now: the Gemini and OpenAI-compatible backends
-	// reject a non-empty SuspendToolName at construction with a clear error
-	// until their executors grow suspend support (DEV-2247 follow-up slices) —
-	// silently ignoring it would advertise a lifecycle that can never fire.
-	// The name must differ from the terminal submit tool's name and from every
-	// caller-registered tool (both validated by the executor). Empty (the
-	// default) leaves suspension disabled and the run's behavior byte-for-byte
-	// unchanged.
+	// Supported on every backend. The name must differ from the terminal
+	// submit tool's name and from every caller-registered tool (both
+	// validated by the executor). Empty (the default) leaves suspension
+	// disabled and the run's behavior byte-for-byte unchanged.
 	SuspendToolName string
 
 	// SuspendToolDescription is the friend-facing description advertised to the
@@ -156,7 +192,6 @@ func submitOptions[Resp, CB any](config Config[Resp, CB]) submitresult.Options[R
 // suspendQuestionProperty is the single input property the suspend tool schema
 // declares: the friend-facing question text. checkpoint.QuestionFromPending
 // reads the same key ("question") when deriving a Question from a pending
-// suspend call, so the schema and the extraction can never drift. Backends
-// that gain suspend support later must build their schemas from this same
-// constant.
+// suspend call, so the schema and the extraction can never drift. Every
+// backend builds its suspend tool schema from this constant.
 const suspendQuestionProperty = "question"
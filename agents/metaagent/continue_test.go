@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metaagent
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+)
+
+// parkedEnvelope returns an envelope parked by the OpenAI-compatible backend
+// with one pending ask-a-friend call.
+func parkedEnvelope() checkpoint.Envelope {
+	return checkpoint.Envelope{
+		Provider:       checkpoint.ProviderOpenAI,
+		Model:          "meta/llama-old",
+		RemainingTurns: 7,
+		ProviderState: json.RawMessage(`{"messages":[
+			{"role":"user","content":"Fix the build"},
+			{"role":"assistant","tool_calls":[{"id":"call_ask","type":"function","function":{"name":"ask_a_friend","arguments":"{\"question\":\"Bump Go?\"}"}}]}]}`),
+		PendingToolCalls: []checkpoint.PendingToolCall{{
+			ID:        "call_ask",
+			Name:      "ask_a_friend",
+			InputJSON: json.RawMessage(`{"question":"Bump Go?"}`),
+		}},
+	}
+}
+
+func TestContinuationConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		suffix       *promptbuilder.Prompt
+		maxTurns     int
+		wantMaxTurns int
+		wantPrefix   string
+	}{{
+		name:         "no suffix, default turns",
+		wantMaxTurns: 7,
+		wantPrefix:   "This request continues an earlier run",
+	}, {
+		name:         "caller suffix kept first",
+		suffix:       promptbuilder.MustNewPrompt("Review for security."),
+		maxTurns:     3,
+		wantMaxTurns: 3,
+		wantPrefix:   "Review for security.\n\nThis request continues",
+	}, {
+		name:         "turns capped at the remaining budget",
+		maxTurns:     50,
+		wantMaxTurns: 7,
+		wantPrefix:   "This request continues",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := suspendConfig(t)
+			config.UserPromptSuffix = tt.suffix
+			config.MaxTurns = tt.maxTurns
+
+			got, err := continuationConfig(config, parkedEnvelope(), map[string]string{"call_ask": "yes"})
+			if err != nil {
+				t.Fatalf("continuationConfig() error = %v", err)
+			}
+			if got.MaxTurns != tt.wantMaxTurns {
+				t.Errorf("MaxTurns: got = %d, want = %d", got.MaxTurns, tt.wantMaxTurns)
+			}
+			suffix, err := got.UserPromptSuffix.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !strings.HasPrefix(suffix, tt.wantPrefix) {
+				t.Errorf("suffix does not start with %q:\n%s", tt.wantPrefix, suffix)
+			}
+			for _, want := range []string{
+				`<parked_conversation provider="openai" model="meta/llama-old">`,
+				`<question>Bump Go?</question>`,
+				"BEGIN HUMAN ANSWER",
+			} {
+				if !strings.Contains(suffix, want) {
+					t.Errorf("suffix missing %q:\n%s", want, suffix)
+				}
+			}
+		})
+	}
+}
+
+func TestContinuationConfigErrors(t *testing.T) {
+	exhausted := parkedEnvelope()
+	exhausted.RemainingTurns = 0
+
+	unknown := parkedEnvelope()
+	unknown.Provider = "mystery"
+
+	for name, env := range map[string]checkpoint.Envelope{
+		"exhausted turn budget": exhausted,
+		"unknown provider":      unknown,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := continuationConfig(suspendConfig(t), env, nil); err == nil {
+				t.Error("continuationConfig() error = nil, want error")
+			}
+		})
+	}
+}
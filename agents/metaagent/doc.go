@@ -20,6 +20,17 @@ SPDX-License-Identifier: Apache-2.0
 //   - Models starting with "claude-" use Anthropic's SDK via Vertex AI (native)
 //   - Models in "publisher/model" format use Vertex AI's OpenAI-compatible endpoint
 //
+// Config.OpenAIEndpoint sends OpenAI-compatible models to another server
+// instead — the first-party OpenAI API (OpenAIBaseURL) or a local vLLM or
+// llama.cpp server — along with any model id that matches none of the shapes
+// above, so a local server's own model names work unchanged:
+//
+//	config.OpenAIEndpoint = &metaagent.OpenAIEndpoint{BaseURL: "http://localhost:8000/v1"}
+//	agent, err := metaagent.New[*Request, *Result, MyCallbacks](ctx, "", "", "qwen2.5-coder-32b", config)
+//
+// Declare the capabilities of models outside the built-in routing rules, such
+// as a reasoning model that rejects temperature, with model.Register.
+//
 // # Usage
 //
 // Define your callback type by composing tool callbacks:
@@ -53,8 +64,28 @@ SPDX-License-Identifier: Apache-2.0
 // Setting Config.SuspendToolName advertises a held-out ask-a-friend tool; when
 // the model calls it, Execute returns a *checkpoint.Suspension instead of a
 // Resp, and the paused conversation is later continued through the opt-in
-// Resumer capability (obtained via AsResumer). Only the Claude backend
-// supports this today — the Gemini and OpenAI-compatible backends reject a
-// set SuspendToolName at construction until their executors grow suspend
-// support. See the suspend package for the park/wake orchestration around it.
+// Resumer capability (obtained via AsResumer). Every backend supports this;
+// an envelope resumes only on the backend that parked it. See the suspend
+// package for the park/wake orchestration around it.
+//
+// When the model changed while the run was parked, Continue carries the
+// answered conversation over instead: it starts a fresh run on the new model
+// seeded with the parked transcript, the pending question, and the friend's
+// answer (see the checkpoint/continuation package). Pair it with
+// suspend.Coordinator's ContinueOnDrift so drifted envelopes are claimed
+// rather than swept.
+//
+// # Failover
+//
+// NewFailover builds an agent over an ordered chain of models, which may span
+// backends. When a model's run fails because a retryable provider error
+// exhausted the executor's retries (ShouldFailover), the request is re-run
+// from scratch on the next model in the chain:
+//
+//	agent, err := metaagent.NewFailover[*Request, *Result, MyCallbacks](ctx, projectID, region,
+//	    []string{"claude-sonnet-4-6", "gemini-3-pro-preview"}, config)
+//
+// Each attempt carries the failover_backend and failover_attempt execution
+// labels, so traces and GenAI metrics show which backend served the run, and
+// every fallback is counted by genai.agent.failovers.
 package metaagent
@@ -0,0 +1,148 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metaagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/agents/metrics"
+	"chainguard.dev/driftlessaf/agents/model"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/chainguard-dev/clog"
+)
+
+// Execution-context labels a failover agent stamps on each attempt. They ride
+// on every GenAI metric the attempt emits and on its trace's exec_context, so
+// both show which backend served a run and whether it was a fallback. Both are
+// bounded: the backend by model.Backend, the attempt by the chain length.
+const (
+	// FailoverBackendLabel carries the model.Backend serving the attempt.
+	FailoverBackendLabel = "failover_backend"
+	// FailoverAttemptLabel carries the attempt's 0-based position in the
+	// failover chain; "0" is the primary model.
+	FailoverAttemptLabel = "failover_attempt"
+)
+
+// failoverAgent runs a request on the first model in its chain and moves to
+// the next when the current one fails with a retryable error that exhausted
+// its retries.
+type failoverAgent[Req promptbuilder.Bindable, Resp, CB any] struct {
+	models  []string
+	agents  []Agent[Req, Resp, CB]
+	metrics *metrics.GenAI
+}
+
+// NewFailover creates a meta-agent that serves each request from an ordered
+// chain of models, which may span backends (model.BackendClaude,
+// BackendGemini and BackendOpenAICompat). Execute runs the request on the
+// first model; when that run fails because a retryable provider error
+// (capacity, rate limit, overload) exhausted the executor's retries, it re-runs
+// the request from scratch on the next model, and so on down the chain. Any
+// other outcome — success, a non-retryable error, a suspension — is returned
+// as is.
+//
+// Every model is constructed up front with New and the same config, so each
+// backend maps config.Effort onto its own control and builds config.Tools into
+// its own tool definitions; a chain whose models cannot all be constructed is
+// rejected. Claude-only settings (ThinkingBudget, MaxTokens) apply only to
+// the Claude entries.
+//
+// The serving backend and attempt are stamped on each attempt's execution
+// context (FailoverBackendLabel, FailoverAttemptLabel), and every move down the
+// chain is counted by the genai.agent.failovers metric.
+//
+// The returned agent is a Resumer: an envelope resumes on whichever model in
+// the chain parked it.
+func NewFailover[Req promptbuilder.Bindable, Resp, CB any](
+	ctx context.Context,
+	projectID, region string,
+	modelNames []string,
+	config Config[Resp, CB],
+) (Agent[Req, Resp, CB], error) {
+	if len(modelNames) == 0 {
+		return nil, errors.New("failover requires at least one model")
+	}
+	agents := make([]Agent[Req, Resp, CB], 0, len(modelNames))
+	for _, name := range modelNames {
+		agent, err := New[Req](ctx, projectID, region, name, config)
+		if err != nil {
+			return nil, fmt.Errorf("failover model %s: %w", name, err)
+		}
+		agents = append(agents, agent)
+	}
+	return newFailoverAgent(modelNames, agents), nil
+}
+
+func newFailoverAgent[Req promptbuilder.Bindable, Resp, CB any](models []string, agents []Agent[Req, Resp, CB]) *failoverAgent[Req, Resp, CB] {
+	return &failoverAgent[Req, Resp, CB]{
+		models:  models,
+		agents:  agents,
+		metrics: metrics.NewGenAI("chainguard.ai.agents"),
+	}
+}
+
+// ShouldFailover reports whether err ends an attempt in a way a different
+// model could recover from: a retryable provider error that exhausted the
+// executor's retries. The executors mark those with retry.RequeueIfRetryable,
+// so the check is the workqueue infrastructure marker alone.
+func ShouldFailover(err error) bool {
+	return err != nil && workqueue.HasInfrastructureMarker(err)
+}
+
+// Execute implements Agent.
+func (a *failoverAgent[Req, Resp, CB]) Execute(ctx context.Context, request Req, callbacks CB) (Resp, error) {
+	var (
+		resp Resp
+		err  error
+	)
+	for i, agent := range a.agents {
+		resp, err = agent.Execute(attemptContext(ctx, a.models[i], i), request, callbacks)
+		if !ShouldFailover(err) || i == len(a.agents)-1 {
+			return resp, err
+		}
+		clog.WarnContext(ctx, "model exhausted retries, failing over",
+			"model", a.models[i], "next_model", a.models[i+1], "error", err)
+		a.metrics.RecordFailover(ctx, a.models[i], a.models[i+1])
+	}
+	return resp, err
+}
+
+// Resume implements Resumer. The envelope is bound to the provider and model
+// that parked it, so each model in the chain is offered it in order and the
+// first whose executor accepts it resumes the run. Config drift on every
+// model is returned as the last model's drift error.
+func (a *failoverAgent[Req, Resp, CB]) Resume(ctx context.Context, env checkpoint.Envelope, answers map[string]string, callbacks CB) (Resp, error) {
+	var zero Resp
+	err := fmt.Errorf("%w: no model in the failover chain can resume it", checkpoint.ErrConfigDrift)
+	for i, agent := range a.agents {
+		resumer, ok := AsResumer(agent)
+		if !ok {
+			continue
+		}
+		var resp Resp
+		resp, err = resumer.Resume(attemptContext(ctx, a.models[i], i), env, answers, callbacks)
+		if !errors.Is(err, checkpoint.ErrConfigDrift) {
+			return resp, err
+		}
+	}
+	return zero, err
+}
+
+// attemptContext stamps the failover labels for the attempt on ctx.
+func attemptContext(ctx context.Context, modelName string, attempt int) context.Context {
+	return agenttrace.WithExecutionContext(ctx, agenttrace.ExecutionContext{
+		Labels: map[string]string{
+			FailoverBackendLabel: string(model.Resolve(modelName).Backend),
+			FailoverAttemptLabel: strconv.Itoa(attempt),
+		},
+	})
+}
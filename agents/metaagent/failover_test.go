@@ -0,0 +1,139 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metaagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/checkpoint"
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeAgent returns a fixed outcome and records the failover labels each call
+// ran under.
+type fakeAgent struct {
+	resp   *testResponse
+	err    error
+	labels []map[string]string
+}
+
+func (f *fakeAgent) Execute(ctx context.Context, _ *testRequest, _ testCallbacks) (*testResponse, error) {
+	f.labels = append(f.labels, agenttrace.GetExecutionContext(ctx).Labels)
+	return f.resp, f.err
+}
+
+func (f *fakeAgent) Resume(ctx context.Context, _ checkpoint.Envelope, _ map[string]string, _ testCallbacks) (*testResponse, error) {
+	return f.Execute(ctx, nil, testCallbacks{})
+}
+
+// exhausted is the error shape an executor returns when a retryable provider
+// error exhausted its retries (retry.RequeueIfRetryable).
+var exhausted = workqueue.InfrastructureError(workqueue.RequeueAfter(5*time.Minute), errors.New("529 overloaded"))
+
+func TestFailoverExecute(t *testing.T) {
+	models := []string{"claude-sonnet-4-6", "gemini-3-pro-preview", "openai/gpt-oss-120b"}
+	tests := []struct {
+		name      string
+		outcomes  []error
+		wantCalls []int
+		wantErr   bool
+	}{{
+		name:      "primary serves",
+		outcomes:  []error{nil, nil, nil},
+		wantCalls: []int{1, 0, 0},
+	}, {
+		name:      "fails over past exhausted retries",
+		outcomes:  []error{exhausted, exhausted, nil},
+		wantCalls: []int{1, 1, 1},
+	}, {
+		name:      "non-retryable error is final",
+		outcomes:  []error{errors.New("invalid request"), nil, nil},
+		wantCalls: []int{1, 0, 0},
+		wantErr:   true,
+	}, {
+		name:      "suspension is final",
+		outcomes:  []error{&checkpoint.Suspension{}, nil, nil},
+		wantCalls: []int{1, 0, 0},
+		wantErr:   true,
+	}, {
+		name:      "chain exhausted",
+		outcomes:  []error{exhausted, exhausted, exhausted},
+		wantCalls: []int{1, 1, 1},
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakes := make([]*fakeAgent, len(models))
+			agents := make([]Agent[*testRequest, *testResponse, testCallbacks], len(models))
+			for i, err := range tt.outcomes {
+				fakes[i] = &fakeAgent{resp: &testResponse{}, err: err}
+				agents[i] = fakes[i]
+			}
+
+			_, err := newFailoverAgent(models, agents).Execute(t.Context(), &testRequest{}, testCallbacks{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for i, f := range fakes {
+				if len(f.labels) != tt.wantCalls[i] {
+					t.Errorf("%s calls: got = %d, want = %d", models[i], len(f.labels), tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFailoverLabels(t *testing.T) {
+	primary, fallback := &fakeAgent{err: exhausted}, &fakeAgent{resp: &testResponse{}}
+	agent := newFailoverAgent([]string{"claude-sonnet-4-6", "gemini-3-pro-preview"},
+		[]Agent[*testRequest, *testResponse, testCallbacks]{primary, fallback})
+
+	ctx := agenttrace.WithExecutionContext(t.Context(), agenttrace.ExecutionContext{Labels: map[string]string{"team": "x"}})
+	if _, err := agent.Execute(ctx, &testRequest{}, testCallbacks{}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := map[string]string{"team": "x", FailoverBackendLabel: "gemini", FailoverAttemptLabel: "1"}
+	if diff := cmp.Diff(want, fallback.labels[0]); diff != "" {
+		t.Errorf("fallback labels (-want +got):\n%s", diff)
+	}
+	if got := primary.labels[0][FailoverBackendLabel]; got != "claude" {
+		t.Errorf("primary backend label: got = %q, want = %q", got, "claude")
+	}
+}
+
+func TestFailoverResume(t *testing.T) {
+	drift := fmt.Errorf("%w: model mismatch", checkpoint.ErrConfigDrift)
+	parker := &fakeAgent{resp: &testResponse{}}
+	agent := newFailoverAgent([]string{"claude-sonnet-4-6", "gemini-3-pro-preview"},
+		[]Agent[*testRequest, *testResponse, testCallbacks]{&fakeAgent{err: drift}, parker})
+
+	if _, err := agent.Resume(t.Context(), checkpoint.Envelope{}, nil, testCallbacks{}); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if len(parker.labels) != 1 {
+		t.Errorf("parking model resumes: got = %d, want = 1", len(parker.labels))
+	}
+
+	// No model in the chain accepts the envelope.
+	agent = newFailoverAgent([]string{"claude-sonnet-4-6"},
+		[]Agent[*testRequest, *testResponse, testCallbacks]{&fakeAgent{err: drift}})
+	if _, err := agent.Resume(t.Context(), checkpoint.Envelope{}, nil, testCallbacks{}); !errors.Is(err, checkpoint.ErrConfigDrift) {
+		t.Errorf("Resume() error = %v, want checkpoint.ErrConfigDrift", err)
+	}
+}
+
+func TestNewFailoverRejectsEmptyChain(t *testing.T) {
+	if _, err := NewFailover[*testRequest](t.Context(), "p", "r", nil, suspendConfig(t)); err == nil {
+		t.Error("NewFailover() error = nil, want error")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strings"
 
+	"chainguard.dev/driftlessaf/agents/checkpoint"
 	"chainguard.dev/driftlessaf/agents/executor/googleexecutor"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/submitresult"
@@ -28,16 +29,6 @@ func newGoogleAgent[Req promptbuilder.Bindable, Resp, CB any](
 	projectID, region, model string,
 	config Config[Resp, CB],
 ) (Agent[Req, Resp, CB], error) {
-	// Suspend/resume is not wired for this backend yet: googleexecutor has no
-	// suspend tool option, so a set SuspendToolName would otherwise be silently
-	// dropped and the advertised pause lifecycle could never fire. Fail closed
-	// with a clear error (before any client is built) until the googleexecutor
-	// suspend/resume slice lands (DEV-2247). googleAgent likewise does not
-	// implement Resumer, so AsResumer reports false for agents built here.
-	if config.SuspendToolName != "" {
-		return nil, fmt.Errorf("suspend/resume (SuspendToolName %q) is not yet supported on the Gemini backend; it lands with the googleexecutor suspend/resume slice", config.SuspendToolName)
-	}
-
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
 		Project:  projectID,
 		Location: region,
@@ -58,7 +49,7 @@ func newGoogleAgent[Req promptbuilder.Bindable, Resp, CB any](
 	executorOpts := []googleexecutor.Option[Req, Resp]{
 		googleexecutor.WithModel[Req, Resp](model),
 		googleexecutor.WithTemperature[Req, Resp](0.2),
-		googleexecutor.WithMaxOutputTokens[Req, Resp](65536), // Gemini 2.5 Flash max output tokens
+		googleexecutor.WithMaxOutputTokens[Req, Resp](int32(outputTokens(model, 65536))), // Gemini 2.5 Flash max output tokens
 		googleexecutor.WithSubmitResultProvider[Req, Resp](func() (googletool.SubmitMetadata[Resp], error) { return submitTool, nil }),
 		googleexecutor.WithResourceLabels[Req, Resp](map[string]string{"projectID": projectID, "region": region, "model_name": strings.ToLower(model)}),
 	}
@@ -74,6 +65,18 @@ func newGoogleAgent[Req promptbuilder.Bindable, Resp, CB any](
 		executorOpts = append(executorOpts, googleexecutor.WithToolCallConcurrency[Req, Resp](config.ToolCallConcurrency))
 	}
 
+	if config.Compaction != nil {
+		executorOpts = append(executorOpts, googleexecutor.WithCompaction[Req, Resp](*config.Compaction))
+	}
+
+	if config.Budget != nil {
+		executorOpts = append(executorOpts, googleexecutor.WithBudget[Req, Resp](*config.Budget))
+	}
+
+	if config.Observer != nil {
+		executorOpts = append(executorOpts, googleexecutor.WithObserver[Req, Resp](config.Observer))
+	}
+
 	if config.SystemInstructions != nil {
 		executorOpts = append(executorOpts, googleexecutor.WithSystemInstructions[Req, Resp](config.SystemInstructions))
 	}
@@ -88,6 +91,20 @@ func newGoogleAgent[Req promptbuilder.Bindable, Resp, CB any](
 		executorOpts = append(executorOpts, googleexecutor.WithEffort[Req, Resp](config.Effort))
 	}
 
+	if config.SuspendToolName != "" {
+		name, desc := config.SuspendToolName, config.SuspendToolDescription
+		executorOpts = append(executorOpts, googleexecutor.WithSuspendTool[Req, Resp](func() (*genai.FunctionDeclaration, error) {
+			return &genai.FunctionDeclaration{
+				Name:        name,
+				Description: desc,
+				Parameters: &genai.Schema{
+					Type:       genai.TypeObject,
+					Properties: map[string]*genai.Schema{suspendQuestionProperty: {Type: genai.TypeString}},
+				},
+			}, nil
+		}))
+	}
+
 	executor, err := googleexecutor.New[Req, Resp](client, config.UserPrompt, executorOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating Google executor: %w", err)
@@ -107,3 +124,20 @@ func (a *googleAgent[Req, Resp, CB]) Execute(ctx context.Context, request Req, c
 	}
 	return a.executor.Execute(ctx, request, googletool.Map(tools))
 }
+
+// Resume implements Resumer by delegating to the concrete Gemini executor's
+// resume capability. As with claudeAgent.Resume, the executor built by
+// googleexecutor.New always satisfies googleexecutor.Resumer; the ok-check
+// guards against a non-resumable Interface implementation being injected.
+func (a *googleAgent[Req, Resp, CB]) Resume(ctx context.Context, env checkpoint.Envelope, answers map[string]string, callbacks CB) (Resp, error) {
+	var zero Resp
+	tools, err := a.config.Tools.Tools(ctx, callbacks)
+	if err != nil {
+		return zero, fmt.Errorf("building tools: %w", err)
+	}
+	resumer, ok := a.executor.(googleexecutor.Resumer[Req, Resp])
+	if !ok {
+		return zero, fmt.Errorf("google executor does not support resume")
+	}
+	return resumer.Resume(ctx, env, answers, googletool.Map(tools))
+}
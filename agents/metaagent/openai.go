@@ -7,28 +7,72 @@ package metaagent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"chainguard.dev/driftlessaf/agents/checkpoint"
 	"chainguard.dev/driftlessaf/agents/executor/openaiexecutor"
 	"chainguard.dev/driftlessaf/agents/promptbuilder"
 	"chainguard.dev/driftlessaf/agents/submitresult"
 	"chainguard.dev/driftlessaf/agents/toolcall/openaistool"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+// OpenAIBaseURL is the base URL of the first-party OpenAI API, for
+// OpenAIEndpoint.BaseURL.
+const OpenAIBaseURL = "https://api.openai.com/v1"
+
+// OpenAIEndpoint points the OpenAI-compatible backend at a chat completions
+// server other than Vertex AI: the first-party OpenAI API (OpenAIBaseURL), or
+// a self-hosted vLLM or llama.cpp server for offline development. See
+// Config.OpenAIEndpoint.
+type OpenAIEndpoint struct {
+	// BaseURL is the server's API root, including the version path (for
+	// example "http://localhost:8000/v1"). Required.
+	BaseURL string
+
+	// APIKey is sent as the bearer token. Empty falls back to the
+	// OPENAI_API_KEY environment variable, and to no key at all for servers
+	// that do not check one.
+	APIKey string
+
+	// HTTPClient, when non-nil, sends the requests — for example to add an
+	// authenticating transport in front of a self-hosted server. Nil uses the
+	// SDK's default client.
+	HTTPClient *http.Client
+}
+
+// client builds an OpenAI SDK client for the endpoint.
+func (ep *OpenAIEndpoint) client() (openai.Client, error) {
+	if ep.BaseURL == "" {
+		return openai.Client{}, errors.New("OpenAI endpoint base URL cannot be empty")
+	}
+	opts := []option.RequestOption{option.WithBaseURL(ep.BaseURL)}
+	if ep.APIKey != "" {
+		opts = append(opts, option.WithAPIKey(ep.APIKey))
+	}
+	if ep.HTTPClient != nil {
+		opts = append(opts, option.WithHTTPClient(ep.HTTPClient))
+	}
+	return openai.NewClient(opts...), nil
+}
+
 // openAICompatAgent implements Agent using the OpenAI-compatible API (e.g. Vertex AI partner models).
 type openAICompatAgent[Req promptbuilder.Bindable, Resp, CB any] struct {
 	executor openaiexecutor.Interface[Req, Resp]
 	config   Config[Resp, CB]
 }
 
-// newOpenAICompatAgent creates an agent using Vertex AI's OpenAI-compatible endpoint.
-// Model names use publisher/model format (e.g. "google/gemini-2.5-pro").
+// newOpenAICompatAgent creates an agent using Vertex AI's OpenAI-compatible
+// endpoint, where model names use publisher/model format (e.g.
+// "google/gemini-2.5-pro"), or the server config.OpenAIEndpoint names, which
+// takes whatever model names that server serves.
 func newOpenAICompatAgent[Req promptbuilder.Bindable, Resp, CB any](
 	ctx context.Context,
 	projectID, region, model string,
@@ -38,46 +82,19 @@ func newOpenAICompatAgent[Req promptbuilder.Bindable, Resp, CB any](
 	if config.UserPrompt == nil {
 		return nil, fmt.Errorf("creating OpenAI-compatible executor: prompt cannot be nil")
 	}
-	// Suspend/resume is not wired for this backend yet: openaiexecutor has no
-	// suspend tool option, so a set SuspendToolName would otherwise be silently
-	// dropped and the advertised pause lifecycle could never fire. Fail closed
-	// with a clear error until the openaiexecutor suspend/resume slice lands
-	// (DEV-2247). openAICompatAgent likewise does not implement Resumer, so
-	// AsResumer reports false for agents built here.
-	if config.SuspendToolName != "" {
-		return nil, fmt.Errorf("suspend/resume (SuspendToolName %q) is not yet supported on the OpenAI-compatible backend; it lands with the openaiexecutor suspend/resume slice", config.SuspendToolName)
-	}
 
-	// Use GCP Application Default Credentials for authentication.
-	// The oauth2 transport overwrites the Authorization header set by the OpenAI SDK,
-	// replacing the dummy API key with a real GCP access token on each request.
-	tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		return nil, fmt.Errorf("creating GCP token source: %w", err)
-	}
-
-	// The "global" region uses a different hostname than regional endpoints.
-	var baseURL string
-	if region == "global" {
-		baseURL = fmt.Sprintf(
-			"https://aiplatform.googleapis.com/v1beta1/projects/%s/locations/global/endpoints/openapi",
-			projectID,
-		)
+	var (
+		client openai.Client
+		err    error
+	)
+	if config.OpenAIEndpoint != nil {
+		client, err = config.OpenAIEndpoint.client()
 	} else {
-		baseURL = fmt.Sprintf(
-			"https://%s-aiplatform.googleapis.com/v1beta1/projects/%s/locations/%s/endpoints/openapi",
-			region, projectID, region,
-		)
+		client, err = vertexOpenAIClient(ctx, projectID, region)
+	}
+	if err != nil {
+		return nil, err
 	}
-
-	client := openai.NewClient(
-		option.WithBaseURL(baseURL),
-		// Provide a non-empty placeholder; the oauth2 transport replaces this with a real GCP token.
-		option.WithAPIKey("vertex-ai-auth"),
-		option.WithHTTPClient(&http.Client{
-			Transport: &oauth2.Transport{Source: tokenSource},
-		}),
-	)
 
 	// Build the terminal submit_result tool. The executor gates accepted
 	// submissions on the configured result validators before committing them
@@ -90,7 +107,7 @@ func newOpenAICompatAgent[Req promptbuilder.Bindable, Resp, CB any](
 	executorOpts := []openaiexecutor.Option[Req, Resp]{
 		openaiexecutor.WithModel[Req, Resp](model),
 		openaiexecutor.WithTemperature[Req, Resp](0.2),
-		openaiexecutor.WithMaxTokens[Req, Resp](32768),
+		openaiexecutor.WithMaxTokens[Req, Resp](outputTokens(model, 32768)),
 		openaiexecutor.WithSubmitResultProvider[Req, Resp](func() (openaistool.SubmitMetadata[Resp], error) { return submitTool, nil }),
 		openaiexecutor.WithResourceLabels[Req, Resp](map[string]string{
 			"projectID":  projectID,
@@ -110,6 +127,18 @@ func newOpenAICompatAgent[Req promptbuilder.Bindable, Resp, CB any](
 		executorOpts = append(executorOpts, openaiexecutor.WithToolCallConcurrency[Req, Resp](config.ToolCallConcurrency))
 	}
 
+	if config.Compaction != nil {
+		executorOpts = append(executorOpts, openaiexecutor.WithCompaction[Req, Resp](*config.Compaction))
+	}
+
+	if config.Budget != nil {
+		executorOpts = append(executorOpts, openaiexecutor.WithBudget[Req, Resp](*config.Budget))
+	}
+
+	if config.Observer != nil {
+		executorOpts = append(executorOpts, openaiexecutor.WithObserver[Req, Resp](config.Observer))
+	}
+
 	if config.SystemInstructions != nil {
 		executorOpts = append(executorOpts, openaiexecutor.WithSystemInstructions[Req, Resp](config.SystemInstructions))
 	}
@@ -125,6 +154,22 @@ func newOpenAICompatAgent[Req promptbuilder.Bindable, Resp, CB any](
 		executorOpts = append(executorOpts, openaiexecutor.WithEffort[Req, Resp](config.Effort))
 	}
 
+	if config.SuspendToolName != "" {
+		name, desc := config.SuspendToolName, config.SuspendToolDescription
+		executorOpts = append(executorOpts, openaiexecutor.WithSuspendTool[Req, Resp](func() (openai.ChatCompletionToolParam, error) {
+			return openai.ChatCompletionToolParam{
+				Function: shared.FunctionDefinitionParam{
+					Name:        name,
+					Description: openai.String(desc),
+					Parameters: shared.FunctionParameters{
+						"type":       "object",
+						"properties": map[string]any{suspendQuestionProperty: map[string]any{"type": "string"}},
+					},
+				},
+			}, nil
+		}))
+	}
+
 	exec, err := openaiexecutor.New[Req, Resp](client, config.UserPrompt, executorOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating OpenAI-compatible executor: %w", err)
@@ -144,3 +189,55 @@ func (a *openAICompatAgent[Req, Resp, CB]) Execute(ctx context.Context, request
 	}
 	return a.executor.Execute(ctx, request, openaistool.Map(tools))
 }
+
+// Resume implements Resumer by delegating to the concrete OpenAI-compatible
+// executor's resume capability. As with claudeAgent.Resume, the executor built
+// by openaiexecutor.New always satisfies openaiexecutor.Resumer; the ok-check
+// guards against a non-resumable Interface implementation being injected.
+func (a *openAICompatAgent[Req, Resp, CB]) Resume(ctx context.Context, env checkpoint.Envelope, answers map[string]string, callbacks CB) (Resp, error) {
+	var zero Resp
+	tools, err := a.config.Tools.Tools(ctx, callbacks)
+	if err != nil {
+		return zero, fmt.Errorf("building tools: %w", err)
+	}
+	resumer, ok := a.executor.(openaiexecutor.Resumer[Req, Resp])
+	if !ok {
+		return zero, fmt.Errorf("openai executor does not support resume")
+	}
+	return resumer.Resume(ctx, env, answers, openaistool.Map(tools))
+}
+
+// vertexOpenAIClient builds an OpenAI SDK client for Vertex AI's
+// OpenAI-compatible endpoint in the given project and region.
+func vertexOpenAIClient(ctx context.Context, projectID, region string) (openai.Client, error) {
+	// Use GCP Application Default Credentials for authentication.
+	// The oauth2 transport overwrites the Authorization header set by the OpenAI SDK,
+	// replacing the dummy API key with a real GCP access token on each request.
+	tokenSource, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return openai.Client{}, fmt.Errorf("creating GCP token source: %w", err)
+	}
+
+	// The "global" region uses a different hostname than regional endpoints.
+	var baseURL string
+	if region == "global" {
+		baseURL = fmt.Sprintf(
+			"https://aiplatform.googleapis.com/v1beta1/projects/%s/locations/global/endpoints/openapi",
+			projectID,
+		)
+	} else {
+		baseURL = fmt.Sprintf(
+			"https://%s-aiplatform.googleapis.com/v1beta1/projects/%s/locations/%s/endpoints/openapi",
+			region, projectID, region,
+		)
+	}
+
+	return openai.NewClient(
+		option.WithBaseURL(baseURL),
+		// Provide a non-empty placeholder; the oauth2 transport replaces this with a real GCP token.
+		option.WithAPIKey("vertex-ai-auth"),
+		option.WithHTTPClient(&http.Client{
+			Transport: &oauth2.Transport{Source: tokenSource},
+		}),
+	), nil
+}
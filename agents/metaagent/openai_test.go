@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metaagent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/model"
+	"chainguard.dev/driftlessaf/agents/promptbuilder"
+	"chainguard.dev/driftlessaf/agents/toolcall"
+)
+
+// TestOpenAIEndpoint points the OpenAI-compatible backend at a local server
+// and checks a model id New could not otherwise route reaches it with the
+// configured key, and that a registered reasoning model is sent without
+// temperature.
+func TestOpenAIEndpoint(t *testing.T) {
+	model.Register("test-endpoint-reasoner", model.Info{Backend: model.BackendOpenAICompat})
+
+	var (
+		mu       sync.Mutex
+		auth     []string
+		requests []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		mu.Lock()
+		auth = append(auth, r.Header.Get("Authorization"))
+		requests = append(requests, req)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+  "id": "chatcmpl-1",
+  "object": "chat.completion",
+  "created": 1,
+  "model": "local",
+  "choices": [{
+    "index": 0,
+    "finish_reason": "tool_calls",
+    "message": {
+      "role": "assistant",
+      "content": "",
+      "tool_calls": [
+        {"id": "call_submit", "type": "function", "function": {"name": "submit_result", "arguments": "{\"reasoning\":\"done\",\"result\":{}}"}}
+      ]
+    }
+  }],
+  "usage": {"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2}
+}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	userPrompt, err := promptbuilder.NewPrompt("payload")
+	if err != nil {
+		t.Fatalf("NewPrompt() error = %v", err)
+	}
+	config := Config[*testResponse, toolcall.EmptyTools]{
+		UserPrompt:     userPrompt,
+		Tools:          toolcall.NewEmptyToolsProvider[*testResponse](),
+		OpenAIEndpoint: &OpenAIEndpoint{BaseURL: srv.URL, APIKey: "local-key"},
+	}
+
+	for _, id := range []string{"qwen2.5-coder-7b", "test-endpoint-reasoner-1"} {
+		agent, err := New[*testRequest](t.Context(), "", "", id, config)
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", id, err)
+		}
+		if _, err := agent.Execute(t.Context(), &testRequest{}, toolcall.EmptyTools{}); err != nil {
+			t.Fatalf("Execute(%q) error = %v", id, err)
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("requests: got = %d, want = 2", len(requests))
+	}
+	for i, want := range []struct {
+		model       string
+		temperature bool
+	}{
+		{model: "qwen2.5-coder-7b", temperature: true},
+		{model: "test-endpoint-reasoner-1", temperature: false},
+	} {
+		if got := requests[i]["model"]; got != want.model {
+			t.Errorf("request %d model: got = %v, want = %q", i+1, got, want.model)
+		}
+		if _, got := requests[i]["temperature"]; got != want.temperature {
+			t.Errorf("request %d temperature sent: got = %v, want = %v", i+1, got, want.temperature)
+		}
+		if got, want := auth[i], "Bearer local-key"; got != want {
+			t.Errorf("request %d Authorization: got = %q, want = %q", i+1, got, want)
+		}
+	}
+}
+
+func TestOpenAIEndpointRequiresBaseURL(t *testing.T) {
+	userPrompt, err := promptbuilder.NewPrompt("payload")
+	if err != nil {
+		t.Fatalf("NewPrompt() error = %v", err)
+	}
+	config := Config[*testResponse, toolcall.EmptyTools]{
+		UserPrompt:     userPrompt,
+		Tools:          toolcall.NewEmptyToolsProvider[*testResponse](),
+		OpenAIEndpoint: &OpenAIEndpoint{},
+	}
+	if _, err := New[*testRequest](t.Context(), "", "", "local-model", config); err == nil {
+		t.Error("New() with an empty endpoint base URL: got = nil, want error")
+	}
+}
@@ -24,10 +24,10 @@ import (
 // unchanged. This mirrors claudeexecutor.Resumer, which is likewise kept off
 // the executor's exported Interface.
 //
-// Today only the Claude backend implements Resumer; the Gemini and
-// OpenAI-compatible backends gain it together with suspend support in their
-// executors (DEV-2247 follow-up slices), and until then AsResumer reports
-// false for agents built on them.
+// Every backend (Claude, Gemini, and OpenAI-compatible) implements Resumer,
+// each delegating to its executor's Resumer. An envelope resumes only on the
+// backend that parked it: the executors reject a Provider mismatch as
+// checkpoint.ErrConfigDrift.
 //
 // The answers map keys each pending tool-call ID (from
 // Envelope.PendingToolCalls[i].ID — never re-derived from the tool name) to the
@@ -8,7 +8,6 @@ package metaagent
 import (
 	"context"
 	"errors"
-	"strings"
 	"testing"
 
 	"chainguard.dev/driftlessaf/agents/checkpoint"
@@ -34,31 +33,43 @@ func suspendConfig(t *testing.T) Config[*testResponse, testCallbacks] {
 	}
 }
 
-// TestSuspendToolRejectedOnGeminiBackend pins the fail-closed contract for the
-// backend without executor suspend support: a set SuspendToolName must be a
-// clear construction error, never silently dropped — a run whose model was
-// promised an ask-a-friend tool that is never advertised could neither pause nor
-// be diagnosed.
-func TestSuspendToolRejectedOnGeminiBackend(t *testing.T) {
-	_, err := New[*testRequest](t.Context(), "test-project", "us-central1", "gemini-2.5-flash", suspendConfig(t))
-	if err == nil {
-		t.Fatal("New() with SuspendToolName on Gemini: got nil, want error")
-	}
-	if !strings.Contains(err.Error(), "not yet supported on the Gemini backend") {
-		t.Errorf("New() error = %v, want a clear not-yet-supported error", err)
+// TestGoogleAndOpenAIAgentsAreResumers proves the Gemini and
+// OpenAI-compatible backends construct with the suspend tool enabled — wiring
+// it to the executor, which validates it — and expose the Resumer capability
+// through AsResumer. Construction acquires Application Default Credentials,
+// so the test points GOOGLE_APPLICATION_CREDENTIALS at a hermetic fake key.
+func TestGoogleAndOpenAIAgentsAreResumers(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", fakeGoogleCredentials(t))
+
+	// gemini-* routes to the Gemini backend; publisher/model routes to the
+	// OpenAI-compatible backend.
+	for _, model := range []string{"gemini-2.5-flash", "google/gemini-2.5-pro"} {
+		t.Run(model, func(t *testing.T) {
+			agent, err := New[*testRequest](t.Context(), "test-project", "us-central1", model, suspendConfig(t))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, ok := AsResumer[*testRequest](agent); !ok {
+				t.Error("AsResumer() = false, want true")
+			}
+		})
 	}
 }
 
-// TestSuspendToolRejectedOnOpenAIBackend is the OpenAI-compatible twin of the
-// Gemini test above. The rejection fires before credential acquisition, so no
-// fake ADC is needed.
-func TestSuspendToolRejectedOnOpenAIBackend(t *testing.T) {
-	_, err := New[*testRequest](t.Context(), "test-project", "us-central1", "google/gemini-2.5-pro", suspendConfig(t))
-	if err == nil {
-		t.Fatal("New() with SuspendToolName on OpenAI-compat: got nil, want error")
-	}
-	if !strings.Contains(err.Error(), "not yet supported on the OpenAI-compatible backend") {
-		t.Errorf("New() error = %v, want a clear not-yet-supported error", err)
+// TestSuspendToolNameCollisionRejected pins that every backend hands the
+// suspend tool to its executor for validation: a suspend tool named after the
+// terminal submit tool is a construction error, never silently dropped.
+func TestSuspendToolNameCollisionRejected(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", fakeGoogleCredentials(t))
+
+	for _, model := range []string{"claude-test-model", "gemini-2.5-flash", "google/gemini-2.5-pro"} {
+		t.Run(model, func(t *testing.T) {
+			config := suspendConfig(t)
+			config.SuspendToolName = "submit_result"
+			if _, err := New[*testRequest](t.Context(), "test-project", "us-central1", model, config); err == nil {
+				t.Error("New() with a suspend tool named after the submit tool: got nil, want error")
+			}
+		})
 	}
 }
 
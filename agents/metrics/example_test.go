@@ -86,6 +86,20 @@ func ExampleGenAI_RecordAPIRequest() {
 	// API request metrics recorded
 }
 
+// ExampleGenAI_RecordFailover demonstrates counting a failover from one
+// model to the next after the first exhausted its retries.
+func ExampleGenAI_RecordFailover() {
+	ctx := context.Background()
+	m := metrics.NewGenAI("chainguard.ai.agents")
+
+	m.RecordFailover(ctx, "claude-sonnet-4-6", "gemini-3-pro-preview")
+
+	fmt.Println("Failover metrics recorded")
+
+	// Output:
+	// Failover metrics recorded
+}
+
 // ExampleGenAI_multipleModels demonstrates tracking metrics across different models.
 func ExampleGenAI_multipleModels() {
 	ctx := context.Background()
@@ -44,6 +44,9 @@ type GenAI struct {
 	// wire — and crucially carries the resource-label attributes
 	// (service_name, agent_name, model_name) that the GCP-side metric lacks.
 	apiRequests metric.Int64Counter
+	// failovers counts executions that a failover layer moved off one model
+	// onto the next after the first exhausted its retries.
+	failovers metric.Int64Counter
 }
 
 // newInstrument creates a metric instrument with graceful degradation: if
@@ -110,6 +113,11 @@ func NewGenAI(meterName string) *GenAI {
 			"Failed to create api requests counter, metrics will be disabled", meterName,
 			metric.WithDescription("LLM API request attempts, labeled by HTTP response code"),
 			metric.WithUnit("{requests}")),
+		failovers: newInstrument[metric.Int64Counter, metric.Int64CounterOption](meter.Int64Counter, noop.Int64Counter{},
+			"genai.agent.failovers",
+			"Failed to create failovers counter, metrics will be disabled", meterName,
+			metric.WithDescription("Agent executions moved to a fallback model after the previous one exhausted its retries"),
+			metric.WithUnit("{executions}")),
 	}
 }
 
@@ -228,3 +236,16 @@ func (m *GenAI) RecordTurns(ctx context.Context, model string, turns int, limitE
 		m.turnLimitExceeded.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
 	}
 }
+
+// RecordFailover counts an execution moving from fromModel, whose retryable
+// errors exhausted their retries, to toModel, the next model in a failover
+// chain. The served model itself shows up on the token, turn and request
+// metrics the next attempt records, under its own model dimension.
+func (m *GenAI) RecordFailover(ctx context.Context, fromModel, toModel string, attrs ...attribute.KeyValue) {
+	baseAttrs := recordAttrs(ctx, attrs,
+		attribute.String("model", fromModel),
+		attribute.String("gen_ai.request.model", fromModel),
+		attribute.String("failover_model", toModel))
+
+	m.failovers.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
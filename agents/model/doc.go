@@ -20,4 +20,24 @@ SPDX-License-Identifier: Apache-2.0
 // newest capability surface for its backend — a deliberate bias, so a
 // freshly released model works without a registry change and the exception
 // tables grow only when a model is verified to reject a parameter.
+//
+// # Declared models
+//
+// Register declares a model in code; Load, LoadFile and LoadEnv declare
+// models from a YAML or JSON document (see Config), so a deployment can add
+// a self-hosted model, correct a capability, or attach output caps and
+// token prices without a rebuild:
+//
+//	models:
+//	  local-reasoner:
+//	    backend: openai-compat
+//	    efforts: [low, medium, high]
+//	    maxOutputTokens: 32768
+//
+// Declarations take precedence over the built-in rules, the longest
+// matching prefix wins, and fields a loaded declaration leaves unset keep
+// their built-in value. The executors check their output-token limit
+// against Info.MaxOutputTokens, and reject an effort level a declared model
+// does not list; for ids only the built-in rules cover, an unsupported
+// effort is clamped or dropped with a warning instead.
 package model
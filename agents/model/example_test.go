@@ -7,6 +7,7 @@ package model_test
 
 import (
 	"fmt"
+	"strings"
 
 	"chainguard.dev/driftlessaf/agents/effort"
 	"chainguard.dev/driftlessaf/agents/model"
@@ -36,3 +37,44 @@ func ExampleInfo_SupportsEffort() {
 	// false
 	// true
 }
+
+func ExampleRegister() {
+	// A reasoning model served by a local OpenAI-compatible server: it takes
+	// reasoning_effort but rejects temperature.
+	model.Register("local-reasoner", model.Info{
+		Backend: model.BackendOpenAICompat,
+		Efforts: []effort.Level{effort.Low, effort.Medium, effort.High},
+	})
+
+	info := model.Resolve("local-reasoner-32b")
+	fmt.Println(info.Backend)
+	fmt.Println(info.SamplingParams)
+	fmt.Println(info.SupportsEffort(effort.High))
+	// Output:
+	// openai-compat
+	// false
+	// true
+}
+
+func ExampleLoad() {
+	// Declarations usually come from a file (LoadFile) or the environment
+	// (LoadEnv). Fields left out keep the built-in rules for the id.
+	err := model.Load(strings.NewReader(`
+models:
+  claude-example-5:
+    contextWindow: 1000000
+    price: {input: 3, output: 15}
+`))
+	if err != nil {
+		panic(err)
+	}
+
+	info := model.Resolve("claude-example-5@20260101")
+	fmt.Println(info.Backend)
+	fmt.Println(info.ContextWindow, info.MaxOutputTokens)
+	fmt.Println(info.Price.Input, info.Price.Output)
+	// Output:
+	// claude
+	// 1000000 128000
+	// 3 15
+}
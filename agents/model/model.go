@@ -60,6 +60,24 @@ type Info struct {
 	ExtendedThinkingBudget bool
 	// ThinkingControl is the Gemini thinking-knob generation the model takes.
 	ThinkingControl ThinkingControl
+	// ContextWindow is the model's input context size in tokens; zero means
+	// unknown.
+	ContextWindow int64
+	// MaxOutputTokens is the largest per-request output-token limit the
+	// provider accepts; zero means unknown, and the executors then skip the
+	// check.
+	MaxOutputTokens int64
+	// Price is the model's token pricing, or nil when unknown. The built-in
+	// rules carry no prices; declare them with Register or Load.
+	Price *Price
+}
+
+// Price is a model's token pricing in US dollars per million tokens.
+type Price struct {
+	Input      float64 `json:"input" yaml:"input"`
+	Output     float64 `json:"output" yaml:"output"`
+	CacheRead  float64 `json:"cacheRead" yaml:"cacheRead"`
+	CacheWrite float64 `json:"cacheWrite" yaml:"cacheWrite"`
 }
 
 // SupportsEffort reports whether the provider accepts the effort level
@@ -118,11 +136,30 @@ var preXHighEffortModelPrefixes = []string{
 	"claude-opus-4-6",
 }
 
-// Resolve returns the capability Info for the given model id. The backend is
-// determined from the id's routing shape, matching prefixes
-// case-insensitively; ids that match no known shape resolve to the zero Info
-// (BackendUnknown, no capabilities).
+// claudeMaxOutputTokens is the output-token ceiling of current Claude models
+// (Sonnet 5, Opus 4.6/4.7/4.8, Fable 5) on both the Vertex and
+// Anthropic-direct backends.
+const claudeMaxOutputTokens = 128000
+
+// geminiMaxOutputTokens is the output-token ceiling of current Gemini models
+// (Gemini 2.5 and 3.x Pro and Flash).
+const geminiMaxOutputTokens = 65536
+
+// Resolve returns the capability Info for the given model id. Ids declared
+// with Register or Load resolve to the declared Info, layered over the
+// built-in rules for anything the declaration leaves unset (see Registered);
+// otherwise the backend is determined from the id's routing shape, matching
+// prefixes case-insensitively, and ids that match no known shape resolve to
+// the zero Info (BackendUnknown, no capabilities).
 func Resolve(id string) Info {
+	if info, ok := Registered(id); ok {
+		return info
+	}
+	return builtin(id)
+}
+
+// builtin resolves id against the built-in rules alone.
+func builtin(id string) Info {
 	lower := strings.ToLower(id)
 	switch {
 	case strings.HasPrefix(lower, "gemini-"):
@@ -146,7 +183,7 @@ func Resolve(id string) Info {
 // exception-prefix tables match case-sensitively against the id as given,
 // mirroring how the Anthropic API matches model names.
 func claudeInfo(id string) Info {
-	info := Info{Backend: BackendClaude}
+	info := Info{Backend: BackendClaude, MaxOutputTokens: claudeMaxOutputTokens}
 	if !hasAnyPrefix(id, samplingParamsRemovedPrefixes) {
 		info.SamplingParams = true
 		info.ExtendedThinkingBudget = true
@@ -169,6 +206,7 @@ func geminiInfo(id string) Info {
 		Efforts:         slices.Clone(fullEfforts),
 		SamplingParams:  true,
 		ThinkingControl: geminiThinkingControl(id),
+		MaxOutputTokens: geminiMaxOutputTokens,
 	}
 }
 
@@ -22,27 +22,32 @@ func TestResolve(t *testing.T) {
 
 	claudeNoEffort := model.Info{
 		Backend:                model.BackendClaude,
+		MaxOutputTokens:        128000,
 		SamplingParams:         true,
 		ExtendedThinkingBudget: true,
 	}
 	claudePreXHigh := model.Info{
 		Backend:                model.BackendClaude,
+		MaxOutputTokens:        128000,
 		Efforts:                preXHigh,
 		SamplingParams:         true,
 		ExtendedThinkingBudget: true,
 	}
 	claudeAdaptive := model.Info{
-		Backend: model.BackendClaude,
-		Efforts: fullScale,
+		Backend:         model.BackendClaude,
+		MaxOutputTokens: 128000,
+		Efforts:         fullScale,
 	}
 	geminiBudget := model.Info{
 		Backend:         model.BackendGemini,
+		MaxOutputTokens: 65536,
 		Efforts:         fullScale,
 		SamplingParams:  true,
 		ThinkingControl: model.ThinkingControlBudget,
 	}
 	geminiLevel := model.Info{
 		Backend:         model.BackendGemini,
+		MaxOutputTokens: 65536,
 		Efforts:         fullScale,
 		SamplingParams:  true,
 		ThinkingControl: model.ThinkingControlLevel,
@@ -73,6 +78,7 @@ func TestResolve(t *testing.T) {
 		// back to the budget control.
 		{"CLAUDE-FABLE-5", model.Info{
 			Backend:                model.BackendClaude,
+			MaxOutputTokens:        128000,
 			Efforts:                fullScale,
 			SamplingParams:         true,
 			ExtendedThinkingBudget: true,
@@ -109,12 +115,14 @@ func TestResolve(t *testing.T) {
 		// until the id is verified to reject them.
 		{"claude-sonnet-6", model.Info{
 			Backend:                model.BackendClaude,
+			MaxOutputTokens:        128000,
 			Efforts:                fullScale,
 			SamplingParams:         true,
 			ExtendedThinkingBudget: true,
 		}},
 		{"claude-opus-5", model.Info{
 			Backend:                model.BackendClaude,
+			MaxOutputTokens:        128000,
 			Efforts:                fullScale,
 			SamplingParams:         true,
 			ExtendedThinkingBudget: true,
@@ -167,3 +175,42 @@ func TestInfoSupportsEffort(t *testing.T) {
 		})
 	}
 }
+
+func TestRegister(t *testing.T) {
+	reasoning := model.Info{
+		Backend: model.BackendOpenAICompat,
+		Efforts: []effort.Level{effort.Low, effort.Medium, effort.High},
+	}
+	chat := model.Info{
+		Backend:        model.BackendOpenAICompat,
+		SamplingParams: true,
+	}
+	model.Register("test-register-", chat)
+	model.Register("Test-Register-Reasoning", reasoning)
+	// A declared prefix overrides the built-in routing rules.
+	model.Register("test-override/", reasoning)
+
+	tests := []struct {
+		id   string
+		want model.Info
+	}{
+		{id: "test-register-chat-7b", want: chat},
+		{id: "test-register-reasoning-v2", want: reasoning},
+		{id: "TEST-REGISTER-REASONING", want: reasoning},
+		{id: "test-override/model", want: reasoning},
+		{id: "test-unregistered", want: model.Info{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, model.Resolve(tt.id)); diff != "" {
+				t.Errorf("Resolve(%q) mismatch (-want +got):\n%s", tt.id, diff)
+			}
+		})
+	}
+
+	// Resolve hands out copies: mutating one must not leak into the registry.
+	model.Resolve("test-register-reasoning").Efforts[0] = effort.Max
+	if got := model.Resolve("test-register-reasoning").Efforts[0]; got != effort.Low {
+		t.Errorf("Efforts[0] after mutation: got = %q, want = %q", got, effort.Low)
+	}
+}
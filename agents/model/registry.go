@@ -0,0 +1,253 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"chainguard.dev/driftlessaf/agents/effort"
+)
+
+const (
+	// FileEnv names the environment variable LoadEnv reads a model file path
+	// from.
+	FileEnv = "DRIFTLESSAF_MODELS_FILE"
+	// InlineEnv names the environment variable LoadEnv reads inline model
+	// declarations from, in the same YAML or JSON form as the file.
+	InlineEnv = "DRIFTLESSAF_MODELS"
+)
+
+// Spec declares model capabilities loaded from configuration. Every field is
+// optional: a field left unset keeps what the built-in rules resolve for the
+// id, so a file can add a price or an output cap to a known model without
+// restating its other capabilities.
+type Spec struct {
+	Backend                *Backend         `json:"backend,omitempty" yaml:"backend"`
+	Efforts                *[]effort.Level  `json:"efforts,omitempty" yaml:"efforts"`
+	SamplingParams         *bool            `json:"samplingParams,omitempty" yaml:"samplingParams"`
+	ExtendedThinkingBudget *bool            `json:"extendedThinkingBudget,omitempty" yaml:"extendedThinkingBudget"`
+	ThinkingControl        *ThinkingControl `json:"thinkingControl,omitempty" yaml:"thinkingControl"`
+	ContextWindow          *int64           `json:"contextWindow,omitempty" yaml:"contextWindow"`
+	MaxOutputTokens        *int64           `json:"maxOutputTokens,omitempty" yaml:"maxOutputTokens"`
+	Price                  *Price           `json:"price,omitempty" yaml:"price"`
+}
+
+// Config is the document Load reads: model declarations keyed by id prefix.
+//
+//	models:
+//	  claude-opus-4-8:
+//	    price: {input: 5, output: 25, cacheRead: 0.5, cacheWrite: 6.25}
+//	  local-reasoner:
+//	    backend: openai-compat
+//	    efforts: [low, medium, high]
+//	    contextWindow: 131072
+//	    maxOutputTokens: 32768
+//
+// JSON is accepted too, being a subset of YAML.
+type Config struct {
+	Models map[string]Spec `json:"models" yaml:"models"`
+}
+
+// registry holds the declared specs, keyed by lowercased id prefix.
+var registry struct {
+	sync.RWMutex
+	specs map[string]Spec
+}
+
+// Register declares the capabilities of the models whose ids start with
+// prefix (case-insensitively), for ids the built-in routing rules do not
+// cover or describe wrongly: a first-party OpenAI model, a model served by a
+// local vLLM or llama.cpp server, or a "publisher/model" id that rejects the
+// sampling parameters. A declared Info takes precedence over the built-in
+// rules, and the longest matching prefix wins. Registering a prefix again
+// replaces its declaration.
+//
+// Call Register during program initialization, before the executors that
+// consult Resolve are constructed. It is safe for concurrent use.
+func Register(prefix string, info Info) {
+	efforts := slices.Clone(info.Efforts)
+	spec := Spec{
+		Backend:                &info.Backend,
+		Efforts:                &efforts,
+		SamplingParams:         &info.SamplingParams,
+		ExtendedThinkingBudget: &info.ExtendedThinkingBudget,
+		ThinkingControl:        &info.ThinkingControl,
+		ContextWindow:          &info.ContextWindow,
+		MaxOutputTokens:        &info.MaxOutputTokens,
+	}
+	if info.Price != nil {
+		price := *info.Price
+		spec.Price = &price
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	setSpec(strings.ToLower(prefix), spec)
+}
+
+// setSpec records spec under the lowercased prefix. The caller holds the
+// registry lock.
+func setSpec(prefix string, spec Spec) {
+	if registry.specs == nil {
+		registry.specs = make(map[string]Spec)
+	}
+	registry.specs[prefix] = spec
+}
+
+// Registered returns the Info for id when a declaration made with Register
+// or Load matches it, and false otherwise. The longest matching prefix wins,
+// and fields its declaration leaves unset come from the built-in rules.
+func Registered(id string) (Info, bool) {
+	lower := strings.ToLower(id)
+	registry.RLock()
+	var (
+		best    Spec
+		bestLen = -1
+	)
+	for prefix, spec := range registry.specs {
+		if strings.HasPrefix(lower, prefix) && len(prefix) > bestLen {
+			best, bestLen = spec, len(prefix)
+		}
+	}
+	registry.RUnlock()
+	if bestLen < 0 {
+		return Info{}, false
+	}
+	return best.apply(builtin(id)), true
+}
+
+// apply overlays the fields s sets onto info.
+func (s Spec) apply(info Info) Info {
+	if s.Backend != nil {
+		info.Backend = *s.Backend
+	}
+	if s.Efforts != nil {
+		info.Efforts = slices.Clone(*s.Efforts)
+	}
+	if s.SamplingParams != nil {
+		info.SamplingParams = *s.SamplingParams
+	}
+	if s.ExtendedThinkingBudget != nil {
+		info.ExtendedThinkingBudget = *s.ExtendedThinkingBudget
+	}
+	if s.ThinkingControl != nil {
+		info.ThinkingControl = *s.ThinkingControl
+	}
+	if s.ContextWindow != nil {
+		info.ContextWindow = *s.ContextWindow
+	}
+	if s.MaxOutputTokens != nil {
+		info.MaxOutputTokens = *s.MaxOutputTokens
+	}
+	if s.Price != nil {
+		price := *s.Price
+		info.Price = &price
+	}
+	return info
+}
+
+// validate reports the first field of s that holds a value Resolve's callers
+// could not act on.
+func (s Spec) validate() error {
+	if s.Backend != nil {
+		switch *s.Backend {
+		case BackendClaude, BackendGemini, BackendOpenAICompat:
+		default:
+			return fmt.Errorf("unknown backend %q (want %q, %q or %q)", *s.Backend, BackendClaude, BackendGemini, BackendOpenAICompat)
+		}
+	}
+	if s.Efforts != nil {
+		for _, l := range *s.Efforts {
+			if err := l.Validate(); err != nil {
+				return fmt.Errorf("efforts: %w", err)
+			}
+		}
+	}
+	if s.ThinkingControl != nil {
+		switch *s.ThinkingControl {
+		case ThinkingControlNone, ThinkingControlBudget, ThinkingControlLevel:
+		default:
+			return fmt.Errorf("unknown thinkingControl %q (want %q or %q)", *s.ThinkingControl, ThinkingControlBudget, ThinkingControlLevel)
+		}
+	}
+	if s.ContextWindow != nil && *s.ContextWindow < 0 {
+		return fmt.Errorf("contextWindow must not be negative, got %d", *s.ContextWindow)
+	}
+	if s.MaxOutputTokens != nil && *s.MaxOutputTokens < 0 {
+		return fmt.Errorf("maxOutputTokens must not be negative, got %d", *s.MaxOutputTokens)
+	}
+	if p := s.Price; p != nil && (p.Input < 0 || p.Output < 0 || p.CacheRead < 0 || p.CacheWrite < 0) {
+		return fmt.Errorf("price must not be negative, got %+v", *p)
+	}
+	return nil
+}
+
+// Load reads a Config in YAML or JSON from r and declares each of its
+// models, as Register would but keeping the built-in capabilities for any
+// field a declaration leaves unset. Unknown fields and invalid values are
+// errors, and a document that fails validation declares nothing. Empty input
+// is not an error.
+func Load(r io.Reader) error {
+	var cfg Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("decoding model config: %w", err)
+	}
+	for prefix, spec := range cfg.Models {
+		if prefix == "" {
+			return errors.New("model config: empty model prefix")
+		}
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("model config %q: %w", prefix, err)
+		}
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for prefix, spec := range cfg.Models {
+		setSpec(strings.ToLower(prefix), spec)
+	}
+	return nil
+}
+
+// LoadFile calls Load on the contents of the named file.
+func LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening model config: %w", err)
+	}
+	defer f.Close()
+	if err := Load(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadEnv loads the model file named by $DRIFTLESSAF_MODELS_FILE and then the
+// inline declarations in $DRIFTLESSAF_MODELS, so a deployment can add or
+// correct models without a rebuild. Either variable may be unset; inline
+// declarations replace file declarations for the same prefix.
+func LoadEnv() error {
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := LoadFile(path); err != nil {
+			return err
+		}
+	}
+	if inline := os.Getenv(InlineEnv); inline != "" {
+		if err := Load(strings.NewReader(inline)); err != nil {
+			return fmt.Errorf("%s: %w", InlineEnv, err)
+		}
+	}
+	return nil
+}
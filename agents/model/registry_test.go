@@ -0,0 +1,158 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package model_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/effort"
+	"chainguard.dev/driftlessaf/agents/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	const config = `
+models:
+  # Overlays the built-in Claude rules: only the price and context window
+  # change.
+  claude-test-load:
+    contextWindow: 1000000
+    price: {input: 3, output: 15, cacheRead: 0.3, cacheWrite: 3.75}
+  test-load/reasoner:
+    backend: openai-compat
+    efforts: [low, high]
+    samplingParams: false
+    maxOutputTokens: 32768
+`
+	if err := model.Load(strings.NewReader(config)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		id   string
+		want model.Info
+	}{{
+		id: "claude-test-load-v1",
+		want: model.Info{
+			Backend:                model.BackendClaude,
+			Efforts:                []effort.Level{effort.Low, effort.Medium, effort.High, effort.XHigh, effort.Max},
+			SamplingParams:         true,
+			ExtendedThinkingBudget: true,
+			ContextWindow:          1000000,
+			MaxOutputTokens:        128000,
+			Price:                  &model.Price{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+		},
+	}, {
+		id: "Test-Load/Reasoner-70B",
+		want: model.Info{
+			Backend:         model.BackendOpenAICompat,
+			Efforts:         []effort.Level{effort.Low, effort.High},
+			MaxOutputTokens: 32768,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			info, ok := model.Registered(tt.id)
+			if !ok {
+				t.Fatalf("Registered(%q): not found", tt.id)
+			}
+			if diff := cmp.Diff(tt.want, info); diff != "" {
+				t.Errorf("Registered(%q) mismatch (-want +got):\n%s", tt.id, diff)
+			}
+			if diff := cmp.Diff(tt.want, model.Resolve(tt.id)); diff != "" {
+				t.Errorf("Resolve(%q) mismatch (-want +got):\n%s", tt.id, diff)
+			}
+		})
+	}
+
+	if _, ok := model.Registered("claude-fable-5"); ok {
+		t.Error("Registered(claude-fable-5): got a declaration, want none")
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	const config = `{"models": {"test-load-json": {"backend": "gemini", "thinkingControl": "level"}}}`
+	if err := model.Load(strings.NewReader(config)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	info := model.Resolve("test-load-json-1")
+	if info.Backend != model.BackendGemini || info.ThinkingControl != model.ThinkingControlLevel {
+		t.Errorf("Resolve: got = %+v, want a level-controlled Gemini model", info)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"unknown field", "models: {test-invalid-a: {maxTokens: 5}}", "maxTokens"},
+		{"unknown backend", "models: {test-invalid-b: {backend: bedrock}}", "unknown backend"},
+		{"unknown effort", "models: {test-invalid-c: {efforts: [turbo]}}", "efforts"},
+		{"unknown thinking control", "models: {test-invalid-d: {thinkingControl: dial}}", "thinkingControl"},
+		{"negative output cap", "models: {test-invalid-e: {maxOutputTokens: -1}}", "maxOutputTokens"},
+		{"negative price", "models: {test-invalid-f: {price: {input: -1}}}", "price"},
+		{"not a mapping", "models: [a, b]", "decoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := model.Load(strings.NewReader(tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load: got = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+
+	// A document with one invalid entry declares none of its entries.
+	const mixed = `
+models:
+  test-invalid-ok: {backend: openai-compat}
+  test-invalid-bad: {backend: bedrock}
+`
+	if err := model.Load(strings.NewReader(mixed)); err == nil {
+		t.Fatal("Load: got = nil, want an error")
+	}
+	if _, ok := model.Registered("test-invalid-ok"); ok {
+		t.Error("Registered(test-invalid-ok): a rejected document declared a model")
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	if err := model.Load(strings.NewReader("")); err != nil {
+		t.Errorf("Load(empty): %v", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	if err := os.WriteFile(path, []byte(`
+models:
+  test-env-:
+    backend: openai-compat
+    maxOutputTokens: 4096
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(model.FileEnv, path)
+	// The inline declaration is applied after the file, so it wins.
+	t.Setenv(model.InlineEnv, `{"models": {"test-env-": {"backend": "openai-compat", "maxOutputTokens": 8192}}}`)
+
+	if err := model.LoadEnv(); err != nil {
+		t.Fatalf("LoadEnv: %v", err)
+	}
+	if got := model.Resolve("test-env-model").MaxOutputTokens; got != 8192 {
+		t.Errorf("MaxOutputTokens: got = %d, want = 8192", got)
+	}
+
+	t.Setenv(model.FileEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := model.LoadEnv(); err == nil {
+		t.Error("LoadEnv with a missing file: got = nil, want an error")
+	}
+}
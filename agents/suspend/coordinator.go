@@ -64,9 +64,20 @@ type Coordinator struct {
 
 	// ValidateEnvelope, if set, is consulted on Wake against a parked envelope.
 	// Returning an error that Is(err, checkpoint.ErrConfigDrift) causes Wake to
-	// treat the checkpoint as unusable: it is deleted and WakeFresh is returned.
+	// treat the checkpoint as unusable: it is deleted and WakeFresh is returned
+	// (unless ContinueOnDrift is set).
 	ValidateEnvelope func(ctx context.Context, env *checkpoint.Envelope) error
 
+	// ContinueOnDrift opts into continuation mode: a parked envelope that
+	// ValidateEnvelope reports as drifted is no longer swept, but waits for
+	// its answer and is claimed like any other, with WakeResult.Drift set. The
+	// caller cannot Resume such an envelope on the new configuration — the
+	// provider state is the old provider's — and instead seeds a fresh run
+	// from it (see metaagent.Continue and the checkpoint/continuation
+	// package). An expired deadline still sweeps. Optional; false keeps the
+	// fail-closed default.
+	ContinueOnDrift bool
+
 	// now is the clock, injectable for tests. nil means time.Now.
 	now func() time.Time
 }
@@ -194,7 +205,8 @@ func (c *Coordinator) Wake(ctx context.Context, key string) (WakeDecision, *Wake
 	// paired with the dead envelope — a question posted by a Suspend racing in
 	// after the delete carries a fresh nonce, which the nonce-bound Consume
 	// below leaves untouched.
-	if c.envelopeDead(ctx, env) {
+	dead, drift := c.checkEnvelope(ctx, env)
+	if dead {
 		q, pending, perr := c.Questions.Pending(ctx, key)
 		if derr := c.Store.Delete(ctx, key, tok); derr != nil {
 			if errors.Is(derr, checkpoint.ErrTokenMismatch) {
@@ -251,24 +263,29 @@ func (c *Coordinator) Wake(ctx context.Context, key string) (WakeDecision, *Wake
 			"key", key, "question_id", ans.QuestionID, "error", cerr)
 	}
 
-	return WakeResume, &WakeResult{Envelope: env, Answer: ans, Token: tok}, nil
+	return WakeResume, &WakeResult{Envelope: env, Answer: ans, Token: tok, Drift: drift}, nil
 }
 
-// envelopeDead reports whether a parked envelope must not be resumed: its
+// checkEnvelope reports whether a parked envelope must not be resumed: its
 // deadline has passed, or the optional ValidateEnvelope hook signals config
-// drift. Every envelope Suspend parks carries a non-zero deadline (the
-// caller's, or the stamped DefaultParkDeadline), so the IsZero guard only
-// spares envelopes saved into the Store outside this coordinator.
-func (c *Coordinator) envelopeDead(ctx context.Context, env *checkpoint.Envelope) bool {
+// drift. Under ContinueOnDrift a drifted envelope is not dead; the drift error
+// is returned instead so Wake can surface it on the WakeResult. Every envelope
+// Suspend parks carries a non-zero deadline (the caller's, or the stamped
+// DefaultParkDeadline), so the IsZero guard only spares envelopes saved into
+// the Store outside this coordinator.
+func (c *Coordinator) checkEnvelope(ctx context.Context, env *checkpoint.Envelope) (dead bool, drift error) {
 	if !env.Deadline.IsZero() && c.clock().After(env.Deadline) {
-		return true
+		return true, nil
 	}
 	if c.ValidateEnvelope != nil {
 		if err := c.ValidateEnvelope(ctx, env); err != nil && errors.Is(err, checkpoint.ErrConfigDrift) {
-			return true
+			if c.ContinueOnDrift {
+				return false, err
+			}
+			return true, nil
 		}
 	}
-	return false
+	return false, nil
 }
 
 // newNonce returns a 128-bit random hex string for use as a per-pause question
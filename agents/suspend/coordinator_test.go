@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -643,6 +644,56 @@ func TestWakeConfigDrift(t *testing.T) {
 	}
 }
 
+// TestWakeContinueOnDrift proves continuation mode keeps a drifted envelope
+// parked until answered and then claims it with the drift surfaced, while an
+// expired deadline still sweeps.
+func TestWakeContinueOnDrift(t *testing.T) {
+	ctx := t.Context()
+	store, qs := memstore.New(), memquestions.New()
+	c := newCoord(t, store, qs)
+	c.ContinueOnDrift = true
+	c.ValidateEnvelope = func(_ context.Context, _ *checkpoint.Envelope) error {
+		return fmt.Errorf("model changed: %w", checkpoint.ErrConfigDrift)
+	}
+
+	if err := c.Suspend(ctx, testKey, newSuspension("proceed?")); !isRequeue(err) {
+		t.Fatalf("Suspend: %v", err)
+	}
+	// Unanswered: the drifted envelope waits instead of being swept.
+	if d, res, err := c.Wake(ctx, testKey); err != nil || d != suspend.WakeRearm || res != nil {
+		t.Fatalf("Wake(unanswered): got (%v,%v,%v), want (WakeRearm,nil,nil)", d, res, err)
+	}
+	if _, ok := qs.Provide(ctx, testKey, "go"); !ok {
+		t.Fatal("Provide failed")
+	}
+
+	d, res, err := c.Wake(ctx, testKey)
+	if err != nil || d != suspend.WakeResume {
+		t.Fatalf("Wake(answered): got (%v,%v), want (WakeResume,nil)", d, err)
+	}
+	if !errors.Is(res.Drift, checkpoint.ErrConfigDrift) {
+		t.Errorf("Drift: got = %v, want ErrConfigDrift", res.Drift)
+	}
+	if res.Answer.Text != "go" {
+		t.Errorf("Answer: got = %q, want = %q", res.Answer.Text, "go")
+	}
+	if _, _, found, _ := store.Load(ctx, testKey); found {
+		t.Fatal("Wake(answered): claimed envelope should be deleted")
+	}
+
+	// A drifted envelope past its deadline is still dead.
+	now := time.Now()
+	suspend.SetClock(c, func() time.Time { return now })
+	c.DefaultParkDeadline = time.Hour
+	if err := c.Suspend(ctx, testKey, newSuspension("proceed?")); !isRequeue(err) {
+		t.Fatalf("Suspend: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if d, res, err := c.Wake(ctx, testKey); err != nil || d != suspend.WakeFresh || res != nil {
+		t.Fatalf("Wake(expired): got (%v,%v,%v), want (WakeFresh,nil,nil)", d, res, err)
+	}
+}
+
 // TestWakeDeadSweepConsumeBestEffort proves question cleanup during a dead
 // sweep never blocks the sweep itself: a Pending or Consume failure is logged,
 // not returned — the sweep already committed at the CAS delete, so Wake still
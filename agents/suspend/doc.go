@@ -25,6 +25,13 @@ SPDX-License-Identifier: Apache-2.0
 //	             delete) and the question consumed; resume with the raw answer
 //	             (the executor's Resume owns framing).
 //
+// Config drift — a parked envelope whose provider or model no longer matches
+// the agent, as reported by the ValidateEnvelope hook — sweeps the envelope by
+// default. Setting ContinueOnDrift opts into continuation instead: the drifted
+// envelope waits for its answer and is claimed as WakeResume with
+// WakeResult.Drift set, and the caller seeds a fresh run on the new model from
+// its transcript (metaagent.Continue) rather than resuming it.
+//
 // checkpoint stays a pure primitive: it knows nothing about questions, nonces,
 // or the workqueue. All of that lives here.
 package suspend
@@ -126,4 +126,9 @@ type WakeResult struct {
 	// Token is the CAS handle that was used to claim (delete) the envelope,
 	// retained for telemetry and idempotency assertions.
 	Token checkpoint.Token
+	// Drift is the checkpoint.ErrConfigDrift error ValidateEnvelope reported
+	// for the envelope, set only under Coordinator.ContinueOnDrift. A non-nil
+	// Drift means the envelope cannot be resumed as-is: the caller continues
+	// it on the new configuration instead (see metaagent.Continue).
+	Drift error
 }
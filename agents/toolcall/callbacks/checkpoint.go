@@ -0,0 +1,307 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// StartCheckpoint is the ID of the implicit checkpoint every worktree starts
+// with: its state before any callback changed it.
+const StartCheckpoint = "start"
+
+const (
+	// diffContext is the number of context lines around each change in the
+	// diffs DiffChanges renders.
+	diffContext = 3
+
+	// maxDiffEdits bounds the edit distance the line diff searches for. A
+	// file that differs by more is rendered as a whole-file replacement,
+	// keeping memory bounded on rewrites.
+	maxDiffEdits = 4000
+)
+
+// CheckpointDiffResult is the result of a DiffSinceCheckpoint callback call.
+type CheckpointDiffResult struct {
+	// Files lists the paths that differ from the checkpoint, sorted.
+	Files []string
+
+	// Diff is the unified diff text for this page.
+	Diff string
+
+	// NextOffset is the byte offset to resume reading from.
+	// Nil when the entire diff has been returned.
+	NextOffset *int64
+
+	// Remaining is the number of bytes remaining after NextOffset.
+	Remaining int64
+}
+
+// FileVersion is one version of a file in a FileChange.
+type FileVersion struct {
+	// Exists is false for a file that is absent in this version.
+	Exists bool
+
+	// Data is the file content, or the link target of a symlink.
+	Data []byte
+
+	// Mode is the file mode; fs.ModeSymlink marks a symlink.
+	Mode fs.FileMode
+}
+
+// FileChange is a file that differs between two states of a worktree.
+type FileChange struct {
+	Path     string
+	Old, New FileVersion
+}
+
+// DiffChanges renders changes as a git-style unified diff, ordered by path,
+// and returns the page of it starting at byte offset; a negative limit
+// returns the rest. Implementations of DiffSinceCheckpoint share it so every
+// worktree reports its changes in the same form, one ApplyUnifiedDiff can
+// apply.
+func DiffChanges(changes []FileChange, offset int64, limit int) CheckpointDiffResult {
+	changes = slices.Clone(changes)
+	slices.SortFunc(changes, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+
+	var sb strings.Builder
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if d := unifiedDiff(c.Path, c.Old, c.New); d != "" {
+			files = append(files, c.Path)
+			sb.WriteString(d)
+		}
+	}
+	page := readPage([]byte(sb.String()), offset, limit)
+	return CheckpointDiffResult{
+		Files:      files,
+		Diff:       page.Content,
+		NextOffset: page.NextOffset,
+		Remaining:  page.Remaining,
+	}
+}
+
+// gitMode renders a file mode the way git records it.
+func gitMode(m fs.FileMode) string {
+	switch {
+	case m&fs.ModeSymlink != 0:
+		return "120000"
+	case m.Perm()&0o111 != 0:
+		return "100755"
+	default:
+		return "100644"
+	}
+}
+
+// unifiedDiff renders the diff of one file, or "" when the versions do not
+// differ.
+func unifiedDiff(path string, old, new FileVersion) string {
+	sameData := old.Exists == new.Exists && bytes.Equal(old.Data, new.Data)
+	if sameData && (!old.Exists || gitMode(old.Mode) == gitMode(new.Mode)) {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", path, path)
+	oldName, newName := "a/"+path, "b/"+path
+	switch {
+	case !old.Exists:
+		fmt.Fprintf(&sb, "new file mode %s\n", gitMode(new.Mode))
+		oldName = "/dev/null"
+	case !new.Exists:
+		fmt.Fprintf(&sb, "deleted file mode %s\n", gitMode(old.Mode))
+		newName = "/dev/null"
+	case gitMode(old.Mode) != gitMode(new.Mode):
+		fmt.Fprintf(&sb, "old mode %s\nnew mode %s\n", gitMode(old.Mode), gitMode(new.Mode))
+	}
+	if sameData || (len(old.Data) == 0 && len(new.Data) == 0) {
+		return sb.String()
+	}
+	if bytes.IndexByte(old.Data, 0) >= 0 || bytes.IndexByte(new.Data, 0) >= 0 {
+		fmt.Fprintf(&sb, "Binary files %s and %s differ\n", oldName, newName)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	writeHunks(&sb, diffLines(terminatedLines(old.Data), terminatedLines(new.Data)))
+	return sb.String()
+}
+
+// terminatedLines splits data into lines that keep their "\n", so a final
+// line without one differs from the same text with one.
+func terminatedLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeHunks writes the hunks of edits, each change surrounded by
+// diffContext lines and hunks merged when their context would overlap.
+func writeHunks(sb *strings.Builder, edits []hunkLine) {
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		// edits[i] is the first change of a hunk; extend it over every later
+		// change close enough to share context.
+		start := max(i-diffContext, 0)
+		end := i + 1 // one past the hunk's last change
+		for j := end; j < len(edits) && j-end <= 2*diffContext; j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			}
+		}
+		end = min(end+diffContext, len(edits))
+
+		oldLine, newLine := lineNumbers(edits[:start])
+		oldCount, newCount := 0, 0
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount > 0 {
+			oldLine++
+		}
+		if newCount > 0 {
+			newLine++
+		}
+		fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.op)
+			sb.WriteString(strings.TrimSuffix(e.text, "\n"))
+			sb.WriteByte('\n')
+			if !strings.HasSuffix(e.text, "\n") {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+}
+
+// lineNumbers counts the old and new lines edits cover.
+func lineNumbers(edits []hunkLine) (oldLines, newLines int) {
+	for _, e := range edits {
+		if e.op != '+' {
+			oldLines++
+		}
+		if e.op != '-' {
+			newLines++
+		}
+	}
+	return oldLines, newLines
+}
+
+// diffLines returns an edit script turning a into b, as hunk lines.
+func diffLines(a, b []string) []hunkLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]hunkLine, 0, len(a)+len(b)-prefix-suffix)
+	for _, l := range a[:prefix] {
+		edits = append(edits, hunkLine{op: ' ', text: l})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		edits = append(edits, hunkLine{op: ' ', text: l})
+	}
+	return edits
+}
+
+// myers finds a shortest edit script from a to b with Myers' algorithm,
+// falling back to deleting all of a and inserting all of b once the edit
+// distance exceeds maxDiffEdits.
+func myers(a, b []string) []hunkLine {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1) // v[off+k] is the furthest x reached on diagonal k
+	// trace[d] holds v for diagonals -d..d as step d began.
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		if d > maxDiffEdits {
+			return replaceLines(a, b)
+		}
+		trace = append(trace, slices.Clone(v[off-d:off+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1] // down: insert b[y]
+			} else {
+				x = v[off+k-1] + 1 // right: delete a[x]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replaceLines(a, b)
+}
+
+// backtrack recovers the edit script from the trace of myers.
+func backtrack(trace [][]int, a, b []string) []hunkLine {
+	x, y := len(a), len(b)
+	var edits []hunkLine
+	for d := len(trace) - 1; d > 0; d-- {
+		at := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, hunkLine{op: ' ', text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, hunkLine{op: '+', text: b[y-1]})
+		} else {
+			edits = append(edits, hunkLine{op: '-', text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for ; x > 0; x-- {
+		edits = append(edits, hunkLine{op: ' ', text: a[x-1]})
+	}
+	slices.Reverse(edits)
+	return edits
+}
+
+// replaceLines is the edit script that deletes all of a and inserts all of
+// b.
+func replaceLines(a, b []string) []hunkLine {
+	edits := make([]hunkLine, 0, len(a)+len(b))
+	for _, l := range a {
+		edits = append(edits, hunkLine{op: '-', text: l})
+	}
+	for _, l := range b {
+		edits = append(edits, hunkLine{op: '+', text: l})
+	}
+	return edits
+}
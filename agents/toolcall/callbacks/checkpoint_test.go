@@ -0,0 +1,198 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+// memTarget is an in-memory PatchTarget.
+type memTarget map[string]callbacks.FileVersion
+
+func (m memTarget) ReadFile(path string) ([]byte, fs.FileMode, error) {
+	v, ok := m[path]
+	if !ok {
+		return nil, 0, fs.ErrNotExist
+	}
+	return v.Data, v.Mode, nil
+}
+
+func (m memTarget) WriteFile(path string, data []byte, mode fs.FileMode) error {
+	m[path] = callbacks.FileVersion{Exists: true, Data: data, Mode: mode}
+	return nil
+}
+
+func (m memTarget) Remove(path string) error {
+	if _, ok := m[path]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(m, path)
+	return nil
+}
+
+// TestDiffChangesRoundTrip checks the rendered diff applies back: patching
+// the old version of every file yields the new one.
+func TestDiffChangesRoundTrip(t *testing.T) {
+	var long []string
+	for i := range 40 {
+		long = append(long, fmt.Sprintf("line %d", i))
+	}
+	edited := slices.Clone(long)
+	edited[2] = "changed near the top"
+	edited = slices.Insert(edited, 20, "inserted in the middle")
+	edited = slices.Delete(edited, 35, 37)
+
+	file := func(s string) callbacks.FileVersion {
+		return callbacks.FileVersion{Exists: true, Data: []byte(s), Mode: 0o644}
+	}
+	for _, tc := range []struct {
+		name     string
+		old, new callbacks.FileVersion
+	}{
+		{"several hunks", file(strings.Join(long, "\n") + "\n"), file(strings.Join(edited, "\n") + "\n")},
+		{"adds final newline", file("a\nb"), file("a\nb\n")},
+		{"drops final newline", file("a\nb\n"), file("a\nc")},
+		{"created", callbacks.FileVersion{}, file("new\n")},
+		{"deleted", file("old\n"), callbacks.FileVersion{}},
+		{"emptied", file("x\ny\n"), file("")},
+		{"mode only", file("same\n"), callbacks.FileVersion{Exists: true, Data: []byte("same\n"), Mode: 0o755}},
+		{"rewritten", file("a\nb\nc\n"), file("x\ny\n")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := callbacks.DiffChanges([]callbacks.FileChange{{Path: "f.txt", Old: tc.old, New: tc.new}}, 0, -1)
+			if !slices.Equal(res.Files, []string{"f.txt"}) || res.NextOffset != nil {
+				t.Fatalf("result: got = %+v, want the whole diff of f.txt", res)
+			}
+
+			target := memTarget{}
+			if tc.old.Exists {
+				target["f.txt"] = tc.old
+			}
+			applied, err := callbacks.ApplyUnifiedDiff(target, res.Diff)
+			if err != nil || !applied.Applied {
+				t.Fatalf("ApplyUnifiedDiff(%q): got = %+v, %v", res.Diff, applied, err)
+			}
+			got, ok := target["f.txt"]
+			if ok != tc.new.Exists || string(got.Data) != string(tc.new.Data) || (ok && got.Mode.Perm() != tc.new.Mode.Perm()) {
+				t.Errorf("round trip of %q: got = %+v (exists %v), want = %+v", res.Diff, got, ok, tc.new)
+			}
+		})
+	}
+}
+
+func TestDiffChangesPaginates(t *testing.T) {
+	changes := []callbacks.FileChange{
+		{Path: "b.txt", Old: callbacks.FileVersion{}, New: callbacks.FileVersion{Exists: true, Data: []byte("b\n"), Mode: 0o644}},
+		{Path: "a.txt", Old: callbacks.FileVersion{}, New: callbacks.FileVersion{Exists: true, Data: []byte("a\n"), Mode: 0o644}},
+		{Path: "same.txt", Old: callbacks.FileVersion{Exists: true, Data: []byte("s\n")}, New: callbacks.FileVersion{Exists: true, Data: []byte("s\n")}},
+	}
+	whole := callbacks.DiffChanges(changes, 0, -1)
+	if !slices.Equal(whole.Files, []string{"a.txt", "b.txt"}) || strings.Index(whole.Diff, "a.txt") > strings.Index(whole.Diff, "b.txt") {
+		t.Fatalf("whole diff: got = %+v, want a.txt then b.txt and no unchanged file", whole)
+	}
+	first := callbacks.DiffChanges(changes, 0, 10)
+	if first.Diff != whole.Diff[:10] || first.NextOffset == nil || first.Remaining != int64(len(whole.Diff)-10) {
+		t.Fatalf("first page: got = %+v", first)
+	}
+	rest := callbacks.DiffChanges(changes, *first.NextOffset, -1)
+	if first.Diff+rest.Diff != whole.Diff || rest.NextOffset != nil {
+		t.Errorf("pages: got = %q + %q, want = %q", first.Diff, rest.Diff, whole.Diff)
+	}
+}
+
+func TestLocalWorktreeCheckpoints(t *testing.T) {
+	r := openTestRoot(t, map[string]string{"keep.txt": "keep\n", "edit.txt": "one\ntwo\n", "gone.txt": "bye\n"})
+	cb := callbacks.LocalWorktree(r)
+	ctx := t.Context()
+
+	read := func(path string) string {
+		t.Helper()
+		data, err := r.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "<absent>"
+		} else if err != nil {
+			t.Fatalf("ReadFile(%s): %v", path, err)
+		}
+		return string(data)
+	}
+
+	if _, err := cb.EditFile(ctx, "edit.txt", "two", "2", false); err != nil {
+		t.Fatalf("EditFile: %v", err)
+	}
+	cp, err := cb.CreateCheckpoint(ctx)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+	if err := cb.WriteFile(ctx, "dir/new.txt", "new\n", 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := cb.DeleteFile(ctx, "gone.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := cb.EditFile(ctx, "edit.txt", "one", "1", false); err != nil {
+		t.Fatalf("EditFile: %v", err)
+	}
+	later, err := cb.CreateCheckpoint(ctx)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+
+	diff, err := cb.DiffSinceCheckpoint(ctx, cp, 0, -1)
+	if err != nil {
+		t.Fatalf("DiffSinceCheckpoint: %v", err)
+	}
+	if want := []string{"dir/new.txt", "edit.txt", "gone.txt"}; !slices.Equal(diff.Files, want) {
+		t.Errorf("files since %s: got = %q, want = %q", cp, diff.Files, want)
+	}
+	if !strings.Contains(diff.Diff, "-one\n+1\n 2\n") {
+		t.Errorf("diff since %s: got = %q, want the second edit only", cp, diff.Diff)
+	}
+
+	restored, err := cb.RestoreCheckpoint(ctx, cp)
+	if err != nil {
+		t.Fatalf("RestoreCheckpoint: %v", err)
+	}
+	if want := []string{"dir/new.txt", "edit.txt", "gone.txt"}; !slices.Equal(restored, want) {
+		t.Errorf("restored: got = %q, want = %q", restored, want)
+	}
+	if got := read("edit.txt") + read("gone.txt") + read("dir/new.txt"); got != "one\n2\nbye\n<absent>" {
+		t.Errorf("after restoring %s: got = %q", cp, got)
+	}
+	if _, err := cb.DiffSinceCheckpoint(ctx, later, 0, -1); err == nil {
+		t.Errorf("DiffSinceCheckpoint(%s) after restoring an earlier checkpoint: got = nil, want an error", later)
+	}
+
+	// The restored checkpoint keeps working, and the start undoes everything.
+	if err := cb.Chmod(ctx, "keep.txt", 0o755); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	diff, err = cb.DiffSinceCheckpoint(ctx, callbacks.StartCheckpoint, 0, -1)
+	if err != nil {
+		t.Fatalf("DiffSinceCheckpoint(start): %v", err)
+	}
+	if want := []string{"edit.txt", "keep.txt"}; !slices.Equal(diff.Files, want) {
+		t.Errorf("files since start: got = %q, want = %q", diff.Files, want)
+	}
+	if _, err := cb.RestoreCheckpoint(ctx, callbacks.StartCheckpoint); err != nil {
+		t.Fatalf("RestoreCheckpoint(start): %v", err)
+	}
+	if got := read("edit.txt"); got != "one\ntwo\n" {
+		t.Errorf("edit.txt after restoring the start: got = %q", got)
+	}
+	if info, err := r.Stat("keep.txt"); err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("keep.txt mode after restoring the start: got = %v, %v, want 0644", info.Mode(), err)
+	}
+	if _, err := cb.RestoreCheckpoint(ctx, "cp-missing"); err == nil {
+		t.Error("RestoreCheckpoint of an unknown checkpoint: got = nil, want an error")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import "context"
+
+// SymbolQuery selects the symbols a code-navigation callback works on,
+// either by name or by the position of an identifier in a file.
+type SymbolQuery struct {
+	// Name is the symbol as it is written in code: "Name" for a package-level
+	// declaration, "Type.Member" for a method or field, and either may be
+	// qualified by a package name ("pkg.Name", "pkg.Type.Member").
+	Name string
+
+	// Package restricts a Name query to the package in this directory,
+	// relative to the worktree root. A trailing "/..." includes the
+	// directories below it. Empty searches every package.
+	Package string
+
+	// Path selects the identifier at a position in this file, relative to
+	// the worktree root, instead of a Name.
+	Path string
+
+	// Offset is the byte offset of the identifier in Path. Used when Line is
+	// zero.
+	Offset int64
+
+	// Line and Column are the 1-based line and byte column of the identifier
+	// in Path. Without a Column, the first identifier on Line that refers to
+	// a symbol is used.
+	Line, Column int
+}
+
+// Symbol describes a declared symbol.
+type Symbol struct {
+	// Name is the symbol's name, qualified by its receiver or struct type for
+	// methods and fields ("Type.Member").
+	Name string
+
+	// Kind is "func", "method", "type", "var", "const", "field", "package"
+	// or "builtin".
+	Kind string
+
+	// Package is the import path of the package declaring the symbol.
+	Package string
+
+	// Path, Line and Column locate the declaration. Path is empty for
+	// symbols declared outside the worktree.
+	Path         string
+	Line, Column int
+
+	// Signature is the declaration without its body, such as a function
+	// signature or a type definition.
+	Signature string
+
+	// Doc is the symbol's doc comment.
+	Doc string
+}
+
+// Reference is a use of a symbol.
+type Reference struct {
+	// Path, Line and Column locate the use.
+	Path         string
+	Line, Column int
+
+	// Text is the source line containing the use, trimmed of surrounding
+	// whitespace.
+	Text string
+}
+
+// ReferenceResult is the result of a FindReferences callback call.
+type ReferenceResult struct {
+	// Symbols are the symbols the query matched.
+	Symbols []Symbol
+
+	// References is the page of uses of Symbols, ordered by position.
+	References []Reference
+
+	// NextOffset is the item offset to resume listing from.
+	// Nil when there are no more references.
+	NextOffset *int
+
+	// Total is the total number of references.
+	Total int
+}
+
+// TypeInfo describes the type of a symbol.
+type TypeInfo struct {
+	// Symbol is the symbol described.
+	Symbol Symbol
+
+	// Type is the symbol's type; for a type declaration, the type itself.
+	Type string
+
+	// Underlying is the underlying type, when it differs from Type.
+	Underlying string
+
+	// Fields lists the fields of a struct type, including embedded ones.
+	Fields []string
+
+	// Methods lists the method set of the type, or of a pointer to it,
+	// including promoted methods.
+	Methods []string
+}
+
+// CodeCallbacks provides language-aware navigation of the code in a
+// worktree: where symbols are declared, where they are used, what a package
+// declares and what type an identifier has.
+type CodeCallbacks struct {
+	// FindDefinition returns the declarations the query matches.
+	FindDefinition func(ctx context.Context, q SymbolQuery) ([]Symbol, error)
+
+	// FindReferences returns the uses of the symbols the query matches.
+	// Results are paginated by offset and limit. A negative offset is
+	// invalid and must return an error.
+	FindReferences func(ctx context.Context, q SymbolQuery, offset, limit int) (ReferenceResult, error)
+
+	// ListPackageSymbols lists the package-level declarations, and their
+	// methods, of the package in dir, relative to the worktree root. Only
+	// exported symbols are listed unless includeUnexported is set.
+	ListPackageSymbols func(ctx context.Context, dir string, includeUnexported bool) ([]Symbol, error)
+
+	// GetTypeInfo describes the types of the symbols the query matches.
+	GetTypeInfo func(ctx context.Context, q SymbolQuery) ([]TypeInfo, error)
+}
@@ -28,6 +28,55 @@ WorktreeCallbacks provides file operations on a git worktree:
 		// ... other callbacks (DeleteFile, MoveFile, CopyFile, etc.)
 	}
 
+ApplyPatch takes a unified diff spanning any number of files. ApplyUnifiedDiff
+is the shared implementation behind LocalWorktree and clonemanager: it places
+hunks with patch(1)-style offsets, whitespace tolerance and fuzz, writes
+nothing unless every hunk applies, and reports each hunk's outcome in a
+PatchResult so a model can correct the ones that failed.
+
+CreateCheckpoint, DiffSinceCheckpoint and RestoreCheckpoint let an agent
+back out of an approach that did not work, and let a reconciler review the
+run's changes before committing them: the diff since StartCheckpoint is every
+change made so far. LocalWorktree keeps a copy-on-write journal of the files
+its callbacks change; DiffChanges renders the diff for any implementation.
+
+# Exec Callbacks
+
+ExecCallbacks runs commands such as builds, tests and linters in a worktree,
+with paginated output:
+
+	cb := callbacks.ExecCallbacks{
+		RunCommand: func(ctx context.Context, command string, args []string, dir string, timeout time.Duration, limit int) (ExecResult, error) {
+			// Run an allow-listed command and return the first page of output
+		},
+		ReadOutput: func(ctx context.Context, id string, offset int64, limit int) (ReadResult, error) {
+			// Return a later page of a retained command output
+		},
+	}
+
+LocalExec is a reference implementation for an os.Root: it runs the commands
+an ExecConfig allow-lists, with a timeout, a scrubbed environment, bounded
+output and, on Linux, resource limits and optional namespaces.
+
+# Code Callbacks
+
+CodeCallbacks answers questions about the code in a worktree that grep
+cannot: where a symbol is declared, every place it is used, what a package
+declares and what type an identifier has. Symbols are selected by a
+SymbolQuery, either by name or by a file position:
+
+	cb := callbacks.CodeCallbacks{
+		FindDefinition: func(ctx context.Context, q SymbolQuery) ([]Symbol, error) {
+			// Resolve q.Name, or the identifier at q.Path:q.Line:q.Column
+		},
+		FindReferences: func(ctx context.Context, q SymbolQuery, offset, limit int) (ReferenceResult, error) {
+			// Return a page of the uses of the symbols q matches
+		},
+		// ... ListPackageSymbols, GetTypeInfo
+	}
+
+The gocode package implements them for Go with go/types.
+
 # Finding Callbacks
 
 FindingCallbacks provides access to CI failure information:
@@ -73,6 +122,15 @@ HistoryCallbacks provides access to commit history and file diffs:
 		GetFileDiff: func(ctx context.Context, path, start, end string, offset int64, limit int) (FileDiffResult, error) {
 			// Return paginated unified diff for a file over a commit range
 		},
+		Log: func(ctx context.Context, q LogQuery, offset, limit int) (LogResult, error) {
+			// Search older history by path and message pattern
+		},
+		// ... Blame, ShowFile
 	}
+
+Blame, Log and ShowFile look past the base ref, for diagnosing regressions:
+who last changed a line, which commits touched a path or mention an issue,
+and what a file looked like before. They are optional. A LogResult is
+Truncated when a shallow clone ran out of history before the search did.
 */
 package callbacks
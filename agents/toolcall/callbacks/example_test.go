@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
 )
@@ -221,6 +222,41 @@ func ExampleHistoryCallbacks() {
 	// Diff length: 38 bytes
 }
 
+// ExampleExecCallbacks demonstrates how to use ExecCallbacks to run a
+// command and page through its output.
+func ExampleExecCallbacks() {
+	ctx := context.Background()
+	output := "--- FAIL: TestParse\nFAIL\n"
+
+	cb := callbacks.ExecCallbacks{
+		RunCommand: func(ctx context.Context, command string, args []string, dir string, timeout time.Duration, limit int) (callbacks.ExecResult, error) {
+			next := int64(limit)
+			return callbacks.ExecResult{
+				ExitCode:   1,
+				Output:     output[:limit],
+				OutputID:   "out-1",
+				NextOffset: &next,
+				Remaining:  int64(len(output) - limit),
+			}, nil
+		},
+		ReadOutput: func(ctx context.Context, id string, offset int64, limit int) (callbacks.ReadResult, error) {
+			return callbacks.ReadResult{Content: output[offset:]}, nil
+		},
+	}
+
+	result, _ := cb.RunCommand(ctx, "go", []string{"test", "./..."}, "", 0, 10)
+	fmt.Printf("Exit code: %d\n", result.ExitCode)
+	fmt.Printf("First page: %q\n", result.Output)
+
+	rest, _ := cb.ReadOutput(ctx, result.OutputID, *result.NextOffset, 100)
+	fmt.Printf("Rest: %q\n", rest.Content)
+
+	// Output:
+	// Exit code: 1
+	// First page: "--- FAIL: "
+	// Rest: "TestParse\nFAIL\n"
+}
+
 // ExampleFindingKind demonstrates the FindingKind constants.
 func ExampleFindingKind() {
 	ciCheck := callbacks.FindingKindCICheck
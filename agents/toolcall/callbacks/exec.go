@@ -0,0 +1,62 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"context"
+	"time"
+)
+
+// ExecResult is the result of a RunCommand callback call.
+type ExecResult struct {
+	// ExitCode is the command's exit status, or -1 when it was killed by a
+	// signal (including on timeout).
+	ExitCode int
+
+	// TimedOut reports that the command was killed for exceeding its timeout.
+	TimedOut bool
+
+	// Duration is the command's wall-clock run time.
+	Duration time.Duration
+
+	// Output is the first page of the command's combined stdout and stderr.
+	Output string
+
+	// OutputID identifies the retained output for ReadOutput. Empty when the
+	// whole output fit in Output.
+	OutputID string
+
+	// NextOffset is the byte offset to resume reading the output from with
+	// ReadOutput. Nil when Output holds the rest of it.
+	NextOffset *int64
+
+	// Remaining is the number of output bytes after NextOffset.
+	Remaining int64
+
+	// Dropped is the number of bytes discarded from the start of the output
+	// because it exceeded the retention limit. Offsets count from the first
+	// retained byte.
+	Dropped int64
+}
+
+// ExecCallbacks provides callback functions for running commands in a
+// worktree. Implementations decide which commands may run and how they are
+// isolated; a command that is not permitted returns an error, while one that
+// runs and fails reports its ExitCode instead.
+type ExecCallbacks struct {
+	// RunCommand runs command with args in dir, a directory relative to the
+	// worktree root ("" or "." for the root itself), and returns up to limit
+	// bytes of its output. A positive timeout shortens the implementation's
+	// default; it cannot extend it.
+	RunCommand func(ctx context.Context, command string, args []string, dir string, timeout time.Duration, limit int) (ExecResult, error)
+
+	// ReadOutput reads the output of an earlier RunCommand call by its
+	// OutputID, starting at the given byte offset and returning up to limit
+	// bytes. Implementations retain a bounded number of outputs, so an old ID
+	// may no longer resolve. A negative offset is invalid and must return an
+	// error.
+	ReadOutput func(ctx context.Context, id string, offset int64, limit int) (ReadResult, error)
+}
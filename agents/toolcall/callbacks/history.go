@@ -5,7 +5,10 @@ SPDX-License-Identifier: Apache-2.0
 
 package callbacks
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // CommitFile describes a file changed in a single commit.
 type CommitFile struct {
@@ -60,8 +63,84 @@ type FileDiffResult struct {
 	Remaining int64
 }
 
+// BlameLine attributes one line of a file to the commit that last changed it.
+type BlameLine struct {
+	// Line is the 1-based line number in the blamed revision.
+	Line int
+
+	// SHA is the short (7-character) hash of the commit that last changed
+	// the line.
+	SHA string
+
+	// Author is the name of that commit's author.
+	Author string
+
+	// Date is when that commit was authored.
+	Date time.Time
+
+	// Text is the line's content, without its trailing newline.
+	Text string
+}
+
+// BlameResult is the result of a Blame callback call.
+type BlameResult struct {
+	// Lines is the blamed line range in this page.
+	Lines []BlameLine
+
+	// NextLine is the line number to resume blaming from.
+	// Nil when the page reached the end of the file.
+	NextLine *int
+
+	// TotalLines is the number of lines in the file.
+	TotalLines int
+}
+
+// LogQuery selects the commits a Log callback call returns.
+type LogQuery struct {
+	// Rev is the revision to walk back from. Empty means HEAD.
+	Rev string
+
+	// Path limits the log to commits that changed this file or directory.
+	Path string
+
+	// MessagePattern limits the log to commits whose message matches this
+	// regular expression.
+	MessagePattern string
+}
+
+// LogEntry describes a single commit returned by a Log callback call.
+type LogEntry struct {
+	// SHA is the short (7-character) commit hash.
+	SHA string
+
+	// Author is the commit author's name.
+	Author string
+
+	// Date is when the commit was authored.
+	Date time.Time
+
+	// Message is the full commit message.
+	Message string
+}
+
+// LogResult is the result of a Log callback call.
+type LogResult struct {
+	// Commits is the list of matching commits in this page, newest first.
+	Commits []LogEntry
+
+	// NextOffset is the item offset to resume listing from.
+	// Nil when there are no more matching commits.
+	NextOffset *int
+
+	// Truncated reports that the walk reached the oldest commit available
+	// locally, so older matches may exist that were not searched.
+	Truncated bool
+}
+
 // HistoryCallbacks provides callback functions for reading commit history
-// relative to a fixed base ref determined at construction time.
+// relative to a fixed base ref determined at construction time. Blame, Log
+// and ShowFile are not bounded by the base ref and may reach further back;
+// they are optional, and the corresponding tools are only offered when set.
 type HistoryCallbacks struct {
 	// ListCommits lists commits from the base ref to HEAD in reverse
 	// chronological order. Each commit includes its changed files with
@@ -72,4 +151,17 @@ type HistoryCallbacks struct {
 	// commit range. Pass empty start for base ref, empty end for HEAD.
 	// Results are paginated by byte offset and limit.
 	GetFileDiff func(ctx context.Context, path, start, end string, offset int64, limit int) (FileDiffResult, error)
+
+	// Blame attributes limit lines of path at rev, starting at the 1-based
+	// startLine, to the commits that last changed them. Pass empty rev for
+	// HEAD.
+	Blame func(ctx context.Context, path, rev string, startLine, limit int) (BlameResult, error)
+
+	// Log lists the commits reachable from q.Rev that match q, newest
+	// first. Results are paginated by offset and limit.
+	Log func(ctx context.Context, q LogQuery, offset, limit int) (LogResult, error)
+
+	// ShowFile reads path as it was at rev. Pass empty rev for HEAD.
+	// Results are paginated by byte offset and limit.
+	ShowFile func(ctx context.Context, path, rev string, offset int64, limit int) (ReadResult, error)
 }
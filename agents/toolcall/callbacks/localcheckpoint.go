@@ -0,0 +1,206 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// localCheckpoints is the copy-on-write journal behind LocalWorktree's
+// checkpoints. Each generation starts at a checkpoint and records, the first
+// time a callback is about to change a path, what the path held before; the
+// files nobody touched are never copied.
+//
+// Only changes made through the callbacks are journaled. Files a command
+// from LocalExec writes, and directories, are not.
+type localCheckpoints struct {
+	r *os.Root
+
+	// op is held shared by changes and exclusively by checkpoint operations,
+	// so a checkpoint never lands between a change and its journal entry.
+	op sync.RWMutex
+
+	mu   sync.Mutex // guards the journal against concurrent changes
+	seq  int
+	ids  []string                 // ids[i] is the checkpoint generation i began at
+	gens []map[string]FileVersion // gens[i] holds the originals of paths first changed in generation i
+}
+
+func newLocalCheckpoints(r *os.Root) *localCheckpoints {
+	return &localCheckpoints{
+		r:    r,
+		ids:  []string{StartCheckpoint},
+		gens: []map[string]FileVersion{{}},
+	}
+}
+
+// change journals paths and then runs fn, the change to them.
+func (c *localCheckpoints) change(fn func() error, paths ...string) error {
+	c.op.RLock()
+	defer c.op.RUnlock()
+	for _, p := range paths {
+		if err := c.save(p); err != nil {
+			return err
+		}
+	}
+	return fn()
+}
+
+// save records the current version of p in the open generation unless it
+// already holds one. It is called, within change, before every change to p.
+func (c *localCheckpoints) save(p string) error {
+	p = path.Clean(p)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	gen := c.gens[len(c.gens)-1]
+	if _, ok := gen[p]; ok {
+		return nil
+	}
+	v, ok, err := c.read(p)
+	if err != nil {
+		return err
+	}
+	if ok {
+		gen[p] = v
+	}
+	return nil
+}
+
+// read returns the version of p on disk. It reports false for directories,
+// which are not journaled.
+func (c *localCheckpoints) read(p string) (FileVersion, bool, error) {
+	info, err := c.r.Lstat(p)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return FileVersion{}, true, nil
+	case err != nil:
+		return FileVersion{}, false, err
+	case info.IsDir():
+		return FileVersion{}, false, nil
+	}
+	v := FileVersion{Exists: true, Mode: info.Mode()}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := c.r.Readlink(p)
+		if err != nil {
+			return FileVersion{}, false, err
+		}
+		v.Data = []byte(target)
+		return v, true, nil
+	}
+	if v.Data, err = c.r.ReadFile(p); err != nil {
+		return FileVersion{}, false, err
+	}
+	return v, true, nil
+}
+
+func (c *localCheckpoints) create(context.Context) (string, error) {
+	c.op.Lock()
+	defer c.op.Unlock()
+	c.seq++
+	id := "cp-" + strconv.Itoa(c.seq)
+	c.ids = append(c.ids, id)
+	c.gens = append(c.gens, map[string]FileVersion{})
+	return id, nil
+}
+
+// since returns the generation that began at checkpoint id and, for every
+// path changed after it, the version it held at that checkpoint. The caller
+// holds c.op.
+func (c *localCheckpoints) since(id string) (int, map[string]FileVersion, error) {
+	g := slices.Index(c.ids, id)
+	if g < 0 {
+		return 0, nil, fmt.Errorf("unknown checkpoint %q", id)
+	}
+	originals := map[string]FileVersion{}
+	for _, gen := range c.gens[g:] {
+		for p, v := range gen {
+			if _, ok := originals[p]; !ok {
+				originals[p] = v
+			}
+		}
+	}
+	return g, originals, nil
+}
+
+func (c *localCheckpoints) diff(_ context.Context, id string, offset int64, limit int) (CheckpointDiffResult, error) {
+	if err := checkOffset(offset); err != nil {
+		return CheckpointDiffResult{}, err
+	}
+	c.op.Lock()
+	defer c.op.Unlock()
+	_, originals, err := c.since(id)
+	if err != nil {
+		return CheckpointDiffResult{}, err
+	}
+	changes := make([]FileChange, 0, len(originals))
+	for p, old := range originals {
+		cur, ok, err := c.read(p)
+		if err != nil {
+			return CheckpointDiffResult{}, err
+		}
+		if ok {
+			changes = append(changes, FileChange{Path: p, Old: old, New: cur})
+		}
+	}
+	return DiffChanges(changes, offset, limit), nil
+}
+
+func (c *localCheckpoints) restore(_ context.Context, id string) ([]string, error) {
+	c.op.Lock()
+	defer c.op.Unlock()
+	g, originals, err := c.since(id)
+	if err != nil {
+		return nil, err
+	}
+	var restored []string
+	for p, old := range originals {
+		cur, ok, err := c.read(p)
+		if err != nil {
+			return nil, err
+		}
+		if ok && unifiedDiff(p, old, cur) == "" {
+			continue
+		}
+		if err := c.write(p, old); err != nil {
+			return nil, fmt.Errorf("restoring %s: %w", p, err)
+		}
+		restored = append(restored, p)
+	}
+	// The worktree is back at checkpoint g: later checkpoints are gone and
+	// its generation starts over.
+	c.ids = c.ids[:g+1]
+	c.gens = append(c.gens[:g], map[string]FileVersion{})
+	slices.Sort(restored)
+	return restored, nil
+}
+
+// write puts version v of p on disk.
+func (c *localCheckpoints) write(p string, v FileVersion) error {
+	if err := c.r.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if !v.Exists {
+		return nil
+	}
+	if err := c.r.MkdirAll(path.Dir(p), 0o755); err != nil {
+		return err
+	}
+	if v.Mode&fs.ModeSymlink != 0 {
+		return c.r.Symlink(string(v.Data), p)
+	}
+	if err := c.r.WriteFile(p, v.Data, v.Mode.Perm()); err != nil {
+		return err
+	}
+	return c.r.Chmod(p, v.Mode.Perm())
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultExecTimeout bounds each command LocalExec runs when
+	// ExecConfig.Timeout is zero.
+	DefaultExecTimeout = 10 * time.Minute
+
+	// DefaultMaxOutputBytes is the combined output LocalExec retains per
+	// command when ExecConfig.MaxOutputBytes is zero.
+	DefaultMaxOutputBytes = 1 << 20
+
+	// retainedOutputs is how many command outputs LocalExec keeps for
+	// ReadOutput; older ones are forgotten.
+	retainedOutputs = 16
+
+	// execWaitDelay bounds how long a finished or killed command may hold its
+	// output pipes open through a background child.
+	execWaitDelay = 5 * time.Second
+)
+
+// ExecConfig configures the commands LocalExec runs and how they are
+// confined.
+type ExecConfig struct {
+	// Commands allow-lists the programs RunCommand may start, by bare name
+	// ("go", "make", "golangci-lint"). Each is resolved on the PATH of the
+	// calling process. A command that is not listed, or that contains a path
+	// separator, is rejected.
+	Commands []string
+
+	// Timeout bounds each command. A call may ask for less, never more. Zero
+	// means DefaultExecTimeout.
+	Timeout time.Duration
+
+	// MaxOutputBytes bounds the combined stdout and stderr retained per
+	// command. Beyond it the start of the output is dropped, keeping the tail
+	// where build and test failures are usually reported. Zero means
+	// DefaultMaxOutputBytes.
+	MaxOutputBytes int
+
+	// Env passes environment variables through to commands, either as
+	// "KEY=VALUE" or as a bare "KEY" copied from the calling process. Every
+	// other variable is scrubbed: commands see only PATH, a per-command HOME
+	// and TMPDIR, and these. Credentials in the calling process's environment
+	// therefore never reach a command unless listed here.
+	Env []string
+
+	// MaxMemoryBytes caps each command's address space (RLIMIT_AS). Language
+	// toolchains reserve far more address space than they use, so leave
+	// generous headroom. Zero means no cap. Linux only.
+	MaxMemoryBytes uint64
+
+	// MaxFileBytes caps the size of any file a command writes
+	// (RLIMIT_FSIZE). Zero means no cap. Linux only.
+	MaxFileBytes uint64
+
+	// Namespaces runs each command in new user, network, IPC and UTS
+	// namespaces, so it has no network access beyond a loopback device.
+	// Requires unprivileged user namespaces. Linux only.
+	Namespaces bool
+}
+
+// LocalExec returns an ExecCallbacks that runs the allow-listed commands of
+// cfg in directories under r, the os.Root a LocalWorktree is backed by.
+//
+// Each command runs in its own process group, which is killed when the
+// timeout expires or ctx is canceled, and with a scrubbed environment (see
+// ExecConfig.Env). On Linux the resource limits and namespaces of cfg are
+// applied as well; elsewhere a cfg that asks for them fails every call.
+// Resource limits are applied before the command runs, by starting the
+// calling binary in its place: it sets them as this package initializes and
+// then execs the command, so the binary's other package initializers may run
+// first.
+//
+// The working directory is checked against r, but a command can still reach
+// the rest of the filesystem: the confinement is of environment, network and
+// resources, not of paths. Files a command writes into the worktree, such as
+// build outputs, are part of it like any other change.
+func LocalExec(r *os.Root, cfg ExecConfig) ExecCallbacks {
+	e := &localExec{
+		root:      r,
+		cfg:       cfg,
+		timeout:   cfg.Timeout,
+		maxOutput: cfg.MaxOutputBytes,
+		outputs:   make(map[string][]byte, retainedOutputs),
+	}
+	if e.timeout <= 0 {
+		e.timeout = DefaultExecTimeout
+	}
+	if e.maxOutput <= 0 {
+		e.maxOutput = DefaultMaxOutputBytes
+	}
+	return ExecCallbacks{
+		RunCommand: e.run,
+		ReadOutput: e.read,
+	}
+}
+
+// localExec holds the state LocalExec's callbacks share.
+type localExec struct {
+	root      *os.Root
+	cfg       ExecConfig
+	timeout   time.Duration
+	maxOutput int
+
+	mu      sync.Mutex
+	seq     int
+	order   []string
+	outputs map[string][]byte // finished commands' output, for ReadOutput
+}
+
+func (e *localExec) run(ctx context.Context, command string, args []string, dir string, timeout time.Duration, limit int) (ExecResult, error) {
+	if !slices.Contains(e.cfg.Commands, command) || strings.ContainsAny(command, `/\`) {
+		return ExecResult{}, fmt.Errorf("command %q is not allowed (allowed: %s)", command, strings.Join(e.cfg.Commands, ", "))
+	}
+	if err := checkPlatform(e.cfg); err != nil {
+		return ExecResult{}, err
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	workDir, err := e.workDir(dir)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	if timeout <= 0 || timeout > e.timeout {
+		timeout = e.timeout
+	}
+
+	scratch, err := os.MkdirTemp("", "exec-")
+	if err != nil {
+		return ExecResult{}, fmt.Errorf("creating scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out := &tailBuffer{max: e.maxOutput}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = workDir
+	cmd.Env = scrubbedEnv(e.cfg.Env, scratch)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = sysProcAttr(e.cfg)
+	cmd.Cancel = func() error { return killGroup(cmd.Process) }
+	cmd.WaitDelay = execWaitDelay
+
+	limitCommand(cmd, e.cfg)
+
+	start := time.Now()
+	// The parent-death signal fires when the thread that started the command
+	// exits, not the process: start it from a thread this goroutine holds.
+	runtime.LockOSThread()
+	err = cmd.Start()
+	runtime.UnlockOSThread()
+	if err != nil {
+		return ExecResult{}, err
+	}
+	waitErr := cmd.Wait()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The caller gave up; only a timeout is reported as a result.
+		return ExecResult{}, ctx.Err()
+	}
+	result := ExecResult{
+		ExitCode: cmd.ProcessState.ExitCode(),
+		TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+		Duration: time.Since(start),
+		Dropped:  out.dropped(),
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) && !errors.Is(waitErr, exec.ErrWaitDelay) && ctx.Err() == nil {
+		return ExecResult{}, waitErr
+	}
+
+	data := out.bytes()
+	page := readPage(data, 0, limit)
+	result.Output = page.Content
+	if page.NextOffset != nil {
+		result.OutputID = e.retain(data)
+		result.NextOffset = page.NextOffset
+		result.Remaining = page.Remaining
+	}
+	return result, nil
+}
+
+func (e *localExec) read(_ context.Context, id string, offset int64, limit int) (ReadResult, error) {
+	if err := checkOffset(offset); err != nil {
+		return ReadResult{}, err
+	}
+	e.mu.Lock()
+	data, ok := e.outputs[id]
+	e.mu.Unlock()
+	if !ok {
+		return ReadResult{}, fmt.Errorf("no retained output %q; run the command again", id)
+	}
+	return readPage(data, offset, limit), nil
+}
+
+// workDir resolves dir against the root, rejecting paths that leave it or
+// are not directories.
+func (e *localExec) workDir(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	info, err := e.root.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", dir)
+	}
+	return filepath.Join(e.root.Name(), filepath.FromSlash(dir)), nil
+}
+
+// retain stores data for ReadOutput and returns its ID, forgetting the oldest
+// output once retainedOutputs are held.
+func (e *localExec) retain(data []byte) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.seq++
+	id := "out-" + strconv.Itoa(e.seq)
+	e.outputs[id] = data
+	e.order = append(e.order, id)
+	if len(e.order) > retainedOutputs {
+		delete(e.outputs, e.order[0])
+		e.order = e.order[1:]
+	}
+	return id
+}
+
+// readPage returns up to limit bytes of data from offset; a negative limit
+// reads to the end.
+func readPage(data []byte, offset int64, limit int) ReadResult {
+	if offset >= int64(len(data)) {
+		return ReadResult{}
+	}
+	data = data[offset:]
+	if limit < 0 || limit >= len(data) {
+		return ReadResult{Content: string(data)}
+	}
+	next := offset + int64(limit)
+	return ReadResult{
+		Content:    string(data[:limit]),
+		NextOffset: &next,
+		Remaining:  int64(len(data) - limit),
+	}
+}
+
+// scrubbedEnv builds a command's environment: PATH from the calling process,
+// HOME and TMPDIR in the per-command scratch directory, then the pass-through
+// entries of ExecConfig.Env.
+func scrubbedEnv(pass []string, scratch string) []string {
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + scratch,
+		"TMPDIR=" + scratch,
+	}
+	for _, kv := range pass {
+		if strings.Contains(kv, "=") {
+			env = append(env, kv)
+		} else if v, ok := os.LookupEnv(kv); ok {
+			env = append(env, kv+"="+v)
+		}
+	}
+	return env
+}
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+// exec.Cmd serializes writes when Stdout and Stderr are the same writer.
+type tailBuffer struct {
+	max     int
+	buf     []byte
+	written int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.written += int64(len(p))
+	b.buf = append(b.buf, p...)
+	// Compact only once the buffer holds twice the limit, so a chatty command
+	// costs amortized constant time per byte.
+	if len(b.buf) > 2*b.max {
+		b.buf = slices.Clone(b.buf[len(b.buf)-b.max:])
+	}
+	return len(p), nil
+}
+
+// bytes returns the retained tail of the output.
+func (b *tailBuffer) bytes() []byte {
+	if len(b.buf) > b.max {
+		return b.buf[len(b.buf)-b.max:]
+	}
+	return b.buf
+}
+
+// dropped returns how many bytes were written before the retained tail.
+func (b *tailBuffer) dropped() int64 {
+	return b.written - int64(len(b.bytes()))
+}
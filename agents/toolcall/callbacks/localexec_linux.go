@@ -0,0 +1,120 @@
+//go:build linux
+
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkPlatform accepts every ExecConfig: Linux supports all of its
+// confinement settings.
+func checkPlatform(ExecConfig) error { return nil }
+
+// sysProcAttr starts a command in its own process group, killed with the
+// calling process, and in new namespaces when cfg.Namespaces is set. The
+// user namespace maps the caller's uid and gid to themselves, so files the
+// command writes keep their usual owner.
+func sysProcAttr(cfg ExecConfig) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+	if cfg.Namespaces {
+		attr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	return attr
+}
+
+// limitsEnv carries a command's resource limits, as "AS,FSIZE" byte counts,
+// to the re-executed binary that applies them; see limitCommand.
+const limitsEnv = "DRIFTLESSAF_EXEC_RLIMITS"
+
+// selfExe is the running binary, which limitCommand starts in place of the
+// command.
+const selfExe = "/proc/self/exe"
+
+func init() {
+	if spec, ok := os.LookupEnv(limitsEnv); ok {
+		execWithLimits(spec)
+	}
+}
+
+// limitCommand arranges for cmd to start under the resource limits of cfg.
+// Limits are inherited across fork and exec but cannot be set between them
+// from Go, and setting them once the command runs leaves a window in which
+// it can fork children outside them. So cmd instead starts this binary,
+// which applies the limits in execWithLimits as it initializes and then
+// execs the command in its place, before the command runs at all.
+func limitCommand(cmd *exec.Cmd, cfg ExecConfig) {
+	if cfg.MaxMemoryBytes == 0 && cfg.MaxFileBytes == 0 {
+		return
+	}
+	// cmd.Args[0] is the command's resolved path, which the wrapper execs.
+	cmd.Path = selfExe
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d,%d", limitsEnv, cfg.MaxMemoryBytes, cfg.MaxFileBytes))
+}
+
+// execWithLimits applies the limits of spec (limitsEnv's value) to this
+// process and execs the command limitCommand wrapped, with limitsEnv
+// removed from its environment. It never returns: a failure is reported on
+// stderr, which the command's output captures, with exit status 126.
+func execWithLimits(spec string) {
+	if err := applyLimitSpec(spec); err != nil {
+		fmt.Fprintf(os.Stderr, "applying resource limits: %v\n", err)
+		os.Exit(126)
+	}
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, limitsEnv+"=")
+	})
+	err := syscall.Exec(os.Args[0], os.Args, env)
+	fmt.Fprintf(os.Stderr, "exec %s: %v\n", os.Args[0], err)
+	os.Exit(126)
+}
+
+// applyLimitSpec sets the limits of spec, skipping zero values.
+func applyLimitSpec(spec string) error {
+	as, fsize, ok := strings.Cut(spec, ",")
+	if !ok {
+		return fmt.Errorf("malformed %s %q", limitsEnv, spec)
+	}
+	for _, l := range []struct {
+		resource int
+		value    string
+	}{
+		{unix.RLIMIT_AS, as},
+		{unix.RLIMIT_FSIZE, fsize},
+	} {
+		v, err := strconv.ParseUint(l.value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed %s %q: %w", limitsEnv, spec, err)
+		}
+		if v == 0 {
+			continue
+		}
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: v, Max: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// killGroup kills the command's whole process group, so children it started
+// do not outlive it.
+func killGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// checkPlatform rejects the confinement settings that need Linux, rather
+// than running the command without them.
+func checkPlatform(cfg ExecConfig) error {
+	if cfg.Namespaces || cfg.MaxMemoryBytes != 0 || cfg.MaxFileBytes != 0 {
+		return fmt.Errorf("exec namespaces and resource limits are not supported on %s", runtime.GOOS)
+	}
+	return nil
+}
+
+// sysProcAttr leaves process attributes at their defaults.
+func sysProcAttr(ExecConfig) *syscall.SysProcAttr { return nil }
+
+// limitCommand has nothing to apply: checkPlatform rejected any limits.
+func limitCommand(*exec.Cmd, ExecConfig) {}
+
+// killGroup kills the command; without process groups its children may
+// outlive it.
+func killGroup(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks_test
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestLocalExecRunsAllowedCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	r := openTestRoot(t, map[string]string{"pkg/file.txt": "hello"})
+	t.Setenv("EXEC_TEST_SECRET", "s3cret")
+	t.Setenv("EXEC_TEST_PASSED", "kept")
+	cb := callbacks.LocalExec(r, callbacks.ExecConfig{
+		Commands: []string{"sh"},
+		Env:      []string{"EXEC_TEST_PASSED", "EXEC_TEST_SET=set"},
+	})
+
+	t.Run("runs in dir", func(t *testing.T) {
+		res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "cat file.txt; exit 3"}, "pkg", 0, -1)
+		if err != nil {
+			t.Fatalf("RunCommand: %v", err)
+		}
+		if res.ExitCode != 3 || res.Output != "hello" || res.TimedOut {
+			t.Errorf("result: got = %+v, want exit 3 with output hello", res)
+		}
+	})
+
+	t.Run("scrubs environment", func(t *testing.T) {
+		res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", `echo "$EXEC_TEST_SECRET|$EXEC_TEST_PASSED|$EXEC_TEST_SET"; test "$HOME" != "` + os.Getenv("HOME") + `"`}, "", 0, -1)
+		if err != nil {
+			t.Fatalf("RunCommand: %v", err)
+		}
+		if got, want := strings.TrimSpace(res.Output), "|kept|set"; got != want || res.ExitCode != 0 {
+			t.Errorf("environment: got = %q (exit %d), want = %q with a scratch HOME", got, res.ExitCode, want)
+		}
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		for _, tc := range []struct {
+			name, command, dir string
+		}{
+			{"unlisted command", "cat", ""},
+			{"command path", "/bin/sh", ""},
+			{"dir outside root", "sh", "../"},
+			{"file as dir", "sh", "pkg/file.txt"},
+		} {
+			if _, err := cb.RunCommand(t.Context(), tc.command, []string{"-c", "true"}, tc.dir, 0, -1); err == nil {
+				t.Errorf("%s: got = nil, want an error", tc.name)
+			}
+		}
+	})
+}
+
+func TestLocalExecPaginatesOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	r := openTestRoot(t, nil)
+	cb := callbacks.LocalExec(r, callbacks.ExecConfig{Commands: []string{"sh"}, MaxOutputBytes: 8})
+
+	// Twelve bytes of output, of which the last eight are retained.
+	res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "printf 0123456789ab"}, "", 0, 5)
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if res.Output != "45678" || res.Dropped != 4 || res.NextOffset == nil || *res.NextOffset != 5 || res.Remaining != 3 || res.OutputID == "" {
+		t.Fatalf("first page: got = %+v, want 45678 continuing at 5 with 3 remaining and 4 dropped", res)
+	}
+	page, err := cb.ReadOutput(t.Context(), res.OutputID, *res.NextOffset, 100)
+	if err != nil {
+		t.Fatalf("ReadOutput: %v", err)
+	}
+	if page.Content != "9ab" || page.NextOffset != nil {
+		t.Errorf("second page: got = %+v, want the final 9ab", page)
+	}
+	if _, err := cb.ReadOutput(t.Context(), "out-missing", 0, 10); err == nil {
+		t.Error("ReadOutput of an unknown id: got = nil, want an error")
+	}
+	if _, err := cb.ReadOutput(t.Context(), res.OutputID, -1, 10); err == nil {
+		t.Error("ReadOutput at a negative offset: got = nil, want an error")
+	}
+}
+
+func TestLocalExecTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	r := openTestRoot(t, nil)
+	cb := callbacks.LocalExec(r, callbacks.ExecConfig{Commands: []string{"sh"}, Timeout: time.Minute})
+
+	start := time.Now()
+	// The background sleep holds the output pipe open, so this also checks
+	// the whole process group is killed.
+	res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "echo started; sleep 60 & sleep 60"}, "", 100*time.Millisecond, -1)
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if !res.TimedOut || res.ExitCode != -1 || strings.TrimSpace(res.Output) != "started" {
+		t.Errorf("result: got = %+v, want a timed-out run that printed started", res)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("elapsed: got = %v, want the requested timeout to apply", elapsed)
+	}
+}
+
+func TestLocalExecLimitsApplyBeforeStart(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are Linux only")
+	}
+	r := openTestRoot(t, nil)
+	cb := callbacks.LocalExec(r, callbacks.ExecConfig{
+		Commands:     []string{"sh"},
+		MaxFileBytes: 1024,
+	})
+
+	// The shell's first act is to fork a writer, which must already be held
+	// to the limit; the wrapper's variable must not reach the command.
+	script := `head -c 4096 /dev/zero > "$TMPDIR/big"; wc -c < "$TMPDIR/big"; echo "${DRIFTLESSAF_EXEC_RLIMITS-unset}"`
+	res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", script}, "", 0, -1)
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	// The shell may report the writer's SIGXFSZ first.
+	if got := strings.Fields(res.Output); len(got) < 2 || got[len(got)-2] != "1024" || got[len(got)-1] != "unset" {
+		t.Errorf("output: got = %q, want a 1024 byte file and no limits variable", res.Output)
+	}
+}
+
+func TestLocalExecNamespaces(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("namespaces are Linux only")
+	}
+	r := openTestRoot(t, nil)
+	cb := callbacks.LocalExec(r, callbacks.ExecConfig{
+		Commands:     []string{"sh"},
+		Namespaces:   true,
+		MaxFileBytes: 1 << 20,
+	})
+
+	// /proc/net/dev lists the interfaces of the caller's network namespace:
+	// a fresh one has only loopback.
+	res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "tail -n +3 /proc/net/dev | cut -d: -f1"}, "", 0, -1)
+	if errors.Is(err, os.ErrPermission) || (err != nil && strings.Contains(err.Error(), "operation not permitted")) {
+		t.Skipf("user namespaces unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if got := strings.Fields(res.Output); len(got) != 1 || got[0] != "lo" {
+		t.Errorf("interfaces: got = %q, want only lo", got)
+	}
+}
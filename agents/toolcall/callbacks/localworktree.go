@@ -18,7 +18,13 @@ import (
 // LocalWorktree returns a WorktreeCallbacks backed by r, an os.Root that
 // sandboxes all file operations within a single directory tree. Path traversal
 // outside the root is prevented by the os.Root implementation.
+//
+// Its checkpoints are a copy-on-write journal: a file is copied the first
+// time a callback changes it after a checkpoint, and restoring writes the
+// copies back. Changes made outside the callbacks, such as the build outputs
+// of LocalExec commands, are not tracked, and neither are directories.
 func LocalWorktree(r *os.Root) WorktreeCallbacks {
+	cp := newLocalCheckpoints(r)
 	return WorktreeCallbacks{
 		ReadFile: func(_ context.Context, path string, offset int64, limit int) (ReadResult, error) {
 			if err := checkOffset(offset); err != nil {
@@ -45,73 +51,65 @@ func LocalWorktree(r *os.Root) WorktreeCallbacks {
 		},
 
 		WriteFile: func(_ context.Context, path, content string, mode os.FileMode) error {
-			if err := r.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return cp.change(func() error {
+				if err := r.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					return err
+				}
+				return r.WriteFile(path, []byte(content), mode)
+			}, path)
+		},
+
+		EditFile: func(_ context.Context, path, oldString, newString string, replaceAll bool) (result EditResult, err error) {
+			err = cp.change(func() error {
+				result, err = localEditFile(r, path, oldString, newString, replaceAll)
 				return err
-			}
-			return r.WriteFile(path, []byte(content), mode)
+			}, path)
+			return result, err
 		},
 
-		EditFile: func(_ context.Context, path, oldString, newString string, replaceAll bool) (EditResult, error) {
-			data, err := r.ReadFile(path)
-			if err != nil {
-				return EditResult{}, err
-			}
-			content := string(data)
-			count := strings.Count(content, oldString)
-			if count == 0 {
-				return EditResult{}, fmt.Errorf("string not found in %q", path)
-			}
-			if !replaceAll && count > 1 {
-				return EditResult{}, fmt.Errorf("string found %d times in %q; use replace_all=true to replace all occurrences", count, path)
-			}
-			var updated string
-			if replaceAll {
-				updated = strings.ReplaceAll(content, oldString, newString)
-			} else {
-				updated = strings.Replace(content, oldString, newString, 1)
-			}
-			info, err := r.Stat(path)
-			if err != nil {
-				return EditResult{}, err
-			}
-			if err := r.WriteFile(path, []byte(updated), info.Mode()); err != nil {
-				return EditResult{}, err
-			}
-			replacements := 1
-			if replaceAll {
-				replacements = count
-			}
-			return EditResult{Replacements: replacements}, nil
+		ApplyPatch: func(_ context.Context, patch string) (result PatchResult, err error) {
+			// The patch target journals each path before writing it.
+			err = cp.change(func() error {
+				result, err = ApplyUnifiedDiff(rootPatchTarget{r: r, cp: cp}, patch)
+				return err
+			})
+			return result, err
 		},
 
 		DeleteFile: func(_ context.Context, path string) error {
-			return r.Remove(path)
+			return cp.change(func() error { return r.Remove(path) }, path)
 		},
 
 		MoveFile: func(_ context.Context, src, dst string) error {
-			return r.Rename(src, dst)
+			return cp.change(func() error { return r.Rename(src, dst) }, src, dst)
 		},
 
 		CopyFile: func(_ context.Context, src, dst string) error {
-			data, err := r.ReadFile(src)
-			if err != nil {
-				return err
-			}
-			info, err := r.Stat(src)
-			if err != nil {
-				return err
-			}
-			return r.WriteFile(dst, data, info.Mode())
+			return cp.change(func() error {
+				data, err := r.ReadFile(src)
+				if err != nil {
+					return err
+				}
+				info, err := r.Stat(src)
+				if err != nil {
+					return err
+				}
+				return r.WriteFile(dst, data, info.Mode())
+			}, dst)
 		},
 
 		CreateSymlink: func(_ context.Context, path, target string) error {
-			return r.Symlink(target, path)
+			return cp.change(func() error { return r.Symlink(target, path) }, path)
 		},
 
 		Chmod: func(_ context.Context, path string, mode os.FileMode) error {
-			return r.Chmod(path, mode)
+			return cp.change(func() error { return r.Chmod(path, mode) }, path)
 		},
 
+		CreateCheckpoint:    cp.create,
+		DiffSinceCheckpoint: cp.diff,
+		RestoreCheckpoint:   cp.restore,
+
 		ListDirectory: func(_ context.Context, path, filter string, offset, limit int) (ListResult, error) {
 			if err := checkOffset(int64(offset)); err != nil {
 				return ListResult{}, err
@@ -213,6 +211,82 @@ func checkOffset(offset int64) error {
 	return nil
 }
 
+// localEditFile replaces oldString with newString in the file at path under
+// r, keeping the file's mode. oldString must occur exactly once unless
+// replaceAll is set. LocalWorktree's EditFile runs it inside a checkpoint
+// change, so the file is journaled before it is rewritten.
+func localEditFile(r *os.Root, path, oldString, newString string, replaceAll bool) (EditResult, error) {
+	data, err := r.ReadFile(path)
+	if err != nil {
+		return EditResult{}, err
+	}
+	content := string(data)
+	count := strings.Count(content, oldString)
+	if count == 0 {
+		return EditResult{}, fmt.Errorf("string not found in %q", path)
+	}
+	if !replaceAll && count > 1 {
+		return EditResult{}, fmt.Errorf("string found %d times in %q; use replace_all=true to replace all occurrences", count, path)
+	}
+	var updated string
+	if replaceAll {
+		updated = strings.ReplaceAll(content, oldString, newString)
+	} else {
+		updated = strings.Replace(content, oldString, newString, 1)
+	}
+	info, err := r.Stat(path)
+	if err != nil {
+		return EditResult{}, err
+	}
+	if err := r.WriteFile(path, []byte(updated), info.Mode()); err != nil {
+		return EditResult{}, err
+	}
+	replacements := 1
+	if replaceAll {
+		replacements = count
+	}
+	return EditResult{Replacements: replacements}, nil
+}
+
+// rootPatchTarget is the PatchTarget of a LocalWorktree.
+type rootPatchTarget struct {
+	r  *os.Root
+	cp *localCheckpoints
+}
+
+func (t rootPatchTarget) ReadFile(path string) ([]byte, fs.FileMode, error) {
+	info, err := t.r.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, 0, fmt.Errorf("%q is not a regular file", path)
+	}
+	data, err := t.r.ReadFile(path)
+	return data, info.Mode().Perm(), err
+}
+
+func (t rootPatchTarget) WriteFile(path string, data []byte, mode fs.FileMode) error {
+	if err := t.cp.save(path); err != nil {
+		return err
+	}
+	if err := t.r.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := t.r.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file.
+	return t.r.Chmod(path, mode)
+}
+
+func (t rootPatchTarget) Remove(path string) error {
+	if err := t.cp.save(path); err != nil {
+		return err
+	}
+	return t.r.Remove(path)
+}
+
 // localWalkDir recursively walks the directory tree under r starting at dir
 // via fs.WalkDir, calling fn for each regular file that matches the optional
 // filter glob. Hidden directories (names starting with ".") are skipped.
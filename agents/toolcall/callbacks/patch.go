@@ -0,0 +1,661 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// maxPatchFuzz is how many leading and trailing context lines ApplyUnifiedDiff
+// may ignore to place a hunk, matching patch(1)'s default fuzz factor.
+const maxPatchFuzz = 2
+
+// PatchResult is the result of an ApplyPatch callback call.
+type PatchResult struct {
+	// Applied reports whether the patch was written. A patch applies whole
+	// or not at all: when any file or hunk fails, no file is changed.
+	Applied bool
+
+	// Files is the outcome for each file in the patch, in patch order.
+	Files []PatchFileResult
+}
+
+// PatchFileResult is the outcome of one file in a patch.
+type PatchFileResult struct {
+	// Path is the file path relative to the worktree root: the new path, or
+	// the old one for a deletion.
+	Path string
+
+	// OldPath is the previous path of a renamed file. Empty otherwise.
+	OldPath string
+
+	// Type is the change type: "added", "modified", "deleted", or "renamed".
+	Type string
+
+	// Hunks is the outcome of each hunk, in patch order.
+	Hunks []HunkResult
+
+	// Error explains a failure that is not specific to a hunk, such as a
+	// missing file or one that already exists. Empty on success.
+	Error string
+}
+
+// HunkResult is the outcome of one hunk in a patch.
+type HunkResult struct {
+	// Header is the hunk's "@@ -l,s +l,s @@" line.
+	Header string
+
+	// Applied reports whether the hunk found its place in the file.
+	Applied bool
+
+	// Line is the 1-based line of the original file the hunk applied at.
+	Line int
+
+	// Offset is how many lines Line is from the position the header gives.
+	Offset int
+
+	// Fuzz is how many context lines were ignored at each end of the hunk to
+	// place it; zero for an exact placement.
+	Fuzz int
+
+	// Whitespace reports that the hunk's lines matched the file only when
+	// differences in whitespace were ignored.
+	Whitespace bool
+
+	// Error explains why the hunk did not apply. Empty on success.
+	Error string
+}
+
+// Failed returns the number of files and hunks in r that failed.
+func (r PatchResult) Failed() int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Error != "" {
+			n++
+		}
+		for _, h := range f.Hunks {
+			if !h.Applied {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// PatchTarget is the file access ApplyUnifiedDiff needs. Paths are relative
+// to the worktree root, and ReadFile reports a missing file with an error
+// satisfying errors.Is(err, fs.ErrNotExist).
+type PatchTarget interface {
+	ReadFile(path string) ([]byte, fs.FileMode, error)
+	WriteFile(path string, data []byte, mode fs.FileMode) error
+	Remove(path string) error
+}
+
+// ApplyUnifiedDiff applies patch, a unified diff in the format of diff -u or
+// git diff covering one or more files, to target. It is the shared core of
+// the ApplyPatch callbacks.
+//
+// Hunks are placed the way patch(1) places them: at the line the header
+// gives, or the nearest line where their context matches, first exactly,
+// then ignoring trailing whitespace, then ignoring all surrounding
+// whitespace, and then ignoring up to two context lines at either end. A
+// placement that ignores indentation must be the only one in the file, so
+// blocks that differ only in indentation are never confused.
+// Context lines keep the file's own text. Every file is patched in memory
+// before anything is written, so a failed hunk leaves the worktree untouched
+// and the returned PatchResult says which hunks failed and why.
+//
+// The returned error is reserved for patches that cannot be parsed and for
+// failures reading or writing target; if a write fails, the files already
+// written are restored on a best-effort basis.
+func ApplyUnifiedDiff(target PatchTarget, patch string) (PatchResult, error) {
+	files, err := parsePatch(patch)
+	if err != nil {
+		return PatchResult{}, err
+	}
+
+	state := &patchState{target: target, files: map[string]*patchFile{}}
+	result := PatchResult{Files: make([]PatchFileResult, 0, len(files))}
+	ok := true
+	for _, fp := range files {
+		fr, err := state.apply(fp)
+		if err != nil {
+			return PatchResult{}, err
+		}
+		if fr.Error != "" {
+			ok = false
+		}
+		for _, h := range fr.Hunks {
+			ok = ok && h.Applied
+		}
+		result.Files = append(result.Files, fr)
+	}
+	if !ok {
+		return result, nil
+	}
+	if err := state.commit(); err != nil {
+		return PatchResult{}, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// filePatch is the parsed patch for one file. An empty oldPath marks a new
+// file, and an empty newPath a deletion.
+type filePatch struct {
+	oldPath, newPath string
+	mode             fs.FileMode // from a "new file mode" or "new mode" line; zero keeps the file's
+	hunks            []hunk
+}
+
+// hunk is one parsed "@@" section.
+type hunk struct {
+	header             string
+	oldStart, oldLines int
+	newStart, newLines int
+	lines              []hunkLine
+	oldNoEOL, newNoEOL bool // "\ No newline at end of file" after the last old or new line
+}
+
+// hunkLine is one line of a hunk: op is ' ' for context, '-' for a removal
+// and '+' for an addition.
+type hunkLine struct {
+	op   byte
+	text string
+}
+
+// parsePatch splits a unified diff into its file patches.
+func parsePatch(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(patch, "\r\n", "\n"), "\n"), "\n")
+	var (
+		files []filePatch
+		cur   *filePatch
+		git   bool // cur began with a "diff --git" line
+	)
+	start := func() {
+		files = append(files, filePatch{})
+		cur = &files[len(files)-1]
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			start()
+			git = true
+			if a, b, ok := gitDiffPaths(strings.TrimPrefix(line, "diff --git ")); ok {
+				cur.oldPath, cur.newPath = a, b
+			}
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if cur == nil || !git || len(cur.hunks) > 0 {
+				start()
+				git = false
+			}
+			cur.oldPath, cur.newPath = headerPaths(strings.TrimPrefix(line, "--- "), strings.TrimPrefix(lines[i+1], "+++ "))
+			i++
+		case cur == nil:
+			// Preamble before the first file, such as a commit message.
+		case strings.HasPrefix(line, "new file mode "):
+			cur.oldPath = ""
+			cur.mode = parseMode(strings.TrimPrefix(line, "new file mode "))
+		case strings.HasPrefix(line, "deleted file mode "):
+			cur.newPath = ""
+		case strings.HasPrefix(line, "new mode "):
+			cur.mode = parseMode(strings.TrimPrefix(line, "new mode "))
+		case strings.HasPrefix(line, "rename from "):
+			cur.oldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			cur.newPath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			return nil, errors.New("binary patches are not supported")
+		case strings.HasPrefix(line, "@@ "):
+			h, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			cur.hunks = append(cur.hunks, h)
+			i = next - 1
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no file changes found; expected a unified diff with ---/+++ headers and @@ hunks")
+	}
+	for _, fp := range files {
+		if fp.oldPath == "" && fp.newPath == "" {
+			return nil, errors.New("file patch without a path")
+		}
+	}
+	return files, nil
+}
+
+// parseHunk parses the hunk whose header is lines[i], returning it and the
+// index of the first line after it.
+func parseHunk(lines []string, i int) (hunk, int, error) {
+	h := hunk{header: lines[i]}
+	ranges, _, ok := strings.Cut(strings.TrimPrefix(lines[i], "@@ "), " @@")
+	oldRange, newRange, ok2 := strings.Cut(ranges, " ")
+	if !ok || !ok2 || !strings.HasPrefix(oldRange, "-") || !strings.HasPrefix(newRange, "+") {
+		return hunk{}, 0, fmt.Errorf("malformed hunk header %q", lines[i])
+	}
+	var err error
+	if h.oldStart, h.oldLines, err = parseRange(oldRange[1:]); err != nil {
+		return hunk{}, 0, fmt.Errorf("malformed hunk header %q: %w", lines[i], err)
+	}
+	if h.newStart, h.newLines, err = parseRange(newRange[1:]); err != nil {
+		return hunk{}, 0, fmt.Errorf("malformed hunk header %q: %w", lines[i], err)
+	}
+
+	oldSeen, newSeen := 0, 0
+	i++
+	for ; i < len(lines) && (oldSeen < h.oldLines || newSeen < h.newLines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, `\`) {
+			h.markNoEOL()
+			continue
+		}
+		op, text := byte(' '), ""
+		if line != "" {
+			// An empty line is a context line whose trailing space was
+			// stripped by an editor.
+			op, text = line[0], line[1:]
+		}
+		switch op {
+		case ' ':
+			oldSeen++
+			newSeen++
+		case '-':
+			oldSeen++
+		case '+':
+			newSeen++
+		default:
+			return hunk{}, 0, fmt.Errorf("hunk %q: unexpected line %q; hunk lines start with ' ', '-' or '+'", h.header, line)
+		}
+		h.lines = append(h.lines, hunkLine{op: op, text: text})
+	}
+	if oldSeen != h.oldLines || newSeen != h.newLines {
+		return hunk{}, 0, fmt.Errorf("hunk %q: has %d old and %d new lines, header says %d and %d", h.header, oldSeen, newSeen, h.oldLines, h.newLines)
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		h.markNoEOL()
+		i++
+	}
+	return h, i, nil
+}
+
+// markNoEOL records a "\ No newline at end of file" marker against the side
+// of the hunk's last line.
+func (h *hunk) markNoEOL() {
+	if len(h.lines) == 0 {
+		return
+	}
+	switch h.lines[len(h.lines)-1].op {
+	case '-':
+		h.oldNoEOL = true
+	case '+':
+		h.newNoEOL = true
+	default:
+		h.oldNoEOL, h.newNoEOL = true, true
+	}
+}
+
+// parseRange parses the "l,s" or "l" of a hunk header.
+func parseRange(s string) (start, count int, err error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// parseMode parses an octal git file mode, keeping its permission bits.
+func parseMode(s string) fs.FileMode {
+	m, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil {
+		return 0
+	}
+	return fs.FileMode(m).Perm()
+}
+
+// headerPaths extracts the paths of a ---/+++ header pair, dropping
+// timestamps, /dev/null and git's a/ and b/ prefixes.
+func headerPaths(oldHeader, newHeader string) (string, string) {
+	oldPath, _, _ := strings.Cut(oldHeader, "\t")
+	newPath, _, _ := strings.Cut(newHeader, "\t")
+	if oldPath == "/dev/null" {
+		oldPath = ""
+	}
+	if newPath == "/dev/null" {
+		newPath = ""
+	}
+	if (oldPath == "" || strings.HasPrefix(oldPath, "a/")) && (newPath == "" || strings.HasPrefix(newPath, "b/")) {
+		oldPath = strings.TrimPrefix(oldPath, "a/")
+		newPath = strings.TrimPrefix(newPath, "b/")
+	}
+	return oldPath, newPath
+}
+
+// gitDiffPaths extracts the paths of a "diff --git a/x b/y" line, which is
+// all a mode change or pure rename carries.
+func gitDiffPaths(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, "a/") {
+		return "", "", false
+	}
+	i := strings.Index(s, " b/")
+	if i < 0 {
+		return "", "", false
+	}
+	return s[2:i], s[i+3:], true
+}
+
+// patchFile is a file's content as the patch so far leaves it.
+type patchFile struct {
+	data    []byte
+	mode    fs.FileMode
+	exists  bool
+	changed bool
+
+	// The file as it was before the patch, to restore after a failed write.
+	origData   []byte
+	origMode   fs.FileMode
+	origExists bool
+}
+
+// patchState patches files in memory, loading each from target on first
+// use, so every hunk of a patch is placed before anything is written.
+type patchState struct {
+	target PatchTarget
+	files  map[string]*patchFile
+	order  []string
+}
+
+func (s *patchState) file(path string) (*patchFile, error) {
+	if f, ok := s.files[path]; ok {
+		return f, nil
+	}
+	data, mode, err := s.target.ReadFile(path)
+	f := &patchFile{}
+	switch {
+	case err == nil:
+		f.data, f.mode, f.exists = data, mode, true
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return nil, err
+	}
+	f.origData, f.origMode, f.origExists = f.data, f.mode, f.exists
+	s.files[path] = f
+	s.order = append(s.order, path)
+	return f, nil
+}
+
+// apply patches fp in memory, reporting file and hunk failures in the
+// result.
+func (s *patchState) apply(fp filePatch) (PatchFileResult, error) {
+	fr := PatchFileResult{Path: fp.newPath}
+	switch {
+	case fp.oldPath == "":
+		fr.Type = "added"
+	case fp.newPath == "":
+		fr.Type, fr.Path = "deleted", fp.oldPath
+	case fp.oldPath != fp.newPath:
+		fr.Type, fr.OldPath = "renamed", fp.oldPath
+	default:
+		fr.Type = "modified"
+	}
+
+	var (
+		src  *patchFile
+		data []byte
+		mode fs.FileMode = 0o644
+	)
+	if fp.oldPath != "" {
+		var err error
+		if src, err = s.file(fp.oldPath); err != nil {
+			return PatchFileResult{}, err
+		}
+		if !src.exists {
+			fr.Error = fmt.Sprintf("%s does not exist", fp.oldPath)
+			return fr, nil
+		}
+		data, mode = src.data, src.mode
+	}
+	dst := src
+	if fp.newPath != fp.oldPath && fp.newPath != "" {
+		var err error
+		if dst, err = s.file(fp.newPath); err != nil {
+			return PatchFileResult{}, err
+		}
+		if dst.exists {
+			fr.Error = fmt.Sprintf("%s already exists", fp.newPath)
+			return fr, nil
+		}
+	}
+
+	out, hunks := applyHunks(data, fp.hunks)
+	fr.Hunks = hunks
+	for _, h := range hunks {
+		if !h.Applied {
+			return fr, nil
+		}
+	}
+	if fp.newPath == "" {
+		if len(out) != 0 {
+			fr.Error = fmt.Sprintf("%s has content the deletion does not remove", fp.oldPath)
+			return fr, nil
+		}
+		src.data, src.exists, src.changed = nil, false, true
+		return fr, nil
+	}
+	if fp.mode != 0 {
+		mode = fp.mode
+	}
+	if src != nil && src != dst {
+		src.data, src.exists, src.changed = nil, false, true
+	}
+	dst.data, dst.mode, dst.exists, dst.changed = out, mode, true, true
+	return fr, nil
+}
+
+// commit writes every changed file, restoring the ones already written if a
+// write fails.
+func (s *patchState) commit() error {
+	var done []string
+	for _, path := range s.order {
+		f := s.files[path]
+		if !f.changed {
+			continue
+		}
+		var err error
+		if f.exists {
+			err = s.target.WriteFile(path, f.data, f.mode)
+		} else if f.origExists {
+			err = s.target.Remove(path)
+		}
+		if err != nil {
+			for _, p := range done {
+				s.restore(p)
+			}
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		done = append(done, path)
+	}
+	return nil
+}
+
+// restore puts path back the way it was before the patch.
+func (s *patchState) restore(path string) {
+	f := s.files[path]
+	if f.origExists {
+		_ = s.target.WriteFile(path, f.origData, f.origMode)
+	} else {
+		_ = s.target.Remove(path)
+	}
+}
+
+// applyHunks applies hunks in order to data, returning the patched content
+// and the outcome of each hunk. The content is only meaningful when every
+// hunk applied.
+func applyHunks(data []byte, hunks []hunk) ([]byte, []HunkResult) {
+	lines, eol := splitLines(string(data))
+	var (
+		out     []string
+		pos     int // lines before pos are already copied to out
+		drift   int // how far the previous hunk landed from its header
+		results = make([]HunkResult, 0, len(hunks))
+	)
+	for _, h := range hunks {
+		res := HunkResult{Header: h.header}
+		// A hunk with no old lines inserts after line oldStart; any other
+		// starts at it.
+		base := h.oldStart - 1
+		if h.oldLines == 0 {
+			base = h.oldStart
+		}
+		at, body, fuzz, level, ok := placeHunk(lines, pos, base+drift, h)
+		if !ok {
+			res.Error = "could not find the hunk's context and removed lines in the file; re-read the file and regenerate the hunk"
+			results = append(results, res)
+			continue
+		}
+		res.Applied, res.Line, res.Offset, res.Fuzz, res.Whitespace = true, at+1, at-(base+fuzz), fuzz, level > 0
+		drift = at - (base + fuzz)
+
+		out = append(out, lines[pos:at]...)
+		j := at
+		for _, l := range body {
+			switch l.op {
+			case ' ':
+				out = append(out, lines[j]) // keep the file's own text
+				j++
+			case '-':
+				j++
+			case '+':
+				out = append(out, l.text)
+			}
+		}
+		pos = j
+		if pos == len(lines) {
+			switch {
+			case h.newNoEOL:
+				eol = false
+			case h.oldNoEOL:
+				eol = true
+			}
+		}
+		results = append(results, res)
+	}
+	out = append(out, lines[pos:]...)
+	return []byte(joinLines(out, eol)), results
+}
+
+// placeHunk finds where h applies in lines at or after pos, searching
+// outward from want. It returns the line index, the hunk lines left after
+// fuzz trimmed their context, the fuzz and the whitespace level used.
+func placeHunk(lines []string, pos, want int, h hunk) (int, []hunkLine, int, int, bool) {
+	lead, trail := 0, 0
+	for lead < len(h.lines) && h.lines[lead].op == ' ' {
+		lead++
+	}
+	for trail < len(h.lines)-lead && h.lines[len(h.lines)-1-trail].op == ' ' {
+		trail++
+	}
+	for fuzz := 0; fuzz <= maxPatchFuzz; fuzz++ {
+		cutLead, cutTrail := min(fuzz, lead), min(fuzz, trail)
+		if fuzz > 0 && cutLead < fuzz && cutTrail < fuzz {
+			break // nothing more to trim
+		}
+		body := h.lines[cutLead : len(h.lines)-cutTrail]
+		var old []string
+		for _, l := range body {
+			if l.op != '+' {
+				old = append(old, l.text)
+			}
+		}
+		if len(old) == 0 {
+			return min(max(want+cutLead, pos), len(lines)), body, cutLead, 0, true
+		}
+		for level := range 3 {
+			if at, ok := search(lines, pos, want+cutLead, old, level); ok {
+				return at, body, cutLead, level, true
+			}
+		}
+	}
+	return 0, nil, 0, 0, false
+}
+
+// search finds old in lines at or after pos, nearest to want first. Ignoring
+// leading whitespace (level 2) cannot tell apart blocks that differ only in
+// indentation, so at that level a match is only accepted when it is the only
+// one.
+func search(lines []string, pos, want int, old []string, level int) (int, bool) {
+	last := len(lines) - len(old)
+	for d := 0; want-d >= pos || want+d <= last; d++ {
+		for _, at := range []int{want + d, want - d} {
+			if at >= pos && at <= last && matches(lines[at:at+len(old)], old, level) {
+				return at, level < 2 || !matchesElsewhere(lines, pos, at, old, level)
+			}
+		}
+	}
+	return 0, false
+}
+
+// matchesElsewhere reports whether old also matches lines at or after pos
+// somewhere other than at.
+func matchesElsewhere(lines []string, pos, at int, old []string, level int) bool {
+	for i := pos; i <= len(lines)-len(old); i++ {
+		if i != at && matches(lines[i:i+len(old)], old, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches compares lines at a whitespace level: 0 exact, 1 ignoring
+// trailing whitespace, 2 ignoring leading and trailing whitespace.
+func matches(got, want []string, level int) bool {
+	for i := range want {
+		a, b := got[i], want[i]
+		switch level {
+		case 1:
+			a, b = strings.TrimRight(a, " \t\r"), strings.TrimRight(b, " \t\r")
+		case 2:
+			a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		}
+		if a != b {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits s into lines and reports whether it ends in a newline.
+func splitLines(s string) ([]string, bool) {
+	if s == "" {
+		return nil, true
+	}
+	eol := strings.HasSuffix(s, "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), eol
+}
+
+// joinLines is the inverse of splitLines.
+func joinLines(lines []string, eol bool) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	s := strings.Join(lines, "\n")
+	if eol {
+		s += "\n"
+	}
+	return s
+}
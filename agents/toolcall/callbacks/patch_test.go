@@ -0,0 +1,261 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package callbacks_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+const patchSource = `package main
+
+func main() {
+	println("one")
+	println("two")
+	println("three")
+}
+`
+
+func TestApplyPatch(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		patch string
+		want  map[string]string // "" marks a file that must not exist
+		check func(*testing.T, callbacks.PatchResult)
+	}{{
+		name:  "exact",
+		files: map[string]string{"main.go": patchSource},
+		patch: `diff --git a/main.go b/main.go
+index 0000000..1111111 100644
+--- a/main.go
++++ b/main.go
+@@ -4,3 +4,3 @@ func main() {
+ 	println("one")
+-	println("two")
++	println("TWO")
+ 	println("three")
+`,
+		want: map[string]string{"main.go": strings.Replace(patchSource, `"two"`, `"TWO"`, 1)},
+		check: func(t *testing.T, res callbacks.PatchResult) {
+			h := res.Files[0].Hunks[0]
+			if h.Line != 4 || h.Offset != 0 || h.Fuzz != 0 || h.Whitespace {
+				t.Errorf("hunk: got = %+v, want an exact placement at line 4", h)
+			}
+		},
+	}, {
+		name:  "offset and whitespace",
+		files: map[string]string{"main.go": "// header\n// more\n" + patchSource},
+		patch: `--- main.go
++++ main.go
+@@ -4,3 +4,3 @@
+   println("one")
+-  println("two")
++	println("TWO")
+   println("three")
+`,
+		want: map[string]string{"main.go": "// header\n// more\n" + strings.Replace(patchSource, `"two"`, `"TWO"`, 1)},
+		check: func(t *testing.T, res callbacks.PatchResult) {
+			h := res.Files[0].Hunks[0]
+			if h.Line != 6 || h.Offset != 2 || !h.Whitespace {
+				t.Errorf("hunk: got = %+v, want line 6, offset 2, whitespace-insensitive", h)
+			}
+		},
+	}, {
+		name:  "fuzz",
+		files: map[string]string{"main.go": patchSource},
+		patch: `--- a/main.go
++++ b/main.go
+@@ -3,5 +3,5 @@
+ func main() {
+ 	println("one")
+-	println("two")
++	println("TWO")
+ 	println("three")
+ } // stale context
+`,
+		want: map[string]string{"main.go": strings.Replace(patchSource, `"two"`, `"TWO"`, 1)},
+		check: func(t *testing.T, res callbacks.PatchResult) {
+			if h := res.Files[0].Hunks[0]; h.Fuzz != 1 {
+				t.Errorf("hunk: got = %+v, want fuzz 1", h)
+			}
+		},
+	}, {
+		name:  "create, delete and rename",
+		files: map[string]string{"old.txt": "a\nb\n", "gone.txt": "bye\n"},
+		patch: `diff --git a/new.txt b/new.txt
+new file mode 100755
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++#!/bin/sh
++echo hi
+\ No newline at end of file
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/old.txt b/dir/renamed.txt
+similarity index 50%
+rename from old.txt
+rename to dir/renamed.txt
+--- a/old.txt
++++ b/dir/renamed.txt
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+`,
+		want: map[string]string{
+			"new.txt":         "#!/bin/sh\necho hi",
+			"gone.txt":        "",
+			"old.txt":         "",
+			"dir/renamed.txt": "a\nc\n",
+		},
+		check: func(t *testing.T, res callbacks.PatchResult) {
+			var types []string
+			for _, f := range res.Files {
+				types = append(types, f.Type)
+			}
+			if got := strings.Join(types, ","); got != "added,deleted,renamed" {
+				t.Errorf("types: got = %s, want = added,deleted,renamed", got)
+			}
+		},
+	}, {
+		name:  "several hunks in one file",
+		files: map[string]string{"n.txt": "1\n2\n3\n4\n5\n6\n7\n8\n9\n"},
+		patch: `--- a/n.txt
++++ b/n.txt
+@@ -1,3 +1,4 @@
+ 1
++1.5
+ 2
+ 3
+@@ -7,3 +8,2 @@
+ 7
+-8
+ 9
+`,
+		want: map[string]string{"n.txt": "1\n1.5\n2\n3\n4\n5\n6\n7\n9\n"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := openTestRoot(t, tc.files)
+			res, err := callbacks.LocalWorktree(r).ApplyPatch(t.Context(), tc.patch)
+			if err != nil {
+				t.Fatalf("ApplyPatch: %v", err)
+			}
+			if !res.Applied || res.Failed() != 0 {
+				t.Fatalf("result: got = %+v, want applied", res)
+			}
+			for path, want := range tc.want {
+				got, err := r.ReadFile(path)
+				switch {
+				case want == "" && !errors.Is(err, fs.ErrNotExist):
+					t.Errorf("%s: got err = %v, want it removed", path, err)
+				case want != "" && string(got) != want:
+					t.Errorf("%s: got = %q, want = %q", path, got, want)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, res)
+			}
+		})
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	r := openTestRoot(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+	res, err := callbacks.LocalWorktree(r).ApplyPatch(t.Context(), `--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-a
++A
+--- a/b.txt
++++ b/b.txt
+@@ -1 +1 @@
+-not in the file
++B
+--- a/missing.txt
++++ b/missing.txt
+@@ -1 +1 @@
+-x
++y
+`)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if res.Applied || res.Failed() != 2 {
+		t.Fatalf("result: got = %+v, want two failures and nothing applied", res)
+	}
+	if !res.Files[0].Hunks[0].Applied || res.Files[1].Hunks[0].Error == "" || res.Files[2].Error == "" {
+		t.Errorf("files: got = %+v, want the b.txt hunk and missing.txt reported", res.Files)
+	}
+	if got, _ := r.ReadFile("a.txt"); string(got) != "a\n" {
+		t.Errorf("a.txt: got = %q, want it unchanged", got)
+	}
+}
+
+func TestApplyPatchIndentationTwins(t *testing.T) {
+	// Two blocks that differ only in indentation.
+	const twins = "if x {\n\ty()\n}\n\tif x {\n\t\ty()\n\t}\n"
+
+	r := openTestRoot(t, map[string]string{"a.txt": twins})
+	cb := callbacks.LocalWorktree(r)
+
+	// Both blocks match once indentation is ignored, so the hunk fails
+	// rather than landing on whichever is nearer its header.
+	res, err := cb.ApplyPatch(t.Context(), "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n  if x {\n-    y()\n+    z()\n  }\n")
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if res.Applied || res.Failed() != 1 {
+		t.Errorf("ambiguous hunk: got = %+v, want it rejected", res)
+	}
+	if got, _ := r.ReadFile("a.txt"); string(got) != twins {
+		t.Errorf("a.txt: got = %q, want it unchanged", got)
+	}
+
+	// A hunk matching one block exactly applies there, even with a header
+	// pointing at the other.
+	res, err = cb.ApplyPatch(t.Context(), "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n \tif x {\n-\t\ty()\n+\t\tz()\n \t}\n")
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if h := res.Files[0].Hunks[0]; !res.Applied || h.Line != 4 || h.Whitespace {
+		t.Errorf("exact hunk: got = %+v, want an exact placement at line 4", res)
+	}
+	if got, _ := r.ReadFile("a.txt"); string(got) != strings.Replace(twins, "\t\ty()", "\t\tz()", 1) {
+		t.Errorf("a.txt: got = %q, want the indented block patched", got)
+	}
+}
+
+func TestApplyPatchRejectsMalformed(t *testing.T) {
+	r := openTestRoot(t, map[string]string{"a.txt": "a\n"})
+	cb := callbacks.LocalWorktree(r)
+	for _, tc := range []struct {
+		name, patch string
+	}{
+		{"not a diff", "please change a to b"},
+		{"short hunk", "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n-a\n+b\n"},
+		{"bad header", "--- a/a.txt\n+++ b/a.txt\n@@ -x +1 @@\n-a\n+b\n"},
+		{"binary", "diff --git a/a.bin b/a.bin\nBinary files a/a.bin and b/a.bin differ\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := cb.ApplyPatch(t.Context(), tc.patch); err == nil {
+				t.Error("ApplyPatch: got = nil, want an error")
+			}
+		})
+	}
+	if _, err := cb.ApplyPatch(t.Context(), "--- a/../escape.txt\n+++ b/../escape.txt\n@@ -0,0 +1 @@\n+x\n"); err == nil {
+		t.Error("ApplyPatch outside the root: got = nil, want an error")
+	}
+}
@@ -125,6 +125,29 @@ type WorktreeCallbacks struct {
 	// in the file. Changes are automatically staged.
 	EditFile func(ctx context.Context, path, oldString, newString string, replaceAll bool) (EditResult, error)
 
+	// ApplyPatch applies a unified diff that may span several files, creating,
+	// deleting and renaming files as it says. The patch applies atomically:
+	// if any hunk cannot be placed, no file changes and the result reports
+	// which hunks failed. The error is for malformed patches and I/O failures.
+	// See ApplyUnifiedDiff. Changes are automatically staged.
+	ApplyPatch func(ctx context.Context, patch string) (PatchResult, error)
+
+	// CreateCheckpoint snapshots the worktree and returns an ID for
+	// DiffSinceCheckpoint and RestoreCheckpoint. StartCheckpoint names the
+	// worktree as it was before any change and needs no call.
+	CreateCheckpoint func(ctx context.Context) (string, error)
+
+	// DiffSinceCheckpoint returns the unified diff from the checkpoint with
+	// the given ID to the current worktree (see DiffChanges). Results are
+	// paginated by byte offset and limit. A negative offset is invalid and
+	// must return an error.
+	DiffSinceCheckpoint func(ctx context.Context, id string, offset int64, limit int) (CheckpointDiffResult, error)
+
+	// RestoreCheckpoint returns the worktree to the checkpoint with the given
+	// ID and reports the paths it changed, sorted. The checkpoint stays
+	// valid; those created after it are discarded.
+	RestoreCheckpoint func(ctx context.Context, id string) ([]string, error)
+
 	// SearchCodebase searches for a regex pattern across files. The filter
 	// parameter supports glob patterns (with * wildcards) or exact filename
 	// matching. Results are paginated by offset and limit. A negative offset
@@ -0,0 +1,138 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/params"
+)
+
+// maxCheckpointDiffLimit is the maximum number of bytes a single
+// diff_since_checkpoint call returns.
+const maxCheckpointDiffLimit = 100000
+
+func createCheckpointTool[Resp any](createCheckpoint func(context.Context) (string, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "create_checkpoint",
+			Description: "Save the current state of the repository files so you can later see what changed since (diff_since_checkpoint) or undo it (restore_checkpoint). " +
+				"Take one before trying an approach you may want to back out of.",
+			Annotations: &ToolAnnotations{
+				Destructive: Ptr(false),
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{})
+
+			id, err := createCheckpoint(ctx)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to create checkpoint", err)
+			}
+
+			resp := map[string]any{"checkpoint_id": id}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func diffSinceCheckpointTool[Resp any](diffSinceCheckpoint func(context.Context, string, int64, int) (callbacks.CheckpointDiffResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name:        "diff_since_checkpoint",
+			Description: fmt.Sprintf("Show the unified diff of every file changed since a checkpoint. Omit checkpoint_id (or pass %q) for all changes made so far.", callbacks.StartCheckpoint),
+			Parameters: []Parameter{
+				{Name: "checkpoint_id", Type: "string", Description: fmt.Sprintf("The checkpoint_id returned by create_checkpoint (default: %q)", callbacks.StartCheckpoint), Required: false},
+				{Name: "offset", Type: "integer", Description: "Byte offset into the diff to start reading from (default: 0)", Required: false, Minimum: Ptr[float64](0)},
+				{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum bytes of diff to return (default: 20000, max: %d)", maxCheckpointDiffLimit), Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			id, errResp := OptionalParam[string](call, "checkpoint_id", callbacks.StartCheckpoint)
+			if errResp != nil {
+				return errResp
+			}
+			offset, errResp := OptionalParam[int64](call, "offset", 0)
+			if errResp != nil {
+				return errResp
+			}
+			if errResp := rejectNegativeOffset(call, trace, offset); errResp != nil {
+				return errResp
+			}
+			limit, errResp := OptionalParam[int](call, "limit", 20000)
+			if errResp != nil {
+				return errResp
+			}
+			if limit <= 0 || limit > maxCheckpointDiffLimit {
+				return params.Error("limit must be between 1 and %d, got %d", maxCheckpointDiffLimit, limit)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"checkpoint_id": id, "offset": offset, "limit": limit})
+
+			result, err := diffSinceCheckpoint(ctx, id, offset, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to diff since checkpoint", err, "checkpoint_id", id)
+			}
+
+			resp := map[string]any{
+				"files": result.Files,
+				"diff":  result.Diff,
+			}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+			}
+			resp["remaining"] = result.Remaining
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func restoreCheckpointTool[Resp any](restoreCheckpoint func(context.Context, string) ([]string, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "restore_checkpoint",
+			Description: fmt.Sprintf("Undo every file change made since a checkpoint, returning the repository to the state it saved. Pass %q to undo all changes. ", callbacks.StartCheckpoint) +
+				"The checkpoint can be restored again; checkpoints created after it are discarded.",
+			Parameters: []Parameter{
+				{Name: "checkpoint_id", Type: "string", Description: fmt.Sprintf("The checkpoint_id returned by create_checkpoint, or %q", callbacks.StartCheckpoint), Required: true},
+			},
+			Annotations: &ToolAnnotations{
+				Destructive: Ptr(true),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			id, errResp := Param[string](call, trace, "checkpoint_id")
+			if errResp != nil {
+				return errResp
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"checkpoint_id": id})
+
+			restored, err := restoreCheckpoint(ctx, id)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to restore checkpoint", err, "checkpoint_id", id)
+			}
+
+			resp := map[string]any{"checkpoint_id": id, "restored": restored}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
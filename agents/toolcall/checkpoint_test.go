@@ -0,0 +1,87 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestCheckpointTools(t *testing.T) {
+	r, err := os.OpenRoot(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenRoot: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	cb := callbacks.LocalWorktree(r)
+	tools := worktreeToolDefs[string](cb)
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+		resp := tools[name].Handler(t.Context(), ToolCall{ID: "1", Name: name, Args: args}, trace, nil)
+		if msg, ok := resp["error"]; ok {
+			t.Fatalf("%s: %v", name, msg)
+		}
+		return resp
+	}
+
+	if err := cb.WriteFile(t.Context(), "main.go", "package main\n", 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	id, _ := call("create_checkpoint", nil)["checkpoint_id"].(string)
+	if id == "" {
+		t.Fatal("create_checkpoint returned no checkpoint_id")
+	}
+	if err := cb.WriteFile(t.Context(), "main.go", "package other\n", 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	resp := call("diff_since_checkpoint", map[string]any{"checkpoint_id": id})
+	if files, _ := resp["files"].([]string); !slices.Equal(files, []string{"main.go"}) {
+		t.Errorf("files: got = %v, want = [main.go]", resp["files"])
+	}
+	if diff, _ := resp["diff"].(string); !strings.Contains(diff, "-package main\n+package other\n") {
+		t.Errorf("diff: got = %q, want the rename of the package", diff)
+	}
+	if diff, _ := call("diff_since_checkpoint", nil)["diff"].(string); !strings.Contains(diff, "new file mode") {
+		t.Errorf("diff since the start: got = %q, want main.go created", diff)
+	}
+
+	resp = call("restore_checkpoint", map[string]any{"checkpoint_id": id})
+	if restored, _ := resp["restored"].([]string); !slices.Equal(restored, []string{"main.go"}) {
+		t.Errorf("restored: got = %v, want = [main.go]", resp["restored"])
+	}
+	if got, _ := r.ReadFile("main.go"); string(got) != "package main\n" {
+		t.Errorf("main.go: got = %q, want the checkpointed content", got)
+	}
+}
+
+func TestCheckpointToolsRejectBadArgs(t *testing.T) {
+	tools := worktreeToolDefs[string](callbacks.LocalWorktree(nil))
+	for _, tc := range []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"negative offset", "diff_since_checkpoint", map[string]any{"offset": float64(-1)}, "offset must be >= 0"},
+		{"limit too large", "diff_since_checkpoint", map[string]any{"limit": float64(maxCheckpointDiffLimit + 1)}, "limit"},
+		{"missing id", "restore_checkpoint", map[string]any{}, "checkpoint_id"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+			resp := tools[tc.tool].Handler(t.Context(), ToolCall{ID: "1", Name: tc.tool, Args: tc.args}, trace, nil)
+			if msg, _ := resp["error"].(string); !strings.Contains(msg, tc.want) {
+				t.Errorf("response: got = %v, want an error mentioning %q", resp, tc.want)
+			}
+		})
+	}
+}
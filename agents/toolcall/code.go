@@ -0,0 +1,342 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/params"
+)
+
+// CodeTools wraps a base tools type and adds code-navigation callbacks.
+type CodeTools[T any] struct {
+	base T
+	callbacks.CodeCallbacks
+}
+
+// NewCodeTools creates a CodeTools wrapping the given base tools.
+func NewCodeTools[T any](base T, cb callbacks.CodeCallbacks) CodeTools[T] {
+	return CodeTools[T]{base: base, CodeCallbacks: cb}
+}
+
+// codeToolsProvider wraps a base ToolProvider and adds code-navigation tools.
+type codeToolsProvider[Resp, T any] struct {
+	baseProvider ToolProvider[Resp, T]
+}
+
+var _ ToolProvider[any, CodeTools[any]] = (*codeToolsProvider[any, any])(nil)
+
+// NewCodeToolsProvider creates a provider that adds code-navigation tools
+// (find_definition, find_references, list_package_symbols, get_type_info) on
+// top of the base provider's tools.
+func NewCodeToolsProvider[Resp, T any](base ToolProvider[Resp, T]) ToolProvider[Resp, CodeTools[T]] {
+	return codeToolsProvider[Resp, T]{baseProvider: base}
+}
+
+func (p codeToolsProvider[Resp, T]) Tools(ctx context.Context, cb CodeTools[T]) (map[string]Tool[Resp], error) {
+	tools, err := p.baseProvider.Tools(ctx, cb.base)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(tools, codeToolDefs[Resp](cb.CodeCallbacks))
+	return tools, nil
+}
+
+// maxFindReferencesLimit is the maximum number of references a single
+// find_references call returns.
+const maxFindReferencesLimit = 500
+
+func codeToolDefs[Resp any](cb callbacks.CodeCallbacks) map[string]Tool[Resp] {
+	tools := make(map[string]Tool[Resp], 4)
+	if cb.FindDefinition != nil {
+		tools["find_definition"] = findDefinitionTool[Resp](cb.FindDefinition)
+	}
+	if cb.FindReferences != nil {
+		tools["find_references"] = findReferencesTool[Resp](cb.FindReferences)
+	}
+	if cb.ListPackageSymbols != nil {
+		tools["list_package_symbols"] = listPackageSymbolsTool[Resp](cb.ListPackageSymbols)
+	}
+	if cb.GetTypeInfo != nil {
+		tools["get_type_info"] = getTypeInfoTool[Resp](cb.GetTypeInfo)
+	}
+	return tools
+}
+
+// symbolQueryParameters are the parameters selecting a symbol, shared by the
+// tools that take a SymbolQuery.
+var symbolQueryParameters = []Parameter{
+	{Name: "name", Type: "string", Description: `Symbol name as written in code: "Name", "Type.Method", "Type.Field", optionally qualified by package name ("pkg.Name"). Use this or path.`, Required: false},
+	{Name: "package", Type: "string", Description: `Restrict a name search to the package in this directory, relative to the repository root; append "/..." to include subdirectories`, Required: false},
+	{Name: "path", Type: "string", Description: "Go file containing an identifier to resolve, relative to the repository root. Use this or name.", Required: false},
+	{Name: "line", Type: "integer", Description: "1-based line of the identifier in path", Required: false, Minimum: Ptr[float64](0)},
+	{Name: "column", Type: "integer", Description: "1-based byte column of the identifier on line. Omit to use the first symbol on the line.", Required: false, Minimum: Ptr[float64](0)},
+	{Name: "offset", Type: "integer", Description: "Byte offset of the identifier in path, instead of line and column", Required: false, Minimum: Ptr[float64](0)},
+}
+
+// symbolQueryParam reads the symbol-selecting parameters of a call, along
+// with the attributes recording them on the trace.
+func symbolQueryParam[Resp any](call ToolCall, trace *agenttrace.Trace[Resp]) (callbacks.SymbolQuery, map[string]any, map[string]any) {
+	var q callbacks.SymbolQuery
+	var errResp map[string]any
+	if q.Name, errResp = OptionalParam[string](call, "name", ""); errResp != nil {
+		return q, nil, errResp
+	}
+	if q.Package, errResp = OptionalParam[string](call, "package", ""); errResp != nil {
+		return q, nil, errResp
+	}
+	if q.Path, errResp = OptionalParam[string](call, "path", ""); errResp != nil {
+		return q, nil, errResp
+	}
+	if q.Line, errResp = OptionalParam[int](call, "line", 0); errResp != nil {
+		return q, nil, errResp
+	}
+	if q.Column, errResp = OptionalParam[int](call, "column", 0); errResp != nil {
+		return q, nil, errResp
+	}
+	if q.Offset, errResp = OptionalParam[int64](call, "offset", 0); errResp != nil {
+		return q, nil, errResp
+	}
+	if errResp := rejectNegativeOffset(call, trace, q.Offset); errResp != nil {
+		return q, nil, errResp
+	}
+	switch {
+	case q.Name == "" && q.Path == "":
+		return q, nil, params.Error("either name or path is required")
+	case q.Line < 0 || q.Column < 0:
+		return q, nil, params.Error("line and column must be >= 1, got %d:%d", q.Line, q.Column)
+	}
+
+	attrs := map[string]any{}
+	if q.Path != "" {
+		attrs["path"], attrs["line"], attrs["column"], attrs["offset"] = q.Path, q.Line, q.Column, q.Offset
+	} else {
+		attrs["name"], attrs["package"] = q.Name, q.Package
+	}
+	return q, attrs, nil
+}
+
+func findDefinitionTool[Resp any](findDefinition func(context.Context, callbacks.SymbolQuery) ([]callbacks.Symbol, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "find_definition",
+			Description: "Find where a Go symbol is declared, by name or by the position of an identifier that uses it. " +
+				"Returns each matching declaration's location, signature and doc comment.",
+			Parameters: symbolQueryParameters,
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			q, attrs, errResp := symbolQueryParam(call, trace)
+			if errResp != nil {
+				return errResp
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, attrs)
+
+			syms, err := findDefinition(ctx, q)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to find definition", err, "name", q.Name, "path", q.Path)
+			}
+
+			resp := map[string]any{"definitions": formatSymbols(syms)}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func findReferencesTool[Resp any](findReferences func(context.Context, callbacks.SymbolQuery, int, int) (callbacks.ReferenceResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "find_references",
+			Description: "Find every use of a Go symbol across the repository, by name or by the position of an identifier. " +
+				"Use it to see what a change to a function, type, method or field would affect.",
+			Parameters: append(append([]Parameter(nil), symbolQueryParameters...),
+				Parameter{Name: "result_offset", Type: "integer", Description: "Number of references to skip (default: 0)", Required: false, Minimum: Ptr[float64](0)},
+				Parameter{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum references to return (default: 50, max: %d)", maxFindReferencesLimit), Required: false},
+			),
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			q, attrs, errResp := symbolQueryParam(call, trace)
+			if errResp != nil {
+				return errResp
+			}
+			offset, errResp := OptionalParam[int](call, "result_offset", 0)
+			if errResp != nil {
+				return errResp
+			}
+			if offset < 0 {
+				return params.Error("result_offset must be >= 0, got %d", offset)
+			}
+			limit, errResp := OptionalParam[int](call, "limit", 50)
+			if errResp != nil {
+				return errResp
+			}
+			if limit <= 0 || limit > maxFindReferencesLimit {
+				return params.Error("limit must be between 1 and %d, got %d", maxFindReferencesLimit, limit)
+			}
+			attrs["result_offset"], attrs["limit"] = offset, limit
+
+			tc := trace.StartToolCall(call.ID, call.Name, attrs)
+
+			result, err := findReferences(ctx, q, offset, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to find references", err, "name", q.Name, "path", q.Path)
+			}
+
+			refs := make([]map[string]any, 0, len(result.References))
+			for _, r := range result.References {
+				refs = append(refs, map[string]any{
+					"path":   r.Path,
+					"line":   r.Line,
+					"column": r.Column,
+					"text":   r.Text,
+				})
+			}
+			resp := map[string]any{
+				"symbols":    formatSymbols(result.Symbols),
+				"references": refs,
+				"total":      result.Total,
+			}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+			}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func listPackageSymbolsTool[Resp any](listPackageSymbols func(context.Context, string, bool) ([]callbacks.Symbol, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name:        "list_package_symbols",
+			Description: "List the declarations of the Go package in a directory: its types with their methods, functions, variables and constants, each with a one-line signature and doc summary.",
+			Parameters: []Parameter{
+				{Name: "dir", Type: "string", Description: `Package directory, relative to the repository root ("." for the root)`, Required: true},
+				{Name: "include_unexported", Type: "boolean", Description: "Also list unexported symbols (default: false)", Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			dir, errResp := Param[string](call, trace, "dir")
+			if errResp != nil {
+				return errResp
+			}
+			includeUnexported, errResp := OptionalParam[bool](call, "include_unexported", false)
+			if errResp != nil {
+				return errResp
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"dir": dir, "include_unexported": includeUnexported})
+
+			syms, err := listPackageSymbols(ctx, dir, includeUnexported)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to list package symbols", err, "dir", dir)
+			}
+
+			resp := map[string]any{"dir": dir, "symbols": formatSymbols(syms)}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func getTypeInfoTool[Resp any](getTypeInfo func(context.Context, callbacks.SymbolQuery) ([]callbacks.TypeInfo, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "get_type_info",
+			Description: "Describe the type of a Go symbol, by name or by the position of an identifier: its type and underlying type, " +
+				"and for a type or a variable of one, its struct fields and method set, including promoted methods.",
+			Parameters: symbolQueryParameters,
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			q, attrs, errResp := symbolQueryParam(call, trace)
+			if errResp != nil {
+				return errResp
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, attrs)
+
+			infos, err := getTypeInfo(ctx, q)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to get type info", err, "name", q.Name, "path", q.Path)
+			}
+
+			entries := make([]map[string]any, 0, len(infos))
+			for _, info := range infos {
+				entry := formatSymbol(info.Symbol)
+				entry["type"] = info.Type
+				if info.Underlying != "" {
+					entry["underlying"] = info.Underlying
+				}
+				if len(info.Fields) > 0 {
+					entry["fields"] = info.Fields
+				}
+				if len(info.Methods) > 0 {
+					entry["methods"] = info.Methods
+				}
+				entries = append(entries, entry)
+			}
+			resp := map[string]any{"types": entries}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+// formatSymbols converts Symbols into the JSON response form.
+func formatSymbols(syms []callbacks.Symbol) []map[string]any {
+	out := make([]map[string]any, 0, len(syms))
+	for _, s := range syms {
+		out = append(out, formatSymbol(s))
+	}
+	return out
+}
+
+func formatSymbol(s callbacks.Symbol) map[string]any {
+	m := map[string]any{
+		"name":    s.Name,
+		"kind":    s.Kind,
+		"package": s.Package,
+	}
+	if s.Path != "" {
+		m["path"], m["line"], m["column"] = s.Path, s.Line, s.Column
+	}
+	if s.Signature != "" {
+		m["signature"] = s.Signature
+	}
+	if s.Doc != "" {
+		m["doc"] = s.Doc
+	}
+	return m
+}
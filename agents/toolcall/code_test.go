@@ -0,0 +1,113 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/gocode"
+)
+
+func TestCodeTools(t *testing.T) {
+	tools := codeToolDefs[string](gocode.Callbacks(fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/m\n")},
+		"lib/lib.go": {Data: []byte(`package lib
+
+// Counter counts.
+type Counter struct{ N int }
+
+// Inc increments the counter.
+func (c *Counter) Inc() { c.N++ }
+`)},
+		"main.go": {Data: []byte(`package main
+
+import "example.com/m/lib"
+
+func main() {
+	var c lib.Counter
+	c.Inc()
+	c.Inc()
+}
+`)},
+	}))
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+		resp := tools[name].Handler(t.Context(), ToolCall{ID: "1", Name: name, Args: args}, trace, nil)
+		if msg, ok := resp["error"]; ok {
+			t.Fatalf("%s: %v", name, msg)
+		}
+		return resp
+	}
+
+	defs, _ := call("find_definition", map[string]any{"path": "main.go", "line": float64(7), "column": float64(4)})["definitions"].([]map[string]any)
+	if len(defs) != 1 || defs[0]["name"] != "Counter.Inc" || defs[0]["path"] != "lib/lib.go" || defs[0]["doc"] != "Inc increments the counter." {
+		t.Errorf("definitions: got = %v, want Counter.Inc in lib/lib.go", defs)
+	}
+
+	resp := call("find_references", map[string]any{"name": "Counter.Inc", "limit": float64(1)})
+	if resp["total"] != 2 || resp["next_offset"] != 1 {
+		t.Errorf("references: got = %v, want 2 in pages of 1", resp)
+	}
+	if refs, _ := resp["references"].([]map[string]any); len(refs) != 1 || refs[0]["text"] != "c.Inc()" {
+		t.Errorf("first reference: got = %v, want c.Inc()", resp["references"])
+	}
+
+	syms, _ := call("list_package_symbols", map[string]any{"dir": "lib"})["symbols"].([]map[string]any)
+	var names []string
+	for _, s := range syms {
+		names = append(names, s["name"].(string))
+	}
+	if want := []string{"Counter", "Counter.Inc"}; !slices.Equal(names, want) {
+		t.Errorf("symbols: got = %q, want = %q", names, want)
+	}
+
+	types, _ := call("get_type_info", map[string]any{"name": "lib.Counter"})["types"].([]map[string]any)
+	if len(types) != 1 || !slices.Equal(types[0]["fields"].([]string), []string{"N int"}) || !slices.Equal(types[0]["methods"].([]string), []string{"Inc()"}) {
+		t.Errorf("type info: got = %v, want Counter's field and method", types)
+	}
+}
+
+func TestCodeToolsRejectBadArgs(t *testing.T) {
+	cb := callbacks.CodeCallbacks{
+		FindDefinition: func(context.Context, callbacks.SymbolQuery) ([]callbacks.Symbol, error) { return nil, nil },
+		FindReferences: func(context.Context, callbacks.SymbolQuery, int, int) (callbacks.ReferenceResult, error) {
+			return callbacks.ReferenceResult{}, nil
+		},
+		ListPackageSymbols: func(context.Context, string, bool) ([]callbacks.Symbol, error) { return nil, nil },
+	}
+	tools := codeToolDefs[string](cb)
+	if _, ok := tools["get_type_info"]; ok {
+		t.Error("get_type_info: registered without a GetTypeInfo callback")
+	}
+	for _, tc := range []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"no symbol", "find_definition", map[string]any{}, "name or path"},
+		{"negative offset", "find_definition", map[string]any{"path": "a.go", "offset": float64(-1)}, "offset must be >= 0"},
+		{"negative line", "find_definition", map[string]any{"path": "a.go", "line": float64(-2)}, "line and column"},
+		{"limit too large", "find_references", map[string]any{"name": "X", "limit": float64(maxFindReferencesLimit + 1)}, "limit"},
+		{"negative result offset", "find_references", map[string]any{"name": "X", "result_offset": float64(-1)}, "result_offset"},
+		{"missing dir", "list_package_symbols", map[string]any{}, "dir"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+			resp := tools[tc.tool].Handler(t.Context(), ToolCall{ID: "1", Name: tc.tool, Args: tc.args}, trace, nil)
+			if msg, _ := resp["error"].(string); !strings.Contains(msg, tc.want) {
+				t.Errorf("response: got = %v, want an error mentioning %q", resp, tc.want)
+			}
+		})
+	}
+}
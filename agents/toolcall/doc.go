@@ -59,5 +59,24 @@ SPDX-License-Identifier: Apache-2.0
 // Factory functions for callbacks are provided by other packages:
 //   - WorktreeCallbacks: clonemanager.WorktreeCallbacks(worktree)
 //   - FindingCallbacks: session.FindingCallbacks()
-//   - HistoryCallbacks: clonemanager.HistoryCallbacks(repo, baseCommit)
+//   - HistoryCallbacks: clonemanager.HistoryCallbacks(repo, baseCommit), or
+//     lease.HistoryCallbacks() to fetch older history on demand
+//   - ExecCallbacks: clonemanager.ExecCallbacks(worktree, config), closing the
+//     worktree root it returns when done, or
+//     callbacks.LocalExec(root, config)
+//   - CodeCallbacks: gocode.Callbacks(fsys), with gocode.WithRoot(dir) to load
+//     packages and their dependencies through go/packages
+//
+// CodeTools adds find_definition, find_references, list_package_symbols and
+// get_type_info, backed by CodeCallbacks; gocode.Callbacks provides them for
+// the Go code in any fs.FS, such as worktreefs.New over a leased worktree.
+// Compose with NewCodeTools(tools, cc) and NewCodeToolsProvider.
+//
+// HistoryTools always offers list_commits and get_file_diff. It adds blame,
+// log and show_file_at_revision when the corresponding HistoryCallbacks are
+// set, which clonemanager does.
+//
+// ExecTools adds run_command and read_command_output, so an agent can build
+// and test its changes before submitting them. It composes like the others:
+// NewExecTools(tools, ec) with NewExecToolsProvider.
 package toolcall
@@ -0,0 +1,212 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/params"
+)
+
+// ExecTools wraps a base tools type and adds command-execution callbacks.
+type ExecTools[T any] struct {
+	base T
+	callbacks.ExecCallbacks
+}
+
+// NewExecTools creates an ExecTools wrapping the given base tools.
+func NewExecTools[T any](base T, cb callbacks.ExecCallbacks) ExecTools[T] {
+	return ExecTools[T]{base: base, ExecCallbacks: cb}
+}
+
+// execToolsProvider wraps a base ToolProvider and adds command-execution
+// tools.
+type execToolsProvider[Resp, T any] struct {
+	baseProvider ToolProvider[Resp, T]
+}
+
+var _ ToolProvider[any, ExecTools[any]] = (*execToolsProvider[any, any])(nil)
+
+// NewExecToolsProvider creates a provider that adds command-execution tools
+// (run_command, read_command_output) on top of the base provider's tools.
+func NewExecToolsProvider[Resp, T any](base ToolProvider[Resp, T]) ToolProvider[Resp, ExecTools[T]] {
+	return execToolsProvider[Resp, T]{baseProvider: base}
+}
+
+func (p execToolsProvider[Resp, T]) Tools(ctx context.Context, cb ExecTools[T]) (map[string]Tool[Resp], error) {
+	tools, err := p.baseProvider.Tools(ctx, cb.base)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(tools, execToolDefs[Resp](cb.ExecCallbacks))
+	return tools, nil
+}
+
+const (
+	// defaultCommandOutputLimit is the number of output bytes run_command and
+	// read_command_output return when the model does not ask for a limit.
+	defaultCommandOutputLimit = 20000
+
+	// maxCommandOutputLimit is the maximum number of output bytes a single
+	// run_command or read_command_output call returns.
+	maxCommandOutputLimit = 100000
+)
+
+func execToolDefs[Resp any](cb callbacks.ExecCallbacks) map[string]Tool[Resp] {
+	tools := make(map[string]Tool[Resp], 2)
+	if cb.RunCommand != nil {
+		tools["run_command"] = runCommandTool[Resp](cb.RunCommand)
+	}
+	if cb.ReadOutput != nil {
+		tools["read_command_output"] = readCommandOutputTool[Resp](cb.ReadOutput)
+	}
+	return tools
+}
+
+func runCommandTool[Resp any](runCommand func(context.Context, string, []string, string, time.Duration, int) (callbacks.ExecResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "run_command",
+			Description: "Run a command in the repository, such as a build, test or linter, to check your changes before submitting. " +
+				"Only an allow-listed set of programs is available, run directly rather than through a shell. " +
+				"A non-zero exit_code is reported, not treated as an error. Long output is paginated: pass output_id and next_offset to read_command_output for the rest.",
+			Parameters: []Parameter{
+				{Name: "command", Type: "string", Description: "The program to run, by name (e.g. \"go\")", Required: true},
+				{Name: "args", Type: "array", Items: &Schema{Type: "string"}, Description: "Arguments to the program, one per element (e.g. [\"test\", \"./...\"])", Required: false},
+				{Name: "dir", Type: "string", Description: "Directory to run in, relative to the repository root (default: the root)", Required: false},
+				{Name: "timeout_seconds", Type: "integer", Description: "Stop the command after this many seconds (default and maximum: the configured limit)", Required: false, Minimum: Ptr[float64](1)},
+				{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum bytes of output to return (default: %d, max: %d)", defaultCommandOutputLimit, maxCommandOutputLimit), Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				OpenWorld: Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			command, errResp := Param[string](call, trace, "command")
+			if errResp != nil {
+				return errResp
+			}
+			rawArgs, errResp := OptionalParam[[]any](call, "args", nil)
+			if errResp != nil {
+				return errResp
+			}
+			args := make([]string, 0, len(rawArgs))
+			for i, a := range rawArgs {
+				s, ok := a.(string)
+				if !ok {
+					return params.Error("args[%d] must be a string, got %T", i, a)
+				}
+				args = append(args, s)
+			}
+			dir, errResp := OptionalParam[string](call, "dir", "")
+			if errResp != nil {
+				return errResp
+			}
+			timeoutSeconds, errResp := OptionalParam[int](call, "timeout_seconds", 0)
+			if errResp != nil {
+				return errResp
+			}
+			if timeoutSeconds < 0 {
+				return params.Error("timeout_seconds must be positive, got %d", timeoutSeconds)
+			}
+			limit, errResp := OptionalParam[int](call, "limit", defaultCommandOutputLimit)
+			if errResp != nil {
+				return errResp
+			}
+			if limit <= 0 || limit > maxCommandOutputLimit {
+				return params.Error("limit must be between 1 and %d, got %d", maxCommandOutputLimit, limit)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"command": command, "args": args, "dir": dir, "timeout_seconds": timeoutSeconds})
+
+			result, err := runCommand(ctx, command, args, dir, time.Duration(timeoutSeconds)*time.Second, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to run command", err, "command", command)
+			}
+
+			resp := map[string]any{
+				"exit_code":   result.ExitCode,
+				"timed_out":   result.TimedOut,
+				"duration_ms": result.Duration.Milliseconds(),
+				"output":      result.Output,
+			}
+			if result.OutputID != "" {
+				resp["output_id"] = result.OutputID
+			}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+				resp["remaining"] = result.Remaining
+			}
+			if result.Dropped > 0 {
+				resp["dropped_bytes"] = result.Dropped
+			}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func readCommandOutputTool[Resp any](readOutput func(context.Context, string, int64, int) (callbacks.ReadResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name:        "read_command_output",
+			Description: "Read more of the output of an earlier run_command call, by the output_id it returned.",
+			Parameters: []Parameter{
+				{Name: "output_id", Type: "string", Description: "The output_id returned by run_command", Required: true},
+				{Name: "offset", Type: "integer", Description: "Byte offset to start reading from, usually the next_offset returned by the previous call", Required: true, Minimum: Ptr[float64](0)},
+				{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum bytes of output to return (default: %d, max: %d)", defaultCommandOutputLimit, maxCommandOutputLimit), Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			id, errResp := Param[string](call, trace, "output_id")
+			if errResp != nil {
+				return errResp
+			}
+			offset, errResp := Param[int64](call, trace, "offset")
+			if errResp != nil {
+				return errResp
+			}
+			if errResp := rejectNegativeOffset(call, trace, offset); errResp != nil {
+				return errResp
+			}
+			limit, errResp := OptionalParam[int](call, "limit", defaultCommandOutputLimit)
+			if errResp != nil {
+				return errResp
+			}
+			if limit <= 0 || limit > maxCommandOutputLimit {
+				return params.Error("limit must be between 1 and %d, got %d", maxCommandOutputLimit, limit)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"output_id": id, "offset": offset, "limit": limit})
+
+			result, err := readOutput(ctx, id, offset, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to read command output", err, "output_id", id)
+			}
+
+			resp := map[string]any{
+				"output": result.Content,
+			}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+			}
+			resp["remaining"] = result.Remaining
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
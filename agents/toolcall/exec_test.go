@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestRunCommandTool(t *testing.T) {
+	var (
+		gotArgs    []string
+		gotDir     string
+		gotTimeout time.Duration
+	)
+	next := int64(3)
+	cb := callbacks.ExecCallbacks{
+		RunCommand: func(_ context.Context, command string, args []string, dir string, timeout time.Duration, limit int) (callbacks.ExecResult, error) {
+			gotArgs, gotDir, gotTimeout = args, dir, timeout
+			return callbacks.ExecResult{ExitCode: 1, Output: "FAI", OutputID: "out-1", NextOffset: &next, Remaining: 1}, nil
+		},
+	}
+	tools := execToolDefs[string](cb)
+	if _, ok := tools["read_command_output"]; ok {
+		t.Error("read_command_output registered without a ReadOutput callback")
+	}
+
+	trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+	resp := tools["run_command"].Handler(t.Context(), ToolCall{ID: "1", Name: "run_command", Args: map[string]any{
+		"command":         "go",
+		"args":            []any{"test", "./..."},
+		"dir":             "pkg",
+		"timeout_seconds": float64(30),
+	}}, trace, nil)
+
+	if !slices.Equal(gotArgs, []string{"test", "./..."}) || gotDir != "pkg" || gotTimeout != 30*time.Second {
+		t.Errorf("callback inputs: got = %q in %q for %v", gotArgs, gotDir, gotTimeout)
+	}
+	if resp["exit_code"] != 1 || resp["output"] != "FAI" || resp["output_id"] != "out-1" || resp["next_offset"] != int64(3) {
+		t.Errorf("response: got = %v, want the failing exit code and a continuation", resp)
+	}
+}
+
+func TestRunCommandToolRejectsBadArgs(t *testing.T) {
+	cb := callbacks.ExecCallbacks{
+		RunCommand: func(context.Context, string, []string, string, time.Duration, int) (callbacks.ExecResult, error) {
+			t.Error("RunCommand callback invoked with invalid arguments")
+			return callbacks.ExecResult{}, nil
+		},
+		ReadOutput: func(context.Context, string, int64, int) (callbacks.ReadResult, error) {
+			t.Error("ReadOutput callback invoked with invalid arguments")
+			return callbacks.ReadResult{}, nil
+		},
+	}
+	tools := execToolDefs[string](cb)
+
+	for _, tc := range []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"non-string arg", "run_command", map[string]any{"command": "go", "args": []any{"test", float64(1)}}, "args[1]"},
+		{"limit too large", "run_command", map[string]any{"command": "go", "limit": float64(maxCommandOutputLimit + 1)}, "limit"},
+		{"negative timeout", "run_command", map[string]any{"command": "go", "timeout_seconds": float64(-1)}, "timeout_seconds"},
+		{"negative offset", "read_command_output", map[string]any{"output_id": "out-1", "offset": float64(-1)}, "offset must be >= 0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+			resp := tools[tc.tool].Handler(t.Context(), ToolCall{ID: "1", Name: tc.tool, Args: tc.args}, trace, nil)
+			if msg, _ := resp["error"].(string); !strings.Contains(msg, tc.want) {
+				t.Errorf("response: got = %v, want an error mentioning %q", resp, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gocode
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/types"
+	"strings"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+// maxMembers caps the fields and methods a TypeInfo lists.
+const maxMembers = 200
+
+// symbol describes obj. A compact symbol, as listed for a package, has a
+// one-line signature and only the first sentence of its doc comment.
+func (s *session) symbol(obj types.Object, compact bool) callbacks.Symbol {
+	sym := callbacks.Symbol{
+		Name: obj.Name(),
+		Kind: kind(obj),
+	}
+	if obj.Pkg() != nil {
+		sym.Package = obj.Pkg().Path()
+	}
+	if pn, ok := obj.(*types.PkgName); ok {
+		sym.Name, sym.Package = pn.Imported().Name(), pn.Imported().Path()
+		sym.Signature = "package " + pn.Imported().Name()
+		return sym
+	}
+
+	d, u := s.decl(obj)
+	if d.owner != "" {
+		sym.Name = d.owner + "." + sym.Name
+	} else if recv := receiverName(obj); recv != "" {
+		sym.Name = recv + "." + sym.Name
+	}
+	if u != nil && !u.external {
+		pos := s.n.fset.Position(obj.Pos())
+		sym.Path, sym.Line, sym.Column = pos.Filename, pos.Line, pos.Column
+	}
+	sym.Signature = s.signature(obj, d, compact)
+	sym.Doc = strings.TrimSpace(d.doc)
+	if compact && sym.Doc != "" {
+		sym.Doc = new(doc.Package).Synopsis(sym.Doc)
+	}
+	return sym
+}
+
+// decl returns the declaration syntax of obj and the unit holding it, or nil
+// for objects declared outside the worktree that were not loaded.
+func (s *session) decl(obj types.Object) (decl, *unit) {
+	if obj.Pkg() == nil || !obj.Pos().IsValid() {
+		return decl{}, nil
+	}
+	u := s.byPath[obj.Pkg().Path()]
+	if u == nil {
+		return decl{}, nil
+	}
+	return u.decls[obj.Pos()], u
+}
+
+// kind classifies obj for Symbol.Kind.
+func kind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.Func:
+		if receiverName(obj) != "" || isInterfaceMethod(obj) {
+			return "method"
+		}
+		return "func"
+	case *types.TypeName:
+		return "type"
+	case *types.Var:
+		if obj.IsField() {
+			return "field"
+		}
+		return "var"
+	case *types.Const:
+		return "const"
+	case *types.PkgName:
+		return "package"
+	case *types.Label:
+		return "label"
+	default:
+		return "builtin"
+	}
+}
+
+// receiverName returns the name of the type a method is declared on.
+func receiverName(obj types.Object) string {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return ""
+	}
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return ""
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+func isInterfaceMethod(fn *types.Func) bool {
+	recv := fn.Signature().Recv()
+	return recv != nil && types.IsInterface(recv.Type())
+}
+
+// signature renders the declaration of obj without its body or doc comment.
+func (s *session) signature(obj types.Object, d decl, compact bool) string {
+	qual := qualifier(obj.Pkg())
+	if tn, ok := obj.(*types.TypeName); ok && compact {
+		if tn.IsAlias() {
+			return "type " + tn.Name() + " = " + types.TypeString(types.Unalias(tn.Type()), qual)
+		}
+		switch u := tn.Type().Underlying().(type) {
+		case *types.Struct:
+			return "type " + tn.Name() + " struct"
+		case *types.Interface:
+			return "type " + tn.Name() + " interface"
+		default:
+			if ts, ok := d.node.(*ast.TypeSpec); ok {
+				return "type " + tn.Name() + " " + s.print(ts.Type)
+			}
+			return "type " + tn.Name() + " " + types.TypeString(u, qual)
+		}
+	}
+
+	switch node := d.node.(type) {
+	case *ast.FuncDecl:
+		fd := *node
+		fd.Doc, fd.Body = nil, nil
+		return s.print(&fd)
+	case *ast.TypeSpec:
+		ts := *node
+		ts.Doc, ts.Comment = nil, nil
+		return "type " + s.print(&ts)
+	}
+	switch obj := obj.(type) {
+	case *types.Const:
+		return "const " + obj.Name() + " " + types.TypeString(obj.Type(), qual) + " = " + obj.Val().String()
+	case *types.Var:
+		if obj.Type() == types.Typ[types.Invalid] {
+			if obj.IsField() {
+				return "field " + obj.Name() + " " + s.typeString(obj, obj.Type(), qual)
+			}
+			return "var " + obj.Name() + " " + s.typeString(obj, obj.Type(), qual)
+		}
+	}
+	return types.ObjectString(obj, qual)
+}
+
+// qualifier names packages other than pkg by their package name.
+func qualifier(pkg *types.Package) types.Qualifier {
+	return func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+}
+
+// typeString renders the type of obj. Types from outside the worktree
+// cannot be resolved, so they are taken from the declaration's syntax.
+func (s *session) typeString(obj types.Object, t types.Type, qual types.Qualifier) string {
+	if t == types.Typ[types.Invalid] {
+		if d, _ := s.decl(obj); d.typ != "" {
+			return d.typ
+		}
+	}
+	return types.TypeString(t, qual)
+}
+
+func (s *session) print(node ast.Node) string {
+	var b strings.Builder
+	if err := printer.Fprint(&b, s.n.fset, node); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// typeInfo describes the type of obj, and for a type, or a variable of one,
+// its fields and method set.
+func (s *session) typeInfo(obj types.Object) callbacks.TypeInfo {
+	qual := qualifier(obj.Pkg())
+	info := callbacks.TypeInfo{Symbol: s.symbol(obj, false)}
+	t := obj.Type()
+	if t == nil {
+		return info
+	}
+	info.Type = s.typeString(obj, t, qual)
+	if t == types.Typ[types.Invalid] {
+		return info
+	}
+	if u := types.TypeString(t.Underlying(), qual); u != info.Type {
+		info.Underlying = u
+	}
+
+	switch obj.(type) {
+	case *types.TypeName, *types.Var, *types.Const:
+	default:
+		return info
+	}
+	base := t
+	if p, ok := types.Unalias(t).(*types.Pointer); ok {
+		base = p.Elem()
+	}
+	if st, ok := base.Underlying().(*types.Struct); ok {
+		for i := range min(st.NumFields(), maxMembers) {
+			f := st.Field(i)
+			typ := s.typeString(f, f.Type(), qual)
+			field := f.Name() + " " + typ
+			if f.Embedded() {
+				field = typ + " (embedded)"
+			}
+			info.Fields = append(info.Fields, field)
+		}
+	}
+	ms := types.NewMethodSet(base)
+	if !types.IsInterface(base) {
+		ms = types.NewMethodSet(types.NewPointer(base))
+	}
+	for i := range min(ms.Len(), maxMembers) {
+		fn := ms.At(i).Obj()
+		sig := types.TypeString(fn.Type(), qual)
+		info.Methods = append(info.Methods, fn.Name()+strings.TrimPrefix(sig, "func"))
+	}
+	return info
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+/*
+Package gocode implements callbacks.CodeCallbacks for the Go code in a
+worktree, using go/parser and go/types.
+
+Callbacks takes the worktree as an fs.FS, so the same navigator serves a
+local checkout (os.DirFS, os.Root.FS) and a leased clonemanager worktree
+(worktreefs.New over clonemanager.WorktreeCallbacks):
+
+	cb := clonemanager.WorktreeCallbacks(wt)
+	tools := toolcall.NewCodeTools(
+		toolcall.NewWorktreeTools(toolcall.EmptyTools{}, cb),
+		gocode.Callbacks(worktreefs.New(ctx, cb), gocode.WithRoot(wt.Filesystem.Root())),
+	)
+
+# Loading
+
+Packages are found by walking the worktree for go.mod files and Go sources,
+skipping vendor, testdata and hidden directories. Build constraints are
+evaluated for the default GOOS and GOARCH with cgo disabled.
+
+With WithRoot, each module is loaded from its directory on disk with
+golang.org/x/tools/go/packages, which runs the go command: the module's
+packages, their tests and everything they import are type-checked from
+source, so standard library and third-party types resolve, and their
+declarations are described without a worktree position. The load is redone
+whenever a Go file or go.mod in the worktree changes.
+
+Without it, the worktree's packages are type-checked from fsys alone, in
+dependency order. Nothing is built and no go command runs, so the navigator
+works in sandboxes without a toolchain or network access. The price is that
+only packages inside the worktree are type-checked: imports from other
+modules and the standard library resolve to empty placeholder packages, so
+their symbols have no types and uses of them are not reported as
+references.
+
+Each directory yields up to two units: the package together with its
+in-package test files, and its external _test package. Without WithRoot,
+units are cached across calls and re-checked only when a file in the
+package, or in a package it imports, changes, so queries against a worktree
+an agent is editing stay cheap.
+*/
+package gocode
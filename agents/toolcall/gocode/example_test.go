@@ -0,0 +1,57 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gocode_test
+
+import (
+	"context"
+	"fmt"
+	"testing/fstest"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"chainguard.dev/driftlessaf/agents/toolcall/gocode"
+)
+
+func ExampleCallbacks() {
+	ctx := context.Background()
+
+	// Any fs.FS rooted at the worktree works: os.DirFS, os.Root.FS or
+	// worktreefs.New for a leased clonemanager worktree.
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/hello\n")},
+		"hello.go": {Data: []byte(`package hello
+
+// Hello returns a greeting.
+func Hello(name string) string { return "Hello, " + name }
+`)},
+		"cmd/hello/main.go": {Data: []byte(`package main
+
+import "example.com/hello"
+
+func main() { println(hello.Hello("world")) }
+`)},
+	}
+	cb := gocode.Callbacks(fsys)
+
+	defs, err := cb.FindDefinition(ctx, callbacks.SymbolQuery{Name: "hello.Hello"})
+	if err != nil {
+		panic(err)
+	}
+	for _, d := range defs {
+		fmt.Printf("%s:%d: %s\n", d.Path, d.Line, d.Signature)
+	}
+
+	refs, err := cb.FindReferences(ctx, callbacks.SymbolQuery{Name: "Hello"}, 0, 10)
+	if err != nil {
+		panic(err)
+	}
+	for _, r := range refs.References {
+		fmt.Printf("%s:%d: %s\n", r.Path, r.Line, r.Text)
+	}
+
+	// Output:
+	// hello.go:4: func Hello(name string) string
+	// cmd/hello/main.go:5: func main() { println(hello.Hello("world")) }
+}
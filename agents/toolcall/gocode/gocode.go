@@ -0,0 +1,421 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gocode
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+// navigator answers code queries about the Go packages in fsys.
+type navigator struct {
+	fsys fs.FS
+	fset *token.FileSet
+
+	// root is the worktree's directory on disk when packages are loaded
+	// with go/packages; empty when they are checked from fsys alone.
+	root string
+
+	// mu serializes queries, which share the cache.
+	mu           sync.Mutex
+	cache        map[unitKey]*unit
+	placeholders map[string]*types.Package
+	loaded       *packagesLoad
+}
+
+// packagesLoad is the outcome of a go/packages load of the worktree.
+type packagesLoad struct {
+	fingerprint [sha256.Size]byte // of the sources loaded
+	units       map[unitKey]*unit
+	byPath      map[string]*unit
+}
+
+// Option configures Callbacks.
+type Option func(*navigator)
+
+// WithRoot loads packages with golang.org/x/tools/go/packages from root,
+// the worktree's directory on disk, instead of checking them from fsys
+// alone. Imports from the standard library and from other modules are then
+// type-checked too, so their symbols have types and the worktree's uses of
+// them are references. Each module in the worktree is loaded from its own
+// directory; it needs a go command, and the module's dependencies in the
+// module cache or reachable through GOPROXY.
+func WithRoot(root string) Option {
+	return func(n *navigator) { n.root = root }
+}
+
+// Callbacks returns CodeCallbacks answering queries about the Go code in
+// fsys, which is rooted at the worktree root. The callbacks reread fsys on
+// every call, so they see edits made through other callbacks, and are safe
+// for concurrent use.
+func Callbacks(fsys fs.FS, opts ...Option) callbacks.CodeCallbacks {
+	n := &navigator{
+		fsys:         fsys,
+		fset:         token.NewFileSet(),
+		cache:        make(map[unitKey]*unit),
+		placeholders: make(map[string]*types.Package),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return callbacks.CodeCallbacks{
+		FindDefinition:     n.findDefinition,
+		FindReferences:     n.findReferences,
+		ListPackageSymbols: n.listPackageSymbols,
+		GetTypeInfo:        n.getTypeInfo,
+	}
+}
+
+// query runs fn against a fresh snapshot of the worktree.
+func query[T any](ctx context.Context, n *navigator, fn func(*session) (T, error)) (T, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s, err := n.newSession(ctx)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("reading worktree: %w", err)
+	}
+	return fn(s)
+}
+
+func (n *navigator) findDefinition(ctx context.Context, q callbacks.SymbolQuery) ([]callbacks.Symbol, error) {
+	return query(ctx, n, func(s *session) ([]callbacks.Symbol, error) {
+		objs, err := s.resolve(q)
+		if err != nil {
+			return nil, err
+		}
+		syms := make([]callbacks.Symbol, 0, len(objs))
+		for _, obj := range objs {
+			syms = append(syms, s.symbol(obj, false))
+		}
+		return syms, nil
+	})
+}
+
+func (n *navigator) findReferences(ctx context.Context, q callbacks.SymbolQuery, offset, limit int) (callbacks.ReferenceResult, error) {
+	if offset < 0 {
+		return callbacks.ReferenceResult{}, fmt.Errorf("offset must be >= 0, got %d", offset)
+	}
+	return query(ctx, n, func(s *session) (callbacks.ReferenceResult, error) {
+		objs, err := s.resolve(q)
+		if err != nil {
+			return callbacks.ReferenceResult{}, err
+		}
+		var result callbacks.ReferenceResult
+		// A package loaded with and without its tests declares distinct
+		// objects at the same positions, so targets match by position too.
+		targets := make(map[types.Object]bool, len(objs))
+		declared := make(map[token.Pos]bool, len(objs))
+		for _, obj := range objs {
+			targets[obj] = true
+			if obj.Pos().IsValid() {
+				declared[obj.Pos()] = true
+			}
+			result.Symbols = append(result.Symbols, s.symbol(obj, false))
+		}
+
+		// Any package in the worktree may refer to the targets.
+		units, err := s.all("")
+		if err != nil {
+			return callbacks.ReferenceResult{}, err
+		}
+		seen := make(map[token.Pos]bool)
+		var refs []callbacks.Reference
+		for _, u := range units {
+			for id, obj := range u.info.Uses {
+				obj = origin(obj)
+				if !targets[obj] && !declared[obj.Pos()] || seen[id.Pos()] {
+					continue
+				}
+				seen[id.Pos()] = true
+				pos := s.n.fset.Position(id.Pos())
+				refs = append(refs, callbacks.Reference{
+					Path:   pos.Filename,
+					Line:   pos.Line,
+					Column: pos.Column,
+					Text:   lineText(u.src[pos.Filename], pos.Offset),
+				})
+			}
+		}
+		slices.SortFunc(refs, func(a, b callbacks.Reference) int {
+			return cmp.Or(strings.Compare(a.Path, b.Path), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+		})
+
+		result.Total = len(refs)
+		start := min(offset, len(refs))
+		end := len(refs)
+		if limit > 0 && limit < end-start {
+			end = start + limit
+			next := end
+			result.NextOffset = &next
+		}
+		result.References = refs[start:end]
+		return result, nil
+	})
+}
+
+func (n *navigator) listPackageSymbols(ctx context.Context, dir string, includeUnexported bool) ([]callbacks.Symbol, error) {
+	dir = path.Clean(strings.TrimPrefix(dir, "/"))
+	return query(ctx, n, func(s *session) ([]callbacks.Symbol, error) {
+		u, err := s.load(unitKey{dir: dir})
+		if err != nil {
+			return nil, err
+		}
+		if u == nil {
+			return nil, fmt.Errorf("no Go package in %q", dir)
+		}
+		var syms []callbacks.Symbol
+		scope := u.pkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !includeUnexported && !obj.Exported() {
+				continue
+			}
+			syms = append(syms, s.symbol(obj, true))
+			tn, ok := obj.(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			methods := slices.Collect(named.Methods())
+			slices.SortFunc(methods, func(a, b *types.Func) int { return strings.Compare(a.Name(), b.Name()) })
+			for _, m := range methods {
+				if includeUnexported || m.Exported() {
+					syms = append(syms, s.symbol(m, true))
+				}
+			}
+		}
+		return syms, nil
+	})
+}
+
+func (n *navigator) getTypeInfo(ctx context.Context, q callbacks.SymbolQuery) ([]callbacks.TypeInfo, error) {
+	return query(ctx, n, func(s *session) ([]callbacks.TypeInfo, error) {
+		objs, err := s.resolve(q)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]callbacks.TypeInfo, 0, len(objs))
+		for _, obj := range objs {
+			infos = append(infos, s.typeInfo(obj))
+		}
+		return infos, nil
+	})
+}
+
+// resolve returns the objects a query selects.
+func (s *session) resolve(q callbacks.SymbolQuery) ([]types.Object, error) {
+	switch {
+	case q.Path != "":
+		obj, err := s.objectAt(q)
+		if err != nil {
+			return nil, err
+		}
+		return []types.Object{obj}, nil
+	case q.Name != "":
+		objs, err := s.lookup(q.Name, q.Package)
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) == 0 {
+			if q.Package != "" {
+				return nil, fmt.Errorf("no symbol %q in %s", q.Name, q.Package)
+			}
+			return nil, fmt.Errorf("no symbol %q in the worktree", q.Name)
+		}
+		return objs, nil
+	default:
+		return nil, errors.New("a symbol name or a file position is required")
+	}
+}
+
+// lookup returns the package-level objects, and the fields and methods, that
+// name refers to in the packages matching pattern.
+func (s *session) lookup(name, pattern string) ([]types.Object, error) {
+	units, err := s.all(pattern)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(name, ".")
+	var objs []types.Object
+	add := func(obj types.Object) {
+		if obj != nil && !slices.Contains(objs, obj) {
+			objs = append(objs, obj)
+		}
+	}
+	for _, u := range units {
+		scope := u.pkg.Scope()
+		switch len(parts) {
+		case 1:
+			add(scope.Lookup(parts[0]))
+		case 2:
+			if u.pkg.Name() == parts[0] {
+				add(scope.Lookup(parts[1]))
+			}
+			add(member(scope.Lookup(parts[0]), parts[1]))
+		case 3:
+			if u.pkg.Name() == parts[0] {
+				add(member(scope.Lookup(parts[1]), parts[2]))
+			}
+		}
+	}
+	if len(objs) == 0 && len(parts) == 1 {
+		// An unqualified method or field name.
+		for _, u := range units {
+			scope := u.pkg.Scope()
+			for _, tn := range scope.Names() {
+				if obj := member(scope.Lookup(tn), parts[0]); obj != nil && obj.Pkg() == u.pkg {
+					add(obj)
+				}
+			}
+		}
+	}
+	return objs, nil
+}
+
+// member returns the field or method name of the type obj declares.
+func member(obj types.Object, name string) types.Object {
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	m, _, _ := types.LookupFieldOrMethod(tn.Type(), true, tn.Pkg(), name)
+	return m
+}
+
+// objectAt returns the object that the identifier at the query's position
+// declares or refers to.
+func (s *session) objectAt(q callbacks.SymbolQuery) (types.Object, error) {
+	p := path.Clean(strings.TrimPrefix(q.Path, "/"))
+	if !strings.HasSuffix(p, ".go") {
+		return nil, fmt.Errorf("%s is not a Go file", p)
+	}
+	var u *unit
+	var file *ast.File
+	for _, xtest := range []bool{false, true} {
+		cand, err := s.load(unitKey{dir: path.Dir(p), xtest: xtest})
+		if err != nil {
+			return nil, err
+		}
+		if cand == nil {
+			continue
+		}
+		for _, f := range cand.files {
+			if s.n.fset.File(f.Pos()).Name() == p {
+				u, file = cand, f
+			}
+		}
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is not part of a Go package in the worktree, or is excluded by build constraints", p)
+	}
+	tf := s.n.fset.File(file.Pos())
+
+	var pos token.Pos
+	var where string
+	switch {
+	case q.Line > 0 && q.Column > 0:
+		if q.Line > tf.LineCount() {
+			return nil, fmt.Errorf("%s has %d lines, got line %d", p, tf.LineCount(), q.Line)
+		}
+		off := tf.Offset(tf.LineStart(q.Line)) + q.Column - 1
+		if off > tf.Size() {
+			return nil, fmt.Errorf("column %d is past the end of %s:%d", q.Column, p, q.Line)
+		}
+		pos, where = tf.Pos(off), fmt.Sprintf("%s:%d:%d", p, q.Line, q.Column)
+	case q.Line > 0:
+		if q.Line > tf.LineCount() {
+			return nil, fmt.Errorf("%s has %d lines, got line %d", p, tf.LineCount(), q.Line)
+		}
+		where = fmt.Sprintf("%s:%d", p, q.Line)
+	default:
+		if q.Offset < 0 || q.Offset > int64(tf.Size()) {
+			return nil, fmt.Errorf("offset %d is outside %s, which has %d bytes", q.Offset, p, tf.Size())
+		}
+		pos, where = tf.Pos(int(q.Offset)), fmt.Sprintf("%s at offset %d", p, q.Offset)
+	}
+
+	var found types.Object
+	ast.Inspect(file, func(node ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		id, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if pos.IsValid() {
+			if pos < id.Pos() || pos > id.End() {
+				return true
+			}
+		} else if tf.Line(id.Pos()) != q.Line {
+			return true
+		}
+		if obj := identObject(u.info, id); obj != nil {
+			found = obj
+		}
+		return true
+	})
+	if found == nil {
+		return nil, fmt.Errorf("no identifier referring to a symbol at %s", where)
+	}
+	return found, nil
+}
+
+// identObject returns the object an identifier declares or refers to.
+func identObject(info *types.Info, id *ast.Ident) types.Object {
+	if obj := info.Defs[id]; obj != nil {
+		return origin(obj)
+	}
+	if obj := info.Uses[id]; obj != nil {
+		return origin(obj)
+	}
+	return nil
+}
+
+// origin maps an object of an instantiated generic type or function to the
+// declared one.
+func origin(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Origin()
+	case *types.Var:
+		return obj.Origin()
+	}
+	return obj
+}
+
+// lineText returns the line of src containing offset, trimmed.
+func lineText(src []byte, offset int) string {
+	if offset > len(src) {
+		return ""
+	}
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+	end := bytes.IndexByte(src[offset:], '\n')
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += offset
+	}
+	return strings.TrimSpace(string(src[start:end]))
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gocode
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func testModule() fstest.MapFS {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	return fstest.MapFS{
+		"go.mod": file("module example.com/app // the app\n\ngo 1.24\n"),
+		"greet/greet.go": file(`package greet
+
+import "strings"
+
+// Greeting is the default greeting.
+const Greeting = "Hello"
+
+// Greeter greets people. It is safe for concurrent use.
+type Greeter struct {
+	// Name is who to greet.
+	Name string
+	strings.Builder
+}
+
+// New returns a Greeter for name.
+func New(name string) *Greeter {
+	return &Greeter{Name: name}
+}
+
+// Greet returns the greeting.
+func (g *Greeter) Greet() string {
+	return Greeting + ", " + g.Name
+}
+
+func (g *Greeter) reset() { g.Name = "" }
+`),
+		"greet/greet_test.go": file(`package greet
+
+func helper() *Greeter { return New("test") }
+`),
+		"greet/example_test.go": file(`package greet_test
+
+import "example.com/app/greet"
+
+func Example() {
+	_ = greet.New("x").Greet()
+}
+`),
+		"greet/greet_windows.go": file("package greet\n\nfunc windowsOnly() {}\n"),
+		"cmd/app/main.go": file(`package main
+
+import (
+	"fmt"
+
+	"example.com/app/greet"
+)
+
+func main() {
+	g := greet.New("world")
+	fmt.Println(g.Greet(), g.Name)
+}
+`),
+		"tools/go.mod":      file("module example.com/tools\n"),
+		"tools/tool.go":     file("package tools\n\nfunc New() {}\n"),
+		"testdata/x.go":     file("package x\n\nfunc New() {}\n"),
+		".hidden/hidden.go": file("package hidden\n\nfunc New() {}\n"),
+	}
+}
+
+func TestFindDefinition(t *testing.T) {
+	cb := Callbacks(testModule())
+
+	for _, tc := range []struct {
+		name  string
+		query callbacks.SymbolQuery
+		want  []string // name@path:line
+	}{
+		{"by name", callbacks.SymbolQuery{Name: "Greeter"}, []string{"Greeter@greet/greet.go:9"}},
+		{"qualified", callbacks.SymbolQuery{Name: "greet.New"}, []string{"New@greet/greet.go:16"}},
+		{"every package", callbacks.SymbolQuery{Name: "New"}, []string{"New@greet/greet.go:16", "New@tools/tool.go:3"}},
+		{"package filter", callbacks.SymbolQuery{Name: "New", Package: "tools/..."}, []string{"New@tools/tool.go:3"}},
+		{"method", callbacks.SymbolQuery{Name: "Greeter.Greet"}, []string{"Greeter.Greet@greet/greet.go:21"}},
+		{"field", callbacks.SymbolQuery{Name: "greet.Greeter.Name"}, []string{"Greeter.Name@greet/greet.go:11"}},
+		{"unqualified method", callbacks.SymbolQuery{Name: "reset"}, []string{"Greeter.reset@greet/greet.go:25"}},
+		{"test helper", callbacks.SymbolQuery{Name: "helper"}, []string{"helper@greet/greet_test.go:3"}},
+		{"use by position", callbacks.SymbolQuery{Path: "cmd/app/main.go", Line: 11, Column: 17}, []string{"Greeter.Greet@greet/greet.go:21"}},
+		{"first symbol on line", callbacks.SymbolQuery{Path: "cmd/app/main.go", Line: 10}, []string{"g@cmd/app/main.go:10"}},
+		{"offset", callbacks.SymbolQuery{Path: "greet/example_test.go", Offset: 81}, []string{"New@greet/greet.go:16"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			syms, err := cb.FindDefinition(t.Context(), tc.query)
+			if err != nil {
+				t.Fatalf("FindDefinition: %v", err)
+			}
+			got := make([]string, 0, len(syms))
+			for _, s := range syms {
+				got = append(got, s.Name+"@"+s.Path+":"+strconv.Itoa(s.Line))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("definitions: got = %q, wanted = %q", got, tc.want)
+			}
+		})
+	}
+
+	syms, err := cb.FindDefinition(t.Context(), callbacks.SymbolQuery{Name: "Greeter"})
+	if err != nil {
+		t.Fatalf("FindDefinition: %v", err)
+	}
+	if got, want := syms[0].Doc, "Greeter greets people. It is safe for concurrent use."; got != want {
+		t.Errorf("doc: got = %q, wanted = %q", got, want)
+	}
+	if got, want := syms[0].Signature, "type Greeter struct {\n\t// Name is who to greet.\n\tName\tstring\n\tstrings.Builder\n}"; got != want {
+		t.Errorf("signature: got = %q, wanted = %q", got, want)
+	}
+	if got, want := syms[0].Package, "example.com/app/greet"; got != want {
+		t.Errorf("package: got = %q, wanted = %q", got, want)
+	}
+}
+
+func TestFindDefinitionErrors(t *testing.T) {
+	cb := Callbacks(testModule())
+	for _, tc := range []struct {
+		name  string
+		query callbacks.SymbolQuery
+		want  string
+	}{
+		{"empty", callbacks.SymbolQuery{}, "required"},
+		{"unknown", callbacks.SymbolQuery{Name: "Missing"}, `no symbol "Missing"`},
+		{"excluded by build constraints", callbacks.SymbolQuery{Name: "windowsOnly"}, "no symbol"},
+		{"skipped directory", callbacks.SymbolQuery{Name: "New", Package: "testdata"}, "no symbol"},
+		{"line past the end", callbacks.SymbolQuery{Path: "cmd/app/main.go", Line: 99}, "has 12 lines"},
+		{"no identifier", callbacks.SymbolQuery{Path: "cmd/app/main.go", Line: 2}, "no identifier"},
+		{"not a package file", callbacks.SymbolQuery{Path: "greet/greet_windows.go", Line: 1}, "build constraints"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := cb.FindDefinition(t.Context(), tc.query); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("FindDefinition: got err = %v, wanted one mentioning %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	cb := Callbacks(testModule())
+
+	result, err := cb.FindReferences(t.Context(), callbacks.SymbolQuery{Name: "greet.New"}, 0, 2)
+	if err != nil {
+		t.Fatalf("FindReferences: %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("total: got = %d, wanted = 3", result.Total)
+	}
+	got := make([]string, 0, len(result.References))
+	for _, r := range result.References {
+		got = append(got, r.Path+":"+strconv.Itoa(r.Line)+":"+strconv.Itoa(r.Column)+" "+r.Text)
+	}
+	want := []string{
+		"cmd/app/main.go:10:13 g := greet.New(\"world\")",
+		"greet/example_test.go:6:12 _ = greet.New(\"x\").Greet()",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("references: got = %q, wanted = %q", got, want)
+	}
+	if result.NextOffset == nil || *result.NextOffset != 2 {
+		t.Fatalf("next offset: got = %v, wanted 2", result.NextOffset)
+	}
+
+	result, err = cb.FindReferences(t.Context(), callbacks.SymbolQuery{Name: "greet.New"}, 2, 2)
+	if err != nil {
+		t.Fatalf("FindReferences: %v", err)
+	}
+	if len(result.References) != 1 || result.References[0].Path != "greet/greet_test.go" || result.NextOffset != nil {
+		t.Errorf("second page: got = %+v, wanted the in-package test's use and no next offset", result)
+	}
+
+	// A field's uses include composite literal keys and selectors.
+	result, err = cb.FindReferences(t.Context(), callbacks.SymbolQuery{Path: "greet/greet.go", Line: 11, Column: 2}, 0, 10)
+	if err != nil {
+		t.Fatalf("FindReferences: %v", err)
+	}
+	if result.Total != 4 {
+		t.Errorf("field references: got = %+v, wanted 4", result.References)
+	}
+
+	if _, err := cb.FindReferences(t.Context(), callbacks.SymbolQuery{Name: "New"}, -1, 10); err == nil {
+		t.Error("negative offset: got = nil, wanted an error")
+	}
+}
+
+func TestListPackageSymbols(t *testing.T) {
+	cb := Callbacks(testModule())
+
+	syms, err := cb.ListPackageSymbols(t.Context(), "greet", false)
+	if err != nil {
+		t.Fatalf("ListPackageSymbols: %v", err)
+	}
+	got := make([]string, 0, len(syms))
+	for _, s := range syms {
+		got = append(got, s.Kind+" "+s.Name+": "+s.Signature)
+	}
+	want := []string{
+		"type Greeter: type Greeter struct",
+		"method Greeter.Greet: func (g *Greeter) Greet() string",
+		`const Greeting: const Greeting untyped string = "Hello"`,
+		"func New: func New(name string) *Greeter",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("symbols: got = %q, wanted = %q", got, want)
+	}
+	if got, want := syms[0].Doc, "Greeter greets people."; got != want {
+		t.Errorf("synopsis: got = %q, wanted = %q", got, want)
+	}
+
+	syms, err = cb.ListPackageSymbols(t.Context(), "greet", true)
+	if err != nil {
+		t.Fatalf("ListPackageSymbols: %v", err)
+	}
+	if len(syms) != 6 {
+		t.Errorf("with unexported: got = %d symbols, wanted 6 (adding helper and reset)", len(syms))
+	}
+
+	if _, err := cb.ListPackageSymbols(t.Context(), "missing", false); err == nil {
+		t.Error("missing package: got = nil, wanted an error")
+	}
+}
+
+func TestGetTypeInfo(t *testing.T) {
+	cb := Callbacks(testModule())
+
+	infos, err := cb.GetTypeInfo(t.Context(), callbacks.SymbolQuery{Name: "Greeter", Package: "greet"})
+	if err != nil {
+		t.Fatalf("GetTypeInfo: %v", err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("infos: got = %d, wanted 1", len(infos))
+	}
+	info := infos[0]
+	if info.Type != "Greeter" || !strings.HasPrefix(info.Underlying, "struct{Name string") {
+		t.Errorf("type: got = %q, %q, wanted Greeter and its struct", info.Type, info.Underlying)
+	}
+	if want := []string{"Name string", "strings.Builder (embedded)"}; !slices.Equal(info.Fields, want) {
+		t.Errorf("fields: got = %q, wanted = %q", info.Fields, want)
+	}
+	if !slices.Contains(info.Methods, "Greet() string") || !slices.Contains(info.Methods, "reset()") {
+		t.Errorf("methods: got = %q, wanted Greet and reset", info.Methods)
+	}
+
+	// A variable is described by its type's fields and methods.
+	infos, err = cb.GetTypeInfo(t.Context(), callbacks.SymbolQuery{Path: "cmd/app/main.go", Line: 10, Column: 2})
+	if err != nil {
+		t.Fatalf("GetTypeInfo: %v", err)
+	}
+	if got := infos[0]; got.Type != "*greet.Greeter" || len(got.Fields) != 2 {
+		t.Errorf("variable: got = %+v, wanted a *greet.Greeter with its fields", got)
+	}
+}
+
+func TestCallbacksSeeEdits(t *testing.T) {
+	fsys := testModule()
+	cb := Callbacks(fsys)
+
+	if _, err := cb.FindDefinition(t.Context(), callbacks.SymbolQuery{Name: "Greeter.Greet"}); err != nil {
+		t.Fatalf("FindDefinition: %v", err)
+	}
+	src := strings.ReplaceAll(string(fsys["greet/greet.go"].Data), "Greet()", "Hello()")
+	fsys["greet/greet.go"] = &fstest.MapFile{Data: []byte(src)}
+
+	if _, err := cb.FindDefinition(t.Context(), callbacks.SymbolQuery{Name: "Greeter.Greet"}); err == nil {
+		t.Error("renamed method: got = nil, wanted it gone")
+	}
+	result, err := cb.FindReferences(t.Context(), callbacks.SymbolQuery{Name: "Greeter.Hello"}, 0, 10)
+	if err != nil {
+		t.Fatalf("FindReferences: %v", err)
+	}
+	// The importers were re-checked against the new package: their calls to
+	// Greet no longer resolve.
+	if result.Total != 0 {
+		t.Errorf("references to Hello: got = %+v, wanted none", result.References)
+	}
+}
+
+func TestWithRootTypesStandardLibrary(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("needs a go command")
+	}
+	fsys := testModule()
+	fsys["ctx/ctx.go"] = &fstest.MapFile{Data: []byte(`package ctx
+
+import "context"
+
+// Root is the root context.
+var Root context.Context = context.Background()
+`)}
+	root := t.TempDir()
+	for name, f := range fsys {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cb := Callbacks(os.DirFS(root), WithRoot(root))
+
+	infos, err := cb.GetTypeInfo(t.Context(), callbacks.SymbolQuery{Name: "Root", Package: "ctx"})
+	if err != nil {
+		t.Fatalf("GetTypeInfo: %v", err)
+	}
+	if got := infos[0]; got.Type != "context.Context" || !slices.Contains(got.Methods, "Done() <-chan struct{}") {
+		t.Errorf("Root: got = %+v, wanted a context.Context with its methods", got)
+	}
+
+	// The standard library's declarations are described, without a
+	// worktree position.
+	syms, err := cb.FindDefinition(t.Context(), callbacks.SymbolQuery{Path: "ctx/ctx.go", Line: 6, Column: 24})
+	if err != nil {
+		t.Fatalf("FindDefinition: %v", err)
+	}
+	if got := syms[0]; got.Name != "Context" || got.Package != "context" || got.Kind != "type" || got.Path != "" || got.Doc == "" {
+		t.Errorf("context.Context: got = %+v, wanted the documented type outside the worktree", got)
+	}
+
+	// An embedded standard library type contributes its methods.
+	infos, err = cb.GetTypeInfo(t.Context(), callbacks.SymbolQuery{Name: "Greeter", Package: "greet"})
+	if err != nil {
+		t.Fatalf("GetTypeInfo: %v", err)
+	}
+	if got := infos[0].Methods; !slices.Contains(got, "String() string") {
+		t.Errorf("Greeter methods: got = %q, wanted strings.Builder's String", got)
+	}
+
+	// References are found from packages checked with and without their
+	// tests.
+	result, err := cb.FindReferences(t.Context(), callbacks.SymbolQuery{Name: "New", Package: "greet"}, 0, 10)
+	if err != nil {
+		t.Fatalf("FindReferences: %v", err)
+	}
+	var paths []string
+	for _, ref := range result.References {
+		paths = append(paths, ref.Path)
+	}
+	if want := []string{"cmd/app/main.go", "greet/example_test.go", "greet/greet_test.go"}; !slices.Equal(paths, want) {
+		t.Errorf("references: got = %q, wanted = %q", paths, want)
+	}
+}
+
+func TestGuessName(t *testing.T) {
+	for path, want := range map[string]string{
+		"fmt":                             "fmt",
+		"github.com/go-git/go-git/v5":     "git",
+		"gopkg.in/yaml.v3":                "yaml",
+		"github.com/foo/bar.go":           "bar",
+		"k8s.io/client-go/kubernetes":     "kubernetes",
+		"github.com/google/go-github/v84": "github",
+	} {
+		if got := guessName(path); got != want {
+			t.Errorf("guessName(%q): got = %q, wanted = %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,701 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gocode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// unitKey identifies a type-checking unit: the package in dir with its
+// in-package tests, or its external test package.
+type unitKey struct {
+	dir   string
+	xtest bool
+}
+
+// unit is a type-checked package.
+type unit struct {
+	key         unitKey
+	fingerprint [sha256.Size]byte
+	pkg         *types.Package
+	info        *types.Info
+	files       []*ast.File
+	src         map[string][]byte // by worktree path
+	decls       map[token.Pos]decl
+	deps        map[string]*types.Package // worktree packages imported, by import path
+
+	// external marks a package from outside the worktree, loaded with
+	// go/packages only to describe the worktree's uses of it.
+	external bool
+}
+
+// decl is the syntax declaring an object, keyed by the position of its name.
+type decl struct {
+	owner string   // the enclosing type of a field or interface method
+	doc   string   // the doc comment
+	typ   string   // the type expression of a field or variable, if any
+	node  ast.Node // the FuncDecl or TypeSpec, for printing signatures
+}
+
+// module is a go.mod found in the worktree.
+type module struct {
+	dir, path string
+}
+
+// session loads units against one snapshot of the worktree.
+type session struct {
+	n       *navigator
+	ctx     context.Context
+	modules []module                // longest directory first
+	dirs    map[string][]sourceFile // build-matched Go files, by directory
+	units   map[unitKey]*unit       // loaded this session
+	byPath  map[string]*unit        // loaded this session, by import path
+	loading map[unitKey]bool
+}
+
+// sourceFile is a Go file matched by the build constraints.
+type sourceFile struct {
+	path string
+	src  []byte
+}
+
+// newSession walks the worktree for modules and Go files.
+func (n *navigator) newSession(ctx context.Context) (*session, error) {
+	s := &session{
+		n:       n,
+		ctx:     ctx,
+		dirs:    make(map[string][]sourceFile),
+		units:   make(map[unitKey]*unit),
+		byPath:  make(map[string]*unit),
+		loading: make(map[unitKey]bool),
+	}
+	srcs := make(map[string][]byte) // Go files and go.mod files, by worktree path
+	err := fs.WalkDir(n.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != "." && skipDir(name) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		switch {
+		case name == "go.mod":
+			data, err := fs.ReadFile(n.fsys, p)
+			if err != nil {
+				return err
+			}
+			if mp := modulePath(data); mp != "" {
+				s.modules = append(s.modules, module{dir: path.Dir(p), path: mp})
+			}
+			srcs[p] = data
+		case strings.HasSuffix(name, ".go"):
+			data, err := fs.ReadFile(n.fsys, p)
+			if err != nil {
+				return err
+			}
+			srcs[p] = data
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(s.modules, func(a, b module) int { return len(b.dir) - len(a.dir) })
+
+	// Evaluate build constraints against the sources just read.
+	ctxt := build.Default
+	ctxt.CgoEnabled = false
+	ctxt.JoinPath = path.Join
+	ctxt.OpenFile = func(p string) (io.ReadCloser, error) {
+		data, ok := srcs[p]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	for p, data := range srcs {
+		if !strings.HasSuffix(p, ".go") {
+			continue
+		}
+		dir, name := path.Split(p)
+		dir = path.Clean(dir)
+		if ok, err := ctxt.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		s.dirs[dir] = append(s.dirs[dir], sourceFile{path: p, src: data})
+	}
+	for _, files := range s.dirs {
+		slices.SortFunc(files, func(a, b sourceFile) int { return strings.Compare(a.path, b.path) })
+	}
+	if n.root != "" {
+		return s, s.loadPackages(srcs)
+	}
+	for key := range n.cache {
+		if _, ok := s.dirs[key.dir]; !ok {
+			delete(n.cache, key)
+		}
+	}
+	return s, nil
+}
+
+// skipDir reports whether the go command ignores directories with this name.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+		name == "vendor" || name == "testdata" || name == "node_modules"
+}
+
+// modulePath returns the path in a go.mod's module directive.
+func modulePath(gomod []byte) string {
+	for line := range strings.Lines(string(gomod)) {
+		line, _, _ = strings.Cut(line, "//")
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module")
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != '\t' && rest[0] != '"') {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if p, err := strconv.Unquote(rest); err == nil {
+			return p
+		}
+		return rest
+	}
+	return ""
+}
+
+// module returns the module containing dir.
+func (s *session) module(dir string) (module, bool) {
+	for _, m := range s.modules {
+		if m.dir == "." || dir == m.dir || strings.HasPrefix(dir, m.dir+"/") {
+			return m, true
+		}
+	}
+	return module{}, false
+}
+
+// importPath returns the import path of the package in dir. Outside any
+// module, the directory itself stands in for it.
+func (s *session) importPath(dir string) string {
+	m, ok := s.module(dir)
+	if !ok {
+		return dir
+	}
+	if dir == m.dir {
+		return m.path
+	}
+	if m.dir == "." {
+		return m.path + "/" + dir
+	}
+	return m.path + strings.TrimPrefix(dir, m.dir)
+}
+
+// importDir returns the worktree directory of the package with the given
+// import path, if the worktree has one.
+func (s *session) importDir(importPath string) (string, bool) {
+	for _, m := range s.modules {
+		rel, ok := strings.CutPrefix(importPath, m.path)
+		if !ok || (rel != "" && rel[0] != '/') {
+			continue
+		}
+		dir := path.Join(m.dir, strings.TrimPrefix(rel, "/"))
+		// A nested module owns its directories.
+		if owner, _ := s.module(dir); owner != m {
+			continue
+		}
+		if _, ok := s.dirs[dir]; ok {
+			return dir, true
+		}
+	}
+	if _, ok := s.dirs[importPath]; ok {
+		if _, inModule := s.module(importPath); !inModule {
+			return importPath, true
+		}
+	}
+	return "", false
+}
+
+// packageDirs returns the directories holding Go files, sorted.
+func (s *session) packageDirs() []string {
+	dirs := make([]string, 0, len(s.dirs))
+	for dir := range s.dirs {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// all loads every unit in the worktree, or below pattern when it is set: a
+// directory, or a directory followed by "/..." to include those below it.
+func (s *session) all(pattern string) ([]*unit, error) {
+	base, recursive := ".", pattern == ""
+	if pattern != "" {
+		base, recursive = strings.CutSuffix(path.Clean(strings.TrimPrefix(pattern, "/")), "...")
+		base = path.Clean(strings.TrimSuffix(base, "/"))
+	}
+	var units []*unit
+	for _, dir := range s.packageDirs() {
+		switch {
+		case dir == base:
+		case recursive && (base == "." || strings.HasPrefix(dir, base+"/")):
+		default:
+			continue
+		}
+		for _, xtest := range []bool{false, true} {
+			u, err := s.load(unitKey{dir: dir, xtest: xtest})
+			if err != nil {
+				return nil, err
+			}
+			if u != nil {
+				units = append(units, u)
+			}
+		}
+	}
+	return units, nil
+}
+
+// load returns the unit for key, type-checking it and the worktree packages it
+// imports unless the navigator's cached unit is still current. It returns nil
+// when the directory has no files for the unit.
+func (s *session) load(key unitKey) (*unit, error) {
+	if u, ok := s.units[key]; ok {
+		return u, nil
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.n.root != "" {
+		// go/packages loaded every unit up front.
+		return nil, nil
+	}
+	if s.loading[key] {
+		// An import cycle; the type checker reports it as an error.
+		return nil, nil
+	}
+	s.loading[key] = true
+	defer delete(s.loading, key)
+
+	files := s.unitFiles(key)
+	if len(files) == 0 {
+		s.units[key] = nil
+		return nil, nil
+	}
+	fp := fingerprint(files)
+	if u := s.n.cache[key]; u != nil && u.fingerprint == fp && s.current(u) {
+		s.store(u)
+		return u, nil
+	}
+
+	u, err := s.check(key, files, fp)
+	if err != nil {
+		return nil, err
+	}
+	s.n.cache[key] = u
+	s.store(u)
+	return u, nil
+}
+
+func (s *session) store(u *unit) {
+	s.units[u.key] = u
+	s.byPath[u.pkg.Path()] = u
+}
+
+// current reports whether the packages a cached unit imports are the ones
+// loaded now.
+func (s *session) current(u *unit) bool {
+	for importPath, pkg := range u.deps {
+		dir, ok := s.importDir(importPath)
+		if !ok {
+			return false
+		}
+		dep, err := s.load(unitKey{dir: dir})
+		if err != nil || dep == nil || dep.pkg != pkg {
+			return false
+		}
+	}
+	return true
+}
+
+// unitFiles returns the files of a unit. Files whose package clause
+// disagrees with the package's are left out, as the go command would refuse
+// them.
+func (s *session) unitFiles(key unitKey) []sourceFile {
+	var files []sourceFile
+	var name string
+	for _, f := range s.dirs[key.dir] {
+		pkg := packageName(f.src)
+		if pkg == "" {
+			continue
+		}
+		test := strings.HasSuffix(f.path, "_test.go")
+		if xtest := test && strings.HasSuffix(pkg, "_test"); xtest != key.xtest {
+			continue
+		}
+		if name == "" {
+			name = pkg
+		}
+		if pkg == name {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// packageName returns the name in a file's package clause.
+func packageName(src []byte) string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly)
+	if err != nil || f.Name == nil {
+		return ""
+	}
+	return f.Name.Name
+}
+
+// fingerprint hashes the paths and contents of files.
+func fingerprint(files []sourceFile) [sha256.Size]byte {
+	h := sha256.New()
+	for _, f := range files {
+		h.Write([]byte(f.path))
+		h.Write([]byte{0})
+		h.Write(f.src)
+		h.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// check parses and type-checks a unit. Type errors are expected, since
+// packages outside the worktree are placeholders, and are ignored: go/types
+// records everything it can resolve regardless.
+func (s *session) check(key unitKey, files []sourceFile, fp [sha256.Size]byte) (*unit, error) {
+	u := &unit{
+		key:         key,
+		fingerprint: fp,
+		src:         make(map[string][]byte, len(files)),
+		decls:       make(map[token.Pos]decl),
+		deps:        make(map[string]*types.Package),
+	}
+	for _, f := range files {
+		// Syntax errors still yield a partial tree worth checking.
+		af, _ := parser.ParseFile(s.n.fset, f.path, f.src, parser.ParseComments|parser.SkipObjectResolution)
+		if af == nil || af.Name == nil {
+			continue
+		}
+		u.files = append(u.files, af)
+		u.src[f.path] = f.src
+		collectDecls(s.n.fset, af, u.decls)
+	}
+
+	importPath := s.importPath(key.dir)
+	if key.xtest {
+		importPath += "_test"
+	}
+	var loadErr error
+	conf := types.Config{
+		Importer: importerFunc(func(ip string) (*types.Package, error) {
+			if ip == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if dir, ok := s.importDir(ip); ok {
+				dep, err := s.load(unitKey{dir: dir})
+				if err != nil {
+					loadErr = err
+				}
+				if dep != nil {
+					u.deps[ip] = dep.pkg
+					return dep.pkg, nil
+				}
+			}
+			return s.n.placeholder(ip), nil
+		}),
+		Error:       func(error) {},
+		FakeImportC: true,
+	}
+	u.info = &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	u.pkg, _ = conf.Check(importPath, s.n.fset, u.files, u.info)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return u, nil
+}
+
+// loadMode is what loadPackages asks go/packages for: every package the
+// worktree's packages depend on, type-checked from source with its syntax.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// loadPackages loads the packages of each module in the worktree, with their
+// tests and dependencies, through go/packages from the module's directory
+// under the navigator's root. srcs holds the worktree's Go files and go.mod
+// files; when they are unchanged since the last load, its units are reused.
+//
+// With tests, go/packages checks a package twice: alone, and with its
+// in-package test files. The unit for the directory is the variant with the
+// tests; both share their syntax, so objects from either variant have the
+// same positions.
+func (s *session) loadPackages(srcs map[string][]byte) error {
+	files := make([]sourceFile, 0, len(srcs))
+	for p, data := range srcs {
+		files = append(files, sourceFile{path: p, src: data})
+	}
+	slices.SortFunc(files, func(a, b sourceFile) int { return strings.Compare(a.path, b.path) })
+	fp := fingerprint(files)
+	if l := s.n.loaded; l != nil && l.fingerprint == fp {
+		s.units, s.byPath = l.units, l.byPath
+		return nil
+	}
+
+	var (
+		mu     sync.Mutex
+		parsed = make(map[string][]byte) // worktree sources parsed, by worktree path
+	)
+	parse := func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		if p, ok := s.n.worktreePath(filename); ok {
+			filename = p
+			mu.Lock()
+			parsed[p] = src
+			mu.Unlock()
+		}
+		return parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	}
+	for _, m := range s.modules {
+		cfg := &packages.Config{
+			Mode:      loadMode,
+			Context:   s.ctx,
+			Dir:       filepath.Join(s.n.root, filepath.FromSlash(m.dir)),
+			Env:       append(os.Environ(), "CGO_ENABLED=0"),
+			Fset:      s.n.fset,
+			ParseFile: parse,
+			Tests:     true,
+		}
+		// Package errors, like type errors, are expected and ignored; only
+		// a failure to run the build system fails the load.
+		pkgs, err := packages.Load(cfg, "./...")
+		if err != nil {
+			return fmt.Errorf("loading packages of %s: %w", m.path, err)
+		}
+		packages.Visit(pkgs, nil, func(p *packages.Package) {
+			s.addPackage(p, parsed)
+		})
+	}
+	s.n.loaded = &packagesLoad{fingerprint: fp, units: s.units, byPath: s.byPath}
+	return nil
+}
+
+// addPackage records a package go/packages loaded: as the unit of its
+// worktree directory, or as an external package when it lies outside the
+// worktree.
+func (s *session) addPackage(p *packages.Package, parsed map[string][]byte) {
+	if p.Types == nil || len(p.GoFiles) == 0 || strings.HasSuffix(p.PkgPath, ".test") {
+		return // nothing checked, or a generated test main
+	}
+	dir, inTree := s.n.worktreePath(filepath.Dir(p.GoFiles[0]))
+	key := unitKey{dir: dir, xtest: strings.HasSuffix(p.PkgPath, "_test")}
+	// The variant with the package's own tests; other test variants are
+	// recompiled for a dependent's tests and lack them.
+	withTests := key.xtest || p.ID == p.PkgPath+" ["+p.PkgPath+".test]"
+	switch {
+	case !inTree && s.byPath[p.PkgPath] != nil:
+		return
+	case inTree && p.ID != p.PkgPath && !withTests:
+		return
+	case inTree && s.units[key] != nil && !withTests:
+		return // keep the variant with the in-package tests
+	}
+
+	u := &unit{
+		key:      key,
+		pkg:      p.Types,
+		info:     p.TypesInfo,
+		files:    p.Syntax,
+		src:      make(map[string][]byte),
+		decls:    make(map[token.Pos]decl),
+		external: !inTree,
+	}
+	for _, f := range p.Syntax {
+		collectDecls(s.n.fset, f, u.decls)
+		name := s.n.fset.File(f.Pos()).Name()
+		if src, ok := parsed[name]; ok {
+			u.src[name] = src
+		}
+	}
+	if inTree {
+		s.units[key] = u
+	}
+	s.byPath[p.PkgPath] = u
+}
+
+// worktreePath returns the worktree path of a file or directory go/packages
+// reports, and whether it lies inside the worktree.
+func (n *navigator) worktreePath(name string) (string, bool) {
+	rel, err := filepath.Rel(n.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return name, false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// placeholder returns the empty package standing in for an import from
+// outside the worktree. The same package is returned for every import of
+// the path, so objects compare equal across units.
+func (n *navigator) placeholder(importPath string) *types.Package {
+	if pkg, ok := n.placeholders[importPath]; ok {
+		return pkg
+	}
+	pkg := types.NewPackage(importPath, guessName(importPath))
+	pkg.MarkComplete()
+	n.placeholders[importPath] = pkg
+	return pkg
+}
+
+// guessName guesses a package's name from its import path, the way goimports
+// does: the last element that is not a major version, without a "go-" prefix
+// or ".go" suffix, up to its first non-identifier character.
+func guessName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, ".go")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// collectDecls records the declaration syntax of a file's package-level
+// objects, and of the fields and methods of its types.
+func collectDecls(fset *token.FileSet, f *ast.File, decls map[token.Pos]decl) {
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			decls[d.Name.Pos()] = decl{doc: d.Doc.Text(), node: d}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				doc := d.Doc
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					decls[spec.Name.Pos()] = decl{doc: doc.Text(), node: spec}
+					collectMembers(fset, spec.Name.Name, spec.Type, decls)
+				case *ast.ValueSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					for _, name := range spec.Names {
+						decls[name.Pos()] = decl{doc: doc.Text(), typ: exprString(fset, spec.Type)}
+					}
+				}
+			}
+		}
+	}
+}
+
+// collectMembers records the fields of a struct type and the methods of an
+// interface type.
+func collectMembers(fset *token.FileSet, owner string, typ ast.Expr, decls map[token.Pos]decl) {
+	var fields *ast.FieldList
+	switch t := typ.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	default:
+		return
+	}
+	for _, field := range fields.List {
+		doc := field.Doc
+		if doc == nil {
+			doc = field.Comment
+		}
+		typ := exprString(fset, field.Type)
+		for _, name := range field.Names {
+			decls[name.Pos()] = decl{owner: owner, doc: doc.Text(), typ: typ}
+		}
+		if len(field.Names) == 0 {
+			// An embedded field is named by its type.
+			if id := embeddedName(field.Type); id != nil {
+				decls[id.Pos()] = decl{owner: owner, doc: doc.Text(), typ: typ}
+			}
+		}
+	}
+}
+
+// exprString prints an expression, or returns "" for a nil one.
+func exprString(fset *token.FileSet, x ast.Expr) string {
+	if x == nil {
+		return ""
+	}
+	var b strings.Builder
+	if err := printer.Fprint(&b, fset, x); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// embeddedName returns the identifier naming an embedded field.
+func embeddedName(x ast.Expr) *ast.Ident {
+	for {
+		switch t := x.(type) {
+		case *ast.Ident:
+			return t
+		case *ast.StarExpr:
+			x = t.X
+		case *ast.SelectorExpr:
+			return t.Sel
+		case *ast.IndexExpr:
+			x = t.X
+		case *ast.IndexListExpr:
+			x = t.X
+		default:
+			return nil
+		}
+	}
+}
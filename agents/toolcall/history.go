@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"time"
 
 	"chainguard.dev/driftlessaf/agents/agenttrace"
 	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
@@ -35,7 +36,8 @@ type historyToolsProvider[Resp, T any] struct {
 var _ ToolProvider[any, HistoryTools[any]] = (*historyToolsProvider[any, any])(nil)
 
 // NewHistoryToolsProvider creates a provider that adds history tools
-// (list_commits, get_file_diff) on top of the base provider's tools.
+// (list_commits, get_file_diff, and blame, log and show_file_at_revision when
+// their callbacks are set) on top of the base provider's tools.
 func NewHistoryToolsProvider[Resp, T any](base ToolProvider[Resp, T]) ToolProvider[Resp, HistoryTools[T]] {
 	return historyToolsProvider[Resp, T]{baseProvider: base}
 }
@@ -57,10 +59,22 @@ const (
 	// maxFileDiffLimit is the maximum number of bytes that can be returned
 	// in a single get_file_diff call.
 	maxFileDiffLimit = 100000
+
+	// maxBlameLines is the maximum number of lines a single blame call
+	// attributes.
+	maxBlameLines = 500
+
+	// maxLogLimit is the maximum number of commits that can be returned in
+	// a single log call.
+	maxLogLimit = 100
+
+	// maxShowFileLimit is the maximum number of bytes that can be returned
+	// in a single show_file_at_revision call.
+	maxShowFileLimit = 100000
 )
 
 func historyToolDefs[Resp any](cb callbacks.HistoryCallbacks) map[string]Tool[Resp] {
-	return map[string]Tool[Resp]{
+	tools := map[string]Tool[Resp]{
 		"list_commits": {
 			Def: Definition{
 				Name:        "list_commits",
@@ -172,6 +186,235 @@ func historyToolDefs[Resp any](cb callbacks.HistoryCallbacks) map[string]Tool[Re
 			},
 		},
 	}
+	if cb.Blame != nil {
+		tools["blame"] = blameTool[Resp](cb.Blame)
+	}
+	if cb.Log != nil {
+		tools["log"] = logTool[Resp](cb.Log)
+	}
+	if cb.ShowFile != nil {
+		tools["show_file_at_revision"] = showFileAtRevisionTool[Resp](cb.ShowFile)
+	}
+	return tools
+}
+
+func blameTool[Resp any](blame func(context.Context, string, string, int, int) (callbacks.BlameResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "blame",
+			Description: "Show which commit last changed each line in a range of a file, with its author and date. " +
+				"Use it to find the change that introduced a line, then inspect that commit with log or show_file_at_revision.",
+			Parameters: []Parameter{
+				{Name: "path", Type: "string", Description: "File path (relative to repository root)", Required: true},
+				{Name: "revision", Type: "string", Description: "Commit SHA, branch or revision expression such as HEAD~3 to blame the file at. Omit for HEAD.", Required: false},
+				{Name: "start_line", Type: "integer", Description: "First line to blame, 1-based (default: 1)", Required: false, Minimum: Ptr[float64](1)},
+				{Name: "end_line", Type: "integer", Description: fmt.Sprintf("Last line to blame, inclusive (default: start_line + 99; at most %d lines per call)", maxBlameLines), Required: false, Minimum: Ptr[float64](1)},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			path, errResp := Param[string](call, trace, "path")
+			if errResp != nil {
+				return errResp
+			}
+			revision, errResp := OptionalParam[string](call, "revision", "")
+			if errResp != nil {
+				return errResp
+			}
+			startLine, errResp := OptionalParam[int](call, "start_line", 1)
+			if errResp != nil {
+				return errResp
+			}
+			endLine, errResp := OptionalParam[int](call, "end_line", startLine+99)
+			if errResp != nil {
+				return errResp
+			}
+			switch {
+			case startLine < 1:
+				return params.Error("start_line must be >= 1, got %d", startLine)
+			case endLine < startLine:
+				return params.Error("end_line %d is before start_line %d", endLine, startLine)
+			case endLine-startLine+1 > maxBlameLines:
+				return params.Error("line range %d-%d exceeds maximum of %d lines", startLine, endLine, maxBlameLines)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"path": path, "revision": revision, "start_line": startLine, "end_line": endLine})
+
+			result, err := blame(ctx, path, revision, startLine, endLine-startLine+1)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to blame file", err, "path", path, "revision", revision)
+			}
+
+			lines := make([]map[string]any, 0, len(result.Lines))
+			for _, l := range result.Lines {
+				lines = append(lines, map[string]any{
+					"line":   l.Line,
+					"sha":    l.SHA,
+					"author": l.Author,
+					"date":   l.Date.UTC().Format(time.RFC3339),
+					"text":   l.Text,
+				})
+			}
+			resp := map[string]any{
+				"path":        path,
+				"lines":       lines,
+				"total_lines": result.TotalLines,
+			}
+			if result.NextLine != nil {
+				resp["next_line"] = *result.NextLine
+			}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func logTool[Resp any](log func(context.Context, callbacks.LogQuery, int, int) (callbacks.LogResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "log",
+			Description: "Search commit history, newest first, for commits that changed a path and/or whose message matches a regular expression. " +
+				"Unlike list_commits, it is not limited to the changes since the base branch. " +
+				"truncated is set when older history could not be searched.",
+			Parameters: []Parameter{
+				{Name: "revision", Type: "string", Description: "Commit SHA, branch or revision expression to walk back from. Omit for HEAD.", Required: false},
+				{Name: "path", Type: "string", Description: "Only list commits that changed this file or directory (relative to repository root)", Required: false},
+				{Name: "message_pattern", Type: "string", Description: "Only list commits whose message matches this regular expression (RE2 syntax; prefix with (?i) to ignore case)", Required: false},
+				{Name: "offset", Type: "integer", Description: "Number of matching commits to skip (default: 0)", Required: false, Minimum: Ptr[float64](0)},
+				{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum commits to return (default: 20, max: %d)", maxLogLimit), Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			var q callbacks.LogQuery
+			var errResp map[string]any
+			if q.Rev, errResp = OptionalParam[string](call, "revision", ""); errResp != nil {
+				return errResp
+			}
+			if q.Path, errResp = OptionalParam[string](call, "path", ""); errResp != nil {
+				return errResp
+			}
+			if q.MessagePattern, errResp = OptionalParam[string](call, "message_pattern", ""); errResp != nil {
+				return errResp
+			}
+			offset, errResp := OptionalParam[int](call, "offset", 0)
+			if errResp != nil {
+				return errResp
+			}
+			limit, errResp := OptionalParam[int](call, "limit", 20)
+			if errResp != nil {
+				return errResp
+			}
+			switch {
+			case offset < 0:
+				return params.Error("offset must be >= 0, got %d", offset)
+			case limit < 1 || limit > maxLogLimit:
+				return params.Error("limit must be between 1 and %d, got %d", maxLogLimit, limit)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{
+				"revision": q.Rev, "path": q.Path, "message_pattern": q.MessagePattern, "offset": offset, "limit": limit,
+			})
+
+			result, err := log(ctx, q, offset, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to search history", err, "revision", q.Rev, "path", q.Path, "message_pattern", q.MessagePattern)
+			}
+
+			commits := make([]map[string]any, 0, len(result.Commits))
+			for _, c := range result.Commits {
+				commits = append(commits, map[string]any{
+					"sha":     c.SHA,
+					"author":  c.Author,
+					"date":    c.Date.UTC().Format(time.RFC3339),
+					"message": c.Message,
+				})
+			}
+			resp := map[string]any{"commits": commits}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+			}
+			if result.Truncated {
+				resp["truncated"] = true
+			}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
+func showFileAtRevisionTool[Resp any](showFile func(context.Context, string, string, int64, int) (callbacks.ReadResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name:        "show_file_at_revision",
+			Description: "Read a file as it was at a given commit, starting at a byte offset. Returns the content, next_offset to continue reading, and remaining bytes.",
+			Parameters: []Parameter{
+				{Name: "path", Type: "string", Description: "File path (relative to repository root)", Required: true},
+				{Name: "revision", Type: "string", Description: "Commit SHA, branch or revision expression such as HEAD~3", Required: true},
+				{Name: "offset", Type: "integer", Description: "Byte offset to start reading from (default: 0)", Required: false, Minimum: Ptr[float64](0)},
+				{Name: "limit", Type: "integer", Description: fmt.Sprintf("Maximum bytes to read (default: 20000, max: %d)", maxShowFileLimit), Required: false},
+			},
+			Annotations: &ToolAnnotations{
+				ReadOnly:    true,
+				Destructive: Ptr(false),
+				Idempotent:  true,
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			path, errResp := Param[string](call, trace, "path")
+			if errResp != nil {
+				return errResp
+			}
+			revision, errResp := Param[string](call, trace, "revision")
+			if errResp != nil {
+				return errResp
+			}
+			offset, errResp := OptionalParam[int64](call, "offset", 0)
+			if errResp != nil {
+				return errResp
+			}
+			if errResp := rejectNegativeOffset(call, trace, offset); errResp != nil {
+				return errResp
+			}
+			limit, errResp := OptionalParam[int](call, "limit", 20000)
+			if errResp != nil {
+				return errResp
+			}
+			if limit < 1 || limit > maxShowFileLimit {
+				return params.Error("limit must be between 1 and %d, got %d", maxShowFileLimit, limit)
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"path": path, "revision": revision, "offset": offset, "limit": limit})
+
+			result, err := showFile(ctx, path, revision, offset, limit)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to read file at revision", err, "path", path, "revision", revision)
+			}
+
+			resp := map[string]any{
+				"path":     path,
+				"revision": revision,
+				"content":  result.Content,
+			}
+			if result.NextOffset != nil {
+				resp["next_offset"] = *result.NextOffset
+			}
+			resp["remaining"] = result.Remaining
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
 }
 
 func formatCommitListResult(result callbacks.CommitListResult) map[string]any {
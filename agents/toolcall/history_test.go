@@ -0,0 +1,111 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package toolcall
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/agents/agenttrace"
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestHistoryTools(t *testing.T) {
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var gotQuery callbacks.LogQuery
+	var gotRange [2]int
+	cb := callbacks.HistoryCallbacks{
+		Blame: func(_ context.Context, path, rev string, startLine, limit int) (callbacks.BlameResult, error) {
+			gotRange = [2]int{startLine, limit}
+			next := startLine + 1
+			return callbacks.BlameResult{
+				Lines:      []callbacks.BlameLine{{Line: startLine, SHA: "abc1234", Author: "alice", Date: when, Text: "x := 1"}},
+				NextLine:   &next,
+				TotalLines: 40,
+			}, nil
+		},
+		Log: func(_ context.Context, q callbacks.LogQuery, offset, limit int) (callbacks.LogResult, error) {
+			gotQuery = q
+			return callbacks.LogResult{
+				Commits:   []callbacks.LogEntry{{SHA: "abc1234", Author: "alice", Date: when, Message: "fix: x"}},
+				Truncated: true,
+			}, nil
+		},
+		ShowFile: func(_ context.Context, path, rev string, offset int64, limit int) (callbacks.ReadResult, error) {
+			return callbacks.ReadResult{Content: path + "@" + rev}, nil
+		},
+	}
+	tools := historyToolDefs[string](cb)
+	call := func(name string, args map[string]any) map[string]any {
+		t.Helper()
+		trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+		resp := tools[name].Handler(t.Context(), ToolCall{ID: "1", Name: name, Args: args}, trace, nil)
+		if msg, ok := resp["error"]; ok {
+			t.Fatalf("%s: %v", name, msg)
+		}
+		return resp
+	}
+
+	resp := call("blame", map[string]any{"path": "a.go", "start_line": float64(10), "end_line": float64(19)})
+	if gotRange != [2]int{10, 10} {
+		t.Errorf("blame range: got = %v, want start 10 and 10 lines", gotRange)
+	}
+	lines, _ := resp["lines"].([]map[string]any)
+	if len(lines) != 1 || lines[0]["sha"] != "abc1234" || lines[0]["date"] != "2026-01-02T03:04:05Z" || resp["next_line"] != 11 || resp["total_lines"] != 40 {
+		t.Errorf("blame: got = %v, want one line attributed to abc1234", resp)
+	}
+
+	resp = call("log", map[string]any{"path": "pkg", "message_pattern": "^fix"})
+	if gotQuery != (callbacks.LogQuery{Path: "pkg", MessagePattern: "^fix"}) {
+		t.Errorf("log query: got = %+v, want path and pattern passed through", gotQuery)
+	}
+	if commits, _ := resp["commits"].([]map[string]any); len(commits) != 1 || commits[0]["message"] != "fix: x" || resp["truncated"] != true {
+		t.Errorf("log: got = %v, want one truncated match", resp)
+	}
+
+	resp = call("show_file_at_revision", map[string]any{"path": "a.go", "revision": "HEAD~2"})
+	if resp["content"] != "a.go@HEAD~2" || resp["remaining"] != int64(0) {
+		t.Errorf("show_file_at_revision: got = %v, want the file at HEAD~2", resp)
+	}
+}
+
+func TestHistoryToolsRejectBadArgs(t *testing.T) {
+	cb := callbacks.HistoryCallbacks{
+		Blame: func(context.Context, string, string, int, int) (callbacks.BlameResult, error) {
+			return callbacks.BlameResult{}, nil
+		},
+		Log: func(context.Context, callbacks.LogQuery, int, int) (callbacks.LogResult, error) {
+			return callbacks.LogResult{}, nil
+		},
+	}
+	tools := historyToolDefs[string](cb)
+	if _, ok := tools["show_file_at_revision"]; ok {
+		t.Error("show_file_at_revision: registered without a ShowFile callback")
+	}
+	for _, tc := range []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{"missing path", "blame", map[string]any{}, "path"},
+		{"zero start line", "blame", map[string]any{"path": "a.go", "start_line": float64(0)}, "start_line"},
+		{"inverted range", "blame", map[string]any{"path": "a.go", "start_line": float64(5), "end_line": float64(4)}, "before start_line"},
+		{"range too large", "blame", map[string]any{"path": "a.go", "end_line": float64(maxBlameLines + 1)}, "exceeds maximum"},
+		{"negative offset", "log", map[string]any{"offset": float64(-1)}, "offset"},
+		{"limit too large", "log", map[string]any{"limit": float64(maxLogLimit + 1)}, "limit"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+			resp := tools[tc.tool].Handler(t.Context(), ToolCall{ID: "1", Name: tc.tool, Args: tc.args}, trace, nil)
+			if msg, _ := resp["error"].(string); !strings.Contains(msg, tc.want) {
+				t.Errorf("response: got = %v, want an error mentioning %q", resp, tc.want)
+			}
+		})
+	}
+}
@@ -51,13 +51,25 @@ func (p worktreeToolsProvider[Resp, T]) Tools(ctx context.Context, cb WorktreeTo
 }
 
 func worktreeToolDefs[Resp any](cb callbacks.WorktreeCallbacks) map[string]Tool[Resp] {
-	tools := make(map[string]Tool[Resp], 10)
+	tools := make(map[string]Tool[Resp], 14)
 	if cb.ReadFile != nil {
 		tools["read_file"] = readFileTool[Resp](cb.ReadFile)
 	}
 	if cb.EditFile != nil {
 		tools["edit_file"] = editFileTool[Resp](cb.EditFile)
 	}
+	if cb.ApplyPatch != nil {
+		tools["apply_patch"] = applyPatchTool[Resp](cb.ApplyPatch)
+	}
+	if cb.CreateCheckpoint != nil {
+		tools["create_checkpoint"] = createCheckpointTool[Resp](cb.CreateCheckpoint)
+	}
+	if cb.DiffSinceCheckpoint != nil {
+		tools["diff_since_checkpoint"] = diffSinceCheckpointTool[Resp](cb.DiffSinceCheckpoint)
+	}
+	if cb.RestoreCheckpoint != nil {
+		tools["restore_checkpoint"] = restoreCheckpointTool[Resp](cb.RestoreCheckpoint)
+	}
 	if cb.WriteFile != nil {
 		tools["write_file"] = writeFileTool[Resp](cb.WriteFile)
 	}
@@ -184,6 +196,80 @@ func editFileTool[Resp any](editFile func(context.Context, string, string, strin
 	}
 }
 
+func applyPatchTool[Resp any](applyPatch func(context.Context, string) (callbacks.PatchResult, error)) Tool[Resp] {
+	return Tool[Resp]{
+		Def: Definition{
+			Name: "apply_patch",
+			Description: "Apply a unified diff (as produced by diff -u or git diff) that may change, create, delete or rename several files at once. " +
+				"Hunks may be placed a few lines away from their header, and context that differs only in whitespace still matches where it matches just one place. " +
+				"The patch applies atomically: if any hunk fails, no file changes and each failing hunk is reported so you can re-read the file and resend a corrected patch.",
+			Parameters: []Parameter{
+				{Name: "patch", Type: "string", Description: "The unified diff, with ---/+++ file headers (a/ and b/ prefixes and /dev/null are understood) and @@ hunks with accurate line counts", Required: true},
+			},
+			Annotations: &ToolAnnotations{
+				Destructive: Ptr(true),
+				OpenWorld:   Ptr(false),
+			},
+		},
+		Handler: func(ctx context.Context, call ToolCall, trace *agenttrace.Trace[Resp], _ *Resp) map[string]any {
+			patch, errResp := Param[string](call, trace, "patch")
+			if errResp != nil {
+				return errResp
+			}
+
+			tc := trace.StartToolCall(call.ID, call.Name, map[string]any{"patch_bytes": len(patch)})
+
+			result, err := applyPatch(ctx, patch)
+			if err != nil {
+				return completeError(ctx, tc, "Failed to apply patch", err)
+			}
+
+			files := make([]map[string]any, 0, len(result.Files))
+			for _, f := range result.Files {
+				file := map[string]any{"path": f.Path, "type": f.Type}
+				if f.OldPath != "" {
+					file["old_path"] = f.OldPath
+				}
+				if f.Error != "" {
+					file["error"] = f.Error
+				}
+				hunks := make([]map[string]any, 0, len(f.Hunks))
+				for _, h := range f.Hunks {
+					hunk := map[string]any{"header": h.Header, "applied": h.Applied}
+					if h.Applied {
+						hunk["line"] = h.Line
+					}
+					if h.Offset != 0 {
+						hunk["offset"] = h.Offset
+					}
+					if h.Fuzz != 0 {
+						hunk["fuzz"] = h.Fuzz
+					}
+					if h.Whitespace {
+						hunk["whitespace_ignored"] = true
+					}
+					if h.Error != "" {
+						hunk["error"] = h.Error
+					}
+					hunks = append(hunks, hunk)
+				}
+				file["hunks"] = hunks
+				files = append(files, file)
+			}
+
+			resp := map[string]any{"applied": result.Applied, "files": files}
+			if !result.Applied {
+				err := fmt.Errorf("patch not applied: %d file or hunk failures, no files were changed", result.Failed())
+				resp["error"] = err.Error()
+				tc.Complete(resp, err)
+				return resp
+			}
+			tc.Complete(resp, nil)
+			return resp
+		},
+	}
+}
+
 func writeFileTool[Resp any](writeFile func(context.Context, string, string, os.FileMode) error) Tool[Resp] {
 	return Tool[Resp]{
 		Def: Definition{
@@ -99,3 +99,42 @@ func TestWorktreeToolsOffsetSchemaMinimum(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyPatchToolReportsFailedHunks(t *testing.T) {
+	cb := callbacks.WorktreeCallbacks{
+		ApplyPatch: func(_ context.Context, patch string) (callbacks.PatchResult, error) {
+			if !strings.HasPrefix(patch, "--- a/main.go") {
+				t.Errorf("patch: got = %q, want the model's diff", patch)
+			}
+			return callbacks.PatchResult{Files: []callbacks.PatchFileResult{{
+				Path: "main.go",
+				Type: "modified",
+				Hunks: []callbacks.HunkResult{
+					{Header: "@@ -1,3 +1,3 @@", Applied: true, Line: 3, Offset: 2},
+					{Header: "@@ -20,3 +20,3 @@", Error: "context not found"},
+				},
+			}}}, nil
+		},
+	}
+	tools := worktreeToolDefs[string](cb)
+
+	trace, _ := agenttrace.StartTrace[string](t.Context(), "test")
+	resp := tools["apply_patch"].Handler(t.Context(), ToolCall{ID: "1", Name: "apply_patch", Args: map[string]any{
+		"patch": "--- a/main.go\n+++ b/main.go\n",
+	}}, trace, nil)
+
+	if resp["applied"] != false {
+		t.Errorf("applied: got = %v, want = false", resp["applied"])
+	}
+	if msg, _ := resp["error"].(string); !strings.Contains(msg, "no files were changed") {
+		t.Errorf("error: got = %q, want it to say nothing changed", msg)
+	}
+	files, _ := resp["files"].([]map[string]any)
+	if len(files) != 1 {
+		t.Fatalf("files: got = %v, want one file", resp["files"])
+	}
+	hunks, _ := files[0]["hunks"].([]map[string]any)
+	if len(hunks) != 2 || hunks[0]["offset"] != 2 || hunks[1]["error"] != "context not found" {
+		t.Errorf("hunks: got = %v, want the placed hunk's offset and the failed hunk's error", hunks)
+	}
+}
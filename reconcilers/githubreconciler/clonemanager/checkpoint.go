@@ -0,0 +1,333 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clonemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+)
+
+// worktreeCheckpoints implements the checkpoint callbacks of
+// WorktreeCallbacks on top of the git index and object store.
+//
+// The index is the baseline: it is never written before commitChanges (see
+// WorktreeCallbacks), so it still holds the checked-out commit, and it is
+// StartCheckpoint. A checkpoint records only the files that differ from the
+// index when it is taken, per wt.Status, with their content stashed as blobs
+// in the object store; every other file is at its index version. Diffs and
+// restores therefore cover the whole worktree, including files written by
+// commands, except paths the repository's .gitignore excludes.
+type worktreeCheckpoints struct {
+	wt   *gogit.Worktree
+	root string
+	repo func() (*gogit.Repository, error)
+
+	mu    sync.Mutex
+	seq   int
+	ids   []string
+	snaps []map[string]fileVersion // snaps[i] holds the files that differed from the index at checkpoint ids[i]
+}
+
+// fileVersion is a version of a worktree file, its content stored as a blob.
+type fileVersion struct {
+	exists  bool
+	symlink bool
+	exec    bool
+	hash    plumbing.Hash
+}
+
+func newWorktreeCheckpoints(wt *gogit.Worktree, root string) *worktreeCheckpoints {
+	return &worktreeCheckpoints{
+		wt:   wt,
+		root: root,
+		repo: sync.OnceValues(func() (*gogit.Repository, error) {
+			return gogit.PlainOpen(root)
+		}),
+		ids:   []string{callbacks.StartCheckpoint},
+		snaps: []map[string]fileVersion{{}},
+	}
+}
+
+func (c *worktreeCheckpoints) create(context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	repo, err := c.repo()
+	if err != nil {
+		return "", err
+	}
+	status, err := c.wt.Status()
+	if err != nil {
+		return "", fmt.Errorf("worktree status: %w", err)
+	}
+	snap := make(map[string]fileVersion, len(status))
+	for path := range status {
+		v, data, ok, err := c.read(path)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		if v.exists {
+			if v.hash, err = stashBlob(repo, data); err != nil {
+				return "", fmt.Errorf("stashing %s: %w", path, err)
+			}
+		}
+		snap[path] = v
+	}
+	c.seq++
+	id := "cp-" + strconv.Itoa(c.seq)
+	c.ids = append(c.ids, id)
+	c.snaps = append(c.snaps, snap)
+	return id, nil
+}
+
+func (c *worktreeCheckpoints) diff(_ context.Context, id string, offset int64, limit int) (callbacks.CheckpointDiffResult, error) {
+	if offset < 0 {
+		return callbacks.CheckpointDiffResult{}, fmt.Errorf("offset must be >= 0, got %d", offset)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var changes []callbacks.FileChange
+	err := c.changed(id, func(path string, want fileVersion, repo *gogit.Repository) error {
+		old, err := c.load(repo, want)
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", path, err)
+		}
+		cur, data, _, err := c.read(path)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, callbacks.FileChange{
+			Path: path,
+			Old:  old,
+			New:  callbacks.FileVersion{Exists: cur.exists, Data: data, Mode: cur.mode()},
+		})
+		return nil
+	})
+	if err != nil {
+		return callbacks.CheckpointDiffResult{}, err
+	}
+	return callbacks.DiffChanges(changes, offset, limit), nil
+}
+
+func (c *worktreeCheckpoints) restore(_ context.Context, id string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var restored []string
+	err := c.changed(id, func(path string, want fileVersion, repo *gogit.Repository) error {
+		v, err := c.load(repo, want)
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", path, err)
+		}
+		if err := c.write(path, v); err != nil {
+			return fmt.Errorf("restoring %s: %w", path, err)
+		}
+		restored = append(restored, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Drop the checkpoints taken after the one restored.
+	g := slices.Index(c.ids, id)
+	c.ids, c.snaps = c.ids[:g+1], c.snaps[:g+1]
+	slices.Sort(restored)
+	return restored, nil
+}
+
+// changed calls fn for every path whose current version differs from its
+// version at checkpoint id, passing the latter. Only the paths in the
+// checkpoint and those differing from the index now can differ. The caller
+// holds c.mu.
+func (c *worktreeCheckpoints) changed(id string, fn func(path string, want fileVersion, repo *gogit.Repository) error) error {
+	g := slices.Index(c.ids, id)
+	if g < 0 {
+		return fmt.Errorf("unknown checkpoint %q", id)
+	}
+	repo, err := c.repo()
+	if err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("reading index: %w", err)
+	}
+	status, err := c.wt.Status()
+	if err != nil {
+		return fmt.Errorf("worktree status: %w", err)
+	}
+
+	snap := c.snaps[g]
+	paths := make([]string, 0, len(snap)+len(status))
+	for path := range snap {
+		paths = append(paths, path)
+	}
+	for path := range status {
+		if _, ok := snap[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		want, ok := snap[path]
+		if !ok {
+			if want, err = indexVersion(idx, path); err != nil {
+				return err
+			}
+		}
+		cur, _, ok, err := c.read(path)
+		if err != nil {
+			return err
+		}
+		if !ok || cur == want {
+			continue
+		}
+		if err := fn(path, want, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read returns the current version of path and its content. It reports
+// false for directories, which are not versioned.
+func (c *worktreeCheckpoints) read(path string) (fileVersion, []byte, bool, error) {
+	fullPath, err := validatePath(c.root, path)
+	if err != nil {
+		return fileVersion{}, nil, false, err
+	}
+	fi, err := os.Lstat(fullPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fileVersion{}, nil, true, nil
+	case err != nil:
+		return fileVersion{}, nil, false, err
+	case fi.IsDir():
+		return fileVersion{}, nil, false, nil
+	}
+	var data []byte
+	v := fileVersion{exists: true}
+	if fi.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(fullPath)
+		if err != nil {
+			return fileVersion{}, nil, false, err
+		}
+		data, v.symlink = []byte(target), true
+	} else {
+		if data, err = os.ReadFile(fullPath); err != nil {
+			return fileVersion{}, nil, false, err
+		}
+		v.exec = fi.Mode().Perm()&0o111 != 0
+	}
+	v.hash = plumbing.ComputeHash(plumbing.BlobObject, data)
+	return v, data, true, nil
+}
+
+// load fetches the content of v from the object store.
+func (c *worktreeCheckpoints) load(repo *gogit.Repository, v fileVersion) (callbacks.FileVersion, error) {
+	if !v.exists {
+		return callbacks.FileVersion{}, nil
+	}
+	blob, err := repo.BlobObject(v.hash)
+	if err != nil {
+		return callbacks.FileVersion{}, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return callbacks.FileVersion{}, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return callbacks.FileVersion{}, err
+	}
+	return callbacks.FileVersion{Exists: true, Data: data, Mode: v.mode()}, nil
+}
+
+// write puts v at path, the way a checkout would. Like the other callbacks it
+// leaves the index alone.
+func (c *worktreeCheckpoints) write(path string, v callbacks.FileVersion) error {
+	fullPath, err := validatePath(c.root, path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if !v.Exists {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	if v.Mode&fs.ModeSymlink != 0 {
+		return os.Symlink(string(v.Data), fullPath)
+	}
+	return os.WriteFile(fullPath, v.Data, v.Mode.Perm())
+}
+
+// mode is the file mode git checks v out with.
+func (v fileVersion) mode() fs.FileMode {
+	switch {
+	case v.symlink:
+		return fs.ModeSymlink | 0o777
+	case v.exec:
+		return 0o755
+	default:
+		return 0o644
+	}
+}
+
+// indexVersion returns the version of path the index records.
+func indexVersion(idx *index.Index, path string) (fileVersion, error) {
+	e, err := idx.Entry(filepath.ToSlash(path))
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return fileVersion{}, nil
+	}
+	if err != nil {
+		return fileVersion{}, fmt.Errorf("index entry %s: %w", path, err)
+	}
+	return fileVersion{
+		exists:  true,
+		symlink: e.Mode == filemode.Symlink,
+		exec:    e.Mode == filemode.Executable,
+		hash:    e.Hash,
+	}, nil
+}
+
+// stashBlob stores data in the object store as a loose blob. Nothing
+// references it, so git gc eventually prunes it.
+func stashBlob(repo *gogit.Repository, data []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(data)))
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clonemanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestCheckpoints(t *testing.T) {
+	wt, root := initWorktree(t)
+	cb := WorktreeCallbacks(wt)
+	ctx := context.Background()
+
+	if err := cb.WriteFile(ctx, "hello.txt", "Hello, checkpoint!", 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cp, err := cb.CreateCheckpoint(ctx)
+	if err != nil {
+		t.Fatalf("create checkpoint: %v", err)
+	}
+
+	// Changes after the checkpoint, including one made behind the callbacks'
+	// back the way a command would.
+	if _, err := cb.EditFile(ctx, "script.sh", "echo hi", "echo bye", false); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if err := cb.DeleteFile(ctx, "subdir/nested.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	writeTestFile(t, root, "artifact.out", "built\n", 0o644)
+
+	diff, err := cb.DiffSinceCheckpoint(ctx, cp, 0, -1)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if want := []string{"artifact.out", "script.sh", "subdir/nested.txt"}; !slices.Equal(diff.Files, want) {
+		t.Errorf("files since %s: got = %q, wanted = %q", cp, diff.Files, want)
+	}
+	if !strings.Contains(diff.Diff, "-echo hi\n+echo bye\n") {
+		t.Errorf("diff since %s: got = %q, wanted the script edit", cp, diff.Diff)
+	}
+
+	restored, err := cb.RestoreCheckpoint(ctx, cp)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if want := []string{"artifact.out", "script.sh", "subdir/nested.txt"}; !slices.Equal(restored, want) {
+		t.Errorf("restored: got = %q, wanted = %q", restored, want)
+	}
+	for path, want := range map[string]string{
+		"hello.txt":         "Hello, checkpoint!",
+		"script.sh":         "#!/bin/sh\necho hi\n",
+		"subdir/nested.txt": "nested content here",
+	} {
+		got, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil || string(got) != want {
+			t.Errorf("%s after restore: got = %q, %v, wanted = %q", path, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "artifact.out")); !os.IsNotExist(err) {
+		t.Errorf("artifact.out after restore: got err = %v, wanted it removed", err)
+	}
+	if fi, err := os.Stat(filepath.Join(root, "script.sh")); err != nil || fi.Mode().Perm()&0o111 == 0 {
+		t.Errorf("script.sh after restore: got = %v, %v, wanted it executable", fi, err)
+	}
+
+	// The start is the index, which the callbacks never write: restoring it
+	// leaves a clean worktree.
+	if _, err := cb.RestoreCheckpoint(ctx, callbacks.StartCheckpoint); err != nil {
+		t.Fatalf("restore start: %v", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !status.IsClean() {
+		t.Errorf("status after restoring the start: got = %v, wanted clean", status)
+	}
+	if _, err := cb.DiffSinceCheckpoint(ctx, "cp-missing", 0, -1); err == nil {
+		t.Error("diff since an unknown checkpoint: got = nil, wanted an error")
+	}
+}
@@ -10,6 +10,8 @@ SPDX-License-Identifier: Apache-2.0
 //   - Report metadata about the prepared commit and whether the target path exists.
 //   - Offer MakeAndPushChanges, a high-level helper that accepts a callback to
 //     apply updates, create a signed commit, and force-push a branch.
+//   - Provide history callbacks (HistoryCallbacks) for agent tools, deepening
+//     the shallow clone on demand when blame or log need older commits.
 //
 // Callers typically acquire a lease per reconciliation, operate on the prepared
 // working tree through the provided callback, and finally Return the lease to
@@ -0,0 +1,35 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clonemanager
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// ExecCallbacks creates callbacks.ExecCallbacks that run the allow-listed
+// commands of cfg inside a git worktree, confined as callbacks.LocalExec
+// describes. Working directories are scoped to the worktree root.
+//
+// Commands share the worktree with the WorktreeCallbacks, and everything
+// they leave in it is committed by MakeAndPushChanges (see
+// WorktreeCallbacks): point build caches and outputs outside the worktree
+// through cfg.Env (for example GOCACHE), or have the agent clean up after
+// itself. Do not allow-list git: the lease owns the repository state.
+//
+// The callbacks hold the worktree root open; close the returned io.Closer
+// once the agent is done with them, before the lease is returned.
+func ExecCallbacks(wt *gogit.Worktree, cfg callbacks.ExecConfig) (callbacks.ExecCallbacks, io.Closer, error) {
+	root, err := os.OpenRoot(wt.Filesystem.Root())
+	if err != nil {
+		return callbacks.ExecCallbacks{}, nil, fmt.Errorf("opening worktree root: %w", err)
+	}
+	return callbacks.LocalExec(root, cfg), root, nil
+}
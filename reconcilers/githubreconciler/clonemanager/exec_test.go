@@ -0,0 +1,45 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clonemanager
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+)
+
+func TestExecCallbacks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	wt, _ := initWorktree(t)
+	cb, closer, err := ExecCallbacks(wt, callbacks.ExecConfig{Commands: []string{"sh"}})
+	if err != nil {
+		t.Fatalf("ExecCallbacks: %v", err)
+	}
+
+	res, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "cat nested.txt"}, "subdir", 0, -1)
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if res.ExitCode != 0 || strings.TrimSpace(res.Output) != "nested content here" {
+		t.Errorf("result: got = %+v, want the nested file's content", res)
+	}
+
+	if _, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "true"}, "..", 0, -1); err == nil {
+		t.Error("RunCommand outside the worktree: got = nil, want an error")
+	}
+
+	// Closing releases the worktree root the callbacks hold.
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := cb.RunCommand(t.Context(), "sh", []string{"-c", "true"}, "", 0, -1); err == nil {
+		t.Error("RunCommand after Close: got = nil, want an error")
+	}
+}
@@ -19,8 +19,16 @@ import (
 )
 
 // HistoryCallbacks creates callbacks.HistoryCallbacks bound to a git repository,
-// showing changes between baseCommit and the current HEAD.
-func HistoryCallbacks(repo *gogit.Repository, baseCommit plumbing.Hash) callbacks.HistoryCallbacks {
+// showing changes between baseCommit and the current HEAD. Blame, Log and
+// ShowFile reach past baseCommit into whatever history the repository holds;
+// pass WithDeepen to let them fetch more of it from a shallow clone.
+func HistoryCallbacks(repo *gogit.Repository, baseCommit plumbing.Hash, opts ...HistoryOption) callbacks.HistoryCallbacks {
+	var o historyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r := &revisions{repo: repo, deepen: o.deepen}
+
 	return callbacks.HistoryCallbacks{
 		ListCommits: func(ctx context.Context, offset, limit int) (callbacks.CommitListResult, error) {
 			all, err := collectCommits(repo, baseCommit)
@@ -79,6 +87,9 @@ func HistoryCallbacks(repo *gogit.Repository, baseCommit plumbing.Hash) callback
 
 			return paginateDiff(buf.String(), offset, limit), nil
 		},
+		Blame:    r.blame,
+		Log:      r.log,
+		ShowFile: r.showFile,
 	}
 }
 
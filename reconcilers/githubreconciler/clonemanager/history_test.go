@@ -15,9 +15,11 @@ import (
 	"testing"
 	"time"
 
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
 )
 
 // testSig returns a deterministic commit signature for testing.
@@ -1127,3 +1129,243 @@ func TestCollectCommitsWithMerge(t *testing.T) {
 }
 
 func int64Ptr(v int64) *int64 { return &v }
+
+// initRevisionRepo creates a repo whose commits, oldest first, are authored
+// an hour apart:
+//
+//	alice "add a.txt"        a.txt = one two three
+//	bob   "fix: add dir/b"   dir/b.txt
+//	bob   "rework a.txt"     a.txt = one TWO three
+//	alice "fix: trim a.txt"  a.txt = one TWO
+//
+// Returns the repo and the commit hashes, newest first.
+func initRevisionRepo(t *testing.T) (*gogit.Repository, []plumbing.Hash) {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var hashes []plumbing.Hash
+	for _, c := range []struct {
+		author, message, path, content string
+	}{
+		{"alice", "add a.txt", "a.txt", "one\ntwo\nthree\n"},
+		{"bob", "fix: add dir/b", "dir/b.txt", "b\n"},
+		{"bob", "rework a.txt", "a.txt", "one\nTWO\nthree\n"},
+		{"alice", "fix: trim a.txt", "a.txt", "one\nTWO\n"},
+	} {
+		writeTestFile(t, dir, c.path, c.content, 0o644)
+		if _, err := wt.Add(c.path); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: c.author, Email: c.author + "@test", When: when}
+		h, err := wt.Commit(c.message, &gogit.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append([]plumbing.Hash{h}, hashes...)
+		when = when.Add(time.Hour)
+	}
+	return repo, hashes
+}
+
+func TestBlame(t *testing.T) {
+	repo, hashes := initRevisionRepo(t)
+	cb := HistoryCallbacks(repo, hashes[0])
+	ctx := context.Background()
+
+	got, err := cb.Blame(ctx, "a.txt", "", 1, 1)
+	if err != nil {
+		t.Fatalf("Blame: %v", err)
+	}
+	if got.TotalLines != 2 || len(got.Lines) != 1 || got.NextLine == nil || *got.NextLine != 2 {
+		t.Fatalf("first page: got = %+v, wanted line 1 of 2 with next line 2", got)
+	}
+	if l := got.Lines[0]; l.Line != 1 || l.Text != "one" || l.SHA != hashes[3].String()[:7] || l.Author != "alice" {
+		t.Errorf("line 1: got = %+v, wanted \"one\" from alice's first commit", l)
+	}
+
+	got, err = cb.Blame(ctx, "a.txt", "", 2, 10)
+	if err != nil {
+		t.Fatalf("Blame: %v", err)
+	}
+	if len(got.Lines) != 1 || got.NextLine != nil {
+		t.Fatalf("second page: got = %+v, wanted the last line", got)
+	}
+	if l := got.Lines[0]; l.Text != "TWO" || l.SHA != hashes[1].String()[:7] || l.Author != "bob" {
+		t.Errorf("line 2: got = %+v, wanted \"TWO\" from bob's rework", l)
+	}
+
+	// At an older revision, the file still has its original lines.
+	got, err = cb.Blame(ctx, "a.txt", hashes[2].String()[:7], 2, 2)
+	if err != nil {
+		t.Fatalf("Blame at revision: %v", err)
+	}
+	if len(got.Lines) != 2 || got.Lines[0].Text != "two" || got.Lines[1].Text != "three" || got.TotalLines != 3 {
+		t.Errorf("blame at revision: got = %+v, wanted lines two and three", got)
+	}
+
+	if _, err := cb.Blame(ctx, "a.txt", "", 0, 10); err == nil {
+		t.Error("start line 0: got = nil, wanted an error")
+	}
+	if _, err := cb.Blame(ctx, "missing.txt", "", 1, 10); err == nil {
+		t.Error("missing file: got = nil, wanted an error")
+	}
+}
+
+func TestLog(t *testing.T) {
+	repo, hashes := initRevisionRepo(t)
+	cb := HistoryCallbacks(repo, hashes[0])
+	ctx := context.Background()
+
+	shas := func(entries []callbacks.LogEntry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.SHA)
+		}
+		return out
+	}
+	short := func(idx ...int) []string {
+		var out []string
+		for _, i := range idx {
+			out = append(out, hashes[i].String()[:7])
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		name string
+		q    callbacks.LogQuery
+		want []string
+	}{
+		{"all", callbacks.LogQuery{}, short(0, 1, 2, 3)},
+		{"file", callbacks.LogQuery{Path: "a.txt"}, short(0, 1, 3)},
+		{"directory", callbacks.LogQuery{Path: "/dir/"}, short(2)},
+		{"message", callbacks.LogQuery{MessagePattern: "^fix:"}, short(0, 2)},
+		{"path and message", callbacks.LogQuery{Path: "a.txt", MessagePattern: "(?i)REWORK"}, short(1)},
+		{"revision", callbacks.LogQuery{Rev: hashes[1].String()[:7], Path: "a.txt"}, short(1, 3)},
+		{"no match", callbacks.LogQuery{Path: "nope.txt"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cb.Log(ctx, tc.q, 0, 10)
+			if err != nil {
+				t.Fatalf("Log: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, shas(got.Commits)); diff != "" {
+				t.Errorf("commits (-want, +got):\n%s", diff)
+			}
+			if got.NextOffset != nil || got.Truncated {
+				t.Errorf("result: got = %+v, wanted a single complete page", got)
+			}
+		})
+	}
+
+	// Pagination.
+	first, err := cb.Log(ctx, callbacks.LogQuery{Path: "a.txt"}, 0, 2)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if diff := cmp.Diff(short(0, 1), shas(first.Commits)); diff != "" || first.NextOffset == nil || *first.NextOffset != 2 {
+		t.Fatalf("first page: got = %+v, wanted two commits and next offset 2 (-want, +got):\n%s", first, diff)
+	}
+	if e := first.Commits[0]; e.Author != "alice" || e.Message != "fix: trim a.txt" || !e.Date.Equal(time.Date(2026, 1, 2, 6, 4, 5, 0, time.UTC)) {
+		t.Errorf("first entry: got = %+v, wanted alice's trim", e)
+	}
+	second, err := cb.Log(ctx, callbacks.LogQuery{Path: "a.txt"}, *first.NextOffset, 2)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if diff := cmp.Diff(short(3), shas(second.Commits)); diff != "" || second.NextOffset != nil {
+		t.Errorf("second page: got = %+v, wanted the last commit (-want, +got):\n%s", second, diff)
+	}
+
+	if _, err := cb.Log(ctx, callbacks.LogQuery{MessagePattern: "("}, 0, 10); err == nil {
+		t.Error("invalid pattern: got = nil, wanted an error")
+	}
+	if _, err := cb.Log(ctx, callbacks.LogQuery{Rev: "no-such-branch"}, 0, 10); err == nil {
+		t.Error("unknown revision: got = nil, wanted an error")
+	}
+}
+
+// TestLogWithMerge verifies that Log walks every parent of a merge, and
+// that a merge which takes a path unchanged from one parent does not count
+// as changing it.
+func TestLogWithMerge(t *testing.T) {
+	repo, baseHash := initMergeRepo(t)
+	cb := HistoryCallbacks(repo, baseHash)
+	ctx := context.Background()
+
+	all, err := cb.Log(ctx, callbacks.LogQuery{}, 0, 10)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(all.Commits) != 5 {
+		t.Errorf("commits: got = %d, wanted all 5 including the side branch", len(all.Commits))
+	}
+
+	side, err := cb.Log(ctx, callbacks.LogQuery{Path: "side.txt"}, 0, 10)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(side.Commits) != 1 || side.Commits[0].Message != "side branch" {
+		t.Errorf("side.txt log: got = %+v, wanted only the side branch commit", side.Commits)
+	}
+}
+
+func TestShowFile(t *testing.T) {
+	repo, hashes := initRevisionRepo(t)
+	cb := HistoryCallbacks(repo, hashes[0])
+	ctx := context.Background()
+
+	got, err := cb.ShowFile(ctx, "a.txt", hashes[3].String()[:7], 0, 8)
+	if err != nil {
+		t.Fatalf("ShowFile: %v", err)
+	}
+	if got.Content != "one\ntwo\n" || got.NextOffset == nil || *got.NextOffset != 8 || got.Remaining != 6 {
+		t.Errorf("first page: got = %+v, wanted 8 of 14 bytes", got)
+	}
+	got, err = cb.ShowFile(ctx, "a.txt", "HEAD~3", 8, -1)
+	if err != nil {
+		t.Fatalf("ShowFile: %v", err)
+	}
+	if got.Content != "three\n" || got.NextOffset != nil || got.Remaining != 0 {
+		t.Errorf("rest: got = %+v, wanted the remaining line", got)
+	}
+
+	got, err = cb.ShowFile(ctx, "a.txt", "", 0, 100)
+	if err != nil {
+		t.Fatalf("ShowFile: %v", err)
+	}
+	if got.Content != "one\nTWO\n" {
+		t.Errorf("HEAD: got = %q, wanted the current content", got.Content)
+	}
+
+	if _, err := cb.ShowFile(ctx, "dir/b.txt", hashes[3].String(), 0, 100); err == nil {
+		t.Error("file added later: got = nil, wanted an error")
+	}
+	if _, err := cb.ShowFile(ctx, "a.txt", "", -1, 100); err == nil {
+		t.Error("negative offset: got = nil, wanted an error")
+	}
+}
+
+func TestPaginateContent(t *testing.T) {
+	// "é" is two bytes; a page must not split it.
+	got := paginateContent("aé", 0, 2)
+	if got.Content != "a" || got.NextOffset == nil || *got.NextOffset != 1 || got.Remaining != 2 {
+		t.Errorf("split rune: got = %+v, wanted \"a\" and next offset 1", got)
+	}
+	if got := paginateContent("abc", 3, 10); got.Content != "" || got.NextOffset != nil {
+		t.Errorf("offset at end: got = %+v, wanted an empty result", got)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
 	"chainguard.dev/driftlessaf/reconcilers/githubreconciler"
 	"github.com/chainguard-dev/clog"
 	"github.com/chainguard-dev/terraform-infra-common/pkg/gitexec/gogit"
@@ -30,6 +31,9 @@ const cloneDirPrefix = "clonemanager-clone-"
 
 const gitFetchDepth = 1
 
+// maxHistoryDepth bounds how deep Lease.Deepen fetches a leased ref's history.
+const maxHistoryDepth = 10000
+
 // ErrNothingToCommit is returned by MakeAndPushChanges when the update function
 // runs without error but leaves the working tree clean (i.e., no diff to commit).
 // changemanager.Upsert translates this into changemanager.ErrNoChanges so callers
@@ -117,6 +121,14 @@ type Lease struct {
 	// in sync with the actual clone depth so callers never request more
 	// history than was fetched.
 	baseCommit plumbing.Hash
+
+	// ref is the leased ref, which Deepen refetches.
+	ref string
+
+	// mu guards depth, the number of the ref's commits fetched so far. It
+	// starts at the lease's fetch depth and grows with each Deepen.
+	mu    sync.Mutex
+	depth int
 }
 
 // UpdateFunc receives the prepared working tree for a lease and returns the
@@ -172,7 +184,8 @@ func (m *Manager) Lease(ctx context.Context, res *githubreconciler.Resource) (*L
 // ref and returns a Lease handle. The ref can be a branch name (e.g., "main",
 // "feature-branch") that will be fetched and checked out.
 // By default it fetches with depth 1. Use WithCommitDepth to fetch deeper
-// history for commit walking (e.g., list_commits).
+// history for commit walking (e.g., list_commits). History older than that is
+// fetched lazily: see Lease.Deepen and Lease.HistoryCallbacks.
 // Callers must invoke Return to release the clone back to the pool.
 func (m *Manager) LeaseRef(ctx context.Context, res *githubreconciler.Resource, ref string, opts ...LeaseOption) (*Lease, error) {
 	o := leaseOptions{depth: gitFetchDepth}
@@ -240,6 +253,8 @@ func (m *Manager) leaseRef(ctx context.Context, res *githubreconciler.Resource,
 		sha:        sha,
 		pathExists: exists,
 		baseCommit: baseCommit,
+		ref:        ref,
+		depth:      depth,
 	}, nil
 }
 
@@ -311,36 +326,12 @@ func (m *Manager) prepareClone(ctx context.Context, cl *clone, ref string, res *
 		cl.repo = repo
 	}
 
-	auth, err := m.authForRemote()
-	if err != nil {
-		return "", false, fmt.Errorf("getting token: %w", err)
-	}
-
-	dst := plumbing.NewRemoteReferenceName("origin", ref)
-	fetchURL := repoURL(res)
-	fetchOpts := &git.FetchOptions{
-		RemoteName: "origin",
-		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+%s:%s", resolveRefName(ref), dst))},
-		Auth:       auth,
-		Depth:      depth,
-		Tags:       git.NoTags,
-	}
-
-	// Fetch from the trusted URL (see trustedRemote), never from the clone's
-	// on-disk .git/config: a pooled clone is reused across reconciles and
-	// resetClone does not restore .git/config, so untrusted code that rewrote
-	// [remote "origin"] url in an earlier lease could otherwise redirect this
-	// credentialed fetch.
 	clog.InfoContextf(ctx, "Fetching ref %s", ref)
-	switch err := trustedRemote(repo, fetchURL).FetchContext(ctx, fetchOpts); {
-	case errors.Is(err, git.NoErrAlreadyUpToDate):
-		// No pack transferred; the clone did not grow.
-	case err != nil:
-		return "", false, fmt.Errorf("fetching ref %s: %w", ref, err)
-	default:
-		cl.fetches++
+	if err := m.fetchRef(ctx, cl, ref, repoURL(res), depth); err != nil {
+		return "", false, err
 	}
 
+	dst := plumbing.NewRemoteReferenceName("origin", ref)
 	remoteRef, err := repo.Reference(dst, true)
 	if err != nil {
 		return "", false, fmt.Errorf("getting remote ref %s: %w", ref, err)
@@ -407,6 +398,40 @@ func (m *Manager) prepareClone(ctx context.Context, cl *clone, ref string, res *
 	return remoteRef.Hash().String(), true, nil
 }
 
+// fetchRef fetches ref into the clone's refs/remotes/origin namespace with
+// the given depth. On a shallow clone, a larger depth than it was fetched
+// with deepens its history.
+func (m *Manager) fetchRef(ctx context.Context, cl *clone, ref, fetchURL string, depth int) error {
+	auth, err := m.authForRemote()
+	if err != nil {
+		return fmt.Errorf("getting token: %w", err)
+	}
+
+	dst := plumbing.NewRemoteReferenceName("origin", ref)
+	fetchOpts := &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("+%s:%s", resolveRefName(ref), dst))},
+		Auth:       auth,
+		Depth:      depth,
+		Tags:       git.NoTags,
+	}
+
+	// Fetch from the trusted URL (see trustedRemote), never from the clone's
+	// on-disk .git/config: a pooled clone is reused across reconciles and
+	// resetClone does not restore .git/config, so untrusted code that rewrote
+	// [remote "origin"] url in an earlier lease could otherwise redirect this
+	// credentialed fetch.
+	switch err := trustedRemote(cl.repo, fetchURL).FetchContext(ctx, fetchOpts); {
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		// No pack transferred; the clone did not grow.
+	case err != nil:
+		return fmt.Errorf("fetching ref %s: %w", ref, err)
+	default:
+		cl.fetches++
+	}
+	return nil
+}
+
 func (m *Manager) resetClone(cl *clone) error {
 	worktree, err := cl.repo.Worktree()
 	if err != nil {
@@ -678,6 +703,33 @@ func (l *Lease) BaseCommit() plumbing.Hash {
 	return l.baseCommit
 }
 
+// HistoryCallbacks returns history callbacks for the lease's clone, relative
+// to BaseCommit. Blame, Log and ShowFile deepen the clone with Deepen when
+// they need commits older than it holds.
+func (l *Lease) HistoryCallbacks() callbacks.HistoryCallbacks {
+	return HistoryCallbacks(l.clone.repo, l.baseCommit, WithDeepen(l.Deepen))
+}
+
+// Deepen fetches more of the leased ref's history into the clone, growing
+// the depth it was leased with several-fold, up to maxHistoryDepth commits.
+// It returns an error once that bound is reached. The ref is refetched by
+// name, so if it has moved since the lease was created the history fetched
+// is that of its new tip, which usually shares the leased commit's.
+func (l *Lease) Deepen(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.depth >= maxHistoryDepth {
+		return fmt.Errorf("history of %s already fetched to the maximum depth of %d commits", l.ref, maxHistoryDepth)
+	}
+	depth := min(max(4*l.depth, l.depth+100), maxHistoryDepth)
+	clog.InfoContext(ctx, "Deepening history", "ref", l.ref, "from", l.depth, "to", depth)
+	if err := l.manager.fetchRef(ctx, l.clone, l.ref, l.remoteURL, depth); err != nil {
+		return err
+	}
+	l.depth = depth
+	return nil
+}
+
 // Return resets the working tree and places the clone back into the manager's
 // pool. Clones that have served the manager's fetch bound are discarded
 // instead, so a replacement is cloned fresh on a later lease. Once Return
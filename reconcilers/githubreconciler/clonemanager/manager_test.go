@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
 	"chainguard.dev/driftlessaf/reconcilers/githubreconciler"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -580,6 +581,79 @@ func TestMaxFetchesDiscardsClone(t *testing.T) {
 	_ = lease.Return(ctx)
 }
 
+// TestLeaseHistoryDeepens verifies that a lease's history callbacks fetch
+// older commits on demand rather than stopping at the shallow clone's depth.
+func TestLeaseHistoryDeepens(t *testing.T) {
+	ctx := t.Context()
+
+	mgr, err := New(ctx, staticTokenSource(""), "clonemanager-test", nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	repoDir, initial := initTestRepo(t)
+	for i := range 5 {
+		commitToTestRepo(t, repoDir, fmt.Sprintf("pkg-%d.yaml", i))
+	}
+
+	repoURL = func(*githubreconciler.Resource) string { return repoDir }
+	t.Cleanup(func() { repoURL = defaultRemoteURL })
+
+	lease, err := mgr.LeaseRef(ctx, &githubreconciler.Resource{
+		Owner: "tests",
+		Repo:  repoDir,
+		Type:  githubreconciler.ResourceTypeIssue,
+	}, "master")
+	if err != nil {
+		t.Fatalf("LeaseRef: %v", err)
+	}
+	t.Cleanup(func() { _ = lease.Return(ctx) })
+
+	// Without deepening, the depth-1 clone only knows its tip.
+	shallow := HistoryCallbacks(lease.Repo(), lease.BaseCommit())
+	got, err := shallow.Log(ctx, callbacks.LogQuery{}, 0, 10)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(got.Commits) != 1 || !got.Truncated {
+		t.Errorf("shallow log: got = %+v, wanted only the tip, truncated", got)
+	}
+	if _, err := shallow.Blame(ctx, "packages/foo.yaml", "", 1, 10); err == nil {
+		t.Error("shallow blame: got = nil, wanted an error")
+	}
+
+	cb := lease.HistoryCallbacks()
+	got, err = cb.Log(ctx, callbacks.LogQuery{Path: "packages/foo.yaml"}, 0, 10)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(got.Commits) != 1 || got.Commits[0].SHA != initial[:7] || got.Truncated {
+		t.Errorf("deepened log: got = %+v, wanted the initial commit %s", got, initial[:7])
+	}
+
+	blame, err := cb.Blame(ctx, "packages/foo.yaml", "", 1, 10)
+	if err != nil {
+		t.Fatalf("Blame: %v", err)
+	}
+	if len(blame.Lines) != 1 || blame.Lines[0].SHA != initial[:7] {
+		t.Errorf("blame: got = %+v, wanted the line attributed to %s", blame, initial[:7])
+	}
+
+	file, err := cb.ShowFile(ctx, "packages/foo.yaml", initial[:7], 0, 100)
+	if err != nil {
+		t.Fatalf("ShowFile: %v", err)
+	}
+	if file.Content != "name: foo" {
+		t.Errorf("ShowFile: got = %q, wanted = %q", file.Content, "name: foo")
+	}
+
+	// Deepening stops at maxHistoryDepth.
+	lease.depth = maxHistoryDepth
+	if err := lease.Deepen(ctx); err == nil {
+		t.Error("Deepen past maxHistoryDepth: got = nil, wanted an error")
+	}
+}
+
 // BenchmarkLease measures the cost of a Lease/Return cycle against a real
 // remote repository. It uses golang/go at master as a representative large
 // repository (~15k files, ~400MB). Set GITHUB_TOKEN to run.
@@ -0,0 +1,374 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clonemanager
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+
+	"chainguard.dev/driftlessaf/agents/toolcall/callbacks"
+	"github.com/chainguard-dev/clog"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// HistoryOption configures the callbacks returned by HistoryCallbacks.
+type HistoryOption func(*historyOptions)
+
+type historyOptions struct {
+	deepen func(context.Context) error
+}
+
+// WithDeepen lets Blame, Log and ShowFile fetch older commits when they run
+// into the boundary of a shallow clone, instead of failing or returning a
+// truncated log. deepen is called each time more history is needed and
+// should return an error once it cannot fetch any further; Lease.Deepen
+// does this for a leased clone.
+func WithDeepen(deepen func(context.Context) error) HistoryOption {
+	return func(o *historyOptions) {
+		o.deepen = deepen
+	}
+}
+
+// revisions answers history queries about arbitrary revisions, which unlike
+// ListCommits and GetFileDiff are not bounded by the base commit.
+type revisions struct {
+	repo   *gogit.Repository
+	deepen func(context.Context) error
+}
+
+func (r *revisions) blame(ctx context.Context, p, rev string, startLine, limit int) (callbacks.BlameResult, error) {
+	if startLine < 1 {
+		return callbacks.BlameResult{}, fmt.Errorf("start line must be >= 1, got %d", startLine)
+	}
+	return deepening(ctx, r, func() (callbacks.BlameResult, bool, error) {
+		c, err := r.commit(rev)
+		if err != nil {
+			return callbacks.BlameResult{}, missingHistory(err, rev), err
+		}
+		br, err := gogit.Blame(c, p)
+		if err != nil {
+			return callbacks.BlameResult{}, missingHistory(err, ""), fmt.Errorf("blame %s: %w", p, err)
+		}
+
+		total := len(br.Lines)
+		result := callbacks.BlameResult{TotalLines: total}
+		end := total
+		if limit > 0 {
+			end = min(startLine-1+limit, total)
+		}
+		for i := startLine - 1; i < end; i++ {
+			l := br.Lines[i]
+			result.Lines = append(result.Lines, callbacks.BlameLine{
+				Line:   i + 1,
+				SHA:    l.Hash.String()[:7],
+				Author: l.AuthorName,
+				Date:   l.Date,
+				Text:   l.Text,
+			})
+		}
+		if end < total {
+			next := end + 1
+			result.NextLine = &next
+		}
+		return result, false, nil
+	})
+}
+
+func (r *revisions) log(ctx context.Context, q callbacks.LogQuery, offset, limit int) (callbacks.LogResult, error) {
+	switch {
+	case offset < 0:
+		return callbacks.LogResult{}, fmt.Errorf("offset must be >= 0, got %d", offset)
+	case limit <= 0:
+		return callbacks.LogResult{}, fmt.Errorf("limit must be > 0, got %d", limit)
+	}
+	var re *regexp.Regexp
+	if q.MessagePattern != "" {
+		var err error
+		if re, err = regexp.Compile(q.MessagePattern); err != nil {
+			return callbacks.LogResult{}, fmt.Errorf("invalid message pattern: %w", err)
+		}
+	}
+	p := path.Clean("/" + q.Path)[1:]
+
+	return deepening(ctx, r, func() (callbacks.LogResult, bool, error) {
+		start, err := r.commit(q.Rev)
+		if err != nil {
+			return callbacks.LogResult{}, missingHistory(err, q.Rev), err
+		}
+		// One match past the page tells whether another page follows.
+		matches, truncated, err := r.walk(ctx, start, func(c *object.Commit, parents []*object.Commit) (bool, error) {
+			if re != nil && !re.MatchString(c.Message) {
+				return false, nil
+			}
+			if p == "" {
+				return true, nil
+			}
+			return changesPath(c, parents, p)
+		}, offset+limit+1)
+		if err != nil {
+			return callbacks.LogResult{}, false, err
+		}
+
+		var result callbacks.LogResult
+		if offset < len(matches) {
+			page := matches[offset:min(offset+limit, len(matches))]
+			result.Commits = make([]callbacks.LogEntry, 0, len(page))
+			for _, c := range page {
+				result.Commits = append(result.Commits, callbacks.LogEntry{
+					SHA:     c.Hash.String()[:7],
+					Author:  c.Author.Name,
+					Date:    c.Author.When,
+					Message: c.Message,
+				})
+			}
+		}
+		if len(matches) > offset+limit {
+			next := offset + limit
+			result.NextOffset = &next
+		} else {
+			result.Truncated = truncated
+		}
+		return result, result.Truncated, nil
+	})
+}
+
+func (r *revisions) showFile(ctx context.Context, p, rev string, offset int64, limit int) (callbacks.ReadResult, error) {
+	if offset < 0 {
+		return callbacks.ReadResult{}, fmt.Errorf("offset must be >= 0, got %d", offset)
+	}
+	return deepening(ctx, r, func() (callbacks.ReadResult, bool, error) {
+		c, err := r.commit(rev)
+		if err != nil {
+			return callbacks.ReadResult{}, missingHistory(err, rev), err
+		}
+		f, err := c.File(p)
+		if errors.Is(err, object.ErrFileNotFound) {
+			return callbacks.ReadResult{}, false, fmt.Errorf("file %q does not exist at %s", p, c.Hash.String()[:7])
+		} else if err != nil {
+			return callbacks.ReadResult{}, false, fmt.Errorf("get file %q: %w", p, err)
+		}
+		if binary, err := f.IsBinary(); err != nil {
+			return callbacks.ReadResult{}, missingHistory(err, ""), fmt.Errorf("read file %q: %w", p, err)
+		} else if binary || isBinaryFile(p) {
+			return callbacks.ReadResult{}, false, fmt.Errorf("file %q appears to be binary", p)
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return callbacks.ReadResult{}, missingHistory(err, ""), fmt.Errorf("read file %q: %w", p, err)
+		}
+		return paginateContent(content, offset, limit), false, nil
+	})
+}
+
+// commit resolves rev, or HEAD when rev is empty, to a commit.
+func (r *revisions) commit(rev string) (*object.Commit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", rev, err)
+	}
+	return r.repo.CommitObject(*hash)
+}
+
+// walk visits the commits reachable from start, newest first by committer
+// time, and returns the first max of them that match accepts. match is given
+// the parents of each commit that are present in the clone. truncated
+// reports that the walk reached commits whose parents were not fetched.
+func (r *revisions) walk(ctx context.Context, start *object.Commit, match func(c *object.Commit, parents []*object.Commit) (bool, error), max int) (_ []*object.Commit, truncated bool, _ error) {
+	queue := &commitQueue{start}
+	seen := map[plumbing.Hash]bool{start.Hash: true}
+	var matches []*object.Commit
+	for queue.Len() > 0 && len(matches) < max {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		c := heap.Pop(queue).(*object.Commit)
+		parents := make([]*object.Commit, 0, len(c.ParentHashes))
+		for _, h := range c.ParentHashes {
+			parent, err := r.repo.CommitObject(h)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				truncated = true
+				continue
+			} else if err != nil {
+				return nil, false, fmt.Errorf("get parent of %s: %w", c.Hash.String()[:7], err)
+			}
+			parents = append(parents, parent)
+			if !seen[h] {
+				seen[h] = true
+				heap.Push(queue, parent)
+			}
+		}
+		ok, err := match(c, parents)
+		if err != nil {
+			return nil, false, fmt.Errorf("commit %s: %w", c.Hash.String()[:7], err)
+		}
+		if ok {
+			matches = append(matches, c)
+		}
+	}
+	return matches, truncated, nil
+}
+
+// changesPath reports whether c changed the file or directory at p. Like git
+// log, a merge only counts when it differs from every parent, and a commit
+// without parents counts when it contains p.
+func changesPath(c *object.Commit, parents []*object.Commit, p string) (bool, error) {
+	h, err := entryHash(c, p)
+	if err != nil {
+		return false, err
+	}
+	if len(parents) == 0 {
+		return !h.IsZero(), nil
+	}
+	for _, parent := range parents {
+		ph, err := entryHash(parent, p)
+		if err != nil {
+			return false, err
+		}
+		if ph == h {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// entryHash returns the hash of the tree entry at p in c, or the zero hash
+// when c has no such entry.
+func entryHash(c *object.Commit, p string) (plumbing.Hash, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("get tree: %w", err)
+	}
+	e, err := tree.FindEntry(p)
+	switch {
+	case errors.Is(err, object.ErrEntryNotFound), errors.Is(err, object.ErrDirectoryNotFound):
+		return plumbing.ZeroHash, nil
+	case err != nil:
+		return plumbing.ZeroHash, fmt.Errorf("find %s: %w", p, err)
+	}
+	return e.Hash, nil
+}
+
+// commitQueue is a heap of commits ordered newest first by committer time.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int           { return len(q) }
+func (q commitQueue) Less(i, j int) bool { return q[i].Committer.When.After(q[j].Committer.When) }
+func (q commitQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)        { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// deepening runs fn, which reports whether it needed commits older than the
+// clone holds, deepening the clone and running fn again until it has what it
+// needs. It settles for fn's last result once deepening fails or no longer
+// moves the clone's shallow boundary, noting in fn's error that it ran out of
+// history.
+func deepening[T any](ctx context.Context, r *revisions, fn func() (T, bool, error)) (T, error) {
+	for {
+		result, more, err := fn()
+		if !more {
+			return result, err
+		}
+		before := shallowBoundary(r.repo)
+		if len(before) == 0 {
+			return result, err
+		}
+		if err != nil {
+			err = fmt.Errorf("%w (the clone's history is shallow and may not reach the commits needed)", err)
+		}
+		if r.deepen == nil {
+			return result, err
+		}
+		if derr := r.deepen(ctx); derr != nil {
+			clog.WarnContext(ctx, "Failed to deepen history", "error", derr)
+			return result, err
+		}
+		if slices.Equal(shallowBoundary(r.repo), before) {
+			return result, err
+		}
+	}
+}
+
+// shallowBoundary returns, sorted, the commits of a shallow clone whose
+// parents were not fetched. It is empty once the clone holds the full
+// history. The shallow file alone cannot tell, as go-git adds to it when
+// deepening but never removes commits whose parents have since arrived.
+func shallowBoundary(repo *gogit.Repository) []plumbing.Hash {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil
+	}
+	var boundary []plumbing.Hash
+	for _, h := range shallow {
+		c, err := repo.CommitObject(h)
+		if err != nil {
+			continue
+		}
+		for _, parent := range c.ParentHashes {
+			if repo.Storer.HasEncodedObject(parent) != nil {
+				boundary = append(boundary, h)
+				break
+			}
+		}
+	}
+	slices.SortFunc(boundary, func(a, b plumbing.Hash) int { return bytes.Compare(a[:], b[:]) })
+	return boundary
+}
+
+// hashRevision matches revisions that name a commit by its (abbreviated) hash.
+var hashRevision = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+// missingHistory reports whether err may be cured by fetching more history:
+// either an object the clone lacks was needed, or rev names a commit by a
+// hash the clone does not hold.
+func missingHistory(err error, rev string) bool {
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return true
+	}
+	return errors.Is(err, plumbing.ErrReferenceNotFound) && hashRevision.MatchString(rev)
+}
+
+// paginateContent applies byte offset/limit pagination to file content,
+// without splitting a UTF-8 character. A negative limit returns everything
+// from offset.
+func paginateContent(content string, offset int64, limit int) callbacks.ReadResult {
+	total := int64(len(content))
+	if offset >= total {
+		return callbacks.ReadResult{}
+	}
+	end := total
+	if limit >= 0 {
+		end = min(offset+int64(limit), total)
+	}
+	buf := []byte(content[offset:end])
+	if end < total {
+		buf = adjustUTF8Boundary(buf)
+		end = offset + int64(len(buf))
+	}
+	result := callbacks.ReadResult{
+		Content:   string(buf),
+		Remaining: total - end,
+	}
+	if result.Remaining > 0 {
+		result.NextOffset = &end
+	}
+	return result
+}
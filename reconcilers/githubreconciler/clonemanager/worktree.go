@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -43,8 +44,17 @@ import (
 //     dropped from the commit: the callback succeeds and the file exists on
 //     disk, but `git add -A` skips ignored paths, so the file never reaches
 //     the commit tree.
+//
+// Checkpoints follow the same rule. They are taken against the index, which
+// therefore still holds the checked-out commit and serves as
+// callbacks.StartCheckpoint, and stash changed files as unreferenced blobs in
+// the object store. Since they compare the whole worktree with the index,
+// they also capture and undo changes that commands made, and the diff since
+// the start is what commitChanges is about to commit. Ignored paths are
+// outside them too.
 func WorktreeCallbacks(wt *gogit.Worktree) callbacks.WorktreeCallbacks {
 	root := wt.Filesystem.Root()
+	cp := newWorktreeCheckpoints(wt, root)
 
 	return callbacks.WorktreeCallbacks{
 		ReadFile: func(_ context.Context, path string, offset int64, limit int) (callbacks.ReadResult, error) {
@@ -281,6 +291,16 @@ func WorktreeCallbacks(wt *gogit.Worktree) callbacks.WorktreeCallbacks {
 			return callbacks.EditResult{Replacements: len(offsets)}, nil
 		},
 
+		ApplyPatch: func(_ context.Context, patch string) (callbacks.PatchResult, error) {
+			// Like every other write here, the patch only touches the working
+			// tree; commitChanges stages it with the rest.
+			return callbacks.ApplyUnifiedDiff(worktreePatchTarget{root: root}, patch)
+		},
+
+		CreateCheckpoint:    cp.create,
+		DiffSinceCheckpoint: cp.diff,
+		RestoreCheckpoint:   cp.restore,
+
 		SearchCodebase: func(_ context.Context, searchPath, pattern, filter string, offset, limit int) (callbacks.SearchResult, error) {
 			searchRoot, err := validatePath(root, searchPath)
 			if err != nil {
@@ -384,6 +404,49 @@ func validatePath(root, path string) (string, error) {
 	return fullPath, nil
 }
 
+// worktreePatchTarget is the callbacks.PatchTarget of a worktree, rooted at
+// root and confined to it by validatePath.
+type worktreePatchTarget struct{ root string }
+
+func (t worktreePatchTarget) ReadFile(path string) ([]byte, fs.FileMode, error) {
+	fullPath, err := validatePath(t.root, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := os.Lstat(fullPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, 0, fmt.Errorf("%q is not a regular file", path)
+	}
+	data, err := os.ReadFile(fullPath)
+	return data, fi.Mode().Perm(), err
+}
+
+func (t worktreePatchTarget) WriteFile(path string, data []byte, mode fs.FileMode) error {
+	fullPath, err := validatePath(t.root, path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fullPath, data, mode); err != nil {
+		return err
+	}
+	// os.WriteFile leaves an existing file's mode alone.
+	return os.Chmod(fullPath, mode)
+}
+
+func (t worktreePatchTarget) Remove(path string) error {
+	fullPath, err := validatePath(t.root, path)
+	if err != nil {
+		return err
+	}
+	return os.Remove(fullPath)
+}
+
 // validateSymlinkTarget checks that a symlink target will not escape the
 // worktree root. Absolute targets are always rejected. Relative targets are
 // resolved from the symlink's parent directory to verify they stay within root.
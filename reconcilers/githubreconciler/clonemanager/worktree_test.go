@@ -1420,3 +1420,93 @@ func assertUnstagedChange(t *testing.T, wt *gogit.Worktree, path string) {
 		t.Errorf("path %q staging: got = %v, wanted unstaged (callbacks must not stage)", path, st.Staging)
 	}
 }
+
+func TestApplyPatch(t *testing.T) {
+	wt, root := initWorktree(t)
+	cb := WorktreeCallbacks(wt)
+	ctx := context.Background()
+
+	res, err := cb.ApplyPatch(ctx, `diff --git a/script.sh b/script.sh
+--- a/script.sh
++++ b/script.sh
+@@ -1,2 +1,2 @@
+ #!/bin/sh
+-echo hi
++echo bye
+diff --git a/subdir/added.txt b/subdir/added.txt
+new file mode 100644
+--- /dev/null
++++ b/subdir/added.txt
+@@ -0,0 +1 @@
++added
+`)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if !res.Applied {
+		t.Fatalf("result: got = %+v, want applied", res)
+	}
+
+	got, err := os.ReadFile(filepath.Join(root, "script.sh"))
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if string(got) != "#!/bin/sh\necho bye\n" {
+		t.Errorf("content: got = %q, wanted = %q", got, "#!/bin/sh\necho bye\n")
+	}
+	fi, err := os.Stat(filepath.Join(root, "script.sh"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if fi.Mode().Perm()&0o111 == 0 {
+		t.Error("executable bit lost after patch")
+	}
+
+	// Like the other writes, the patch is left unstaged for commitChanges
+	// (FUL-411).
+	status, err := wt.Status()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if st := status.File("script.sh"); st.Worktree != gogit.Modified || st.Staging != gogit.Unmodified {
+		t.Errorf("script.sh status: got = %+v, wanted an unstaged modification", st)
+	}
+	if st := status.File("subdir/added.txt"); st.Worktree != gogit.Untracked {
+		t.Errorf("subdir/added.txt status: got = %+v, wanted untracked", st)
+	}
+}
+
+func TestApplyPatchFailureChangesNothing(t *testing.T) {
+	wt, root := initWorktree(t)
+	cb := WorktreeCallbacks(wt)
+	ctx := context.Background()
+
+	res, err := cb.ApplyPatch(ctx, `--- a/hello.txt
++++ b/hello.txt
+@@ -1 +1 @@
+-Hello, World!
++Goodbye, World!
+--- a/subdir/nested.txt
++++ b/subdir/nested.txt
+@@ -1 +1 @@
+-something else entirely
++replacement
+`)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if res.Applied || res.Failed() != 1 || res.Files[1].Hunks[0].Error == "" {
+		t.Fatalf("result: got = %+v, want the nested.txt hunk reported and nothing applied", res)
+	}
+	got, err := os.ReadFile(filepath.Join(root, "hello.txt"))
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if string(got) != "Hello, World!" {
+		t.Errorf("hello.txt: got = %q, wanted it unchanged", got)
+	}
+
+	if _, err := cb.ApplyPatch(ctx, "--- /dev/null\n+++ b/../escape.txt\n@@ -0,0 +1 @@\n+x\n"); err == nil {
+		t.Error("patch escaping the worktree: got = nil, wanted an error")
+	}
+}
@@ -86,7 +86,7 @@ SPDX-License-Identifier: Apache-2.0
 //	                toolcall.NewWorktreeTools(toolcall.EmptyTools{}, clonemanager.WorktreeCallbacks(wt)),
 //	                session.FindingCallbacks(),
 //	            ),
-//	            clonemanager.HistoryCallbacks(lease.Repo(), lease.BaseCommit()),
+//	            lease.HistoryCallbacks(),
 //	        ), nil
 //	    },
 //	    metapathreconciler.WithLabels("automated"),
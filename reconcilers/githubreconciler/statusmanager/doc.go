@@ -91,4 +91,18 @@ SPDX-License-Identifier: Apache-2.0
 // Each Check Run includes a details URL that links to Cloud Logging with
 // pre-configured filters for the specific PR and commit SHA, making it easy
 // to view logs for that particular reconciliation run.
+//
+// # Agent Progress
+//
+// NewProgressObserver adapts an agent's progress events into in-progress
+// Check Run updates, so a PR shows which turn and tool a long agent run is on
+// while it works. Updates are published from a goroutine, coalesced to at
+// most one per interval, and flushed as each turn and the run end:
+//
+//	config.Observer = statusmanager.NewProgressObserver(session, 15*time.Second,
+//	    func(p statusmanager.Progress) MyDetails {
+//	        return MyDetails{Summary: p.Title()}
+//	    })
+//
+// Set the final state with SetActualState once the run returns.
 package statusmanager
@@ -0,0 +1,256 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statusmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+)
+
+// DefaultProgressInterval is the minimum time between the check run updates
+// NewProgressObserver publishes when no interval is given.
+const DefaultProgressInterval = 10 * time.Second
+
+// maxProgressText bounds Progress.Text so a verbose turn cannot bloat the
+// check run output.
+const maxProgressText = 1000
+
+// Progress summarizes a running agent for NewProgressObserver's check run
+// updates.
+type Progress struct {
+	// Turn is the zero-based conversation turn in progress.
+	Turn int
+
+	// ActiveTool names the most recently started tool call, or is empty when
+	// the model is generating rather than running a tool.
+	ActiveTool string
+
+	// ToolCalls counts the tool calls completed so far, and FailedToolCalls
+	// the ones among them that returned an error to the model.
+	ToolCalls       int
+	FailedToolCalls int
+
+	// Rejections counts the submissions the result validators rejected.
+	Rejections int
+
+	// Text is the tail of the response text the model has written this turn.
+	Text string
+}
+
+// Title renders p as a short check run title.
+func (p Progress) Title() string {
+	if p.ActiveTool != "" {
+		return fmt.Sprintf("Turn %d: running %s", p.Turn+1, p.ActiveTool)
+	}
+	return fmt.Sprintf("Turn %d: thinking", p.Turn+1)
+}
+
+// stateSetter is the part of Session the progress observer writes through.
+type stateSetter[T any] interface {
+	SetActualState(ctx context.Context, title string, status *Status[T]) error
+}
+
+// NewProgressObserver returns a progress.Observer that publishes an agent
+// run's progress to the session's check run as an "in_progress" status, for
+// metaagent.Config.Observer or an executor's WithObserver option. details
+// builds the status Details from the run's Progress; the title is
+// Progress.Title.
+//
+// Events only update the run's Progress; a goroutine started with the run's
+// first event publishes it, so the executor's stream and tool goroutines
+// never wait on the GitHub API. Updates are coalesced to at most one per
+// interval (DefaultProgressInterval when interval is not positive): the
+// first event publishes at once, and later ones are published together once
+// the interval has passed. The end of a turn publishes the latest Progress
+// without waiting for the interval, and the end of the run publishes it
+// before the executor returns and stops the goroutine, so no update is lost
+// to throttling and none lands after the caller sets the final state. A
+// failed update is logged and never fails the run.
+func NewProgressObserver[T any](session *Session[T], interval time.Duration, details func(Progress) T) progress.Observer {
+	return newProgressObserver[T](session, interval, details)
+}
+
+func newProgressObserver[T any](session stateSetter[T], interval time.Duration, details func(Progress) T) progress.Observer {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	p := &progressPublisher[T]{session: session, interval: interval, details: details}
+	return p.observe
+}
+
+// progressPublisher folds a run's events into its Progress and publishes it
+// from a goroutine that lives from the run's first event to its
+// progress.RunCompleted.
+type progressPublisher[T any] struct {
+	session  stateSetter[T]
+	interval time.Duration
+	details  func(Progress) T
+
+	mu    sync.Mutex
+	state Progress
+	// dirty records that state has changed since it was last published.
+	dirty bool
+	// signals is the running publisher's, or nil when none is running.
+	signals *publishSignals
+}
+
+// publishSignals are the channels the observer wakes its publisher with.
+// wake and flush hold at most one pending signal, so bursts of events
+// coalesce into a single publish.
+type publishSignals struct {
+	wake  chan struct{} // state changed; publish once the interval allows
+	flush chan struct{} // publish now
+	stop  chan struct{} // publish now and exit
+	done  chan struct{} // closed as the publisher exits
+}
+
+func (p *progressPublisher[T]) observe(ctx context.Context, ev progress.Event) {
+	p.mu.Lock()
+	switch ev.Kind {
+	case progress.TurnCompleted, progress.RunCompleted:
+	default:
+		p.state = p.state.apply(ev)
+		p.dirty = true
+	}
+	sig := p.signals
+	if sig == nil && ev.Kind != progress.RunCompleted {
+		sig = &publishSignals{
+			wake:  make(chan struct{}, 1),
+			flush: make(chan struct{}, 1),
+			stop:  make(chan struct{}),
+			done:  make(chan struct{}),
+		}
+		p.signals = sig
+		go p.run(ctx, sig)
+	}
+	if ev.Kind == progress.RunCompleted {
+		// A later run through the same observer starts a new publisher.
+		p.signals = nil
+	}
+	p.mu.Unlock()
+
+	if sig == nil {
+		return
+	}
+	switch ev.Kind {
+	case progress.RunCompleted:
+		close(sig.stop)
+		<-sig.done
+	case progress.TurnCompleted:
+		notify(sig.flush)
+	default:
+		notify(sig.wake)
+	}
+}
+
+// notify leaves a signal on ch unless one is already pending.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// run publishes the Progress as sig requests until the run completes or ctx,
+// the context of the run's first event, is done.
+func (p *progressPublisher[T]) run(ctx context.Context, sig *publishSignals) {
+	defer close(sig.done)
+	var last time.Time
+	for {
+		select {
+		case <-sig.wake:
+			// Hold the update until the interval since the last publish has
+			// passed, unless the turn or run ends first.
+			if wait := p.interval - time.Since(last); !last.IsZero() && wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-sig.flush:
+				case <-sig.stop:
+					timer.Stop()
+					p.publish(ctx)
+					return
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+				timer.Stop()
+			}
+		case <-sig.flush:
+		case <-sig.stop:
+			p.publish(ctx)
+			return
+		case <-ctx.Done():
+			return
+		}
+		if p.publish(ctx) {
+			last = time.Now()
+		}
+	}
+}
+
+// publish sends the latest Progress to the check run if it has changed since
+// the last publish, and reports whether it did.
+func (p *progressPublisher[T]) publish(ctx context.Context) bool {
+	p.mu.Lock()
+	if !p.dirty {
+		p.mu.Unlock()
+		return false
+	}
+	snapshot := p.state
+	p.dirty = false
+	p.mu.Unlock()
+
+	if err := p.session.SetActualState(ctx, snapshot.Title(), &Status[T]{
+		Status:  "in_progress",
+		Details: p.details(snapshot),
+	}); err != nil {
+		clog.WarnContext(ctx, "Failed to publish agent progress", "error", err)
+	}
+	return true
+}
+
+// apply folds ev into p.
+func (p Progress) apply(ev progress.Event) Progress {
+	switch ev.Kind {
+	case progress.TurnStarted:
+		p.Turn, p.ActiveTool, p.Text = ev.Turn, "", ""
+	case progress.TextDelta:
+		p.Text = tail(p.Text+ev.Text, maxProgressText)
+	case progress.ToolCallStarted:
+		p.ActiveTool = ev.ToolName
+	case progress.ToolCallCompleted:
+		p.ToolCalls++
+		if ev.Error != "" {
+			p.FailedToolCalls++
+		}
+		if p.ActiveTool == ev.ToolName {
+			p.ActiveTool = ""
+		}
+	case progress.SubmitRejected:
+		p.Rejections++
+	}
+	return p
+}
+
+// tail returns the last n bytes of s or fewer, starting on a rune boundary.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	for len(s) > 0 && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statusmanager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/driftlessaf/agents/executor/progress"
+)
+
+// recordingSetter captures the states a progress observer publishes.
+type recordingSetter struct {
+	mu     sync.Mutex
+	titles []string
+	states []*Status[Progress]
+	err    error
+}
+
+func (r *recordingSetter) SetActualState(_ context.Context, title string, status *Status[Progress]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.titles = append(r.titles, title)
+	r.states = append(r.states, status)
+	return r.err
+}
+
+// published returns copies of the titles and states published so far.
+func (r *recordingSetter) published() ([]string, []*Status[Progress]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.titles...), append([]*Status[Progress](nil), r.states...)
+}
+
+func identity(p Progress) Progress { return p }
+
+func TestProgressObserverSummarizesRun(t *testing.T) {
+	setter := &recordingSetter{}
+	obs := newProgressObserver[Progress](setter, time.Nanosecond, identity)
+
+	ctx := t.Context()
+	for _, ev := range []progress.Event{
+		{Kind: progress.TurnStarted, Turn: 0},
+		{Kind: progress.TextDelta, Turn: 0, Text: "Reading "},
+		{Kind: progress.TextDelta, Turn: 0, Text: "the config."},
+		{Kind: progress.ToolCallStarted, Turn: 0, ToolName: "read_file"},
+		{Kind: progress.ToolCallCompleted, Turn: 0, ToolName: "read_file", Error: "not found"},
+		{Kind: progress.TurnCompleted, Turn: 0},
+		{Kind: progress.TurnStarted, Turn: 1},
+		{Kind: progress.ToolCallStarted, Turn: 1, ToolName: "submit_result"},
+		{Kind: progress.SubmitRejected, Turn: 1, ToolName: "submit_result"},
+		{Kind: progress.RunCompleted},
+	} {
+		obs.Emit(ctx, ev)
+	}
+
+	// Updates may coalesce, but the run's end publishes its final summary
+	// before Emit returns.
+	titles, states := setter.published()
+	if len(states) == 0 {
+		t.Fatal("updates: got none, want the final summary")
+	}
+	for i, s := range states {
+		if s.Status != "in_progress" {
+			t.Errorf("update %d status: got = %q, want = in_progress", i+1, s.Status)
+		}
+	}
+	if got, want := titles[len(titles)-1], "Turn 2: running submit_result"; got != want {
+		t.Errorf("final title: got = %q, want = %q", got, want)
+	}
+	want := Progress{Turn: 1, ActiveTool: "submit_result", ToolCalls: 1, FailedToolCalls: 1, Rejections: 1}
+	if diff := cmp.Diff(want, states[len(states)-1].Details); diff != "" {
+		t.Errorf("final progress (-want +got):\n%s", diff)
+	}
+
+	// Nothing is published once the run has completed.
+	time.Sleep(10 * time.Millisecond)
+	if _, after := setter.published(); len(after) != len(states) {
+		t.Errorf("updates after the run completed: got = %d, want = 0", len(after)-len(states))
+	}
+}
+
+func TestProgressObserverThrottles(t *testing.T) {
+	setter := &recordingSetter{err: errors.New("rate limited")}
+	obs := newProgressObserver[Progress](setter, time.Hour, identity)
+
+	ctx := t.Context()
+	obs.Emit(ctx, progress.Event{Kind: progress.TurnStarted, Turn: 0})
+	for range 10 {
+		obs.Emit(ctx, progress.Event{Kind: progress.ToolCallCompleted, ToolName: "read_file"})
+	}
+	obs.Emit(ctx, progress.Event{Kind: progress.RunCompleted})
+
+	// The first event publishes at once (a failed update is only logged);
+	// the events inside the interval are held and published together when
+	// the run completes.
+	titles, states := setter.published()
+	if len(states) > 2 {
+		t.Fatalf("updates: got = %d, want at most 2", len(states))
+	}
+	if got := titles[0]; got != "Turn 1: thinking" {
+		t.Errorf("first title: got = %q, want = %q", got, "Turn 1: thinking")
+	}
+	if got := states[len(states)-1].Details.ToolCalls; got != 10 {
+		t.Errorf("final tool calls: got = %d, want = 10", got)
+	}
+}
+
+func TestProgressObserverFlushesOnTurnEnd(t *testing.T) {
+	setter := &recordingSetter{}
+	obs := newProgressObserver[Progress](setter, time.Hour, identity)
+
+	ctx := t.Context()
+	for _, ev := range []progress.Event{
+		{Kind: progress.TurnStarted, Turn: 0},
+		{Kind: progress.TextDelta, Turn: 0, Text: "Reading the config."},
+		{Kind: progress.TurnCompleted, Turn: 0},
+	} {
+		obs.Emit(ctx, ev)
+	}
+	defer obs.Emit(ctx, progress.Event{Kind: progress.RunCompleted})
+
+	// The turn's end publishes its text without waiting out the interval.
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		_, states := setter.published()
+		if n := len(states); n > 0 && states[n-1].Details.Text == "Reading the config." {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("updates: got = %d, want one with the turn's text", len(states))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestProgressTextKeepsTail(t *testing.T) {
+	var p Progress
+	p = p.apply(progress.Event{Kind: progress.TextDelta, Text: strings.Repeat("a", maxProgressText)})
+	p = p.apply(progress.Event{Kind: progress.TextDelta, Text: "é!"})
+	if len(p.Text) > maxProgressText || !strings.HasSuffix(p.Text, "é!") {
+		t.Errorf("text: got %d bytes ending %q, want at most %d ending in the latest delta",
+			len(p.Text), p.Text[len(p.Text)-3:], maxProgressText)
+	}
+}
@@ -33,6 +33,15 @@ func (c *exampleClient) Process(_ context.Context, req *workqueue.ProcessRequest
 	return &workqueue.ProcessResponse{}, nil
 }
 
+func (c *exampleClient) ProcessBatch(ctx context.Context, req *workqueue.ProcessBatchRequest, _ ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	resp := &workqueue.ProcessBatchResponse{}
+	for _, r := range req.GetRequests() {
+		_, err := c.Process(ctx, r)
+		resp.Results = append(resp.Results, workqueue.NewProcessBatchResult(r.GetKey(), err))
+	}
+	return resp, nil
+}
+
 func (c *exampleClient) Close() error { return nil }
 
 func Example() {
@@ -11,6 +11,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"chainguard.dev/driftlessaf/workqueue"
@@ -27,14 +28,21 @@ type options struct {
 	priority    int64
 	now         func() time.Time
 	concurrency int
+	batchSize   int
 }
 
-// WithConcurrency bounds the number of in-flight workqueue enqueues per
+// WithConcurrency bounds the number of in-flight workqueue enqueue calls per
 // Process call. n must be positive. Default is 32.
 func WithConcurrency(n int) Option {
 	return func(o *options) { o.concurrency = n }
 }
 
+// WithBatchSize sets how many keys Process enqueues per ProcessBatch call.
+// n must be positive. Default is workqueue.MaxProcessBatchSize.
+func WithBatchSize(n int) Option {
+	return func(o *options) { o.batchSize = n }
+}
+
 // WithPriority sets the workqueue priority stamped on every enqueue this
 // Sharder produces. The default (zero) is the lowest priority; producers
 // whose work should outrank others (or be outranked) set an explicit
@@ -65,6 +73,7 @@ type Sharder struct {
 	tick        time.Duration
 	wq          workqueue.Client
 	concurrency int
+	batchSize   int
 	priority    int64
 }
 
@@ -89,6 +98,7 @@ func New(tick time.Duration, wq workqueue.Client, opts ...Option) (*Sharder, err
 	o := options{
 		now:         time.Now,
 		concurrency: defaultConcurrency,
+		batchSize:   workqueue.MaxProcessBatchSize,
 	}
 
 	for _, opt := range opts {
@@ -100,12 +110,16 @@ func New(tick time.Duration, wq workqueue.Client, opts ...Option) (*Sharder, err
 	if o.concurrency <= 0 {
 		return nil, fmt.Errorf("resync: concurrency must be positive, got %d", o.concurrency)
 	}
+	if o.batchSize <= 0 {
+		return nil, fmt.Errorf("resync: batch size must be positive, got %d", o.batchSize)
+	}
 	return &Sharder{
 		now:         o.now,
 		start:       o.now(),
 		tick:        tick,
 		wq:          wq,
 		concurrency: o.concurrency,
+		batchSize:   o.batchSize,
 		priority:    o.priority,
 	}, nil
 }
@@ -122,8 +136,9 @@ func New(tick time.Duration, wq workqueue.Client, opts ...Option) (*Sharder, err
 // so the per-key minute-of-period is stable within a period and reshuffles
 // between periods.
 //
-// Process fans out workqueue enqueues up to the Sharder's concurrency limit;
-// the first error cancels the remaining enqueues and is returned.
+// Process enqueues the in-shard keys in batches of the Sharder's batch size
+// (see workqueue.EnqueueBatch), fanning the batches out up to its concurrency
+// limit; the first error cancels the remaining enqueues and is returned.
 func (s *Sharder) Process(ctx context.Context, period time.Duration, keys map[string]struct{}) error {
 	if period <= 0 {
 		return fmt.Errorf("resync: period must be positive, got %v", period)
@@ -139,37 +154,67 @@ func (s *Sharder) Process(ctx context.Context, period time.Duration, keys map[st
 	periodMinutes := uint64(period / time.Minute)
 	periodSalt := uint64(periodStart.Unix()) //nolint:gosec // G115: Unix() is interpreted as bit pattern; sign is irrelevant for hashing.
 
-	eg, egCtx := errgroup.WithContext(ctx)
-	eg.SetLimit(s.concurrency)
+	var shard []scheduled
 	for key := range keys {
 		keyMin := int64(bucket(periodSalt, key, periodMinutes)) //nolint:gosec // G115: bucket result is in [0, periodMinutes), bounded.
 		if keyMin < shardStartMin || keyMin >= shardStartMin+tickMinutes {
 			continue
 		}
-		target := s.start.Add(time.Duration(keyMin-shardStartMin) * time.Minute)
+		shard = append(shard, scheduled{
+			key:    key,
+			target: s.start.Add(time.Duration(keyMin-shardStartMin) * time.Minute),
+		})
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(s.concurrency)
+	for batch := range slices.Chunk(shard, s.batchSize) {
 		eg.Go(func() error {
-			// Compute DelaySeconds against now() at the moment of enqueue,
-			// not against start. Tree walks, App enumeration, and workqueue
-			// RPCs can add minutes of latency between New and this point;
-			// using start.Add(delay) directly would push every key forward
-			// by that latency and cause drift across cycles. Anchoring on
-			// the absolute target instant (start + intra-shard offset) and
-			// subtracting the latest now() lets the latency subtract from
-			// the wait instead of accumulating onto it.
-			if _, err := s.wq.Process(egCtx, &workqueue.ProcessRequest{
-				Key:          key,
-				Priority:     s.priority,
-				DelaySeconds: max(0, int64(target.Sub(s.now()).Seconds())),
-			}); err != nil {
-				return fmt.Errorf("enqueue %q: %w", key, err)
-			}
-			clog.InfoContextf(egCtx, "enqueued %q at %s", key, target.Format(time.RFC3339))
-			return nil
+			return s.enqueue(egCtx, batch)
 		})
 	}
 	return eg.Wait()
 }
 
+// scheduled is an in-shard key and the instant it should be processed at.
+type scheduled struct {
+	key    string
+	target time.Time
+}
+
+// enqueue enqueues batch onto the workqueue, returning the first per-key
+// failure.
+func (s *Sharder) enqueue(ctx context.Context, batch []scheduled) error {
+	// Compute DelaySeconds against now() at the moment of enqueue, not
+	// against start. Tree walks, App enumeration, and workqueue RPCs can add
+	// minutes of latency between New and this point; using start.Add(delay)
+	// directly would push every key forward by that latency and cause drift
+	// across cycles. Anchoring on the absolute target instant (start +
+	// intra-shard offset) and subtracting the latest now() lets the latency
+	// subtract from the wait instead of accumulating onto it.
+	now := s.now()
+	reqs := make([]*workqueue.ProcessRequest, 0, len(batch))
+	for _, sk := range batch {
+		reqs = append(reqs, &workqueue.ProcessRequest{
+			Key:          sk.key,
+			Priority:     s.priority,
+			DelaySeconds: max(0, int64(sk.target.Sub(now).Seconds())),
+		})
+	}
+
+	results, err := workqueue.EnqueueBatch(ctx, s.wq, reqs)
+	if err != nil {
+		return fmt.Errorf("enqueue batch of %d keys: %w", len(reqs), err)
+	}
+	for i, r := range results {
+		if err := r.Err(); err != nil {
+			return fmt.Errorf("enqueue %q: %w", r.GetKey(), err)
+		}
+		clog.InfoContextf(ctx, "enqueued %q at %s", r.GetKey(), batch[i].target.Format(time.RFC3339))
+	}
+	return nil
+}
+
 // bucket assigns key a deterministic minute-of-period in [0, periodMinutes),
 // salted by periodSalt so the assignment reshuffles between periods.
 func bucket(periodSalt uint64, key string, periodMinutes uint64) uint64 {
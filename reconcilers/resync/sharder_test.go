@@ -19,6 +19,8 @@ import (
 	"chainguard.dev/driftlessaf/reconcilers/resync"
 	"chainguard.dev/driftlessaf/workqueue"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // staticNow returns a clock function that always reports t. Suitable for the
@@ -47,14 +49,34 @@ func (c *fakeClock) Advance(d time.Duration) {
 	c.t = c.t.Add(d)
 }
 
-// fakeWQ records each (key, delay) it observes via Process. Optionally
-// returns processErr from every Process call.
+// fakeWQ records each (key, delay) it observes via Process or ProcessBatch.
+// Optionally returns processErr from every call. When legacy is set,
+// ProcessBatch answers Unimplemented like a server that predates it.
 type fakeWQ struct {
 	workqueue.WorkqueueServiceClient
 
 	mu         sync.Mutex
 	enqueued   map[string]int64
+	batches    int
 	processErr error
+	legacy     bool
+}
+
+func (f *fakeWQ) ProcessBatch(ctx context.Context, req *workqueue.ProcessBatchRequest, _ ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	if f.legacy {
+		return nil, status.Error(codes.Unimplemented, "unknown method ProcessBatch")
+	}
+	f.mu.Lock()
+	f.batches++
+	f.mu.Unlock()
+	resp := &workqueue.ProcessBatchResponse{}
+	for _, r := range req.GetRequests() {
+		if _, err := f.Process(ctx, r); err != nil {
+			return nil, err
+		}
+		resp.Results = append(resp.Results, workqueue.NewProcessBatchResult(r.GetKey(), nil))
+	}
+	return resp, nil
 }
 
 func (f *fakeWQ) Process(_ context.Context, req *workqueue.ProcessRequest, _ ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
@@ -131,6 +153,17 @@ func TestNewValidation(t *testing.T) {
 		tick: time.Hour,
 		wq:   &fakeWQ{},
 		opts: []resync.Option{resync.WithNow(now), resync.WithConcurrency(8)},
+	}, {
+		name:    "batch size zero",
+		tick:    time.Hour,
+		wq:      &fakeWQ{},
+		opts:    []resync.Option{resync.WithNow(now), resync.WithBatchSize(0)},
+		wantErr: true,
+	}, {
+		name: "batch size positive",
+		tick: time.Hour,
+		wq:   &fakeWQ{},
+		opts: []resync.Option{resync.WithNow(now), resync.WithBatchSize(100)},
 	}}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -320,6 +353,42 @@ func TestProcessErrorPropagation(t *testing.T) {
 	}
 }
 
+// TestProcessBatching asserts that in-shard keys are sent in ProcessBatch
+// calls of the configured size, and that servers without ProcessBatch still
+// receive every key via Process. We use period == tick so every key falls in
+// the single shard.
+func TestProcessBatching(t *testing.T) {
+	keys := make(map[string]struct{}, 25)
+	for i := range 25 {
+		keys[fmt.Sprintf("k-%d", i)] = struct{}{}
+	}
+
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy=%t", legacy), func(t *testing.T) {
+			fake := &fakeWQ{legacy: legacy}
+			sh, err := resync.New(time.Hour, fake,
+				resync.WithNow(staticNow(time.Unix(1700000000, 0))),
+				resync.WithBatchSize(10))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if err := sh.Process(t.Context(), time.Hour, keys); err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			if len(fake.enqueued) != len(keys) {
+				t.Errorf("enqueued: got = %d, want = %d", len(fake.enqueued), len(keys))
+			}
+			want := 3
+			if legacy {
+				want = 0
+			}
+			if fake.batches != want {
+				t.Errorf("ProcessBatch calls: got = %d, want = %d", fake.batches, want)
+			}
+		})
+	}
+}
+
 // TestProcessEmpty asserts that an empty keyset is handled cleanly.
 func TestProcessEmpty(t *testing.T) {
 	fake := &fakeWQ{}
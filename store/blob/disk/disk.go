@@ -0,0 +1,452 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package disk
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"chainguard.dev/driftlessaf/store/blob"
+)
+
+const (
+	// objectsDir holds one file per object, named by escapeName.
+	objectsDir = "objects"
+
+	// lockFile is the cross-process lock serializing writes to the store.
+	lockFile = ".lock"
+
+	// tempPrefix marks in-flight writes in objectsDir. escapeName never
+	// produces a leading '.', so temp files can't collide with objects.
+	tempPrefix = ".tmp-"
+
+	// headerSize is the length of the big-endian generation header that
+	// precedes every object's body.
+	headerSize = 8
+
+	// maxFileName is NAME_MAX on common filesystems. Objects whose escaped
+	// name is longer are stored under hashedPrefix plus the SHA-256 of the
+	// name, with the name itself recorded after the header so List can
+	// recover it. escapeName never produces '#', so the two can't collide.
+	maxFileName  = 255
+	hashedPrefix = "#"
+
+	// listBatch is how many directory entries List reads at a time.
+	listBatch = 256
+)
+
+// Store is a local-disk blob store rooted at a single directory. The zero value
+// is not usable; call New. It is safe for concurrent use, including by several
+// processes on the same host sharing the directory.
+type Store struct {
+	root string
+
+	// mu serializes writers within this process; the file lock serializes
+	// them across processes.
+	mu sync.Mutex
+}
+
+// New returns a Store rooted at dir, creating the directory if needed.
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, objectsDir), 0o750); err != nil {
+		return nil, fmt.Errorf("blob disk %q: %w", dir, err)
+	}
+	return &Store{root: dir}, nil
+}
+
+// Put writes data at name subject to cond, returning the object's new
+// generation. A failed precondition (DoesNotExist against an existing object,
+// or a stale GenerationMatch) is reported as blob.ErrPreconditionFailed.
+func (s *Store) Put(ctx context.Context, name string, data []byte, cond blob.Cond) (blob.Gen, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	path, hashed, err := s.path(name)
+	if err != nil {
+		return 0, err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, fmt.Errorf("blob put %q: %w", name, err)
+	}
+	defer unlock()
+
+	cur, exists, err := readGen(path)
+	if err != nil {
+		return 0, fmt.Errorf("blob put %q: %w", name, err)
+	}
+	// DoesNotExist takes precedence over GenerationMatch, matching blob.Cond.
+	switch {
+	case cond.DoesNotExist:
+		if exists {
+			return 0, fmt.Errorf("blob put %q: %w", name, blob.ErrPreconditionFailed)
+		}
+	case cond.GenerationMatch != 0:
+		if !exists || cur != cond.GenerationMatch {
+			return 0, fmt.Errorf("blob put %q: %w", name, blob.ErrPreconditionFailed)
+		}
+	}
+
+	// Generations are wall-clock nanoseconds, bumped past the current one so
+	// they strictly advance even if the clock steps backwards. Using the clock
+	// (rather than a per-object counter) keeps a delete-then-recreate from
+	// reissuing a generation captured before the delete.
+	gen := max(blob.Gen(time.Now().UnixNano()), cur+1)
+	var record []byte
+	if hashed {
+		record = nameRecord(name)
+	}
+	if err := s.writeFile(path, gen, record, data); err != nil {
+		return 0, fmt.Errorf("blob put %q: %w", name, err)
+	}
+	return gen, nil
+}
+
+// Get returns the object's bytes and generation; ok is false (nil data, zero
+// Gen, nil error) when no object exists at name.
+func (s *Store) Get(ctx context.Context, name string) ([]byte, blob.Gen, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, false, err
+	}
+	path, hashed, err := s.path(name)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	// Writers replace files by rename, so an unlocked read sees either the
+	// previous or the new object in full, never a mix.
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, 0, false, nil
+	case err != nil:
+		return nil, 0, false, fmt.Errorf("blob get %q: %w", name, err)
+	case len(raw) < headerSize:
+		return nil, 0, false, fmt.Errorf("blob get %q: truncated object (%d bytes)", name, len(raw))
+	}
+	body := raw[headerSize:]
+	if hashed {
+		stored, rest, err := splitNameRecord(body)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("blob get %q: %w", name, err)
+		}
+		if stored != name {
+			return nil, 0, false, fmt.Errorf("blob get %q: file holds %q", name, stored)
+		}
+		body = rest
+	}
+	return body, blob.Gen(binary.BigEndian.Uint64(raw[:headerSize])), true, nil //nolint:gosec // generations are positive nanosecond stamps
+}
+
+// Delete removes name. With ifGen == 0 the delete is unconditional; with
+// ifGen > 0 it removes the object only if its generation matches. An
+// unconditional delete of an absent object is reported as blob.ErrNotExist; a
+// generation-conditional delete against an absent object or a differing
+// generation is reported as blob.ErrPreconditionFailed.
+func (s *Store) Delete(ctx context.Context, name string, ifGen blob.Gen) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, _, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return fmt.Errorf("blob delete %q: %w", name, err)
+	}
+	defer unlock()
+
+	cur, exists, err := readGen(path)
+	if err != nil {
+		return fmt.Errorf("blob delete %q: %w", name, err)
+	}
+	switch {
+	case !exists && ifGen == 0:
+		return fmt.Errorf("blob delete %q: %w", name, blob.ErrNotExist)
+	case !exists, ifGen != 0 && cur != ifGen:
+		return fmt.Errorf("blob delete %q: %w", name, blob.ErrPreconditionFailed)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("blob delete %q: %w", name, err)
+	}
+	return nil
+}
+
+// defaultListLimit caps a List that does not set a positive limit, so a listing
+// over a wide prefix never returns an unbounded slice.
+const defaultListLimit = 100
+
+// List returns a bounded, name-ordered Page of the objects whose name has the
+// given prefix. It caps at limit (or defaultListLimit when limit <= 0) and sets
+// Page.Cursor when more objects remain; pass that cursor back for the next page.
+// Bodies are not returned; fetch them with Get.
+func (s *Store) List(ctx context.Context, prefix string, limit int, cursor string) (blob.Page, error) {
+	if err := ctx.Err(); err != nil {
+		return blob.Page{}, err
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	dir, err := os.Open(filepath.Join(s.root, objectsDir))
+	if err != nil {
+		return blob.Page{}, fmt.Errorf("blob list %q: %w", prefix, err)
+	}
+	defer dir.Close()
+
+	// Directory order is arbitrary, so every entry is visited, but only the
+	// first limit+1 matching names are kept: one past the page tells whether
+	// another page follows, and memory stays bounded by the page rather than
+	// the store.
+	var found []listEntry
+	for {
+		entries, err := dir.ReadDir(listBatch)
+		for _, e := range entries {
+			le, ok, lerr := s.listEntry(e)
+			if lerr != nil {
+				return blob.Page{}, fmt.Errorf("blob list %q: %w", prefix, lerr)
+			}
+			if !ok || !strings.HasPrefix(le.name, prefix) || le.name <= cursor {
+				continue
+			}
+			if len(found) > limit && le.name >= found[limit].name {
+				continue
+			}
+			i, _ := slices.BinarySearchFunc(found, le.name, func(e listEntry, name string) int {
+				return strings.Compare(e.name, name)
+			})
+			found = slices.Insert(found, i, le)
+			if len(found) > limit+1 {
+				found = found[:limit+1]
+			}
+		}
+		if errors.Is(err, io.EOF) || (err == nil && len(entries) == 0) {
+			break
+		} else if err != nil {
+			return blob.Page{}, fmt.Errorf("blob list %q: %w", prefix, err)
+		}
+	}
+
+	page := blob.Page{Objects: make([]blob.Object, 0, min(limit, len(found)))}
+	if len(found) > limit {
+		// Resume after the last name considered here, even if that object
+		// has since been deleted.
+		page.Cursor = found[limit-1].name
+		found = found[:limit]
+	}
+	for _, le := range found {
+		gen := le.gen
+		if !le.hashed {
+			var ok bool
+			gen, ok, err = readGen(filepath.Join(s.root, objectsDir, le.file))
+			if err != nil {
+				return blob.Page{}, fmt.Errorf("blob list %q: %w", prefix, err)
+			}
+			if !ok {
+				// Deleted between the directory read and now.
+				continue
+			}
+		}
+		page.Objects = append(page.Objects, blob.Object{Name: le.name, Gen: gen})
+	}
+	return page, nil
+}
+
+// listEntry is an object file found by List.
+type listEntry struct {
+	name, file string
+	hashed     bool
+	// gen is only read up front for hashed files, whose name is stored
+	// alongside it.
+	gen blob.Gen
+}
+
+// listEntry maps a directory entry onto the object it holds; ok is false for
+// entries that are not objects, including hashed objects deleted since the
+// directory was read.
+func (s *Store) listEntry(e os.DirEntry) (listEntry, bool, error) {
+	file := e.Name()
+	switch {
+	case e.IsDir(), strings.HasPrefix(file, "."):
+		return listEntry{}, false, nil
+	case strings.HasPrefix(file, hashedPrefix):
+		gen, name, ok, err := readNameRecord(filepath.Join(s.root, objectsDir, file))
+		if err != nil || !ok {
+			return listEntry{}, false, err
+		}
+		return listEntry{name: name, file: file, hashed: true, gen: gen}, true, nil
+	}
+	name, err := url.PathUnescape(file)
+	if err != nil {
+		// Not one of ours; leave foreign files alone.
+		return listEntry{}, false, nil
+	}
+	return listEntry{name: name, file: file}, true, nil
+}
+
+// path returns the file holding name and whether it is a hashed file,
+// rejecting the empty name (which has no file representation).
+func (s *Store) path(name string) (string, bool, error) {
+	if name == "" {
+		return "", false, errors.New("blob disk: empty object name")
+	}
+	file, hashed := fileName(name)
+	return filepath.Join(s.root, objectsDir, file), hashed, nil
+}
+
+// lock serializes a read-modify-write against every other writer of the store,
+// in this process and in others, returning the matching unlock.
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	f, err := os.OpenFile(filepath.Join(s.root, lockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if err := lockFD(f); err != nil {
+		_ = f.Close()
+		s.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFD(f)
+		_ = f.Close()
+		s.mu.Unlock()
+	}, nil
+}
+
+// writeFile atomically replaces path with gen's header followed by record and
+// data: the bytes are written and synced to a temp file in the same
+// directory, which is then renamed over path.
+func (s *Store) writeFile(path string, gen blob.Gen, record, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	// Best-effort cleanup; after a successful rename the temp name is gone.
+	defer os.Remove(tmp.Name())
+
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(gen)) //nolint:gosec // generations are positive nanosecond stamps
+	if _, err := tmp.Write(header[:]); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(record); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readGen returns the generation recorded in path's header; ok is false when
+// the file does not exist.
+func readGen(path string) (blob.Gen, bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	var header [headerSize]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return 0, false, fmt.Errorf("reading header of %s: %w", filepath.Base(path), err)
+	}
+	return blob.Gen(binary.BigEndian.Uint64(header[:])), true, nil //nolint:gosec // generations are positive nanosecond stamps
+}
+
+// readNameRecord returns the generation and object name recorded at the head
+// of the hashed file path; ok is false when the file does not exist.
+func readNameRecord(path string) (blob.Gen, string, bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, "", false, nil
+	} else if err != nil {
+		return 0, "", false, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, "", false, fmt.Errorf("reading header of %s: %w", filepath.Base(path), err)
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, "", false, fmt.Errorf("reading name of %s: %w", filepath.Base(path), err)
+	}
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return 0, "", false, fmt.Errorf("reading name of %s: %w", filepath.Base(path), err)
+	}
+	return blob.Gen(binary.BigEndian.Uint64(header[:])), string(name), true, nil //nolint:gosec // generations are positive nanosecond stamps
+}
+
+// nameRecord encodes name as the length-prefixed record that follows the
+// header of a hashed file.
+func nameRecord(name string) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(name))), name...)
+}
+
+// splitNameRecord splits the body of a hashed file into the stored object
+// name and the object's data.
+func splitNameRecord(body []byte) (string, []byte, error) {
+	n, k := binary.Uvarint(body)
+	if k <= 0 || uint64(len(body)-k) < n {
+		return "", nil, errors.New("truncated name record")
+	}
+	return string(body[k : k+int(n)]), body[k+int(n):], nil //nolint:gosec // n is bounded by len(body)
+}
+
+// fileName returns the file name for the object name, hashing names whose
+// escaped form exceeds maxFileName.
+func fileName(name string) (string, bool) {
+	if escaped := escapeName(name); len(escaped) <= maxFileName {
+		return escaped, false
+	}
+	sum := sha256.Sum256([]byte(name))
+	return hashedPrefix + hex.EncodeToString(sum[:]), true
+}
+
+// escapeName maps an object name onto a single file name. PathEscape encodes
+// '/', so hierarchical names stay flat (and "a" and "a/b" can coexist); a
+// leading '.' is encoded too, so no object collides with "." / ".." or with
+// the store's own dot-files.
+func escapeName(name string) string {
+	escaped := url.PathEscape(name)
+	if strings.HasPrefix(escaped, ".") {
+		escaped = "%2E" + escaped[1:]
+	}
+	return escaped
+}
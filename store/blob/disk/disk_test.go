@@ -0,0 +1,153 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package disk_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/store/blob/blobtest"
+	"chainguard.dev/driftlessaf/store/blob/disk"
+)
+
+// TestDisk_Conformance drives the disk backend through the shared contract
+// suite, so it cannot drift from Mem and the GCS backend.
+func TestDisk_Conformance(t *testing.T) {
+	dir := t.TempDir()
+	blobtest.RunConformance(t, func() blobtest.Store {
+		s, err := disk.New(dir)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return s
+	})
+}
+
+// TestDisk_SharedDirectory pins that two Stores over one directory see each
+// other's writes and honor each other's generations, which is what lets
+// separate processes on a host share a store.
+func TestDisk_SharedDirectory(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	a, err := disk.New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b, err := disk.New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	gen, err := a.Put(ctx, "queued/org/repo", []byte("v1"), blob.Cond{DoesNotExist: true})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := b.Put(ctx, "queued/org/repo", []byte("dup"), blob.Cond{DoesNotExist: true}); !errors.Is(err, blob.ErrPreconditionFailed) {
+		t.Fatalf("Put(create, existing via other store): got %v, want ErrPreconditionFailed", err)
+	}
+	if _, err := b.Put(ctx, "queued/org/repo", []byte("v2"), blob.Cond{GenerationMatch: gen}); err != nil {
+		t.Fatalf("Put(match gen via other store): %v", err)
+	}
+	if err := a.Delete(ctx, "queued/org/repo", gen); !errors.Is(err, blob.ErrPreconditionFailed) {
+		t.Fatalf("Delete(stale gen): got %v, want ErrPreconditionFailed", err)
+	}
+	got, _, ok, err := a.Get(ctx, "queued/org/repo")
+	if err != nil || !ok || !bytes.Equal(got, []byte("v2")) {
+		t.Fatalf("Get: got %q ok=%t err=%v, want %q", got, ok, err, "v2")
+	}
+}
+
+// TestDisk_NameEscaping pins that hierarchical and dot-leading names map onto
+// distinct files and round-trip through List unchanged.
+func TestDisk_NameEscaping(t *testing.T) {
+	ctx := t.Context()
+	s, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	names := []string{"..", ".hidden", "a", "a/b", "a/b/c", "with space%"}
+	for _, name := range names {
+		if _, err := s.Put(ctx, name, []byte(name), blob.Cond{DoesNotExist: true}); err != nil {
+			t.Fatalf("Put(%q): %v", name, err)
+		}
+	}
+
+	page, err := s.List(ctx, "", 0, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page.Objects) != len(names) {
+		t.Fatalf("List: got %d objects, want %d: %+v", len(page.Objects), len(names), page.Objects)
+	}
+	for i, o := range page.Objects {
+		if o.Name != names[i] {
+			t.Errorf("List[%d]: got %q, want %q", i, o.Name, names[i])
+		}
+		got, _, ok, err := s.Get(ctx, o.Name)
+		if err != nil || !ok || string(got) != o.Name {
+			t.Errorf("Get(%q): got %q ok=%t err=%v", o.Name, got, ok, err)
+		}
+	}
+
+	if _, err := s.Put(ctx, "", nil, blob.Cond{}); err == nil {
+		t.Error("Put(empty name): got nil error, want failure")
+	}
+}
+
+// TestDisk_LongNames pins that names whose escaped form exceeds NAME_MAX are
+// stored under a hashed file name yet still round-trip through Get, List and
+// Delete, ordered among the ordinary names.
+func TestDisk_LongNames(t *testing.T) {
+	ctx := t.Context()
+	s, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	long := "queued/" + strings.Repeat("a/", 200)
+	names := []string{"queued/0", long + "x", long + "y", "queued/z"}
+	for _, name := range names {
+		if _, err := s.Put(ctx, name, []byte(name), blob.Cond{DoesNotExist: true}); err != nil {
+			t.Fatalf("Put(%q): %v", name, err)
+		}
+	}
+	for _, name := range names {
+		got, _, ok, err := s.Get(ctx, name)
+		if err != nil || !ok || string(got) != name {
+			t.Errorf("Get(%q): got %q ok=%t err=%v", name, got, ok, err)
+		}
+	}
+
+	var listed []string
+	cursor := ""
+	for {
+		page, err := s.List(ctx, "queued/", 1, cursor)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, o := range page.Objects {
+			listed = append(listed, o.Name)
+		}
+		if cursor = page.Cursor; cursor == "" {
+			break
+		}
+	}
+	if !slices.Equal(listed, names) {
+		t.Errorf("List: got %q, want %q", listed, names)
+	}
+
+	if err := s.Delete(ctx, long+"x", 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, ok, err := s.Get(ctx, long+"x"); err != nil || ok {
+		t.Errorf("Get(deleted): got ok=%t err=%v, want absent", ok, err)
+	}
+}
@@ -0,0 +1,20 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package disk is a local-filesystem backend for the blob content store.
+//
+// [Store] implements the blob backend method set — Put, Get, Delete, List —
+// over a single directory, for single-host deployments and local development
+// where object storage is unavailable. Each object is one file whose name is
+// the escaped object name, or a hash of it when the escaped name would exceed
+// the filesystem's name limit; writes land via write-to-temp-then-rename, so a
+// reader never observes a torn body. Every file carries its generation in a
+// fixed header, and compare-and-swap writes and conditional deletes are
+// serialized by a lock on the directory, so several processes on one host can
+// share a store safely.
+//
+// It returns a concrete *Store; consumers declare the small interface they need
+// (see the blobtest package's Store for the full method set).
+package disk
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/store/blob/disk"
+)
+
+// ExampleNew demonstrates a disk-backed store: a create-only Put, a rejected
+// duplicate, and a prefix List.
+func ExampleNew() {
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "blob-disk-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := disk.New(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err := store.Put(ctx, "notes/run_1/fix_plan", []byte("the plan"), blob.Cond{DoesNotExist: true}); err != nil {
+		panic(err)
+	}
+	if _, err := store.Put(ctx, "notes/run_1/fix_plan", []byte("again"), blob.Cond{DoesNotExist: true}); errors.Is(err, blob.ErrPreconditionFailed) {
+		fmt.Println("already stored")
+	}
+
+	page, err := store.List(ctx, "notes/", 0, "")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("listed:", len(page.Objects), page.Objects[0].Name)
+
+	// Output:
+	// already stored
+	// listed: 1 notes/run_1/fix_plan
+}
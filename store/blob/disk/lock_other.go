@@ -0,0 +1,18 @@
+//go:build !unix
+
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package disk
+
+import "os"
+
+// lockFD is a no-op where advisory file locks are unavailable: writers are
+// still serialized within a process by Store.mu, but a directory must not be
+// shared across processes on these platforms.
+func lockFD(*os.File) error { return nil }
+
+// unlockFD is the no-op counterpart of lockFD.
+func unlockFD(*os.File) error { return nil }
@@ -0,0 +1,23 @@
+//go:build unix
+
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package disk
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFD takes an exclusive advisory lock on f, blocking until it is granted.
+func lockFD(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX) //nolint:gosec // file descriptors fit in an int
+}
+
+// unlockFD releases the lock taken by lockFD.
+func unlockFD(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:gosec // file descriptors fit in an int
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Admin is implemented by workqueues whose dead-letter queue can be managed.
+// Check for it with a type assertion on an Interface.
+type Admin interface {
+	// ReplayDeadLetter moves a dead-lettered key back into the queue, as if
+	// queued with opts, and resets its attempt count so it gets the full
+	// retry budget again. When opts.Priority is zero the key keeps the
+	// priority it was dead-lettered with. If the key was re-queued since it
+	// was dead-lettered, the two entries merge as a duplicate Queue would.
+	// It returns a NotFound status error when the key is not dead-lettered.
+	ReplayDeadLetter(ctx context.Context, key string, opts Options) error
+
+	// PurgeDeadLetter permanently removes a dead-lettered key. It returns a
+	// NotFound status error when the key is not dead-lettered.
+	PurgeDeadLetter(ctx context.Context, key string) error
+}
+
+// DeadLetterFilter selects dead-lettered keys for ReplayDeadLetters and
+// PurgeDeadLetters. The zero value matches every dead-lettered key.
+type DeadLetterFilter struct {
+	// Prefix, when set, matches only keys with this prefix.
+	Prefix string
+
+	// FailedAfter, when set, matches only keys dead-lettered at or after it.
+	FailedAfter time.Time
+
+	// FailedBefore, when set, matches only keys dead-lettered before it.
+	FailedBefore time.Time
+}
+
+// Matches reports whether k is selected by the filter.
+func (f DeadLetterFilter) Matches(k DeadLetteredKey) bool {
+	if !strings.HasPrefix(k.Name(), f.Prefix) {
+		return false
+	}
+	failed := k.GetFailedTime()
+	if !f.FailedAfter.IsZero() && failed.Before(f.FailedAfter) {
+		return false
+	}
+	if !f.FailedBefore.IsZero() && !failed.Before(f.FailedBefore) {
+		return false
+	}
+	return true
+}
+
+// AdminInterface is a workqueue that also manages its dead-letter queue.
+type AdminInterface interface {
+	Interface
+	Admin
+}
+
+// ReplayDeadLetters replays every dead-lettered key of wq selected by filter
+// (see Admin.ReplayDeadLetter), returning the keys it replayed. Keys that
+// leave the dead-letter queue concurrently are skipped. On error, the keys
+// replayed so far are returned alongside it.
+func ReplayDeadLetters(ctx context.Context, wq AdminInterface, filter DeadLetterFilter, opts Options) ([]string, error) {
+	return forEachDeadLetter(ctx, wq, filter, func(key string) error {
+		return wq.ReplayDeadLetter(ctx, key, opts)
+	})
+}
+
+// PurgeDeadLetters permanently removes every dead-lettered key of wq selected
+// by filter, returning the keys it removed. Keys that leave the dead-letter
+// queue concurrently are skipped. On error, the keys purged so far are
+// returned alongside it.
+func PurgeDeadLetters(ctx context.Context, wq AdminInterface, filter DeadLetterFilter) ([]string, error) {
+	return forEachDeadLetter(ctx, wq, filter, func(key string) error {
+		return wq.PurgeDeadLetter(ctx, key)
+	})
+}
+
+func forEachDeadLetter(ctx context.Context, wq Interface, filter DeadLetterFilter, f func(key string) error) ([]string, error) {
+	_, _, dl, err := wq.Enumerate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Enumerate() = %w", err)
+	}
+	var done []string
+	for _, k := range dl {
+		if !filter.Matches(k) {
+			continue
+		}
+		if err := f(k.Name()); err != nil {
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return done, fmt.Errorf("key %q: %w", k.Name(), err)
+		}
+		done = append(done, k.Name())
+	}
+	return done, nil
+}
+
+// filter translates the wire selector into a DeadLetterFilter.
+func (x *DeadLetterSelector) filter() DeadLetterFilter {
+	f := DeadLetterFilter{Prefix: x.GetPrefix()}
+	if s := x.GetFailedAfter(); s != 0 {
+		f.FailedAfter = time.Unix(s, 0)
+	}
+	if s := x.GetFailedBefore(); s != 0 {
+		f.FailedBefore = time.Unix(s, 0)
+	}
+	return f
+}
+
+// ServeReplayDeadLetters implements the ReplayDeadLetters RPC over wq, so a
+// WorkqueueServiceServer fronting a workqueue can delegate to it. It fails
+// with Unimplemented when wq does not implement Admin.
+func ServeReplayDeadLetters(ctx context.Context, wq Interface, req *ReplayDeadLettersRequest) (*DeadLettersResponse, error) {
+	awq, ok := wq.(AdminInterface)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "workqueue does not support dead-letter management")
+	}
+	opts := Options{Priority: req.GetPriority()}
+	if req.GetDelaySeconds() > 0 {
+		opts.NotBefore = time.Now().UTC().Add(time.Duration(req.GetDelaySeconds()) * time.Second)
+	}
+	if key := req.GetSelector().GetKey(); key != "" {
+		if err := awq.ReplayDeadLetter(ctx, key, opts); err != nil {
+			return nil, err
+		}
+		return &DeadLettersResponse{Keys: []string{key}}, nil
+	}
+	keys, err := ReplayDeadLetters(ctx, awq, req.GetSelector().filter(), opts)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "replayed %d keys before failing: %v", len(keys), err)
+	}
+	return &DeadLettersResponse{Keys: keys}, nil
+}
+
+// ServePurgeDeadLetters implements the PurgeDeadLetters RPC over wq, so a
+// WorkqueueServiceServer fronting a workqueue can delegate to it. It fails
+// with Unimplemented when wq does not implement Admin.
+func ServePurgeDeadLetters(ctx context.Context, wq Interface, req *PurgeDeadLettersRequest) (*DeadLettersResponse, error) {
+	awq, ok := wq.(AdminInterface)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "workqueue does not support dead-letter management")
+	}
+	if key := req.GetSelector().GetKey(); key != "" {
+		if err := awq.PurgeDeadLetter(ctx, key); err != nil {
+			return nil, err
+		}
+		return &DeadLettersResponse{Keys: []string{key}}, nil
+	}
+	keys, err := PurgeDeadLetters(ctx, awq, req.GetSelector().filter())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "purged %d keys before failing: %v", len(keys), err)
+	}
+	return &DeadLettersResponse{Keys: keys}, nil
+}
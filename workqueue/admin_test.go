@@ -0,0 +1,165 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeDeadLetter struct {
+	workqueue.DeadLetteredKey
+	name   string
+	failed time.Time
+}
+
+func (f fakeDeadLetter) Name() string             { return f.name }
+func (f fakeDeadLetter) GetFailedTime() time.Time { return f.failed }
+
+func TestDeadLetterFilterMatches(t *testing.T) {
+	now := time.Now()
+	key := fakeDeadLetter{name: "repo/foo", failed: now}
+
+	tests := []struct {
+		name   string
+		filter workqueue.DeadLetterFilter
+		want   bool
+	}{{
+		name: "zero value matches everything",
+		want: true,
+	}, {
+		name:   "matching prefix",
+		filter: workqueue.DeadLetterFilter{Prefix: "repo/"},
+		want:   true,
+	}, {
+		name:   "other prefix",
+		filter: workqueue.DeadLetterFilter{Prefix: "other/"},
+		want:   false,
+	}, {
+		name:   "failed after is inclusive",
+		filter: workqueue.DeadLetterFilter{FailedAfter: now},
+		want:   true,
+	}, {
+		name:   "failed after later time",
+		filter: workqueue.DeadLetterFilter{FailedAfter: now.Add(time.Second)},
+		want:   false,
+	}, {
+		name:   "failed before is exclusive",
+		filter: workqueue.DeadLetterFilter{FailedBefore: now},
+		want:   false,
+	}, {
+		name:   "within window",
+		filter: workqueue.DeadLetterFilter{FailedAfter: now.Add(-time.Hour), FailedBefore: now.Add(time.Hour)},
+		want:   true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(key); got != tt.want {
+				t.Errorf("Matches() = %v, wanted %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// notAdmin hides the Admin methods of the workqueue it wraps.
+type notAdmin struct {
+	workqueue.Interface
+}
+
+func TestServeDeadLetters_unimplemented(t *testing.T) {
+	wq := notAdmin{inmem.NewWorkQueue(5)}
+
+	if _, err := workqueue.ServeReplayDeadLetters(t.Context(), wq, &workqueue.ReplayDeadLettersRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ServeReplayDeadLetters() = %v, wanted Unimplemented", err)
+	}
+	if _, err := workqueue.ServePurgeDeadLetters(t.Context(), wq, &workqueue.PurgeDeadLettersRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ServePurgeDeadLetters() = %v, wanted Unimplemented", err)
+	}
+}
+
+func TestServeDeadLetters(t *testing.T) {
+	ctx := t.Context()
+	wq := inmem.NewWorkQueue(5)
+	for _, key := range []string{"a-1", "a-2", "b-1"} {
+		deadletter(ctx, t, wq, key)
+	}
+
+	resp, err := workqueue.ServeReplayDeadLetters(ctx, wq, &workqueue.ReplayDeadLettersRequest{
+		Selector: &workqueue.DeadLetterSelector{Key: "b-1"},
+		Priority: 7,
+	})
+	if err != nil {
+		t.Fatalf("ServeReplayDeadLetters() = %v", err)
+	}
+	if diff := cmp.Diff([]string{"b-1"}, resp.GetKeys()); diff != "" {
+		t.Errorf("ServeReplayDeadLetters() keys (-want, +got):\n%s", diff)
+	}
+	state, err := wq.Get(ctx, "b-1")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if state.GetStatus() != workqueue.KeyState_QUEUED || state.GetPriority() != 7 {
+		t.Errorf("Get() = %v, wanted queued with priority 7", state)
+	}
+
+	// A missing key surfaces NotFound.
+	if _, err := workqueue.ServeReplayDeadLetters(ctx, wq, &workqueue.ReplayDeadLettersRequest{
+		Selector: &workqueue.DeadLetterSelector{Key: "b-1"},
+	}); status.Code(err) != codes.NotFound {
+		t.Errorf("ServeReplayDeadLetters() = %v, wanted NotFound", err)
+	}
+
+	resp, err = workqueue.ServePurgeDeadLetters(ctx, wq, &workqueue.PurgeDeadLettersRequest{
+		Selector: &workqueue.DeadLetterSelector{Prefix: "a-"},
+	})
+	if err != nil {
+		t.Fatalf("ServePurgeDeadLetters() = %v", err)
+	}
+	keys := resp.GetKeys()
+	slices.Sort(keys)
+	if diff := cmp.Diff([]string{"a-1", "a-2"}, keys); diff != "" {
+		t.Errorf("ServePurgeDeadLetters() keys (-want, +got):\n%s", diff)
+	}
+	if _, _, dl, err := wq.Enumerate(ctx); err != nil {
+		t.Fatalf("Enumerate() = %v", err)
+	} else if len(dl) != 0 {
+		t.Errorf("Enumerate() dead letters = %d, wanted 0", len(dl))
+	}
+}
+
+func deadletter(ctx context.Context, t *testing.T, wq workqueue.Interface, key string) {
+	t.Helper()
+	if err := wq.Queue(ctx, key, workqueue.Options{}); err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+	_, qd, _, err := wq.Enumerate(ctx)
+	if err != nil {
+		t.Fatalf("Enumerate() = %v", err)
+	}
+	for _, k := range qd {
+		if k.Name() != key {
+			continue
+		}
+		owned, err := k.Start(ctx)
+		if err != nil {
+			t.Fatalf("Start() = %v", err)
+		}
+		if err := owned.Deadletter(ctx); err != nil {
+			t.Fatalf("Deadletter() = %v", err)
+		}
+		return
+	}
+	t.Fatalf("key %q not queued", key)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxProcessBatchSize bounds how many keys EnqueueBatch sends in a single
+// ProcessBatch call; larger batches are split. It is a variable so binaries
+// can adjust it and tests can shrink it.
+var MaxProcessBatchSize = 500
+
+// Err returns the error enqueuing the key failed with, as a gRPC status
+// error, or nil if it succeeded.
+func (x *ProcessBatchResult) Err() error {
+	if code := codes.Code(x.GetCode()); code != codes.OK { //nolint:gosec // gRPC codes are small non-negative values
+		return status.Error(code, x.GetMessage())
+	}
+	return nil
+}
+
+// Err joins the errors of every failed key in the response, each prefixed
+// with its key, or returns nil if every key was enqueued.
+func (x *ProcessBatchResponse) Err() error {
+	var errs []error
+	for _, r := range x.GetResults() {
+		if err := r.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("enqueue %q: %w", r.GetKey(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewProcessBatchResult returns the result for key of enqueuing it with err.
+func NewProcessBatchResult(key string, err error) *ProcessBatchResult {
+	s := status.Convert(err)
+	return &ProcessBatchResult{
+		Key:     key,
+		Code:    int32(s.Code()), //nolint:gosec // gRPC codes are small non-negative values
+		Message: s.Message(),
+	}
+}
+
+// EnqueueBatch enqueues every request through c, sending them in ProcessBatch
+// calls of at most MaxProcessBatchSize keys. Servers that predate
+// ProcessBatch (those answering Unimplemented) are sent one Process call per
+// key instead. It returns one result per request, in request order, and an
+// error only when a call fails outright for a reason other than
+// Unimplemented; per-key failures are reported in the results.
+func EnqueueBatch(ctx context.Context, c WorkqueueServiceClient, reqs []*ProcessRequest) ([]*ProcessBatchResult, error) {
+	results := make([]*ProcessBatchResult, 0, len(reqs))
+	for len(reqs) > 0 {
+		n := min(len(reqs), max(MaxProcessBatchSize, 1))
+		chunk := reqs[:n]
+		reqs = reqs[n:]
+
+		resp, err := c.ProcessBatch(ctx, &ProcessBatchRequest{Requests: chunk})
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			for _, req := range chunk {
+				_, err := c.Process(ctx, req)
+				results = append(results, NewProcessBatchResult(req.GetKey(), err))
+			}
+		case err != nil:
+			return results, fmt.Errorf("ProcessBatch() = %w", err)
+		case len(resp.GetResults()) != len(chunk):
+			return results, fmt.Errorf("ProcessBatch() returned %d results for %d requests", len(resp.GetResults()), len(chunk))
+		default:
+			results = append(results, resp.GetResults()...)
+		}
+	}
+	return results, nil
+}
+
+// ServeProcessBatch implements the ProcessBatch RPC over wq, so a
+// WorkqueueServiceServer fronting a workqueue can delegate to it. Each
+// request is enqueued with its priority, and with a not-before of now plus
+// its delay.
+func ServeProcessBatch(ctx context.Context, wq Interface, req *ProcessBatchRequest) (*ProcessBatchResponse, error) {
+	resp := &ProcessBatchResponse{
+		Results: make([]*ProcessBatchResult, 0, len(req.GetRequests())),
+	}
+	for _, r := range req.GetRequests() {
+		opts := Options{Priority: r.GetPriority()}
+		if r.GetDelaySeconds() > 0 {
+			opts.NotBefore = time.Now().UTC().Add(time.Duration(r.GetDelaySeconds()) * time.Second)
+		}
+		var err error
+		if r.GetKey() == "" {
+			err = status.Error(codes.InvalidArgument, "key is required")
+		} else {
+			err = wq.Queue(ctx, r.GetKey(), opts)
+		}
+		resp.Results = append(resp.Results, NewProcessBatchResult(r.GetKey(), err))
+	}
+	return resp, nil
+}
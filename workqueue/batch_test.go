@@ -0,0 +1,134 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// batchClient serves ProcessBatch and Process from an in-memory workqueue,
+// recording the size of each call. When legacy is set, ProcessBatch answers
+// Unimplemented like a server that predates it.
+type batchClient struct {
+	workqueue.WorkqueueServiceClient
+
+	wq      workqueue.Interface
+	legacy  bool
+	batches []int
+	singles int
+}
+
+func (c *batchClient) ProcessBatch(ctx context.Context, req *workqueue.ProcessBatchRequest, _ ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	if c.legacy {
+		return nil, status.Error(codes.Unimplemented, "unknown method ProcessBatch")
+	}
+	c.batches = append(c.batches, len(req.GetRequests()))
+	return workqueue.ServeProcessBatch(ctx, c.wq, req)
+}
+
+func (c *batchClient) Process(ctx context.Context, req *workqueue.ProcessRequest, _ ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
+	c.singles++
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "key is required")
+	}
+	return &workqueue.ProcessResponse{}, c.wq.Queue(ctx, req.GetKey(), workqueue.Options{Priority: req.GetPriority()})
+}
+
+func TestEnqueueBatch(t *testing.T) {
+	orig := workqueue.MaxProcessBatchSize
+	workqueue.MaxProcessBatchSize = 2
+	t.Cleanup(func() { workqueue.MaxProcessBatchSize = orig })
+
+	reqs := []*workqueue.ProcessRequest{
+		{Key: "a", Priority: 1},
+		{Key: ""},
+		{Key: "b", DelaySeconds: 60},
+		{Key: "c"},
+		{Key: "d"},
+	}
+	wantCodes := []codes.Code{codes.OK, codes.InvalidArgument, codes.OK, codes.OK, codes.OK}
+
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy=%t", legacy), func(t *testing.T) {
+			c := &batchClient{wq: inmem.NewWorkQueue(10), legacy: legacy}
+
+			results, err := workqueue.EnqueueBatch(t.Context(), c, reqs)
+			if err != nil {
+				t.Fatalf("EnqueueBatch() = %v", err)
+			}
+			if len(results) != len(reqs) {
+				t.Fatalf("EnqueueBatch() returned %d results, wanted %d", len(results), len(reqs))
+			}
+			for i, r := range results {
+				if r.GetKey() != reqs[i].GetKey() {
+					t.Errorf("result %d: key = %q, wanted %q", i, r.GetKey(), reqs[i].GetKey())
+				}
+				if got := status.Code(r.Err()); got != wantCodes[i] {
+					t.Errorf("result %d: code = %v, wanted %v", i, got, wantCodes[i])
+				}
+			}
+
+			if legacy {
+				if c.singles != len(reqs) {
+					t.Errorf("Process calls = %d, wanted %d", c.singles, len(reqs))
+				}
+			} else if diff := cmp.Diff([]int{2, 2, 1}, c.batches); diff != "" {
+				t.Errorf("ProcessBatch sizes (-want, +got):\n%s", diff)
+			}
+
+			for _, key := range []string{"a", "b", "c", "d"} {
+				if _, err := c.wq.Get(t.Context(), key); err != nil {
+					t.Errorf("Get(%q) = %v", key, err)
+				}
+			}
+		})
+	}
+}
+
+type failingBatchClient struct {
+	workqueue.WorkqueueServiceClient
+	err error
+}
+
+func (c *failingBatchClient) ProcessBatch(context.Context, *workqueue.ProcessBatchRequest, ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	return nil, c.err
+}
+
+func TestEnqueueBatch_surfacesCallErrors(t *testing.T) {
+	backendErr := status.Error(codes.Unavailable, "backend unavailable")
+	_, err := workqueue.EnqueueBatch(t.Context(), &failingBatchClient{err: backendErr}, []*workqueue.ProcessRequest{{Key: "a"}})
+	if !errors.Is(err, backendErr) {
+		t.Errorf("EnqueueBatch() = %v, wanted %v", err, backendErr)
+	}
+}
+
+func TestProcessBatchResponseErr(t *testing.T) {
+	resp := &workqueue.ProcessBatchResponse{Results: []*workqueue.ProcessBatchResult{
+		workqueue.NewProcessBatchResult("ok", nil),
+	}}
+	if err := resp.Err(); err != nil {
+		t.Errorf("Err() = %v, wanted nil", err)
+	}
+
+	resp.Results = append(resp.Results, workqueue.NewProcessBatchResult("bad", status.Error(codes.Internal, "boom")))
+	err := resp.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, wanted an error")
+	}
+	if want := `enqueue "bad": rpc error: code = Internal desc = boom`; err.Error() != want {
+		t.Errorf("Err() = %q, wanted %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,795 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobqueue
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue"
+)
+
+// Store is the blob backend method set the queue is written against. It is
+// declared here, by the consumer, following the store/blob convention: any
+// backend that passes blobtest.RunConformance (blob.Mem, the GCS and disk
+// stores, ...) satisfies it implicitly.
+type Store interface {
+	Put(ctx context.Context, name string, data []byte, cond blob.Cond) (blob.Gen, error)
+	Get(ctx context.Context, name string) ([]byte, blob.Gen, bool, error)
+	Delete(ctx context.Context, name string, ifGen blob.Gen) error
+	List(ctx context.Context, prefix string, limit int, cursor string) (blob.Page, error)
+}
+
+// Option configures a blob-backed workqueue created by NewWorkQueue.
+type Option func(*wq)
+
+// WithOwner sets the identity recorded on each in-progress entry this queue
+// starts, e.g. the host or region claiming the key. Defaults to "", which
+// records nothing.
+func WithOwner(owner string) Option {
+	return func(w *wq) { w.owner = owner }
+}
+
+// NewWorkQueue creates a workqueue whose state lives in store. Every process
+// that shares the store shares the queue; each key is leased to one of them at
+// a time.
+func NewWorkQueue(store Store, limit int, opts ...Option) workqueue.Interface {
+	w := &wq{
+		store: store,
+		limit: limit,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+type wq struct {
+	store Store
+	limit int
+	// owner is recorded on the in-progress entries this queue starts; "" if
+	// WithOwner was not provided.
+	owner string
+}
+
+var _ workqueue.Interface = (*wq)(nil)
+var _ workqueue.Admin = (*wq)(nil)
+var _ workqueue.Lister = (*wq)(nil)
+var _ workqueue.LimitEnumerator = (*wq)(nil)
+
+// RefreshInterval is the period on which we refresh the lease of owned keys.
+// It is surfaced as a global, so that it can be mutated by tests and exposed as
+// a flag by binaries wrapping this library. Every process sharing a store
+// should use the same value, or they may see unexpected behavior.
+var RefreshInterval = 5 * time.Minute
+
+// heartbeatRetryInterval is the period on which the lease heartbeat retries
+// after a transient storage error, until the lease would expire. It is a
+// variable so tests can shorten it.
+var heartbeatRetryInterval = 30 * time.Second
+
+const (
+	queuedPrefix     = "queued/"
+	inProgressPrefix = "in-progress/"
+	deadLetterPrefix = "dead-letter/"
+
+	// listPageSize is how many names each List call fetches while enumerating.
+	listPageSize = 1000
+)
+
+// entry is the body stored for every key. Which fields are meaningful depends
+// on the prefix the entry lives under: the lease and owner only apply while
+// in progress, the not-before and floor only while queued, and the failed
+// time and failure details only once dead-lettered. The attempt errors follow
+// the key through every state until it completes.
+type entry struct {
+	Priority        int64     `json:"priority,omitempty"`
+	Attempts        int       `json:"attempts,omitempty"`
+	NotBefore       time.Time `json:"notBefore,omitzero"`
+	NotBeforeFloor  bool      `json:"notBeforeFloor,omitempty"`
+	Created         time.Time `json:"created"`
+	LastAttempted   time.Time `json:"lastAttempted,omitzero"`
+	LeaseExpiration time.Time `json:"leaseExpiration,omitzero"`
+	Owner           string    `json:"owner,omitempty"`
+	FailedTime      time.Time `json:"failedTime,omitzero"`
+	AttemptErrors   []string  `json:"attemptErrors,omitempty"`
+	LastError       string    `json:"lastError,omitempty"`
+	FailureReason   string    `json:"failureReason,omitempty"`
+	Infrastructure  bool      `json:"infrastructure,omitempty"`
+}
+
+// mergeEntry merges an incoming enqueue (src) into an already-queued entry
+// (dst), reporting whether dst changed. Priority always rises to the highest
+// of the two, and the not-before merge follows the floor semantics documented
+// on workqueue.Options.NotBeforeFloor.
+func mergeEntry(dst *entry, src entry) bool {
+	changed := false
+	if dst.Priority < src.Priority {
+		dst.Priority = src.Priority
+		changed = true
+	}
+	switch {
+	case src.NotBeforeFloor && dst.NotBeforeFloor:
+		// Two floors: the later (max) not-before wins.
+		if dst.NotBefore.Before(src.NotBefore) {
+			dst.NotBefore = src.NotBefore
+			changed = true
+		}
+	case src.NotBeforeFloor:
+		// Incoming floor over a non-floor entry: the floor wins outright,
+		// replacing the (undercuttable) not-before in either direction.
+		dst.NotBefore = src.NotBefore
+		dst.NotBeforeFloor = true
+		changed = true
+	case dst.NotBeforeFloor:
+		// Existing floor, non-floor incoming: keep the floored entry as-is.
+	case dst.NotBefore.After(src.NotBefore):
+		// Neither is a floor: the earliest not-before wins.
+		dst.NotBefore = src.NotBefore
+		changed = true
+	}
+	return changed
+}
+
+// get reads and decodes the entry at name; ok is false when it is absent.
+func (w *wq) get(ctx context.Context, name string) (entry, blob.Gen, bool, error) {
+	data, gen, ok, err := w.store.Get(ctx, name)
+	if err != nil || !ok {
+		return entry{}, 0, false, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return entry{}, 0, false, fmt.Errorf("decoding %q: %w", name, err)
+	}
+	return e, gen, true, nil
+}
+
+// put encodes e and writes it at name subject to cond.
+func (w *wq) put(ctx context.Context, name string, e entry, cond blob.Cond) (blob.Gen, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, fmt.Errorf("encoding %q: %w", name, err)
+	}
+	return w.store.Put(ctx, name, data, cond)
+}
+
+// enqueue creates the queued entry for key from e, or, when the key is
+// already queued, merges e into the existing entry (see mergeEntry). The
+// read-merge-write is generation-pinned and retried, so concurrent enqueues of
+// the same key never lose an update.
+func (w *wq) enqueue(ctx context.Context, key string, e entry) error {
+	name := queuedPrefix + key
+	for {
+		_, err := w.put(ctx, name, e, blob.Cond{DoesNotExist: true})
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, blob.ErrPreconditionFailed) {
+			clog.WarnContextf(ctx, "enqueue: create failed for key %q: %v", key, err)
+			return fmt.Errorf("Put() = %w", err)
+		}
+
+		clog.DebugContextf(ctx, "Key %q already exists", key)
+		cur, gen, ok, err := w.get(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Get() = %w", err)
+		} else if !ok {
+			clog.InfoContextf(ctx, "Key %q was deleted before we could fetch the duplicate, retrying.", key)
+			continue
+		}
+		if !mergeEntry(&cur, e) {
+			return nil
+		}
+		if _, err := w.put(ctx, name, cur, blob.Cond{GenerationMatch: gen}); err == nil {
+			return nil
+		} else if !errors.Is(err, blob.ErrPreconditionFailed) {
+			clog.WarnContextf(ctx, "enqueue: update failed for key %q: %v", key, err)
+			return fmt.Errorf("Put() = %w", err)
+		}
+		// Another writer got there first (or the entry was started); go again.
+	}
+}
+
+// Queue implements workqueue.Interface.
+func (w *wq) Queue(ctx context.Context, key string, opts workqueue.Options) error {
+	return w.enqueue(ctx, key, entry{
+		Priority:       opts.Priority,
+		NotBefore:      opts.NotBefore.UTC(),
+		NotBeforeFloor: opts.NotBeforeFloor,
+		Created:        time.Now().UTC(),
+	})
+}
+
+// each calls f with the name, generation and decoded entry of every object
+// under prefix. Objects removed between the listing and the read are skipped.
+func (w *wq) each(ctx context.Context, prefix string, f func(name string, gen blob.Gen, e entry)) error {
+	cursor := ""
+	for {
+		page, err := w.store.List(ctx, prefix, listPageSize, cursor)
+		if err != nil {
+			return fmt.Errorf("List() = %w", err)
+		}
+		for _, o := range page.Objects {
+			e, gen, ok, err := w.get(ctx, o.Name)
+			if err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			f(o.Name, gen, e)
+		}
+		if page.Cursor == "" {
+			return nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+// Enumerate implements workqueue.Interface.
+func (w *wq) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+	return w.EnumerateN(ctx, w.limit)
+}
+
+// EnumerateN implements workqueue.LimitEnumerator.
+func (w *wq) EnumerateN(ctx context.Context, limit int) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+	var wip []workqueue.ObservedInProgressKey
+	if err := w.each(ctx, inProgressPrefix, func(name string, gen blob.Gen, e entry) {
+		wip = append(wip, &inProgressKey{
+			wq:    w,
+			key:   strings.TrimPrefix(name, inProgressPrefix),
+			gen:   gen,
+			entry: e,
+		})
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	var qd []*queuedKey
+	if err := w.each(ctx, queuedPrefix, func(name string, gen blob.Gen, e entry) {
+		if now.Before(e.NotBefore) {
+			clog.DebugContextf(ctx, "Skipping key %q until %v", name, e.NotBefore)
+			return
+		}
+		qd = append(qd, &queuedKey{
+			wq:    w,
+			key:   strings.TrimPrefix(name, queuedPrefix),
+			gen:   gen,
+			entry: e,
+		})
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+	slices.SortFunc(qd, func(a, b *queuedKey) int {
+		// Higher priority first, then oldest first, then by name.
+		if c := cmp.Compare(b.entry.Priority, a.entry.Priority); c != 0 {
+			return c
+		}
+		if c := a.entry.Created.Compare(b.entry.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key, b.key)
+	})
+	if len(qd) > limit {
+		qd = qd[:limit]
+	}
+	qk := make([]workqueue.QueuedKey, 0, len(qd))
+	for _, q := range qd {
+		qk = append(qk, q)
+	}
+
+	var dl []workqueue.DeadLetteredKey
+	if err := w.each(ctx, deadLetterPrefix, func(name string, _ blob.Gen, e entry) {
+		dl = append(dl, &deadLetteredKey{
+			key:   strings.TrimPrefix(name, deadLetterPrefix),
+			entry: e,
+		})
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return wip, qk, dl, nil
+}
+
+// Get implements workqueue.Interface.
+func (w *wq) Get(ctx context.Context, key string) (*workqueue.KeyState, error) {
+	for _, prefix := range []string{inProgressPrefix, queuedPrefix, deadLetterPrefix} {
+		if e, _, ok, err := w.get(ctx, prefix+key); err != nil {
+			return nil, err
+		} else if ok {
+			return keyState(prefix, key, e), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "key %q not found", key)
+}
+
+// List implements workqueue.Lister.
+func (w *wq) List(ctx context.Context, prefix string) ([]*workqueue.KeyState, error) {
+	var states []*workqueue.KeyState
+	for _, p := range []string{inProgressPrefix, queuedPrefix, deadLetterPrefix} {
+		if err := w.each(ctx, p+prefix, func(name string, _ blob.Gen, e entry) {
+			states = append(states, keyState(p, strings.TrimPrefix(name, p), e))
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+// keyState reports the state of key from its entry under prefix.
+func keyState(prefix, key string, e entry) *workqueue.KeyState {
+	state := &workqueue.KeyState{
+		Key:           key,
+		Attempts:      int32(e.Attempts), //nolint:gosec  // we're not worried about this overflowing
+		Priority:      e.Priority,
+		NotBeforeTime: e.NotBefore.Unix(),
+		AttemptErrors: e.AttemptErrors,
+	}
+	switch prefix {
+	case inProgressPrefix:
+		state.Status = workqueue.KeyState_IN_PROGRESS
+		state.Owner = e.Owner
+	case queuedPrefix:
+		state.Status = workqueue.KeyState_QUEUED
+		state.QueuedTime = e.Created.Unix()
+	case deadLetterPrefix:
+		state.Status = workqueue.KeyState_DEAD_LETTER
+		state.QueuedTime = e.Created.Unix()
+		state.LastError = e.LastError
+		state.FailureReason = e.FailureReason
+		state.InfrastructureError = e.Infrastructure
+	}
+	return state
+}
+
+// ReplayDeadLetter implements workqueue.Admin.
+func (w *wq) ReplayDeadLetter(ctx context.Context, key string, opts workqueue.Options) error {
+	name := deadLetterPrefix + key
+	dead, gen, ok, err := w.get(ctx, name)
+	if err != nil {
+		return err
+	} else if !ok {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	}
+	if opts.Priority == 0 {
+		opts.Priority = dead.Priority
+	}
+
+	clog.InfoContextf(ctx, "Replaying dead-lettered key %q", key)
+	// Queue before removing the dead-letter entry: if the delete fails the
+	// key is merely listed twice, never lost. The queued entry starts with
+	// no attempts, so the key gets its full retry budget.
+	if err := w.Queue(ctx, key, opts); err != nil {
+		return err
+	}
+	if err := w.store.Delete(ctx, name, gen); err != nil && !lostOwnership(err) {
+		return fmt.Errorf("Delete() = %w", err)
+	}
+	return nil
+}
+
+// PurgeDeadLetter implements workqueue.Admin.
+func (w *wq) PurgeDeadLetter(ctx context.Context, key string) error {
+	if err := w.store.Delete(ctx, deadLetterPrefix+key, 0); errors.Is(err, blob.ErrNotExist) {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	} else if err != nil {
+		return fmt.Errorf("Delete() = %w", err)
+	}
+	clog.InfoContextf(ctx, "Purged dead-lettered key %q", key)
+	return nil
+}
+
+// lostOwnership reports whether err means the in-progress entry no longer
+// carries the generation this process leased: it was replaced or removed.
+func lostOwnership(err error) bool {
+	return errors.Is(err, blob.ErrPreconditionFailed) || errors.Is(err, blob.ErrNotExist)
+}
+
+type inProgressKey struct {
+	wq  *wq
+	key string
+
+	ownerCtx    context.Context
+	ownerCancel context.CancelFunc
+
+	// heartbeatStopped is closed when the heartbeat goroutine exits; it is nil
+	// for keys observed via Enumerate, which do not heartbeat.
+	heartbeatStopped chan struct{}
+
+	// Once we start to heartbeat things, then that thread may update gen and
+	// entry, so use the RWMutex to protect them from concurrent access.
+	rw    sync.RWMutex
+	gen   blob.Gen
+	entry entry
+}
+
+var _ workqueue.ObservedInProgressKey = (*inProgressKey)(nil)
+var _ workqueue.OwnedInProgressKey = (*inProgressKey)(nil)
+
+// Name implements workqueue.Key.
+func (o *inProgressKey) Name() string {
+	return o.key
+}
+
+// Priority implements workqueue.Key.
+func (o *inProgressKey) Priority() int64 {
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+	return o.entry.Priority
+}
+
+// GetAttempts implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) GetAttempts() int {
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+	return o.entry.Attempts
+}
+
+// Context implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) Context() context.Context {
+	return o.ownerCtx
+}
+
+// IsOrphaned implements workqueue.ObservedInProgressKey.
+func (o *inProgressKey) IsOrphaned() bool {
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+	// A missing lease is treated as orphaned, as is one in the past.
+	return o.entry.LeaseExpiration.IsZero() || time.Now().UTC().After(o.entry.LeaseExpiration)
+}
+
+// stopHeartbeat cancels the lease heartbeat and waits for its goroutine to
+// exit, so cleanup paths observe a stable gen and can never race an in-flight
+// refresh. It is a no-op for keys observed via Enumerate.
+func (o *inProgressKey) stopHeartbeat() {
+	if o.ownerCancel != nil {
+		o.ownerCancel()
+	}
+	if o.heartbeatStopped != nil {
+		<-o.heartbeatStopped
+	}
+}
+
+// Requeue implements workqueue.InProgressKey.
+func (o *inProgressKey) Requeue(ctx context.Context) error {
+	return o.RequeueWithOptions(ctx, workqueue.Options{})
+}
+
+// RequeueWithOptions implements workqueue.InProgressKey.
+func (o *inProgressKey) RequeueWithOptions(ctx context.Context, opts workqueue.Options) error {
+	o.stopHeartbeat()
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+
+	// A key observed via Enumerate has no heartbeat keeping its view of the
+	// lease current: the owner may have refreshed it since we listed it. Every
+	// refresh advances the generation, so the pinned delete below already
+	// refuses to remove a refreshed lease; checking up front also avoids
+	// leaving a spurious queued twin behind in that case.
+	if o.ownerCancel == nil {
+		_, gen, ok, err := o.wq.get(ctx, inProgressPrefix+o.key)
+		switch {
+		case err != nil:
+			return fmt.Errorf("Get() = %w", err)
+		case !ok:
+			clog.WarnContextf(ctx, "RequeueWithOptions: lost ownership of key %q, skipping requeue", o.key)
+			return nil
+		case gen != o.gen:
+			clog.WarnContextf(ctx, "RequeueWithOptions: lease on key %q changed since observation, skipping requeue", o.key)
+			return nil
+		}
+	}
+
+	now := time.Now().UTC()
+	e := o.entry
+	// The lease and owner only apply while the key is in progress.
+	e.LeaseExpiration = time.Time{}
+	e.Owner = ""
+	e.LastAttempted = now
+	e.Created = now
+	e.AttemptErrors = workqueue.RecordAttemptError(e.AttemptErrors, opts.AttemptError)
+
+	switch {
+	case opts.BackoffDelay > 0:
+		// Failure-retry backoff: wait BackoffDelay before reprocessing WITHOUT
+		// resetting the attempt count and regardless of priority, so the
+		// dispatcher's attempts >= maxRetry dead-letter cutoff stays reachable.
+		e.NotBefore = now.Add(opts.BackoffDelay)
+	case opts.Delay > 0:
+		// Reset attempts when using custom delay, as this indicates periodic
+		// revisit pattern rather than retry due to failure.
+		e.Attempts = 0
+		e.AttemptErrors = nil
+		e.NotBefore = now.Add(opts.Delay)
+	case e.Priority != 0:
+		// If no custom delay and priority is set, use the standard backoff.
+		backoffDelay := min(time.Duration(e.Attempts*int(workqueue.BackoffPeriod)), workqueue.MaximumBackoffPeriod)
+		e.NotBefore = now.Add(backoffDelay)
+	}
+	// Start clears any stale floor, so we only need to set it here.
+	e.NotBeforeFloor = opts.NotBeforeFloor
+	if opts.Priority != 0 {
+		e.Priority = opts.Priority
+	}
+
+	if err := o.wq.enqueue(ctx, o.key, e); err != nil {
+		return err
+	}
+	if err := o.wq.store.Delete(ctx, inProgressPrefix+o.key, o.gen); err != nil {
+		if lostOwnership(err) {
+			// Another attempt owns the key now (or it is already gone), so
+			// leave the queued twin in place: a spurious re-execution is the
+			// safe bias, where deleting it could drop an event a concurrent
+			// enqueue merged into it.
+			clog.WarnContextf(ctx, "RequeueWithOptions: lost ownership of key %q, leaving requeue twin intact: %v", o.key, err)
+			return nil
+		}
+		clog.WarnContextf(ctx, "RequeueWithOptions: failed to delete in-progress entry for key %q: %v", o.key, err)
+		return err
+	}
+	return nil
+}
+
+// Complete implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) Complete(ctx context.Context) error {
+	o.stopHeartbeat()
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+
+	// Best-effort delete of the dead-letter entry, if it exists.
+	if err := o.wq.store.Delete(ctx, deadLetterPrefix+o.key, 0); err != nil && !errors.Is(err, blob.ErrNotExist) {
+		clog.WarnContextf(ctx, "Complete: failed to delete dead-letter entry for key %q: %v", o.key, err)
+	}
+
+	if err := o.wq.store.Delete(ctx, inProgressPrefix+o.key, o.gen); err != nil {
+		if lostOwnership(err) {
+			// The work completed, but another attempt owns the key now; its
+			// lease entry must be left intact.
+			clog.WarnContextf(ctx, "Complete: lost ownership of key %q, skipping delete: %v", o.key, err)
+			return nil
+		}
+		clog.ErrorContextf(ctx, "Complete: failed to delete in-progress entry for key %q: %v", o.key, err)
+		return err
+	}
+	return nil
+}
+
+// Deadletter implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) Deadletter(ctx context.Context) error {
+	return o.DeadletterWithError(ctx, nil)
+}
+
+// DeadletterWithError implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) DeadletterWithError(ctx context.Context, cause error) error {
+	o.stopHeartbeat()
+	o.rw.RLock()
+	defer o.rw.RUnlock()
+
+	// Only dead-letter the lease we hold: if another attempt replaced it, the
+	// key's state is that attempt's to decide.
+	if _, gen, ok, err := o.wq.get(ctx, inProgressPrefix+o.key); err != nil {
+		return fmt.Errorf("Get() = %w", err)
+	} else if !ok || gen != o.gen {
+		clog.WarnContextf(ctx, "Deadletter: lost ownership of key %q, skipping dead-letter", o.key)
+		return nil
+	}
+
+	clog.InfoContextf(ctx, "Moving key %q to dead letter queue", o.key)
+
+	e := o.entry
+	e.LeaseExpiration = time.Time{}
+	e.Owner = ""
+	e.FailedTime = time.Now().UTC()
+	failure := workqueue.NewFailureDetails(cause, e.AttemptErrors)
+	e.AttemptErrors = failure.AttemptErrors
+	e.LastError = failure.LastError
+	e.FailureReason = failure.Reason
+	e.Infrastructure = failure.Infrastructure
+	if _, err := o.wq.put(ctx, deadLetterPrefix+o.key, e, blob.Cond{}); err != nil {
+		clog.WarnContextf(ctx, "Deadletter: write to dead-letter failed for key %q: %v", o.key, err)
+		return fmt.Errorf("failed to create dead letter entry: %w", err)
+	}
+
+	if err := o.wq.store.Delete(ctx, inProgressPrefix+o.key, o.gen); err != nil {
+		if lostOwnership(err) {
+			clog.WarnContextf(ctx, "Deadletter: lost ownership of key %q, skipping delete: %v", o.key, err)
+			return nil
+		}
+		clog.WarnContextf(ctx, "Deadletter: failed to delete in-progress entry for key %q: %v", o.key, err)
+		return err
+	}
+	return nil
+}
+
+func (o *inProgressKey) startHeartbeat(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	o.ownerCtx = ctx
+	o.ownerCancel = cancel
+	o.heartbeatStopped = make(chan struct{})
+
+	go func() {
+		defer close(o.heartbeatStopped)
+		ticker := time.NewTicker(RefreshInterval)
+		defer ticker.Stop()
+		defer cancel()
+
+		expiry := o.entry.LeaseExpiration
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				newExpiry, ok := o.refreshLease(ctx, expiry)
+				if !ok {
+					return
+				}
+				expiry = newExpiry
+			}
+		}
+	}()
+}
+
+// refreshLease extends the lease on the in-progress entry, retrying transient
+// errors until the current lease expires. It returns the new expiration and
+// whether ownership is retained; on a definitive loss of ownership or once the
+// lease can no longer be extended before it lapses, it returns false so the
+// caller cancels the in-progress work.
+func (o *inProgressKey) refreshLease(ctx context.Context, expiry time.Time) (time.Time, bool) {
+	retryInterval := min(heartbeatRetryInterval, RefreshInterval)
+
+	for {
+		if ctx.Err() != nil {
+			return time.Time{}, false
+		}
+
+		newExpiry := time.Now().UTC().Add(3 * RefreshInterval)
+		err := func() error {
+			o.rw.Lock()
+			defer o.rw.Unlock()
+
+			e := o.entry
+			e.LeaseExpiration = newExpiry
+			// Pin the generation we hold: a replacement by another attempt
+			// (or a removal) fails the write rather than adopting its lease.
+			gen, err := o.wq.put(ctx, inProgressPrefix+o.key, e, blob.Cond{GenerationMatch: o.gen})
+			if err != nil {
+				return err
+			}
+			o.gen, o.entry = gen, e
+			return nil
+		}()
+		switch {
+		case err == nil:
+			return newExpiry, true
+
+		case ctx.Err() != nil:
+			return time.Time{}, false
+
+		case lostOwnership(err):
+			clog.ErrorContextf(ctx, "refreshLease: lost ownership of %q, terminating in-progress work: %v", o.key, err)
+			return time.Time{}, false
+		}
+
+		if !time.Now().UTC().Add(retryInterval).Before(expiry) {
+			clog.ErrorContextf(ctx, "refreshLease: failed to update expiration for %q before lease expiry, terminating in-progress work: %v", o.key, err)
+			return time.Time{}, false
+		}
+		clog.WarnContextf(ctx, "refreshLease: failed to update expiration for %q (will retry): %v", o.key, err)
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, false
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+type queuedKey struct {
+	wq    *wq
+	key   string
+	gen   blob.Gen
+	entry entry
+}
+
+var _ workqueue.QueuedKey = (*queuedKey)(nil)
+
+// Name implements workqueue.Key.
+func (q *queuedKey) Name() string {
+	return q.key
+}
+
+// Priority implements workqueue.Key.
+func (q *queuedKey) Priority() int64 {
+	return q.entry.Priority
+}
+
+// Start implements workqueue.QueuedKey.
+func (q *queuedKey) Start(ctx context.Context) (workqueue.OwnedInProgressKey, error) {
+	now := time.Now().UTC()
+	e := q.entry
+	e.Attempts++
+	e.Created = now
+	e.LeaseExpiration = now.Add(3 * RefreshInterval)
+	e.Owner = q.wq.owner
+	// Never carry the not-before or its floor onto a running key;
+	// RequeueWithOptions re-applies them on request.
+	e.NotBefore = time.Time{}
+	e.NotBeforeFloor = false
+
+	gen, err := q.wq.put(ctx, inProgressPrefix+q.key, e, blob.Cond{DoesNotExist: true})
+	if err != nil {
+		clog.WarnContextf(ctx, "Start: write to in-progress failed for key %q: %v", q.key, err)
+		return nil, fmt.Errorf("Put() = %w", err)
+	}
+	if err := q.wq.store.Delete(ctx, queuedPrefix+q.key, 0); err != nil && !errors.Is(err, blob.ErrNotExist) {
+		// The in-progress entry was already created above, so we proceed
+		// normally. The queued entry stays behind and the key will be
+		// processed a second time as a result.
+		clog.WarnContextf(ctx, "Failed to delete queued entry for %q after starting: %v (key will be processed multiple times)", q.key, err)
+	}
+
+	oip := &inProgressKey{
+		wq:    q.wq,
+		key:   q.key,
+		gen:   gen,
+		entry: e,
+	}
+
+	// start a process to heartbeat things, and set up a context that we can
+	// cancel if the heartbeat observes a loss in ownership.
+	oip.startHeartbeat(ctx)
+
+	return oip, nil
+}
+
+type deadLetteredKey struct {
+	key   string
+	entry entry
+}
+
+var _ workqueue.DeadLetteredKey = (*deadLetteredKey)(nil)
+
+// Name implements workqueue.Key.
+func (d *deadLetteredKey) Name() string {
+	return d.key
+}
+
+// Priority implements workqueue.Key.
+func (d *deadLetteredKey) Priority() int64 {
+	return d.entry.Priority
+}
+
+// GetFailedTime implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetFailedTime() time.Time {
+	return d.entry.FailedTime
+}
+
+// GetAttempts implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetAttempts() int {
+	return d.entry.Attempts
+}
+
+// GetFailureDetails implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetFailureDetails() workqueue.FailureDetails {
+	return workqueue.FailureDetails{
+		LastError:      d.entry.LastError,
+		Reason:         d.entry.FailureReason,
+		Infrastructure: d.entry.Infrastructure,
+		AttemptErrors:  d.entry.AttemptErrors,
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/conformance"
+)
+
+func TestWorkQueue(t *testing.T) {
+	// Adjust this to a suitable period for testing things.
+	// The conformance tests own adjusting MaximumBackoffPeriod.
+	workqueue.BackoffPeriod = 1 * time.Second
+
+	// Share one store across constructions so durability is observable.
+	store := blob.NewMem()
+	ctor := func(u int) workqueue.Interface {
+		return NewWorkQueue(store, u)
+	}
+
+	conformance.TestSemantics(t, ctor)
+
+	conformance.TestConcurrency(t, ctor)
+
+	conformance.TestDurability(t, ctor)
+
+	conformance.TestMaxRetry(t, ctor)
+
+	conformance.TestBackoffDelay(t, ctor)
+
+	conformance.TestDeadLetterAdmin(t, ctor)
+
+	conformance.TestList(t, ctor)
+}
+
+func TestLease(t *testing.T) {
+	orig := RefreshInterval
+	t.Cleanup(func() { RefreshInterval = orig })
+	RefreshInterval = 100 * time.Millisecond
+
+	ctx := context.Background()
+	wq := NewWorkQueue(blob.NewMem(), 5, WithOwner("host-a"))
+	if err := wq.Queue(ctx, "foo", workqueue.Options{}); err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	_, qd, _, err := wq.Enumerate(ctx)
+	if err != nil || len(qd) != 1 {
+		t.Fatalf("Enumerate: got %d queued, err=%v", len(qd), err)
+	}
+	owned, err := qd[0].Start(ctx)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// Observe the key, then let the heartbeat refresh the lease past what we
+	// observed.
+	wip, _, _, err := wq.Enumerate(ctx)
+	if err != nil || len(wip) != 1 {
+		t.Fatalf("Enumerate: got %d in-progress, err=%v", len(wip), err)
+	}
+	if wip[0].IsOrphaned() {
+		t.Error("IsOrphaned: got true for a freshly leased key")
+	}
+	if got := wip[0].(*inProgressKey).entry.Owner; got != "host-a" {
+		t.Errorf("owner: got %q, want %q", got, "host-a")
+	}
+	time.Sleep(3 * RefreshInterval)
+
+	// The stale observation must not steal the refreshed lease.
+	if err := wip[0].Requeue(ctx); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	state, err := wq.Get(ctx, "foo")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if state.Status != workqueue.KeyState_IN_PROGRESS {
+		t.Errorf("status after stale requeue: got %v, want IN_PROGRESS", state.Status)
+	}
+	select {
+	case <-owned.Context().Done():
+		t.Fatal("owner context canceled while it still holds the lease")
+	default:
+	}
+
+	// Once the owner stops heartbeating, the lease lapses and an observer may
+	// recover the orphaned key.
+	owned.(*inProgressKey).stopHeartbeat()
+	time.Sleep(4 * RefreshInterval)
+	wip, _, _, err = wq.Enumerate(ctx)
+	if err != nil || len(wip) != 1 {
+		t.Fatalf("Enumerate: got %d in-progress, err=%v", len(wip), err)
+	}
+	if !wip[0].IsOrphaned() {
+		t.Fatal("IsOrphaned: got false after the lease lapsed")
+	}
+	if err := wip[0].Requeue(ctx); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	state, err = wq.Get(ctx, "foo")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if state.Status != workqueue.KeyState_QUEUED || state.Attempts != 1 {
+		t.Errorf("state after orphan requeue: got %v attempts=%d, want QUEUED attempts=1", state.Status, state.Attempts)
+	}
+}
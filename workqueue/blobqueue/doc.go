@@ -0,0 +1,19 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blobqueue provides a workqueue implementation written purely
+// against the store/blob contract, so any blob backend can host a durable
+// queue.
+//
+// Each key is one object under the queued/, in-progress/, or dead-letter/
+// prefix whose body records its priority, attempts, not-before (and floor),
+// lease expiration, and owner. Every state transition is a compare-and-swap
+// on the object's generation: concurrent enqueues merge into the queued
+// object with a generation-pinned read-modify-write, the lease heartbeat
+// rewrites the in-progress object pinned to the generation it holds, and
+// Requeue, Complete and Deadletter only remove the in-progress generation
+// they leased. A backend that passes blobtest.RunConformance therefore
+// passes the workqueue conformance suite.
+package blobqueue
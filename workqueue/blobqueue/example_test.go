@@ -0,0 +1,37 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blobqueue_test
+
+import (
+	"context"
+	"fmt"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/blobqueue"
+)
+
+// ExampleNewWorkQueue demonstrates hosting a workqueue on a blob store. The
+// in-memory store is used here; production callers pass a durable backend
+// such as the GCS or disk blob stores.
+func ExampleNewWorkQueue() {
+	ctx := context.Background()
+	store := blob.NewMem()
+
+	wq := blobqueue.NewWorkQueue(store, 5)
+	if err := wq.Queue(ctx, "my-key", workqueue.Options{Priority: 10}); err != nil {
+		panic(err)
+	}
+
+	// The queue's state lives in the store, so another queue over the same
+	// store sees the key.
+	_, queued, _, err := blobqueue.NewWorkQueue(store, 5).Enumerate(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Queued keys: %d (%s)\n", len(queued), queued[0].Name())
+	// Output: Queued keys: 1 (my-key)
+}
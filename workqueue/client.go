@@ -18,6 +18,9 @@ import (
 	"google.golang.org/grpc/credentials/oauth"
 )
 
+// Client is a WorkqueueServiceClient holding its own connection. To enqueue
+// many keys, pass it to EnqueueBatch, which batches them into ProcessBatch
+// calls and falls back to Process against servers without it.
 type Client interface {
 	WorkqueueServiceClient
 
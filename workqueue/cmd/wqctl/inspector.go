@@ -0,0 +1,223 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/schedule"
+)
+
+// inspector is the set of operations wqctl performs against a workqueue.
+type inspector interface {
+	// list returns the state of every key with the given prefix.
+	list(ctx context.Context, prefix string) ([]*workqueue.KeyState, error)
+	// get returns the state of a single key.
+	get(ctx context.Context, key string) (*workqueue.KeyState, error)
+	// enqueue queues key with the given priority, not before delay from now.
+	enqueue(ctx context.Context, key string, priority int64, delay time.Duration) error
+	// requeue returns an in-progress key to the queue, after delay when it is
+	// non-zero and after the backend's default backoff otherwise.
+	requeue(ctx context.Context, key string, delay time.Duration) error
+	// deadletter dead-letters an in-progress key, recording reason as the
+	// cause when it is non-empty.
+	deadletter(ctx context.Context, key, reason string) error
+}
+
+// local inspects a workqueue backend directly.
+type local struct {
+	wq workqueue.Interface
+	// sched, when set, reports the schedules of recurring keys in get.
+	sched *schedule.Scheduler
+}
+
+var _ inspector = (*local)(nil)
+
+// newLocal inspects wq, reading the schedules that a schedule.Scheduler
+// persists under prefix in store.
+func newLocal(wq workqueue.Interface, store schedule.Store, prefix string) *local {
+	return &local{wq: wq, sched: schedule.New(wq, store, schedule.WithPrefix(prefix))}
+}
+
+func (l *local) list(ctx context.Context, prefix string) ([]*workqueue.KeyState, error) {
+	lwq, ok := l.wq.(workqueue.Lister)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support listing keys", l.wq)
+	}
+	states, err := lwq.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	workqueue.SortKeyStates(states)
+	return states, nil
+}
+
+func (l *local) get(ctx context.Context, key string) (*workqueue.KeyState, error) {
+	if l.sched != nil {
+		return l.sched.Get(ctx, key)
+	}
+	return l.wq.Get(ctx, key)
+}
+
+func (l *local) enqueue(ctx context.Context, key string, priority int64, delay time.Duration) error {
+	opts := workqueue.Options{Priority: priority}
+	if delay > 0 {
+		opts.NotBefore = time.Now().Add(delay)
+	}
+	return l.wq.Queue(ctx, key, opts)
+}
+
+// inProgress finds the in-progress key named key.
+func (l *local) inProgress(ctx context.Context, key string) (workqueue.ObservedInProgressKey, error) {
+	wip, _, _, err := l.wq.Enumerate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("enumerate: %w", err)
+	}
+	for _, k := range wip {
+		if k.Name() == key {
+			return k, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "key %q is not in progress", key)
+}
+
+func (l *local) requeue(ctx context.Context, key string, delay time.Duration) error {
+	k, err := l.inProgress(ctx, key)
+	if err != nil {
+		return err
+	}
+	if delay > 0 {
+		return k.RequeueWithOptions(ctx, workqueue.Options{Priority: k.Priority(), Delay: delay})
+	}
+	return k.Requeue(ctx)
+}
+
+func (l *local) deadletter(ctx context.Context, key, reason string) error {
+	k, err := l.inProgress(ctx, key)
+	if err != nil {
+		return err
+	}
+	// The backends' observed keys can dead-letter the attempt they observed,
+	// but ObservedInProgressKey does not promise it.
+	dk, ok := k.(interface {
+		DeadletterWithError(context.Context, error) error
+	})
+	if !ok {
+		return fmt.Errorf("%T does not support dead-lettering observed keys", k)
+	}
+	if reason == "" {
+		reason = "dead-lettered by wqctl"
+	}
+	return dk.DeadletterWithError(ctx, errors.New(reason))
+}
+
+// remote inspects a workqueue through its WorkqueueService endpoint.
+type remote struct {
+	client workqueue.WorkqueueServiceClient
+}
+
+var _ inspector = (*remote)(nil)
+
+// errRemoteUnsupported is returned for operations the WorkqueueService RPCs
+// do not expose.
+var errRemoteUnsupported = errors.New("not supported against a remote endpoint: use -bucket or -dir to reach the backend directly")
+
+func (r *remote) list(ctx context.Context, prefix string) ([]*workqueue.KeyState, error) {
+	resp, err := r.client.ListKeys(ctx, &workqueue.ListKeysRequest{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetKeys(), nil
+}
+
+func (r *remote) get(ctx context.Context, key string) (*workqueue.KeyState, error) {
+	return r.client.GetKeyState(ctx, &workqueue.GetKeyStateRequest{Key: key})
+}
+
+func (r *remote) enqueue(ctx context.Context, key string, priority int64, delay time.Duration) error {
+	_, err := r.client.Process(ctx, &workqueue.ProcessRequest{
+		Key:          key,
+		Priority:     priority,
+		DelaySeconds: int64(delay.Round(time.Second) / time.Second),
+	})
+	return err
+}
+
+func (r *remote) requeue(context.Context, string, time.Duration) error {
+	return errRemoteUnsupported
+}
+
+func (r *remote) deadletter(context.Context, string, string) error {
+	return errRemoteUnsupported
+}
+
+// formatTime renders a Unix timestamp, or "-" when it is unset.
+func formatTime(unix int64, now time.Time) string {
+	if unix <= 0 {
+		return "-"
+	}
+	t := time.Unix(unix, 0)
+	if t.After(now) {
+		return fmt.Sprintf("%s (in %s)", t.UTC().Format(time.RFC3339), t.Sub(now).Round(time.Second))
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// printList writes states as a table.
+func printList(w io.Writer, states []*workqueue.KeyState, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tSTATUS\tPRIORITY\tNOT BEFORE\tATTEMPTS\tOWNER")
+	for _, s := range states {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%s\n",
+			s.GetKey(), s.GetStatus(), s.GetPriority(),
+			formatTime(s.GetNotBeforeTime(), now), s.GetAttempts(), orDash(s.GetOwner()))
+	}
+	return tw.Flush()
+}
+
+// printState writes every recorded detail of a single key.
+func printState(w io.Writer, s *workqueue.KeyState, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Key:\t%s\n", s.GetKey())
+	fmt.Fprintf(tw, "Status:\t%s\n", s.GetStatus())
+	fmt.Fprintf(tw, "Priority:\t%d\n", s.GetPriority())
+	fmt.Fprintf(tw, "Attempts:\t%d\n", s.GetAttempts())
+	fmt.Fprintf(tw, "Owner:\t%s\n", orDash(s.GetOwner()))
+	fmt.Fprintf(tw, "Queued:\t%s\n", formatTime(s.GetQueuedTime(), now))
+	fmt.Fprintf(tw, "Not before:\t%s\n", formatTime(s.GetNotBeforeTime(), now))
+	if s.GetStatus() == workqueue.KeyState_DEAD_LETTER {
+		fmt.Fprintf(tw, "Last error:\t%s\n", orDash(s.GetLastError()))
+		fmt.Fprintf(tw, "Failure reason:\t%s\n", orDash(s.GetFailureReason()))
+		fmt.Fprintf(tw, "Infrastructure error:\t%t\n", s.GetInfrastructureError())
+	}
+	if sched := s.GetSchedule(); sched != nil {
+		fmt.Fprintf(tw, "Schedule:\t%s (priority %d)\n", sched.GetSpec(), sched.GetPriority())
+		fmt.Fprintf(tw, "Last run:\t%s\n", formatTime(sched.GetLastRunTime(), now))
+		fmt.Fprintf(tw, "Next run:\t%s\n", formatTime(sched.GetNextRunTime(), now))
+	}
+	if errs := s.GetAttemptErrors(); len(errs) > 0 {
+		fmt.Fprintf(tw, "Attempt errors:\t%s\n", strings.Join(errs, "\n\t"))
+	}
+	return tw.Flush()
+}
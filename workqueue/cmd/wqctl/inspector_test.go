@@ -0,0 +1,203 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"chainguard.dev/driftlessaf/store/blob/disk"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/fs"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"chainguard.dev/driftlessaf/workqueue/schedule"
+)
+
+// start queues key and starts it, leaving it in progress.
+func start(t *testing.T, wq workqueue.Interface, key string) {
+	t.Helper()
+	ctx := t.Context()
+	if err := wq.Queue(ctx, key, workqueue.Options{}); err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+	_, qd, _, err := wq.Enumerate(ctx)
+	if err != nil {
+		t.Fatalf("Enumerate() = %v", err)
+	}
+	for _, k := range qd {
+		if k.Name() == key {
+			if _, err := k.Start(ctx); err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			return
+		}
+	}
+	t.Fatalf("key %q is not queued", key)
+}
+
+func TestLocal(t *testing.T) {
+	ctx := t.Context()
+	wq := inmem.NewWorkQueue(1)
+	insp := &local{wq: wq}
+
+	if err := insp.enqueue(ctx, "repo/later", 4, time.Hour); err != nil {
+		t.Fatalf("enqueue() = %v", err)
+	}
+	start(t, wq, "repo/running")
+	start(t, wq, "repo/failing")
+
+	if err := insp.deadletter(ctx, "repo/failing", "stuck on a bad input"); err != nil {
+		t.Fatalf("deadletter() = %v", err)
+	}
+	state, err := insp.get(ctx, "repo/failing")
+	if err != nil {
+		t.Fatalf("get() = %v", err)
+	}
+	if state.GetStatus() != workqueue.KeyState_DEAD_LETTER || state.GetLastError() != "stuck on a bad input" {
+		t.Errorf("get() = %v, wanted dead-lettered with the reason recorded", state)
+	}
+
+	var out bytes.Buffer
+	if err := execute(ctx, insp, request{cmd: "list", key: "repo/"}, &out); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("list printed %d lines, wanted a header and 3 keys:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{"repo/running", "repo/later", "repo/failing"} {
+		if !strings.HasPrefix(lines[i+1], want+" ") {
+			t.Errorf("line %d = %q, wanted key %q", i+1, lines[i+1], want)
+		}
+	}
+	if !strings.Contains(lines[2], "(in 1h0m0s)") && !strings.Contains(lines[2], "(in 59m59s)") {
+		t.Errorf("delayed key line = %q, wanted its NotBefore", lines[2])
+	}
+
+	if err := insp.requeue(ctx, "repo/running", time.Minute); err != nil {
+		t.Fatalf("requeue() = %v", err)
+	}
+	state, err = insp.get(ctx, "repo/running")
+	if err != nil {
+		t.Fatalf("get() = %v", err)
+	}
+	if state.GetStatus() != workqueue.KeyState_QUEUED || state.GetNotBeforeTime() <= time.Now().Unix() {
+		t.Errorf("get() = %v, wanted queued with a future NotBefore", state)
+	}
+
+	if err := insp.requeue(ctx, "repo/running", 0); err == nil {
+		t.Error("requeue() of a key that is not in progress succeeded")
+	}
+}
+
+// fakeService serves the WorkqueueService RPCs wqctl uses over a workqueue.
+type fakeService struct {
+	workqueue.WorkqueueServiceClient
+	wq workqueue.Interface
+}
+
+func (f *fakeService) ListKeys(ctx context.Context, req *workqueue.ListKeysRequest, _ ...grpc.CallOption) (*workqueue.ListKeysResponse, error) {
+	return workqueue.ServeListKeys(ctx, f.wq, req)
+}
+
+func (f *fakeService) GetKeyState(ctx context.Context, req *workqueue.GetKeyStateRequest, _ ...grpc.CallOption) (*workqueue.KeyState, error) {
+	return workqueue.ServeGetKeyState(ctx, f.wq, req)
+}
+
+func (f *fakeService) Process(ctx context.Context, req *workqueue.ProcessRequest, _ ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
+	return &workqueue.ProcessResponse{}, f.wq.Queue(ctx, req.GetKey(), workqueue.Options{
+		Priority: req.GetPriority(),
+		Delay:    time.Duration(req.GetDelaySeconds()) * time.Second,
+	})
+}
+
+func TestRemote(t *testing.T) {
+	ctx := t.Context()
+	wq := inmem.NewWorkQueue(5)
+	insp := &remote{client: &fakeService{wq: wq}}
+
+	if err := insp.enqueue(ctx, "repo/a", 3, 0); err != nil {
+		t.Fatalf("enqueue() = %v", err)
+	}
+	state, err := insp.get(ctx, "repo/a")
+	if err != nil {
+		t.Fatalf("get() = %v", err)
+	}
+	if state.GetStatus() != workqueue.KeyState_QUEUED || state.GetPriority() != 3 {
+		t.Errorf("get() = %v, wanted queued with priority 3", state)
+	}
+
+	states, err := insp.list(ctx, "repo/")
+	if err != nil {
+		t.Fatalf("list() = %v", err)
+	}
+	if len(states) != 1 || states[0].GetKey() != "repo/a" {
+		t.Errorf("list() = %v, wanted repo/a", states)
+	}
+
+	if err := insp.requeue(ctx, "repo/a", 0); !errors.Is(err, errRemoteUnsupported) {
+		t.Errorf("requeue() = %v, wanted %v", err, errRemoteUnsupported)
+	}
+	if err := insp.deadletter(ctx, "repo/a", ""); !errors.Is(err, errRemoteUnsupported) {
+		t.Errorf("deadletter() = %v, wanted %v", err, errRemoteUnsupported)
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	wq, err := fs.NewWorkQueue(dir, 1)
+	if err != nil {
+		t.Fatalf("fs.NewWorkQueue() = %v", err)
+	}
+	store, err := disk.New(dir)
+	if err != nil {
+		t.Fatalf("disk.New() = %v", err)
+	}
+	sched := schedule.New(wq, store)
+	if err := sched.Register(ctx, schedule.Schedule{Key: "repo/nightly", Cron: "@daily", Priority: 2}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	// Directly against the queue's directory.
+	var out bytes.Buffer
+	if err := run(ctx, []string{"get", "-dir", dir, "repo/nightly"}, &out); err != nil {
+		t.Fatalf("run(get) = %v", err)
+	}
+	if !strings.Contains(out.String(), "@daily (priority 2)") {
+		t.Errorf("get printed:\n%s\nwanted the key's schedule", out.String())
+	}
+
+	// Through a server answering GetKeyState with the Scheduler.
+	state, err := (&remote{client: &fakeService{wq: sched}}).get(ctx, "repo/nightly")
+	if err != nil {
+		t.Fatalf("get() = %v", err)
+	}
+	if state.GetSchedule().GetSpec() != "@daily" {
+		t.Errorf("get() = %v, wanted the key's schedule", state)
+	}
+}
+
+func TestRun_usage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"bogus"},
+		{"get"},
+		{"get", "-dir", "/tmp/q"},
+		{"list", "a", "b"},
+		{"get", "-bucket", "b", "-dir", "/tmp/q", "key"},
+	} {
+		if err := run(t.Context(), args, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q) succeeded, wanted a usage error", args)
+		}
+	}
+}
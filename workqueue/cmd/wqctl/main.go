@@ -0,0 +1,221 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command wqctl inspects and operates on a workqueue, one subcommand per
+// operation:
+//
+//	wqctl list [prefix]      — list the queued, in-progress and dead-lettered
+//	                           keys (including keys whose NotBefore is in the
+//	                           future) with their priority, NotBefore,
+//	                           attempts and owner.
+//	wqctl get <key>          — show everything recorded about a single key.
+//	wqctl enqueue <key>      — queue a key (-priority, -delay).
+//	wqctl requeue <key>      — return an in-progress key to the queue (-delay).
+//	wqctl deadletter <key>   — dead-letter an in-progress key (-reason).
+//
+// Every subcommand takes exactly one of:
+//
+//	-bucket <name>      the GCS bucket of a gcs workqueue
+//	-dir <path>         the directory of an fs workqueue
+//	-endpoint <url>     a WorkqueueService endpoint, such as a hyperqueue
+//
+// Against -bucket or -dir, get also reports the key's schedule when a
+// schedule.Scheduler persists schedules beside the queue, under
+// -schedule-prefix (schedule's default, "schedules/", unless set). Against an
+// endpoint, the server reports schedules when it answers GetKeyState through
+// a Scheduler.
+//
+// Against an endpoint, list needs the server to implement ListKeys, and
+// requeue and deadletter are unavailable: the RPCs do not expose in-progress
+// keys, so reach the backend directly instead.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"chainguard.dev/driftlessaf/store/blob/disk"
+	blobgcs "chainguard.dev/driftlessaf/store/blob/gcs"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/fs"
+	"chainguard.dev/driftlessaf/workqueue/gcs"
+)
+
+const usage = `usage: wqctl <command> [flags] [args]
+
+commands:
+  list [prefix]     list keys
+  get <key>         show a single key
+  enqueue <key>     queue a key
+  requeue <key>     return an in-progress key to the queue
+  deadletter <key>  dead-letter an in-progress key
+
+Run "wqctl <command> -h" for the flags of a command.
+`
+
+// target holds the flags selecting the workqueue to operate on.
+type target struct {
+	bucket         string
+	dir            string
+	endpoint       string
+	schedulePrefix string
+}
+
+func (t *target) register(fset *flag.FlagSet) {
+	fset.StringVar(&t.bucket, "bucket", "", "GCS bucket of a gcs workqueue")
+	fset.StringVar(&t.dir, "dir", "", "directory of an fs workqueue")
+	fset.StringVar(&t.endpoint, "endpoint", "", "WorkqueueService endpoint URL")
+	fset.StringVar(&t.schedulePrefix, "schedule-prefix", "schedules/", "prefix the queue's schedules are stored under, with -bucket or -dir")
+}
+
+// open connects to the selected workqueue. The returned func releases it.
+func (t *target) open(ctx context.Context) (inspector, func() error, error) {
+	set := 0
+	for _, v := range []string{t.bucket, t.dir, t.endpoint} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, nil, errors.New("exactly one of -bucket, -dir or -endpoint is required")
+	}
+
+	// The limit only bounds the queued keys Enumerate returns, which wqctl
+	// never starts.
+	const limit = 1
+	switch {
+	case t.bucket != "":
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("storage.NewClient: %w", err)
+		}
+		bucket := client.Bucket(t.bucket)
+		return newLocal(gcs.NewWorkQueue(bucket, limit), blobgcs.New(bucket), t.schedulePrefix), client.Close, nil
+	case t.dir != "":
+		wq, err := fs.NewWorkQueue(t.dir, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fs.NewWorkQueue: %w", err)
+		}
+		store, err := disk.New(t.dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("disk.New: %w", err)
+		}
+		return newLocal(wq, store, t.schedulePrefix), func() error { return nil }, nil
+	default:
+		client, err := workqueue.NewWorkqueueClient(ctx, t.endpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &remote{client: client}, client.Close, nil
+	}
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "wqctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the subcommand named by args[0].
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	cmd, args := args[0], args[1:]
+
+	var t target
+	req := request{cmd: cmd}
+	fset := flag.NewFlagSet("wqctl "+cmd, flag.ContinueOnError)
+	t.register(fset)
+	switch cmd {
+	case "list", "get":
+	case "enqueue":
+		fset.Int64Var(&req.priority, "priority", 0, "priority of the key; higher runs first")
+		fset.DurationVar(&req.delay, "delay", 0, "how long to wait before the key may run")
+	case "requeue":
+		fset.DurationVar(&req.delay, "delay", 0, "how long to wait before the key may run again (default: the backend's backoff)")
+	case "deadletter":
+		fset.StringVar(&req.reason, "reason", "", "error to record as the cause")
+	default:
+		return fmt.Errorf("unknown command %q\n%s", cmd, usage)
+	}
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case cmd == "list" && fset.NArg() > 1:
+		return errors.New("usage: wqctl list [flags] [prefix]")
+	case cmd != "list" && fset.NArg() != 1:
+		return fmt.Errorf("usage: wqctl %s [flags] <key>", cmd)
+	}
+	req.key = fset.Arg(0)
+
+	insp, closer, err := t.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer closer() //nolint:errcheck
+
+	return execute(ctx, insp, req, stdout)
+}
+
+// request is a parsed subcommand.
+type request struct {
+	cmd string
+	// key is the key operated on, or the prefix to list.
+	key      string
+	priority int64
+	delay    time.Duration
+	reason   string
+}
+
+// execute runs req against insp, writing its output to stdout.
+func execute(ctx context.Context, insp inspector, req request, stdout io.Writer) error {
+	key := req.key
+	switch req.cmd {
+	case "list":
+		states, err := insp.list(ctx, key)
+		if err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+		return printList(stdout, states, time.Now())
+	case "get":
+		state, err := insp.get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("get %q: %w", key, err)
+		}
+		return printState(stdout, state, time.Now())
+	case "enqueue":
+		if err := insp.enqueue(ctx, key, req.priority, req.delay); err != nil {
+			return fmt.Errorf("enqueue %q: %w", key, err)
+		}
+		fmt.Fprintf(stdout, "Queued %q\n", key)
+	case "requeue":
+		if err := insp.requeue(ctx, key, req.delay); err != nil {
+			return fmt.Errorf("requeue %q: %w", key, err)
+		}
+		fmt.Fprintf(stdout, "Requeued %q\n", key)
+	case "deadletter":
+		if err := insp.deadletter(ctx, key, req.reason); err != nil {
+			return fmt.Errorf("deadletter %q: %w", key, err)
+		}
+		fmt.Fprintf(stdout, "Dead-lettered %q\n", key)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package conformance
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestDeadLetterAdmin tests the workqueue.Admin implementation of a workqueue:
+// inspecting, replaying and purging dead-lettered keys. The workqueues
+// returned by ctor must implement workqueue.Admin.
+func TestDeadLetterAdmin(t *testing.T, ctor func(int) workqueue.Interface) {
+	ct := &conformanceTester{
+		t:           t,
+		ctor:        ctor,
+		concurrency: 5,
+	}
+
+	ct.adminScenario("replay keeps priority and resets attempts", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		deadletter(ctx, t, wq, "foo", 5)
+
+		state, err := wq.Get(ctx, "foo")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if state.GetStatus() != workqueue.KeyState_DEAD_LETTER {
+			t.Fatalf("Expected status DEAD_LETTER, got %v", state.GetStatus())
+		}
+		checkDeadLetters(t, wq, []string{"foo"})
+
+		if err := wq.ReplayDeadLetter(ctx, "foo", workqueue.Options{}); err != nil {
+			t.Fatalf("ReplayDeadLetter failed: %v", err)
+		}
+		checkDeadLetters(t, wq, nil)
+
+		_, qd := checkQueue(t, wq, ExpectedState{
+			Queued: []string{"foo"},
+		})
+		if got := qd[0].Priority(); got != 5 {
+			t.Errorf("Expected priority 5, got %d", got)
+		}
+
+		owned, err := qd[0].Start(ctx)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		if got := owned.GetAttempts(); got != 1 {
+			t.Errorf("Expected attempt count 1 after replay, got %d", got)
+		}
+		if err := owned.Complete(ctx); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+
+		// The key is no longer dead-lettered, so replaying it again fails.
+		if err := wq.ReplayDeadLetter(ctx, "foo", workqueue.Options{}); status.Code(err) != codes.NotFound {
+			t.Errorf("ReplayDeadLetter = %v, wanted NotFound", err)
+		}
+	})
+
+	ct.adminScenario("replay with priority", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		deadletter(ctx, t, wq, "foo", 5)
+
+		if err := wq.ReplayDeadLetter(ctx, "foo", workqueue.Options{Priority: 10}); err != nil {
+			t.Fatalf("ReplayDeadLetter failed: %v", err)
+		}
+
+		_, qd := checkQueue(t, wq, ExpectedState{
+			Queued: []string{"foo"},
+		})
+		if got := qd[0].Priority(); got != 10 {
+			t.Errorf("Expected priority 10, got %d", got)
+		}
+	})
+
+	ct.adminScenario("replay with delay", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		deadletter(ctx, t, wq, "foo", 0)
+
+		if err := wq.ReplayDeadLetter(ctx, "foo", workqueue.Options{
+			NotBefore: time.Now().Add(workqueue.BackoffPeriod),
+		}); err != nil {
+			t.Fatalf("ReplayDeadLetter failed: %v", err)
+		}
+
+		// The key shouldn't appear until the delay has passed.
+		_, _ = checkQueue(t, wq, ExpectedState{})
+		checkDeadLetters(t, wq, nil)
+
+		time.Sleep(workqueue.BackoffPeriod)
+
+		_, _ = checkQueue(t, wq, ExpectedState{
+			Queued: []string{"foo"},
+		})
+	})
+
+	ct.adminScenario("purge", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		deadletter(ctx, t, wq, "foo", 0)
+
+		if err := wq.PurgeDeadLetter(ctx, "foo"); err != nil {
+			t.Fatalf("PurgeDeadLetter failed: %v", err)
+		}
+		checkDeadLetters(t, wq, nil)
+
+		if _, err := wq.Get(ctx, "foo"); status.Code(err) != codes.NotFound {
+			t.Errorf("Get = %v, wanted NotFound", err)
+		}
+		if err := wq.PurgeDeadLetter(ctx, "foo"); status.Code(err) != codes.NotFound {
+			t.Errorf("PurgeDeadLetter = %v, wanted NotFound", err)
+		}
+	})
+
+	ct.adminScenario("bulk replay and purge", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		start := time.Now().Add(-time.Minute)
+		// Distinct priorities keep the replayed keys in a stable queue order.
+		deadletter(ctx, t, wq, "a-1", 2)
+		deadletter(ctx, t, wq, "a-2", 1)
+		deadletter(ctx, t, wq, "b-1", 0)
+
+		keys, err := workqueue.ReplayDeadLetters(ctx, wq, workqueue.DeadLetterFilter{Prefix: "a-"}, workqueue.Options{})
+		if err != nil {
+			t.Fatalf("ReplayDeadLetters failed: %v", err)
+		}
+		slices.Sort(keys)
+		if diff := cmp.Diff([]string{"a-1", "a-2"}, keys); diff != "" {
+			t.Errorf("Unexpected replayed keys (-want, +got):\n%s", diff)
+		}
+		checkDeadLetters(t, wq, []string{"b-1"})
+		_, _ = checkQueue(t, wq, ExpectedState{
+			Queued: []string{"a-1", "a-2"},
+		})
+
+		// A window that ends before anything failed selects nothing.
+		keys, err = workqueue.PurgeDeadLetters(ctx, wq, workqueue.DeadLetterFilter{FailedBefore: start})
+		if err != nil {
+			t.Fatalf("PurgeDeadLetters failed: %v", err)
+		}
+		if len(keys) != 0 {
+			t.Errorf("Expected no purged keys, got %v", keys)
+		}
+		checkDeadLetters(t, wq, []string{"b-1"})
+
+		keys, err = workqueue.PurgeDeadLetters(ctx, wq, workqueue.DeadLetterFilter{FailedAfter: start})
+		if err != nil {
+			t.Fatalf("PurgeDeadLetters failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"b-1"}, keys); diff != "" {
+			t.Errorf("Unexpected purged keys (-want, +got):\n%s", diff)
+		}
+		checkDeadLetters(t, wq, nil)
+	})
+}
+
+// adminScenario is like scenario, but also starts and ends with an empty
+// dead-letter queue, which drain leaves untouched.
+func (ct *conformanceTester) adminScenario(name string, f func(context.Context, *testing.T, workqueue.AdminInterface)) {
+	ct.scenario(name, func(ctx context.Context, t *testing.T, wq workqueue.Interface) {
+		awq, ok := wq.(workqueue.AdminInterface)
+		if !ok {
+			t.Fatalf("%T does not implement workqueue.Admin", wq)
+		}
+		purge := func() {
+			if _, err := workqueue.PurgeDeadLetters(context.WithoutCancel(ctx), awq, workqueue.DeadLetterFilter{}); err != nil {
+				t.Fatalf("PurgeDeadLetters failed: %v", err)
+			}
+		}
+		purge()
+		t.Cleanup(purge)
+
+		f(ctx, t, awq)
+	})
+}
+
+// deadletter queues key with the given priority, starts it and dead-letters it.
+func deadletter(ctx context.Context, t *testing.T, wq workqueue.Interface, key string, priority int64) {
+	t.Helper()
+	if err := wq.Queue(ctx, key, workqueue.Options{Priority: priority}); err != nil {
+		t.Fatalf("Queue failed: %v", err)
+	}
+	_, qd, _, err := wq.Enumerate(ctx)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+	idx := slices.IndexFunc(qd, func(k workqueue.QueuedKey) bool { return k.Name() == key })
+	if idx < 0 {
+		t.Fatalf("Key %q is not queued", key)
+	}
+	owned, err := qd[idx].Start(ctx)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := owned.Deadletter(ctx); err != nil {
+		t.Fatalf("Deadletter failed: %v", err)
+	}
+}
+
+// checkDeadLetters checks the names of the dead-lettered keys, in any order.
+func checkDeadLetters(t *testing.T, wq workqueue.Interface, want []string) {
+	t.Helper()
+	_, _, dl, err := wq.Enumerate(t.Context())
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+	var got []string //nolint: prealloc
+	for _, k := range dl {
+		got = append(got, k.Name())
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Unexpected dead-lettered keys (-want, +got):\n%s", diff)
+	}
+}
@@ -7,7 +7,10 @@ package conformance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -1180,4 +1183,116 @@ func TestMaxRetry(t *testing.T, ctor func(int) workqueue.Interface) {
 		time.Sleep(2 * workqueue.BackoffPeriod)
 		_, _ = checkQueue(t, wq, ExpectedState{})
 	})
+
+	ct.scenario("dead-letter records failure details", func(ctx context.Context, t *testing.T, wq workqueue.Interface) {
+		if err := wq.Queue(ctx, "bar", workqueue.Options{}); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+		_, qd := checkQueue(t, wq, ExpectedState{
+			Queued: []string{"bar"},
+		})
+		owned, err := qd[0].Start(ctx)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		// Fail the first attempt, recording its error.
+		if err := owned.RequeueWithOptions(ctx, workqueue.Options{
+			BackoffDelay: workqueue.BackoffPeriod / 2,
+			AttemptError: errors.New("first attempt"),
+		}); err != nil {
+			t.Fatalf("RequeueWithOptions failed: %v", err)
+		}
+		time.Sleep(workqueue.BackoffPeriod)
+
+		state, err := wq.Get(ctx, "bar")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"first attempt"}, state.GetAttemptErrors()); diff != "" {
+			t.Errorf("Unexpected queued attempt errors (-want, +got):\n%s", diff)
+		}
+
+		_, qd = checkQueue(t, wq, ExpectedState{
+			Queued: []string{"bar"},
+		})
+		owned, err = qd[0].Start(ctx)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		// Dead-letter the second attempt with a non-retriable infrastructure error.
+		cause := workqueue.NonRetriableError(status.Error(codes.Unavailable, "second attempt"), "gave up")
+		if err := owned.DeadletterWithError(ctx, cause); err != nil {
+			t.Fatalf("DeadletterWithError failed: %v", err)
+		}
+		_, _ = checkQueue(t, wq, ExpectedState{})
+
+		want := workqueue.FailureDetails{
+			LastError:      cause.Error(),
+			Reason:         "gave up",
+			Infrastructure: true,
+			AttemptErrors:  []string{"first attempt", cause.Error()},
+		}
+
+		_, _, dl, err := wq.Enumerate(ctx)
+		if err != nil {
+			t.Fatalf("Enumerate failed: %v", err)
+		}
+		idx := slices.IndexFunc(dl, func(k workqueue.DeadLetteredKey) bool { return k.Name() == "bar" })
+		if idx < 0 {
+			t.Fatal("Key \"bar\" is not dead-lettered")
+		}
+		if diff := cmp.Diff(want, dl[idx].GetFailureDetails()); diff != "" {
+			t.Errorf("Unexpected failure details (-want, +got):\n%s", diff)
+		}
+
+		state, err = wq.Get(ctx, "bar")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		got := workqueue.FailureDetails{
+			LastError:      state.GetLastError(),
+			Reason:         state.GetFailureReason(),
+			Infrastructure: state.GetInfrastructureError(),
+			AttemptErrors:  state.GetAttemptErrors(),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Unexpected key state failure details (-want, +got):\n%s", diff)
+		}
+	})
+
+	ct.scenario("dead-letter truncates long errors", func(ctx context.Context, t *testing.T, wq workqueue.Interface) {
+		if err := wq.Queue(ctx, "baz", workqueue.Options{}); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+		_, qd := checkQueue(t, wq, ExpectedState{
+			Queued: []string{"baz"},
+		})
+		owned, err := qd[0].Start(ctx)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		long := strings.Repeat("x", workqueue.MaxAttemptErrorLength+10)
+		if err := owned.DeadletterWithError(ctx, workqueue.NonRetriableError(errors.New(long), long)); err != nil {
+			t.Fatalf("DeadletterWithError failed: %v", err)
+		}
+
+		state, err := wq.Get(ctx, "baz")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if got := len(state.GetLastError()); got != workqueue.MaxAttemptErrorLength {
+			t.Errorf("LastError: got %d bytes, want %d", got, workqueue.MaxAttemptErrorLength)
+		}
+		if got := len(state.GetFailureReason()); got != workqueue.MaxAttemptErrorLength {
+			t.Errorf("FailureReason: got %d bytes, want %d", got, workqueue.MaxAttemptErrorLength)
+		}
+		for _, msg := range state.GetAttemptErrors() {
+			if len(msg) > workqueue.MaxAttemptErrorLength {
+				t.Errorf("AttemptErrors: got a %d byte entry, want at most %d", len(msg), workqueue.MaxAttemptErrorLength)
+			}
+		}
+	})
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestList tests the workqueue.Lister implementation of a workqueue. The
+// workqueues returned by ctor must implement both workqueue.Lister and
+// workqueue.Admin.
+func TestList(t *testing.T, ctor func(int) workqueue.Interface) {
+	ct := &conformanceTester{
+		t:    t,
+		ctor: ctor,
+		// A limit of one shows that List is not capped the way Enumerate is.
+		concurrency: 1,
+	}
+
+	ct.adminScenario("list reports every key", func(ctx context.Context, t *testing.T, wq workqueue.AdminInterface) {
+		lwq, ok := wq.(workqueue.Lister)
+		if !ok {
+			t.Fatalf("%T does not implement workqueue.Lister", wq)
+		}
+
+		deadletter(ctx, t, wq, "a/dead", 1)
+		if err := wq.Queue(ctx, "a/running", workqueue.Options{Priority: 3}); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+		_, qd := checkQueue(t, wq, ExpectedState{Queued: []string{"a/running"}})
+		owned, err := qd[0].Start(ctx)
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		t.Cleanup(func() {
+			if err := owned.Complete(context.WithoutCancel(ctx)); err != nil {
+				t.Errorf("Complete failed: %v", err)
+			}
+		})
+
+		// Enumerate hides delayed keys; List does not. Wait out the delay
+		// before the scenario drains the queue, so it starts the next one
+		// empty.
+		notBefore := time.Now().Add(2 * time.Second)
+		if err := wq.Queue(ctx, "a/delayed", workqueue.Options{Priority: 2, NotBefore: notBefore}); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+		t.Cleanup(func() { time.Sleep(time.Until(notBefore)) })
+		for _, key := range []string{"a/queued", "b/queued"} {
+			if err := wq.Queue(ctx, key, workqueue.Options{}); err != nil {
+				t.Fatalf("Queue failed: %v", err)
+			}
+		}
+
+		type entry struct {
+			Key      string
+			Status   workqueue.KeyState_Status
+			Priority int64
+			Delayed  bool
+		}
+		list := func(prefix string) []entry {
+			t.Helper()
+			states, err := lwq.List(ctx, prefix)
+			if err != nil {
+				t.Fatalf("List(%q) failed: %v", prefix, err)
+			}
+			workqueue.SortKeyStates(states)
+			got := make([]entry, 0, len(states))
+			for _, s := range states {
+				got = append(got, entry{
+					Key:      s.GetKey(),
+					Status:   s.GetStatus(),
+					Priority: s.GetPriority(),
+					Delayed:  s.GetNotBeforeTime() > time.Now().Unix(),
+				})
+			}
+			return got
+		}
+
+		want := []entry{
+			{Key: "a/running", Status: workqueue.KeyState_IN_PROGRESS, Priority: 3},
+			{Key: "a/delayed", Status: workqueue.KeyState_QUEUED, Priority: 2, Delayed: true},
+			{Key: "a/queued", Status: workqueue.KeyState_QUEUED},
+			{Key: "a/dead", Status: workqueue.KeyState_DEAD_LETTER, Priority: 1},
+		}
+		if diff := cmp.Diff(want, list("a/")); diff != "" {
+			t.Errorf("List(a/) (-want, +got):\n%s", diff)
+		}
+		if got := list(""); len(got) != len(want)+1 {
+			t.Errorf("List() returned %d keys, wanted %d: %v", len(got), len(want)+1, got)
+		}
+		if got := list("c/"); len(got) != 0 {
+			t.Errorf("List(c/) = %v, wanted no keys", got)
+		}
+	})
+}
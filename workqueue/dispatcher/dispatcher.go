@@ -111,15 +111,63 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 	openSlots := concurrency - nWIP
 	launchLimit := min(batchSize, openSlots)
 
-	idx, launched := 0, 0
-	for ; idx < len(next) && launched < launchLimit; idx++ {
+	// Count in-flight keys against their concurrency groups (if configured),
+	// so keys whose group is saturated are left in the queue.
+	groups := cfg.groups.newCounts(activeKeys)
+
+	idx, launched, saturated := 0, 0, 0
+	throttled := map[string]int{}
+	now := time.Now()
+	considered := make(map[string]struct{}, len(next))
+	skipped, round := false, 0
+	for ; launched < launchLimit; idx++ {
+		// Enumerate only returns the head of the queue, so skipped keys may
+		// use up every candidate while keys that could launch wait behind
+		// them. When the candidates run out, look further down the queue.
+		if idx == len(next) {
+			more, ok := overfetch(ctx, wq, next, skipped, round)
+			if !ok {
+				break
+			}
+			next, idx, skipped = more, 0, false
+			round++
+		}
 		nextKey := next[idx]
 
+		// An over-fetched list repeats the keys already considered.
+		if _, ok := considered[nextKey.Name()]; ok {
+			continue
+		}
+		considered[nextKey.Name()] = struct{}{}
+
 		// If the next key is already in progress, then move to the next candidate.
 		if _, ok := activeKeys[nextKey.Name()]; ok {
 			continue
 		}
 
+		// If the next key's concurrency group is full, leave it queued (without
+		// burning an attempt) and move to the next candidate.
+		if !groups.admit(nextKey.Name()) {
+			saturated++
+			skipped = true
+			continue
+		}
+
+		// If a rate limit has no budget for the next key, leave it queued as
+		// well. Once the global budget is spent, no further key can launch this
+		// pass, so count every open slot as throttled and stop.
+		res, scope, ok := cfg.limits.reserve(nextKey.Name(), now)
+		if !ok {
+			groups.release(nextKey.Name())
+			if scope == throttleGlobal {
+				throttled[scope] += launchLimit - launched
+				break
+			}
+			throttled[scope]++
+			skipped = true
+			continue
+		}
+
 		// At this point, we know that nextKey gets launched.  There are two paths below:
 		// 1. One is where we lose the race and someone else launches it, and
 		// 2. The other is where we launch it.
@@ -133,6 +181,8 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 			oip, err := nextKey.Start(ctx)
 			if err != nil {
 				clog.DebugContextf(ctx, "Failed to start key %q: %v", nextKey.Name(), err)
+				// Nothing launched, so return the rate-limit tokens.
+				res.cancel()
 				return nil
 			}
 
@@ -197,12 +247,13 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 				clog.WarnContextf(ctx, "Failed callback for key %q: %v", oip.Name(), err)
 				attempts := oip.GetAttempts()
 
-				// If maxRetry is configured and we've reached or exceeded it, use Deadletter() instead of Requeue()
+				// If maxRetry is configured and we've reached or exceeded it, dead-letter
+				// the key (recording err as the cause) instead of requeueing it.
 				if maxRetry > 0 && attempts >= maxRetry {
 					clog.InfoContextf(ctx, "Key %q has reached max retry limit (%d/%d), failing permanently",
 						oip.Name(), attempts, maxRetry)
 
-					if err := oip.Deadletter(cleanupCtx); err != nil {
+					if err := oip.DeadletterWithError(cleanupCtx, err); err != nil {
 						return fmt.Errorf("fail(after reaching max retries) = %w", err)
 					}
 					cfg.errors.emit(cleanupCtx, ErrorContext{
@@ -250,7 +301,7 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 					}
 					infra := workqueue.IsInfrastructureError(err)
 					clog.InfoContextf(ctx, "Key %q failed (attempt %d, infrastructure=%t), requeueing with %v backoff", oip.Name(), attempts, infra, delay)
-					if err := oip.RequeueWithOptions(cleanupCtx, workqueue.Options{BackoffDelay: delay}); err != nil {
+					if err := oip.RequeueWithOptions(cleanupCtx, workqueue.Options{BackoffDelay: delay, AttemptError: err}); err != nil {
 						return fmt.Errorf("requeue(after failed callback) = %w", err)
 					}
 					cfg.errors.emit(cleanupCtx, ErrorContext{
@@ -272,7 +323,10 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 			return nil
 		})
 	}
-	clog.InfoContextf(ctx, "Launched %d new keys (wip: %d, batch: %d)", launched, nWIP, batchSize)
+	countThrottled(throttleGlobal, throttled[throttleGlobal])
+	countThrottled(throttleGroup, throttled[throttleGroup])
+	clog.InfoContextf(ctx, "Launched %d new keys (wip: %d, batch: %d, group-saturated: %d, throttled: %d global, %d group)",
+		launched, nWIP, batchSize, saturated, throttled[throttleGlobal], throttled[throttleGroup])
 
 	// Return the future to wait on outstanding work, then drain any
 	// in-flight error events so they are not lost on shutdown.
@@ -283,6 +337,28 @@ func HandleAsync(ctx context.Context, wq workqueue.Interface, concurrency, batch
 	}
 }
 
+// maxOverfetchRounds bounds how many times a dispatch pass doubles the queued
+// keys it considers in order to look past keys it had to skip.
+const maxOverfetchRounds = 3
+
+// overfetch returns more of the queue than next, for a pass that ran out of
+// candidates. It reports false when the pass should stop instead: when no
+// candidate was skipped (so next was the whole eligible queue, or every
+// candidate launched), when wq cannot enumerate past its limit, when the
+// rounds are used up, or when the queue holds nothing beyond next.
+func overfetch(ctx context.Context, wq workqueue.Interface, next []workqueue.QueuedKey, skipped bool, round int) ([]workqueue.QueuedKey, bool) {
+	le, ok := wq.(workqueue.LimitEnumerator)
+	if !ok || !skipped || round >= maxOverfetchRounds {
+		return nil, false
+	}
+	_, more, _, err := le.EnumerateN(ctx, 2*len(next))
+	if err != nil {
+		clog.WarnContextf(ctx, "Failed to over-fetch queued keys: %v", err)
+		return nil, false
+	}
+	return more, len(more) > len(next)
+}
+
 // retryBackoff returns the base backoff for a failed dispatch on the given
 // attempt: workqueue.BackoffPeriod doubling per recorded attempt, capped at
 // workqueue.MaximumBackoffPeriod. Attempt counts below one (possible when a
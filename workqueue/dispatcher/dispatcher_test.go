@@ -29,6 +29,8 @@ type mockKey struct {
 	requeue  int
 	dead     int
 	complete int
+	// deadErr captures the cause passed to the last DeadletterWithError.
+	deadErr error
 	// bareRequeue counts calls to Requeue (no options). requeueOpts counts
 	// calls to RequeueWithOptions and captures the last options passed, so a
 	// test can assert which requeue path the dispatcher took.
@@ -87,10 +89,14 @@ func (m *mockInProgressKey) Complete(context.Context) error {
 	m.complete++
 	return nil
 }
-func (m *mockInProgressKey) Deadletter(context.Context) error {
+func (m *mockInProgressKey) Deadletter(ctx context.Context) error {
+	return m.DeadletterWithError(ctx, nil)
+}
+func (m *mockInProgressKey) DeadletterWithError(_ context.Context, err error) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.dead++
+	m.deadErr = err
 	return nil
 }
 
@@ -242,6 +248,9 @@ func TestHandleAsync_DefaultBackoff(t *testing.T) {
 	if next.lastReqOpts.Delay != 0 {
 		t.Errorf("Delay: got = %v, want = 0 (backoff must not reset attempts)", next.lastReqOpts.Delay)
 	}
+	if err := next.lastReqOpts.AttemptError; err == nil || err.Error() != "fail" {
+		t.Errorf("AttemptError: got = %v, want = fail", err)
+	}
 }
 
 // TestHandleAsync_WithBackoff verifies the WithBackoff hook replaces the
@@ -312,6 +321,9 @@ func TestHandleAsync_CallbackFails_DeadletterOnMaxRetry(t *testing.T) {
 	if next.dead != 1 {
 		t.Errorf("expected Deadletter to be called")
 	}
+	if next.deadErr == nil || next.deadErr.Error() != "fail" {
+		t.Errorf("Deadletter cause: got = %v, want = fail", next.deadErr)
+	}
 }
 
 func TestHandleAsync_CallbackFails_NonRetriable(t *testing.T) {
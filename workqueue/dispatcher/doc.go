@@ -47,4 +47,37 @@ SPDX-License-Identifier: Apache-2.0
 // The hook is called with the key's current attempt count on each requeued
 // failure; a positive return value replaces the default delay, and a nil
 // hook or non-positive return keeps the default curve.
+//
+// # Concurrency Groups
+//
+// Beyond the overall concurrency limit, [WithConcurrencyGroups] bounds how
+// many keys of the same group are in flight at once — for example at most
+// one key per repository, so agents don't fight over the same branch, or at
+// most a few per GitHub organization, to stay within an installation's rate
+// limit:
+//
+//	handler := dispatcher.Handler(wq, 10, 5, callback, 3,
+//	    dispatcher.WithConcurrencyGroups(orgOf, func(string) int { return 3 }),
+//	)
+//
+// Queued keys whose group is saturated are skipped and stay queued, without
+// consuming an attempt, until a later dispatch pass finds room.
+//
+// # Rate Limits
+//
+// For throughput-limited downstream APIs, [WithRateLimit] and
+// [WithGroupRateLimits] gate key launches on token buckets — one shared by
+// every key and one per key group — so each pass launches only as many keys
+// as the budget allows:
+//
+//	handler := dispatcher.Handler(wq, 10, 5, callback, 3,
+//	    dispatcher.WithRateLimit(rate.Limit(20), 20),
+//	    dispatcher.WithGroupRateLimits(apiOf, func(api string) (rate.Limit, int) {
+//	        return rate.Limit(5), 5
+//	    }),
+//	)
+//
+// Throttled keys stay queued without consuming an attempt. Deferred launches
+// are counted by the workqueue_dispatch_throttled_total metric, labeled by
+// the scope of the bucket that throttled them.
 package dispatcher
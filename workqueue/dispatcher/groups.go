@@ -0,0 +1,92 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+// WithConcurrencyGroups bounds how many keys of the same concurrency group
+// are in flight at once, on top of the dispatcher's overall concurrency.
+//
+// group maps a key to its group name (for example, the repository or GitHub
+// organization the key belongs to); keys mapped to "" are ungrouped and only
+// subject to the overall limit. limit returns the maximum number of
+// in-flight keys for a group; a non-positive limit leaves the group
+// unbounded. For example, to process at most one key per repository:
+//
+//	dispatcher.WithConcurrencyGroups(repoOf, func(string) int { return 1 })
+//
+// A dispatch pass counts the group of every in-progress key, including those
+// owned by other dispatchers, and skips queued keys whose group is
+// saturated. Skipped keys stay queued untouched, so they keep their place
+// and attempt count and are picked up by a later pass once the group has
+// room. If skipped keys fill the head of the queue that Enumerate returns, a
+// pass looks further down queues that implement workqueue.LimitEnumerator,
+// so a saturated group cannot starve the groups queued behind it.
+func WithConcurrencyGroups(group func(key string) string, limit func(group string) int) Option {
+	return func(c *config) {
+		if group == nil || limit == nil {
+			c.groups = nil
+			return
+		}
+		c.groups = &concurrencyGroups{group: group, limit: limit}
+	}
+}
+
+// concurrencyGroups tracks the in-flight keys of each group during a single
+// dispatch pass.
+type concurrencyGroups struct {
+	group func(key string) string
+	limit func(group string) int
+}
+
+// groupCounts is the number of in-flight keys per group within a pass.
+type groupCounts struct {
+	*concurrencyGroups
+	inflight map[string]int
+}
+
+// newCounts returns the group counts for a pass, seeded with the names of the
+// keys already in flight. It is nil-safe: a nil receiver yields counts that
+// admit every key.
+func (g *concurrencyGroups) newCounts(active map[string]struct{}) groupCounts {
+	gc := groupCounts{concurrencyGroups: g}
+	if g == nil {
+		return gc
+	}
+	gc.inflight = make(map[string]int, len(active))
+	for key := range active {
+		if name := g.group(key); name != "" {
+			gc.inflight[name]++
+		}
+	}
+	return gc
+}
+
+// admit reports whether key's group has room for another in-flight key, and
+// if so counts key against it.
+func (gc groupCounts) admit(key string) bool {
+	if gc.concurrencyGroups == nil {
+		return true
+	}
+	name := gc.group(key)
+	if name == "" {
+		return true
+	}
+	if limit := gc.limit(name); limit > 0 && gc.inflight[name] >= limit {
+		return false
+	}
+	gc.inflight[name]++
+	return true
+}
+
+// release returns the slot admit counted for key, for a key that was admitted
+// but not launched after all.
+func (gc groupCounts) release(key string) {
+	if gc.concurrencyGroups == nil {
+		return
+	}
+	if name := gc.group(key); name != "" {
+		gc.inflight[name]--
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"github.com/google/go-cmp/cmp"
+)
+
+// repoOf groups keys of the form "<repo>/<item>" by repo, and leaves other
+// keys ungrouped.
+func repoOf(key string) string {
+	repo, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return ""
+	}
+	return repo
+}
+
+func TestHandleAsync_ConcurrencyGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		wip    []string
+		next   []string
+		limits map[string]int
+		want   []string
+	}{{
+		name: "one per group",
+		next: []string{"a/1", "a/2", "b/1", "b/2", "c/1"},
+		want: []string{"a/1", "b/1", "c/1"},
+	}, {
+		name:   "per-group limits",
+		next:   []string{"a/1", "a/2", "a/3", "b/1", "b/2"},
+		limits: map[string]int{"a": 2},
+		want:   []string{"a/1", "a/2", "b/1"},
+	}, {
+		name:   "unbounded group",
+		next:   []string{"a/1", "a/2", "a/3"},
+		limits: map[string]int{"a": 0},
+		want:   []string{"a/1", "a/2", "a/3"},
+	}, {
+		name: "in-flight keys count against their group",
+		wip:  []string{"a/1"},
+		next: []string{"a/2", "b/1"},
+		want: []string{"b/1"},
+	}, {
+		name: "ungrouped keys are not limited",
+		next: []string{"x", "y", "a/1", "a/2"},
+		want: []string{"x", "y", "a/1"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := make(map[string]*mockKey, len(tt.next))
+			q := &mockQueue{}
+			for _, name := range tt.wip {
+				q.wip = append(q.wip, &mockInProgressKey{mockKey: &mockKey{name: name}})
+			}
+			for _, name := range tt.next {
+				keys[name] = &mockKey{name: name}
+				q.next = append(q.next, keys[name])
+			}
+
+			var mu sync.Mutex
+			var got []string
+			future := HandleAsync(t.Context(), q, 10, 0, func(_ context.Context, key string, _ workqueue.Options) error {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, key)
+				return nil
+			}, 0, WithConcurrencyGroups(repoOf, func(group string) int {
+				if limit, ok := tt.limits[group]; ok {
+					return limit
+				}
+				return 1
+			}))
+			if err := future(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := map[string]bool{}
+			for _, name := range tt.want {
+				want[name] = true
+			}
+			gotSet := map[string]bool{}
+			for _, name := range got {
+				gotSet[name] = true
+			}
+			if diff := cmp.Diff(want, gotSet); diff != "" {
+				t.Errorf("launched keys (-want, +got):\n%s", diff)
+			}
+
+			// Skipped keys are left queued untouched: not started, requeued
+			// or failed, so they burn no attempts.
+			for name, k := range keys {
+				if want[name] {
+					continue
+				}
+				if k.requeue != 0 || k.dead != 0 || k.complete != 0 {
+					t.Errorf("key %q: requeue=%d dead=%d complete=%d, wanted it left alone", name, k.requeue, k.dead, k.complete)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleAsync_ConcurrencyGroupsDoNotConsumeSlots(t *testing.T) {
+	// With a batch of two, the saturated "a" keys must not take the slot
+	// that "b/1" can use.
+	next := []workqueue.QueuedKey{
+		&mockKey{name: "a/1"},
+		&mockKey{name: "a/2"},
+		&mockKey{name: "a/3"},
+		&mockKey{name: "b/1"},
+	}
+	q := &mockQueue{next: next}
+
+	var mu sync.Mutex
+	var got []string
+	future := HandleAsync(t.Context(), q, 10, 2, func(_ context.Context, key string, _ workqueue.Options) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, key)
+		return nil
+	}, 0, WithConcurrencyGroups(repoOf, func(string) int { return 1 }))
+	if err := future(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff([]string{"a/1", "b/1"}, got); diff != "" {
+		t.Errorf("launched keys (-want, +got):\n%s", diff)
+	}
+}
+
+func TestHandleAsync_ConcurrencyGroupsOverfetch(t *testing.T) {
+	// The queue only enumerates its top two keys, both of the saturated group
+	// "a"; the idle group "b" ranks behind them and must still launch.
+	ctx := t.Context()
+	wq := inmem.NewWorkQueue(2)
+	if err := wq.Queue(ctx, "a/0", workqueue.Options{Priority: 10}); err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	_, next, _, err := wq.Enumerate(ctx)
+	if err != nil {
+		t.Fatalf("Enumerate: %v", err)
+	}
+	if _, err := next[0].Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	for _, key := range []string{"a/1", "a/2", "a/3"} {
+		if err := wq.Queue(ctx, key, workqueue.Options{Priority: 5}); err != nil {
+			t.Fatalf("Queue: %v", err)
+		}
+	}
+	if err := wq.Queue(ctx, "b/1", workqueue.Options{}); err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+
+	var mu sync.Mutex
+	var got []string
+	future := HandleAsync(ctx, wq, 10, 0, func(_ context.Context, key string, _ workqueue.Options) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, key)
+		return nil
+	}, 0, WithConcurrencyGroups(repoOf, func(string) int { return 1 }))
+	if err := future(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"b/1"}, got); diff != "" {
+		t.Errorf("launched keys (-want, +got):\n%s", diff)
+	}
+}
@@ -45,3 +45,23 @@ func countTrigger(result string) {
 		"result":        result,
 	}).Inc()
 }
+
+var mThrottled = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "workqueue_dispatch_throttled_total",
+		Help: "The number of key launches a dispatch pass deferred because a rate limit was exhausted, by scope: global (WithRateLimit) or group (WithGroupRateLimits).",
+	},
+	[]string{"service_name", "revision_name", "scope"},
+)
+
+// countThrottled records n key launches deferred by the rate limit of scope.
+func countThrottled(scope string, n int) {
+	if n <= 0 {
+		return
+	}
+	mThrottled.With(prometheus.Labels{
+		"service_name":  metricServiceName,
+		"revision_name": metricRevisionName,
+		"scope":         scope,
+	}).Add(float64(n))
+}
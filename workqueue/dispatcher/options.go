@@ -83,6 +83,8 @@ type config struct {
 	errors         errorEmitter
 	backoff        func(attempts int) time.Duration
 	dispatchPeriod time.Duration
+	groups         *concurrencyGroups
+	limits         rateLimits
 }
 
 // WithDispatchPeriod sets the minimum interval between dispatch passes for
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit bounds the rate at which the dispatcher launches keys to
+// limit per second, with bursts of up to burst keys, using a token bucket
+// shared by every key. Each launch takes one token, which is returned if the
+// key cannot be started after all; a dispatch pass stops
+// launching once the bucket is empty and leaves the remaining keys queued,
+// without consuming an attempt, for a later pass.
+//
+// The bucket lives in the returned Option, so it is shared by every dispatch
+// pass the Option is passed to. Handler reuses its options across passes;
+// callers of Handle or HandleAsync should construct the Option once and pass
+// it on every call.
+func WithRateLimit(limit rate.Limit, burst int) Option {
+	l := rate.NewLimiter(limit, burst)
+	return func(c *config) { c.limits.global = l }
+}
+
+// WithGroupRateLimits bounds the rate at which the dispatcher launches keys
+// of each key group, with a token bucket per group. group maps a key to its
+// group name (for example, the downstream API or installation the key
+// calls); keys mapped to "" are only subject to WithRateLimit. limit returns
+// the rate and burst of a group's bucket, and is called once per group, the
+// first time a key of that group is dispatched; return rate.Inf to leave a
+// group unthrottled.
+//
+// Keys whose group bucket is empty are skipped and stay queued, without
+// consuming an attempt, while the pass goes on to launch keys of other
+// groups. As with WithRateLimit, the buckets live in the returned Option.
+func WithGroupRateLimits(group func(key string) string, limit func(group string) (rate.Limit, int)) Option {
+	g := &groupLimiters{
+		group:    group,
+		limit:    limit,
+		limiters: make(map[string]*rate.Limiter),
+	}
+	return func(c *config) { c.limits.groups = g }
+}
+
+// Throttle scopes recorded by mThrottled.
+const (
+	throttleGlobal = "global"
+	throttleGroup  = "group"
+)
+
+// rateLimits holds the token buckets that gate key launches. The zero value
+// admits every key.
+type rateLimits struct {
+	global *rate.Limiter
+	groups *groupLimiters
+}
+
+// reserve takes a token for launching key from its group's bucket and the
+// global bucket. If either is empty it takes nothing and returns the scope
+// of the bucket that throttled the key.
+func (rl rateLimits) reserve(key string, now time.Time) (res reservation, scope string, ok bool) {
+	res.at = now
+	if l := rl.groups.limiter(key); l != nil {
+		res.group = l.ReserveN(now, 1)
+		if !res.group.OK() || res.group.DelayFrom(now) > 0 {
+			res.group.CancelAt(now)
+			return reservation{}, throttleGroup, false
+		}
+	}
+	if rl.global != nil {
+		res.global = rl.global.ReserveN(now, 1)
+		if !res.global.OK() || res.global.DelayFrom(now) > 0 {
+			res.cancel()
+			return reservation{}, throttleGlobal, false
+		}
+	}
+	return res, "", true
+}
+
+// reservation is the tokens reserve took to launch a key.
+type reservation struct {
+	group, global *rate.Reservation
+	// at is when the tokens were taken. A token counts as spent once its
+	// reservation time has passed, so it must be returned as of then.
+	at time.Time
+}
+
+// cancel returns the tokens, for a key that did not launch after all (for
+// example, because another dispatcher started it first), so the buckets keep
+// their configured rate.
+func (r reservation) cancel() {
+	if r.group != nil {
+		r.group.CancelAt(r.at)
+	}
+	if r.global != nil {
+		r.global.CancelAt(r.at)
+	}
+}
+
+// groupLimiters lazily creates the token bucket of each key group.
+type groupLimiters struct {
+	group func(key string) string
+	limit func(group string) (rate.Limit, int)
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// limiter returns the bucket of key's group, or nil if key is ungrouped. It
+// is nil-safe.
+func (g *groupLimiters) limiter(key string) *rate.Limiter {
+	if g == nil {
+		return nil
+	}
+	name := g.group(key)
+	if name == "" {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	l, ok := g.limiters[name]
+	if !ok {
+		l = rate.NewLimiter(g.limit(name))
+		g.limiters[name] = l
+	}
+	return l
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
+
+	"chainguard.dev/driftlessaf/workqueue"
+)
+
+// throttledCount reads the current value of the throttled counter for the
+// given scope, so tests can assert deltas against the process-global metric.
+func throttledCount(t *testing.T, scope string) float64 {
+	t.Helper()
+	c, err := mThrottled.GetMetricWithLabelValues(metricServiceName, metricRevisionName, scope)
+	if err != nil {
+		t.Fatalf("GetMetricWithLabelValues(%q): %v", scope, err)
+	}
+	return testutil.ToFloat64(c)
+}
+
+// dispatchPass runs one dispatch pass over a fresh queue of keys and returns
+// the launched keys, sorted.
+func dispatchPass(t *testing.T, keys []string, opts ...Option) []string {
+	t.Helper()
+	q := &mockQueue{}
+	for _, name := range keys {
+		q.next = append(q.next, &mockKey{name: name})
+	}
+
+	var mu sync.Mutex
+	var got []string
+	future := HandleAsync(t.Context(), q, 10, 0, func(_ context.Context, key string, _ workqueue.Options) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, key)
+		return nil
+	}, 0, opts...)
+	if err := future(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.Sort(got)
+	return got
+}
+
+func TestHandleAsync_RateLimit(t *testing.T) {
+	// A bucket of three tokens that effectively never refills.
+	limit := WithRateLimit(rate.Every(time.Hour), 3)
+	before := throttledCount(t, throttleGlobal)
+
+	got := dispatchPass(t, []string{"k1", "k2", "k3", "k4", "k5"}, limit)
+	if diff := cmp.Diff([]string{"k1", "k2", "k3"}, got); diff != "" {
+		t.Errorf("first pass launched (-want, +got):\n%s", diff)
+	}
+
+	// The bucket is shared across passes through the same Option, so the
+	// next pass launches nothing.
+	if got := dispatchPass(t, []string{"k4", "k5"}, limit); len(got) != 0 {
+		t.Errorf("second pass launched %v, wanted nothing", got)
+	}
+
+	// Each pass counts its unused open slots: 10-3 then 10-0.
+	if got, want := throttledCount(t, throttleGlobal)-before, float64(7+10); got != want {
+		t.Errorf("global throttled delta: got = %v, want = %v", got, want)
+	}
+}
+
+func TestHandleAsync_GroupRateLimits(t *testing.T) {
+	before := throttledCount(t, throttleGroup)
+
+	got := dispatchPass(t, []string{"a/1", "a/2", "a/3", "b/1", "b/2", "c/1", "x"},
+		WithGroupRateLimits(repoOf, func(group string) (rate.Limit, int) {
+			switch group {
+			case "a":
+				return rate.Every(time.Hour), 2
+			case "c":
+				return rate.Inf, 0
+			default:
+				return rate.Every(time.Hour), 1
+			}
+		}))
+	if diff := cmp.Diff([]string{"a/1", "a/2", "b/1", "c/1", "x"}, got); diff != "" {
+		t.Errorf("launched (-want, +got):\n%s", diff)
+	}
+	if got, want := throttledCount(t, throttleGroup)-before, float64(2); got != want {
+		t.Errorf("group throttled delta: got = %v, want = %v", got, want)
+	}
+}
+
+func TestHandleAsync_RateLimitsCombined(t *testing.T) {
+	// When the global bucket runs dry, the group token taken for the key it
+	// throttled is returned, so the group can still launch it next pass.
+	global := WithRateLimit(rate.Every(time.Hour), 1)
+	groups := WithGroupRateLimits(repoOf, func(string) (rate.Limit, int) {
+		return rate.Every(time.Hour), 1
+	})
+
+	if diff := cmp.Diff([]string{"a/1"}, dispatchPass(t, []string{"a/1", "b/1"}, global, groups)); diff != "" {
+		t.Errorf("first pass launched (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"b/1"}, dispatchPass(t, []string{"b/1"}, groups)); diff != "" {
+		t.Errorf("second pass launched (-want, +got):\n%s", diff)
+	}
+}
+
+func TestHandleAsync_RateLimitReleasesConcurrencyGroup(t *testing.T) {
+	// A key throttled by its group bucket must not hold its concurrency
+	// group slot, or the next key of that group would be wrongly skipped.
+	got := dispatchPass(t, []string{"a/1", "b/1"},
+		WithConcurrencyGroups(func(string) string { return "all" }, func(string) int { return 1 }),
+		WithGroupRateLimits(repoOf, func(group string) (rate.Limit, int) {
+			if group == "a" {
+				return rate.Every(time.Hour), 0
+			}
+			return rate.Inf, 0
+		}))
+	if diff := cmp.Diff([]string{"b/1"}, got); diff != "" {
+		t.Errorf("launched (-want, +got):\n%s", diff)
+	}
+}
+
+func TestHandleAsync_RateLimitRefundsFailedStart(t *testing.T) {
+	// A key that another dispatcher starts first does not launch here, so
+	// its tokens go back to the buckets for the next key.
+	global := WithRateLimit(rate.Every(time.Hour), 1)
+	groups := WithGroupRateLimits(func(string) string { return "all" }, func(string) (rate.Limit, int) {
+		return rate.Every(time.Hour), 1
+	})
+
+	q := &mockQueue{next: []workqueue.QueuedKey{&mockKey{name: "k1", startErr: errors.New("lost the race")}}}
+	future := HandleAsync(t.Context(), q, 10, 0, func(context.Context, string, workqueue.Options) error {
+		t.Error("callback invoked for a key that failed to start")
+		return nil
+	}, 0, global, groups)
+	if err := future(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"k2"}, dispatchPass(t, []string{"k2"}, global, groups)); diff != "" {
+		t.Errorf("second pass launched (-want, +got):\n%s", diff)
+	}
+}
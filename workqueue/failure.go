@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue
+
+import "strings"
+
+// Note that these are variables, so that they can be modified by tests and
+// made flags in binary entrypoints.
+var (
+	// MaxAttemptErrors bounds how many attempt errors a key carries. Only the
+	// most recent ones are kept.
+	MaxAttemptErrors = 3
+
+	// MaxAttemptErrorLength bounds the length in bytes of each recorded
+	// attempt error; longer messages are truncated. Together with
+	// MaxAttemptErrors it keeps the history small enough for object metadata.
+	MaxAttemptErrorLength = 512
+)
+
+// FailureDetails describes why a dead-lettered key failed.
+type FailureDetails struct {
+	// LastError is the message of the error that dead-lettered the key,
+	// truncated to MaxAttemptErrorLength.
+	LastError string
+
+	// Reason is the NoRetryDetails reason carried by the error that
+	// dead-lettered the key (see NonRetriableError), if any, truncated to
+	// MaxAttemptErrorLength.
+	Reason string
+
+	// Infrastructure reports whether the error that dead-lettered the key was
+	// an infrastructure error (see IsInfrastructureError).
+	Infrastructure bool
+
+	// AttemptErrors are the messages of the most recent failed attempts,
+	// oldest first. When the key was dead-lettered with an error, the last
+	// entry is (the possibly truncated) LastError.
+	AttemptErrors []string
+}
+
+// NewFailureDetails builds the FailureDetails of a key dead-lettered with err,
+// whose earlier attempts failed with the recorded errors in prior.
+// Implementations of OwnedInProgressKey.DeadletterWithError use it so every
+// backend records the same, equally bounded, details.
+func NewFailureDetails(err error, prior []string) FailureDetails {
+	if err == nil {
+		return FailureDetails{AttemptErrors: prior}
+	}
+	return FailureDetails{
+		LastError:      truncateError(err.Error()),
+		Reason:         truncateError(GetNonRetriableDetails(err).GetMessage()),
+		Infrastructure: IsInfrastructureError(err),
+		AttemptErrors:  RecordAttemptError(prior, err),
+	}
+}
+
+// RecordAttemptError appends the message of err, truncated to
+// MaxAttemptErrorLength, to the recorded attempt errors in errs, and drops
+// the oldest so that at most MaxAttemptErrors remain. It returns errs
+// unchanged when err is nil, and never modifies errs in place.
+func RecordAttemptError(errs []string, err error) []string {
+	if err == nil {
+		return errs
+	}
+	out := make([]string, 0, len(errs)+1)
+	out = append(out, errs...)
+	out = append(out, truncateError(err.Error()))
+	if len(out) > MaxAttemptErrors {
+		out = out[len(out)-MaxAttemptErrors:]
+	}
+	return out
+}
+
+// truncateError bounds msg to MaxAttemptErrorLength bytes without splitting a
+// UTF-8 sequence.
+func truncateError(msg string) string {
+	if len(msg) > MaxAttemptErrorLength {
+		return strings.ToValidUTF8(msg[:MaxAttemptErrorLength], "")
+	}
+	return msg
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecordAttemptError(t *testing.T) {
+	long := strings.Repeat("x", MaxAttemptErrorLength+10)
+
+	tests := []struct {
+		name string
+		errs []string
+		err  error
+		want []string
+	}{{
+		name: "nil error leaves errors alone",
+		errs: []string{"a"},
+		want: []string{"a"},
+	}, {
+		name: "appends",
+		errs: []string{"a"},
+		err:  errors.New("b"),
+		want: []string{"a", "b"},
+	}, {
+		name: "drops the oldest",
+		errs: []string{"a", "b", "c"},
+		err:  errors.New("d"),
+		want: []string{"b", "c", "d"},
+	}, {
+		name: "truncates long messages",
+		err:  errors.New(long),
+		want: []string{long[:MaxAttemptErrorLength]},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := append([]string(nil), tt.errs...)
+			got := RecordAttemptError(tt.errs, tt.err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("RecordAttemptError() (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(orig, tt.errs); diff != "" {
+				t.Errorf("RecordAttemptError() modified its input (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewFailureDetails(t *testing.T) {
+	cause := NonRetriableError(status.Error(codes.Unavailable, "boom"), "gave up")
+
+	got := NewFailureDetails(cause, []string{"earlier"})
+	want := FailureDetails{
+		LastError:      cause.Error(),
+		Reason:         "gave up",
+		Infrastructure: true,
+		AttemptErrors:  []string{"earlier", cause.Error()},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewFailureDetails() (-want, +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(FailureDetails{AttemptErrors: []string{"earlier"}}, NewFailureDetails(nil, []string{"earlier"})); diff != "" {
+		t.Errorf("NewFailureDetails(nil) (-want, +got):\n%s", diff)
+	}
+
+	long := strings.Repeat("x", MaxAttemptErrorLength+10)
+	got = NewFailureDetails(NonRetriableError(errors.New(long), long), nil)
+	if len(got.LastError) != MaxAttemptErrorLength || len(got.Reason) != MaxAttemptErrorLength {
+		t.Errorf("NewFailureDetails(long): got LastError of %d bytes and Reason of %d, want both %d", len(got.LastError), len(got.Reason), MaxAttemptErrorLength)
+	}
+}
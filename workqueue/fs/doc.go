@@ -0,0 +1,14 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package fs provides a local-filesystem-backed workqueue implementation for
+// single-host deployments and local development.
+//
+// It is the blobqueue workqueue over the disk blob store: keys are stored as
+// files under queued/, in-progress/, and dead-letter/ names in a directory,
+// so queued work survives restarts, and the queue keeps the GCS backend's
+// priority ordering, not-before floor merging, attempt counting, and lease
+// refresh. Lease timing is governed by blobqueue.RefreshInterval.
+package fs
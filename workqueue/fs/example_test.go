@@ -0,0 +1,47 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fs_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/fs"
+)
+
+// ExampleNewWorkQueue demonstrates creating a filesystem-backed workqueue and
+// observing that queued keys survive a new instance over the same directory.
+func ExampleNewWorkQueue() {
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "workqueue-example")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wq, err := fs.NewWorkQueue(dir, 5)
+	if err != nil {
+		panic(err)
+	}
+	if err := wq.Queue(ctx, "my-key", workqueue.Options{Priority: 10}); err != nil {
+		panic(err)
+	}
+
+	// A fresh instance (e.g. after a restart) sees the same queue.
+	wq, err = fs.NewWorkQueue(dir, 5)
+	if err != nil {
+		panic(err)
+	}
+	_, queued, _, err := wq.Enumerate(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Queued keys: %d (%s)\n", len(queued), queued[0].Name())
+	// Output: Queued keys: 1 (my-key)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fs
+
+import (
+	"chainguard.dev/driftlessaf/store/blob/disk"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/blobqueue"
+)
+
+// Option configures a filesystem-backed workqueue created by NewWorkQueue.
+type Option = blobqueue.Option
+
+// WithOwner sets the identity recorded on each in-progress entry this queue
+// starts, e.g. the host or process claiming the key. Defaults to "", which
+// records nothing.
+func WithOwner(owner string) Option {
+	return blobqueue.WithOwner(owner)
+}
+
+// NewWorkQueue creates a workqueue that keeps its state durably under dir,
+// creating the directory if needed. Several processes on the same host may
+// share dir; each key is leased to one of them at a time.
+func NewWorkQueue(dir string, limit int, opts ...Option) (workqueue.Interface, error) {
+	store, err := disk.New(dir)
+	if err != nil {
+		return nil, err
+	}
+	return blobqueue.NewWorkQueue(store, limit, opts...), nil
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fs
+
+import (
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/conformance"
+)
+
+func TestWorkQueue(t *testing.T) {
+	// Adjust this to a suitable period for testing things.
+	// The conformance tests own adjusting MaximumBackoffPeriod.
+	workqueue.BackoffPeriod = 1 * time.Second
+
+	dir := t.TempDir()
+	ctor := func(u int) workqueue.Interface {
+		wq, err := NewWorkQueue(dir, u)
+		if err != nil {
+			t.Fatalf("NewWorkQueue: %v", err)
+		}
+		return wq
+	}
+
+	conformance.TestSemantics(t, ctor)
+
+	conformance.TestConcurrency(t, ctor)
+
+	conformance.TestDurability(t, ctor)
+
+	conformance.TestMaxRetry(t, ctor)
+
+	conformance.TestBackoffDelay(t, ctor)
+
+	conformance.TestDeadLetterAdmin(t, ctor)
+
+	conformance.TestList(t, ctor)
+}
@@ -7,6 +7,7 @@ package gcs
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/rand/v2"
@@ -87,6 +88,9 @@ func (w *wq) baseLabels() prometheus.Labels {
 }
 
 var _ workqueue.Interface = (*wq)(nil)
+var _ workqueue.Admin = (*wq)(nil)
+var _ workqueue.Lister = (*wq)(nil)
+var _ workqueue.LimitEnumerator = (*wq)(nil)
 
 // RefreshInterval is the period on which we refresh the lease of owned objects
 // It is surfaced as a global, so that it can be mutated by tests and exposed as
@@ -118,6 +122,11 @@ const (
 	failedTimeMetadataKey     = "failed-time"
 	lastAttemptedKey          = "last-attempted"
 	ownerMetadataKey          = "owner"
+	attemptErrorsMetadataKey  = "attempt-errors"
+	lastErrorMetadataKey      = "last-error"
+	failureReasonMetadataKey  = "failure-reason"
+	infrastructureMetadataKey = "infrastructure-error"
+	infrastructureValue       = "true"
 )
 
 var noPriority = fmt.Sprintf("%08d", 0)
@@ -266,6 +275,11 @@ func checkPreconditionFailedOk(err error) (bool, error) {
 
 // Enumerate implements workqueue.Interface.
 func (w *wq) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+	return w.EnumerateN(ctx, w.limit)
+}
+
+// EnumerateN implements workqueue.LimitEnumerator.
+func (w *wq) EnumerateN(ctx context.Context, limit int) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
 	labels := w.baseLabels()
 
 	start := time.Now()
@@ -275,8 +289,8 @@ func (w *wq) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey,
 
 	iter := w.client.Objects(ctx, nil)
 
-	wip := make([]workqueue.ObservedInProgressKey, 0, w.limit)
-	qd := make([]*queuedKey, 0, w.limit+1)
+	wip := make([]workqueue.ObservedInProgressKey, 0, limit)
+	qd := make([]*queuedKey, 0, limit+1)
 	var dl []workqueue.DeadLetteredKey
 
 	queued, notbefore, deadlettered := 0, 0, 0
@@ -379,8 +393,8 @@ func (w *wq) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey,
 				}
 				return qd[i].attrs.Name < qd[j].attrs.Name
 			})
-			if len(qd) > w.limit {
-				qd = qd[:w.limit]
+			if len(qd) > limit {
+				qd = qd[:limit]
 			}
 			queued++
 
@@ -422,6 +436,8 @@ type objectAttrs struct {
 	priority    int64
 	notBefore   time.Time
 	createdTime time.Time
+	owner       string
+	failure     workqueue.FailureDetails
 }
 
 func (w *wq) getAttrs(ctx context.Context, objKey string) (objectAttrs, error) {
@@ -430,7 +446,11 @@ func (w *wq) getAttrs(ctx context.Context, objKey string) (objectAttrs, error) {
 	if err != nil {
 		return objectAttrs{}, err
 	}
+	return parseAttrs(attrs), nil
+}
 
+// parseAttrs decodes the workqueue metadata recorded on an object.
+func parseAttrs(attrs *storage.ObjectAttrs) objectAttrs {
 	var attempts int
 	if att, ok := attrs.Metadata[attemptsMetadataKey]; ok && att != "" {
 		if parsedAttempts, err := strconv.Atoi(att); err == nil {
@@ -457,68 +477,155 @@ func (w *wq) getAttrs(ctx context.Context, objKey string) (objectAttrs, error) {
 		priority:    priority,
 		notBefore:   notBefore,
 		createdTime: attrs.Created,
-	}, nil
+		owner:       attrs.Metadata[ownerMetadataKey],
+		failure:     failureDetails(attrs.Metadata),
+	}
 }
 
-func (w *wq) getInProgressKey(ctx context.Context, key string) (*workqueue.KeyState, error) {
-	attrs, err := w.getAttrs(ctx, fmt.Sprintf("%s%s", inProgressPrefix, key))
-	if err != nil {
-		return nil, err
+// attemptErrors decodes the recorded attempt errors from object metadata.
+// Malformed values are treated as no errors.
+func attemptErrors(metadata map[string]string) []string {
+	v, ok := metadata[attemptErrorsMetadataKey]
+	if !ok || v == "" {
+		return nil
 	}
-	return &workqueue.KeyState{
-		Key:           key,
-		Status:        workqueue.KeyState_IN_PROGRESS,
-		Attempts:      attrs.attempts,
-		Priority:      attrs.priority,
-		NotBeforeTime: attrs.notBefore.Unix(),
-	}, nil
+	var errs []string
+	if err := json.Unmarshal([]byte(v), &errs); err != nil {
+		return nil
+	}
+	return errs
 }
 
-func (w *wq) getQueuedKey(ctx context.Context, key string) (*workqueue.KeyState, error) {
-	attrs, err := w.getAttrs(ctx, fmt.Sprintf("%s%s", queuedPrefix, key))
+// setAttemptErrors encodes errs into object metadata, removing the key when
+// there are none.
+func setAttemptErrors(metadata map[string]string, errs []string) {
+	if len(errs) == 0 {
+		delete(metadata, attemptErrorsMetadataKey)
+		return
+	}
+	b, err := json.Marshal(errs)
 	if err != nil {
-		return nil, err
+		// Marshaling a string slice cannot fail.
+		return
 	}
-	return &workqueue.KeyState{
-		Key:           key,
-		Status:        workqueue.KeyState_QUEUED,
-		Attempts:      attrs.attempts,
-		Priority:      attrs.priority,
-		QueuedTime:    attrs.createdTime.Unix(),
-		NotBeforeTime: attrs.notBefore.Unix(),
-	}, nil
+	metadata[attemptErrorsMetadataKey] = string(b)
 }
 
-func (w *wq) getDeadLetterKey(ctx context.Context, key string) (*workqueue.KeyState, error) {
-	attrs, err := w.getAttrs(ctx, fmt.Sprintf("%s%s", deadLetterPrefix, key))
-	if err != nil {
-		return nil, err
+// failureDetails decodes the failure details recorded on a dead-lettered
+// object's metadata.
+func failureDetails(metadata map[string]string) workqueue.FailureDetails {
+	return workqueue.FailureDetails{
+		LastError:      metadata[lastErrorMetadataKey],
+		Reason:         metadata[failureReasonMetadataKey],
+		Infrastructure: metadata[infrastructureMetadataKey] == infrastructureValue,
+		AttemptErrors:  attemptErrors(metadata),
 	}
-	return &workqueue.KeyState{
+}
+
+// keyState returns the state of key given the attributes of its object in
+// the given status.
+func (a objectAttrs) keyState(key string, st workqueue.KeyState_Status) *workqueue.KeyState {
+	ks := &workqueue.KeyState{
 		Key:           key,
-		Status:        workqueue.KeyState_DEAD_LETTER,
-		Attempts:      attrs.attempts,
-		Priority:      attrs.priority,
-		QueuedTime:    attrs.createdTime.Unix(),
-		NotBeforeTime: attrs.notBefore.Unix(),
-	}, nil
+		Status:        st,
+		Attempts:      a.attempts,
+		Priority:      a.priority,
+		NotBeforeTime: a.notBefore.Unix(),
+		AttemptErrors: a.failure.AttemptErrors,
+	}
+	switch st {
+	case workqueue.KeyState_IN_PROGRESS:
+		ks.Owner = a.owner
+	case workqueue.KeyState_QUEUED:
+		ks.QueuedTime = a.createdTime.Unix()
+	case workqueue.KeyState_DEAD_LETTER:
+		ks.QueuedTime = a.createdTime.Unix()
+		ks.LastError = a.failure.LastError
+		ks.FailureReason = a.failure.Reason
+		ks.InfrastructureError = a.failure.Infrastructure
+	}
+	return ks
+}
+
+// statusPrefixes maps each key status to the object prefix it is stored
+// under, in the order Get consults them.
+var statusPrefixes = []struct {
+	prefix string
+	status workqueue.KeyState_Status
+}{
+	{inProgressPrefix, workqueue.KeyState_IN_PROGRESS},
+	{queuedPrefix, workqueue.KeyState_QUEUED},
+	{deadLetterPrefix, workqueue.KeyState_DEAD_LETTER},
 }
 
 // Get implements workqueue.Interface.
 func (w *wq) Get(ctx context.Context, key string) (*workqueue.KeyState, error) {
-	if state, err := w.getInProgressKey(ctx, key); err == nil {
-		return state, nil
+	for _, sp := range statusPrefixes {
+		if attrs, err := w.getAttrs(ctx, sp.prefix+key); err == nil {
+			return attrs.keyState(key, sp.status), nil
+		}
 	}
+	return nil, status.Errorf(codes.NotFound, "key %q not found", key)
+}
 
-	if state, err := w.getQueuedKey(ctx, key); err == nil {
-		return state, nil
+// List implements workqueue.Lister.
+func (w *wq) List(ctx context.Context, prefix string) ([]*workqueue.KeyState, error) {
+	var states []*workqueue.KeyState
+	for _, sp := range statusPrefixes {
+		iter := w.client.Objects(ctx, &storage.Query{Prefix: sp.prefix + prefix})
+		for {
+			objAttrs, err := iter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("Next() = %w", err)
+			}
+			key := strings.TrimPrefix(objAttrs.Name, sp.prefix)
+			states = append(states, parseAttrs(objAttrs).keyState(key, sp.status))
+		}
 	}
+	return states, nil
+}
 
-	if state, err := w.getDeadLetterKey(ctx, key); err == nil {
-		return state, nil
+// ReplayDeadLetter implements workqueue.Admin.
+func (w *wq) ReplayDeadLetter(ctx context.Context, key string, opts workqueue.Options) error {
+	obj := w.client.Object(fmt.Sprintf("%s%s", deadLetterPrefix, key))
+	attrs, err := obj.Attrs(ctx)
+	if isNotFound(err) {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	} else if err != nil {
+		return fmt.Errorf("Attrs() = %w", err)
+	}
+	if opts.Priority == 0 {
+		if p, err := strconv.ParseInt(attrs.Metadata[priorityMetadataKey], 10, 64); err == nil {
+			opts.Priority = p
+		}
 	}
 
-	return nil, status.Errorf(codes.NotFound, "key %q not found", key)
+	clog.InfoContextf(ctx, "Replaying dead-lettered key %q", key)
+	// Queue before removing the dead-letter object: if the delete fails the
+	// key is merely listed twice, never lost. The queued object starts
+	// without attempts metadata, so the key gets its full retry budget.
+	if err := w.Queue(ctx, key, opts); err != nil {
+		return err
+	}
+	if err := obj.Generation(attrs.Generation).Delete(ctx); err != nil && !lostOwnership(err) {
+		clog.WarnContextf(ctx, "ReplayDeadLetter: failed to delete dead-letter object for key %q: %v", key, err)
+		return fmt.Errorf("Delete() = %w", err)
+	}
+	return nil
+}
+
+// PurgeDeadLetter implements workqueue.Admin.
+func (w *wq) PurgeDeadLetter(ctx context.Context, key string) error {
+	if err := w.client.Object(fmt.Sprintf("%s%s", deadLetterPrefix, key)).Delete(ctx); isNotFound(err) {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	} else if err != nil {
+		clog.WarnContextf(ctx, "PurgeDeadLetter: failed to delete dead-letter object for key %q: %v", key, err)
+		return fmt.Errorf("Delete() = %w", err)
+	}
+	clog.InfoContextf(ctx, "Purged dead-lettered key %q", key)
+	return nil
 }
 
 type inProgressKey struct {
@@ -681,6 +788,10 @@ func (o *inProgressKey) requeueOnce(ctx context.Context, opts workqueue.Options,
 	delete(copier.Metadata, ownerMetadataKey)
 	// Set the last attempted time as unix timestamp when requeuing
 	copier.Metadata[lastAttemptedKey] = strconv.FormatInt(time.Now().UTC().Unix(), 10)
+	// Record why this attempt failed, if it did.
+	if opts.AttemptError != nil {
+		setAttemptErrors(copier.Metadata, workqueue.RecordAttemptError(attemptErrors(copier.Metadata), opts.AttemptError))
+	}
 
 	// Handle custom delay if specified
 	if opts.BackoffDelay > 0 {
@@ -695,6 +806,7 @@ func (o *inProgressKey) requeueOnce(ctx context.Context, opts workqueue.Options,
 		// Reset attempts when using custom delay, as this indicates periodic revisit pattern
 		// rather than retry due to failure
 		copier.Metadata[attemptsMetadataKey] = "0"
+		delete(copier.Metadata, attemptErrorsMetadataKey)
 		notBefore := time.Now().UTC().Add(opts.Delay)
 		copier.Metadata[notBeforeMetadataKey] = notBefore.Format(time.RFC3339)
 	} else if p, ok := copier.Metadata[priorityMetadataKey]; ok && p != noPriority {
@@ -869,6 +981,11 @@ func (o *inProgressKey) Complete(ctx context.Context) error {
 
 // Deadletter implements workqueue.OwnedInProgressKey.
 func (o *inProgressKey) Deadletter(ctx context.Context) error {
+	return o.DeadletterWithError(ctx, nil)
+}
+
+// DeadletterWithError implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) DeadletterWithError(ctx context.Context, cause error) error {
 	o.stopHeartbeat()
 	o.rw.RLock()
 	defer o.rw.RUnlock()
@@ -895,8 +1012,20 @@ func (o *inProgressKey) Deadletter(ctx context.Context) error {
 	delete(copier.Metadata, expirationMetadataKey)
 	delete(copier.Metadata, ownerMetadataKey)
 
-	// Add metadata about when the key was dead-lettered
+	// Add metadata about when and why the key was dead-lettered
 	copier.Metadata[failedTimeMetadataKey] = time.Now().UTC().Format(time.RFC3339)
+	if cause != nil {
+		failure := workqueue.NewFailureDetails(cause, attemptErrors(copier.Metadata))
+		setAttemptErrors(copier.Metadata, failure.AttemptErrors)
+		// NewFailureDetails bounds both messages, so they fit in object metadata.
+		copier.Metadata[lastErrorMetadataKey] = failure.LastError
+		if failure.Reason != "" {
+			copier.Metadata[failureReasonMetadataKey] = failure.Reason
+		}
+		if failure.Infrastructure {
+			copier.Metadata[infrastructureMetadataKey] = infrastructureValue
+		}
+	}
 
 	// Record time to completion for dead-lettered task
 	ttcLabels := o.baseLabels()
@@ -1224,6 +1353,11 @@ func (d *deadLetteredKey) GetFailedTime() time.Time {
 	return d.attrs.Created
 }
 
+// GetFailureDetails implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetFailureDetails() workqueue.FailureDetails {
+	return failureDetails(d.attrs.Metadata)
+}
+
 // GetAttempts implements workqueue.DeadLetteredKey.
 func (d *deadLetteredKey) GetAttempts() int {
 	if att, ok := d.attrs.Metadata[attemptsMetadataKey]; ok && att != "" {
@@ -63,4 +63,12 @@ func TestWorkQueue(t *testing.T) {
 	conformance.TestBackoffDelay(t, func(u int) workqueue.Interface {
 		return NewWorkQueue(client.Bucket(bucket), u)
 	})
+
+	conformance.TestDeadLetterAdmin(t, func(u int) workqueue.Interface {
+		return NewWorkQueue(client.Bucket(bucket), u)
+	})
+
+	conformance.TestList(t, func(u int) workqueue.Interface {
+		return NewWorkQueue(client.Bucket(bucket), u)
+	})
 }
@@ -1147,3 +1147,43 @@ func TestEnumerateCountsInProgressByOwner(t *testing.T) {
 		t.Errorf("in-progress by owner unknown = %v, want 1", got)
 	}
 }
+
+func TestListReportsOwner(t *testing.T) {
+	objects := [][2]string{
+		{"in-progress/repo/a", "us-a"},
+		{"queued/repo/a", ""},
+		{"queued/repo/b", ""},
+		{"queued/other", ""},
+	}
+	f := &fakeGCS{handler: func(call gcsCall) (int, string) {
+		var matched [][2]string
+		for _, obj := range objects {
+			if strings.HasPrefix(obj[0], call.query.Get("prefix")) {
+				matched = append(matched, obj)
+			}
+		}
+		return 200, listJSON(matched...)
+	}}
+	wq := NewWorkQueue(newTestClient(t, f), 1)
+
+	states, err := wq.(workqueue.Lister).List(t.Context(), "repo/")
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	// The queue's limit caps Enumerate, not List.
+	if len(states) != 3 {
+		t.Fatalf("List() returned %d keys, want 3: %v", len(states), states)
+	}
+	for _, s := range states {
+		wantOwner := ""
+		if s.Status == workqueue.KeyState_IN_PROGRESS {
+			wantOwner = "us-a"
+		}
+		if s.Owner != wantOwner {
+			t.Errorf("%s %q: owner = %q, want %q", s.Status, s.Key, s.Owner, wantOwner)
+		}
+		if !strings.HasPrefix(s.Key, "repo/") {
+			t.Errorf("List() returned key %q outside the prefix", s.Key)
+		}
+	}
+}
@@ -19,4 +19,29 @@ SPDX-License-Identifier: Apache-2.0
 //   - Deterministic routing: the same key always routes to the same shard
 //   - Even distribution: keys are roughly evenly distributed across shards
 //   - Fast computation: FNV-1a is a fast non-cryptographic hash
+//
+// # Weights and Rendezvous Hashing
+//
+// NewWithBackends takes named [Backend]s, each with an optional Weight: a
+// backend of weight 3 receives three times the keys of a backend of weight
+// 1. By default a weighted backend takes Weight slots of the hash ring.
+//
+// With [WithRendezvousHashing], each key instead goes to the backend with
+// the highest weighted rendezvous score for (backend name, key). Adding or
+// removing a backend then only moves the keys that backend gains or loses;
+// with modulo hashing, changing the shard count moves most keys.
+//
+// # Failover
+//
+// [WithFailover] tracks the health of each backend with a
+// [breaker.Breaker]: calls failing with Unavailable or DeadlineExceeded count
+// against the backend, and while its circuit is open its keys are routed to
+// the next backend in their routing order. A backend with a Health client is
+// probed over the gRPC health protocol before traffic returns to it, at most
+// once per [WithHealthCheckInterval]:
+//
+//	srv, err := hyperqueue.NewWithBackends([]hyperqueue.Backend{
+//	    {Name: "us-east1", Client: east, Health: healthpb.NewHealthClient(eastConn)},
+//	    {Name: "us-west1", Client: west, Weight: 2},
+//	}, hyperqueue.WithRendezvousHashing(), hyperqueue.WithFailover(nil))
 package hyperqueue
@@ -20,3 +20,12 @@ func ExampleNew() {
 	fmt.Println("error with no backends:", err)
 	// Output: error with no backends: at least one backend is required
 }
+
+// ExampleNewWithBackends demonstrates the validation of named, weighted
+// backends.
+func ExampleNewWithBackends() {
+	_, err := hyperqueue.NewWithBackends([]hyperqueue.Backend{{Name: "us-east1"}},
+		hyperqueue.WithRendezvousHashing(), hyperqueue.WithFailover(nil))
+	fmt.Println(err)
+	// Output: backend 0: client is required
+}
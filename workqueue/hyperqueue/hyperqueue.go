@@ -8,38 +8,246 @@ package hyperqueue
 import (
 	"context"
 	"errors"
-	"hash/fnv"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"chainguard.dev/driftlessaf/breaker"
 	"chainguard.dev/driftlessaf/workqueue"
 )
 
+// Backend is one shard of a hyperqueue.
+type Backend struct {
+	// Name identifies the backend. It must be unique, and with rendezvous
+	// hashing it should be stable across deployments: keys are placed by
+	// name, not by position.
+	Name string
+
+	// Client is the backend's workqueue service.
+	Client workqueue.WorkqueueServiceClient
+
+	// Weight is the backend's share of the keys relative to the other
+	// backends. Zero means 1.
+	Weight int
+
+	// Health optionally reports the backend's gRPC health. With failover
+	// enabled, a backend that has been failing is probed through Health
+	// before traffic is routed back to it.
+	Health healthpb.HealthClient
+}
+
+// Option configures a hyperqueue server.
+type Option func(*server)
+
+// WithRendezvousHashing assigns each key to the backend with the highest
+// weighted rendezvous (highest-random-weight) score for it, instead of
+// hash(key) modulo the weighted backends. Adding or removing a backend then
+// only moves the keys that backend gains or loses, rather than reshuffling
+// most keys.
+func WithRendezvousHashing() Option {
+	return func(s *server) { s.rendezvous = true }
+}
+
+// WithFailover temporarily routes keys away from unhealthy backends. Calls
+// failing with Unavailable or DeadlineExceeded count against the backend's
+// circuit in b (a new breaker.Breaker when b is nil), and while it is open
+// the backend's keys go to their next backend in routing order: the next
+// highest rendezvous score, or the next backend around the hash ring.
+//
+// Failover trades placement for availability: a key enqueued on a fallback
+// backend is processed there, and GetKeyState for the key reports the
+// fallback's state until its primary recovers.
+func WithFailover(b *breaker.Breaker) Option {
+	return func(s *server) {
+		if b == nil {
+			b = breaker.New()
+		}
+		s.breaker = b
+	}
+}
+
+// WithHealthCheckInterval sets how long a failed health probe of a backend
+// stands before the backend is probed again (by default,
+// defaultHealthCheckInterval). With failover enabled, a backend that has
+// failed since its last success is probed at most once per interval, however
+// many keys are routed meanwhile.
+func WithHealthCheckInterval(d time.Duration) Option {
+	return func(s *server) { s.healthInterval = d }
+}
+
+// defaultHealthCheckInterval is the default WithHealthCheckInterval.
+const defaultHealthCheckInterval = 5 * time.Second
+
 type server struct {
 	workqueue.UnimplementedWorkqueueServiceServer
-	backends []workqueue.WorkqueueServiceClient
+	backends []Backend
+
+	// slots maps hash(key) % len(slots) to a backend index, each backend
+	// taking as many slots as its weight.
+	slots      []int
+	rendezvous bool
+
+	// breaker tracks backend health for failover; nil disables failover.
+	// suspect marks the backends that have failed since their last success.
+	breaker *breaker.Breaker
+	suspect []atomic.Bool
+
+	// probes records each backend's failed health probes, which stand for
+	// healthInterval.
+	probes         []healthProbe
+	healthInterval time.Duration
 }
 
 // New creates a sharded WorkqueueServiceServer from the provided backend clients.
 // The shard count is determined by len(backends).
 // Keys are consistently routed to shards using hash(key) % len(backends).
-func New(backends []workqueue.WorkqueueServiceClient) (workqueue.WorkqueueServiceServer, error) {
-	if len(backends) == 0 {
-		return nil, errors.New("at least one backend is required")
+func New(backends []workqueue.WorkqueueServiceClient, opts ...Option) (workqueue.WorkqueueServiceServer, error) {
+	bs := make([]Backend, 0, len(backends))
+	for i, b := range backends {
+		bs = append(bs, Backend{Name: strconv.Itoa(i), Client: b})
 	}
-	return &server{backends: backends}, nil
+	return NewWithBackends(bs, opts...)
 }
 
-func (s *server) shardFor(key string) workqueue.WorkqueueServiceClient {
-	h := fnv.New32a()
-	h.Write([]byte(key))
-	return s.backends[int(h.Sum32())%len(s.backends)]
+// NewWithBackends creates a sharded WorkqueueServiceServer from the provided
+// backends, routing keys by their names and weights.
+func NewWithBackends(backends []Backend, opts ...Option) (workqueue.WorkqueueServiceServer, error) {
+	if len(backends) == 0 {
+		return nil, errors.New("at least one backend is required")
+	}
+	s := &server{
+		backends:       backends,
+		suspect:        make([]atomic.Bool, len(backends)),
+		probes:         make([]healthProbe, len(backends)),
+		healthInterval: defaultHealthCheckInterval,
+	}
+	names := make(map[string]struct{}, len(backends))
+	for i, b := range backends {
+		switch {
+		case b.Client == nil:
+			return nil, fmt.Errorf("backend %d: client is required", i)
+		case b.Name == "":
+			return nil, fmt.Errorf("backend %d: name is required", i)
+		case b.Weight < 0:
+			return nil, fmt.Errorf("backend %q: weight must not be negative, got %d", b.Name, b.Weight)
+		}
+		if _, ok := names[b.Name]; ok {
+			return nil, fmt.Errorf("backend %q: duplicate name", b.Name)
+		}
+		names[b.Name] = struct{}{}
+		for range max(b.Weight, 1) {
+			s.slots = append(s.slots, i)
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
 // Process routes the request to the appropriate shard based on the key.
 func (s *server) Process(ctx context.Context, req *workqueue.ProcessRequest) (*workqueue.ProcessResponse, error) {
-	return s.shardFor(req.GetKey()).Process(ctx, req)
+	shard := s.route(ctx, req.GetKey())
+	resp, err := s.backends[shard].Client.Process(ctx, req)
+	s.observe(ctx, shard, err)
+	return resp, err
 }
 
 // GetKeyState routes the request to the appropriate shard based on the key.
 func (s *server) GetKeyState(ctx context.Context, req *workqueue.GetKeyStateRequest) (*workqueue.KeyState, error) {
-	return s.shardFor(req.GetKey()).GetKeyState(ctx, req)
+	shard := s.route(ctx, req.GetKey())
+	resp, err := s.backends[shard].Client.GetKeyState(ctx, req)
+	s.observe(ctx, shard, err)
+	return resp, err
+}
+
+// ProcessBatch splits the batch by shard, enqueues each shard's keys with
+// workqueue.EnqueueBatch, and reassembles the per-key results in request
+// order. Keys a shard could not be asked about report the shard's failure.
+func (s *server) ProcessBatch(ctx context.Context, req *workqueue.ProcessBatchRequest) (*workqueue.ProcessBatchResponse, error) {
+	reqs := req.GetRequests()
+
+	// Group the requests by shard, preserving their relative order.
+	byShard := make(map[int][]int, len(s.backends))
+	for i, r := range reqs {
+		shard := s.route(ctx, r.GetKey())
+		byShard[shard] = append(byShard[shard], i)
+	}
+
+	results := make([]*workqueue.ProcessBatchResult, len(reqs))
+	var wg sync.WaitGroup
+	for shard, idxs := range byShard {
+		wg.Go(func() {
+			sub := make([]*workqueue.ProcessRequest, 0, len(idxs))
+			for _, i := range idxs {
+				sub = append(sub, reqs[i])
+			}
+			got, err := workqueue.EnqueueBatch(ctx, s.backends[shard].Client, sub)
+			s.observe(ctx, shard, err)
+			for j, i := range idxs {
+				if j < len(got) {
+					results[i] = got[j]
+				} else {
+					results[i] = workqueue.NewProcessBatchResult(reqs[i].GetKey(), err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return &workqueue.ProcessBatchResponse{Results: results}, nil
+}
+
+// ReplayDeadLetters routes a single-key selector to the key's shard, and fans
+// any other selector out to every shard, concatenating the replayed keys.
+func (s *server) ReplayDeadLetters(ctx context.Context, req *workqueue.ReplayDeadLettersRequest) (*workqueue.DeadLettersResponse, error) {
+	if key := req.GetSelector().GetKey(); key != "" {
+		return s.shardFor(key).ReplayDeadLetters(ctx, req)
+	}
+	return s.fanOut(func(b workqueue.WorkqueueServiceClient) (*workqueue.DeadLettersResponse, error) {
+		return b.ReplayDeadLetters(ctx, req)
+	})
+}
+
+// PurgeDeadLetters routes a single-key selector to the key's shard, and fans
+// any other selector out to every shard, concatenating the purged keys.
+func (s *server) PurgeDeadLetters(ctx context.Context, req *workqueue.PurgeDeadLettersRequest) (*workqueue.DeadLettersResponse, error) {
+	if key := req.GetSelector().GetKey(); key != "" {
+		return s.shardFor(key).PurgeDeadLetters(ctx, req)
+	}
+	return s.fanOut(func(b workqueue.WorkqueueServiceClient) (*workqueue.DeadLettersResponse, error) {
+		return b.PurgeDeadLetters(ctx, req)
+	})
+}
+
+// ListKeys fans the request out to every shard and merges the keys they
+// report, in workqueue.SortKeyStates order.
+func (s *server) ListKeys(ctx context.Context, req *workqueue.ListKeysRequest) (*workqueue.ListKeysResponse, error) {
+	resp := &workqueue.ListKeysResponse{}
+	for i, b := range s.backends {
+		r, err := b.Client.ListKeys(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		resp.Keys = append(resp.Keys, r.GetKeys()...)
+	}
+	workqueue.SortKeyStates(resp.Keys)
+	return resp, nil
+}
+
+// fanOut calls f on every backend in turn, merging the keys they report. It
+// stops at the first backend that fails.
+func (s *server) fanOut(f func(workqueue.WorkqueueServiceClient) (*workqueue.DeadLettersResponse, error)) (*workqueue.DeadLettersResponse, error) {
+	resp := &workqueue.DeadLettersResponse{}
+	for i, b := range s.backends {
+		r, err := f(b.Client)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		resp.Keys = append(resp.Keys, r.GetKeys()...)
+	}
+	return resp, nil
 }
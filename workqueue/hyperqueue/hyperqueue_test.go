@@ -14,7 +14,10 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"chainguard.dev/driftlessaf/workqueue"
 )
@@ -23,6 +26,13 @@ type mockClient struct {
 	workqueue.WorkqueueServiceClient
 	processCount  atomic.Int64
 	keyStateCount atomic.Int64
+	replayCount   atomic.Int64
+	purgeCount    atomic.Int64
+	batchCount    atomic.Int64
+	// deadLetters are the keys reported by bulk dead-letter requests.
+	deadLetters []string
+	// keys are the key states reported by ListKeys.
+	keys []*workqueue.KeyState
 }
 
 func (m *mockClient) Process(_ context.Context, _ *workqueue.ProcessRequest, _ ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
@@ -30,6 +40,15 @@ func (m *mockClient) Process(_ context.Context, _ *workqueue.ProcessRequest, _ .
 	return &workqueue.ProcessResponse{}, nil
 }
 
+func (m *mockClient) ProcessBatch(_ context.Context, req *workqueue.ProcessBatchRequest, _ ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	m.batchCount.Add(1)
+	resp := &workqueue.ProcessBatchResponse{}
+	for _, r := range req.GetRequests() {
+		resp.Results = append(resp.Results, &workqueue.ProcessBatchResult{Key: r.GetKey()})
+	}
+	return resp, nil
+}
+
 func (m *mockClient) GetKeyState(_ context.Context, req *workqueue.GetKeyStateRequest, _ ...grpc.CallOption) (*workqueue.KeyState, error) {
 	m.keyStateCount.Add(1)
 	return &workqueue.KeyState{
@@ -38,11 +57,34 @@ func (m *mockClient) GetKeyState(_ context.Context, req *workqueue.GetKeyStateRe
 	}, nil
 }
 
+func (m *mockClient) ReplayDeadLetters(_ context.Context, req *workqueue.ReplayDeadLettersRequest, _ ...grpc.CallOption) (*workqueue.DeadLettersResponse, error) {
+	m.replayCount.Add(1)
+	if key := req.GetSelector().GetKey(); key != "" {
+		return &workqueue.DeadLettersResponse{Keys: []string{key}}, nil
+	}
+	return &workqueue.DeadLettersResponse{Keys: m.deadLetters}, nil
+}
+
+func (m *mockClient) ListKeys(_ context.Context, _ *workqueue.ListKeysRequest, _ ...grpc.CallOption) (*workqueue.ListKeysResponse, error) {
+	return &workqueue.ListKeysResponse{Keys: m.keys}, nil
+}
+
+func (m *mockClient) PurgeDeadLetters(_ context.Context, req *workqueue.PurgeDeadLettersRequest, _ ...grpc.CallOption) (*workqueue.DeadLettersResponse, error) {
+	m.purgeCount.Add(1)
+	if key := req.GetSelector().GetKey(); key != "" {
+		return &workqueue.DeadLettersResponse{Keys: []string{key}}, nil
+	}
+	return &workqueue.DeadLettersResponse{Keys: m.deadLetters}, nil
+}
+
 // failingClient returns errors for all operations.
 type failingClient struct {
 	workqueue.WorkqueueServiceClient
 	processErr  error
 	keyStateErr error
+	purgeErr    error
+	batchErr    error
+	listErr     error
 }
 
 func (f *failingClient) Process(context.Context, *workqueue.ProcessRequest, ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
@@ -53,6 +95,18 @@ func (f *failingClient) GetKeyState(context.Context, *workqueue.GetKeyStateReque
 	return nil, f.keyStateErr
 }
 
+func (f *failingClient) ProcessBatch(context.Context, *workqueue.ProcessBatchRequest, ...grpc.CallOption) (*workqueue.ProcessBatchResponse, error) {
+	return nil, f.batchErr
+}
+
+func (f *failingClient) ListKeys(context.Context, *workqueue.ListKeysRequest, ...grpc.CallOption) (*workqueue.ListKeysResponse, error) {
+	return nil, f.listErr
+}
+
+func (f *failingClient) PurgeDeadLetters(context.Context, *workqueue.PurgeDeadLettersRequest, ...grpc.CallOption) (*workqueue.DeadLettersResponse, error) {
+	return nil, f.purgeErr
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -350,3 +404,213 @@ func TestGetKeyState_routesErrorToCorrectShard(t *testing.T) {
 		t.Errorf("GetKeyState() error = %v, wanted %v", err, backendErr)
 	}
 }
+
+func TestReplayDeadLetters_routesKeyToSingleBackend(t *testing.T) {
+	mocks := make([]*mockClient, 3)
+	backends := make([]workqueue.WorkqueueServiceClient, 3)
+	for i := range 3 {
+		mocks[i] = &mockClient{}
+		backends[i] = mocks[i]
+	}
+
+	srv, err := New(backends)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	key := fmt.Sprintf("test-key-%d", rand.Int64())
+	resp, err := srv.ReplayDeadLetters(context.Background(), &workqueue.ReplayDeadLettersRequest{
+		Selector: &workqueue.DeadLetterSelector{Key: key},
+	})
+	if err != nil {
+		t.Fatalf("ReplayDeadLetters() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{key}, resp.GetKeys()); diff != "" {
+		t.Errorf("ReplayDeadLetters() keys (-want, +got):\n%s", diff)
+	}
+
+	var totalCalls int64
+	for _, m := range mocks {
+		totalCalls += m.replayCount.Load()
+	}
+	if totalCalls != 1 {
+		t.Errorf("ReplayDeadLetters() called %d backends, wanted 1", totalCalls)
+	}
+}
+
+func TestPurgeDeadLetters_fansOutToAllBackends(t *testing.T) {
+	mocks := []*mockClient{{
+		deadLetters: []string{"a", "b"},
+	}, {
+		deadLetters: nil,
+	}, {
+		deadLetters: []string{"c"},
+	}}
+	backends := make([]workqueue.WorkqueueServiceClient, len(mocks))
+	for i, m := range mocks {
+		backends[i] = m
+	}
+
+	srv, err := New(backends)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	resp, err := srv.PurgeDeadLetters(context.Background(), &workqueue.PurgeDeadLettersRequest{
+		Selector: &workqueue.DeadLetterSelector{Prefix: "test-"},
+	})
+	if err != nil {
+		t.Fatalf("PurgeDeadLetters() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c"}, resp.GetKeys()); diff != "" {
+		t.Errorf("PurgeDeadLetters() keys (-want, +got):\n%s", diff)
+	}
+	for i, m := range mocks {
+		if got := m.purgeCount.Load(); got != 1 {
+			t.Errorf("shard %d: PurgeDeadLetters() called %d times, wanted 1", i, got)
+		}
+	}
+}
+
+func TestPurgeDeadLetters_surfacesBackendErrors(t *testing.T) {
+	backendErr := errors.New("backend unavailable")
+	srv, err := New([]workqueue.WorkqueueServiceClient{
+		&mockClient{},
+		&failingClient{purgeErr: backendErr},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = srv.PurgeDeadLetters(context.Background(), &workqueue.PurgeDeadLettersRequest{})
+	if err == nil {
+		t.Fatal("PurgeDeadLetters() error = nil, wanted error")
+	}
+	if !errors.Is(err, backendErr) {
+		t.Errorf("PurgeDeadLetters() error = %v, wanted %v", err, backendErr)
+	}
+}
+
+func TestListKeys_mergesAllBackends(t *testing.T) {
+	state := func(key string, st workqueue.KeyState_Status, priority int64) *workqueue.KeyState {
+		return &workqueue.KeyState{Key: key, Status: st, Priority: priority}
+	}
+	backends := []workqueue.WorkqueueServiceClient{
+		&mockClient{keys: []*workqueue.KeyState{
+			state("a", workqueue.KeyState_QUEUED, 0),
+			state("b", workqueue.KeyState_DEAD_LETTER, 0),
+		}},
+		&mockClient{},
+		&mockClient{keys: []*workqueue.KeyState{
+			state("c", workqueue.KeyState_QUEUED, 5),
+			state("d", workqueue.KeyState_IN_PROGRESS, 0),
+		}},
+	}
+
+	srv, err := New(backends)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	resp, err := srv.ListKeys(context.Background(), &workqueue.ListKeysRequest{})
+	if err != nil {
+		t.Fatalf("ListKeys() error = %v", err)
+	}
+	var got []string
+	for _, ks := range resp.GetKeys() {
+		got = append(got, ks.GetKey())
+	}
+	if diff := cmp.Diff([]string{"d", "c", "a", "b"}, got); diff != "" {
+		t.Errorf("ListKeys() keys (-want, +got):\n%s", diff)
+	}
+}
+
+func TestListKeys_surfacesBackendErrors(t *testing.T) {
+	srv, err := New([]workqueue.WorkqueueServiceClient{
+		&mockClient{},
+		&failingClient{listErr: status.Error(codes.Unimplemented, "no listing")},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := srv.ListKeys(context.Background(), &workqueue.ListKeysRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ListKeys() error = %v, wanted Unimplemented", err)
+	}
+}
+
+func TestProcessBatch_splitsAcrossShards(t *testing.T) {
+	mocks := make([]*mockClient, 3)
+	backends := make([]workqueue.WorkqueueServiceClient, 3)
+	for i := range 3 {
+		mocks[i] = &mockClient{}
+		backends[i] = mocks[i]
+	}
+
+	srv, err := New(backends)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := &workqueue.ProcessBatchRequest{}
+	for i := range 100 {
+		req.Requests = append(req.Requests, &workqueue.ProcessRequest{Key: fmt.Sprintf("test-key-%d", i)})
+	}
+
+	resp, err := srv.ProcessBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+	if len(resp.GetResults()) != len(req.GetRequests()) {
+		t.Fatalf("ProcessBatch() results: got = %d, wanted = %d", len(resp.GetResults()), len(req.GetRequests()))
+	}
+	for i, r := range resp.GetResults() {
+		if r.GetKey() != req.GetRequests()[i].GetKey() {
+			t.Errorf("result %d: key = %q, wanted %q", i, r.GetKey(), req.GetRequests()[i].GetKey())
+		}
+		if err := r.Err(); err != nil {
+			t.Errorf("result %d: error = %v", i, err)
+		}
+	}
+	for i, m := range mocks {
+		if got := m.batchCount.Load(); got != 1 {
+			t.Errorf("shard %d: ProcessBatch() called %d times, wanted 1", i, got)
+		}
+		if got := m.processCount.Load(); got != 0 {
+			t.Errorf("shard %d: Process() called %d times, wanted 0", i, got)
+		}
+	}
+}
+
+func TestProcessBatch_reportsShardFailurePerKey(t *testing.T) {
+	backendErr := status.Error(codes.Unavailable, "backend unavailable")
+	srv, err := New([]workqueue.WorkqueueServiceClient{
+		&mockClient{},
+		&failingClient{batchErr: backendErr},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req := &workqueue.ProcessBatchRequest{}
+	for i := range 20 {
+		req.Requests = append(req.Requests, &workqueue.ProcessRequest{Key: fmt.Sprintf("test-key-%d", i)})
+	}
+
+	resp, err := srv.ProcessBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+	for i, r := range resp.GetResults() {
+		key := req.GetRequests()[i].GetKey()
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		want := codes.OK
+		if int(h.Sum32())%2 == 1 {
+			want = codes.Unavailable
+		}
+		if got := status.Code(r.Err()); got != want {
+			t.Errorf("result for %q: code = %v, wanted %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hyperqueue
+
+import (
+	"cmp"
+	"context"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/workqueue"
+)
+
+// shardFor returns the client of key's primary shard.
+func (s *server) shardFor(key string) workqueue.WorkqueueServiceClient {
+	return s.backends[s.shardIndex(key)].Client
+}
+
+// shardIndex returns the index of key's primary shard, ignoring backend
+// health.
+func (s *server) shardIndex(key string) int {
+	if s.rendezvous {
+		return s.candidates(key)[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.slots[int(h.Sum32())%len(s.slots)]
+}
+
+// candidates returns the indices of every backend in key's routing order:
+// by descending weighted rendezvous score, or around the hash ring starting
+// from key's slot.
+func (s *server) candidates(key string) []int {
+	if s.rendezvous {
+		type scored struct {
+			idx   int
+			score float64
+		}
+		scores := make([]scored, 0, len(s.backends))
+		for i, b := range s.backends {
+			scores = append(scores, scored{idx: i, score: rendezvousScore(b.Name, max(b.Weight, 1), key)})
+		}
+		slices.SortFunc(scores, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+		order := make([]int, 0, len(scores))
+		for _, sc := range scores {
+			order = append(order, sc.idx)
+		}
+		return order
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	start := int(h.Sum32()) % len(s.slots)
+	order := make([]int, 0, len(s.backends))
+	seen := make([]bool, len(s.backends))
+	for i := range s.slots {
+		idx := s.slots[(start+i)%len(s.slots)]
+		if !seen[idx] {
+			seen[idx] = true
+			order = append(order, idx)
+		}
+	}
+	return order
+}
+
+// rendezvousScore is the weighted rendezvous score of the backend called name
+// for key: -weight / ln(u), with u the (name, key) hash mapped into (0, 1).
+// Keys go to the backend with the highest score, which gives each backend a
+// share of keys proportional to its weight.
+func rendezvousScore(name string, weight int, key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0x00})
+	h.Write([]byte(key))
+	// FNV alone barely separates names that differ in a single byte, so mix
+	// the hash (with the murmur3 finalizer) before using it.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	// Use the top 53 bits, offset by half a step, so u is never 0 or 1.
+	u := (float64(x>>11) + 0.5) / (1 << 53)
+	return -float64(weight) / math.Log(u)
+}
+
+// route returns the index of the shard to send key to: its primary shard, or
+// with failover enabled, the first shard in its routing order that is
+// healthy. When no shard is healthy it falls back to the primary.
+func (s *server) route(ctx context.Context, key string) int {
+	if s.breaker == nil {
+		return s.shardIndex(key)
+	}
+	order := s.candidates(key)
+	for _, idx := range order {
+		if s.healthy(ctx, idx) {
+			if idx != order[0] {
+				clog.DebugContextf(ctx, "Routing key %q to backend %q: primary %q is unhealthy", key, s.backends[idx].Name, s.backends[order[0]].Name)
+			}
+			return idx
+		}
+	}
+	return order[0]
+}
+
+// healthy reports whether traffic may be routed to the backend at idx. A
+// backend whose circuit is open is unhealthy. A backend that has failed since
+// its last success and has a Health client is probed before traffic is
+// routed back to it.
+func (s *server) healthy(ctx context.Context, idx int) bool {
+	b := s.backends[idx]
+	if ok, _ := s.breaker.Allow(b.Name); !ok {
+		return false
+	}
+	if b.Health == nil || !s.suspect[idx].Load() {
+		return true
+	}
+	return s.probe(ctx, idx)
+}
+
+// healthProbe records when a backend last failed its health probe.
+type healthProbe struct {
+	mu       sync.Mutex
+	failedAt time.Time
+}
+
+// probe checks the health of the backend at idx. A backend that failed a
+// probe within the last healthInterval is reported unhealthy without probing
+// it again, and concurrent callers wait for a single probe rather than each
+// issuing their own, so a suspect backend costs at most one Health.Check per
+// interval however many keys are routed.
+func (s *server) probe(ctx context.Context, idx int) bool {
+	p := &s.probes[idx]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !s.suspect[idx].Load() {
+		// Another caller's probe found it healthy while we waited.
+		return true
+	}
+	if !p.failedAt.IsZero() && time.Since(p.failedAt) < s.healthInterval {
+		return false
+	}
+
+	b := s.backends[idx]
+	resp, err := b.Health.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		clog.WarnContextf(ctx, "Backend %q failed its health check (status: %v): %v", b.Name, resp.GetStatus(), err)
+		p.failedAt = time.Now()
+		s.breaker.RecordFailure(b.Name)
+		return false
+	}
+	p.failedAt = time.Time{}
+	s.suspect[idx].Store(false)
+	s.breaker.RecordSuccess(b.Name)
+	return true
+}
+
+// observe records the outcome of a call to the backend at idx against its
+// circuit. Only unavailability counts as a failure: any other error is the
+// backend answering. Errors caused by the caller's context are ignored.
+func (s *server) observe(ctx context.Context, idx int, err error) {
+	if s.breaker == nil || ctx.Err() != nil {
+		return
+	}
+	name := s.backends[idx].Name
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		s.suspect[idx].Store(true)
+		s.breaker.RecordFailure(name)
+	default:
+		s.suspect[idx].Store(false)
+		s.breaker.RecordSuccess(name)
+	}
+}
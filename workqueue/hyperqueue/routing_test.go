@@ -0,0 +1,297 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hyperqueue
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/breaker"
+	"chainguard.dev/driftlessaf/workqueue"
+)
+
+func TestNewWithBackends(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends []Backend
+		wantErr  bool
+	}{{
+		name:     "valid",
+		backends: []Backend{{Name: "a", Client: &mockClient{}}, {Name: "b", Client: &mockClient{}, Weight: 3}},
+	}, {
+		name:     "missing client",
+		backends: []Backend{{Name: "a"}},
+		wantErr:  true,
+	}, {
+		name:     "missing name",
+		backends: []Backend{{Client: &mockClient{}}},
+		wantErr:  true,
+	}, {
+		name:     "negative weight",
+		backends: []Backend{{Name: "a", Client: &mockClient{}, Weight: -1}},
+		wantErr:  true,
+	}, {
+		name:     "duplicate name",
+		backends: []Backend{{Name: "a", Client: &mockClient{}}, {Name: "a", Client: &mockClient{}}},
+		wantErr:  true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWithBackends(tt.backends); (err != nil) != tt.wantErr {
+				t.Errorf("NewWithBackends() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newServer returns a server over backends, failing the test on error.
+func newServer(t *testing.T, backends []Backend, opts ...Option) *server {
+	t.Helper()
+	srv, err := NewWithBackends(backends, opts...)
+	if err != nil {
+		t.Fatalf("NewWithBackends() error = %v", err)
+	}
+	return srv.(*server)
+}
+
+func TestWeightedDistribution(t *testing.T) {
+	const numKeys = 20000
+	const tolerance = 0.1
+
+	for _, rendezvous := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rendezvous=%t", rendezvous), func(t *testing.T) {
+			var opts []Option
+			if rendezvous {
+				opts = append(opts, WithRendezvousHashing())
+			}
+			weights := []int{1, 3, 0}
+			backends := make([]Backend, len(weights))
+			for i, w := range weights {
+				backends[i] = Backend{Name: fmt.Sprintf("backend-%d", i), Client: &mockClient{}, Weight: w}
+			}
+			srv := newServer(t, backends, opts...)
+
+			counts := make([]int, len(backends))
+			for i := range numKeys {
+				counts[srv.shardIndex(fmt.Sprintf("key-%d", i))]++
+			}
+
+			// A zero weight counts as 1, so the shares are 1:3:1.
+			for i, w := range []float64{1, 3, 1} {
+				want := numKeys * w / 5
+				if dev := (float64(counts[i]) - want) / want; dev > tolerance || dev < -tolerance {
+					t.Errorf("backend %d: got = %d keys, wanted ~%d", i, counts[i], int(want))
+				}
+			}
+		})
+	}
+}
+
+func TestRendezvousHashing_minimalMovement(t *testing.T) {
+	const numKeys = 10000
+
+	backends := func(names ...string) []Backend {
+		bs := make([]Backend, 0, len(names))
+		for _, n := range names {
+			bs = append(bs, Backend{Name: n, Client: &mockClient{}})
+		}
+		return bs
+	}
+	before := newServer(t, backends("a", "b", "c"), WithRendezvousHashing())
+	grown := newServer(t, backends("a", "b", "c", "d"), WithRendezvousHashing())
+	shrunk := newServer(t, backends("a", "c"), WithRendezvousHashing())
+
+	name := func(s *server, key string) string { return s.backends[s.shardIndex(key)].Name }
+
+	moved := 0
+	for i := range numKeys {
+		key := fmt.Sprintf("key-%d", i)
+		was := name(before, key)
+
+		// Adding a backend only moves keys onto the new backend.
+		if now := name(grown, key); now != was {
+			moved++
+			if now != "d" {
+				t.Fatalf("key %q moved from %q to %q when adding d", key, was, now)
+			}
+		}
+
+		// Removing a backend only moves that backend's keys.
+		if now := name(shrunk, key); was != "b" && now != was {
+			t.Fatalf("key %q moved from %q to %q when removing b", key, was, now)
+		}
+	}
+
+	// The new backend takes roughly its fair share: 1/4 of the keys.
+	if want := numKeys / 4; moved < want*8/10 || moved > want*12/10 {
+		t.Errorf("adding a backend moved %d keys, wanted ~%d", moved, want)
+	}
+}
+
+// flakyClient fails Process with Unavailable while down is set.
+type flakyClient struct {
+	mockClient
+	down atomic.Bool
+}
+
+func (f *flakyClient) Process(ctx context.Context, req *workqueue.ProcessRequest, opts ...grpc.CallOption) (*workqueue.ProcessResponse, error) {
+	if f.down.Load() {
+		return nil, status.Error(codes.Unavailable, "backend down")
+	}
+	return f.mockClient.Process(ctx, req, opts...)
+}
+
+// healthClient reports the health status it is set to.
+type healthClient struct {
+	healthpb.HealthClient
+	status atomic.Int32
+	checks atomic.Int64
+}
+
+func (h *healthClient) Check(context.Context, *healthpb.HealthCheckRequest, ...grpc.CallOption) (*healthpb.HealthCheckResponse, error) {
+	h.checks.Add(1)
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_ServingStatus(h.status.Load())}, nil
+}
+
+func TestFailover(t *testing.T) {
+	for _, rendezvous := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rendezvous=%t", rendezvous), func(t *testing.T) {
+			ctx := t.Context()
+			flaky := &flakyClient{}
+			health := &healthClient{}
+			health.status.Store(int32(healthpb.HealthCheckResponse_SERVING))
+			others := []*mockClient{{}, {}}
+
+			opts := []Option{WithFailover(breaker.New(
+				breaker.WithFailureThreshold(1),
+				breaker.WithBaseDelay(time.Nanosecond),
+				breaker.WithMaxDelay(time.Microsecond),
+			)), WithHealthCheckInterval(time.Nanosecond)}
+			if rendezvous {
+				opts = append(opts, WithRendezvousHashing())
+			}
+			srv := newServer(t, []Backend{
+				{Name: "flaky", Client: flaky, Health: health},
+				{Name: "b", Client: others[0]},
+				{Name: "c", Client: others[1]},
+			}, opts...)
+
+			// Find a key whose primary is the flaky backend.
+			var key string
+			for i := range 1000 {
+				if k := fmt.Sprintf("key-%d", i); srv.shardIndex(k) == 0 {
+					key = k
+					break
+				}
+			}
+			if key == "" {
+				t.Fatal("could not find a key that routes to the flaky backend")
+			}
+			process := func() error {
+				_, err := srv.Process(ctx, &workqueue.ProcessRequest{Key: key})
+				return err
+			}
+
+			// While healthy, the key goes to its primary.
+			if err := process(); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if got := flaky.processCount.Load(); got != 1 {
+				t.Errorf("flaky backend calls = %d, wanted 1", got)
+			}
+
+			// The first failure surfaces and trips the circuit.
+			flaky.down.Store(true)
+			health.status.Store(int32(healthpb.HealthCheckResponse_NOT_SERVING))
+			if err := process(); status.Code(err) != codes.Unavailable {
+				t.Fatalf("Process() error = %v, wanted Unavailable", err)
+			}
+
+			// Subsequent requests fail over once the health probe confirms
+			// the backend is still down.
+			time.Sleep(time.Millisecond)
+			if err := process(); err != nil {
+				t.Fatalf("Process() during failover error = %v", err)
+			}
+			if got := others[0].processCount.Load() + others[1].processCount.Load(); got != 1 {
+				t.Errorf("fallback backend calls = %d, wanted 1", got)
+			}
+			if health.checks.Load() == 0 {
+				t.Error("the failed backend was not health-checked")
+			}
+
+			// Once the backend recovers, its keys route back to it.
+			flaky.down.Store(false)
+			health.status.Store(int32(healthpb.HealthCheckResponse_SERVING))
+			time.Sleep(time.Millisecond)
+			if err := process(); err != nil {
+				t.Fatalf("Process() after recovery error = %v", err)
+			}
+			if got := flaky.processCount.Load(); got != 2 {
+				t.Errorf("flaky backend calls after recovery = %d, wanted 2", got)
+			}
+		})
+	}
+}
+
+func TestFailover_probesOncePerInterval(t *testing.T) {
+	flaky := &flakyClient{}
+	flaky.down.Store(true)
+	health := &healthClient{}
+	health.status.Store(int32(healthpb.HealthCheckResponse_NOT_SERVING))
+	srv := newServer(t, []Backend{
+		{Name: "flaky", Client: flaky, Health: health},
+		{Name: "b", Client: &mockClient{}},
+	}, WithFailover(breaker.New(
+		breaker.WithFailureThreshold(1),
+		breaker.WithBaseDelay(time.Nanosecond),
+		breaker.WithMaxDelay(time.Microsecond),
+	)), WithHealthCheckInterval(time.Hour))
+
+	var keys []string
+	for i := 0; len(keys) < 20; i++ {
+		if k := fmt.Sprintf("key-%d", i); srv.shardIndex(k) == 0 {
+			keys = append(keys, k)
+		}
+	}
+	// The first call fails on the backend and marks it suspect.
+	if _, err := srv.Process(t.Context(), &workqueue.ProcessRequest{Key: keys[0]}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Process() error = %v, wanted Unavailable", err)
+	}
+	for _, key := range keys[1:] {
+		time.Sleep(time.Microsecond) // Let the circuit half-open.
+		if _, err := srv.Process(t.Context(), &workqueue.ProcessRequest{Key: key}); err != nil {
+			t.Fatalf("Process() during failover error = %v", err)
+		}
+	}
+	if got := health.checks.Load(); got != 1 {
+		t.Errorf("health checks = %d, wanted 1 per interval", got)
+	}
+}
+
+func TestFailover_ignoresApplicationErrors(t *testing.T) {
+	failing := &failingClient{processErr: status.Error(codes.InvalidArgument, "bad key")}
+	srv := newServer(t, []Backend{{Name: "only", Client: failing}},
+		WithFailover(breaker.New(breaker.WithFailureThreshold(1))))
+
+	for range 3 {
+		if _, err := srv.Process(t.Context(), &workqueue.ProcessRequest{Key: "k"}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Process() error = %v, wanted InvalidArgument", err)
+		}
+	}
+	if ok, _ := srv.breaker.Allow("only"); !ok {
+		t.Error("application errors tripped the circuit")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ func NewWorkQueue(limit int) workqueue.Interface {
 
 		wip:   make(map[string]queueItem, limit),
 		queue: make(map[string]queueItem, 10),
+		dead:  make(map[string]deadItem),
 	}
 }
 
@@ -35,15 +37,27 @@ type wq struct {
 	rw    sync.RWMutex
 	wip   map[string]queueItem
 	queue map[string]queueItem
+	dead  map[string]deadItem
 }
 
 type queueItem struct {
 	workqueue.Options
 	attempts int
 	queued   time.Time
+	// errors are the recorded attempt errors, oldest first.
+	errors []string
+}
+
+type deadItem struct {
+	queueItem
+	failed  time.Time
+	failure workqueue.FailureDetails
 }
 
 var _ workqueue.Interface = (*wq)(nil)
+var _ workqueue.Admin = (*wq)(nil)
+var _ workqueue.Lister = (*wq)(nil)
+var _ workqueue.LimitEnumerator = (*wq)(nil)
 
 // Queue implements workqueue.Interface.
 func (w *wq) Queue(_ context.Context, key string, opts workqueue.Options) error {
@@ -102,31 +116,124 @@ func (w *wq) Get(_ context.Context, key string) (*workqueue.KeyState, error) {
 	defer w.rw.RUnlock()
 
 	if qi, ok := w.wip[key]; ok {
-		return &workqueue.KeyState{
-			Key:           key,
-			Status:        workqueue.KeyState_IN_PROGRESS,
-			Priority:      qi.Priority,
-			Attempts:      int32(qi.attempts), //nolint:gosec  // we're not worried about this overflowing
-			NotBeforeTime: qi.NotBefore.Unix(),
-		}, nil
+		return inProgressState(key, qi), nil
 	}
 
 	if qi, ok := w.queue[key]; ok {
-		return &workqueue.KeyState{
-			Key:           key,
-			Status:        workqueue.KeyState_QUEUED,
-			Priority:      qi.Priority,
-			Attempts:      int32(qi.attempts), //nolint:gosec  // we're not worried about this overflowing
-			QueuedTime:    qi.queued.Unix(),
-			NotBeforeTime: qi.NotBefore.Unix(),
-		}, nil
+		return queuedState(key, qi), nil
+	}
+
+	if di, ok := w.dead[key]; ok {
+		return deadLetterState(key, di), nil
 	}
 
 	return nil, status.Errorf(codes.NotFound, "key %q not found", key)
 }
 
+// List implements workqueue.Lister.
+func (w *wq) List(_ context.Context, prefix string) ([]*workqueue.KeyState, error) {
+	w.rw.RLock()
+	defer w.rw.RUnlock()
+
+	var states []*workqueue.KeyState
+	for key, qi := range w.wip {
+		if strings.HasPrefix(key, prefix) {
+			states = append(states, inProgressState(key, qi))
+		}
+	}
+	for key, qi := range w.queue {
+		if strings.HasPrefix(key, prefix) {
+			states = append(states, queuedState(key, qi))
+		}
+	}
+	for key, di := range w.dead {
+		if strings.HasPrefix(key, prefix) {
+			states = append(states, deadLetterState(key, di))
+		}
+	}
+	return states, nil
+}
+
+func inProgressState(key string, qi queueItem) *workqueue.KeyState {
+	return &workqueue.KeyState{
+		Key:           key,
+		Status:        workqueue.KeyState_IN_PROGRESS,
+		Priority:      qi.Priority,
+		Attempts:      int32(qi.attempts), //nolint:gosec  // we're not worried about this overflowing
+		NotBeforeTime: qi.NotBefore.Unix(),
+		AttemptErrors: qi.errors,
+	}
+}
+
+func queuedState(key string, qi queueItem) *workqueue.KeyState {
+	return &workqueue.KeyState{
+		Key:           key,
+		Status:        workqueue.KeyState_QUEUED,
+		Priority:      qi.Priority,
+		Attempts:      int32(qi.attempts), //nolint:gosec  // we're not worried about this overflowing
+		QueuedTime:    qi.queued.Unix(),
+		NotBeforeTime: qi.NotBefore.Unix(),
+		AttemptErrors: qi.errors,
+	}
+}
+
+func deadLetterState(key string, di deadItem) *workqueue.KeyState {
+	return &workqueue.KeyState{
+		Key:                 key,
+		Status:              workqueue.KeyState_DEAD_LETTER,
+		Priority:            di.Priority,
+		Attempts:            int32(di.attempts), //nolint:gosec  // we're not worried about this overflowing
+		QueuedTime:          di.queued.Unix(),
+		NotBeforeTime:       di.NotBefore.Unix(),
+		LastError:           di.failure.LastError,
+		FailureReason:       di.failure.Reason,
+		InfrastructureError: di.failure.Infrastructure,
+		AttemptErrors:       di.failure.AttemptErrors,
+	}
+}
+
+// ReplayDeadLetter implements workqueue.Admin.
+func (w *wq) ReplayDeadLetter(_ context.Context, key string, opts workqueue.Options) error {
+	w.rw.Lock()
+	defer w.rw.Unlock()
+	di, ok := w.dead[key]
+	if !ok {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	}
+	if opts.Priority == 0 {
+		opts.Priority = di.Priority
+	}
+	delete(w.dead, key)
+	if qi, ok := w.queue[key]; ok {
+		mergeOptions(&qi.Options, opts)
+		w.queue[key] = qi
+		return nil
+	}
+	w.queue[key] = queueItem{
+		Options: opts,
+		queued:  time.Now().UTC(),
+	}
+	return nil
+}
+
+// PurgeDeadLetter implements workqueue.Admin.
+func (w *wq) PurgeDeadLetter(_ context.Context, key string) error {
+	w.rw.Lock()
+	defer w.rw.Unlock()
+	if _, ok := w.dead[key]; !ok {
+		return status.Errorf(codes.NotFound, "key %q is not dead-lettered", key)
+	}
+	delete(w.dead, key)
+	return nil
+}
+
 // Enumerate implements workqueue.Interface.
-func (w *wq) Enumerate(_ context.Context) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+func (w *wq) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+	return w.EnumerateN(ctx, w.limit)
+}
+
+// EnumerateN implements workqueue.LimitEnumerator.
+func (w *wq) EnumerateN(_ context.Context, limit int) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
 	w.rw.RLock()
 	defer w.rw.RUnlock()
 	wip := make([]workqueue.ObservedInProgressKey, 0, len(w.wip))
@@ -134,8 +241,9 @@ func (w *wq) Enumerate(_ context.Context) ([]workqueue.ObservedInProgressKey, []
 		workqueue.Options
 		key      string
 		attempts int
+		errors   []string
 		ts       time.Time
-	}, 0, w.limit+1)
+	}, 0, limit+1)
 
 	for k := range w.wip {
 		wip = append(wip, &inProgressKey{
@@ -154,11 +262,13 @@ func (w *wq) Enumerate(_ context.Context) ([]workqueue.ObservedInProgressKey, []
 			workqueue.Options
 			key      string
 			attempts int
+			errors   []string
 			ts       time.Time
 		}{
 			Options:  ts.Options,
 			key:      k,
 			attempts: ts.attempts,
+			errors:   ts.errors,
 			ts:       ts.queued,
 		})
 		sort.Slice(qd, func(i, j int) bool {
@@ -167,8 +277,8 @@ func (w *wq) Enumerate(_ context.Context) ([]workqueue.ObservedInProgressKey, []
 			}
 			return qd[i].Priority > qd[j].Priority
 		})
-		if len(qd) > w.limit {
-			qd = qd[:w.limit]
+		if len(qd) > limit {
+			qd = qd[:limit]
 		}
 	}
 
@@ -179,16 +289,25 @@ func (w *wq) Enumerate(_ context.Context) ([]workqueue.ObservedInProgressKey, []
 			wq:       w,
 			key:      q.key,
 			attempts: q.attempts,
+			errors:   q.errors,
+		})
+	}
+
+	var dl []workqueue.DeadLetteredKey
+	for k, di := range w.dead {
+		dl = append(dl, &deadLetteredKey{
+			key:  k,
+			item: di,
 		})
 	}
-	// In-memory implementation doesn't support dead-lettering, return empty slice
-	return wip, qk, nil, nil
+	return wip, qk, dl, nil
 }
 
 type inProgressKey struct {
 	workqueue.Options
 
 	attempts int
+	errors   []string
 
 	wq  *wq
 	key string
@@ -227,8 +346,10 @@ func (o *inProgressKey) RequeueWithOptions(_ context.Context, opts workqueue.Opt
 		opts.Priority = o.Priority()
 	}
 
-	// Determine the attempt count to use when requeueing
+	// Determine the attempt count and errors to use when requeueing
 	attempts := o.attempts
+	errors := workqueue.RecordAttemptError(o.errors, opts.AttemptError)
+	opts.AttemptError = nil
 
 	// Handle custom delay if specified
 	switch {
@@ -243,6 +364,7 @@ func (o *inProgressKey) RequeueWithOptions(_ context.Context, opts workqueue.Opt
 		// Reset attempts when using custom delay, as this indicates periodic revisit pattern
 		// rather than retry due to failure
 		attempts = 0
+		errors = nil
 		opts.NotBefore = time.Now().UTC().Add(opts.Delay)
 	case opts.Priority > 0:
 		// If no custom delay and priority is set, use the standard backoff
@@ -257,6 +379,7 @@ func (o *inProgressKey) RequeueWithOptions(_ context.Context, opts workqueue.Opt
 			Options:  opts,
 			attempts: attempts,
 			queued:   time.Now().UTC(),
+			errors:   errors,
 		}
 	} else {
 		mergeOptions(&qi.Options, opts)
@@ -273,15 +396,28 @@ func (o *inProgressKey) GetAttempts() int {
 }
 
 // Deadletter implements workqueue.OwnedInProgressKey.
-func (o *inProgressKey) Deadletter(_ context.Context) error {
+func (o *inProgressKey) Deadletter(ctx context.Context) error {
+	return o.DeadletterWithError(ctx, nil)
+}
+
+// DeadletterWithError implements workqueue.OwnedInProgressKey.
+func (o *inProgressKey) DeadletterWithError(_ context.Context, err error) error {
 	if o.ownerCancel != nil {
 		o.ownerCancel()
 	}
 
-	// For in-memory implementation, we simply remove the task without requeueing it
 	o.wq.rw.Lock()
 	defer o.wq.rw.Unlock()
 	delete(o.wq.wip, o.key)
+	o.wq.dead[o.key] = deadItem{
+		queueItem: queueItem{
+			Options:  o.Options,
+			attempts: o.attempts,
+			queued:   time.Now().UTC(),
+		},
+		failed:  time.Now().UTC(),
+		failure: workqueue.NewFailureDetails(err, o.errors),
+	}
 	return nil
 }
 
@@ -296,6 +432,7 @@ func (o *inProgressKey) Complete(_ context.Context) error {
 	o.wq.rw.Lock()
 	defer o.wq.rw.Unlock()
 	delete(o.wq.wip, o.key)
+	delete(o.wq.dead, o.key)
 	return nil
 }
 
@@ -308,6 +445,7 @@ type queuedKey struct {
 	workqueue.Options
 
 	attempts int
+	errors   []string
 
 	wq  *wq
 	key string
@@ -343,6 +481,7 @@ func (q *queuedKey) Start(ctx context.Context) (workqueue.OwnedInProgressKey, er
 		Options:  q.Options,
 		attempts: q.attempts,
 		queued:   time.Now().UTC(),
+		errors:   q.errors,
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -351,7 +490,40 @@ func (q *queuedKey) Start(ctx context.Context) (workqueue.OwnedInProgressKey, er
 		wq:          q.wq,
 		key:         q.key,
 		attempts:    q.attempts + 1,
+		errors:      q.errors,
 		ownerCtx:    ctx,
 		ownerCancel: cancel,
 	}, nil
 }
+
+type deadLetteredKey struct {
+	key  string
+	item deadItem
+}
+
+var _ workqueue.DeadLetteredKey = (*deadLetteredKey)(nil)
+
+// Name implements workqueue.Key.
+func (d *deadLetteredKey) Name() string {
+	return d.key
+}
+
+// Priority implements workqueue.Key.
+func (d *deadLetteredKey) Priority() int64 {
+	return d.item.Priority
+}
+
+// GetFailedTime implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetFailedTime() time.Time {
+	return d.item.failed
+}
+
+// GetAttempts implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetAttempts() int {
+	return d.item.attempts
+}
+
+// GetFailureDetails implements workqueue.DeadLetteredKey.
+func (d *deadLetteredKey) GetFailureDetails() workqueue.FailureDetails {
+	return d.item.failure
+}
@@ -28,6 +28,10 @@ func TestWorkQueue(t *testing.T) {
 	conformance.TestMaxRetry(t, NewWorkQueue)
 
 	conformance.TestBackoffDelay(t, NewWorkQueue)
+
+	conformance.TestDeadLetterAdmin(t, NewWorkQueue)
+
+	conformance.TestList(t, NewWorkQueue)
 }
 
 func Test_Get_KeyNotFound(t *testing.T) {
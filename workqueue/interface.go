@@ -51,6 +51,17 @@ type Interface interface {
 	Get(ctx context.Context, key string) (*KeyState, error)
 }
 
+// LimitEnumerator is an optional interface for implementations that can
+// enumerate past their configured number of queued keys. The dispatcher uses
+// it to look beyond queued keys it has to skip (for example, because their
+// concurrency group is saturated), so those keys cannot crowd out keys it
+// could launch. Check for it with a type assertion on an Interface.
+type LimitEnumerator interface {
+	// EnumerateN is like Enumerate, but returns up to n queued keys instead
+	// of the configured "N".
+	EnumerateN(ctx context.Context, n int) ([]ObservedInProgressKey, []QueuedKey, []DeadLetteredKey, error)
+}
+
 // Options is a set of options that can be passed when queuing a key.
 type Options struct {
 	// Priority is the priority of the key.
@@ -90,6 +101,12 @@ type Options struct {
 	//   - between two non-floor enqueues the earliest NotBefore wins (the default).
 	// Set via RequeueNotBefore.
 	NotBeforeFloor bool
+
+	// AttemptError is the error that failed the attempt being requeued.
+	// Implementations record it on the key (see RecordAttemptError), so a key
+	// that is eventually dead-lettered carries its recent failure history.
+	// Queue ignores it.
+	AttemptError error
 }
 
 // Key is a shared interface that all key types must implement.
@@ -144,6 +161,10 @@ type OwnedInProgressKey interface {
 	// failed after exceeding the maximum retry attempts.
 	Deadletter(context.Context) error
 
+	// DeadletterWithError is like Deadletter, but records err as the cause on
+	// the dead-lettered key (see NewFailureDetails).
+	DeadletterWithError(context.Context, error) error
+
 	// GetAttempts returns the current attempt count for the key.
 	GetAttempts() int
 
@@ -161,4 +182,7 @@ type DeadLetteredKey interface {
 
 	// GetAttempts returns the number of attempts before the key was dead-lettered.
 	GetAttempts() int
+
+	// GetFailureDetails returns what was recorded about why the key failed.
+	GetFailureDetails() FailureDetails
 }
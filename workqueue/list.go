@@ -0,0 +1,72 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Lister is implemented by workqueues that can report the state of every key
+// they hold. Unlike Enumerate, which only returns the next keys eligible to
+// run, List includes queued keys whose NotBefore is still in the future, and
+// every queued key however deep the queue. Check for it with a type assertion
+// on an Interface.
+type Lister interface {
+	// List returns the state of every key with the given prefix (every key
+	// when prefix is empty). A key that is both in progress and queued again
+	// is listed once in each state.
+	List(ctx context.Context, prefix string) ([]*KeyState, error)
+}
+
+// SortKeyStates orders states by status (in progress, queued, then
+// dead-lettered), then by descending priority, then by key, the order
+// operator tooling displays them in.
+func SortKeyStates(states []*KeyState) {
+	rank := map[KeyState_Status]int{
+		KeyState_IN_PROGRESS: 0,
+		KeyState_QUEUED:      1,
+		KeyState_DEAD_LETTER: 2,
+	}
+	slices.SortFunc(states, func(a, b *KeyState) int {
+		if c := cmp.Compare(rank[a.GetStatus()], rank[b.GetStatus()]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.GetPriority(), a.GetPriority()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetKey(), b.GetKey())
+	})
+}
+
+// ServeListKeys implements the ListKeys RPC over wq, so a
+// WorkqueueServiceServer fronting a workqueue can delegate to it. It fails
+// with Unimplemented when wq does not implement Lister.
+func ServeListKeys(ctx context.Context, wq Interface, req *ListKeysRequest) (*ListKeysResponse, error) {
+	lwq, ok := wq.(Lister)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "workqueue does not support listing keys")
+	}
+	keys, err := lwq.List(ctx, req.GetPrefix())
+	if err != nil {
+		return nil, err
+	}
+	SortKeyStates(keys)
+	return &ListKeysResponse{Keys: keys}, nil
+}
+
+// ServeGetKeyState implements the GetKeyState RPC over wq, so a
+// WorkqueueServiceServer fronting a workqueue can delegate to it. To report
+// the schedules of recurring keys as well, pass a schedule.Scheduler, which
+// wraps wq, rather than wq itself.
+func ServeGetKeyState(ctx context.Context, wq Interface, req *GetKeyStateRequest) (*KeyState, error) {
+	return wq.Get(ctx, req.GetKey())
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package workqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSortKeyStates(t *testing.T) {
+	states := []*workqueue.KeyState{
+		{Key: "dead", Status: workqueue.KeyState_DEAD_LETTER, Priority: 9},
+		{Key: "b", Status: workqueue.KeyState_QUEUED},
+		{Key: "a", Status: workqueue.KeyState_QUEUED},
+		{Key: "urgent", Status: workqueue.KeyState_QUEUED, Priority: 5},
+		{Key: "running", Status: workqueue.KeyState_IN_PROGRESS},
+	}
+	workqueue.SortKeyStates(states)
+
+	got := make([]string, 0, len(states))
+	for _, s := range states {
+		got = append(got, s.GetKey())
+	}
+	if diff := cmp.Diff([]string{"running", "urgent", "a", "b", "dead"}, got); diff != "" {
+		t.Errorf("SortKeyStates() (-want, +got):\n%s", diff)
+	}
+}
+
+// notLister hides the List method of the workqueue it wraps.
+type notLister struct {
+	workqueue.Interface
+}
+
+func TestServeListKeys_unimplemented(t *testing.T) {
+	wq := notLister{inmem.NewWorkQueue(5)}
+
+	if _, err := workqueue.ServeListKeys(t.Context(), wq, &workqueue.ListKeysRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("ServeListKeys() = %v, wanted Unimplemented", err)
+	}
+}
+
+func TestServeListKeys(t *testing.T) {
+	ctx := t.Context()
+	// A limit of one would hide all but one queued key from Enumerate.
+	wq := inmem.NewWorkQueue(1)
+	deadletter(ctx, t, wq, "repo/dead")
+	if err := wq.Queue(ctx, "repo/later", workqueue.Options{NotBefore: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Queue() = %v", err)
+	}
+	for _, key := range []string{"repo/now", "other/now"} {
+		if err := wq.Queue(ctx, key, workqueue.Options{Priority: 1}); err != nil {
+			t.Fatalf("Queue() = %v", err)
+		}
+	}
+
+	resp, err := workqueue.ServeListKeys(ctx, wq, &workqueue.ListKeysRequest{Prefix: "repo/"})
+	if err != nil {
+		t.Fatalf("ServeListKeys() = %v", err)
+	}
+	got := make([]string, 0, len(resp.GetKeys()))
+	for _, s := range resp.GetKeys() {
+		got = append(got, s.GetStatus().String()+" "+s.GetKey())
+	}
+	want := []string{"QUEUED repo/now", "QUEUED repo/later", "DEAD_LETTER repo/dead"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ServeListKeys() keys (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, time.March, 4, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{{
+		expr: "* * * * *",
+		want: time.Date(2026, time.March, 4, 10, 18, 0, 0, time.UTC),
+	}, {
+		expr: "0 * * * *",
+		want: time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC),
+	}, {
+		expr: "*/15 * * * *",
+		want: time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC),
+	}, {
+		expr: "5,40 9-11 * * *",
+		want: time.Date(2026, time.March, 4, 10, 40, 0, 0, time.UTC),
+	}, {
+		expr: "@daily",
+		want: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC),
+	}, {
+		expr: "0 9 * * mon-fri",
+		want: time.Date(2026, time.March, 5, 9, 0, 0, 0, time.UTC),
+	}, {
+		expr: "30 8 * * 7",
+		want: time.Date(2026, time.March, 8, 8, 30, 0, 0, time.UTC),
+	}, {
+		expr: "0 0 1 jan *",
+		want: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		// Both day fields restricted: either one matching runs.
+		expr: "0 0 13 * fri",
+		want: time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC),
+	}, {
+		expr: "0 0 31 * *",
+		want: time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}, {
+		expr: "0 0 30 2 *",
+		want: time.Time{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			spec, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCron() = %v", err)
+			}
+			if got := spec.next(from); !got.Equal(tt.want) {
+				t.Errorf("next(%v) = %v, wanted %v", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron_errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * smarch *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) succeeded, wanted an error", expr)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package schedule enqueues recurring keys into a workqueue: register a key
+// with a fixed interval or a cron expression, and a Scheduler enqueues it on
+// every run, replacing a resync.Sharder driven by an external cron for keys
+// that each need their own cadence.
+//
+// Schedules are persisted in a store/blob backend. Point the Scheduler at the
+// queue's own backend (the bucket of a gcs workqueue through the store/blob
+// GCS backend, or the store of a blobqueue or fs workqueue) and the schedules
+// live beside the keys they drive, under their own prefix, which neither
+// queue enumerates.
+//
+//	sched := schedule.New(wq, store)
+//	if err := sched.Register(ctx, schedule.Schedule{
+//	    Key:  "repo/foo",
+//	    Cron: "0 */6 * * *",
+//	}); err != nil {
+//	    return err
+//	}
+//	go sched.Run(ctx)
+//
+// # Runs
+//
+// Run polls the schedules (every minute by default) and enqueues each run due
+// before the next poll with a NotBefore of its time plus a random jitter
+// (internal/jitter), by default up to a tenth of the time between runs. Runs
+// missed while no Scheduler was polling are skipped rather than fired at
+// once. Every replica sharing the store may run a Scheduler: each run is
+// recorded with a compare-and-swap, and the enqueues of replicas racing on the
+// same run dedup in the queue.
+//
+// # Inspection
+//
+// A Scheduler is itself a workqueue.Interface over the queue it drives, whose
+// Get attaches the key's schedule as KeyState.Schedule (its spec, priority,
+// and next and last runs). Servers answer GetKeyState with it by passing the
+// Scheduler to workqueue.ServeGetKeyState, and wqctl get reads schedules
+// stored under the default prefix (or -schedule-prefix) beside the queue.
+package schedule
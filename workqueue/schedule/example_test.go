@@ -0,0 +1,38 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schedule_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+	"chainguard.dev/driftlessaf/workqueue/schedule"
+)
+
+// ExampleScheduler_Register demonstrates registering a recurring key and
+// reading its schedule back through Get, as GetKeyState reports it.
+func ExampleScheduler_Register() {
+	ctx := context.Background()
+	sched := schedule.New(inmem.NewWorkQueue(5), blob.NewMem())
+
+	if err := sched.Register(ctx, schedule.Schedule{
+		Key:      "repo/foo",
+		Every:    6 * time.Hour,
+		Priority: 10,
+	}); err != nil {
+		panic(err)
+	}
+
+	state, err := sched.Get(ctx, "repo/foo")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(state.GetSchedule().GetSpec(), state.GetSchedule().GetPriority())
+	// Output: @every 6h0m0s 10
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainguard-dev/clog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/internal/jitter"
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue"
+)
+
+// Store is the blob backend method set schedules are persisted in. It is
+// declared here, by the consumer, following the store/blob convention: any
+// backend that passes blobtest.RunConformance (blob.Mem, the GCS and disk
+// stores, ...) satisfies it implicitly.
+type Store interface {
+	Put(ctx context.Context, name string, data []byte, cond blob.Cond) (blob.Gen, error)
+	Get(ctx context.Context, name string) ([]byte, blob.Gen, bool, error)
+	Delete(ctx context.Context, name string, ifGen blob.Gen) error
+	List(ctx context.Context, prefix string, limit int, cursor string) (blob.Page, error)
+}
+
+// Schedule describes a recurring key.
+type Schedule struct {
+	// Key is the workqueue key to enqueue on every run.
+	Key string
+
+	// Every runs the key on a fixed interval, starting one interval after it
+	// is registered. Exactly one of Every and Cron must be set.
+	Every time.Duration
+
+	// Cron runs the key on a five-field cron expression, evaluated in UTC.
+	Cron string
+
+	// Priority is the priority each run is enqueued with.
+	Priority int64
+
+	// Jitter bounds the random delay added to each run, so keys sharing a
+	// schedule do not all land at once. Zero defaults to a tenth of the time
+	// between runs; a negative value disables jitter.
+	Jitter time.Duration
+
+	// Next is the next run that has not been enqueued yet, and Last the most
+	// recent run that was. The Scheduler maintains both; Register ignores
+	// them.
+	Next, Last time.Time
+}
+
+// timing yields the runs of a schedule.
+type timing interface {
+	// next returns the first run strictly after t, or the zero time if there
+	// is none.
+	next(t time.Time) time.Time
+}
+
+// spec returns the schedule's timing, validating it.
+func (s Schedule) spec() (timing, error) {
+	switch {
+	case s.Every > 0 && s.Cron != "":
+		return nil, errors.New("only one of Every and Cron may be set")
+	case s.Every > 0:
+		return every(s.Every), nil
+	case s.Cron != "":
+		return parseCron(s.Cron)
+	default:
+		return nil, errors.New("one of Every (positive) and Cron must be set")
+	}
+}
+
+// every is a fixed-interval spec.
+type every time.Duration
+
+func (e every) next(t time.Time) time.Time { return t.Add(time.Duration(e)) }
+
+// Proto returns the schedule as reported in workqueue.KeyState.
+func (s Schedule) Proto() *workqueue.Schedule {
+	spec := s.Cron
+	if s.Every > 0 {
+		spec = "@every " + s.Every.String()
+	}
+	ps := &workqueue.Schedule{
+		Spec:     spec,
+		Priority: s.Priority,
+	}
+	if !s.Next.IsZero() {
+		ps.NextRunTime = s.Next.Unix()
+	}
+	if !s.Last.IsZero() {
+		ps.LastRunTime = s.Last.Unix()
+	}
+	return ps
+}
+
+// record is the body stored for every schedule.
+type record struct {
+	Key      string        `json:"key"`
+	Every    time.Duration `json:"every,omitempty"`
+	Cron     string        `json:"cron,omitempty"`
+	Priority int64         `json:"priority,omitempty"`
+	Jitter   time.Duration `json:"jitter,omitempty"`
+	Next     time.Time     `json:"next"`
+	Last     time.Time     `json:"last,omitzero"`
+}
+
+func (r record) schedule() Schedule {
+	return Schedule{
+		Key:      r.Key,
+		Every:    r.Every,
+		Cron:     r.Cron,
+		Priority: r.Priority,
+		Jitter:   r.Jitter,
+		Next:     r.Next,
+		Last:     r.Last,
+	}
+}
+
+// Option configures a Scheduler created by New.
+type Option func(*Scheduler)
+
+// WithPrefix sets the prefix under which schedules are stored, so they can
+// share a store (such as the bucket of a gcs workqueue, or the store of a
+// blobqueue) with other data. Defaults to "schedules/".
+func WithPrefix(prefix string) Option {
+	return func(s *Scheduler) { s.prefix = prefix }
+}
+
+// WithPollInterval sets how often Run checks for due schedules. Runs due
+// before the next check are enqueued ahead of time with a NotBefore, so the
+// interval bounds load on the store rather than the precision of runs.
+// Defaults to one minute.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Scheduler) { s.poll = d }
+}
+
+var _ workqueue.Interface = (*Scheduler)(nil)
+
+// Scheduler enqueues recurring keys on schedule. Schedules are persisted in a
+// Store, so every replica sharing the store shares them; replicas may run
+// concurrently, and each run is recorded by exactly one of them.
+type Scheduler struct {
+	wq     workqueue.Interface
+	store  Store
+	prefix string
+	poll   time.Duration
+	now    func() time.Time
+}
+
+// New creates a Scheduler enqueuing into wq the schedules persisted in store.
+func New(wq workqueue.Interface, store Store, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		wq:     wq,
+		store:  store,
+		prefix: "schedules/",
+		poll:   time.Minute,
+		now:    time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// listPageSize is how many names each List call fetches.
+const listPageSize = 1000
+
+// Register creates or replaces the schedule of sched.Key. Its first run is
+// the first time the schedule matches after now.
+func (s *Scheduler) Register(ctx context.Context, sched Schedule) error {
+	if sched.Key == "" {
+		return errors.New("schedule: key is required")
+	}
+	spec, err := sched.spec()
+	if err != nil {
+		return fmt.Errorf("schedule %q: %w", sched.Key, err)
+	}
+	next := spec.next(s.now())
+	if next.IsZero() {
+		return fmt.Errorf("schedule %q: %q never runs", sched.Key, sched.Cron)
+	}
+
+	rec := record{
+		Key:      sched.Key,
+		Every:    sched.Every,
+		Cron:     sched.Cron,
+		Priority: sched.Priority,
+		Jitter:   sched.Jitter,
+		Next:     next,
+	}
+	// Keep the last run of a schedule being replaced.
+	if prev, _, ok, err := s.get(ctx, sched.Key); err != nil {
+		return err
+	} else if ok {
+		rec.Last = prev.Last
+	}
+	return s.put(ctx, rec, blob.Cond{})
+}
+
+// Unregister removes the schedule of key. Runs already enqueued are left in
+// the queue. It is not an error if key has no schedule.
+func (s *Scheduler) Unregister(ctx context.Context, key string) error {
+	if err := s.store.Delete(ctx, s.prefix+key, 0); err != nil && !errors.Is(err, blob.ErrNotExist) {
+		return fmt.Errorf("delete schedule %q: %w", key, err)
+	}
+	return nil
+}
+
+// Lookup returns the schedule of key, and whether it has one.
+func (s *Scheduler) Lookup(ctx context.Context, key string) (Schedule, bool, error) {
+	rec, _, ok, err := s.get(ctx, key)
+	if err != nil || !ok {
+		return Schedule{}, false, err
+	}
+	return rec.schedule(), true, nil
+}
+
+// Queue implements workqueue.Interface by queuing key in the scheduled
+// workqueue.
+func (s *Scheduler) Queue(ctx context.Context, key string, opts workqueue.Options) error {
+	return s.wq.Queue(ctx, key, opts)
+}
+
+// Enumerate implements workqueue.Interface by enumerating the scheduled
+// workqueue.
+func (s *Scheduler) Enumerate(ctx context.Context) ([]workqueue.ObservedInProgressKey, []workqueue.QueuedKey, []workqueue.DeadLetteredKey, error) {
+	return s.wq.Enumerate(ctx)
+}
+
+// Get implements workqueue.Interface: it is the scheduled workqueue's Get
+// with the key's schedule attached, so servers answering GetKeyState through
+// workqueue.ServeGetKeyState with the Scheduler report it. A scheduled key
+// that is not currently in the queue is reported with status UNKNOWN.
+func (s *Scheduler) Get(ctx context.Context, key string) (*workqueue.KeyState, error) {
+	state, err := s.wq.Get(ctx, key)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
+	sched, ok, lerr := s.Lookup(ctx, key)
+	if lerr != nil {
+		return nil, lerr
+	}
+	if !ok {
+		return state, err
+	}
+	if err != nil {
+		state = &workqueue.KeyState{Key: key}
+	}
+	state.Schedule = sched.Proto()
+	return state, nil
+}
+
+// Run enqueues due schedules every poll interval until ctx is canceled. A
+// failed pass is logged and retried on the next one.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil {
+			clog.WarnContextf(ctx, "Failed to enqueue scheduled keys: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick makes one pass over the schedules, enqueuing every run due before the
+// next pass with a NotBefore of its (jittered) time. It reports the
+// schedules it failed to advance, after attempting all of them.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	var errs []error
+	cursor := ""
+	for {
+		page, err := s.store.List(ctx, s.prefix, listPageSize, cursor)
+		if err != nil {
+			return fmt.Errorf("list schedules: %w", err)
+		}
+		for _, obj := range page.Objects {
+			if err := s.advance(ctx, strings.TrimPrefix(obj.Name, s.prefix)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if page.Cursor == "" {
+			return errors.Join(errs...)
+		}
+		cursor = page.Cursor
+	}
+}
+
+// advance enqueues the next run of key's schedule if it is due before the
+// next pass, and records it.
+func (s *Scheduler) advance(ctx context.Context, key string) error {
+	rec, gen, ok, err := s.get(ctx, key)
+	if err != nil || !ok {
+		return err
+	}
+	now := s.now()
+	if rec.Next.After(now.Add(s.poll)) {
+		return nil
+	}
+	spec, err := rec.schedule().spec()
+	if err != nil {
+		return fmt.Errorf("schedule %q: %w", key, err)
+	}
+
+	// Enqueue before recording the run: if another replica records it first,
+	// its enqueue of the same run dedups with ours.
+	notBefore := rec.Next.Add(s.jitter(rec, spec))
+	if err := s.wq.Queue(ctx, key, workqueue.Options{Priority: rec.Priority, NotBefore: notBefore}); err != nil {
+		return fmt.Errorf("enqueue %q: %w", key, err)
+	}
+	clog.DebugContextf(ctx, "Enqueued scheduled key %q not before %v", key, notBefore)
+
+	// Skip runs missed while no replica was polling, rather than firing
+	// them all at once.
+	rec.Last = rec.Next
+	rec.Next = spec.next(latest(rec.Next, now))
+	if err := s.put(ctx, rec, blob.Cond{GenerationMatch: gen}); errors.Is(err, blob.ErrPreconditionFailed) {
+		clog.DebugContextf(ctx, "Schedule %q was updated concurrently, leaving it", key)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// jitter returns the random delay to add to rec's next run.
+func (s *Scheduler) jitter(rec record, spec timing) time.Duration {
+	bound := rec.Jitter
+	if bound == 0 {
+		if after := spec.next(rec.Next); !after.IsZero() {
+			bound = after.Sub(rec.Next) / 10
+		}
+	}
+	if bound <= 0 {
+		return 0
+	}
+	// jitter.Add returns bound plus up to 100% of bound.
+	return jitter.Add(bound) - bound
+}
+
+// latest returns the later of a and b.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// get reads the schedule record of key.
+func (s *Scheduler) get(ctx context.Context, key string) (record, blob.Gen, bool, error) {
+	data, gen, ok, err := s.store.Get(ctx, s.prefix+key)
+	if err != nil {
+		return record{}, 0, false, fmt.Errorf("get schedule %q: %w", key, err)
+	}
+	if !ok {
+		return record{}, 0, false, nil
+	}
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return record{}, 0, false, fmt.Errorf("decode schedule %q: %w", key, err)
+	}
+	return rec, gen, true, nil
+}
+
+// put writes the schedule record of rec.Key.
+func (s *Scheduler) put(ctx context.Context, rec record, cond blob.Cond) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode schedule %q: %w", rec.Key, err)
+	}
+	if _, err := s.store.Put(ctx, s.prefix+rec.Key, data, cond); err != nil {
+		return fmt.Errorf("put schedule %q: %w", rec.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026 Chainguard, Inc.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chainguard.dev/driftlessaf/store/blob"
+	"chainguard.dev/driftlessaf/workqueue"
+	"chainguard.dev/driftlessaf/workqueue/inmem"
+)
+
+// newTestScheduler returns a Scheduler over a fresh queue and store whose
+// clock reads *now.
+func newTestScheduler(now *time.Time, opts ...Option) (*Scheduler, workqueue.Interface, *blob.Mem) {
+	wq := inmem.NewWorkQueue(5)
+	store := blob.NewMem()
+	s := New(wq, store, opts...)
+	s.now = func() time.Time { return *now }
+	return s, wq, store
+}
+
+func TestRegister_validation(t *testing.T) {
+	now := time.Now()
+	s, _, _ := newTestScheduler(&now)
+
+	for _, sched := range []Schedule{
+		{Every: time.Hour},
+		{Key: "k"},
+		{Key: "k", Every: -time.Hour},
+		{Key: "k", Every: time.Hour, Cron: "@daily"},
+		{Key: "k", Cron: "not a cron"},
+		{Key: "k", Cron: "0 0 30 2 *"},
+	} {
+		if err := s.Register(t.Context(), sched); err == nil {
+			t.Errorf("Register(%+v) succeeded, wanted an error", sched)
+		}
+	}
+}
+
+func TestTick(t *testing.T) {
+	ctx := t.Context()
+	now := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+	s, wq, _ := newTestScheduler(&now, WithPollInterval(time.Minute))
+
+	if err := s.Register(ctx, Schedule{Key: "k", Every: time.Hour, Priority: 3, Jitter: -1}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	// Nothing is due before the first run.
+	if err := s.Tick(ctx); err != nil {
+		t.Fatalf("Tick() = %v", err)
+	}
+	if _, err := wq.Get(ctx, "k"); status.Code(err) != codes.NotFound {
+		t.Fatalf("Get() = %v, wanted NotFound before the first run", err)
+	}
+
+	// A run due before the next poll is enqueued ahead, not before its time.
+	now = now.Add(time.Hour - 30*time.Second)
+	if err := s.Tick(ctx); err != nil {
+		t.Fatalf("Tick() = %v", err)
+	}
+	state, err := s.Get(ctx, "k")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	firstRun := time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)
+	if state.GetStatus() != workqueue.KeyState_QUEUED || state.GetPriority() != 3 || state.GetNotBeforeTime() != firstRun.Unix() {
+		t.Errorf("Get() = %v, wanted queued with priority 3 not before %v", state, firstRun)
+	}
+	if got, want := state.GetSchedule().GetLastRunTime(), firstRun.Unix(); got != want {
+		t.Errorf("last run = %v, wanted %v", got, want)
+	}
+	if got, want := state.GetSchedule().GetNextRunTime(), firstRun.Add(time.Hour).Unix(); got != want {
+		t.Errorf("next run = %v, wanted %v", got, want)
+	}
+
+	// Runs missed while nothing polled are skipped.
+	now = firstRun.Add(5*time.Hour + 10*time.Minute)
+	if err := s.Tick(ctx); err != nil {
+		t.Fatalf("Tick() = %v", err)
+	}
+	sched, ok, err := s.Lookup(ctx, "k")
+	if err != nil || !ok {
+		t.Fatalf("Lookup() = %v, %v", ok, err)
+	}
+	if want := now.Add(time.Hour); !sched.Next.Equal(want) {
+		t.Errorf("next run after an outage = %v, wanted %v", sched.Next, want)
+	}
+}
+
+func TestTick_jitter(t *testing.T) {
+	ctx := t.Context()
+	now := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+	s, wq, _ := newTestScheduler(&now)
+
+	// The default jitter is a tenth of the time between runs.
+	if err := s.Register(ctx, Schedule{Key: "k", Cron: "0 * * * *"}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	now = now.Add(time.Hour)
+	if err := s.Tick(ctx); err != nil {
+		t.Fatalf("Tick() = %v", err)
+	}
+	state, err := wq.Get(ctx, "k")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	run := time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)
+	if nb := time.Unix(state.GetNotBeforeTime(), 0); nb.Before(run) || !nb.Before(run.Add(6*time.Minute)) {
+		t.Errorf("not before = %v, wanted within [%v, %v)", nb, run, run.Add(6*time.Minute))
+	}
+}
+
+func TestTick_concurrentReplicas(t *testing.T) {
+	ctx := t.Context()
+	now := time.Date(2026, time.March, 4, 10, 0, 0, 0, time.UTC)
+	a, wq, store := newTestScheduler(&now)
+	b := New(wq, store)
+	b.now = a.now
+
+	if err := a.Register(ctx, Schedule{Key: "k", Every: time.Hour, Jitter: -1}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	now = now.Add(time.Hour)
+	for _, s := range []*Scheduler{a, b, a} {
+		if err := s.Tick(ctx); err != nil {
+			t.Fatalf("Tick() = %v", err)
+		}
+	}
+
+	// The run is recorded once, and the schedule moves on by one interval.
+	sched, _, err := b.Lookup(ctx, "k")
+	if err != nil {
+		t.Fatalf("Lookup() = %v", err)
+	}
+	if want := now.Add(time.Hour); !sched.Next.Equal(want) {
+		t.Errorf("next run = %v, wanted %v", sched.Next, want)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	ctx := t.Context()
+	now := time.Now()
+	s, _, _ := newTestScheduler(&now)
+
+	if err := s.Register(ctx, Schedule{Key: "k", Every: time.Hour}); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	state, err := s.Get(ctx, "k")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if state.GetStatus() != workqueue.KeyState_UNKNOWN || state.GetSchedule() == nil {
+		t.Errorf("Get() = %v, wanted a scheduled key that is not queued", state)
+	}
+
+	if err := s.Unregister(ctx, "k"); err != nil {
+		t.Fatalf("Unregister() = %v", err)
+	}
+	if err := s.Unregister(ctx, "k"); err != nil {
+		t.Fatalf("Unregister() of an unscheduled key = %v", err)
+	}
+	if _, err := s.Get(ctx, "k"); status.Code(err) != codes.NotFound {
+		t.Errorf("Get() = %v, wanted NotFound", err)
+	}
+}
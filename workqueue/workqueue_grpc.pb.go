@@ -19,8 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	WorkqueueService_Process_FullMethodName     = "/chainguard.workqueue.WorkqueueService/Process"
-	WorkqueueService_GetKeyState_FullMethodName = "/chainguard.workqueue.WorkqueueService/GetKeyState"
+	WorkqueueService_Process_FullMethodName           = "/chainguard.workqueue.WorkqueueService/Process"
+	WorkqueueService_GetKeyState_FullMethodName       = "/chainguard.workqueue.WorkqueueService/GetKeyState"
+	WorkqueueService_ProcessBatch_FullMethodName      = "/chainguard.workqueue.WorkqueueService/ProcessBatch"
+	WorkqueueService_ReplayDeadLetters_FullMethodName = "/chainguard.workqueue.WorkqueueService/ReplayDeadLetters"
+	WorkqueueService_PurgeDeadLetters_FullMethodName  = "/chainguard.workqueue.WorkqueueService/PurgeDeadLetters"
+	WorkqueueService_ListKeys_FullMethodName          = "/chainguard.workqueue.WorkqueueService/ListKeys"
 )
 
 // WorkqueueServiceClient is the client API for WorkqueueService service.
@@ -29,6 +33,19 @@ const (
 type WorkqueueServiceClient interface {
 	Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error)
 	GetKeyState(ctx context.Context, in *GetKeyStateRequest, opts ...grpc.CallOption) (*KeyState, error)
+	// ProcessBatch enqueues many keys in one call. Each request is handled as
+	// Process would handle it, and a failure on one key does not fail the
+	// others: the response carries one result per request, in request order.
+	ProcessBatch(ctx context.Context, in *ProcessBatchRequest, opts ...grpc.CallOption) (*ProcessBatchResponse, error)
+	// ReplayDeadLetters moves the selected dead-lettered keys back into the
+	// queue with a fresh attempt count.
+	ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersResponse, error)
+	// PurgeDeadLetters permanently removes the selected dead-lettered keys.
+	PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersResponse, error)
+	// ListKeys reports the state of every key in the queue, including queued
+	// keys that are not yet eligible to run. It is meant for operator tooling:
+	// it reads every key, so it is not cheap on large queues.
+	ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (*ListKeysResponse, error)
 }
 
 type workqueueServiceClient struct {
@@ -59,12 +76,65 @@ func (c *workqueueServiceClient) GetKeyState(ctx context.Context, in *GetKeyStat
 	return out, nil
 }
 
+func (c *workqueueServiceClient) ProcessBatch(ctx context.Context, in *ProcessBatchRequest, opts ...grpc.CallOption) (*ProcessBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessBatchResponse)
+	err := c.cc.Invoke(ctx, WorkqueueService_ProcessBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workqueueServiceClient) ReplayDeadLetters(ctx context.Context, in *ReplayDeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeadLettersResponse)
+	err := c.cc.Invoke(ctx, WorkqueueService_ReplayDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workqueueServiceClient) PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeadLettersResponse)
+	err := c.cc.Invoke(ctx, WorkqueueService_PurgeDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *workqueueServiceClient) ListKeys(ctx context.Context, in *ListKeysRequest, opts ...grpc.CallOption) (*ListKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListKeysResponse)
+	err := c.cc.Invoke(ctx, WorkqueueService_ListKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WorkqueueServiceServer is the server API for WorkqueueService service.
 // All implementations must embed UnimplementedWorkqueueServiceServer
 // for forward compatibility.
 type WorkqueueServiceServer interface {
 	Process(context.Context, *ProcessRequest) (*ProcessResponse, error)
 	GetKeyState(context.Context, *GetKeyStateRequest) (*KeyState, error)
+	// ProcessBatch enqueues many keys in one call. Each request is handled as
+	// Process would handle it, and a failure on one key does not fail the
+	// others: the response carries one result per request, in request order.
+	ProcessBatch(context.Context, *ProcessBatchRequest) (*ProcessBatchResponse, error)
+	// ReplayDeadLetters moves the selected dead-lettered keys back into the
+	// queue with a fresh attempt count.
+	ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*DeadLettersResponse, error)
+	// PurgeDeadLetters permanently removes the selected dead-lettered keys.
+	PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*DeadLettersResponse, error)
+	// ListKeys reports the state of every key in the queue, including queued
+	// keys that are not yet eligible to run. It is meant for operator tooling:
+	// it reads every key, so it is not cheap on large queues.
+	ListKeys(context.Context, *ListKeysRequest) (*ListKeysResponse, error)
 	mustEmbedUnimplementedWorkqueueServiceServer()
 }
 
@@ -81,6 +151,18 @@ func (UnimplementedWorkqueueServiceServer) Process(context.Context, *ProcessRequ
 func (UnimplementedWorkqueueServiceServer) GetKeyState(context.Context, *GetKeyStateRequest) (*KeyState, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetKeyState not implemented")
 }
+func (UnimplementedWorkqueueServiceServer) ProcessBatch(context.Context, *ProcessBatchRequest) (*ProcessBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessBatch not implemented")
+}
+func (UnimplementedWorkqueueServiceServer) ReplayDeadLetters(context.Context, *ReplayDeadLettersRequest) (*DeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayDeadLetters not implemented")
+}
+func (UnimplementedWorkqueueServiceServer) PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*DeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeadLetters not implemented")
+}
+func (UnimplementedWorkqueueServiceServer) ListKeys(context.Context, *ListKeysRequest) (*ListKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKeys not implemented")
+}
 func (UnimplementedWorkqueueServiceServer) mustEmbedUnimplementedWorkqueueServiceServer() {}
 func (UnimplementedWorkqueueServiceServer) testEmbeddedByValue()                          {}
 
@@ -138,6 +220,78 @@ func _WorkqueueService_GetKeyState_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkqueueService_ProcessBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkqueueServiceServer).ProcessBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorkqueueService_ProcessBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkqueueServiceServer).ProcessBatch(ctx, req.(*ProcessBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkqueueService_ReplayDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkqueueServiceServer).ReplayDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorkqueueService_ReplayDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkqueueServiceServer).ReplayDeadLetters(ctx, req.(*ReplayDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkqueueService_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkqueueServiceServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorkqueueService_PurgeDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkqueueServiceServer).PurgeDeadLetters(ctx, req.(*PurgeDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorkqueueService_ListKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorkqueueServiceServer).ListKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorkqueueService_ListKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorkqueueServiceServer).ListKeys(ctx, req.(*ListKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WorkqueueService_ServiceDesc is the grpc.ServiceDesc for WorkqueueService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +307,22 @@ var WorkqueueService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetKeyState",
 			Handler:    _WorkqueueService_GetKeyState_Handler,
 		},
+		{
+			MethodName: "ProcessBatch",
+			Handler:    _WorkqueueService_ProcessBatch_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetters",
+			Handler:    _WorkqueueService_ReplayDeadLetters_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _WorkqueueService_PurgeDeadLetters_Handler,
+		},
+		{
+			MethodName: "ListKeys",
+			Handler:    _WorkqueueService_ListKeys_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "workqueue.proto",